@@ -283,10 +283,10 @@ build() {
     case "${target}" in
         "default"|"")
             log "Building ${BINARY_NAME} for ${OS}/${ARCH} with Go 1.24 optimizations"
-            extra_flags="-tags ${BUILD_TAGS}"
+            extra_flags="-tags ${BUILD_TAGS},release"
             ;;
         "performance")
-            extra_flags="-tags ${BUILD_TAGS},performance -race"
+            extra_flags="-tags ${BUILD_TAGS},release,performance -race"
             build_mode="performance"
             log "Building performance-optimized version with profiling"
             ;;
@@ -298,25 +298,25 @@ build() {
         "windows")
             output_name="${BINARY_NAME}.exe"
             build_env="GOOS=windows GOARCH=amd64"
-            extra_flags="-tags ${BUILD_TAGS}"
+            extra_flags="-tags ${BUILD_TAGS},release"
             log "Cross-compiling for Windows AMD64"
             ;;
         "macos")
             output_name="${BINARY_NAME}-macos-amd64"
             build_env="GOOS=darwin GOARCH=amd64"
-            extra_flags="-tags ${BUILD_TAGS}"
+            extra_flags="-tags ${BUILD_TAGS},release"
             log "Cross-compiling for macOS Intel"
             ;;
         "macos-arm64")
             output_name="${BINARY_NAME}-macos-arm64"
             build_env="GOOS=darwin GOARCH=arm64"
-            extra_flags="-tags ${BUILD_TAGS}"
+            extra_flags="-tags ${BUILD_TAGS},release"
             log "Cross-compiling for macOS Apple Silicon with native optimizations"
             ;;
         "linux")
             output_name="${BINARY_NAME}-linux-amd64"
             build_env="GOOS=linux GOARCH=amd64"
-            extra_flags="-tags ${BUILD_TAGS}"
+            extra_flags="-tags ${BUILD_TAGS},release"
             log "Cross-compiling for Linux AMD64"
             ;;
         "all")
@@ -478,6 +478,8 @@ run_audit() {
     # Modern vet check with enhanced analysis
     log "Running enhanced static analysis..."
     go vet ./...
+    go run ./cmd/minmaxcheck internal cmd
+    go run ./cmd/fyneaudit
     success "Static analysis passed"
     
     # Module verification with modern standards
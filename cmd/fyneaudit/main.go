@@ -0,0 +1,34 @@
+// Command fyneaudit exits non-zero when a package directory, by default the
+// views, their components and the controllers, calls fyne.Do from inside a
+// fyne.Do closure. The directories are audited together, so a controller
+// calling a view method that queues its own update is reported.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"otsu-obliterator/internal/testutil/fyneaudit"
+)
+
+// defaultDirs are the packages that update the UI
+var defaultDirs = []string{"internal/views", "internal/views/components", "internal/controllers"}
+
+func main() {
+	dirs := os.Args[1:]
+	if len(dirs) == 0 {
+		dirs = defaultDirs
+	}
+
+	findings, err := fyneaudit.Audit(dirs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
+	for _, finding := range findings {
+		fmt.Fprintln(os.Stderr, finding)
+	}
+	if len(findings) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,34 @@
+// Command minmaxcheck exits non-zero when a package under the given
+// directories, ./internal by default, redeclares the min or max built-in.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"otsu-obliterator/internal/testutil/minmaxcheck"
+)
+
+func main() {
+	roots := os.Args[1:]
+	if len(roots) == 0 {
+		roots = []string{"internal"}
+	}
+
+	failed := false
+	for _, root := range roots {
+		findings, err := minmaxcheck.Check(root)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(2)
+		}
+		for _, finding := range findings {
+			fmt.Fprintln(os.Stderr, finding)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
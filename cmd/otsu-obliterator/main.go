@@ -6,16 +6,21 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"sync"
 	"syscall"
 	"time"
 
+	"otsu-obliterator/internal/cli"
 	"otsu-obliterator/internal/controllers"
 	"otsu-obliterator/internal/logger"
 	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/monitoring"
 	"otsu-obliterator/internal/opencv/memory"
 	"otsu-obliterator/internal/services"
 	"otsu-obliterator/internal/views"
+	"otsu-obliterator/internal/views/components"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -24,6 +29,7 @@ import (
 const (
 	AppName    = "Otsu Obliterator"
 	AppID      = "com.imageprocessing.otsu-obliterator"
+	PluginDir  = "plugins"
 	AppVersion = "1.0.0"
 )
 
@@ -41,12 +47,27 @@ type Application struct {
 	// Services
 	imageService      *services.ImageService
 	processingService *services.ProcessingService
+	autoSaver         *services.AutoSaver
 
 	// Models/Repositories
 	imageRepo     *models.ImageRepository
 	configRepo    *models.ProcessingConfiguration
 	stateRepo     *models.ProcessingStateRepository
 	memoryManager *memory.Manager
+	memoryWarned  bool
+
+	// Remote monitoring, started only when MONITOR_PORT is set
+	monitor *monitoring.MonitoringServer
+
+	// Flushes exported traces, a no-op unless OTEL_EXPORTER_OTLP_ENDPOINT is set
+	shutdownTracing func(context.Context) error
+
+	// Session persistence
+	sessionSerializer *models.SessionSerializer
+	sessionMu         sync.Mutex
+	pendingSession    *models.Session
+	sessionCleared    bool
+	sessionSaveOnce   sync.Once
 
 	// Lifecycle management
 	ctx    context.Context
@@ -57,6 +78,14 @@ func main() {
 	// Configure Go 1.24 runtime for image processing workloads
 	configureRuntime()
 
+	// Headless mode runs the CLI pipeline before any Fyne initialisation
+	if cli.IsHeadless(os.Args[1:]) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+		stop()
+		os.Exit(code)
+	}
+
 	// Create application context with graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -117,7 +146,7 @@ func NewApplication(ctx context.Context) (*Application, error) {
 
 	// Initialize logger
 	logLevel := determineLogLevel()
-	appLogger := logger.NewStructuredLogger(logLevel)
+	appLogger := newAppLogger(logLevel)
 
 	appLogger.Info("Application starting", map[string]interface{}{
 		"version":     AppVersion,
@@ -125,24 +154,46 @@ func NewApplication(ctx context.Context) (*Application, error) {
 		"go_version":  runtime.Version(),
 		"num_cpu":     runtime.NumCPU(),
 		"log_level":   logLevel,
+		"log_format":  determineLogFormat(),
 	})
 
+	shutdownTracing, err := monitoring.SetupTracing(appCtx, AppVersion)
+	if err != nil {
+		appLogger.Warning("Tracing setup failed, spans will not be exported", map[string]interface{}{
+			"error": err.Error(),
+		})
+		shutdownTracing = func(context.Context) error { return nil }
+	}
+
 	// Initialize repositories/models
 	imageRepo := models.NewImageRepository()
-	configRepo := models.NewProcessingConfiguration()
+	configRepo := models.NewProcessingConfigurationWithPreferences(fyneApp.Preferences())
 	stateRepo := models.NewProcessingStateRepository()
 	memManager := memory.NewManager(appLogger)
 
 	// Initialize services
 	imageService := services.NewImageService(memManager, imageRepo)
+	imageService.SetLogger(appLogger)
 	processingService := services.NewProcessingService(memManager, imageRepo, configRepo, stateRepo)
+	processingService.SetLogger(appLogger)
 
 	// Initialize MVC components
 	mainController := controllers.NewMainController(
 		imageService, processingService,
 		imageRepo, configRepo, stateRepo,
 	)
+	mainController.SetLogger(appLogger)
+	autoSaver := services.NewAutoSaver(imageService, imageRepo, configRepo, appLogger)
+	mainController.SetAutoSaver(autoSaver)
+	if presets, err := models.NewPresetManager("otsu-obliterator"); err != nil {
+		appLogger.Warning("Parameter presets unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		mainController.SetPresetManager(presets)
+	}
 	mainView := views.NewMainView(window)
+	mainView.LoadShortcuts("otsu-obliterator", appLogger)
 
 	// Wire MVC components together
 	mainController.SetMainView(mainView)
@@ -157,14 +208,26 @@ func NewApplication(ctx context.Context) (*Application, error) {
 		view:              mainView,
 		imageService:      imageService,
 		processingService: processingService,
+		autoSaver:         autoSaver,
 		imageRepo:         imageRepo,
 		configRepo:        configRepo,
 		stateRepo:         stateRepo,
 		memoryManager:     memManager,
+		shutdownTracing:   shutdownTracing,
 		ctx:               appCtx,
 		cancel:            appCancel,
 	}
 
+	// Load plugin algorithms before the session so a saved plugin selection resolves
+	application.loadPlugins()
+
+	application.startMonitoring()
+	application.configureMetricsExport()
+
+	// Restore the previous session if one was saved
+	application.restoreSession()
+	mainView.SetClearSessionHandler(application.clearSession)
+
 	// Setup window lifecycle events
 	application.setupWindowEvents()
 
@@ -199,8 +262,9 @@ func (app *Application) Run(ctx context.Context) error {
 		}
 	}()
 
-	// Start performance monitoring
+	// Start performance monitoring and the auto-saver
 	go app.startPerformanceMonitoring()
+	app.autoSaver.Start(app.ctx)
 
 	// Run Fyne application (blocking)
 	app.fyneApp.Run()
@@ -274,7 +338,7 @@ func (app *Application) logPerformanceMetrics() {
 	var memStats runtime.MemStats
 	runtime.ReadMemStats(&memStats)
 
-	allocCount, deallocCount, usedMemory := app.memoryManager.GetStats()
+	memoryStats := app.memoryManager.GetStats()
 	processingStats := app.processingService.GetProcessingStats()
 	imageStats := app.imageRepo.GetImageStats()
 
@@ -282,26 +346,39 @@ func (app *Application) logPerformanceMetrics() {
 		"go_memory_mb":        memStats.Alloc / 1024 / 1024,
 		"go_total_alloc_mb":   memStats.TotalAlloc / 1024 / 1024,
 		"go_gc_runs":          memStats.NumGC,
-		"opencv_allocs":       allocCount,
-		"opencv_deallocs":     deallocCount,
-		"opencv_memory_mb":    usedMemory / 1024 / 1024,
+		"opencv_allocs":       memoryStats.AllocCount,
+		"opencv_deallocs":     memoryStats.DeallocCount,
+		"opencv_memory_mb":    memoryStats.UsedMemory / 1024 / 1024,
 		"images_processed":    processingStats.TotalProcessed,
 		"avg_process_time_ms": processingStats.AverageTime.Milliseconds(),
-		"images_in_memory":    imageStats.ProcessedCount,
+		"images_in_memory":    imageStats.HistorySize,
+		"runs_completed":      imageStats.ProcessedCount,
+		"last_algorithm":      imageStats.LastProcessedAlgorithm,
+		"image_memory_mb":     imageStats.TotalMemoryBytes / 1024 / 1024,
 		"worker_count":        app.processingService.GetWorkerCount(),
 		"goroutine_count":     runtime.NumGoroutine(),
 	})
 
-	// Update UI with memory information (thread-safe)
-	fyne.Do(func() {
-		app.view.SetMemoryInfo(usedMemory, memStats.Alloc)
-	})
+	// Show OpenCV memory against the configured limit, warning once each
+	// time usage rises past the gauge's red zone
+	memoryLimit := app.configRepo.GetPerformanceSettings().MemoryLimit
+	app.view.SetMemoryInfo(memoryStats.UsedMemory, memoryLimit)
+
+	highMemory := memoryLimit > 0 && float64(memoryStats.UsedMemory) > components.MemoryWarningRatio*float64(memoryLimit)
+	if highMemory && !app.memoryWarned {
+		app.view.ShowToast(fmt.Sprintf("OpenCV memory above %.0f%% of the %d MB limit",
+			components.MemoryWarningRatio*100, memoryLimit/1024/1024), 5*time.Second)
+	}
+	app.memoryWarned = highMemory
 }
 
 // initiateShutdown begins the graceful shutdown process
 func (app *Application) initiateShutdown() {
 	app.logger.Info("Shutdown sequence initiated", nil)
 
+	// Capture session state before services release the loaded image
+	app.captureSession()
+
 	// Cancel application context
 	app.cancel()
 
@@ -321,8 +398,11 @@ func (app *Application) performShutdownSequence(ctx context.Context) {
 		name string
 		fn   func()
 	}{
+		{"monitoring server", func() { app.stopMonitoring(ctx) }},
+		{"auto-saver", app.autoSaver.Stop},
 		{"controller", app.controller.Shutdown},
 		{"processing service", app.processingService.Shutdown},
+		{"tracing", func() { app.stopTracing(ctx) }},
 		{"image service", app.imageService.Cleanup},
 		{"memory manager", app.memoryManager.Shutdown},
 	}
@@ -358,8 +438,57 @@ func (app *Application) performShutdownSequence(ctx context.Context) {
 	app.performCleanup()
 }
 
+// startMonitoring serves processing state over HTTP when MONITOR_PORT is set
+func (app *Application) startMonitoring() {
+	monitor, err := monitoring.StartFromEnv(app.stateRepo, app.logger)
+	if err != nil {
+		app.logger.Error("Failed to start monitoring server, monitoring disabled", err, nil)
+		return
+	}
+	app.monitor = monitor
+}
+
+// configureMetricsExport sends segmentation metrics to the backend selected
+// by METRICS_BACKEND
+func (app *Application) configureMetricsExport() {
+	exporter, err := monitoring.NewMetricsExporterFromEnv()
+	if err != nil {
+		app.logger.Warning("Invalid metrics export configuration, export disabled", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	if exporter != nil {
+		app.controller.SetMetricsExporter(exporter)
+	}
+}
+
+// stopMonitoring shuts the monitoring server down within the shutdown deadline
+func (app *Application) stopMonitoring(ctx context.Context) {
+	if app.monitor == nil {
+		return
+	}
+
+	if err := app.monitor.Shutdown(ctx); err != nil {
+		app.logger.Warning("Monitoring server shutdown incomplete", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// stopTracing flushes buffered spans within the shutdown deadline
+func (app *Application) stopTracing(ctx context.Context) {
+	if err := app.shutdownTracing(ctx); err != nil {
+		app.logger.Warning("Trace export incomplete", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
 // performCleanup performs final cleanup operations
 func (app *Application) performCleanup() {
+	app.sessionSaveOnce.Do(app.saveSession)
+
 	// Final garbage collection with Go 1.24 optimizations
 	runtime.GC()
 	runtime.GC() // Double collection for image processing cleanup
@@ -367,6 +496,142 @@ func (app *Application) performCleanup() {
 	app.logger.Info("Application cleanup completed", nil)
 }
 
+// loadPlugins registers plugin algorithms from the plugins directory next to
+// the executable and adds them to the algorithm selector
+func (app *Application) loadPlugins() {
+	dir := PluginDir
+	if executable, err := os.Executable(); err == nil {
+		dir = filepath.Join(filepath.Dir(executable), PluginDir)
+	}
+
+	names, err := app.processingService.LoadPlugins(dir)
+	if err != nil {
+		app.logger.Warning("Some plugins failed to load", map[string]interface{}{
+			"directory": dir,
+			"error":     err.Error(),
+		})
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	app.view.AddAlgorithms(names)
+	app.logger.Info("Plugins loaded", map[string]interface{}{
+		"directory":  dir,
+		"algorithms": names,
+	})
+}
+
+// restoreSession loads the saved session and applies it to configuration and window
+func (app *Application) restoreSession() {
+	serializer, err := models.NewSessionSerializer("otsu-obliterator")
+	if err != nil {
+		app.logger.Warning("Session persistence unavailable", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	app.sessionSerializer = serializer
+
+	if !serializer.Exists() {
+		return
+	}
+
+	session, err := serializer.Load()
+	if err != nil {
+		app.logger.Warning("Failed to load session", map[string]interface{}{
+			"path":  serializer.Path(),
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := session.ApplyTo(app.configRepo); err != nil {
+		app.logger.Warning("Session partially restored", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	app.controller.ChangeAlgorithm(app.configRepo.GetCurrentAlgorithm())
+
+	if session.ViewState.WindowWidth > 0 && session.ViewState.WindowHeight > 0 {
+		app.window.Resize(fyne.NewSize(session.ViewState.WindowWidth, session.ViewState.WindowHeight))
+	}
+	app.window.SetFullScreen(session.ViewState.Fullscreen)
+
+	app.logger.Info("Session restored", map[string]interface{}{
+		"path":            serializer.Path(),
+		"algorithm":       session.CurrentAlgorithm,
+		"last_image_path": session.LastImagePath,
+	})
+}
+
+// captureSession snapshots the current state for saving during cleanup
+func (app *Application) captureSession() {
+	lastImagePath := ""
+	if original := app.imageRepo.GetOriginalImage(); original != nil && original.OriginalURI != nil {
+		lastImagePath = original.OriginalURI.Path()
+	}
+
+	size := app.window.Canvas().Size()
+	viewState := models.SessionViewState{
+		WindowWidth:  size.Width,
+		WindowHeight: size.Height,
+		Fullscreen:   app.window.FullScreen(),
+	}
+
+	session := models.CaptureSession(app.configRepo, lastImagePath, viewState)
+
+	app.sessionMu.Lock()
+	app.pendingSession = session
+	app.sessionMu.Unlock()
+}
+
+// saveSession writes the captured session to disk
+func (app *Application) saveSession() {
+	app.sessionMu.Lock()
+	session := app.pendingSession
+	cleared := app.sessionCleared
+	app.sessionMu.Unlock()
+
+	if app.sessionSerializer == nil || cleared {
+		return
+	}
+
+	if session == nil {
+		session = models.CaptureSession(app.configRepo, "", models.SessionViewState{})
+	}
+
+	if err := app.sessionSerializer.Save(session); err != nil {
+		app.logger.Error("Failed to save session", err, map[string]interface{}{
+			"path": app.sessionSerializer.Path(),
+		})
+		return
+	}
+
+	app.logger.Info("Session saved", map[string]interface{}{
+		"path": app.sessionSerializer.Path(),
+	})
+}
+
+// clearSession deletes the session file and disables saving for this run
+func (app *Application) clearSession() {
+	if app.sessionSerializer == nil {
+		return
+	}
+
+	app.sessionMu.Lock()
+	app.sessionCleared = true
+	app.sessionMu.Unlock()
+
+	if err := app.sessionSerializer.Clear(); err != nil {
+		app.view.ShowError("Clear Session", err)
+		return
+	}
+
+	app.view.UpdateStatus("Session cleared")
+}
+
 // calculateResponsiveWindowSize determines appropriate window size
 func calculateResponsiveWindowSize() fyne.Size {
 	baseWidth := float32(1200)
@@ -407,3 +672,19 @@ func determineLogLevel() logger.LogLevel {
 		return logger.InfoLevel
 	}
 }
+
+// determineLogFormat returns "json" when LOG_FORMAT=json, otherwise "text"
+func determineLogFormat() string {
+	if os.Getenv("LOG_FORMAT") == "json" {
+		return "json"
+	}
+	return "text"
+}
+
+// newAppLogger creates the logger for the configured output format
+func newAppLogger(level logger.LogLevel) logger.Logger {
+	if determineLogFormat() == "json" {
+		return logger.NewJSONLogger(level, os.Stdout)
+	}
+	return logger.NewStructuredLogger(level)
+}
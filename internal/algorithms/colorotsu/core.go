@@ -0,0 +1,276 @@
+package colorotsu
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+
+	"gocv.io/x/gocv"
+)
+
+// maxHue is the largest hue OpenCV stores in an 8-bit HSV image
+const maxHue = 179
+
+// channelIndex maps color_threshold_channel onto the HSV channel it selects
+var channelIndex = map[string]int{"H": 0, "S": 1, "V": 2}
+
+// ColorThresholdProcessor segments colour images in HSV space. Pixels whose
+// hue lies in [lower_hue, upper_hue] are candidates; the Otsu threshold of the
+// selected channel over the candidates splits them into foreground and
+// background, and everything outside the hue range is background.
+type ColorThresholdProcessor struct {
+	name       string
+	workerPool chan struct{}
+}
+
+func NewProcessor() *ColorThresholdProcessor {
+	// Create worker pool for parallel processing
+	workers := make(chan struct{}, runtime.NumCPU())
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers <- struct{}{}
+	}
+
+	return &ColorThresholdProcessor{
+		name:       "HSV Otsu",
+		workerPool: workers,
+	}
+}
+
+func (p *ColorThresholdProcessor) GetName() string {
+	return p.name
+}
+
+func (p *ColorThresholdProcessor) GetDefaultParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"color_threshold_channel": "S",
+		"lower_hue":               0,
+		"upper_hue":               maxHue,
+	}
+}
+
+func (p *ColorThresholdProcessor) ValidateParameters(params map[string]interface{}) error {
+	if channel, ok := params["color_threshold_channel"].(string); ok {
+		if _, valid := channelIndex[channel]; !valid {
+			return fmt.Errorf("color_threshold_channel must be one of: H, S, V, got: %s", channel)
+		}
+	}
+
+	lower := p.getIntParam(params, "lower_hue", 0)
+	upper := p.getIntParam(params, "upper_hue", maxHue)
+	if lower < 0 || lower > maxHue {
+		return fmt.Errorf("lower_hue must be between 0 and %d, got: %d", maxHue, lower)
+	}
+	if upper < 0 || upper > maxHue {
+		return fmt.Errorf("upper_hue must be between 0 and %d, got: %d", maxHue, upper)
+	}
+	if lower > upper {
+		return fmt.Errorf("lower_hue (%d) must not exceed upper_hue (%d)", lower, upper)
+	}
+
+	return nil
+}
+
+func (p *ColorThresholdProcessor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return p.ProcessWithContext(context.Background(), input, params)
+}
+
+func (p *ColorThresholdProcessor) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(input, "HSV Otsu processing"); err != nil {
+		return nil, err
+	}
+
+	if err := p.ValidateParameters(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	// Acquire worker from pool
+	select {
+	case <-p.workerPool:
+		defer func() { p.workerPool <- struct{}{} }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return p.processColorThreshold(ctx, input, params)
+}
+
+// processColorThreshold segments input and records the threshold and
+// candidate fraction of the run with processing.RecordStatistics
+func (p *ColorThresholdProcessor) processColorThreshold(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	hsv, err := p.convertToHSV(input)
+	if err != nil {
+		return nil, fmt.Errorf("HSV conversion failed: %w", err)
+	}
+	defer hsv.Close()
+
+	channelName := p.getStringParam(params, "color_threshold_channel", "S")
+	channel := channelIndex[channelName]
+	lower := p.getIntParam(params, "lower_hue", 0)
+	upper := p.getIntParam(params, "upper_hue", maxHue)
+
+	histogram, candidates, err := p.buildHistogram(ctx, hsv, channel, lower, upper)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := p.calculateOtsuThreshold(histogram)
+
+	total := hsv.Rows() * hsv.Cols()
+	processing.RecordStatistics(ctx, map[string]interface{}{
+		"threshold":          threshold,
+		"channel":            channelName,
+		"candidate_fraction": float64(candidates) / float64(total),
+	})
+
+	return p.applyThreshold(ctx, hsv, channel, lower, upper, threshold)
+}
+
+// convertToHSV returns an 8-bit HSV copy of a BGR or BGRA image
+func (p *ColorThresholdProcessor) convertToHSV(src *safe.Mat) (*safe.Mat, error) {
+	dst, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC3)
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := src.GetMat()
+	dstMat := dst.GetMat()
+
+	switch src.Channels() {
+	case 3:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRToHSV)
+	case 4:
+		bgr := gocv.NewMat()
+		defer bgr.Close()
+		gocv.CvtColor(srcMat, &bgr, gocv.ColorBGRAToBGR)
+		gocv.CvtColor(bgr, &dstMat, gocv.ColorBGRToHSV)
+	default:
+		dst.Close()
+		return nil, fmt.Errorf("HSV thresholding requires a colour image, got %d channel(s)", src.Channels())
+	}
+
+	return dst, nil
+}
+
+// inHueRange reports whether the pixel at (y, x) is a candidate
+func (p *ColorThresholdProcessor) inHueRange(hsv *safe.Mat, y, x, lower, upper int) bool {
+	hue, err := hsv.GetUCharAt3(y, x, 0)
+	return err == nil && int(hue) >= lower && int(hue) <= upper
+}
+
+// buildHistogram counts the selected channel over the pixels in the hue range
+// and returns the histogram with the number of candidates
+func (p *ColorThresholdProcessor) buildHistogram(ctx context.Context, hsv *safe.Mat, channel, lower, upper int) ([]float64, int, error) {
+	histogram := make([]float64, 256)
+	candidates := 0
+	rows := hsv.Rows()
+	cols := hsv.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if !p.inHueRange(hsv, y, x, lower, upper) {
+				continue
+			}
+			if val, err := hsv.GetUCharAt3(y, x, channel); err == nil {
+				histogram[val]++
+				candidates++
+			}
+		}
+	}
+
+	return histogram, candidates, nil
+}
+
+// calculateOtsuThreshold returns the level maximising between-class variance,
+// 0 for an empty histogram
+func (p *ColorThresholdProcessor) calculateOtsuThreshold(histogram []float64) float64 {
+	total := 0.0
+	sum := 0.0
+	for i, h := range histogram {
+		total += h
+		sum += float64(i) * h
+	}
+	if total == 0 {
+		return 0
+	}
+
+	var weightBg, sumBg, maxVariance float64
+	threshold := 0
+
+	for t, h := range histogram {
+		weightBg += h
+		if weightBg == 0 {
+			continue
+		}
+		weightFg := total - weightBg
+		if weightFg == 0 {
+			break
+		}
+
+		sumBg += float64(t) * h
+		meanBg := sumBg / weightBg
+		meanFg := (sum - sumBg) / weightFg
+
+		variance := weightBg * weightFg * (meanBg - meanFg) * (meanBg - meanFg)
+		if variance > maxVariance {
+			maxVariance = variance
+			threshold = t
+		}
+	}
+
+	return float64(threshold)
+}
+
+// applyThreshold marks candidates whose channel value exceeds threshold
+func (p *ColorThresholdProcessor) applyThreshold(ctx context.Context, hsv *safe.Mat, channel, lower, upper int, threshold float64) (*safe.Mat, error) {
+	result, err := safe.NewMat(hsv.Rows(), hsv.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := hsv.Rows()
+	cols := hsv.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Close()
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if !p.inHueRange(hsv, y, x, lower, upper) {
+				continue
+			}
+			if val, err := hsv.GetUCharAt3(y, x, channel); err == nil && float64(val) > threshold {
+				result.SetUCharAt(y, x, 255)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Helper functions
+func (p *ColorThresholdProcessor) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
+	if value, ok := params[key].(int); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p *ColorThresholdProcessor) getStringParam(params map[string]interface{}, key string, defaultValue string) string {
+	if value, ok := params[key].(string); ok {
+		return value
+	}
+	return defaultValue
+}
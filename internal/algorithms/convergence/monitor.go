@@ -0,0 +1,147 @@
+package convergence
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// IterationHandler receives the state of an iterative algorithm after each step
+type IterationHandler func(iteration, maxIterations int, value, delta float64)
+
+type handlerKey struct{}
+
+// WithIterationHandler attaches an iteration handler to the context
+func WithIterationHandler(ctx context.Context, handler IterationHandler) context.Context {
+	return context.WithValue(ctx, handlerKey{}, handler)
+}
+
+// IterationHandlerFromContext returns the iteration handler attached to the context, if any
+func IterationHandlerFromContext(ctx context.Context) IterationHandler {
+	if handler, ok := ctx.Value(handlerKey{}).(IterationHandler); ok {
+		return handler
+	}
+	return nil
+}
+
+// ConvergenceRecord is the state of one iteration of an iterative algorithm.
+// TBDFraction is the share of pixels still undecided, or zero when the
+// algorithm has no to-be-determined class.
+type ConvergenceRecord struct {
+	Iteration   int
+	Threshold   float64
+	Delta       float64
+	TBDFraction float64
+}
+
+// IterativeConvergenceMonitor tracks the values produced by an iterative
+// algorithm and decides when successive values are close enough to stop
+type IterativeConvergenceMonitor struct {
+	mu            sync.RWMutex
+	epsilon       float64
+	maxIterations int
+	history       []float64
+	converged     bool
+	handler       IterationHandler
+}
+
+// NewIterativeConvergenceMonitor creates a monitor that converges once
+// |value - previous| < epsilon or maxIterations values have been recorded
+func NewIterativeConvergenceMonitor(epsilon float64, maxIterations int) *IterativeConvergenceMonitor {
+	return &IterativeConvergenceMonitor{
+		epsilon:       epsilon,
+		maxIterations: maxIterations,
+		history:       make([]float64, 0, maxIterations),
+	}
+}
+
+// SetIterationHandler sets the handler notified after each recorded iteration
+func (m *IterativeConvergenceMonitor) SetIterationHandler(handler IterationHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handler = handler
+}
+
+// Record stores the value of the current iteration and reports whether the
+// sequence has converged
+func (m *IterativeConvergenceMonitor) Record(value float64) bool {
+	m.mu.Lock()
+
+	delta := math.Inf(1)
+	if len(m.history) > 0 {
+		delta = math.Abs(value - m.history[len(m.history)-1])
+	}
+
+	m.history = append(m.history, value)
+	iteration := len(m.history)
+
+	if delta < m.epsilon {
+		m.converged = true
+	}
+
+	done := m.converged || iteration >= m.maxIterations
+	handler := m.handler
+	maxIterations := m.maxIterations
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(iteration, maxIterations, value, delta)
+	}
+
+	return done
+}
+
+// Converged reports whether the last recorded delta was below epsilon
+func (m *IterativeConvergenceMonitor) Converged() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.converged
+}
+
+// Iterations returns the number of recorded iterations
+func (m *IterativeConvergenceMonitor) Iterations() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.history)
+}
+
+// History returns a copy of the recorded values
+func (m *IterativeConvergenceMonitor) History() []float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]float64, len(m.history))
+	copy(result, m.history)
+	return result
+}
+
+// LastValue returns the most recently recorded value
+func (m *IterativeConvergenceMonitor) LastValue() (float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.history) == 0 {
+		return 0, false
+	}
+	return m.history[len(m.history)-1], true
+}
+
+// Progress returns the fraction of the iteration budget used so far
+func (m *IterativeConvergenceMonitor) Progress() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.converged || m.maxIterations <= 0 {
+		return 1.0
+	}
+	return math.Min(1.0, float64(len(m.history))/float64(m.maxIterations))
+}
+
+// Reset clears the recorded history
+func (m *IterativeConvergenceMonitor) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.history = m.history[:0]
+	m.converged = false
+}
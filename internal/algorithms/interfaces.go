@@ -3,6 +3,7 @@ package algorithms
 import (
 	"context"
 
+	"otsu-obliterator/internal/logger"
 	"otsu-obliterator/internal/opencv/safe"
 )
 
@@ -19,3 +20,8 @@ type ContextualAlgorithm interface {
 	Algorithm
 	ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error)
 }
+
+// LoggingAlgorithm is implemented by algorithms that write diagnostic logs
+type LoggingAlgorithm interface {
+	SetLogger(log logger.Logger)
+}
@@ -1,11 +1,18 @@
 package algorithms
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 
+	"otsu-obliterator/internal/algorithms/colorotsu"
+	"otsu-obliterator/internal/algorithms/mce"
+	"otsu-obliterator/internal/algorithms/multilevel"
 	"otsu-obliterator/internal/algorithms/otsu"
+	"otsu-obliterator/internal/algorithms/plugin"
+	"otsu-obliterator/internal/algorithms/ridler"
 	"otsu-obliterator/internal/algorithms/triclass"
+	"otsu-obliterator/internal/logger"
 )
 
 type Manager struct {
@@ -23,22 +30,70 @@ func NewManager() *Manager {
 	}
 
 	manager.registerAlgorithms()
-	manager.initializeDefaultParameters()
 
 	return manager
 }
 
 func (m *Manager) registerAlgorithms() {
-	otsuAlg := otsu.NewProcessor()
-	triclassAlg := triclass.NewProcessor()
+	for _, algorithm := range []Algorithm{
+		otsu.NewProcessor(),
+		triclass.NewProcessor(),
+		ridler.NewProcessor(),
+		mce.NewProcessor(),
+		multilevel.NewProcessor(),
+		colorotsu.NewProcessor(),
+	} {
+		m.Register(algorithm.GetName(), algorithm)
+	}
+}
+
+// Register adds alg under name with its default parameters, replacing any
+// algorithm already registered under that name
+func (m *Manager) Register(name string, alg Algorithm) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	m.algorithms[otsuAlg.GetName()] = otsuAlg
-	m.algorithms[triclassAlg.GetName()] = triclassAlg
+	m.algorithms[name] = alg
+	m.parameters[name] = alg.GetDefaultParameters()
 }
 
-func (m *Manager) initializeDefaultParameters() {
-	for name, algorithm := range m.algorithms {
-		m.parameters[name] = algorithm.GetDefaultParameters()
+// RegisterPlugins loads plugin algorithms from dir and returns the names of
+// those registered. Plugins whose name is already taken are skipped.
+func (m *Manager) RegisterPlugins(dir string) ([]string, error) {
+	plugins, loadErr := plugin.LoadDir(dir)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	errs := []error{loadErr}
+	var names []string
+	for _, p := range plugins {
+		adapter := plugin.NewAdapter(p)
+		name := adapter.GetName()
+		if _, exists := m.algorithms[name]; exists {
+			errs = append(errs, fmt.Errorf("plugin algorithm %q conflicts with an existing algorithm", name))
+			continue
+		}
+
+		m.algorithms[name] = adapter
+		m.parameters[name] = adapter.GetDefaultParameters()
+		names = append(names, name)
+	}
+
+	return names, errors.Join(errs...)
+}
+
+// SetLogger passes the logger to every registered algorithm that logs. In
+// release builds their Debug calls are dropped.
+func (m *Manager) SetLogger(log logger.Logger) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	diagnostic := logger.Diagnostic(log)
+	for _, algorithm := range m.algorithms {
+		if logging, ok := algorithm.(LoggingAlgorithm); ok {
+			logging.SetLogger(diagnostic)
+		}
 	}
 }
 
@@ -0,0 +1,400 @@
+package mce
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+	"otsu-obliterator/internal/processing/filters"
+
+	"gocv.io/x/gocv"
+)
+
+// MinCrossEntropyProcessor implements Li & Lee minimum cross-entropy thresholding
+type MinCrossEntropyProcessor struct {
+	name       string
+	workerPool chan struct{}
+}
+
+// QualityMetrics describes the threshold found by a processing run. It is
+// recorded as the quality_metrics statistic of the run.
+type QualityMetrics struct {
+	Threshold          float64
+	CrossEntropy       float64
+	Iterations         int
+	BackgroundMean     float64
+	ForegroundMean     float64
+	ClassSeparation    float64
+	ForegroundFraction float64
+}
+
+func NewProcessor() *MinCrossEntropyProcessor {
+	// Create worker pool for parallel processing
+	workers := make(chan struct{}, runtime.NumCPU())
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers <- struct{}{}
+	}
+
+	return &MinCrossEntropyProcessor{
+		name:       "Minimum Cross-Entropy",
+		workerPool: workers,
+	}
+}
+
+func (p *MinCrossEntropyProcessor) GetName() string {
+	return p.name
+}
+
+func (p *MinCrossEntropyProcessor) GetDefaultParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"histogram_bins": 256,
+		"apply_clahe":    false,
+		"max_iterations": 50,
+		"quality":        "Best",
+	}
+}
+
+func (p *MinCrossEntropyProcessor) ValidateParameters(params map[string]interface{}) error {
+	if histBins, ok := params["histogram_bins"].(int); ok {
+		if histBins < 8 || histBins > 256 {
+			return fmt.Errorf("histogram_bins must be between 8 and 256, got: %d", histBins)
+		}
+	}
+
+	if maxIter, ok := params["max_iterations"].(int); ok {
+		if maxIter < 1 || maxIter > 500 {
+			return fmt.Errorf("max_iterations must be between 1 and 500, got: %d", maxIter)
+		}
+	}
+
+	if quality, ok := params["quality"].(string); ok {
+		if quality != "Fast" && quality != "Best" {
+			return fmt.Errorf("quality must be one of: Fast, Best, got: %s", quality)
+		}
+	}
+
+	return nil
+}
+
+func (p *MinCrossEntropyProcessor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return p.ProcessWithContext(context.Background(), input, params)
+}
+
+func (p *MinCrossEntropyProcessor) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(input, "Minimum Cross-Entropy processing"); err != nil {
+		return nil, err
+	}
+
+	if err := p.ValidateParameters(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	// Acquire worker from pool
+	select {
+	case <-p.workerPool:
+		defer func() { p.workerPool <- struct{}{} }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return p.processMinCrossEntropy(ctx, input, params)
+}
+
+func (p *MinCrossEntropyProcessor) processMinCrossEntropy(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	working, err := p.applyPreprocessing(ctx, input, params)
+	if err != nil {
+		return nil, fmt.Errorf("preprocessing failed: %w", err)
+	}
+	defer working.Close()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	histBins := p.getIntParam(params, "histogram_bins", 256)
+	histogram, err := p.buildHistogram(ctx, working, histBins)
+	if err != nil {
+		return nil, err
+	}
+
+	var bin, iterations int
+	switch p.getStringParam(params, "quality", "Best") {
+	case "Fast":
+		bin, iterations = p.findThresholdIterative(histogram, p.getIntParam(params, "max_iterations", 50))
+	default:
+		bin = p.findThresholdExhaustive(histogram)
+		iterations = len(histogram) - 1
+	}
+
+	binWidth := 256.0 / float64(histBins)
+	threshold := float64(bin+1)*binWidth - 1.0
+
+	metrics := p.calculateQualityMetrics(histogram, bin, binWidth)
+	metrics.Threshold = threshold
+	metrics.Iterations = iterations
+
+	processing.RecordStatistics(ctx, map[string]interface{}{
+		"quality_metrics": metrics,
+		"threshold":       threshold,
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return p.applyThreshold(ctx, working, threshold)
+}
+
+func (p *MinCrossEntropyProcessor) applyPreprocessing(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	grayscale, err := p.convertToGrayscale(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.getBoolParam(params, "apply_clahe", false) {
+		return grayscale, nil
+	}
+	defer grayscale.Close()
+
+	return filters.NewCLAHEFilter().Apply(ctx, grayscale, params)
+}
+
+func (p *MinCrossEntropyProcessor) convertToGrayscale(src *safe.Mat) (*safe.Mat, error) {
+	if src.Channels() == 1 {
+		return src.Clone()
+	}
+
+	dst, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := src.GetMat()
+	dstMat := dst.GetMat()
+
+	switch src.Channels() {
+	case 3:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRToGray)
+	case 4:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRAToGray)
+	default:
+		dst.Close()
+		return nil, fmt.Errorf("unsupported channel count: %d", src.Channels())
+	}
+
+	return dst, nil
+}
+
+func (p *MinCrossEntropyProcessor) buildHistogram(ctx context.Context, src *safe.Mat, histBins int) ([]float64, error) {
+	histogram := make([]float64, histBins)
+	rows := src.Rows()
+	cols := src.Cols()
+	binScale := float64(histBins) / 256.0
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if val, err := src.GetUCharAt(y, x); err == nil {
+				histogram[int(float64(val)*binScale)]++
+			}
+		}
+	}
+
+	return histogram, nil
+}
+
+// crossEntropy evaluates C(t) for a split after bin t. Intensities are offset by
+// one so that the logarithm stays defined for black pixels.
+func (p *MinCrossEntropyProcessor) crossEntropy(histogram []float64, t int) float64 {
+	var countBg, momentBg, countFg, momentFg float64
+
+	for i, h := range histogram {
+		value := float64(i + 1)
+		if i <= t {
+			countBg += h
+			momentBg += value * h
+		} else {
+			countFg += h
+			momentFg += value * h
+		}
+	}
+
+	if countBg == 0 || countFg == 0 {
+		return math.Inf(1)
+	}
+
+	muBg := momentBg / countBg
+	muFg := momentFg / countFg
+
+	return -momentBg*math.Log(muBg) - momentFg*math.Log(muFg)
+}
+
+// findThresholdExhaustive evaluates the objective at every bin boundary
+func (p *MinCrossEntropyProcessor) findThresholdExhaustive(histogram []float64) int {
+	bestBin := len(histogram) / 2
+	bestCost := math.Inf(1)
+
+	for t := 0; t < len(histogram)-1; t++ {
+		if cost := p.crossEntropy(histogram, t); cost < bestCost {
+			bestCost = cost
+			bestBin = t
+		}
+	}
+
+	return bestBin
+}
+
+// findThresholdIterative uses Li's iterative scheme, which is a Newton-Raphson
+// step on the derivative of the objective:
+// t_next = (mu_fg - mu_bg) / (ln mu_fg - ln mu_bg)
+func (p *MinCrossEntropyProcessor) findThresholdIterative(histogram []float64, maxIterations int) (int, int) {
+	total := 0.0
+	moment := 0.0
+	for i, h := range histogram {
+		total += h
+		moment += float64(i+1) * h
+	}
+
+	if total == 0 {
+		return len(histogram) / 2, 0
+	}
+
+	threshold := moment / total
+	iterations := 0
+
+	for iterations < maxIterations {
+		iterations++
+
+		split := int(math.Round(threshold)) - 1
+		var countBg, momentBg, countFg, momentFg float64
+		for i, h := range histogram {
+			value := float64(i + 1)
+			if i <= split {
+				countBg += h
+				momentBg += value * h
+			} else {
+				countFg += h
+				momentFg += value * h
+			}
+		}
+
+		if countBg == 0 || countFg == 0 {
+			break
+		}
+
+		muBg := momentBg / countBg
+		muFg := momentFg / countFg
+		if muFg == muBg {
+			break
+		}
+
+		next := (muFg - muBg) / (math.Log(muFg) - math.Log(muBg))
+		if math.Abs(next-threshold) < 0.5 {
+			threshold = next
+			break
+		}
+		threshold = next
+	}
+
+	bin := int(math.Round(threshold)) - 1
+	if bin < 0 {
+		bin = 0
+	}
+	if bin > len(histogram)-2 {
+		bin = len(histogram) - 2
+	}
+
+	return bin, iterations
+}
+
+func (p *MinCrossEntropyProcessor) calculateQualityMetrics(histogram []float64, t int, binWidth float64) QualityMetrics {
+	var countBg, sumBg, countFg, sumFg float64
+	for i, h := range histogram {
+		center := (float64(i) + 0.5) * binWidth
+		if i <= t {
+			countBg += h
+			sumBg += center * h
+		} else {
+			countFg += h
+			sumFg += center * h
+		}
+	}
+
+	metrics := QualityMetrics{
+		CrossEntropy: p.crossEntropy(histogram, t),
+	}
+
+	if countBg > 0 {
+		metrics.BackgroundMean = sumBg / countBg
+	}
+	if countFg > 0 {
+		metrics.ForegroundMean = sumFg / countFg
+	}
+	if total := countBg + countFg; total > 0 {
+		metrics.ForegroundFraction = countFg / total
+	}
+	metrics.ClassSeparation = math.Abs(metrics.ForegroundMean-metrics.BackgroundMean) / 255.0
+
+	return metrics
+}
+
+func (p *MinCrossEntropyProcessor) applyThreshold(ctx context.Context, src *safe.Mat, threshold float64) (*safe.Mat, error) {
+	result, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := src.Rows()
+	cols := src.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Close()
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if val, err := src.GetUCharAt(y, x); err == nil && float64(val) > threshold {
+				result.SetUCharAt(y, x, 255)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Helper functions
+func (p *MinCrossEntropyProcessor) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
+	if value, ok := params[key].(int); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p *MinCrossEntropyProcessor) getStringParam(params map[string]interface{}, key string, defaultValue string) string {
+	if value, ok := params[key].(string); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p *MinCrossEntropyProcessor) getBoolParam(params map[string]interface{}, key string, defaultValue bool) bool {
+	if value, ok := params[key].(bool); ok {
+		return value
+	}
+	return defaultValue
+}
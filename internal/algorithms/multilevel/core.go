@@ -0,0 +1,283 @@
+package multilevel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+
+	"gocv.io/x/gocv"
+)
+
+// MultiLevelOtsuProcessor separates an image into N intensity classes using
+// N-1 Otsu thresholds
+type MultiLevelOtsuProcessor struct {
+	name       string
+	workerPool chan struct{}
+}
+
+func NewProcessor() *MultiLevelOtsuProcessor {
+	// Create worker pool for parallel processing
+	workers := make(chan struct{}, runtime.NumCPU())
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers <- struct{}{}
+	}
+
+	return &MultiLevelOtsuProcessor{
+		name:       "Multi-Level Otsu",
+		workerPool: workers,
+	}
+}
+
+func (p *MultiLevelOtsuProcessor) GetName() string {
+	return p.name
+}
+
+func (p *MultiLevelOtsuProcessor) GetDefaultParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"num_classes": 3,
+	}
+}
+
+func (p *MultiLevelOtsuProcessor) ValidateParameters(params map[string]interface{}) error {
+	if numClasses, ok := params["num_classes"].(int); ok {
+		if numClasses < 2 || numClasses > 5 {
+			return fmt.Errorf("num_classes must be between 2 and 5, got: %d", numClasses)
+		}
+	}
+
+	return nil
+}
+
+func (p *MultiLevelOtsuProcessor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return p.ProcessWithContext(context.Background(), input, params)
+}
+
+func (p *MultiLevelOtsuProcessor) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(input, "Multi-Level Otsu processing"); err != nil {
+		return nil, err
+	}
+
+	if err := p.ValidateParameters(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	// Acquire worker from pool
+	select {
+	case <-p.workerPool:
+		defer func() { p.workerPool <- struct{}{} }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return p.processMultiLevel(ctx, input, params)
+}
+
+// processMultiLevel segments input and records the thresholds of the run
+// with processing.RecordStatistics
+func (p *MultiLevelOtsuProcessor) processMultiLevel(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	grayscale, err := p.convertToGrayscale(input)
+	if err != nil {
+		return nil, fmt.Errorf("grayscale conversion failed: %w", err)
+	}
+	defer grayscale.Close()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	numClasses := p.getIntParam(params, "num_classes", 3)
+	histogram, err := p.buildHistogram(ctx, grayscale)
+	if err != nil {
+		return nil, err
+	}
+	thresholds := FindThresholds(histogram, numClasses)
+
+	processing.RecordStatistics(ctx, map[string]interface{}{
+		"thresholds": thresholds,
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	return p.applyThresholds(ctx, grayscale, thresholds)
+}
+
+// FindThresholds returns the numClasses-1 thresholds that maximise the
+// between-class variance of a 256 bin histogram. The search uses the recursive
+// decomposition best(k, t) = max over s of best(k-1, s) + score(s+1, t), which
+// is evaluated bottom-up so every prefix is scored once.
+func FindThresholds(histogram []int, numClasses int) []uint8 {
+	levels := len(histogram)
+	if numClasses < 2 || levels < numClasses {
+		return nil
+	}
+
+	// Cumulative zeroth and first order moments
+	cumCount := make([]float64, levels+1)
+	cumSum := make([]float64, levels+1)
+	for i, count := range histogram {
+		cumCount[i+1] = cumCount[i] + float64(count)
+		cumSum[i+1] = cumSum[i] + float64(i)*float64(count)
+	}
+
+	// score of class covering bins [from, to] is w * mu^2 = S^2 / P
+	score := func(from, to int) float64 {
+		weight := cumCount[to+1] - cumCount[from]
+		if weight <= 0 {
+			return 0
+		}
+		sum := cumSum[to+1] - cumSum[from]
+		return sum * sum / weight
+	}
+
+	// best[k][t] is the maximal score when bins [0, t] form k+1 classes
+	best := make([][]float64, numClasses)
+	split := make([][]int, numClasses)
+	for k := range best {
+		best[k] = make([]float64, levels)
+		split[k] = make([]int, levels)
+		for t := range best[k] {
+			best[k][t] = math.Inf(-1)
+		}
+	}
+
+	for t := 0; t < levels; t++ {
+		best[0][t] = score(0, t)
+	}
+
+	for k := 1; k < numClasses; k++ {
+		for t := k; t < levels; t++ {
+			for prev := k - 1; prev < t; prev++ {
+				candidate := best[k-1][prev] + score(prev+1, t)
+				if candidate > best[k][t] {
+					best[k][t] = candidate
+					split[k][t] = prev
+				}
+			}
+		}
+	}
+
+	thresholds := make([]uint8, numClasses-1)
+	t := levels - 1
+	for k := numClasses - 1; k > 0; k-- {
+		t = split[k][t]
+		thresholds[k-1] = uint8(t)
+	}
+
+	return thresholds
+}
+
+// ClassValue returns the quantised gray value used for class index in an
+// image of numClasses classes, e.g. 0, 64, 128, 192, 255 for 5 classes
+func ClassValue(class, numClasses int) uint8 {
+	if numClasses < 2 {
+		return 0
+	}
+	value := class * 256 / (numClasses - 1)
+	if value > 255 {
+		value = 255
+	}
+	return uint8(value)
+}
+
+func (p *MultiLevelOtsuProcessor) applyThresholds(ctx context.Context, src *safe.Mat, thresholds []uint8) (*safe.Mat, error) {
+	result, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	numClasses := len(thresholds) + 1
+	lookup := make([]uint8, 256)
+	class := 0
+	for i := range lookup {
+		for class < len(thresholds) && i > int(thresholds[class]) {
+			class++
+		}
+		lookup[i] = ClassValue(class, numClasses)
+	}
+
+	rows := src.Rows()
+	cols := src.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Close()
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if val, err := src.GetUCharAt(y, x); err == nil {
+				result.SetUCharAt(y, x, lookup[val])
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (p *MultiLevelOtsuProcessor) buildHistogram(ctx context.Context, src *safe.Mat) ([]int, error) {
+	histogram := make([]int, 256)
+	rows := src.Rows()
+	cols := src.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if val, err := src.GetUCharAt(y, x); err == nil {
+				histogram[val]++
+			}
+		}
+	}
+
+	return histogram, nil
+}
+
+func (p *MultiLevelOtsuProcessor) convertToGrayscale(src *safe.Mat) (*safe.Mat, error) {
+	if src.Channels() == 1 {
+		return src.Clone()
+	}
+
+	dst, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := src.GetMat()
+	dstMat := dst.GetMat()
+
+	switch src.Channels() {
+	case 3:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRToGray)
+	case 4:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRAToGray)
+	default:
+		dst.Close()
+		return nil, fmt.Errorf("unsupported channel count: %d", src.Channels())
+	}
+
+	return dst, nil
+}
+
+// Helper functions
+func (p *MultiLevelOtsuProcessor) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
+	if value, ok := params[key].(int); ok {
+		return value
+	}
+	return defaultValue
+}
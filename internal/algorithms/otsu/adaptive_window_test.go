@@ -0,0 +1,89 @@
+package otsu
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// mixedTextureImage returns a square image whose left half is flat grey with
+// light noise and whose right half is a 4 pixel checkerboard of 60 and 200
+func mixedTextureImage(tb testing.TB, side int) *safe.Mat {
+	tb.Helper()
+
+	img := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(100, 0, 0, 0), side, side, gocv.MatTypeCV8UC1)
+	defer img.Close()
+	for y := 0; y < side; y++ {
+		for x := side / 2; x < side; x++ {
+			if (x/4+y/4)%2 == 0 {
+				img.SetUCharAt(y, x, 60)
+			} else {
+				img.SetUCharAt(y, x, 200)
+			}
+		}
+	}
+
+	noise := gocv.NewMatWithSize(side, side, gocv.MatTypeCV8UC1)
+	defer noise.Close()
+	gocv.RandU(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(8, 0, 0, 0))
+	if err := gocv.Add(img, noise, &img); err != nil {
+		tb.Fatal(err)
+	}
+
+	mat, err := safe.NewMatFromMat(img)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { mat.Close() })
+	return mat
+}
+
+func TestAdaptiveWindowMapFollowsTexture(t *testing.T) {
+	const side = 128
+
+	windowMap := computeAdaptiveWindowMap(mixedTextureImage(t, side))
+
+	for _, p := range []image.Point{{X: 10, Y: 10}, {X: side/2 - 1, Y: side - 1}} {
+		if got := windowMap[p.Y][p.X]; got != adaptiveWindowMax {
+			t.Errorf("smooth pixel %v: window %d, want %d", p, got, adaptiveWindowMax)
+		}
+	}
+	for _, p := range []image.Point{{X: side / 2, Y: 0}, {X: side - 1, Y: side - 1}} {
+		if got := windowMap[p.Y][p.X]; got != adaptiveWindowMin {
+			t.Errorf("textured pixel %v: window %d, want %d", p, got, adaptiveWindowMin)
+		}
+	}
+}
+
+// BenchmarkFixedVsAdaptiveWindow times 2D Otsu on a 1024x1024 image that is
+// half smooth and half textured, with the default window_size and with
+// adaptive_window_size
+func BenchmarkFixedVsAdaptiveWindow(b *testing.B) {
+	input := mixedTextureImage(b, 1024)
+	p := NewProcessor()
+
+	for _, adaptive := range []bool{false, true} {
+		name := "Fixed"
+		if adaptive {
+			name = "Adaptive"
+		}
+
+		params := p.GetDefaultParameters()
+		params["adaptive_window_size"] = adaptive
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				result, err := p.ProcessWithContext(context.Background(), input, params)
+				if err != nil {
+					b.Fatal(err)
+				}
+				result.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package otsu
+
+import (
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// noisyImage returns a mid-grey image with Gaussian noise of standard
+// deviation sigma
+func noisyImage(t *testing.T, sigma float64) *safe.Mat {
+	t.Helper()
+
+	img := gocv.NewMatWithSize(256, 256, gocv.MatTypeCV8UC1)
+	defer img.Close()
+	gocv.RandN(&img, gocv.NewScalar(128, 0, 0, 0), gocv.NewScalar(sigma, 0, 0, 0))
+
+	mat, err := safe.NewMatFromMat(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mat.Close() })
+	return mat
+}
+
+func TestAutoCLAHEClipLimitFallsWithNoise(t *testing.T) {
+	gocv.SetRNGSeed(1)
+
+	// Noise from heavy to light; the SNR of 128/sigma stays inside the
+	// mapped range, so every step must lower the clip limit
+	sigmas := []float64{30, 20, 10, 5, 2}
+
+	previous := maxAutoClipLimit + 1
+	for _, sigma := range sigmas {
+		img := noisyImage(t, sigma)
+
+		noise := estimateNoiseLevel(img)
+		if noise < sigma*0.8 || noise > sigma*1.2 {
+			t.Errorf("sigma %g: estimated noise %.2f, want within 20%%", sigma, noise)
+		}
+
+		limit := autoCLAHEClipLimit(estimateSNR(img))
+		if limit < minAutoClipLimit || limit > maxAutoClipLimit {
+			t.Errorf("sigma %g: clip limit %.3f outside [%g, %g]", sigma, limit, minAutoClipLimit, maxAutoClipLimit)
+		}
+		if limit >= previous {
+			t.Errorf("sigma %g: clip limit %.3f, want below %.3f of the noisier image", sigma, limit, previous)
+		}
+		previous = limit
+	}
+}
+
+func TestAutoCLAHEClipLimitBounds(t *testing.T) {
+	tests := []struct {
+		snr  float64
+		want float64
+	}{
+		{snr: 0.5, want: maxAutoClipLimit},
+		{snr: lowSNR, want: maxAutoClipLimit},
+		{snr: 10, want: (maxAutoClipLimit + minAutoClipLimit) / 2},
+		{snr: highSNR, want: minAutoClipLimit},
+		{snr: 1000, want: minAutoClipLimit},
+	}
+
+	for _, tt := range tests {
+		if got := autoCLAHEClipLimit(tt.snr); got != tt.want {
+			t.Errorf("autoCLAHEClipLimit(%g) = %g, want %g", tt.snr, got, tt.want)
+		}
+	}
+}
@@ -5,21 +5,33 @@ import (
 	"fmt"
 	"image"
 	"runtime"
+	"strings"
 	"sync"
 
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/cuda"
 	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
 	"otsu-obliterator/internal/processing/filters"
 	"otsu-obliterator/internal/processing/histogram"
+	"otsu-obliterator/internal/processing/labelling"
 	"otsu-obliterator/internal/processing/threshold"
 
+	"go.opentelemetry.io/otel"
 	"gocv.io/x/gocv"
 )
 
+// tracer records the processing stages when tracing is configured
+var tracer = otel.Tracer("otsu-obliterator/internal/algorithms/otsu")
+
 type Processor struct {
-	name       string
-	workerPool chan struct{}
-	matPool    sync.Pool
-	mu         sync.RWMutex
+	name         string
+	workerPool   chan struct{}
+	matPool      sync.Pool
+	gpuHistogram *cuda.CUDAHistogramBuilder
+	logger       logger.Logger
+	mu           sync.RWMutex
 }
 
 func NewProcessor() *Processor {
@@ -30,8 +42,9 @@ func NewProcessor() *Processor {
 	}
 
 	return &Processor{
-		name:       "2D Otsu",
-		workerPool: workers,
+		name:         "2D Otsu",
+		workerPool:   workers,
+		gpuHistogram: cuda.NewCUDAHistogramBuilder(),
 		matPool: sync.Pool{
 			New: func() interface{} {
 				return &matPoolItem{}
@@ -46,34 +59,61 @@ type matPoolItem struct {
 	temporary    *safe.Mat
 }
 
+// SetLogger enables debug logging of automatically chosen parameters
+func (p *Processor) SetLogger(log logger.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = log
+}
+
 func (p *Processor) GetName() string {
 	return p.name
 }
 
 func (p *Processor) GetDefaultParameters() map[string]interface{} {
 	return map[string]interface{}{
-		"window_size":            7,
-		"histogram_bins":         0, // Auto-calculate
-		"smoothing_strength":     1.0,
-		"noise_robustness":       true,
-		"gaussian_preprocessing": true,
-		"use_clahe":              false,
-		"clahe_clip_limit":       3.0,
-		"clahe_tile_size":        8,
-		"guided_filtering":       false,
-		"guided_radius":          4,
-		"guided_epsilon":         0.05,
-		"parallel_processing":    true,
+		"window_size":               7,
+		"adaptive_window_size":      false, // Texture-driven window per region, roughly 3x slower
+		"multi_scale":               false, // Blend means at half, full and double window_size
+		"histogram_bins":            0,     // Auto-calculate
+		"smoothing_strength":        1.0,
+		"noise_robustness":          true,
+		"gaussian_preprocessing":    true,
+		"apply_global_equalization": false, // Mutually exclusive with use_clahe
+		"use_clahe":                 false,
+		"clahe_clip_limit":          3.0, // AutoCLAHEClipLimit picks it from image noise
+		"clahe_tile_size":           8,
+		"apply_unsharp":             false,
+		"unsharp_strength":          0.5,
+		"unsharp_radius":            2,
+		"guided_filtering":          false,
+		"guided_radius":             4,
+		"guided_epsilon":            0.05,
+		"parallel_processing":       true,
+		"channel_selection":         "luminance",
+		"weight_map_path":           "", // Grayscale image weighting each pixel's histogram contribution
 	}
 }
 
 func (p *Processor) ValidateParameters(params map[string]interface{}) error {
+	if channel, ok := params["channel_selection"].(string); ok {
+		if _, err := conversion.NewChannelExtractor(channel); err != nil {
+			return fmt.Errorf("channel_selection must be one of: %s, got: %s", strings.Join(conversion.ChannelOptions, ", "), channel)
+		}
+	}
+
 	if windowSize, ok := params["window_size"].(int); ok {
 		if windowSize < 3 || windowSize > 21 || windowSize%2 == 0 {
 			return fmt.Errorf("window_size must be odd number between 3 and 21, got: %d", windowSize)
 		}
 	}
 
+	multiScale, _ := params["multi_scale"].(bool)
+	adaptive, _ := params["adaptive_window_size"].(bool)
+	if multiScale && adaptive {
+		return fmt.Errorf("multi_scale and adaptive_window_size cannot both be enabled")
+	}
+
 	if histBins, ok := params["histogram_bins"].(int); ok {
 		if histBins != 0 && (histBins < 8 || histBins > 256) {
 			return fmt.Errorf("histogram_bins must be 0 (auto) or between 8 and 256, got: %d", histBins)
@@ -86,7 +126,15 @@ func (p *Processor) ValidateParameters(params map[string]interface{}) error {
 		}
 	}
 
-	return nil
+	if err := threshold.ValidateForcedThreshold(params); err != nil {
+		return err
+	}
+
+	if err := filters.ValidateEqualizationParameters(params); err != nil {
+		return err
+	}
+
+	return filters.ValidateUnsharpParameters(params)
 }
 
 func (p *Processor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
@@ -110,14 +158,47 @@ func (p *Processor) ProcessWithContext(ctx context.Context, input *safe.Mat, par
 		return nil, ctx.Err()
 	}
 
+	// 16-bit input is thresholded at 8 bits
+	depthScale := 1.0
+	if conversion.Is16Bit(input) {
+		scaled, err := conversion.ScaleTo8Bit(input)
+		if err != nil {
+			return nil, fmt.Errorf("16-bit input scaling failed: %w", err)
+		}
+		defer scaled.Close()
+		input = scaled
+		depthScale = conversion.Depth16Scale
+	}
+
+	// Reduce colour input to the selected channel before grayscale conversion
+	channel := string(conversion.ChannelLuminance)
+	if val, ok := params["channel_selection"].(string); ok {
+		channel = val
+	}
+	extractor, err := conversion.NewChannelExtractor(channel)
+	if err != nil {
+		return nil, err
+	}
+	selected, err := extractor.Extract(input)
+	if err != nil {
+		return nil, fmt.Errorf("channel extraction failed: %w", err)
+	}
+	defer selected.Close()
+
 	// Get pooled Mats for intermediate operations
 	poolItem := p.matPool.Get().(*matPoolItem)
 	defer p.matPool.Put(poolItem)
 
-	return p.processInternal(ctx, input, params, poolItem)
+	return p.processInternal(ctx, selected, params, poolItem, depthScale)
 }
 
-func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params map[string]interface{}, poolItem *matPoolItem) (*safe.Mat, error) {
+// processInternal thresholds input and records the run's statistics with
+// processing.RecordStatistics: the joint histogram and the optimal threshold
+// pair in histogram bin units, for 16-bit input the pair as 16-bit
+// intensities in source_threshold, and component_count, the number of
+// objects in the result. depthScale is the factor from 8-bit intensities to
+// those of the original input.
+func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params map[string]interface{}, poolItem *matPoolItem, depthScale float64) (*safe.Mat, error) {
 	// Step 1: Convert to grayscale with context check
 	select {
 	case <-ctx.Done():
@@ -125,11 +206,13 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	default:
 	}
 
+	_, span := tracer.Start(ctx, "grayscale")
 	grayscale, err := p.convertToGrayscale(input)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("grayscale conversion failed: %w", err)
 	}
-	defer grayscale.Close()
+	defer safe.SharedMatPool.Put(grayscale)
 
 	// Step 2: Apply preprocessing pipeline
 	select {
@@ -138,11 +221,13 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	default:
 	}
 
-	preprocessed, err := p.applyPreprocessing(ctx, grayscale, params)
+	preprocessCtx, span := tracer.Start(ctx, "preprocessing")
+	preprocessed, err := p.applyPreprocessing(preprocessCtx, grayscale, params)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("preprocessing failed: %w", err)
 	}
-	defer preprocessed.Close()
+	defer safe.SharedMatPool.Put(preprocessed)
 
 	// Step 3: Calculate neighborhood means
 	select {
@@ -151,11 +236,13 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	default:
 	}
 
-	neighborhood, err := p.calculateNeighborhoodMeans(preprocessed, params)
+	_, span = tracer.Start(ctx, "neighborhood_means")
+	neighborhood, err := p.calculateNeighborhoodMeans(ctx, preprocessed, params)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("neighborhood calculation failed: %w", err)
 	}
-	defer neighborhood.Close()
+	defer safe.SharedMatPool.Put(neighborhood)
 
 	// Step 4: Build 2D histogram
 	select {
@@ -164,8 +251,23 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	default:
 	}
 
-	histogramBuilder := histogram.NewTwoDimensionalBuilder()
-	hist, err := histogramBuilder.Build(preprocessed, neighborhood, params)
+	histParams, releaseWeights, err := p.withWeightMap(params)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseWeights()
+
+	// The GPU builder counts every pixel once, so weighted runs stay on the CPU
+	_, weighted := histParams[histogram.WeightMapParam].(*safe.Mat)
+
+	_, span = tracer.Start(ctx, "histogram")
+	var hist [][]float64
+	if useGPU, ok := params["use_gpu_histogram"].(bool); ok && useGPU && !weighted && p.gpuHistogram.IsAvailable() {
+		hist, err = p.gpuHistogram.Build(ctx, preprocessed, neighborhood, params)
+	} else {
+		hist, err = histogram.NewTwoDimensionalBuilder().Build(ctx, preprocessed, neighborhood, histParams)
+	}
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("histogram calculation failed: %w", err)
 	}
@@ -177,12 +279,31 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	default:
 	}
 
-	thresholdCalc := threshold.NewOtsu2DCalculator()
-	thresholds, err := thresholdCalc.Calculate(hist)
+	// A forced threshold applies to both the pixel and neighborhood axes
+	_, span = tracer.Start(ctx, "threshold_search")
+	var thresholds [2]float64
+	if forced, ok := threshold.ForcedThreshold(params); ok {
+		thresholds = [2]float64{forced, forced}
+	} else {
+		thresholds, err = threshold.NewOtsu2DCalculator().Calculate(hist)
+	}
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("threshold calculation failed: %w", err)
 	}
 
+	stats := map[string]interface{}{
+		"histogram":         hist,
+		"optimal_threshold": thresholds,
+	}
+	if depthScale > 1 {
+		binWidth := 256.0 / float64(len(hist))
+		stats["source_threshold"] = [2]float64{
+			thresholds[0] * binWidth * depthScale,
+			thresholds[1] * binWidth * depthScale,
+		}
+	}
+
 	// Step 6: Apply threshold
 	select {
 	case <-ctx.Done():
@@ -190,7 +311,7 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	default:
 	}
 
-	result, err := p.applyThreshold(preprocessed, neighborhood, thresholds, params)
+	result, err := p.applyThreshold(ctx, preprocessed, neighborhood, thresholds, params)
 	if err != nil {
 		return nil, fmt.Errorf("threshold application failed: %w", err)
 	}
@@ -210,9 +331,67 @@ func (p *Processor) processInternal(ctx context.Context, input *safe.Mat, params
 	}
 	result.Close()
 
+	// Step 8: Count the objects of the result for the quality statistics
+	_, span = tracer.Start(ctx, "connectivity")
+	components := p.analyzeConnectivity(final)
+	span.End()
+
+	if components >= 0 {
+		stats["component_count"] = components
+	}
+	processing.RecordStatistics(ctx, stats)
+
 	return final, nil
 }
 
+// analyzeConnectivity returns the number of 8-connected foreground objects
+// in a binary result, or -1 if it cannot be labelled
+func (p *Processor) analyzeConnectivity(result *safe.Mat) int {
+	_, count, err := labelling.LabelMat(result)
+	if err != nil {
+		p.mu.RLock()
+		log := p.logger
+		p.mu.RUnlock()
+		if log != nil {
+			log.Debug("Connectivity analysis skipped", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return -1
+	}
+	return count - 1
+}
+
+// withWeightMap returns params with the weight map of weight_map_path loaded
+// into histogram.WeightMapParam, and a function releasing it. A weight map
+// the caller already supplied, as the application does, is used as is.
+func (p *Processor) withWeightMap(params map[string]interface{}) (map[string]interface{}, func(), error) {
+	if _, ok := params[histogram.WeightMapParam].(*safe.Mat); ok {
+		return params, func() {}, nil
+	}
+	path, _ := params["weight_map_path"].(string)
+	if path == "" {
+		return params, func() {}, nil
+	}
+
+	loaded := gocv.IMRead(path, gocv.IMReadGrayScale|gocv.IMReadAnyDepth)
+	defer loaded.Close()
+	if loaded.Empty() {
+		return nil, nil, fmt.Errorf("failed to read weight map %s", path)
+	}
+	weights, err := safe.NewMatFromMat(loaded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read weight map %s: %w", path, err)
+	}
+
+	weighted := make(map[string]interface{}, len(params)+1)
+	for key, value := range params {
+		weighted[key] = value
+	}
+	weighted[histogram.WeightMapParam] = weights
+	return weighted, weights.Close, nil
+}
+
 func (p *Processor) convertToGrayscale(src *safe.Mat) (*safe.Mat, error) {
 	if src.Channels() == 1 {
 		return src.Clone()
@@ -246,6 +425,18 @@ func (p *Processor) applyPreprocessing(ctx context.Context, src *safe.Mat, param
 	current := src
 	needsCleanup := false
 
+	// Global equalisation spreads the histogram before noise removal so the
+	// filters see the full intensity range
+	if equalizer := filters.NewGlobalEqualizationPreprocessor(); equalizer.ShouldExecute(params) {
+		equalized, err := equalizer.Apply(ctx, current, params)
+		if err != nil {
+			return nil, err
+		}
+
+		current = equalized
+		needsCleanup = true
+	}
+
 	// Apply noise reduction if enabled
 	if useNoise, ok := params["noise_robustness"].(bool); ok && useNoise {
 		select {
@@ -257,7 +448,7 @@ func (p *Processor) applyPreprocessing(ctx context.Context, src *safe.Mat, param
 		default:
 		}
 
-		filtered, err := p.applyMAOTSUFilter(current)
+		filtered, err := p.applyMAOTSUFilter(ctx, current)
 		if err != nil {
 			if needsCleanup {
 				current.Close()
@@ -298,6 +489,23 @@ func (p *Processor) applyPreprocessing(ctx context.Context, src *safe.Mat, param
 		needsCleanup = true
 	}
 
+	// Sharpen after contrast enhancement so smoothing starts from crisp edges
+	if unsharp := filters.NewUnsharpMaskPreprocessor(); unsharp.ShouldExecute(params) {
+		sharpened, err := unsharp.Apply(ctx, current, params)
+		if err != nil {
+			if needsCleanup {
+				current.Close()
+			}
+			return nil, err
+		}
+
+		if needsCleanup {
+			current.Close()
+		}
+		current = sharpened
+		needsCleanup = true
+	}
+
 	// Apply Gaussian smoothing if enabled
 	if useGaussian, ok := params["gaussian_preprocessing"].(bool); ok && useGaussian {
 		select {
@@ -331,7 +539,7 @@ func (p *Processor) applyPreprocessing(ctx context.Context, src *safe.Mat, param
 	return current, nil
 }
 
-func (p *Processor) applyMAOTSUFilter(src *safe.Mat) (*safe.Mat, error) {
+func (p *Processor) applyMAOTSUFilter(ctx context.Context, src *safe.Mat) (*safe.Mat, error) {
 	// Apply median filter for noise reduction
 	median := gocv.NewMat()
 	defer median.Close()
@@ -354,7 +562,14 @@ func (p *Processor) applyMAOTSUFilter(src *safe.Mat) (*safe.Mat, error) {
 	// Weighted combination: 60% median + 40% gaussian
 	rows := src.Rows()
 	cols := src.Cols()
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Close()
+				return nil, err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			medVal := median.GetUCharAt(y, x)
 			gausVal := gaussian.GetUCharAt(y, x)
@@ -373,6 +588,21 @@ func (p *Processor) applyCLAHE(src *safe.Mat, params map[string]interface{}) (*s
 		clipLimit = val
 	}
 
+	if clipLimit == AutoCLAHEClipLimit {
+		snr := estimateSNR(src)
+		clipLimit = autoCLAHEClipLimit(snr)
+
+		p.mu.RLock()
+		log := p.logger
+		p.mu.RUnlock()
+		if log != nil {
+			log.Debug("Auto CLAHE clip limit selected", map[string]interface{}{
+				"snr":        snr,
+				"clip_limit": clipLimit,
+			})
+		}
+	}
+
 	tileSize := 8
 	if val, ok := params["clahe_tile_size"].(int); ok {
 		tileSize = val
@@ -426,19 +656,26 @@ func (p *Processor) applyGaussianSmoothing(src *safe.Mat, params map[string]inte
 	return result, nil
 }
 
-func (p *Processor) calculateNeighborhoodMeans(src *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+func (p *Processor) calculateNeighborhoodMeans(ctx context.Context, src *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
 	windowSize := 7
 	if val, ok := params["window_size"].(int); ok {
 		windowSize = val
 	}
 
+	if multiScale, ok := params["multi_scale"].(bool); ok && multiScale {
+		return calculateMultiScaleMeans(ctx, src, windowSize)
+	}
+
 	calc := filters.NewNeighborhoodCalculator(windowSize)
-	return calc.Calculate(src)
+	if adaptive, ok := params["adaptive_window_size"].(bool); ok && adaptive {
+		return calc.CalculateAdaptive(ctx, src, computeAdaptiveWindowMap(src))
+	}
+	return calc.Calculate(ctx, src)
 }
 
-func (p *Processor) applyThreshold(src, neighborhood *safe.Mat, thresholds [2]float64, params map[string]interface{}) (*safe.Mat, error) {
+func (p *Processor) applyThreshold(ctx context.Context, src, neighborhood *safe.Mat, thresholds [2]float64, params map[string]interface{}) (*safe.Mat, error) {
 	applier := threshold.NewBilinearApplier()
-	return applier.Apply(src, neighborhood, thresholds)
+	return applier.Apply(ctx, src, neighborhood, thresholds)
 }
 
 func (p *Processor) applyPostprocessing(ctx context.Context, src *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
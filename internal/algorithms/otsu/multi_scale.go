@@ -0,0 +1,104 @@
+package otsu
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing/filters"
+)
+
+// multiScaleWeights weight the fine, configured and coarse window means
+var multiScaleWeights = [3]float64{0.25, 0.5, 0.25}
+
+// multiScaleWindows returns the fine, configured and coarse window sizes for
+// windowSize: half and double it, each rounded up to the next odd size
+func multiScaleWindows(windowSize int) [3]int {
+	return [3]int{toOddWindow(windowSize / 2), windowSize, toOddWindow(windowSize * 2)}
+}
+
+// toOddWindow rounds size up to an odd window of at least 1
+func toOddWindow(size int) int {
+	if size < 1 {
+		return 1
+	}
+	if size%2 == 0 {
+		return size + 1
+	}
+	return size
+}
+
+// calculateMultiScaleMeans blends neighborhood means at the three window sizes
+// of multiScaleWindows, so the histogram's second axis responds to both fine
+// and coarse texture. The means come from summed-area tables, which keeps the
+// coarse window as cheap as the fine one.
+func calculateMultiScaleMeans(ctx context.Context, src *safe.Mat, windowSize int) (*safe.Mat, error) {
+	var means [3]*safe.Mat
+	defer func() {
+		for _, mean := range means {
+			if mean != nil {
+				safe.SharedMatPool.Put(mean)
+			}
+		}
+	}()
+
+	for i, window := range multiScaleWindows(windowSize) {
+		mean, err := calculateNeighborhoodMeanIntegral(ctx, src, window)
+		if err != nil {
+			return nil, fmt.Errorf("window %d: %w", window, err)
+		}
+		means[i] = mean
+	}
+
+	return combineMatricesWeighted(ctx, means[:], multiScaleWeights[:])
+}
+
+// calculateNeighborhoodMeanIntegral returns the windowSize box means of src
+func calculateNeighborhoodMeanIntegral(ctx context.Context, src *safe.Mat, windowSize int) (*safe.Mat, error) {
+	return filters.NewNeighborhoodCalculator(windowSize).CalculateIntegral(ctx, src)
+}
+
+// combineMatricesWeighted returns the per-pixel weighted sum of mats, which
+// must share size and type, truncated like the neighborhood means themselves
+func combineMatricesWeighted(ctx context.Context, mats []*safe.Mat, weights []float64) (*safe.Mat, error) {
+	if len(mats) == 0 || len(mats) != len(weights) {
+		return nil, fmt.Errorf("need one weight per matrix, got %d matrices and %d weights", len(mats), len(weights))
+	}
+
+	rows, cols, matType := mats[0].Rows(), mats[0].Cols(), mats[0].Type()
+	for _, mat := range mats[1:] {
+		if mat.Rows() != rows || mat.Cols() != cols || mat.Type() != matType {
+			return nil, fmt.Errorf("matrices differ in size or type")
+		}
+	}
+
+	dst := safe.SharedMatPool.Get(rows, cols, matType)
+	if dst == nil {
+		return nil, fmt.Errorf("failed to create combined Mat")
+	}
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				safe.SharedMatPool.Put(dst)
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			sum := 0.0
+			for i, mat := range mats {
+				val, _ := safe.GetAt[float64](mat, y, x)
+				sum += weights[i] * val
+			}
+
+			if err := safe.SetAt(dst, y, x, math.Trunc(sum)); err != nil {
+				safe.SharedMatPool.Put(dst)
+				return nil, fmt.Errorf("failed to store combined mean: %w", err)
+			}
+		}
+	}
+
+	return dst, nil
+}
@@ -0,0 +1,120 @@
+package otsu
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// discsImage returns a side x side image of small and large bright discs on a
+// dark background with Gaussian noise, and the mask of the discs
+func discsImage(b *testing.B, side int) (*safe.Mat, gocv.Mat) {
+	b.Helper()
+
+	img := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(80, 0, 0, 0), side, side, gocv.MatTypeCV8UC1)
+	defer img.Close()
+	truth := gocv.NewMatWithSize(side, side, gocv.MatTypeCV8UC1)
+	truth.SetTo(gocv.NewScalar(0, 0, 0, 0))
+	b.Cleanup(func() { truth.Close() })
+
+	// A grid of fine discs on the left half, a few coarse ones on the right
+	discs := []struct {
+		spacing, radius int
+		x0, x1          int
+	}{
+		{spacing: side / 32, radius: side / 128, x0: 0, x1: side / 2},
+		{spacing: side / 4, radius: side / 12, x0: side / 2, x1: side},
+	}
+	for _, d := range discs {
+		for y := d.spacing / 2; y < side; y += d.spacing {
+			for x := d.x0 + d.spacing/2; x < d.x1; x += d.spacing {
+				if err := gocv.Circle(&img, image.Pt(x, y), d.radius, color.RGBA{170, 170, 170, 0}, -1); err != nil {
+					b.Fatal(err)
+				}
+				if err := gocv.Circle(&truth, image.Pt(x, y), d.radius, color.RGBA{255, 255, 255, 0}, -1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	}
+
+	// The noise is signed, so add it in 16 bits and saturate back to 8
+	gocv.SetRNGSeed(1)
+	noise := gocv.NewMatWithSize(side, side, gocv.MatTypeCV16SC1)
+	defer noise.Close()
+	gocv.RandN(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(30, 0, 0, 0))
+
+	wide := gocv.NewMat()
+	defer wide.Close()
+	if err := img.ConvertTo(&wide, gocv.MatTypeCV16SC1); err != nil {
+		b.Fatal(err)
+	}
+	if err := gocv.Add(wide, noise, &wide); err != nil {
+		b.Fatal(err)
+	}
+	noisy := gocv.NewMat()
+	defer noisy.Close()
+	if err := wide.ConvertTo(&noisy, gocv.MatTypeCV8UC1); err != nil {
+		b.Fatal(err)
+	}
+
+	mat, err := safe.NewMatFromMat(noisy)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { mat.Close() })
+	return mat, truth
+}
+
+// misclassified returns the percentage of pixels where result and truth differ
+func misclassified(b *testing.B, result *safe.Mat, truth gocv.Mat) float64 {
+	b.Helper()
+
+	diff := gocv.NewMat()
+	defer diff.Close()
+	if err := gocv.Compare(result.GetMat(), truth, &diff, gocv.CompareNE); err != nil {
+		b.Fatal(err)
+	}
+	return 100 * float64(gocv.CountNonZero(diff)) / float64(truth.Total())
+}
+
+// BenchmarkSingleVsMultiScale times 2D Otsu on a 1024x1024 image of fine and
+// coarse discs with one neighborhood scale and with multi_scale, reporting
+// the share of misclassified pixels alongside
+func BenchmarkSingleVsMultiScale(b *testing.B) {
+	input, truth := discsImage(b, 1024)
+	p := NewProcessor()
+
+	for _, multiScale := range []bool{false, true} {
+		name := "SingleScale"
+		if multiScale {
+			name = "MultiScale"
+		}
+
+		params := p.GetDefaultParameters()
+		params["multi_scale"] = multiScale
+
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var result *safe.Mat
+			for i := 0; i < b.N; i++ {
+				if result != nil {
+					result.Close()
+				}
+				var err error
+				result, err = p.ProcessWithContext(context.Background(), input, params)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.StopTimer()
+			b.ReportMetric(misclassified(b, result, truth), "%misclassified")
+			result.Close()
+		})
+	}
+}
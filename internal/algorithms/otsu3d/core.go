@@ -0,0 +1,382 @@
+// Package otsu3d implements Otsu thresholding of volumetric stacks, where each
+// voxel is classified by its intensity, the mean of its in-slice (XY)
+// neighbourhood and the mean of the same position in adjacent slices (Z)
+package otsu3d
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+
+	"gocv.io/x/gocv"
+)
+
+// moments accumulates the weight and per-axis intensity sums of histogram bins
+type moments struct {
+	weight  float64
+	voxel   float64
+	spatial float64
+	axial   float64
+}
+
+func (m *moments) add(o moments) {
+	m.weight += o.weight
+	m.voxel += o.voxel
+	m.spatial += o.spatial
+	m.axial += o.axial
+}
+
+// ThreeDOtsuProcessor thresholds a stack of 2D slices using a joint histogram
+// of (voxel, XY-neighbour mean, Z-neighbour mean). The voxel and XY axes share
+// the spatial threshold and the Z axis has its own axial threshold; a voxel is
+// foreground when all three values lie above their thresholds.
+type ThreeDOtsuProcessor struct {
+	name string
+}
+
+func NewProcessor() *ThreeDOtsuProcessor {
+	return &ThreeDOtsuProcessor{
+		name: "3D Otsu",
+	}
+}
+
+func (p *ThreeDOtsuProcessor) GetName() string {
+	return p.name
+}
+
+func (p *ThreeDOtsuProcessor) GetDefaultParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"xy_window_size": 5,
+		"z_radius":       1,
+		"histogram_bins": 32,
+	}
+}
+
+func (p *ThreeDOtsuProcessor) ValidateParameters(params map[string]interface{}) error {
+	if windowSize, ok := params["xy_window_size"].(int); ok {
+		if windowSize < 3 || windowSize > 21 || windowSize%2 == 0 {
+			return fmt.Errorf("xy_window_size must be odd and between 3 and 21, got: %d", windowSize)
+		}
+	}
+
+	if radius, ok := params["z_radius"].(int); ok {
+		if radius < 1 || radius > 5 {
+			return fmt.Errorf("z_radius must be between 1 and 5, got: %d", radius)
+		}
+	}
+
+	// The joint histogram holds bins³ cells, so the range is kept small
+	if bins, ok := params["histogram_bins"].(int); ok {
+		if bins < 8 || bins > 64 {
+			return fmt.Errorf("histogram_bins must be between 8 and 64, got: %d", bins)
+		}
+	}
+
+	return nil
+}
+
+// ProcessStack thresholds slices as one volume and returns a binary Mat per
+// slice, in the same order. All slices must have the same size. The threshold
+// pair (t_spatial, t_axial) in bin units is recorded as optimal_threshold with
+// processing.RecordStatistics, along with the bin count and number of slices.
+func (p *ThreeDOtsuProcessor) ProcessStack(ctx context.Context, slices []*safe.Mat, params map[string]interface{}) ([]*safe.Mat, error) {
+	if len(slices) == 0 {
+		return nil, fmt.Errorf("empty stack")
+	}
+
+	rows, cols := slices[0].Rows(), slices[0].Cols()
+	for i, slice := range slices {
+		if err := safe.ValidateMatForOperation(slice, "3D Otsu processing"); err != nil {
+			return nil, fmt.Errorf("slice %d: %w", i+1, err)
+		}
+		if slice.Rows() != rows || slice.Cols() != cols {
+			return nil, fmt.Errorf("slice %d is %dx%d, expected %dx%d", i+1, slice.Cols(), slice.Rows(), cols, rows)
+		}
+	}
+
+	if err := p.ValidateParameters(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	windowSize := p.getIntParam(params, "xy_window_size", 5)
+	zRadius := p.getIntParam(params, "z_radius", 1)
+	bins := p.getIntParam(params, "histogram_bins", 32)
+
+	voxels := make([][]uint8, len(slices))
+	spatial := make([][]uint8, len(slices))
+	for i, slice := range slices {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		voxels[i], spatial[i], err = p.readSlice(slice, windowSize)
+		if err != nil {
+			return nil, fmt.Errorf("slice %d: %w", i+1, err)
+		}
+	}
+
+	axial, err := p.calculateAxialMeans(ctx, voxels, zRadius)
+	if err != nil {
+		return nil, err
+	}
+
+	histogram, err := p.buildHistogram(ctx, voxels, spatial, axial, bins)
+	if err != nil {
+		return nil, err
+	}
+
+	tSpatial, tAxial := FindThresholds(histogram, bins)
+
+	processing.RecordStatistics(ctx, map[string]interface{}{
+		"optimal_threshold": [2]int{tSpatial, tAxial},
+		"histogram_bins":    bins,
+		"slices":            len(slices),
+	})
+
+	return p.applyThresholds(ctx, voxels, spatial, axial, rows, cols, bins, tSpatial, tAxial)
+}
+
+// readSlice returns the grayscale pixels of slice and their XY box means in
+// row-major order
+func (p *ThreeDOtsuProcessor) readSlice(slice *safe.Mat, windowSize int) ([]uint8, []uint8, error) {
+	grayscale, err := p.convertToGrayscale(slice)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer grayscale.Close()
+
+	means, err := safe.NewMat(grayscale.Rows(), grayscale.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer means.Close()
+
+	srcMat := grayscale.GetMat()
+	dstMat := means.GetMat()
+	gocv.Blur(srcMat, &dstMat, image.Point{X: windowSize, Y: windowSize})
+
+	rows, cols := grayscale.Rows(), grayscale.Cols()
+	voxels := make([]uint8, rows*cols)
+	spatial := make([]uint8, rows*cols)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if voxels[y*cols+x], err = grayscale.GetUCharAt(y, x); err != nil {
+				return nil, nil, err
+			}
+			if spatial[y*cols+x], err = means.GetUCharAt(y, x); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return voxels, spatial, nil
+}
+
+// calculateAxialMeans averages each voxel with the same position in the
+// slices within radius, clamping the window at the ends of the stack
+func (p *ThreeDOtsuProcessor) calculateAxialMeans(ctx context.Context, voxels [][]uint8, radius int) ([][]uint8, error) {
+	depth := len(voxels)
+	axial := make([][]uint8, depth)
+
+	for z := range voxels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		from, to := max(0, z-radius), min(depth-1, z+radius)
+		count := to - from + 1
+
+		axial[z] = make([]uint8, len(voxels[z]))
+		for i := range voxels[z] {
+			sum := 0
+			for k := from; k <= to; k++ {
+				sum += int(voxels[k][i])
+			}
+			axial[z][i] = uint8((sum + count/2) / count)
+		}
+	}
+
+	return axial, nil
+}
+
+// buildHistogram counts voxels in a bins³ joint histogram indexed by
+// (voxel, spatial, axial) bin
+func (p *ThreeDOtsuProcessor) buildHistogram(ctx context.Context, voxels, spatial, axial [][]uint8, bins int) ([]float64, error) {
+	histogram := make([]float64, bins*bins*bins)
+
+	for z := range voxels {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i := range voxels[z] {
+			v := binOf(voxels[z][i], bins)
+			s := binOf(spatial[z][i], bins)
+			a := binOf(axial[z][i], bins)
+			histogram[(v*bins+s)*bins+a]++
+		}
+	}
+
+	return histogram, nil
+}
+
+func binOf(value uint8, bins int) int {
+	return int(value) * bins / 256
+}
+
+// FindThresholds returns the (t_spatial, t_axial) pair maximising the
+// between-class variance of the joint histogram. Class 0 is the box with
+// voxel and spatial bins ≤ t_spatial and axial bins ≤ t_axial, class 1 the
+// box above both; bins in neither box are edges and noise and are left out,
+// as in 2D Otsu. Prefix and suffix sums make each candidate O(1).
+func FindThresholds(histogram []float64, bins int) (int, int) {
+	lower := cumulativeMoments(histogram, bins, false)
+	upper := cumulativeMoments(histogram, bins, true)
+
+	index := func(v, s, a int) int { return (v*bins+s)*bins + a }
+
+	total := lower[index(bins-1, bins-1, bins-1)]
+	best := [2]int{bins / 2, bins / 2}
+	if total.weight == 0 {
+		return best[0], best[1]
+	}
+
+	meanV := total.voxel / total.weight
+	meanS := total.spatial / total.weight
+	meanA := total.axial / total.weight
+
+	classVariance := func(m moments) float64 {
+		dv := m.voxel/m.weight - meanV
+		ds := m.spatial/m.weight - meanS
+		da := m.axial/m.weight - meanA
+		return m.weight / total.weight * (dv*dv + ds*ds + da*da)
+	}
+
+	maxVariance := -1.0
+	for ts := 0; ts < bins-1; ts++ {
+		for ta := 0; ta < bins-1; ta++ {
+			class0 := lower[index(ts, ts, ta)]
+			class1 := upper[index(ts+1, ts+1, ta+1)]
+			if class0.weight == 0 || class1.weight == 0 {
+				continue
+			}
+
+			variance := classVariance(class0) + classVariance(class1)
+			if variance > maxVariance {
+				maxVariance = variance
+				best = [2]int{ts, ta}
+			}
+		}
+	}
+
+	return best[0], best[1]
+}
+
+// cumulativeMoments returns, for every bin, the moments of all bins at or
+// below it on each axis, or at or above it when fromTop is set
+func cumulativeMoments(histogram []float64, bins int, fromTop bool) []moments {
+	cumulative := make([]moments, len(histogram))
+	for v := 0; v < bins; v++ {
+		for s := 0; s < bins; s++ {
+			for a := 0; a < bins; a++ {
+				weight := histogram[(v*bins+s)*bins+a]
+				cumulative[(v*bins+s)*bins+a] = moments{
+					weight:  weight,
+					voxel:   float64(v) * weight,
+					spatial: float64(s) * weight,
+					axial:   float64(a) * weight,
+				}
+			}
+		}
+	}
+
+	// One running sum per axis turns the histogram into a 3D prefix sum
+	strides := []int{bins * bins, bins, 1}
+	for _, stride := range strides {
+		if fromTop {
+			for i := len(cumulative) - 1; i >= 0; i-- {
+				if (i/stride)%bins != bins-1 {
+					cumulative[i].add(cumulative[i+stride])
+				}
+			}
+		} else {
+			for i := range cumulative {
+				if (i/stride)%bins != 0 {
+					cumulative[i].add(cumulative[i-stride])
+				}
+			}
+		}
+	}
+
+	return cumulative
+}
+
+func (p *ThreeDOtsuProcessor) applyThresholds(ctx context.Context, voxels, spatial, axial [][]uint8, rows, cols, bins, tSpatial, tAxial int) ([]*safe.Mat, error) {
+	results := make([]*safe.Mat, 0, len(voxels))
+	closeResults := func() {
+		for _, result := range results {
+			result.Close()
+		}
+	}
+
+	for z := range voxels {
+		if err := ctx.Err(); err != nil {
+			closeResults()
+			return nil, err
+		}
+
+		result, err := safe.NewMat(rows, cols, gocv.MatTypeCV8UC1)
+		if err != nil {
+			closeResults()
+			return nil, err
+		}
+		results = append(results, result)
+
+		for i := range voxels[z] {
+			if binOf(voxels[z][i], bins) > tSpatial &&
+				binOf(spatial[z][i], bins) > tSpatial &&
+				binOf(axial[z][i], bins) > tAxial {
+				result.SetUCharAt(i/cols, i%cols, 255)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func (p *ThreeDOtsuProcessor) convertToGrayscale(src *safe.Mat) (*safe.Mat, error) {
+	if src.Channels() == 1 {
+		return src.Clone()
+	}
+
+	dst, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := src.GetMat()
+	dstMat := dst.GetMat()
+
+	switch src.Channels() {
+	case 3:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRToGray)
+	case 4:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRAToGray)
+	default:
+		dst.Close()
+		return nil, fmt.Errorf("unsupported channel count: %d", src.Channels())
+	}
+
+	return dst, nil
+}
+
+// Helper functions
+func (p *ThreeDOtsuProcessor) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
+	if value, ok := params[key].(int); ok {
+		return value
+	}
+	return defaultValue
+}
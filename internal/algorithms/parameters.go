@@ -0,0 +1,58 @@
+package algorithms
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseParameters returns the defaults of algorithm with overrides applied,
+// each value parsed into the type of its default. Unknown algorithms and
+// parameter names are rejected.
+func (m *Manager) ParseParameters(algorithm string, overrides map[string]string) (map[string]interface{}, error) {
+	if _, err := m.GetAlgorithm(algorithm); err != nil {
+		available := m.GetAvailableAlgorithms()
+		slices.Sort(available)
+		return nil, fmt.Errorf("unknown algorithm %q (available: %s)", algorithm, strings.Join(available, ", "))
+	}
+
+	parameters := m.GetParameters(algorithm)
+
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		defaultValue, exists := parameters[key]
+		if !exists {
+			return nil, fmt.Errorf("unknown parameter %q for %s", key, algorithm)
+		}
+
+		converted, err := convertParameter(overrides[key], defaultValue)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", key, err)
+		}
+		parameters[key] = converted
+	}
+
+	return parameters, nil
+}
+
+// convertParameter parses value into the type of the default
+func convertParameter(value string, defaultValue interface{}) (interface{}, error) {
+	switch defaultValue.(type) {
+	case int:
+		return strconv.Atoi(value)
+	case float64:
+		return strconv.ParseFloat(value, 64)
+	case bool:
+		return strconv.ParseBool(value)
+	case string:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unsupported parameter type %T", defaultValue)
+	}
+}
@@ -0,0 +1,48 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Extension returns the shared library extension for the current platform
+func Extension() string {
+	if runtime.GOOS == "windows" {
+		return ".dll"
+	}
+	return ".so"
+}
+
+// LoadDir opens every shared library in dir. Libraries that fail to load are
+// reported in the returned error and skipped. A missing directory is not an error.
+func LoadDir(dir string) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+
+	var plugins []Plugin
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), Extension()) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		p, err := open(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		plugins = append(plugins, p)
+	}
+
+	return plugins, errors.Join(errs...)
+}
@@ -0,0 +1,13 @@
+//go:build !((linux || darwin || freebsd) && cgo)
+
+package plugin
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// open reports that Go plugins are not available on this platform
+func open(path string) (Plugin, error) {
+	return nil, fmt.Errorf("plugins are not supported on %s", runtime.GOOS)
+}
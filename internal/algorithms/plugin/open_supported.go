@@ -0,0 +1,32 @@
+//go:build (linux || darwin || freebsd) && cgo
+
+package plugin
+
+import (
+	"fmt"
+	goplugin "plugin"
+)
+
+// open loads a shared library and calls its NewPlugin factory
+func open(path string) (Plugin, error) {
+	library, err := goplugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, err := library.Lookup(FactorySymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	factory, ok := symbol.(func() Plugin)
+	if !ok {
+		return nil, fmt.Errorf("%s has type %T, want func() plugin.Plugin", FactorySymbol, symbol)
+	}
+
+	p := factory()
+	if p == nil {
+		return nil, fmt.Errorf("%s returned nil", FactorySymbol)
+	}
+	return p, nil
+}
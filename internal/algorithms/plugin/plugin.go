@@ -0,0 +1,139 @@
+// Package plugin loads user-supplied thresholding algorithms from Go shared
+// libraries. GoCV values wrap C pointers owned by the host process, so plugins
+// exchange pixels through PixelBuffer instead of *safe.Mat.
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// FactorySymbol is the function every plugin library must export:
+//
+//	func NewPlugin() plugin.Plugin
+const FactorySymbol = "NewPlugin"
+
+// PixelBuffer is 8-bit pixel data exchanged with plugins. Rows are Stride
+// bytes apart and each pixel has Channels interleaved samples in BGR order.
+type PixelBuffer struct {
+	Width    int
+	Height   int
+	Channels int
+	Stride   int
+	Data     []byte
+}
+
+// Plugin is a thresholding algorithm supplied by a shared library
+type Plugin interface {
+	Name() string
+	DefaultParameters() map[string]interface{}
+	Validate(params map[string]interface{}) error
+	Process(ctx context.Context, input PixelBuffer, params map[string]interface{}) (PixelBuffer, error)
+}
+
+// Adapter exposes a Plugin through the algorithm interfaces used by the
+// algorithm manager
+type Adapter struct {
+	plugin Plugin
+}
+
+// NewAdapter wraps a loaded plugin
+func NewAdapter(p Plugin) *Adapter {
+	return &Adapter{plugin: p}
+}
+
+func (a *Adapter) GetName() string {
+	return a.plugin.Name()
+}
+
+func (a *Adapter) GetDefaultParameters() map[string]interface{} {
+	return a.plugin.DefaultParameters()
+}
+
+func (a *Adapter) ValidateParameters(params map[string]interface{}) error {
+	return a.plugin.Validate(params)
+}
+
+func (a *Adapter) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return a.ProcessWithContext(context.Background(), input, params)
+}
+
+func (a *Adapter) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(input, a.plugin.Name()); err != nil {
+		return nil, err
+	}
+
+	buffer, err := MatToPixelBuffer(input)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := a.plugin.Process(ctx, buffer, params)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed: %w", a.plugin.Name(), err)
+	}
+
+	return PixelBufferToMat(output)
+}
+
+// MatToPixelBuffer copies an 8-bit Mat into a PixelBuffer
+func MatToPixelBuffer(mat *safe.Mat) (PixelBuffer, error) {
+	if mat.Type() != gocv.MatTypeCV8UC1 && mat.Type() != gocv.MatTypeCV8UC3 && mat.Type() != gocv.MatTypeCV8UC4 {
+		return PixelBuffer{}, fmt.Errorf("unsupported Mat type for plugins: %v", mat.Type())
+	}
+
+	src := mat.GetMat()
+	data, err := src.DataPtrUint8()
+	if err != nil {
+		return PixelBuffer{}, fmt.Errorf("failed to access Mat data: %w", err)
+	}
+
+	channels := mat.Channels()
+	return PixelBuffer{
+		Width:    mat.Cols(),
+		Height:   mat.Rows(),
+		Channels: channels,
+		Stride:   mat.Cols() * channels,
+		Data:     append([]byte(nil), data...),
+	}, nil
+}
+
+// PixelBufferToMat copies a PixelBuffer returned by a plugin into a new Mat
+func PixelBufferToMat(buffer PixelBuffer) (*safe.Mat, error) {
+	var matType gocv.MatType
+	switch buffer.Channels {
+	case 1:
+		matType = gocv.MatTypeCV8UC1
+	case 3:
+		matType = gocv.MatTypeCV8UC3
+	case 4:
+		matType = gocv.MatTypeCV8UC4
+	default:
+		return nil, fmt.Errorf("unsupported plugin output channel count: %d", buffer.Channels)
+	}
+
+	rowBytes := buffer.Width * buffer.Channels
+	if buffer.Width <= 0 || buffer.Height <= 0 || buffer.Stride < rowBytes ||
+		len(buffer.Data) < buffer.Stride*(buffer.Height-1)+rowBytes {
+		return nil, fmt.Errorf("invalid plugin output buffer %dx%d stride %d with %d bytes",
+			buffer.Width, buffer.Height, buffer.Stride, len(buffer.Data))
+	}
+
+	// Drop row padding so the data is contiguous
+	packed := make([]byte, rowBytes*buffer.Height)
+	for y := 0; y < buffer.Height; y++ {
+		copy(packed[y*rowBytes:(y+1)*rowBytes], buffer.Data[y*buffer.Stride:])
+	}
+
+	mat, err := gocv.NewMatFromBytes(buffer.Height, buffer.Width, matType, packed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Mat from plugin output: %w", err)
+	}
+	defer mat.Close()
+
+	return safe.NewMatFromMat(mat)
+}
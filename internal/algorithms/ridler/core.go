@@ -0,0 +1,342 @@
+package ridler
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"runtime"
+
+	"otsu-obliterator/internal/algorithms/convergence"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+	"otsu-obliterator/internal/processing/threshold"
+
+	"gocv.io/x/gocv"
+)
+
+// RidlerCalvardProcessor implements Ridler-Calvard iterative mean (isodata) thresholding
+type RidlerCalvardProcessor struct {
+	name       string
+	workerPool chan struct{}
+}
+
+func NewProcessor() *RidlerCalvardProcessor {
+	// Create worker pool for parallel processing
+	workers := make(chan struct{}, runtime.NumCPU())
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers <- struct{}{}
+	}
+
+	return &RidlerCalvardProcessor{
+		name:       "Ridler-Calvard",
+		workerPool: workers,
+	}
+}
+
+func (p *RidlerCalvardProcessor) GetName() string {
+	return p.name
+}
+
+func (p *RidlerCalvardProcessor) GetDefaultParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"convergence_epsilon":    0.5,
+		"max_iterations":         100,
+		"gaussian_preprocessing": false,
+		"result_cleanup":         false,
+		"pyramid_levels":         0,
+	}
+}
+
+func (p *RidlerCalvardProcessor) ValidateParameters(params map[string]interface{}) error {
+	if epsilon, ok := params["convergence_epsilon"].(float64); ok {
+		if epsilon <= 0.0 || epsilon > 10.0 {
+			return fmt.Errorf("convergence_epsilon must be between 0 and 10, got: %f", epsilon)
+		}
+	}
+
+	if maxIter, ok := params["max_iterations"].(int); ok {
+		if maxIter < 1 || maxIter > 1000 {
+			return fmt.Errorf("max_iterations must be between 1 and 1000, got: %d", maxIter)
+		}
+	}
+
+	return threshold.ValidatePyramidLevels(params)
+}
+
+func (p *RidlerCalvardProcessor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return p.ProcessWithContext(context.Background(), input, params)
+}
+
+func (p *RidlerCalvardProcessor) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(input, "Ridler-Calvard processing"); err != nil {
+		return nil, err
+	}
+
+	if err := p.ValidateParameters(params); err != nil {
+		return nil, fmt.Errorf("parameter validation failed: %w", err)
+	}
+
+	// Acquire worker from pool
+	select {
+	case <-p.workerPool:
+		defer func() { p.workerPool <- struct{}{} }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return p.processRidlerCalvard(ctx, input, params)
+}
+
+func (p *RidlerCalvardProcessor) processRidlerCalvard(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	working, err := p.applyPreprocessing(input, params)
+	if err != nil {
+		return nil, fmt.Errorf("preprocessing failed: %w", err)
+	}
+	defer working.Close()
+
+	monitor := convergence.NewIterativeConvergenceMonitor(
+		p.getFloatParam(params, "convergence_epsilon", 0.5),
+		p.getIntParam(params, "max_iterations", 100),
+	)
+	monitor.SetIterationHandler(convergence.IterationHandlerFromContext(ctx))
+
+	value, err := p.findThreshold(ctx, working, monitor, params)
+	if err != nil {
+		return nil, fmt.Errorf("threshold search failed: %w", err)
+	}
+
+	// The converged threshold warm starts the next finer pyramid level
+	processing.RecordStatistics(ctx, map[string]interface{}{
+		threshold.WarmStartThresholdStat: value,
+	})
+
+	result, err := p.applyThreshold(ctx, working, value)
+	if err != nil {
+		return nil, fmt.Errorf("threshold application failed: %w", err)
+	}
+
+	if p.getBoolParam(params, "result_cleanup", false) {
+		select {
+		case <-ctx.Done():
+			result.Close()
+			return nil, ctx.Err()
+		default:
+		}
+
+		cleaned, err := p.applyPostprocessing(result)
+		result.Close()
+		if err != nil {
+			return nil, fmt.Errorf("postprocessing failed: %w", err)
+		}
+		result = cleaned
+	}
+
+	return result, nil
+}
+
+// FindThreshold runs the Ridler-Calvard iteration on a single channel image and
+// records each intermediate threshold in the monitor
+func (p *RidlerCalvardProcessor) FindThreshold(ctx context.Context, src *safe.Mat, monitor *convergence.IterativeConvergenceMonitor) (float64, error) {
+	return p.findThreshold(ctx, src, monitor, nil)
+}
+
+// findThreshold is FindThreshold starting from initial_threshold_override
+// when params sets it
+func (p *RidlerCalvardProcessor) findThreshold(ctx context.Context, src *safe.Mat, monitor *convergence.IterativeConvergenceMonitor, params map[string]interface{}) (float64, error) {
+	if src.Channels() != 1 {
+		return 0, fmt.Errorf("expected single channel image, got %d channels", src.Channels())
+	}
+
+	histogram, err := p.buildHistogram(ctx, src)
+	if err != nil {
+		return 0, err
+	}
+	if start, ok := threshold.InitialThresholdOverride(params); ok {
+		return IterateThresholdFrom(ctx, histogram, start, monitor)
+	}
+	return IterateThreshold(ctx, histogram, monitor)
+}
+
+// IterateThreshold starts at the histogram mean and repeatedly moves the threshold
+// to the midpoint of the class means until the monitor reports convergence
+func IterateThreshold(ctx context.Context, histogram []int, monitor *convergence.IterativeConvergenceMonitor) (float64, error) {
+	return IterateThresholdFrom(ctx, histogram, histogramMean(histogram, 0, len(histogram)-1), monitor)
+}
+
+// IterateThresholdFrom is IterateThreshold starting at start, such as the
+// result of a coarser pyramid level
+func IterateThresholdFrom(ctx context.Context, histogram []int, start float64, monitor *convergence.IterativeConvergenceMonitor) (float64, error) {
+	threshold := start
+	monitor.Record(threshold)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		default:
+		}
+
+		split := int(threshold)
+		lowerMean := histogramMean(histogram, 0, split)
+		upperMean := histogramMean(histogram, split+1, len(histogram)-1)
+
+		threshold = (lowerMean + upperMean) / 2.0
+		if monitor.Record(threshold) {
+			return threshold, nil
+		}
+	}
+}
+
+// histogramMean returns the mean intensity of the bins in [from, to], or the
+// centre of the range when it holds no pixels
+func histogramMean(histogram []int, from, to int) float64 {
+	total := 0
+	weightedSum := 0.0
+
+	for i := from; i <= to && i < len(histogram); i++ {
+		total += histogram[i]
+		weightedSum += float64(i) * float64(histogram[i])
+	}
+
+	if total == 0 {
+		return float64(from+to) / 2.0
+	}
+
+	return weightedSum / float64(total)
+}
+
+func (p *RidlerCalvardProcessor) buildHistogram(ctx context.Context, src *safe.Mat) ([]int, error) {
+	histogram := make([]int, 256)
+	rows := src.Rows()
+	cols := src.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if val, err := src.GetUCharAt(y, x); err == nil {
+				histogram[val]++
+			}
+		}
+	}
+
+	return histogram, nil
+}
+
+func (p *RidlerCalvardProcessor) applyPreprocessing(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	grayscale, err := p.convertToGrayscale(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.getBoolParam(params, "gaussian_preprocessing", false) {
+		return grayscale, nil
+	}
+	defer grayscale.Close()
+
+	blurred, err := safe.NewMat(grayscale.Rows(), grayscale.Cols(), grayscale.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := grayscale.GetMat()
+	dstMat := blurred.GetMat()
+	gocv.GaussianBlur(srcMat, &dstMat, image.Point{X: 5, Y: 5}, 1.0, 1.0, gocv.BorderDefault)
+
+	return blurred, nil
+}
+
+func (p *RidlerCalvardProcessor) convertToGrayscale(src *safe.Mat) (*safe.Mat, error) {
+	if src.Channels() == 1 {
+		return src.Clone()
+	}
+
+	dst, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := src.GetMat()
+	dstMat := dst.GetMat()
+
+	switch src.Channels() {
+	case 3:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRToGray)
+	case 4:
+		gocv.CvtColor(srcMat, &dstMat, gocv.ColorBGRAToGray)
+	default:
+		dst.Close()
+		return nil, fmt.Errorf("unsupported channel count: %d", src.Channels())
+	}
+
+	return dst, nil
+}
+
+func (p *RidlerCalvardProcessor) applyThreshold(ctx context.Context, src *safe.Mat, threshold float64) (*safe.Mat, error) {
+	result, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := src.Rows()
+	cols := src.Cols()
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Close()
+				return nil, err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			if val, err := src.GetUCharAt(y, x); err == nil && float64(val) > threshold {
+				result.SetUCharAt(y, x, 255)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (p *RidlerCalvardProcessor) applyPostprocessing(src *safe.Mat) (*safe.Mat, error) {
+	result, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	kernel := gocv.GetStructuringElement(gocv.MorphEllipse, image.Point{X: 3, Y: 3})
+	defer kernel.Close()
+
+	srcMat := src.GetMat()
+	resultMat := result.GetMat()
+	gocv.MorphologyEx(srcMat, &resultMat, gocv.MorphOpen, kernel)
+
+	return result, nil
+}
+
+// Helper functions
+func (p *RidlerCalvardProcessor) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
+	if value, ok := params[key].(int); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p *RidlerCalvardProcessor) getFloatParam(params map[string]interface{}, key string, defaultValue float64) float64 {
+	if value, ok := params[key].(float64); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (p *RidlerCalvardProcessor) getBoolParam(params map[string]interface{}, key string, defaultValue bool) bool {
+	if value, ok := params[key].(bool); ok {
+		return value
+	}
+	return defaultValue
+}
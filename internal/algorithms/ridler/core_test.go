@@ -0,0 +1,129 @@
+package ridler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"otsu-obliterator/internal/algorithms/convergence"
+)
+
+// spikes returns a 256 bin histogram holding count pixels at each level
+func spikes(counts map[int]int) []int {
+	histogram := make([]int, 256)
+	for level, count := range counts {
+		histogram[level] = count
+	}
+	return histogram
+}
+
+func equalSteps(a, b []float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIterateThresholdConvergesStepByStep(t *testing.T) {
+	tests := []struct {
+		name      string
+		histogram []int
+		// steps are the thresholds the monitor records, starting with the
+		// histogram mean
+		steps []float64
+	}{
+		{
+			// The mean already lies midway between the class means
+			name:      "balanced",
+			histogram: spikes(map[int]int{50: 100, 200: 100}),
+			steps:     []float64{125, 125},
+		},
+		{
+			// The larger dark class pulls the mean down to 80; the class
+			// means 40 and 200 then move the threshold to 120
+			name:      "unbalanced",
+			histogram: spikes(map[int]int{40: 300, 200: 100}),
+			steps:     []float64{80, 120, 120},
+		},
+		{
+			// Each class spreads over two levels: the mean is 65 and the
+			// class means are 20 and 200
+			name:      "spread",
+			histogram: spikes(map[int]int{10: 150, 30: 150, 180: 50, 220: 50}),
+			steps:     []float64{65, 110, 110},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := convergence.NewIterativeConvergenceMonitor(0.5, 100)
+
+			threshold, err := IterateThreshold(context.Background(), tt.histogram, monitor)
+			if err != nil {
+				t.Fatalf("IterateThreshold: %v", err)
+			}
+
+			want := tt.steps[len(tt.steps)-1]
+			if threshold != want {
+				t.Errorf("threshold = %v, want %v", threshold, want)
+			}
+			if history := monitor.History(); !equalSteps(history, tt.steps) {
+				t.Errorf("steps = %v, want %v", history, tt.steps)
+			}
+			if !monitor.Converged() {
+				t.Error("monitor did not report convergence")
+			}
+		})
+	}
+}
+
+func TestIterateThresholdFromStart(t *testing.T) {
+	histogram := spikes(map[int]int{40: 300, 200: 100})
+	monitor := convergence.NewIterativeConvergenceMonitor(0.5, 100)
+
+	// Below the dark class the lower range is empty and counts as its centre
+	threshold, err := IterateThresholdFrom(context.Background(), histogram, 30, monitor)
+	if err != nil {
+		t.Fatalf("IterateThresholdFrom: %v", err)
+	}
+
+	want := []float64{30, 47.5, 120, 120}
+	if threshold != 120 {
+		t.Errorf("threshold = %v, want 120", threshold)
+	}
+	if history := monitor.History(); !equalSteps(history, want) {
+		t.Errorf("steps = %v, want %v", history, want)
+	}
+}
+
+func TestIterateThresholdStopsAtMaxIterations(t *testing.T) {
+	histogram := spikes(map[int]int{40: 300, 200: 100})
+	monitor := convergence.NewIterativeConvergenceMonitor(0.5, 2)
+
+	threshold, err := IterateThreshold(context.Background(), histogram, monitor)
+	if err != nil {
+		t.Fatalf("IterateThreshold: %v", err)
+	}
+	if threshold != 120 || monitor.Iterations() != 2 {
+		t.Errorf("threshold = %v after %d iterations, want 120 after 2", threshold, monitor.Iterations())
+	}
+	if monitor.Converged() {
+		t.Error("monitor reported convergence after running out of iterations")
+	}
+}
+
+func TestIterateThresholdCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	monitor := convergence.NewIterativeConvergenceMonitor(0.5, 100)
+	_, err := IterateThreshold(ctx, spikes(map[int]int{50: 100, 200: 100}), monitor)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
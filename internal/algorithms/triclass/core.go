@@ -2,21 +2,40 @@ package triclass
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"image"
 	"math"
 	"runtime"
+	"strings"
 	"sync"
 
+	"otsu-obliterator/internal/algorithms/convergence"
+	"otsu-obliterator/internal/algorithms/watershed"
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/opencv/conversion"
 	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+	"otsu-obliterator/internal/processing/filters"
+	"otsu-obliterator/internal/processing/labelling"
+	"otsu-obliterator/internal/processing/threshold"
 
+	"go.opentelemetry.io/otel"
 	"gocv.io/x/gocv"
 )
 
+// tracer records the processing stages when tracing is configured
+var tracer = otel.Tracer("otsu-obliterator/internal/algorithms/triclass")
+
+// warmStartRadius is how many levels either side of a warm start the initial
+// Otsu search covers
+const warmStartRadius = 16
+
 type Processor struct {
 	name       string
 	workerPool chan struct{}
 	mu         sync.RWMutex
+	logger     logger.Logger
 }
 
 func NewProcessor() *Processor {
@@ -32,30 +51,59 @@ func NewProcessor() *Processor {
 	}
 }
 
+// SetLogger enables debug logging of automatically chosen parameters
+func (p *Processor) SetLogger(log logger.Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.logger = log
+}
+
 func (p *Processor) GetName() string {
 	return p.name
 }
 
 func (p *Processor) GetDefaultParameters() map[string]interface{} {
 	return map[string]interface{}{
-		"initial_threshold_method": "otsu",
-		"histogram_bins":           0, // Auto-calculate
-		"convergence_precision":    1.0,
-		"max_iterations":           8,
-		"minimum_tbd_fraction":     0.01,
-		"class_separation":         0.5,
-		"preprocessing":            true,
-		"result_cleanup":           true,
-		"preserve_borders":         false,
-		"noise_robustness":         true,
-		"guided_filtering":         true,
-		"guided_radius":            6,
-		"guided_epsilon":           0.15,
-		"parallel_processing":      true,
+		"initial_threshold_method":  "otsu",
+		"histogram_bins":            0,   // Auto-calculate
+		"convergence_precision":     1.0, // AutoConvergencePrecision derives it from the dynamic range
+		"max_iterations":            8,
+		"minimum_tbd_fraction":      0.01,
+		"class_separation":          0.5,
+		"preprocessing":             true,
+		"result_cleanup":            true,
+		"preserve_borders":          false,
+		"apply_global_equalization": false,
+		"noise_robustness":          true,
+		"apply_unsharp":             false,
+		"unsharp_strength":          0.5,
+		"unsharp_radius":            2,
+		"guided_filtering":          true,
+		"guided_radius":             6,
+		"guided_epsilon":            0.15,
+		"parallel_processing":       true,
+		"cleanup_kernel_small":      3,
+		"cleanup_kernel_large":      5,
+		"cleanup_iterations":        1,
+		"adaptive_cleanup_kernels":  false, // Derive kernels from image_ppi or size instead
+		"min_object_area":           0,     // Pixels; 0 uses min_object_area_fraction
+		"min_object_area_fraction":  0.001, // Of the image area
+		"max_object_area":           0,     // Pixels; 0 keeps objects of any size
+		"channel_selection":         "luminance",
+		"use_graph_cut":             false, // Resolve the final TBD region by a minimum cut
+		"apply_watershed":           false, // Split touching objects along watershed lines
+		"watershed_min_distance":    watershed.DefaultMinDistance,
+		"pyramid_levels":            0, // Halved levels processed coarse to fine first
 	}
 }
 
 func (p *Processor) ValidateParameters(params map[string]interface{}) error {
+	if channel, ok := params["channel_selection"].(string); ok {
+		if _, err := conversion.NewChannelExtractor(channel); err != nil {
+			return fmt.Errorf("channel_selection must be one of: %s, got: %s", strings.Join(conversion.ChannelOptions, ", "), channel)
+		}
+	}
+
 	if method, ok := params["initial_threshold_method"].(string); ok {
 		validMethods := map[string]bool{"otsu": true, "mean": true, "median": true, "triangle": true}
 		if !validMethods[method] {
@@ -64,8 +112,8 @@ func (p *Processor) ValidateParameters(params map[string]interface{}) error {
 	}
 
 	if precision, ok := params["convergence_precision"].(float64); ok {
-		if precision < 0.5 || precision > 2.0 {
-			return fmt.Errorf("convergence_precision must be between 0.5 and 2.0, got: %f", precision)
+		if precision != AutoConvergencePrecision && (precision < 0.5 || precision > 2.0) {
+			return fmt.Errorf("convergence_precision must be 0 (auto) or between 0.5 and 2.0, got: %f", precision)
 		}
 	}
 
@@ -75,7 +123,73 @@ func (p *Processor) ValidateParameters(params map[string]interface{}) error {
 		}
 	}
 
-	return nil
+	if err := validateCleanupParameters(params); err != nil {
+		return err
+	}
+
+	if distance, ok := params["watershed_min_distance"].(int); ok {
+		if distance < 1 || distance > 50 {
+			return fmt.Errorf("watershed_min_distance must be between 1 and 50, got: %d", distance)
+		}
+	}
+
+	if err := threshold.ValidateForcedThreshold(params); err != nil {
+		return err
+	}
+
+	if err := threshold.ValidatePyramidLevels(params); err != nil {
+		return err
+	}
+
+	if err := filters.ValidateEqualizationParameters(params); err != nil {
+		return err
+	}
+
+	return filters.ValidateUnsharpParameters(params)
+}
+
+// validateCleanupParameters checks the morphological cleanup kernels and
+// iterations, reporting every problem at once
+func validateCleanupParameters(params map[string]interface{}) error {
+	var errs []error
+
+	small, hasSmall := params["cleanup_kernel_small"].(int)
+	if hasSmall && (small < 1 || small > 9 || small%2 == 0) {
+		errs = append(errs, fmt.Errorf("cleanup_kernel_small must be an odd value between 1 and 9, got: %d", small))
+	}
+
+	large, hasLarge := params["cleanup_kernel_large"].(int)
+	if hasLarge && (large < 3 || large > 15 || large%2 == 0) {
+		errs = append(errs, fmt.Errorf("cleanup_kernel_large must be an odd value between 3 and 15, got: %d", large))
+	}
+
+	if hasSmall && hasLarge && small >= large {
+		errs = append(errs, fmt.Errorf("cleanup_kernel_small (%d) must be less than cleanup_kernel_large (%d)", small, large))
+	}
+
+	if iterations, ok := params["cleanup_iterations"].(int); ok && (iterations < 1 || iterations > 5) {
+		errs = append(errs, fmt.Errorf("cleanup_iterations must be between 1 and 5, got: %d", iterations))
+	}
+
+	minArea, hasMinArea := params["min_object_area"].(int)
+	if hasMinArea && minArea < 0 {
+		errs = append(errs, fmt.Errorf("min_object_area must not be negative, got: %d", minArea))
+	}
+
+	if fraction, ok := params["min_object_area_fraction"].(float64); ok && (fraction < 0 || fraction > 0.05) {
+		errs = append(errs, fmt.Errorf("min_object_area_fraction must be between 0 and 0.05, got: %f", fraction))
+	}
+
+	maxArea, hasMaxArea := params["max_object_area"].(int)
+	if hasMaxArea && maxArea < 0 {
+		errs = append(errs, fmt.Errorf("max_object_area must not be negative, got: %d", maxArea))
+	}
+
+	if hasMinArea && hasMaxArea && minArea > 0 && maxArea > 0 && maxArea < minArea {
+		errs = append(errs, fmt.Errorf("max_object_area (%d) must not be less than min_object_area (%d)", maxArea, minArea))
+	}
+
+	return errors.Join(errs...)
 }
 
 func (p *Processor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
@@ -99,10 +213,39 @@ func (p *Processor) ProcessWithContext(ctx context.Context, input *safe.Mat, par
 		return nil, ctx.Err()
 	}
 
-	return p.processIterativeTriclass(ctx, input, params)
+	// 16-bit input is thresholded at 8 bits
+	depthScale := 1.0
+	if conversion.Is16Bit(input) {
+		scaled, err := conversion.ScaleTo8Bit(input)
+		if err != nil {
+			return nil, fmt.Errorf("16-bit input scaling failed: %w", err)
+		}
+		defer scaled.Close()
+		input = scaled
+		depthScale = conversion.Depth16Scale
+	}
+
+	// Reduce colour input to the selected channel before grayscale conversion
+	extractor, err := conversion.NewChannelExtractor(p.getStringParam(params, "channel_selection", string(conversion.ChannelLuminance)))
+	if err != nil {
+		return nil, err
+	}
+	selected, err := extractor.Extract(input)
+	if err != nil {
+		return nil, fmt.Errorf("channel extraction failed: %w", err)
+	}
+	defer selected.Close()
+
+	return p.processIterativeTriclass(ctx, selected, params, depthScale)
 }
 
-func (p *Processor) processIterativeTriclass(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+// processIterativeTriclass segments input and records the run's statistics
+// with processing.RecordStatistics: the convergence history, the first
+// iteration's threshold in warm_start_threshold, for 16-bit input the final
+// threshold as a 16-bit intensity in source_threshold, and after a
+// watershed step the number of objects in watershed_objects. depthScale is
+// the factor from 8-bit intensities to those of the original input.
+func (p *Processor) processIterativeTriclass(ctx context.Context, input *safe.Mat, params map[string]interface{}, depthScale float64) (*safe.Mat, error) {
 	// Step 1: Apply preprocessing
 	select {
 	case <-ctx.Done():
@@ -110,11 +253,13 @@ func (p *Processor) processIterativeTriclass(ctx context.Context, input *safe.Ma
 	default:
 	}
 
-	working, err := p.applyPreprocessing(input, params)
+	preprocessCtx, span := tracer.Start(ctx, "preprocessing")
+	working, err := p.applyPreprocessing(preprocessCtx, input, params)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("preprocessing failed: %w", err)
 	}
-	defer working.Close()
+	defer safe.SharedMatPool.Put(working)
 
 	// Step 2: Perform iterative triclass segmentation
 	select {
@@ -123,11 +268,26 @@ func (p *Processor) processIterativeTriclass(ctx context.Context, input *safe.Ma
 	default:
 	}
 
-	result, err := p.performIterativeSegmentation(ctx, working, params)
+	// Each iteration builds a region histogram and picks its threshold
+	searchCtx, span := tracer.Start(ctx, "threshold_search")
+	result, history, err := p.performIterativeSegmentation(searchCtx, working, params)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("iterative segmentation failed: %w", err)
 	}
 
+	stats := map[string]interface{}{
+		"convergence_history": history,
+	}
+	if len(history) > 0 {
+		// The first iteration's threshold, the one the initial threshold
+		// method produced, warm starts the next finer pyramid level
+		stats[threshold.WarmStartThresholdStat] = history[0].Threshold
+	}
+	if depthScale > 1 && len(history) > 0 {
+		stats["source_threshold"] = history[len(history)-1].Threshold * depthScale
+	}
+
 	// Step 3: Apply cleanup if enabled
 	if shouldCleanup, ok := params["result_cleanup"].(bool); ok && shouldCleanup {
 		select {
@@ -146,10 +306,22 @@ func (p *Processor) processIterativeTriclass(ctx context.Context, input *safe.Ma
 		result = cleaned
 	}
 
+	// Step 4: Separate touching objects if enabled
+	if applyWatershed, ok := params["apply_watershed"].(bool); ok && applyWatershed {
+		separated, count, err := p.applyWatershed(ctx, result, params)
+		result.Close()
+		if err != nil {
+			return nil, fmt.Errorf("watershed failed: %w", err)
+		}
+		result = separated
+		stats["watershed_objects"] = count
+	}
+
+	processing.RecordStatistics(ctx, stats)
 	return result, nil
 }
 
-func (p *Processor) applyPreprocessing(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+func (p *Processor) applyPreprocessing(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
 	current := input
 	needsCleanup := false
 
@@ -163,6 +335,23 @@ func (p *Processor) applyPreprocessing(input *safe.Mat, params map[string]interf
 		needsCleanup = true
 	}
 
+	// Global equalisation spreads the histogram before noise removal
+	if equalizer := filters.NewGlobalEqualizationPreprocessor(); equalizer.ShouldExecute(params) {
+		equalized, err := equalizer.Apply(ctx, current, params)
+		if err != nil {
+			if needsCleanup {
+				current.Close()
+			}
+			return nil, err
+		}
+
+		if needsCleanup {
+			current.Close()
+		}
+		current = equalized
+		needsCleanup = true
+	}
+
 	// Apply noise reduction if enabled
 	if useNoise, ok := params["noise_robustness"].(bool); ok && useNoise {
 		denoised, err := p.applyNonLocalMeansDenoising(current)
@@ -180,9 +369,26 @@ func (p *Processor) applyPreprocessing(input *safe.Mat, params map[string]interf
 		needsCleanup = true
 	}
 
+	// Sharpen before guided filtering so fine detail survives the smoothing
+	if unsharp := filters.NewUnsharpMaskPreprocessor(); unsharp.ShouldExecute(params) {
+		sharpened, err := unsharp.Apply(ctx, current, params)
+		if err != nil {
+			if needsCleanup {
+				current.Close()
+			}
+			return nil, err
+		}
+
+		if needsCleanup {
+			current.Close()
+		}
+		current = sharpened
+		needsCleanup = true
+	}
+
 	// Apply guided filtering if enabled
 	if useGuided, ok := params["guided_filtering"].(bool); ok && useGuided {
-		filtered, err := p.applyGuidedFiltering(current, params)
+		filtered, err := p.applyGuidedFiltering(ctx, current, params)
 		if err != nil {
 			if needsCleanup {
 				current.Close()
@@ -248,7 +454,7 @@ func (p *Processor) applyNonLocalMeansDenoising(src *safe.Mat) (*safe.Mat, error
 	return result, nil
 }
 
-func (p *Processor) applyGuidedFiltering(src *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+func (p *Processor) applyGuidedFiltering(ctx context.Context, src *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
 	radius := 6
 	if val, ok := params["guided_radius"].(int); ok {
 		radius = val
@@ -259,20 +465,29 @@ func (p *Processor) applyGuidedFiltering(src *safe.Mat, params map[string]interf
 		epsilon = val
 	}
 
-	return p.performGuidedFilter(src, radius, epsilon)
+	return p.performGuidedFilter(ctx, src, radius, epsilon)
 }
 
-func (p *Processor) performGuidedFilter(src *safe.Mat, radius int, epsilon float64) (*safe.Mat, error) {
-	result, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
-	if err != nil {
-		return nil, err
+func (p *Processor) performGuidedFilter(ctx context.Context, src *safe.Mat, radius int, epsilon float64) (*safe.Mat, error) {
+	// Every pixel is written below, so a recycled Mat is safe to use
+	result := safe.SharedMatPool.Get(src.Rows(), src.Cols(), src.Type())
+	if result == nil {
+		return nil, fmt.Errorf("failed to create guided filter Mat")
 	}
 
 	rows := src.Rows()
 	cols := src.Cols()
 
-	// Simple box filter approximation of guided filter
+	// Simple box filter approximation of guided filter. Cancellation is
+	// checked about a hundred times per image rather than once per call.
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				safe.SharedMatPool.Put(result)
+				return nil, err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			y1 := max(0, y-radius)
 			x1 := max(0, x-radius)
@@ -302,109 +517,245 @@ func (p *Processor) performGuidedFilter(src *safe.Mat, radius int, epsilon float
 	return result, nil
 }
 
-func (p *Processor) performIterativeSegmentation(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+func (p *Processor) performIterativeSegmentation(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, []convergence.ConvergenceRecord, error) {
 	maxIterations := p.getIntParam(params, "max_iterations", 8)
 	convergencePrecision := p.getFloatParam(params, "convergence_precision", 1.0)
 	minTBDFraction := p.getFloatParam(params, "minimum_tbd_fraction", 0.01)
+	useGraphCut, _ := params["use_graph_cut"].(bool)
+
+	if convergencePrecision == AutoConvergencePrecision {
+		spread := dynamicRange(input)
+		convergencePrecision = ComputeAdaptiveEpsilon(spread, p.getIntParam(params, "histogram_bins", 0))
+
+		p.mu.RLock()
+		log := p.logger
+		p.mu.RUnlock()
+		if log != nil {
+			log.Debug("Adaptive convergence precision selected", map[string]interface{}{
+				"dynamic_range":         spread,
+				"convergence_precision": convergencePrecision,
+			})
+		}
+	}
 
 	result, err := safe.NewMat(input.Rows(), input.Cols(), input.Type())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	currentRegion, err := input.Clone()
 	if err != nil {
 		result.Close()
-		return nil, err
+		return nil, nil, err
 	}
 	defer currentRegion.Close()
 
 	previousThreshold := -1.0
+	warmStart, hasWarmStart := threshold.InitialThresholdOverride(params)
 	totalPixels := float64(currentRegion.Rows() * currentRegion.Cols())
+	handler := convergence.IterationHandlerFromContext(ctx)
+	var history []convergence.ConvergenceRecord
+
+	// A forced threshold replaces the iterations with a single binary split
+	if forced, ok := threshold.ForcedThreshold(params); ok {
+		if err := p.segmentWithThreshold(ctx, result, currentRegion, forced, params); err != nil {
+			result.Close()
+			return nil, nil, err
+		}
+		history = append(history, convergence.ConvergenceRecord{
+			Iteration: 1,
+			Threshold: forced,
+		})
+		return result, history, nil
+	}
 
+	cancelled := processing.CancellationDone(ctx)
 	for iteration := 0; iteration < maxIterations; iteration++ {
 		select {
 		case <-ctx.Done():
 			result.Close()
-			return nil, ctx.Err()
+			return nil, nil, ctx.Err()
+		case <-cancelled:
+			result.Close()
+			return nil, nil, processing.ErrCancelled
 		default:
 		}
 
 		// Check if region has pixels to process
-		nonZeroPixels := p.countNonZeroPixels(currentRegion)
+		nonZeroPixels, err := p.countNonZeroPixels(ctx, currentRegion)
+		if err != nil {
+			result.Close()
+			return nil, nil, err
+		}
 		if nonZeroPixels == 0 {
 			break
 		}
 
-		// Calculate threshold for current region
-		threshold := p.calculateThreshold(currentRegion, params)
+		// Calculate threshold for current region; a warm start applies to the
+		// first iteration only
+		var threshold float64
+		if iteration == 0 && hasWarmStart {
+			threshold, err = p.refineThreshold(ctx, currentRegion, warmStart, params)
+		} else {
+			threshold, err = p.calculateThreshold(ctx, currentRegion, params)
+		}
+		if err != nil {
+			result.Close()
+			return nil, nil, err
+		}
 
 		// Check convergence
-		convergence := math.Abs(threshold - previousThreshold)
-		if convergence < convergencePrecision && iteration > 0 {
+		delta := math.Abs(threshold - previousThreshold)
+		if delta < convergencePrecision && iteration > 0 {
 			break
 		}
 		previousThreshold = threshold
 
 		// Segment current region into foreground, background, and TBD
-		foreground, background, tbd, err := p.segmentRegion(currentRegion, threshold, params)
+		foreground, background, tbd, err := p.segmentRegion(ctx, currentRegion, threshold, params)
 		if err != nil {
 			result.Close()
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Update result with foreground pixels
-		p.updateResult(result, foreground)
+		err = p.updateResult(ctx, result, foreground)
 
 		foreground.Close()
 		background.Close()
+		if err != nil {
+			tbd.Close()
+			result.Close()
+			return nil, nil, err
+		}
 
 		// Check TBD fraction
-		tbdCount := p.countNonZeroPixels(tbd)
+		tbdCount, err := p.countNonZeroPixels(ctx, tbd)
+		if err != nil {
+			tbd.Close()
+			result.Close()
+			return nil, nil, err
+		}
 		tbdFraction := float64(tbdCount) / totalPixels
 
-		if tbdFraction < minTBDFraction {
+		history = append(history, convergence.ConvergenceRecord{
+			Iteration:   iteration + 1,
+			Threshold:   threshold,
+			Delta:       delta,
+			TBDFraction: tbdFraction,
+		})
+		if handler != nil {
+			handler(iteration+1, maxIterations, threshold, delta)
+		}
+
+		if tbdFraction < minTBDFraction && !useGraphCut {
 			tbd.Close()
 			break
 		}
 
 		// Extract TBD region for next iteration
-		newRegion, err := p.extractTBDRegion(input, tbd)
+		newRegion, err := p.extractTBDRegion(ctx, input, tbd)
 		tbd.Close()
 		if err != nil {
 			result.Close()
-			return nil, err
+			return nil, nil, err
 		}
 
 		currentRegion.Close()
 		currentRegion = newRegion
+
+		// The graph cut below resolves the small TBD region that is left
+		if tbdFraction < minTBDFraction {
+			break
+		}
 	}
 
-	return result, nil
+	// The nonzero pixels of currentRegion are those no iteration classified
+	if useGraphCut {
+		cutCtx, span := tracer.Start(ctx, "graph_cut")
+		err := p.resolveTBDWithGraphCut(cutCtx, result, currentRegion)
+		span.End()
+		if err != nil {
+			result.Close()
+			return nil, nil, fmt.Errorf("graph cut failed: %w", err)
+		}
+	}
+
+	return result, history, nil
 }
 
-func (p *Processor) calculateThreshold(region *safe.Mat, params map[string]interface{}) float64 {
+// segmentWithThreshold marks pixels of region above value as foreground in
+// result, leaving no to-be-determined band
+func (p *Processor) segmentWithThreshold(ctx context.Context, result, region *safe.Mat, value float64, params map[string]interface{}) error {
+	binaryParams := make(map[string]interface{}, len(params)+1)
+	for key, param := range params {
+		binaryParams[key] = param
+	}
+	binaryParams["class_separation"] = 0.0
+
+	foreground, background, tbd, err := p.segmentRegion(ctx, region, value, binaryParams)
+	if err != nil {
+		return err
+	}
+	defer foreground.Close()
+	defer background.Close()
+	defer tbd.Close()
+
+	return p.updateResult(ctx, result, foreground)
+}
+
+func (p *Processor) calculateThreshold(ctx context.Context, region *safe.Mat, params map[string]interface{}) (float64, error) {
 	method := p.getStringParam(params, "initial_threshold_method", "otsu")
-	histogram := p.buildHistogram(region)
+	histogram, err := p.buildHistogram(ctx, region)
+	if err != nil {
+		return 0, err
+	}
 
 	switch method {
 	case "mean":
-		return p.calculateMeanThreshold(histogram)
+		return p.calculateMeanThreshold(histogram), nil
 	case "median":
-		return p.calculateMedianThreshold(histogram)
+		return p.calculateMedianThreshold(histogram), nil
 	case "triangle":
-		return p.calculateTriangleThreshold(histogram)
+		return p.calculateTriangleThreshold(histogram), nil
 	default:
-		return p.calculateOtsuThreshold(histogram)
+		return p.calculateOtsuThreshold(histogram), nil
 	}
 }
 
-func (p *Processor) buildHistogram(src *safe.Mat) []int {
+// refineThreshold is calculateThreshold warm started at start: the Otsu search
+// is limited to warmStartRadius levels around it. The other initial methods
+// are closed-form and ignore the warm start.
+func (p *Processor) refineThreshold(ctx context.Context, region *safe.Mat, start float64, params map[string]interface{}) (float64, error) {
+	if p.getStringParam(params, "initial_threshold_method", "otsu") != "otsu" {
+		return p.calculateThreshold(ctx, region, params)
+	}
+
+	histogram, err := p.buildHistogram(ctx, region)
+	if err != nil {
+		return 0, err
+	}
+
+	low := max(0, int(start)-warmStartRadius)
+	high := min(255, int(start)+warmStartRadius)
+	return p.calculateOtsuThresholdIn(histogram, low, high), nil
+}
+
+// buildHistogram counts the non-zero pixels of src. Like the other pixel
+// loops below it checks for cancellation every rowsPerCheck rows, about a
+// hundred times per image.
+func (p *Processor) buildHistogram(ctx context.Context, src *safe.Mat) ([]int, error) {
 	histogram := make([]int, 256)
 	rows := src.Rows()
 	cols := src.Cols()
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			val, err := src.GetUCharAt(y, x)
 			if err == nil && val > 0 {
@@ -413,10 +764,16 @@ func (p *Processor) buildHistogram(src *safe.Mat) []int {
 		}
 	}
 
-	return histogram
+	return histogram, nil
 }
 
 func (p *Processor) calculateOtsuThreshold(histogram []int) float64 {
+	return p.calculateOtsuThresholdIn(histogram, 0, 255)
+}
+
+// calculateOtsuThresholdIn returns the Otsu threshold among the levels low
+// to high
+func (p *Processor) calculateOtsuThresholdIn(histogram []int, low, high int) float64 {
 	total := 0
 	for _, count := range histogram {
 		total += count
@@ -436,8 +793,14 @@ func (p *Processor) calculateOtsuThreshold(histogram []int) float64 {
 	maxVariance := 0.0
 	bestThreshold := 127.5
 
-	for i := 0; i < 256; i++ {
+	for i := 0; i < low; i++ {
+		wB += histogram[i]
+		sumB += float64(i) * float64(histogram[i])
+	}
+
+	for i := low; i <= high; i++ {
 		wB += histogram[i]
+		sumB += float64(i) * float64(histogram[i])
 		if wB == 0 {
 			continue
 		}
@@ -447,7 +810,6 @@ func (p *Processor) calculateOtsuThreshold(histogram []int) float64 {
 			break
 		}
 
-		sumB += float64(i) * float64(histogram[i])
 		mB := sumB / float64(wB)
 		mF := (sum - sumB) / float64(wF)
 
@@ -558,7 +920,7 @@ func (p *Processor) calculateTriangleThreshold(histogram []int) float64 {
 	return bestThreshold
 }
 
-func (p *Processor) segmentRegion(region *safe.Mat, threshold float64, params map[string]interface{}) (*safe.Mat, *safe.Mat, *safe.Mat, error) {
+func (p *Processor) segmentRegion(ctx context.Context, region *safe.Mat, threshold float64, params map[string]interface{}) (*safe.Mat, *safe.Mat, *safe.Mat, error) {
 	rows := region.Rows()
 	cols := region.Cols()
 
@@ -584,7 +946,16 @@ func (p *Processor) segmentRegion(region *safe.Mat, threshold float64, params ma
 	lowerThreshold := threshold * (1.0 - classSeparation)
 	upperThreshold := threshold * (1.0 + classSeparation)
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				foreground.Close()
+				background.Close()
+				tbd.Close()
+				return nil, nil, nil, err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			pixelValue, err := region.GetUCharAt(y, x)
 			if err != nil {
@@ -607,20 +978,27 @@ func (p *Processor) segmentRegion(region *safe.Mat, threshold float64, params ma
 	return foreground, background, tbd, nil
 }
 
-func (p *Processor) updateResult(result, foregroundMask *safe.Mat) {
+func (p *Processor) updateResult(ctx context.Context, result, foregroundMask *safe.Mat) error {
 	rows := result.Rows()
 	cols := result.Cols()
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			if value, err := foregroundMask.GetUCharAt(y, x); err == nil && value > 0 {
 				result.SetUCharAt(y, x, 255)
 			}
 		}
 	}
+	return nil
 }
 
-func (p *Processor) extractTBDRegion(original, tbdMask *safe.Mat) (*safe.Mat, error) {
+func (p *Processor) extractTBDRegion(ctx context.Context, original, tbdMask *safe.Mat) (*safe.Mat, error) {
 	result, err := safe.NewMat(original.Rows(), original.Cols(), original.Type())
 	if err != nil {
 		return nil, err
@@ -629,7 +1007,14 @@ func (p *Processor) extractTBDRegion(original, tbdMask *safe.Mat) (*safe.Mat, er
 	rows := original.Rows()
 	cols := original.Cols()
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				result.Close()
+				return nil, err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			if tbdValue, err := tbdMask.GetUCharAt(y, x); err == nil && tbdValue > 0 {
 				if origValue, err := original.GetUCharAt(y, x); err == nil {
@@ -642,12 +1027,18 @@ func (p *Processor) extractTBDRegion(original, tbdMask *safe.Mat) (*safe.Mat, er
 	return result, nil
 }
 
-func (p *Processor) countNonZeroPixels(mat *safe.Mat) int {
+func (p *Processor) countNonZeroPixels(ctx context.Context, mat *safe.Mat) (int, error) {
 	rows := mat.Rows()
 	cols := mat.Cols()
 	count := 0
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			if value, err := mat.GetUCharAt(y, x); err == nil && value > 0 {
 				count++
@@ -655,27 +1046,134 @@ func (p *Processor) countNonZeroPixels(mat *safe.Mat) int {
 		}
 	}
 
-	return count
+	return count, nil
 }
 
 func (p *Processor) applyPostprocessing(src *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	smallKernel := p.getIntParam(params, "cleanup_kernel_small", 3)
+	largeKernel := p.getIntParam(params, "cleanup_kernel_large", 5)
+	iterations := p.getIntParam(params, "cleanup_iterations", 1)
+
+	if adaptive, ok := params["adaptive_cleanup_kernels"].(bool); ok && adaptive {
+		smallKernel, largeKernel = ComputeAdaptiveKernels(src.Rows(), src.Cols(), p.getFloatParam(params, "image_ppi", 0))
+	}
+
 	// Apply morphological opening
-	opened, err := p.applyMorphologicalOperation(src, gocv.MorphOpen, 3)
+	opened, err := p.applyMorphologicalOperation(src, gocv.MorphOpen, smallKernel, iterations)
 	if err != nil {
 		return nil, err
 	}
 	defer opened.Close()
 
 	// Apply morphological closing
-	result, err := p.applyMorphologicalOperation(opened, gocv.MorphClose, 5)
+	closed, err := p.applyMorphologicalOperation(opened, gocv.MorphClose, largeKernel, iterations)
 	if err != nil {
 		return nil, err
 	}
 
+	// Drop specks the opening left behind and, if asked, oversized blobs
+	minArea, maxArea := p.objectAreaLimits(params, src.Rows(), src.Cols())
+	if minArea <= 1 && maxArea <= 0 {
+		return closed, nil
+	}
+	defer closed.Close()
+
+	result, removed, err := labelling.FilterMatByArea(closed, minArea, maxArea)
+	if err != nil {
+		return nil, fmt.Errorf("object area filtering failed: %w", err)
+	}
+
+	p.mu.RLock()
+	log := p.logger
+	p.mu.RUnlock()
+	if log != nil && removed > 0 {
+		log.Debug("Objects removed by area", map[string]interface{}{
+			"removed":  removed,
+			"min_area": minArea,
+			"max_area": maxArea,
+		})
+	}
+
 	return result, nil
 }
 
-func (p *Processor) applyMorphologicalOperation(src *safe.Mat, op gocv.MorphType, kernelSize int) (*safe.Mat, error) {
+// objectAreaLimits returns the smallest and largest object areas, in pixels,
+// the cleanup keeps. An explicit min_object_area wins over
+// min_object_area_fraction of the image; a largest area of zero is unlimited.
+func (p *Processor) objectAreaLimits(params map[string]interface{}, rows, cols int) (int, int) {
+	minArea := p.getIntParam(params, "min_object_area", 0)
+	if minArea <= 0 {
+		fraction := p.getFloatParam(params, "min_object_area_fraction", 0.001)
+		minArea = int(math.Round(fraction * float64(rows*cols)))
+	}
+	return minArea, p.getIntParam(params, "max_object_area", 0)
+}
+
+// applyWatershed splits touching objects of the binary result and returns
+// the mask with the watershed lines between them cleared and the number of
+// objects found
+func (p *Processor) applyWatershed(ctx context.Context, src *safe.Mat, params map[string]interface{}) (*safe.Mat, int, error) {
+	ctx, span := tracer.Start(ctx, "watershed")
+	defer span.End()
+
+	processor := watershed.NewWatershedPostProcessor(p.getIntParam(params, "watershed_min_distance", watershed.DefaultMinDistance))
+	separated, err := processor.Apply(ctx, src)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer separated.Close()
+
+	mask := gocv.NewMat()
+	defer mask.Close()
+	if err := gocv.InRangeWithScalar(separated.Labels.GetMat(), gocv.NewScalar(1, 0, 0, 0), gocv.NewScalar(float64(separated.Count), 0, 0, 0), &mask); err != nil {
+		return nil, 0, fmt.Errorf("object mask failed: %w", err)
+	}
+
+	result, err := safe.NewMatFromMat(mask)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, separated.Count, nil
+}
+
+// DefaultTargetPPI is the pixel density at which the 3 and 5 pixel cleanup
+// kernels match the size of typical noise specks
+const DefaultTargetPPI = 72.0
+
+// ComputeAdaptiveKernels returns odd opening and closing kernel sizes for an
+// image of targetPPI pixels per inch. Noise specks have a roughly fixed
+// physical size, so kernels grow linearly with the density: a 300 PPI scan
+// needs far larger kernels than a 72 PPI screen image. A targetPPI of zero
+// means the density is unknown and falls back to the image size.
+func ComputeAdaptiveKernels(rows, cols int, targetPPI float64) (small, large int) {
+	if targetPPI <= 0 {
+		// Images over one megapixel are usually photographs or scans
+		if rows*cols > 1_000_000 {
+			return 5, 7
+		}
+		return 3, 5
+	}
+
+	scale := targetPPI / DefaultTargetPPI
+	small = clampOddKernel(nearestOdd(3*scale), 1, 9)
+	large = clampOddKernel(nearestOdd(5*scale), 3, 15)
+	if large <= small {
+		large = clampOddKernel(small+2, 3, 15)
+	}
+	return small, large
+}
+
+// nearestOdd rounds value to the closest odd integer
+func nearestOdd(value float64) int {
+	return 2*int(math.Round((value-1)/2)) + 1
+}
+
+// clampOddKernel limits an odd kernel size to [minSize, maxSize]
+func clampOddKernel(size, minSize, maxSize int) int {
+	return max(minSize, min(size, maxSize))
+}
+
+func (p *Processor) applyMorphologicalOperation(src *safe.Mat, op gocv.MorphType, kernelSize, iterations int) (*safe.Mat, error) {
 	result, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
 	if err != nil {
 		return nil, err
@@ -686,7 +1184,10 @@ func (p *Processor) applyMorphologicalOperation(src *safe.Mat, op gocv.MorphType
 
 	srcMat := src.GetMat()
 	resultMat := result.GetMat()
-	gocv.MorphologyEx(srcMat, &resultMat, op, kernel)
+	if err := gocv.MorphologyExWithParams(srcMat, &resultMat, op, kernel, iterations, gocv.BorderConstant); err != nil {
+		result.Close()
+		return nil, fmt.Errorf("morphological operation failed: %w", err)
+	}
 
 	return result, nil
 }
@@ -712,17 +1213,3 @@ func (p *Processor) getStringParam(params map[string]interface{}, key string, de
 	}
 	return defaultValue
 }
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,35 @@
+package triclass
+
+import "testing"
+
+func TestComputeAdaptiveKernels(t *testing.T) {
+	tests := []struct {
+		name       string
+		rows, cols int
+		ppi        float64
+		small      int
+		large      int
+	}{
+		{name: "72 ppi screenshot", rows: 1080, cols: 1920, ppi: 72, small: 3, large: 5},
+		{name: "150 ppi letter page", rows: 1650, cols: 1275, ppi: 150, small: 7, large: 11},
+		// 300 and 600 ppi would need 13x21 and 25x41 kernels; both clamp
+		{name: "300 ppi letter scan", rows: 3300, cols: 2550, ppi: 300, small: 9, large: 15},
+		{name: "600 ppi letter scan", rows: 6600, cols: 5100, ppi: 600, small: 9, large: 15},
+		// Without a density the image size decides
+		{name: "unknown density photograph", rows: 1080, cols: 1920, ppi: 0, small: 5, large: 7},
+		{name: "unknown density small image", rows: 480, cols: 640, ppi: 0, small: 3, large: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			small, large := ComputeAdaptiveKernels(tt.rows, tt.cols, tt.ppi)
+			if small != tt.small || large != tt.large {
+				t.Errorf("ComputeAdaptiveKernels(%d, %d, %g) = %d, %d, want %d, %d",
+					tt.rows, tt.cols, tt.ppi, small, large, tt.small, tt.large)
+			}
+			if small%2 == 0 || large%2 == 0 || small >= large {
+				t.Errorf("kernels %d and %d must be odd with the small one smaller", small, large)
+			}
+		})
+	}
+}
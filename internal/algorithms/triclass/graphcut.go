@@ -0,0 +1,476 @@
+package triclass
+
+import (
+	"context"
+	"math"
+
+	"otsu-obliterator/internal/opencv/safe"
+)
+
+// graphCutSmoothness weights the neighbour terms of the TBD energy against
+// the terminal terms, which are normalised to sum to 1 per pixel
+const graphCutSmoothness = 0.5
+
+// graphCutCheckInterval is how many augmentations run between context checks
+const graphCutCheckInterval = 1024
+
+// resolveTBDWithGraphCut assigns the pixels still to be determined, the
+// nonzero pixels of region, by a minimum cut. Each TBD pixel links to the
+// source (foreground) and sink (background) with capacities from its distance
+// to the nearest foreground and background pixel of result, and to its TBD
+// 4-neighbours with a capacity that falls with their intensity difference.
+// Pixels on the source side of the cut are set in result.
+func (p *Processor) resolveTBDWithGraphCut(ctx context.Context, result, region *safe.Mat) error {
+	rows := region.Rows()
+	cols := region.Cols()
+
+	index := make([]int, rows*cols)
+	foreground := make([]bool, rows*cols)
+	background := make([]bool, rows*cols)
+	var intensity []float64
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			i := y*cols + x
+			index[i] = -1
+
+			if value, err := region.GetUCharAt(y, x); err == nil && value > 0 {
+				index[i] = len(intensity)
+				intensity = append(intensity, float64(value))
+				continue
+			}
+			if value, err := result.GetUCharAt(y, x); err == nil && value > 0 {
+				foreground[i] = true
+			} else {
+				background[i] = true
+			}
+		}
+	}
+	if len(intensity) == 0 {
+		return nil
+	}
+
+	distForeground := chamferDistance(foreground, rows, cols)
+	distBackground := chamferDistance(background, rows, cols)
+
+	// beta normalises intensity differences by their mean over TBD edges,
+	// so the neighbour terms adapt to the contrast of the region
+	var sumSq float64
+	var edges int
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			i := y*cols + x
+			if index[i] < 0 {
+				continue
+			}
+			if x+1 < cols && index[i+1] >= 0 {
+				diff := intensity[index[i]] - intensity[index[i+1]]
+				sumSq += diff * diff
+				edges++
+			}
+			if y+1 < rows && index[i+cols] >= 0 {
+				diff := intensity[index[i]] - intensity[index[i+cols]]
+				sumSq += diff * diff
+				edges++
+			}
+		}
+	}
+	beta := 0.0
+	if sumSq > 0 {
+		beta = float64(edges) / (2 * sumSq)
+	}
+
+	graph := newBKGraph(len(intensity))
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			i := y*cols + x
+			node := index[i]
+			if node < 0 {
+				continue
+			}
+
+			// The nearer class pulls harder; a missing class does not pull
+			dF, dB := distForeground[i], distBackground[i]
+			switch {
+			case math.IsInf(dF, 1) && math.IsInf(dB, 1):
+				graph.addTerminal(node, 0.5, 0.5)
+			case math.IsInf(dF, 1):
+				graph.addTerminal(node, 0, 1)
+			case math.IsInf(dB, 1):
+				graph.addTerminal(node, 1, 0)
+			default:
+				graph.addTerminal(node, dB/(dF+dB), dF/(dF+dB))
+			}
+
+			// Right and lower neighbours, so each pair is linked once
+			var neighbours []int
+			if x+1 < cols {
+				neighbours = append(neighbours, i+1)
+			}
+			if y+1 < rows {
+				neighbours = append(neighbours, i+cols)
+			}
+			for _, j := range neighbours {
+				if index[j] < 0 {
+					continue
+				}
+				diff := intensity[node] - intensity[index[j]]
+				weight := graphCutSmoothness * math.Exp(-beta*diff*diff)
+				graph.addEdge(node, index[j], weight, weight)
+			}
+		}
+	}
+
+	if err := graph.maxFlow(ctx); err != nil {
+		return err
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			if node := index[y*cols+x]; node >= 0 && graph.inSourceTree(node) {
+				result.SetUCharAt(y, x, 255)
+			}
+		}
+	}
+
+	return nil
+}
+
+// chamferDistance returns the two-pass chamfer approximation of the Euclidean
+// distance from every pixel to the nearest seed, +Inf when there are none
+func chamferDistance(seeds []bool, rows, cols int) []float64 {
+	const straight, diagonal = 1.0, math.Sqrt2
+
+	dist := make([]float64, rows*cols)
+	for i, seed := range seeds {
+		if seed {
+			dist[i] = 0
+		} else {
+			dist[i] = math.Inf(1)
+		}
+	}
+
+	relax := func(i, y, x int, weight float64) {
+		if y < 0 || y >= rows || x < 0 || x >= cols {
+			return
+		}
+		if d := dist[y*cols+x] + weight; d < dist[i] {
+			dist[i] = d
+		}
+	}
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			i := y*cols + x
+			relax(i, y-1, x-1, diagonal)
+			relax(i, y-1, x, straight)
+			relax(i, y-1, x+1, diagonal)
+			relax(i, y, x-1, straight)
+		}
+	}
+	for y := rows - 1; y >= 0; y-- {
+		for x := cols - 1; x >= 0; x-- {
+			i := y*cols + x
+			relax(i, y+1, x+1, diagonal)
+			relax(i, y+1, x, straight)
+			relax(i, y+1, x-1, diagonal)
+			relax(i, y, x+1, straight)
+		}
+	}
+
+	return dist
+}
+
+// Parent markers of bkGraph nodes that have no parent arc
+const (
+	bkNoParent = -1
+	bkTerminal = -2
+	bkOrphan   = -3
+)
+
+const (
+	bkFree = iota
+	bkSource
+	bkSink
+)
+
+type bkNode struct {
+	firstArc int
+	parent   int
+	tree     int
+	// residual is the remaining source capacity when positive and the
+	// remaining sink capacity when negative
+	residual float64
+	active   bool
+}
+
+type bkArc struct {
+	head     int
+	next     int
+	capacity float64
+}
+
+// bkGraph computes a maximum flow with the Boykov-Kolmogorov algorithm, which
+// grows search trees from both terminals and reuses them between
+// augmentations. Arcs are stored in pairs, so the reverse of arc a is a^1.
+type bkGraph struct {
+	nodes   []bkNode
+	arcs    []bkArc
+	queue   []int
+	orphans []int
+	flow    float64
+}
+
+func newBKGraph(nodeCount int) *bkGraph {
+	g := &bkGraph{
+		nodes: make([]bkNode, nodeCount),
+		arcs:  make([]bkArc, 0, 4*nodeCount),
+	}
+	for i := range g.nodes {
+		g.nodes[i].firstArc = -1
+		g.nodes[i].parent = bkNoParent
+	}
+	return g
+}
+
+// addTerminal adds capacities from the source to node and from node to the
+// sink. Only their difference needs routing through the graph.
+func (g *bkGraph) addTerminal(node int, toSource, toSink float64) {
+	g.nodes[node].residual += toSource - toSink
+	g.flow += math.Min(toSource, toSink)
+}
+
+// addEdge adds an arc from i to j with capacity and one back with reverse
+func (g *bkGraph) addEdge(i, j int, capacity, reverse float64) {
+	g.arcs = append(g.arcs,
+		bkArc{head: j, next: g.nodes[i].firstArc, capacity: capacity},
+		bkArc{head: i, next: g.nodes[j].firstArc, capacity: reverse},
+	)
+	g.nodes[i].firstArc = len(g.arcs) - 2
+	g.nodes[j].firstArc = len(g.arcs) - 1
+}
+
+// inSourceTree reports whether node ended on the source side of the cut
+func (g *bkGraph) inSourceTree(node int) bool {
+	return g.nodes[node].tree == bkSource
+}
+
+// tail returns the node arc a leaves
+func (g *bkGraph) tail(a int) int {
+	return g.arcs[a^1].head
+}
+
+func (g *bkGraph) activate(node int) {
+	if !g.nodes[node].active {
+		g.nodes[node].active = true
+		g.queue = append(g.queue, node)
+	}
+}
+
+func (g *bkGraph) maxFlow(ctx context.Context) error {
+	for i := range g.nodes {
+		switch {
+		case g.nodes[i].residual > 0:
+			g.nodes[i].tree = bkSource
+		case g.nodes[i].residual < 0:
+			g.nodes[i].tree = bkSink
+		default:
+			continue
+		}
+		g.nodes[i].parent = bkTerminal
+		g.activate(i)
+	}
+
+	for augmentations := 1; ; augmentations++ {
+		if augmentations%graphCutCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		bridge := g.grow()
+		if bridge < 0 {
+			return nil
+		}
+		g.augment(bridge)
+		g.adopt()
+	}
+}
+
+// grow extends the trees from active nodes until they touch and returns the
+// arc joining them, directed from the source tree to the sink tree, or -1
+// when no augmenting path remains
+func (g *bkGraph) grow() int {
+	for len(g.queue) > 0 {
+		i := g.queue[0]
+		node := &g.nodes[i]
+		if node.tree == bkFree {
+			g.queue = g.queue[1:]
+			node.active = false
+			continue
+		}
+
+		for a := node.firstArc; a >= 0; a = g.arcs[a].next {
+			// Source trees grow along arcs out of i, sink trees along arcs into i
+			capacity := g.arcs[a].capacity
+			if node.tree == bkSink {
+				capacity = g.arcs[a^1].capacity
+			}
+			if capacity <= 0 {
+				continue
+			}
+
+			j := g.arcs[a].head
+			switch g.nodes[j].tree {
+			case bkFree:
+				g.nodes[j].tree = node.tree
+				g.nodes[j].parent = a ^ 1
+				g.activate(j)
+			case node.tree:
+			default:
+				if node.tree == bkSource {
+					return a
+				}
+				return a ^ 1
+			}
+		}
+
+		g.queue = g.queue[1:]
+		node.active = false
+	}
+
+	return -1
+}
+
+// augment pushes the bottleneck flow along the path through bridge and
+// orphans the nodes whose parent arc it saturates
+func (g *bkGraph) augment(bridge int) {
+	bottleneck := g.arcs[bridge].capacity
+
+	i := g.tail(bridge)
+	for g.nodes[i].parent != bkTerminal {
+		a := g.nodes[i].parent
+		bottleneck = math.Min(bottleneck, g.arcs[a^1].capacity)
+		i = g.arcs[a].head
+	}
+	bottleneck = math.Min(bottleneck, g.nodes[i].residual)
+
+	j := g.arcs[bridge].head
+	for g.nodes[j].parent != bkTerminal {
+		a := g.nodes[j].parent
+		bottleneck = math.Min(bottleneck, g.arcs[a].capacity)
+		j = g.arcs[a].head
+	}
+	bottleneck = math.Min(bottleneck, -g.nodes[j].residual)
+
+	g.arcs[bridge].capacity -= bottleneck
+	g.arcs[bridge^1].capacity += bottleneck
+
+	i = g.tail(bridge)
+	for g.nodes[i].parent != bkTerminal {
+		a := g.nodes[i].parent
+		g.arcs[a^1].capacity -= bottleneck
+		g.arcs[a].capacity += bottleneck
+		if g.arcs[a^1].capacity <= 0 {
+			g.orphan(i)
+		}
+		i = g.arcs[a].head
+	}
+	g.nodes[i].residual -= bottleneck
+	if g.nodes[i].residual <= 0 {
+		g.orphan(i)
+	}
+
+	j = g.arcs[bridge].head
+	for g.nodes[j].parent != bkTerminal {
+		a := g.nodes[j].parent
+		g.arcs[a].capacity -= bottleneck
+		g.arcs[a^1].capacity += bottleneck
+		if g.arcs[a].capacity <= 0 {
+			g.orphan(j)
+		}
+		j = g.arcs[a].head
+	}
+	g.nodes[j].residual += bottleneck
+	if g.nodes[j].residual >= 0 {
+		g.orphan(j)
+	}
+
+	g.flow += bottleneck
+}
+
+func (g *bkGraph) orphan(node int) {
+	g.nodes[node].parent = bkOrphan
+	g.orphans = append(g.orphans, node)
+}
+
+// adopt finds each orphan a new parent in its own tree still connected to the
+// terminal, or frees it and orphans its children
+func (g *bkGraph) adopt() {
+	for len(g.orphans) > 0 {
+		i := g.orphans[len(g.orphans)-1]
+		g.orphans = g.orphans[:len(g.orphans)-1]
+		tree := g.nodes[i].tree
+
+		for a := g.nodes[i].firstArc; a >= 0; a = g.arcs[a].next {
+			j := g.arcs[a].head
+			if g.nodes[j].tree == tree && g.treeCapacity(tree, a) > 0 && g.rooted(j) {
+				g.nodes[i].parent = a
+				break
+			}
+		}
+		if g.nodes[i].parent != bkOrphan {
+			continue
+		}
+
+		for a := g.nodes[i].firstArc; a >= 0; a = g.arcs[a].next {
+			j := g.arcs[a].head
+			if g.nodes[j].tree != tree {
+				continue
+			}
+			if g.treeCapacity(tree, a) > 0 {
+				g.activate(j)
+			}
+			if parent := g.nodes[j].parent; parent >= 0 && g.arcs[parent].head == i {
+				g.orphan(j)
+			}
+		}
+
+		g.nodes[i].tree = bkFree
+		g.nodes[i].parent = bkNoParent
+	}
+}
+
+// treeCapacity returns the residual capacity a tree of the given kind could
+// use to reach the tail of arc a from its head
+func (g *bkGraph) treeCapacity(tree, a int) float64 {
+	if tree == bkSource {
+		return g.arcs[a^1].capacity
+	}
+	return g.arcs[a].capacity
+}
+
+// rooted reports whether the parent chain of node reaches its terminal
+func (g *bkGraph) rooted(node int) bool {
+	for {
+		switch parent := g.nodes[node].parent; parent {
+		case bkTerminal:
+			return true
+		case bkOrphan, bkNoParent:
+			return false
+		default:
+			node = g.arcs[parent].head
+		}
+	}
+}
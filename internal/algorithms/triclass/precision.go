@@ -0,0 +1,39 @@
+package triclass
+
+import (
+	"math"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// AutoConvergencePrecision is the convergence_precision value that derives
+// the precision from the dynamic range of the image
+const AutoConvergencePrecision = 0.0
+
+const (
+	minAdaptivePrecision = 0.1
+	maxAdaptivePrecision = 5.0
+)
+
+// ComputeAdaptiveEpsilon scales the convergence precision with the intensity
+// range actually present in the image. A fixed precision of 1.0 is too coarse
+// for a low contrast image spanning only a few dozen levels, where the
+// threshold keeps moving by less than a level without settling. With histBins
+// coarser than 256 thresholds move in whole bins, so the precision never drops
+// below half a bin.
+func ComputeAdaptiveEpsilon(dynamicRange int, histBins int) float64 {
+	epsilon := float64(dynamicRange) / 255.0
+	if histBins > 0 && histBins < 256 {
+		epsilon = math.Max(epsilon, 128.0/float64(histBins))
+	}
+	return math.Max(minAdaptivePrecision, math.Min(epsilon, maxAdaptivePrecision))
+}
+
+// dynamicRange returns the spread between the darkest and brightest pixel of
+// a single channel image
+func dynamicRange(src *safe.Mat) int {
+	minVal, maxVal, _, _ := gocv.MinMaxLoc(src.GetMat())
+	return int(maxVal - minVal)
+}
@@ -0,0 +1,93 @@
+package triclass
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// lowContrastImage returns a side by side image whose left half holds noise
+// in 100-108 and right half noise in 112-120, a dynamic range of 20 levels
+func lowContrastImage(t *testing.T, side int) *safe.Mat {
+	t.Helper()
+
+	img := gocv.NewMatWithSize(side, side, gocv.MatTypeCV8UC1)
+	defer img.Close()
+
+	left := img.Region(image.Rect(0, 0, side/2, side))
+	gocv.RandU(&left, gocv.NewScalar(100, 0, 0, 0), gocv.NewScalar(109, 0, 0, 0))
+	left.Close()
+
+	right := img.Region(image.Rect(side/2, 0, side, side))
+	gocv.RandU(&right, gocv.NewScalar(112, 0, 0, 0), gocv.NewScalar(121, 0, 0, 0))
+	right.Close()
+
+	mat, err := safe.NewMatFromMat(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mat.Close() })
+	return mat
+}
+
+func TestComputeAdaptiveEpsilon(t *testing.T) {
+	tests := []struct {
+		name         string
+		dynamicRange int
+		histBins     int
+		want         float64
+	}{
+		{name: "full range", dynamicRange: 255, want: 1},
+		{name: "half range", dynamicRange: 51, want: 0.2},
+		{name: "low contrast clamps to the minimum", dynamicRange: 20, want: minAdaptivePrecision},
+		{name: "flat image", dynamicRange: 0, want: minAdaptivePrecision},
+		{name: "coarse bins keep half a bin", dynamicRange: 20, histBins: 64, want: 2},
+		{name: "full bins ignored", dynamicRange: 255, histBins: 256, want: 1},
+		{name: "very coarse bins clamp to the maximum", dynamicRange: 255, histBins: 16, want: maxAdaptivePrecision},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputeAdaptiveEpsilon(tt.dynamicRange, tt.histBins); got != tt.want {
+				t.Errorf("ComputeAdaptiveEpsilon(%d, %d) = %g, want %g", tt.dynamicRange, tt.histBins, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveEpsilonConvergesOnLowContrastImage(t *testing.T) {
+	const maxIterations = 15
+
+	input := lowContrastImage(t, 128)
+	if spread := dynamicRange(input); spread != 20 {
+		t.Fatalf("dynamic range = %d, want 20", spread)
+	}
+
+	p := NewProcessor()
+	params := p.GetDefaultParameters()
+	params["convergence_precision"] = AutoConvergencePrecision
+	params["max_iterations"] = maxIterations
+	// A narrow band leaves a to-be-determined region inside 100-120, so the
+	// iterations have work to do
+	params["class_separation"] = 0.05
+
+	result, history, err := p.performIterativeSegmentation(context.Background(), input, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer result.Close()
+
+	if len(history) == 0 || len(history) >= maxIterations {
+		t.Fatalf("ran %d iterations, want convergence within %d", len(history), maxIterations)
+	}
+	for _, record := range history {
+		if record.Threshold < 100 || record.Threshold > 120 {
+			t.Errorf("iteration %d threshold = %g, want it within the 100-120 range of the image",
+				record.Iteration, record.Threshold)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package watershed
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// DefaultMinDistance is the default smallest distance, in pixels, between
+// two object centres
+const DefaultMinDistance = 5
+
+// Result holds the objects a WatershedPostProcessor found. Labels is a CV32S
+// Mat where each object has a unique label from 1 to Count and background and
+// the lines between touching objects are 0. Overlay is an 8-bit BGR image with
+// every object in its own colour and the separating lines in white.
+type Result struct {
+	Labels  *safe.Mat
+	Overlay *safe.Mat
+	Count   int
+}
+
+// Close releases the labels and overlay
+func (r *Result) Close() {
+	if r.Labels != nil {
+		r.Labels.Close()
+	}
+	if r.Overlay != nil {
+		r.Overlay.Close()
+	}
+}
+
+// WatershedPostProcessor splits touching objects of a binary mask. Each
+// foreground pixel is given its distance to the background, the local maxima
+// of that distance, the centres of the objects, seed one marker each, and
+// OpenCV's watershed floods the foreground from the markers until the fronts
+// meet. MinDistance suppresses maxima closer than that to a larger one, so an
+// elongated object is not cut into several.
+type WatershedPostProcessor struct {
+	MinDistance int
+}
+
+func NewWatershedPostProcessor(minDistance int) *WatershedPostProcessor {
+	return &WatershedPostProcessor{MinDistance: max(1, minDistance)}
+}
+
+// Apply labels the objects of an 8-bit single channel mask, where nonzero
+// pixels are foreground. The caller owns the result.
+func (wp *WatershedPostProcessor) Apply(ctx context.Context, mask *safe.Mat) (*Result, error) {
+	if err := safe.ValidateMatForOperation(mask, "watershed"); err != nil {
+		return nil, err
+	}
+	if mask.Channels() != 1 {
+		return nil, fmt.Errorf("watershed requires a single channel mask, got %d channels", mask.Channels())
+	}
+
+	foreground := gocv.NewMat()
+	defer foreground.Close()
+	gocv.Threshold(mask.GetMat(), &foreground, 0, 255, gocv.ThresholdBinary)
+
+	distance := gocv.NewMat()
+	defer distance.Close()
+	nearest := gocv.NewMat()
+	defer nearest.Close()
+	if err := gocv.DistanceTransform(foreground, &distance, &nearest, gocv.DistL2, gocv.DistanceMask5, gocv.DistanceLabelCComp); err != nil {
+		return nil, fmt.Errorf("distance transform failed: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	seeds, err := wp.findSeeds(distance, foreground)
+	if err != nil {
+		return nil, err
+	}
+	defer seeds.Close()
+
+	markers := gocv.NewMat()
+	defer markers.Close()
+	count := gocv.ConnectedComponents(seeds, &markers) - 1
+
+	// Background floods from a marker of its own, one above the objects
+	background := gocv.NewMat()
+	defer background.Close()
+	if err := gocv.BitwiseNot(foreground, &background); err != nil {
+		return nil, fmt.Errorf("background mask failed: %w", err)
+	}
+	backgroundMarker := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(float64(count+1), 0, 0, 0), markers.Rows(), markers.Cols(), gocv.MatTypeCV32S)
+	defer backgroundMarker.Close()
+	if err := backgroundMarker.CopyToWithMask(&markers, background); err != nil {
+		return nil, fmt.Errorf("background marker failed: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Watershed requires a colour image; flooding the distance map places
+	// the lines midway between the object centres
+	relief := gocv.NewMat()
+	defer relief.Close()
+	if err := gocv.Normalize(distance, &relief, 0, 255, gocv.NormMinMax); err != nil {
+		return nil, fmt.Errorf("distance normalisation failed: %w", err)
+	}
+	if err := relief.ConvertTo(&relief, gocv.MatTypeCV8U); err != nil {
+		return nil, fmt.Errorf("distance conversion failed: %w", err)
+	}
+	if err := gocv.CvtColor(relief, &relief, gocv.ColorGrayToBGR); err != nil {
+		return nil, fmt.Errorf("colour conversion failed: %w", err)
+	}
+
+	if err := gocv.Watershed(relief, &markers); err != nil {
+		return nil, fmt.Errorf("watershed failed: %w", err)
+	}
+
+	// Keep the object labels, clearing the background marker and the -1
+	// watershed lines
+	objects := gocv.NewMat()
+	defer objects.Close()
+	if err := gocv.InRangeWithScalar(markers, gocv.NewScalar(1, 0, 0, 0), gocv.NewScalar(float64(count), 0, 0, 0), &objects); err != nil {
+		return nil, fmt.Errorf("object mask failed: %w", err)
+	}
+	labels := gocv.NewMatWithSize(markers.Rows(), markers.Cols(), gocv.MatTypeCV32S)
+	defer labels.Close()
+	labels.SetTo(gocv.NewScalar(0, 0, 0, 0))
+	if err := markers.CopyToWithMask(&labels, objects); err != nil {
+		return nil, fmt.Errorf("label copy failed: %w", err)
+	}
+
+	overlay, err := wp.colourLabels(ctx, markers, count)
+	if err != nil {
+		return nil, err
+	}
+	defer overlay.Close()
+
+	result := &Result{Count: count}
+	if result.Labels, err = safe.NewMatFromMat(labels); err != nil {
+		return nil, err
+	}
+	if result.Overlay, err = safe.NewMatFromMat(overlay); err != nil {
+		result.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+// findSeeds returns an 8-bit mask of the foreground pixels whose distance is
+// the largest within MinDistance. Plateaus give connected groups of pixels,
+// which label as a single seed.
+func (wp *WatershedPostProcessor) findSeeds(distance, foreground gocv.Mat) (gocv.Mat, error) {
+	size := 2*wp.MinDistance + 1
+	kernel := gocv.GetStructuringElement(gocv.MorphEllipse, image.Pt(size, size))
+	defer kernel.Close()
+
+	dilated := gocv.NewMat()
+	defer dilated.Close()
+	if err := gocv.Dilate(distance, &dilated, kernel); err != nil {
+		return gocv.NewMat(), fmt.Errorf("dilation failed: %w", err)
+	}
+
+	maxima := gocv.NewMat()
+	defer maxima.Close()
+	if err := gocv.Compare(distance, dilated, &maxima, gocv.CompareEQ); err != nil {
+		return gocv.NewMat(), fmt.Errorf("maxima comparison failed: %w", err)
+	}
+
+	seeds := gocv.NewMat()
+	if err := gocv.BitwiseAnd(maxima, foreground, &seeds); err != nil {
+		seeds.Close()
+		return gocv.NewMat(), fmt.Errorf("seed mask failed: %w", err)
+	}
+	return seeds, nil
+}
+
+// colourLabels renders markers with each label from 1 to count in its own
+// colour, background black and the watershed lines white
+func (wp *WatershedPostProcessor) colourLabels(ctx context.Context, markers gocv.Mat, count int) (gocv.Mat, error) {
+	rows := markers.Rows()
+	cols := markers.Cols()
+	overlay := gocv.NewMatWithSize(rows, cols, gocv.MatTypeCV8UC3)
+	overlay.SetTo(gocv.NewScalar(0, 0, 0, 0))
+
+	palette := labelPalette(count)
+
+	rowsPerCheck := max(1, rows/100)
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				overlay.Close()
+				return gocv.NewMat(), err
+			}
+		}
+		for x := 0; x < cols; x++ {
+			label := int(markers.GetIntAt(y, x))
+			var colour [3]uint8
+			switch {
+			case label == -1:
+				colour = [3]uint8{255, 255, 255}
+			case label >= 1 && label <= count:
+				colour = palette[label-1]
+			default:
+				continue
+			}
+			for c := 0; c < 3; c++ {
+				overlay.SetUCharAt3(y, x, c, colour[c])
+			}
+		}
+	}
+
+	return overlay, nil
+}
+
+// labelPalette returns count BGR colours with hues stepped by the golden
+// angle, so neighbouring labels get clearly different colours
+func labelPalette(count int) [][3]uint8 {
+	const goldenAngle = 137.50776405003785
+
+	palette := make([][3]uint8, count)
+	for i := range palette {
+		hue := math.Mod(float64(i)*goldenAngle, 360)
+		r, g, b := hsvToRGB(hue, 0.65, 0.95)
+		palette[i] = [3]uint8{b, g, r}
+	}
+	return palette
+}
+
+// hsvToRGB converts a hue in degrees and saturation and value in [0, 1]
+func hsvToRGB(hue, saturation, value float64) (uint8, uint8, uint8) {
+	chroma := value * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := value - chroma
+
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+
+	channel := func(v float64) uint8 {
+		return uint8(math.Round((v + m) * 255))
+	}
+	return channel(r), channel(g), channel(b)
+}
@@ -0,0 +1,343 @@
+// Package benchmark compares every registered algorithm on a fixed set of
+// synthetic reference images with known ground truth.
+package benchmark
+
+//go:generate go run gen_testdata.go
+
+import (
+	"context"
+	"embed"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/pipeline"
+	"otsu-obliterator/internal/processing/threshold"
+)
+
+//go:embed testdata/*.png
+var testdata embed.FS
+
+// maskSuffix marks the ground truth image belonging to a reference image
+const maskSuffix = "_mask"
+
+// heapSampleInterval is how often the Go heap is sampled for its peak
+const heapSampleInterval = time.Millisecond
+
+// Case is a reference image with its ground truth foreground mask
+type Case struct {
+	Name        string
+	Image       image.Image
+	GroundTruth image.Image
+}
+
+// Row is the result of one algorithm on one reference image
+type Row struct {
+	Algorithm  string
+	Image      string
+	MeanMs     float64
+	IoU        float64
+	PeakHeapMB float64
+}
+
+// Run parses args, benchmarks the algorithms and writes a Markdown table to
+// stdout. It returns the process exit code.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	runs := fs.Int("runs", 5, "timed runs per algorithm and image")
+	only := fs.String("algorithms", "", "comma separated algorithm names, all when empty")
+	pyramidLevels := fs.Int("pyramid", 0, "also time warm-startable algorithms coarse to fine over this many pyramid levels")
+	scale := fs.Int("scale", 1, "enlarge the reference images by this factor, 16 gives 4096x4096")
+	ccl := fs.Bool("ccl", false, "time connected component labelling on 2048x2048 masks instead of the algorithms")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *runs < 1 {
+		fmt.Fprintln(stderr, "--runs must be at least 1")
+		return 2
+	}
+	if *scale < 1 || *pyramidLevels < 0 {
+		fmt.Fprintln(stderr, "--scale must be at least 1 and --pyramid at least 0")
+		return 2
+	}
+
+	cases, err := LoadCases()
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if *ccl {
+		rows, err := RunCCL(ctx, cases, *runs)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+		if err := WriteCCLMarkdown(stdout, rows); err != nil {
+			fmt.Fprintf(stderr, "error: failed to write table: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	for i := range cases {
+		cases[i] = scaleCase(cases[i], *scale)
+	}
+
+	manager := algorithms.NewManager()
+	names := manager.GetAvailableAlgorithms()
+	if *only != "" {
+		names = nil
+		for _, name := range strings.Split(*only, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	sort.Strings(names)
+
+	var rows []Row
+	for _, name := range names {
+		algorithm, err := manager.GetAlgorithm(name)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 1
+		}
+
+		variants := []algorithms.Algorithm{algorithm}
+		// Algorithms that accept a pyramid level count warm start from it
+		if _, ok := manager.GetParameters(name)[threshold.PyramidLevelsParam]; ok && *pyramidLevels > 0 {
+			variants = append(variants, pipeline.NewPyramidProcessor(algorithm, *pyramidLevels))
+		}
+
+		for _, c := range cases {
+			for _, variant := range variants {
+				row, err := runCase(ctx, variant, manager.GetParameters(name), c, *runs)
+				if err != nil {
+					fmt.Fprintf(stderr, "error: %s on %s: %v\n", variant.GetName(), c.Name, err)
+					return 1
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	if err := WriteMarkdown(stdout, rows); err != nil {
+		fmt.Fprintf(stderr, "error: failed to write table: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// LoadCases decodes the embedded reference images, sorted by name
+func LoadCases() ([]Case, error) {
+	entries, err := testdata.ReadDir("testdata")
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".png")
+		if strings.HasSuffix(name, maskSuffix) {
+			continue
+		}
+
+		img, err := decodePNG(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		mask, err := decodePNG(name + maskSuffix + ".png")
+		if err != nil {
+			return nil, fmt.Errorf("ground truth for %s: %w", name, err)
+		}
+		if img.Bounds() != mask.Bounds() {
+			return nil, fmt.Errorf("ground truth for %s has a different size", name)
+		}
+
+		cases = append(cases, Case{Name: name, Image: img, GroundTruth: mask})
+	}
+
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// scaleCase enlarges the image and mask of c by factor with nearest
+// neighbour sampling, so large image timings keep the same ground truth
+func scaleCase(c Case, factor int) Case {
+	if factor == 1 {
+		return c
+	}
+	c.Image = scaleImage(c.Image, factor)
+	c.GroundTruth = scaleImage(c.GroundTruth, factor)
+	return c
+}
+
+func scaleImage(img image.Image, factor int) image.Image {
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*factor, bounds.Dy()*factor))
+	for y := 0; y < scaled.Rect.Dy(); y++ {
+		for x := 0; x < scaled.Rect.Dx(); x++ {
+			scaled.Set(x, y, img.At(bounds.Min.X+x/factor, bounds.Min.Y+y/factor))
+		}
+	}
+	return scaled
+}
+
+func decodePNG(name string) (image.Image, error) {
+	file, err := testdata.Open(path.Join("testdata", name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	return img, nil
+}
+
+// runCase times runs of algorithm on c and scores the last result
+func runCase(ctx context.Context, algorithm algorithms.Algorithm, params map[string]interface{}, c Case, runs int) (Row, error) {
+	input, err := conversion.ImageToMat(c.Image)
+	if err != nil {
+		return Row{}, err
+	}
+	defer input.Close()
+
+	sampler := startHeapSampler()
+	var total time.Duration
+	var result *safe.Mat
+	for i := 0; i < runs; i++ {
+		if result != nil {
+			result.Close()
+		}
+
+		start := time.Now()
+		if contextual, ok := algorithm.(algorithms.ContextualAlgorithm); ok {
+			result, err = contextual.ProcessWithContext(ctx, input, params)
+		} else {
+			result, err = algorithm.Process(input, params)
+		}
+		total += time.Since(start)
+		if err != nil {
+			sampler.stop()
+			return Row{}, fmt.Errorf("run %d failed: %w", i+1, err)
+		}
+	}
+	peak := sampler.stop()
+	defer result.Close()
+
+	segmented, err := conversion.MatToImage(result)
+	if err != nil {
+		return Row{}, err
+	}
+
+	return Row{
+		Algorithm:  algorithm.GetName(),
+		Image:      c.Name,
+		MeanMs:     float64(total.Microseconds()) / 1000 / float64(runs),
+		IoU:        iou(segmented, c.GroundTruth),
+		PeakHeapMB: float64(peak) / (1024 * 1024),
+	}, nil
+}
+
+// iou compares foreground pixels, those brighter than mid-grey
+func iou(segmented, groundTruth image.Image) float64 {
+	bounds := groundTruth.Bounds()
+	var intersection, union int
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			a := isForeground(segmented, x, y)
+			b := isForeground(groundTruth, x, y)
+			if a && b {
+				intersection++
+			}
+			if a || b {
+				union++
+			}
+		}
+	}
+	if union == 0 {
+		return 1
+	}
+	return float64(intersection) / float64(union)
+}
+
+func isForeground(img image.Image, x, y int) bool {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return (r+g+b)/3 > 0x7fff
+}
+
+// heapSampler records the peak Go heap above its starting size. OpenCV
+// buffers live outside the Go heap and are not included.
+type heapSampler struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+	peak atomic.Uint64
+}
+
+func startHeapSampler() *heapSampler {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	baseline := stats.HeapAlloc
+
+	s := &heapSampler{done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(heapSampleInterval)
+		defer ticker.Stop()
+
+		for {
+			var stats runtime.MemStats
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc > baseline && stats.HeapAlloc-baseline > s.peak.Load() {
+				s.peak.Store(stats.HeapAlloc - baseline)
+			}
+
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// stop ends sampling and returns the peak in bytes
+func (s *heapSampler) stop() uint64 {
+	close(s.done)
+	s.wg.Wait()
+	return s.peak.Load()
+}
+
+// WriteMarkdown prints rows as a Markdown table. Rows keep their order and
+// numbers use fixed precision so the layout only changes with the results.
+func WriteMarkdown(w io.Writer, rows []Row) error {
+	var b strings.Builder
+	b.WriteString("| Algorithm | Image | Mean time (ms) | IoU | Peak Go heap (MB) |\n")
+	b.WriteString("|---|---|---:|---:|---:|\n")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %s | %.2f | %.4f | %.2f |\n",
+			row.Algorithm, row.Image, row.MeanMs, row.IoU, row.PeakHeapMB)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,102 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+	"time"
+
+	"otsu-obliterator/internal/processing/labelling"
+
+	"gocv.io/x/gocv"
+)
+
+// cclSize is the side of the masks connected component labelling is timed on
+const cclSize = 2048
+
+// CCLRow compares the two connected component labellers on one mask
+type CCLRow struct {
+	Image      string
+	OpenCVMs   float64
+	ParallelMs float64
+	Components int
+}
+
+// RunCCL times gocv.ConnectedComponents against labelling.ParallelCCL on the
+// ground truth masks enlarged to cclSize x cclSize, checking both find the
+// same number of components
+func RunCCL(ctx context.Context, cases []Case, runs int) ([]CCLRow, error) {
+	var rows []CCLRow
+	for _, c := range cases {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		bounds := c.GroundTruth.Bounds()
+		factor := max(1, cclSize/max(bounds.Dx(), bounds.Dy()))
+		pixels, width, height := foregroundBytes(scaleImage(c.GroundTruth, factor))
+
+		mask, err := gocv.NewMatFromBytes(height, width, gocv.MatTypeCV8UC1, pixels)
+		if err != nil {
+			return nil, err
+		}
+		labels := gocv.NewMat()
+
+		var openCVTotal, parallelTotal time.Duration
+		var openCVCount, parallelCount int
+		for i := 0; i < runs; i++ {
+			start := time.Now()
+			openCVCount = gocv.ConnectedComponents(mask, &labels)
+			openCVTotal += time.Since(start)
+
+			start = time.Now()
+			_, parallelCount = labelling.ParallelCCL(pixels, width, height)
+			parallelTotal += time.Since(start)
+		}
+		labels.Close()
+		mask.Close()
+
+		if openCVCount != parallelCount {
+			return nil, fmt.Errorf("%s: OpenCV found %d labels but ParallelCCL %d", c.Name, openCVCount, parallelCount)
+		}
+
+		rows = append(rows, CCLRow{
+			Image:      fmt.Sprintf("%s (%dx%d)", c.Name, width, height),
+			OpenCVMs:   float64(openCVTotal.Microseconds()) / 1000 / float64(runs),
+			ParallelMs: float64(parallelTotal.Microseconds()) / 1000 / float64(runs),
+			Components: openCVCount - 1,
+		})
+	}
+	return rows, nil
+}
+
+// foregroundBytes returns img as one byte per pixel, 255 where isForeground
+func foregroundBytes(img image.Image) ([]byte, int, int) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	pixels := make([]byte, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if isForeground(img, bounds.Min.X+x, bounds.Min.Y+y) {
+				pixels[y*width+x] = 255
+			}
+		}
+	}
+	return pixels, width, height
+}
+
+// WriteCCLMarkdown prints rows as a Markdown table
+func WriteCCLMarkdown(w io.Writer, rows []CCLRow) error {
+	var b strings.Builder
+	b.WriteString("| Mask | gocv.ConnectedComponents (ms) | ParallelCCL (ms) | Components |\n")
+	b.WriteString("|---|---:|---:|---:|\n")
+	for _, row := range rows {
+		fmt.Fprintf(&b, "| %s | %.2f | %.2f | %d |\n",
+			row.Image, row.OpenCVMs, row.ParallelMs, row.Components)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,161 @@
+//go:build ignore
+
+// gen_testdata writes the synthetic reference images and their ground truth
+// masks. Output is deterministic, so regenerating leaves the files unchanged.
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+)
+
+const size = 256
+
+func main() {
+	gradient, gradientMask := blurredGradient()
+	noisy, noisyMask := saltAndPepper()
+
+	for name, img := range map[string]image.Image{
+		"gradient.png":         gradient,
+		"gradient_mask.png":    gradientMask,
+		"salt_pepper.png":      noisy,
+		"salt_pepper_mask.png": noisyMask,
+	} {
+		if err := writePNG(filepath.Join("testdata", name), img); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// blurredGradient places bright shapes on a left-to-right illumination
+// gradient and blurs the result, so a single global threshold clips the
+// darker shapes
+func blurredGradient() (*image.Gray, *image.Gray) {
+	mask := image.NewGray(image.Rect(0, 0, size, size))
+	values := make([]float64, size*size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			background := 40 + 100*float64(x)/float64(size-1)
+			if inShape(x, y) {
+				mask.SetGray(x, y, color.Gray{Y: 255})
+				background += 70
+			}
+			values[y*size+x] = background
+		}
+	}
+
+	values = gaussianBlur(values, 2.0)
+	img := image.NewGray(mask.Bounds())
+	for i, v := range values {
+		img.Pix[i] = uint8(math.Round(math.Max(0, math.Min(v, 255))))
+	}
+	return img, mask
+}
+
+// saltAndPepper draws bright bars on a dark background and flips 5% of the
+// pixels to pure black or white
+func saltAndPepper() (*image.Gray, *image.Gray) {
+	rng := rand.New(rand.NewSource(1))
+	mask := image.NewGray(image.Rect(0, 0, size, size))
+	img := image.NewGray(mask.Bounds())
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			value := uint8(60)
+			if (y/32)%2 == 1 && x >= 24 && x < size-24 {
+				value = 190
+				mask.SetGray(x, y, color.Gray{Y: 255})
+			}
+			switch r := rng.Float64(); {
+			case r < 0.025:
+				value = 0
+			case r < 0.05:
+				value = 255
+			}
+			img.SetGray(x, y, color.Gray{Y: value})
+		}
+	}
+	return img, mask
+}
+
+// inShape reports whether x, y lies in one of the foreground shapes: a disk
+// on the dark side, a square in the middle and a ring on the bright side
+func inShape(x, y int) bool {
+	dist := func(cx, cy int) float64 {
+		return math.Hypot(float64(x-cx), float64(y-cy))
+	}
+	switch {
+	case dist(48, 80) < 28:
+		return true
+	case x >= 104 && x < 152 && y >= 150 && y < 198:
+		return true
+	case dist(200, 96) < 34 && dist(200, 96) >= 18:
+		return true
+	}
+	return false
+}
+
+// gaussianBlur applies a separable Gaussian with mirrored borders
+func gaussianBlur(values []float64, sigma float64) []float64 {
+	radius := int(math.Ceil(3 * sigma))
+	kernel := make([]float64, 2*radius+1)
+	var sum float64
+	for i := range kernel {
+		d := float64(i - radius)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	mirror := func(i int) int {
+		if i < 0 {
+			return -i
+		}
+		if i >= size {
+			return 2*size - 2 - i
+		}
+		return i
+	}
+
+	horizontal := make([]float64, len(values))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var v float64
+			for k, w := range kernel {
+				v += w * values[y*size+mirror(x+k-radius)]
+			}
+			horizontal[y*size+x] = v
+		}
+	}
+
+	result := make([]float64, len(values))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			var v float64
+			for k, w := range kernel {
+				v += w * horizontal[mirror(y+k-radius)*size+x]
+			}
+			result[y*size+x] = v
+		}
+	}
+	return result
+}
+
+func writePNG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
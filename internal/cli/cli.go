@@ -0,0 +1,293 @@
+// Package cli runs the processing pipeline without initialising the GUI.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/monitoring"
+	"otsu-obliterator/internal/opencv/memory"
+	"otsu-obliterator/internal/pipeline"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// HeadlessFlag selects CLI mode in the GUI binary
+const HeadlessFlag = "--headless"
+
+// Result is the JSON document printed to stdout
+type Result struct {
+	Input            string                        `json:"input"`
+	Output           string                        `json:"output"`
+	Algorithm        string                        `json:"algorithm"`
+	Parameters       map[string]interface{}        `json:"parameters"`
+	ProcessingTimeMs float64                       `json:"processing_time_ms"`
+	Metrics          *pipeline.SegmentationMetrics `json:"metrics"`
+	Benchmark        *BenchmarkResult              `json:"benchmark,omitempty"`
+}
+
+// BenchmarkResult summarises repeated runs of the same algorithm
+type BenchmarkResult struct {
+	Runs     int     `json:"runs"`
+	MeanMs   float64 `json:"mean_ms"`
+	StddevMs float64 `json:"stddev_ms"`
+}
+
+// paramFlags collects repeated --params key=value flags
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *paramFlags) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// IsHeadless reports whether args request headless mode
+func IsHeadless(args []string) bool {
+	for _, arg := range args {
+		if arg == HeadlessFlag || arg == "-headless" {
+			return true
+		}
+	}
+	return false
+}
+
+// Run parses args, processes one image and writes a JSON result to stdout.
+// It returns the process exit code.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("otsu-cli", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var params paramFlags
+	input := fs.String("input", "", "input image path")
+	output := fs.String("output", "", "output image path")
+	algorithm := fs.String("algorithm", "2D Otsu", "thresholding algorithm name")
+	benchmark := fs.Int("benchmark", 0, "run the algorithm N times and report timing")
+	fs.Bool("headless", true, "run without GUI (always set for this command)")
+	fs.Var(&params, "params", "algorithm parameter as key=value (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *input == "" || *output == "" {
+		fmt.Fprintln(stderr, "both --input and --output are required")
+		fs.Usage()
+		return 2
+	}
+
+	result, err := process(ctx, *input, *output, *algorithm, params, *benchmark, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(stderr, "error: failed to encode result: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// process loads, segments and saves a single image
+func process(ctx context.Context, input, output, algorithm string, rawParams []string, benchmarkRuns int, stderr io.Writer) (*Result, error) {
+	log := logger.NewFileLogger(logger.WarnLevel, stderr)
+	memManager := memory.NewManager(log)
+	defer memManager.Shutdown()
+
+	coordinator := pipeline.NewCoordinator(memManager, log)
+	defer coordinator.Shutdown()
+
+	// Operators can follow the runs over HTTP when MONITOR_PORT is set
+	stateRepo := models.NewProcessingStateRepository()
+	monitor, err := monitoring.StartFromEnv(stateRepo, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start monitoring server: %w", err)
+	}
+	if monitor != nil {
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			monitor.Shutdown(shutdownCtx)
+		}()
+	}
+
+	parameters, err := buildParameters(algorithm, rawParams)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := openFile(input)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := coordinator.LoadImage(reader)
+	reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", input, err)
+	}
+
+	start := time.Now()
+	processed, err := processTracked(ctx, coordinator, stateRepo, algorithm, parameters)
+	if err != nil {
+		return nil, fmt.Errorf("processing failed: %w", err)
+	}
+	processingTime := time.Since(start)
+
+	metrics, err := coordinator.CalculateSegmentationMetrics(original, processed)
+	if err != nil {
+		return nil, fmt.Errorf("metrics calculation failed: %w", err)
+	}
+
+	var benchmarkResult *BenchmarkResult
+	if benchmarkRuns > 0 {
+		benchmarkResult, err = runBenchmark(ctx, coordinator, stateRepo, algorithm, parameters, benchmarkRuns)
+		if err != nil {
+			return nil, err
+		}
+		// Benchmark runs replace the processed image, so save the latest one
+		processed = coordinator.GetProcessedImage()
+	}
+
+	if err := saveFile(coordinator, output, processed); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Input:            input,
+		Output:           output,
+		Algorithm:        algorithm,
+		Parameters:       parameters,
+		ProcessingTimeMs: float64(processingTime.Microseconds()) / 1000,
+		Metrics:          metrics,
+		Benchmark:        benchmarkResult,
+	}, nil
+}
+
+// processTracked runs the algorithm and records the run in stateRepo for the
+// monitoring server
+func processTracked(ctx context.Context, coordinator *pipeline.Coordinator, stateRepo *models.ProcessingStateRepository, algorithm string, parameters map[string]interface{}) (*pipeline.ImageData, error) {
+	stateRepo.StartProcessing(algorithm)
+	processed, err := coordinator.ProcessImageWithContext(ctx, algorithm, parameters)
+	switch {
+	case err == nil:
+		stateRepo.CompleteProcessing()
+	case ctx.Err() != nil:
+		stateRepo.CancelProcessing()
+	default:
+		stateRepo.FailProcessing()
+	}
+	return processed, err
+}
+
+// runBenchmark times repeated runs and reports mean and standard deviation
+func runBenchmark(ctx context.Context, coordinator *pipeline.Coordinator, stateRepo *models.ProcessingStateRepository, algorithm string, parameters map[string]interface{}, runs int) (*BenchmarkResult, error) {
+	durations := make([]float64, 0, runs)
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		if _, err := processTracked(ctx, coordinator, stateRepo, algorithm, parameters); err != nil {
+			return nil, fmt.Errorf("benchmark run %d failed: %w", i+1, err)
+		}
+		durations = append(durations, float64(time.Since(start).Microseconds())/1000)
+	}
+
+	var sum float64
+	for _, d := range durations {
+		sum += d
+	}
+	mean := sum / float64(runs)
+
+	var variance float64
+	for _, d := range durations {
+		variance += (d - mean) * (d - mean)
+	}
+	variance /= float64(runs)
+
+	return &BenchmarkResult{
+		Runs:     runs,
+		MeanMs:   mean,
+		StddevMs: math.Sqrt(variance),
+	}, nil
+}
+
+// buildParameters starts from algorithm defaults and applies key=value overrides,
+// converting each value to the type of its default
+func buildParameters(algorithm string, rawParams []string) (map[string]interface{}, error) {
+	overrides := make(map[string]string, len(rawParams))
+	for _, raw := range rawParams {
+		key, value, found := strings.Cut(raw, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", raw)
+		}
+		overrides[key] = value
+	}
+
+	return algorithms.NewManager().ParseParameters(algorithm, overrides)
+}
+
+// fileReader adapts an os.File to fyne.URIReadCloser so the pipeline can load
+// from a path without a running Fyne app
+type fileReader struct {
+	*os.File
+	uri fyne.URI
+}
+
+func (f *fileReader) URI() fyne.URI {
+	return f.uri
+}
+
+// fileWriter adapts an os.File to fyne.URIWriteCloser
+type fileWriter struct {
+	*os.File
+	uri fyne.URI
+}
+
+func (f *fileWriter) URI() fyne.URI {
+	return f.uri
+}
+
+func openFile(path string) (fyne.URIReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	return &fileReader{File: file, uri: storage.NewFileURI(path)}, nil
+}
+
+func saveFile(coordinator *pipeline.Coordinator, path string, imageData *pipeline.ImageData) error {
+	if imageData == nil {
+		return errors.New("no processed image to save")
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+
+	writer := &fileWriter{File: file, uri: storage.NewFileURI(path)}
+	if err := coordinator.SaveImage(writer, imageData); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to save %s: %w", path, err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"otsu-obliterator/internal/services"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// SetCompareMode switches Process between the selected algorithm and both
+// compare mode algorithms side by side. Leaving compare mode releases the
+// last comparison.
+func (mc *MainController) SetCompareMode(enabled bool) {
+	mc.mu.Lock()
+	mc.compareMode = enabled
+	var released *services.DualResult
+	if !enabled {
+		released, mc.compareResult = mc.compareResult, nil
+	}
+	mc.mu.Unlock()
+
+	mc.processingService.ReleaseDualResult(released)
+	if !enabled && mc.mainView != nil {
+		mc.mainView.SetComparisonResults(nil, nil)
+	}
+}
+
+// compareModeEnabled reports whether Process runs the compare mode algorithms
+func (mc *MainController) compareModeEnabled() bool {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+	return mc.compareMode
+}
+
+// performCompareProcessing runs both compare mode algorithms concurrently on
+// the output of any pre-process hooks and shows the results side by side
+func (mc *MainController) performCompareProcessing() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	ctx, span := tracer.Start(ctx, "MainController.performCompareProcessing")
+	defer span.End()
+
+	mc.mu.Lock()
+	mc.processingCancelFunc = cancel
+	mc.mu.Unlock()
+
+	go mc.monitorProcessingProgress()
+
+	start := time.Now()
+	var dual *services.DualResult
+	err := fmt.Errorf("no image loaded")
+	if original := mc.imageRepo.GetOriginalImage(); original != nil {
+		input := mc.runPreProcessHooks(original)
+		dual, err = mc.processingService.ProcessImageDual(ctx, input)
+		if input != original {
+			input.Mat.Close()
+		}
+	}
+	elapsed := time.Since(start)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	// The new comparison replaces the previous one unless compare mode was
+	// left while it ran
+	var released *services.DualResult
+	mc.mu.Lock()
+	mc.processingCancelFunc = nil
+	if err == nil {
+		if mc.compareMode {
+			released, mc.compareResult = mc.compareResult, dual
+		} else {
+			released = dual
+		}
+	}
+	shown := mc.compareResult == dual
+	mc.mu.Unlock()
+	mc.processingService.ReleaseDualResult(released)
+
+	if err != nil && ctx.Err() == nil {
+		mc.handleError("Comparison failed", err)
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.SetProcessingActive(false)
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			mc.mainView.UpdateStatus("Processing cancelled")
+		case err != nil:
+			mc.mainView.UpdateStatus("Comparison failed")
+		case shown:
+			mc.mainView.SetComparisonResults(dual.Left, dual.Right)
+			mc.mainView.UpdateStatus(fmt.Sprintf("Comparison completed in %s", elapsed.Round(time.Millisecond)))
+		}
+	}
+
+	if err == nil && mc.logger != nil {
+		mc.logger.Info("Comparison completed", map[string]interface{}{
+			"left":            services.CompareLeftAlgorithm,
+			"right":           services.CompareRightAlgorithm,
+			"processing_time": elapsed,
+		})
+	}
+}
+
+// releaseComparison frees the last comparison's results
+func (mc *MainController) releaseComparison() {
+	mc.mu.Lock()
+	released := mc.compareResult
+	mc.compareResult = nil
+	mc.mu.Unlock()
+
+	mc.processingService.ReleaseDualResult(released)
+}
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+)
+
+// Hook event types emitted around each processing run
+const (
+	PreProcessHook  = "pre_process_hook"
+	PostProcessHook = "post_process_hook"
+)
+
+// PreProcessHookFunc may modify or replace the image before it is processed
+type PreProcessHookFunc func(*models.ImageData) (*models.ImageData, error)
+
+// PostProcessHookFunc receives the processed image and its metrics
+type PostProcessHookFunc func(*models.ImageData, *models.SegmentationMetrics) error
+
+// postProcessHookData is the PostProcessHook event payload. The image is a
+// copy owned by the event, closed once every hook has seen it.
+type postProcessHookData struct {
+	image   *models.ImageData
+	metrics *models.SegmentationMetrics
+}
+
+// RegisterPreProcessHook adds a hook run before every processing run. Hooks
+// run in registration order, each receiving the output of the previous one.
+func (mc *MainController) RegisterPreProcessHook(fn PreProcessHookFunc) {
+	mc.eventMu.Lock()
+	defer mc.eventMu.Unlock()
+	mc.preProcessHooks = append(mc.preProcessHooks, fn)
+}
+
+// RegisterPostProcessHook adds a hook run in the background once a processing
+// run and its metrics are complete. Each hook receives its own copy of the
+// result, so it may keep or modify it without affecting the application.
+func (mc *MainController) RegisterPostProcessHook(fn PostProcessHookFunc) {
+	mc.eventMu.Lock()
+	defer mc.eventMu.Unlock()
+	mc.postProcessHooks = append(mc.postProcessHooks, fn)
+}
+
+// onPostProcessHook runs the post-process hooks on a PostProcessHook event
+// and closes the event's copy of the result
+func (mc *MainController) onPostProcessHook(data interface{}) error {
+	payload, ok := data.(postProcessHookData)
+	if !ok {
+		return fmt.Errorf("invalid data type for %s event", PostProcessHook)
+	}
+	defer payload.image.Mat.Close()
+
+	mc.eventMu.RLock()
+	hooks := append([]PostProcessHookFunc(nil), mc.postProcessHooks...)
+	mc.eventMu.RUnlock()
+
+	for _, hook := range hooks {
+		input, err := copyImageData(payload.image)
+		if err != nil {
+			mc.logHookError(PostProcessHook, err)
+			return nil
+		}
+
+		var metrics *models.SegmentationMetrics
+		if payload.metrics != nil {
+			copied := *payload.metrics
+			metrics = &copied
+		}
+
+		if err := hook(input, metrics); err != nil {
+			mc.logHookError(PostProcessHook, err)
+		}
+		input.Mat.Close()
+	}
+	return nil
+}
+
+// runPreProcessHooks passes a copy of original through the pre-process hooks.
+// A failing hook is skipped and the next one receives the data from before it.
+// The returned image is original itself when no hook changed it; otherwise
+// the caller owns it and must close its Mat.
+func (mc *MainController) runPreProcessHooks(original *models.ImageData) *models.ImageData {
+	mc.eventMu.RLock()
+	hooks := append([]PreProcessHookFunc(nil), mc.preProcessHooks...)
+	mc.eventMu.RUnlock()
+
+	current := original
+	for _, hook := range hooks {
+		input, err := copyImageData(current)
+		if err != nil {
+			mc.logHookError(PreProcessHook, err)
+			break
+		}
+
+		output, err := hook(input)
+		if err == nil && (output == nil || output.Mat == nil) {
+			err = fmt.Errorf("hook returned no image")
+		}
+		if err == nil {
+			err = refreshImageData(output)
+			if err != nil && output.Mat != input.Mat {
+				output.Mat.Close()
+			}
+		}
+		if err != nil {
+			mc.logHookError(PreProcessHook, err)
+			input.Mat.Close()
+			continue
+		}
+
+		if output.Mat != input.Mat {
+			input.Mat.Close()
+		}
+		if current != original {
+			current.Mat.Close()
+		}
+		current = output
+	}
+
+	return current
+}
+
+// emitPostProcessHooks hands a copy of a completed result to the
+// post-process hooks. The result itself may be released from the history
+// before the hooks run.
+func (mc *MainController) emitPostProcessHooks(result *models.ProcessingResult) {
+	mc.eventMu.RLock()
+	registered := len(mc.postProcessHooks) > 0
+	mc.eventMu.RUnlock()
+	if !registered {
+		return
+	}
+
+	snapshot, err := copyImageData(result.ProcessedImage)
+	if err != nil {
+		mc.logHookError(PostProcessHook, err)
+		return
+	}
+
+	var metrics *models.SegmentationMetrics
+	if result.Metrics != nil {
+		copied := *result.Metrics
+		metrics = &copied
+	}
+
+	if err := mc.emitEvent(PostProcessHook, postProcessHookData{image: snapshot, metrics: metrics}); err != nil {
+		snapshot.Mat.Close()
+	}
+}
+
+// logHookError records a hook failure; hooks never interrupt processing
+func (mc *MainController) logHookError(eventType string, err error) {
+	if mc.logger == nil {
+		return
+	}
+	mc.logger.Error("Processing hook failed", err, map[string]interface{}{
+		"event": eventType,
+	})
+}
+
+// refreshImageData rebuilds the display image and dimensions of img from its
+// Mat, which a hook may have modified
+func refreshImageData(img *models.ImageData) error {
+	rendered, err := conversion.MatToImage(img.Mat)
+	if err != nil {
+		return fmt.Errorf("failed to convert hook output: %w", err)
+	}
+
+	bounds := rendered.Bounds()
+	img.Image = rendered
+	img.Width = bounds.Dx()
+	img.Height = bounds.Dy()
+	img.Channels = img.Mat.Channels()
+	return nil
+}
+
+// copyImageData returns a copy of img with its own Mat
+func copyImageData(img *models.ImageData) (*models.ImageData, error) {
+	mat, err := img.Mat.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy image: %w", err)
+	}
+
+	copied := *img
+	copied.Mat = mat
+	return &copied, nil
+}
@@ -2,23 +2,56 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"image"
+	"io"
+	"maps"
+	"os"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"otsu-obliterator/internal/algorithms/convergence"
+	"otsu-obliterator/internal/algorithms/otsu3d"
+	"otsu-obliterator/internal/events"
+	"otsu-obliterator/internal/export"
+	"otsu-obliterator/internal/logger"
 	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/monitoring"
+	"otsu-obliterator/internal/opencv/analysis"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+	"otsu-obliterator/internal/processing/focus"
+	"otsu-obliterator/internal/processing/modality"
+	"otsu-obliterator/internal/processing/noise"
+	"otsu-obliterator/internal/processing/privacy"
+	"otsu-obliterator/internal/processing/threshold"
 	"otsu-obliterator/internal/services"
 	"otsu-obliterator/internal/views"
 
 	"fyne.io/fyne/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer records processing runs when tracing is configured
+var tracer = otel.Tracer("otsu-obliterator/internal/controllers")
+
 // MainController orchestrates the application using MVC pattern
 type MainController struct {
 	// Services
 	imageService      *services.ImageService
 	processingService *services.ProcessingService
+	batchProcessor    *services.BatchProcessor
+	parameterTuner    *services.ParameterTuner
+	sensitivity       *services.SensitivityAnalyser
 
 	// Models/Repositories
 	imageRepo    *models.ImageRepository
@@ -32,15 +65,88 @@ type MainController struct {
 	mu                   sync.RWMutex
 	currentWindow        fyne.Window
 	processingCancelFunc context.CancelFunc
+	loadCancelFunc       context.CancelFunc
 	lastImageLoad        time.Time
+	windowTitle          string
+	
+	// Multi-page image state
+	pages       []*models.ImageData
+	currentPage int
+
+	// Volume stack slices and their 3D Otsu results, one image per slice
+	stack        []*models.ImageData
+	stackResults []image.Image
+	stackSlice   int
+	
+	// Ground truth comparison state
+	errorMapEnabled bool
+
+	// Compare mode runs two algorithms side by side; compareResult holds the
+	// comparison on display until the next one or leaving compare mode
+	compareMode   bool
+	compareResult *services.DualResult
+
+	// Metadata sidebar state. shownProcessed is the processed image on
+	// display; statsSeq drops statistics finished after the image changed.
+	statsVisible   bool
+	statsProcessed bool
+	shownProcessed *models.ImageData
+	statsSeq       atomic.Uint64
+	
+	// Live preview state
+	previewMu        sync.Mutex
+	previewDebouncer *time.Timer
+	previewCancel    context.CancelFunc
+	previewEnabled   bool
 	
+	// Processing queue; queueStarted and queueTotal count the runs of the
+	// current sequence for "Processing 1 of 3". runActive is the run gate,
+	// held by the active processing run, batch or analysis so only one of
+	// them uses processingCancelFunc at a time.
+	queue        chan ProcessingRequest
+	queueMu      sync.Mutex
+	runActive    bool
+	queueStarted int
+	queueTotal   int
+
 	// Event handlers
-	eventHandlers map[string][]EventHandler
-	eventMu       sync.RWMutex
+	eventBus         *events.EventBus
+	preProcessHooks  []PreProcessHookFunc
+	postProcessHooks []PostProcessHookFunc
+	eventMu          sync.RWMutex
+
+	// metricsExporter records the metrics of every completed run, nil when
+	// no metrics backend is configured
+	metricsExporter *monitoring.MetricsExporter
+
+	// autoSaver saves new results when the auto_save preference is on
+	autoSaver *services.AutoSaver
+
+	// presetManager stores named parameter sets, nil when the config
+	// directory is unavailable
+	presetManager *models.PresetManager
+
+	logger logger.Logger
+}
+
+// previewDebounceDelay is how long parameter edits must settle before a preview runs
+const previewDebounceDelay = 500 * time.Millisecond
+
+// previewParameterOverrides lowers quality for fast previews where the algorithm supports it
+var previewParameterOverrides = map[string]interface{}{
+	"histogram_bins": 16,
+	"window_size":    3,
 }
 
+// autoTuneTrials is how many parameter sets Auto-Tune evaluates
+const autoTuneTrials = 20
+
 // EventHandler represents a function that handles application events
-type EventHandler func(data interface{}) error
+type EventHandler = events.EventHandler
+
+// eventBufferSize is how many events of a topic may wait for a busy handler
+// before emitEvent drops them
+const eventBufferSize = 16
 
 // NewMainController creates a new main controller
 func NewMainController(
@@ -53,12 +159,22 @@ func NewMainController(
 	controller := &MainController{
 		imageService:      imageService,
 		processingService: processingService,
+		batchProcessor:    services.NewBatchProcessor(imageService, processingService, configRepo),
+		parameterTuner:    services.NewParameterTuner(processingService, configRepo),
+		sensitivity:       services.NewSensitivityAnalyser(processingService, configRepo),
 		imageRepo:         imageRepo,
 		configRepo:        configRepo,
 		stateRepo:         stateRepo,
-		eventHandlers:     make(map[string][]EventHandler),
+		queue:             make(chan ProcessingRequest, max(configRepo.GetPerformanceSettings().QueueCapacity, 0)),
+	}
+
+	if autoPreview, ok := configRepo.GetGlobalSetting("auto_preview"); ok {
+		controller.previewEnabled, _ = autoPreview.(bool)
 	}
 
+	controller.eventBus = events.NewEventBus(func(topic string, err error) {
+		controller.handleError(fmt.Sprintf("Event handler error (%s)", topic), err)
+	})
 	controller.initializeEventHandlers()
 	return controller
 }
@@ -67,6 +183,27 @@ func NewMainController(
 func (mc *MainController) SetMainView(view *views.MainView) {
 	mc.mainView = view
 	mc.setupViewEventHandlers()
+	mc.applyAppearance()
+}
+
+// SetLogger enables logging of hook failures
+func (mc *MainController) SetLogger(log logger.Logger) {
+	mc.logger = log
+}
+
+// SetMetricsExporter sends the metrics of completed runs to exporter
+func (mc *MainController) SetMetricsExporter(exporter *monitoring.MetricsExporter) {
+	mc.metricsExporter = exporter
+}
+
+// SetAutoSaver sets the auto-saver whose folder the user can choose
+func (mc *MainController) SetAutoSaver(saver *services.AutoSaver) {
+	mc.autoSaver = saver
+}
+
+// SetPresetManager sets where parameter presets are saved. Call before SetMainView.
+func (mc *MainController) SetPresetManager(presets *models.PresetManager) {
+	mc.presetManager = presets
 }
 
 // SetWindow sets the main application window
@@ -74,6 +211,7 @@ func (mc *MainController) SetWindow(window fyne.Window) {
 	mc.mu.Lock()
 	defer mc.mu.Unlock()
 	mc.currentWindow = window
+	mc.windowTitle = window.Title()
 }
 
 // LoadImage handles image loading requests
@@ -83,23 +221,23 @@ func (mc *MainController) LoadImage() {
 		return
 	}
 
-	// Use fyne.Do for thread-safe UI operations in Fyne v2.6+
-	fyne.Do(func() {
-		mc.showFileLoadDialog()
-	})
+	mc.showFileLoadDialog()
 }
 
 // SaveImage handles image saving requests
 func (mc *MainController) SaveImage() {
-	processedImg := mc.imageRepo.GetLatestProcessedImage()
+	// After undo or redo the result on display is the cursor entry, not the
+	// latest run. Without undo history the latest run is the one shown.
+	processedImg, ok := mc.imageRepo.GetUndoStack().Current()
+	if !ok {
+		processedImg = mc.imageRepo.GetLatestProcessedImage()
+	}
 	if processedImg == nil {
 		mc.handleError("Save failed", fmt.Errorf("no processed image available"))
 		return
 	}
 
-	fyne.Do(func() {
-		mc.showFileSaveDialog(processedImg)
-	})
+	mc.showFileSaveDialog(processedImg)
 }
 
 // ProcessImage initiates image processing with the current algorithm
@@ -111,28 +249,40 @@ func (mc *MainController) ProcessImage() {
 		return
 	}
 
-	// Check if already processing
-	if mc.processingService.IsProcessing() {
+	// Full quality result supersedes any pending preview
+	mc.cancelPreview()
+
+	if mc.compareModeEnabled() {
+		mc.submitProcessing(ProcessingRequest{Compare: true, Status: "Starting comparison..."})
 		return
 	}
 
-	// Get current algorithm and parameters
+	// Start now, or queue behind the active run with the current parameters
 	algorithm := mc.configRepo.GetCurrentAlgorithm()
-	
-	// Update UI state to show processing started
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.SetProcessingActive(true)
-			mc.mainView.UpdateStatus("Starting processing...")
-		}
-	})
+	mc.submitProcessing(mc.newProcessingRequest(algorithm, "Starting processing...", nil))
+}
+
+// ApplyThreshold re-processes the image with value as the fixed threshold,
+// skipping the algorithm's threshold optimisation
+func (mc *MainController) ApplyThreshold(value float64) {
+	if mc.imageRepo.GetOriginalImage() == nil {
+		mc.handleError("Processing failed", fmt.Errorf("no image loaded"))
+		return
+	}
+
+	mc.cancelPreview()
 
-	// Start processing in background
-	go mc.performImageProcessing(algorithm)
+	algorithm := mc.configRepo.GetCurrentAlgorithm()
+	status := fmt.Sprintf("Applying threshold %.1f...", value)
+	mc.submitProcessing(mc.newProcessingRequest(algorithm, status, map[string]interface{}{
+		threshold.ForceThresholdParam: &value,
+	}))
 }
 
-// CancelProcessing cancels ongoing processing
+// CancelProcessing cancels ongoing processing along with any queued runs
 func (mc *MainController) CancelProcessing() {
+	mc.drainQueue()
+
 	mc.mu.Lock()
 	if mc.processingCancelFunc != nil {
 		mc.processingCancelFunc()
@@ -141,12 +291,11 @@ func (mc *MainController) CancelProcessing() {
 
 	mc.processingService.CancelProcessing()
 
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.SetProcessingActive(false)
-			mc.mainView.UpdateStatus("Processing cancelled")
-		}
-	})
+	if mc.mainView != nil {
+		mc.mainView.SetProcessingActive(false)
+		mc.mainView.SetQueueLength(0)
+		mc.mainView.UpdateStatus("Processing cancelled")
+	}
 }
 
 // ChangeAlgorithm switches to a different algorithm
@@ -165,12 +314,10 @@ func (mc *MainController) ChangeAlgorithm(algorithm string) {
 	}
 
 	// Update view with new parameters
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.UpdateAlgorithmParameters(algorithm, params.Parameters)
-			mc.mainView.UpdateStatus(fmt.Sprintf("Algorithm changed to %s", algorithm))
-		}
-	})
+	if mc.mainView != nil {
+		mc.mainView.UpdateAlgorithmParameters(algorithm, params.Parameters)
+		mc.mainView.UpdateStatus(fmt.Sprintf("Algorithm changed to %s", algorithm))
+	}
 
 	// Emit algorithm change event
 	mc.emitEvent("algorithm_changed", algorithm)
@@ -192,273 +339,1811 @@ func (mc *MainController) UpdateParameter(name string, value interface{}) {
 		"parameter": name,
 		"value":     value,
 	})
+
+	mc.schedulePreview()
 }
 
-// GetApplicationState returns the current application state
-func (mc *MainController) GetApplicationState() ApplicationState {
-	mc.mu.RLock()
-	defer mc.mu.RUnlock()
+// SavePreset saves the parameters of the current algorithm under name
+func (mc *MainController) SavePreset(name string) {
+	if mc.presetManager == nil {
+		mc.handleError("Save preset failed", fmt.Errorf("presets are unavailable"))
+		return
+	}
 
-	originalImg := mc.imageRepo.GetOriginalImage()
-	processedImg := mc.imageRepo.GetLatestProcessedImage()
-	processingState := mc.stateRepo.GetState()
-	currentAlgorithm := mc.configRepo.GetCurrentAlgorithm()
+	algorithm := mc.configRepo.GetCurrentAlgorithm()
+	params, err := mc.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		mc.handleError("Save preset failed", err)
+		return
+	}
 
-	return ApplicationState{
-		HasOriginalImage:  originalImg != nil,
-		HasProcessedImage: processedImg != nil,
-		IsProcessing:      processingState.IsActive,
-		CurrentAlgorithm:  currentAlgorithm,
-		ProcessingStage:   processingState.CurrentStage,
-		ProcessingProgress: processingState.Progress,
-		LastImageLoad:     mc.lastImageLoad,
+	if err := mc.presetManager.SavePreset(name, algorithm, params.Parameters); err != nil {
+		mc.handleError("Save preset failed", err)
+		return
 	}
+
+	mc.refreshPresets()
+	mc.mainView.UpdateStatus(fmt.Sprintf("Saved preset %q", name))
 }
 
-// ApplicationState represents the current state of the application
-type ApplicationState struct {
-	HasOriginalImage   bool
-	HasProcessedImage  bool
-	IsProcessing       bool
-	CurrentAlgorithm   string
-	ProcessingStage    string
-	ProcessingProgress float64
-	LastImageLoad      time.Time
+// LoadPreset switches to the algorithm of the preset saved under name and
+// applies its parameters. Parameters the algorithm no longer has, or whose
+// type changed, are skipped.
+func (mc *MainController) LoadPreset(name string) {
+	if mc.presetManager == nil {
+		return
+	}
+
+	preset, err := mc.presetManager.LoadPreset(name)
+	if err != nil {
+		mc.handleError("Load preset failed", err)
+		return
+	}
+
+	if preset.Algorithm != mc.configRepo.GetCurrentAlgorithm() {
+		if err := mc.configRepo.SetCurrentAlgorithm(preset.Algorithm); err != nil {
+			mc.handleError("Load preset failed", err)
+			return
+		}
+		mc.emitEvent("algorithm_changed", preset.Algorithm)
+	}
+
+	current, err := mc.configRepo.GetAlgorithmParameters(preset.Algorithm)
+	if err != nil {
+		mc.handleError("Load preset failed", err)
+		return
+	}
+
+	applied := make(map[string]interface{}, len(preset.Parameters))
+	for key, value := range preset.Parameters {
+		defaultValue, known := current.Defaults[key]
+		if !known || fmt.Sprintf("%T", value) != fmt.Sprintf("%T", defaultValue) {
+			continue
+		}
+		if err := mc.configRepo.SetAlgorithmParameter(preset.Algorithm, key, value); err != nil {
+			continue
+		}
+		applied[key] = value
+	}
+
+	params, err := mc.configRepo.GetAlgorithmParameters(preset.Algorithm)
+	if err != nil {
+		mc.handleError("Load preset failed", err)
+		return
+	}
+
+	mc.mainView.ApplyPreset(preset.Algorithm, params.Parameters, applied)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Loaded preset %q", preset.Name))
 }
 
-// performImageProcessing handles the actual processing in background
-func (mc *MainController) performImageProcessing(algorithm string) {
-	// Create cancellable context
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+// refreshPresets lists the saved presets in the view
+func (mc *MainController) refreshPresets() {
+	if mc.presetManager == nil || mc.mainView == nil {
+		return
+	}
+
+	presets := mc.presetManager.ListPresets()
+	names := make([]string, len(presets))
+	for i, preset := range presets {
+		names[i] = preset.Name
+	}
+	mc.mainView.SetPresets(names)
+}
+
+// AutoTune searches the tunable parameters of the current algorithm on the
+// loaded image in background and applies the best values found
+func (mc *MainController) AutoTune() {
+	originalImage := mc.imageRepo.GetOriginalImage()
+	if originalImage == nil {
+		mc.handleError("Auto-tune failed", fmt.Errorf("no image loaded"))
+		return
+	}
+	if !mc.tryBeginRun() {
+		mc.showRunActive("Auto-Tune")
+		return
+	}
+
+	go func() {
+		defer mc.endRun()
+		mc.performAutoTune(mc.configRepo.GetCurrentAlgorithm(), originalImage)
+	}()
+}
+
+// performAutoTune runs the tuning trials with progress reporting and
+// cancellation, then fills in the parameter panel
+func (mc *MainController) performAutoTune(algorithm string, originalImage *models.ImageData) {
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	mc.mu.Lock()
 	mc.processingCancelFunc = cancel
 	mc.mu.Unlock()
 
-	// Start progress monitoring
-	go mc.monitorProcessingProgress()
+	mc.mainView.SetProcessingActive(true)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Auto-tuning %s...", algorithm))
 
-	// Perform processing
-	result, err := mc.processingService.ProcessImage(ctx, algorithm)
+	started := time.Now()
+	mc.parameterTuner.SetProgressHandler(func(done, total int) {
+		progress := float64(done) / float64(total)
+		eta := models.EstimateRemaining(time.Since(started), progress)
+		stage := fmt.Sprintf("Auto-tune trial %d/%d", done, total)
+		mc.mainView.UpdateProcessingProgress(stage, progress, eta)
+	})
+
+	params, err := mc.parameterTuner.TuneParameters(ctx, algorithm, originalImage, autoTuneTrials)
 
-	// Clear cancellation function
 	mc.mu.Lock()
 	mc.processingCancelFunc = nil
 	mc.mu.Unlock()
 
-	// Update UI based on result
-	fyne.Do(func() {
-		if mc.mainView == nil {
-			return
-		}
+	mc.mainView.SetProcessingActive(false)
 
-		mc.mainView.SetProcessingActive(false)
+	if err != nil {
+		mc.handleError("Auto-tune failed", err)
+		return
+	}
 
-		if err != nil {
-			if ctx.Err() != nil {
-				mc.mainView.UpdateStatus("Processing cancelled")
-			} else {
-				mc.mainView.UpdateStatus("Processing failed")
-				mc.handleError("Processing failed", err)
-			}
+	for name, value := range params {
+		if err := mc.configRepo.SetAlgorithmParameter(algorithm, name, value); err != nil {
+			mc.handleError("Auto-tune failed", err)
 			return
 		}
+	}
 
-		if result != nil && result.ProcessedImage != nil {
-			mc.mainView.SetProcessedImage(result.ProcessedImage.Image)
-			mc.mainView.UpdateSegmentationMetrics(result.Metrics)
-			mc.mainView.UpdateStatus("Processing completed")
-
-			// Emit processing complete event
-			mc.emitEvent("processing_complete", result)
-		} else {
-			mc.mainView.UpdateStatus("Processing failed - no result")
-		}
-	})
+	mc.mainView.UpdateAlgorithmParameters(algorithm, params)
+	mc.mainView.UpdateStatus("Auto-tune complete, best parameters applied")
 }
 
-// monitorProcessingProgress tracks processing progress and updates UI
-func (mc *MainController) monitorProcessingProgress() {
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		state := mc.stateRepo.GetState()
-		if !state.IsActive {
-			break
-		}
-
-		// Update UI with current progress
-		fyne.Do(func() {
-			if mc.mainView != nil {
-				mc.mainView.UpdateProcessingProgress(state.CurrentStage, state.Progress)
-			}
-		})
+// AnalyseSensitivity sweeps each numeric parameter of the current algorithm
+// and shows which ones move the IoU most
+func (mc *MainController) AnalyseSensitivity() {
+	originalImage := mc.imageRepo.GetOriginalImage()
+	if originalImage == nil {
+		mc.handleError("Sensitivity analysis failed", fmt.Errorf("no image loaded"))
+		return
 	}
-}
-
-// showFileLoadDialog displays the file selection dialog
-func (mc *MainController) showFileLoadDialog() {
-	if mc.currentWindow == nil {
+	if !mc.tryBeginRun() {
+		mc.showRunActive("Sensitivity Analysis")
 		return
 	}
 
-	// This would be implemented with actual Fyne file dialog
-	// For now, this is a placeholder that shows the pattern
-	
-	// dialog.ShowFileOpen(func(reader fyne.URIReadCloser, err error) {
-	//     if err != nil {
-	//         mc.handleError("File selection error", err)
-	//         return
-	//     }
-	//     if reader == nil {
-	//         return
-	//     }
-	//     
-	//     go mc.loadImageFromReader(reader)
-	// }, mc.currentWindow)
+	go func() {
+		defer mc.endRun()
+		mc.performSensitivityAnalysis(mc.configRepo.GetCurrentAlgorithm(), originalImage)
+	}()
 }
 
-// showFileSaveDialog displays the file save dialog
-func (mc *MainController) showFileSaveDialog(imageData *models.ImageData) {
-	if mc.currentWindow == nil {
-		return
-	}
-
-	// This would be implemented with actual Fyne file dialog
-	// For now, this is a placeholder that shows the pattern
-	
-	// dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
-	//     if err != nil {
-	//         mc.handleError("File save error", err)
-	//         return
-	//     }
-	//     if writer == nil {
-	//         return
-	//     }
-	//     
-	//     go mc.saveImageToWriter(writer, imageData)
-	// }, mc.currentWindow)
-}
-
-// loadImageFromReader loads an image from a file reader
-func (mc *MainController) loadImageFromReader(reader fyne.URIReadCloser) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// performSensitivityAnalysis runs the sweep with progress reporting and
+// cancellation, then shows the ranked parameters
+func (mc *MainController) performSensitivityAnalysis(algorithm string, originalImage *models.ImageData) {
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.UpdateStatus("Loading image...")
-		}
+	mc.mu.Lock()
+	mc.processingCancelFunc = cancel
+	mc.mu.Unlock()
+
+	mc.mainView.SetProcessingActive(true)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Analysing %s parameter sensitivity...", algorithm))
+
+	started := time.Now()
+	mc.sensitivity.SetProgressHandler(func(done, total int) {
+		progress := float64(done) / float64(total)
+		eta := models.EstimateRemaining(time.Since(started), progress)
+		stage := fmt.Sprintf("Sensitivity run %d/%d", done, total)
+		mc.mainView.UpdateProcessingProgress(stage, progress, eta)
 	})
 
-	imageData, err := mc.imageService.LoadImage(ctx, reader)
-	if err != nil {
-		fyne.Do(func() {
-			mc.handleError("Image load failed", err)
-			if mc.mainView != nil {
-				mc.mainView.UpdateStatus("Ready")
-			}
-		})
-		return
+	groundTruth := mc.imageRepo.GetGroundTruth()
+	results, err := mc.sensitivity.Analyse(ctx, algorithm, originalImage, groundTruth, services.MaxSensitivitySteps)
+	if groundTruth != nil {
+		groundTruth.Mat.Close()
 	}
 
 	mc.mu.Lock()
-	mc.lastImageLoad = time.Now()
+	mc.processingCancelFunc = nil
 	mc.mu.Unlock()
 
-	// Update UI with loaded image
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.SetOriginalImage(imageData.Image)
-			mc.mainView.SetProcessedImage(nil) // Clear previous result
-			mc.mainView.UpdateStatus("Image loaded")
-		}
-	})
+	mc.mainView.SetProcessingActive(false)
 
-	// Emit image loaded event
-	mc.emitEvent("image_loaded", imageData)
+	if err != nil {
+		mc.handleError("Sensitivity analysis failed", err)
+		return
+	}
+
+	reference := "the result of the current parameters"
+	if groundTruth != nil {
+		reference = "the ground truth"
+	}
+
+	names := make([]string, len(results))
+	slopes := make([]float64, len(results))
+	for i, result := range results {
+		names[i] = result.ParameterName
+		slopes[i] = result.Slope
+	}
+
+	mc.mainView.ShowSensitivityResults(algorithm, reference, names, slopes)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Sensitivity analysis complete, %s matters most", names[0]))
 }
 
-// saveImageToWriter saves an image to a file writer
-func (mc *MainController) saveImageToWriter(writer fyne.URIWriteCloser, imageData *models.ImageData) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+// PreviewEnabled returns true if parameter changes trigger a live preview
+func (mc *MainController) PreviewEnabled() bool {
+	mc.previewMu.Lock()
+	defer mc.previewMu.Unlock()
+	return mc.previewEnabled
+}
 
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.UpdateStatus("Saving image...")
-		}
-	})
+// SetPreviewEnabled toggles live preview on parameter changes
+func (mc *MainController) SetPreviewEnabled(enabled bool) {
+	mc.previewMu.Lock()
+	mc.previewEnabled = enabled
+	mc.previewMu.Unlock()
 
-	err := mc.imageService.SaveImage(ctx, writer, imageData, "")
-	
-	fyne.Do(func() {
+	mc.configRepo.SetGlobalSetting("auto_preview", enabled)
+	if !enabled {
+		mc.cancelPreview()
+	}
+}
+
+// UpdateGlobalSetting stores an edited preference and applies its effect
+func (mc *MainController) UpdateGlobalSetting(key string, value interface{}) {
+	mc.configRepo.SetGlobalSetting(key, value)
+
+	switch key {
+	case "auto_preview":
+		if enabled, ok := value.(bool); ok {
+			mc.SetPreviewEnabled(enabled)
+		}
+	case "ui_theme", "ui_scale":
+		mc.applyAppearance()
+	case "enable_dp":
 		if mc.mainView != nil {
-			if err != nil {
-				mc.handleError("Image save failed", err)
-				mc.mainView.UpdateStatus("Save failed")
+			if mc.privacyActive() {
+				mc.mainView.UpdateStatus(privacy.StatusWarning)
 			} else {
-				mc.mainView.UpdateStatus("Image saved")
+				mc.mainView.UpdateStatus("Differential privacy off")
 			}
 		}
-	})
-
-	if err == nil {
-		// Emit image saved event
-		mc.emitEvent("image_saved", imageData)
 	}
 }
 
-// Event system methods
+// privacyActive reports whether processing adds differential privacy noise
+func (mc *MainController) privacyActive() bool {
+	enabled, _ := mc.configRepo.GetGlobalSetting("enable_dp")
+	return enabled == true
+}
 
-// initializeEventHandlers sets up default event handlers
-func (mc *MainController) initializeEventHandlers() {
-	mc.addEventListener("image_loaded", mc.onImageLoaded)
-	mc.addEventListener("processing_complete", mc.onProcessingComplete)
-	mc.addEventListener("algorithm_changed", mc.onAlgorithmChanged)
+// ResetGlobalSettings restores default preferences and reapplies them
+func (mc *MainController) ResetGlobalSettings() {
+	mc.configRepo.ResetGlobalSettings()
+
+	enabled := false
+	if autoPreview, ok := mc.configRepo.GetGlobalSetting("auto_preview"); ok {
+		enabled, _ = autoPreview.(bool)
+	}
+	mc.previewMu.Lock()
+	mc.previewEnabled = enabled
+	mc.previewMu.Unlock()
+	if mc.mainView != nil {
+		mc.mainView.SetPreviewEnabled(enabled)
+	}
+
+	mc.applyAppearance()
 }
 
-// setupViewEventHandlers connects view events to controller methods
-func (mc *MainController) setupViewEventHandlers() {
+// applyAppearance applies the ui_theme and ui_scale preferences to the view
+func (mc *MainController) applyAppearance() {
 	if mc.mainView == nil {
 		return
 	}
 
-	// Connect view callbacks to controller methods
-	mc.mainView.SetLoadImageHandler(mc.LoadImage)
-	mc.mainView.SetSaveImageHandler(mc.SaveImage)
-	mc.mainView.SetProcessImageHandler(mc.ProcessImage)
-	mc.mainView.SetCancelProcessingHandler(mc.CancelProcessing)
-	mc.mainView.SetAlgorithmChangeHandler(mc.ChangeAlgorithm)
-	mc.mainView.SetParameterChangeHandler(mc.UpdateParameter)
+	settings := mc.configRepo.GetGlobalSettings()
+	themeName, _ := settings["ui_theme"].(string)
+	scale, _ := settings["ui_scale"].(float64)
+	mc.mainView.ApplyAppearance(themeName, scale)
 }
 
-// addEventListener adds an event handler for a specific event type
-func (mc *MainController) addEventListener(eventType string, handler EventHandler) {
-	mc.eventMu.Lock()
-	defer mc.eventMu.Unlock()
+// SetFullResolution turns downscaling of large images before processing off or on
+func (mc *MainController) SetFullResolution(enabled bool) {
+	settings := mc.configRepo.GetPerformanceSettings()
+	settings.FullResolution = enabled
+	mc.configRepo.UpdatePerformanceSettings(settings)
 
-	if mc.eventHandlers[eventType] == nil {
-		mc.eventHandlers[eventType] = make([]EventHandler, 0)
-	}
-	mc.eventHandlers[eventType] = append(mc.eventHandlers[eventType], handler)
+	mc.schedulePreview()
 }
 
-// emitEvent triggers all handlers for a specific event type
-func (mc *MainController) emitEvent(eventType string, data interface{}) {
-	mc.eventMu.RLock()
-	handlers := mc.eventHandlers[eventType]
-	mc.eventMu.RUnlock()
+// schedulePreview restarts the debounce timer for a live preview
+func (mc *MainController) schedulePreview() {
+	mc.previewMu.Lock()
+	defer mc.previewMu.Unlock()
 
-	for _, handler := range handlers {
-		go func(h EventHandler) {
-			if err := h(data); err != nil {
-				mc.handleError(fmt.Sprintf("Event handler error (%s)", eventType), err)
-			}
-		}(handler)
+	if !mc.previewEnabled || mc.imageRepo.GetOriginalImage() == nil {
+		return
+	}
+
+	if mc.previewDebouncer != nil {
+		mc.previewDebouncer.Stop()
 	}
+	mc.previewDebouncer = time.AfterFunc(previewDebounceDelay, mc.performPreview)
+}
+
+// cancelPreview stops a pending or running preview
+func (mc *MainController) cancelPreview() {
+	mc.previewMu.Lock()
+	defer mc.previewMu.Unlock()
+
+	if mc.previewDebouncer != nil {
+		mc.previewDebouncer.Stop()
+		mc.previewDebouncer = nil
+	}
+	if mc.previewCancel != nil {
+		mc.previewCancel()
+		mc.previewCancel = nil
+	}
+}
+
+// performPreview runs a reduced quality pass and shows it in the processed pane
+func (mc *MainController) performPreview() {
+	// Full processing takes priority over previews
+	if mc.isRunActive() || mc.processingService.IsProcessing() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mc.previewMu.Lock()
+	if mc.previewCancel != nil {
+		mc.previewCancel()
+	}
+	mc.previewCancel = cancel
+	mc.previewMu.Unlock()
+
+	algorithm := mc.configRepo.GetCurrentAlgorithm()
+	img, err := mc.processingService.ProcessPreview(ctx, algorithm, previewParameterOverrides)
+	if err != nil {
+		if ctx.Err() == nil {
+			if mc.mainView != nil {
+				mc.mainView.UpdateStatus(fmt.Sprintf("Preview failed: %v", err))
+			}
+		}
+		return
+	}
+
+	if mc.mainView != nil && ctx.Err() == nil {
+		mc.mainView.SetPreviewImage(img)
+		mc.mainView.UpdateStatus("Preview updated")
+	}
+}
+
+// BatchProcess asks for input and output directories and processes every image
+func (mc *MainController) BatchProcess() {
+	if mc.mainView == nil {
+		return
+	}
+	if mc.isRunActive() {
+		mc.showRunActive("Batch Process")
+		return
+	}
+
+	mc.mainView.ShowFolderDialog(func(inputDir fyne.ListableURI, err error) {
+		if err != nil {
+			mc.handleError("Folder selection error", err)
+			return
+		}
+		if inputDir == nil {
+			return
+		}
+
+		mc.mainView.ShowFolderDialog(func(outputDir fyne.ListableURI, err error) {
+			if err != nil {
+				mc.handleError("Folder selection error", err)
+				return
+			}
+			if outputDir == nil {
+				return
+			}
+
+			mc.performBatchProcessing(inputDir, outputDir)
+		})
+	})
+}
+
+// performBatchProcessing runs a batch in background and reports the summary
+func (mc *MainController) performBatchProcessing(inputDir, outputDir fyne.URI) {
+	mc.startBatch(func(ctx context.Context, progressFn func(int, int)) ([]services.BatchResult, error) {
+		return mc.batchProcessor.RunBatch(ctx, inputDir, outputDir, progressFn)
+	})
+}
+
+// startBatch takes the run gate and runs a batch in background. A run started
+// while the folders were chosen makes it refuse, so the batch never takes over
+// the cancellation of another run.
+func (mc *MainController) startBatch(run func(context.Context, func(int, int)) ([]services.BatchResult, error)) {
+	if !mc.tryBeginRun() {
+		mc.showRunActive("Batch Process")
+		return
+	}
+
+	go func() {
+		defer mc.endRun()
+		mc.runBatch(run)
+	}()
+}
+
+// runBatch runs a batch with progress reporting and cancellation and shows
+// the summary
+func (mc *MainController) runBatch(run func(context.Context, func(int, int)) ([]services.BatchResult, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mc.mu.Lock()
+	mc.processingCancelFunc = cancel
+	mc.mu.Unlock()
+
+	mc.mainView.SetProcessingActive(true)
+	mc.mainView.UpdateStatus("Batch processing...")
+
+	started := time.Now()
+	results, err := run(ctx, func(done, total int) {
+		progress := float64(done) / float64(total)
+		eta := models.EstimateRemaining(time.Since(started), progress)
+		stage := fmt.Sprintf("Batch %d/%d", done, total)
+		mc.mainView.UpdateProcessingProgress(stage, progress, eta)
+	})
+
+	mc.mu.Lock()
+	mc.processingCancelFunc = nil
+	mc.mu.Unlock()
+
+	mc.mainView.SetProcessingActive(false)
+
+	if err != nil && len(results) == 0 {
+		mc.handleError("Batch processing failed", err)
+		mc.mainView.UpdateStatus("Batch processing failed")
+		return
+	}
+
+	summary := services.SummarizeBatch(results)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Batch complete: %d/%d images", summary.Succeeded, summary.Total))
+	mc.mainView.ShowBatchSummary(summary.Total, summary.Failed, summary.MeanProcessTime, summary.MeanIoU, func() {
+		mc.exportBatchResults(results)
+	})
+}
+
+// exportBatchResults writes batch results to a CSV file chosen by the user
+func (mc *MainController) exportBatchResults(results []services.BatchResult) {
+	mc.mainView.ShowSaveDialog(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			mc.handleError("File save error", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if err := services.WriteBatchCSV(writer, results); err != nil {
+			mc.handleError("CSV export failed", err)
+			return
+		}
+		mc.mainView.UpdateStatus("Batch results exported")
+	})
+}
+
+// ExportReport writes the latest processing result to a PDF report chosen by the user
+func (mc *MainController) ExportReport() {
+	original := mc.imageRepo.GetOriginalImage()
+	latest := mc.processingService.GetLatestResult()
+	if original == nil || latest == nil {
+		mc.mainView.ShowInfo("Export PDF Report", "Process an image before exporting a report.")
+		return
+	}
+
+	generator := services.NewReportGenerator(latest.Algorithm)
+	if history, ok := latest.Statistics["convergence"].([]float64); ok {
+		generator.SetConvergenceHistory(history)
+	}
+
+	mc.mainView.ShowExportDialog("report.pdf", []string{".pdf"}, func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			mc.handleError("File save error", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+
+		go func() {
+			defer writer.Close()
+
+			err := generator.GeneratePDF(original, latest.ProcessedImage, latest.Metrics, latest.Parameters, writer)
+			if err != nil {
+				mc.handleError("Report export failed", err)
+				return
+			}
+			mc.mainView.UpdateStatus("Report exported")
+		}()
+	})
+}
+
+// ExportImageJMacro shows the current algorithm and parameters as an ImageJ
+// macro ready to copy into ImageJ or Fiji
+func (mc *MainController) ExportImageJMacro() {
+	algorithm := mc.configRepo.GetCurrentAlgorithm()
+	params, err := mc.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		mc.handleError("ImageJ macro export failed", err)
+		return
+	}
+
+	source, err := export.NewImageJMacroExporter().Export(algorithm, params.Parameters)
+	if err != nil {
+		mc.mainView.ShowInfo("Export ImageJ Macro", fmt.Sprintf("%v. Macro export supports 2D Otsu and Iterative Triclass.", err))
+		return
+	}
+
+	mc.mainView.ShowMacro("ImageJ Macro", source)
+}
+
+// ChooseAutoSaveFolder asks for the folder auto-saved images are written to
+func (mc *MainController) ChooseAutoSaveFolder() {
+	if mc.autoSaver == nil {
+		return
+	}
+
+	mc.mainView.ShowFolderDialog(func(dir fyne.ListableURI, err error) {
+		if err != nil {
+			mc.handleError("Folder selection error", err)
+			return
+		}
+		if dir == nil {
+			return
+		}
+
+		mc.autoSaver.SetAutoSaveDirectory(dir)
+		mc.mainView.UpdateStatus("Auto-saving to " + dir.Name())
+	})
+}
+
+// Undo restores the previous processed image
+func (mc *MainController) Undo() {
+	img, ok := mc.imageRepo.GetUndoStack().Undo()
+	if !ok {
+		mc.mainView.UpdateStatus("Nothing to undo")
+		return
+	}
+
+	mc.mainView.SetProcessedImage(img.Image)
+	mc.setShownProcessed(img)
+	mc.mainView.UpdateStatus("Undo")
+}
+
+// Redo re-applies the most recently undone processed image
+func (mc *MainController) Redo() {
+	img, ok := mc.imageRepo.GetUndoStack().Redo()
+	if !ok {
+		mc.mainView.UpdateStatus("Nothing to redo")
+		return
+	}
+
+	mc.mainView.SetProcessedImage(img.Image)
+	mc.setShownProcessed(img)
+	mc.mainView.UpdateStatus("Redo")
+}
+
+// RestoreHistoryEntry shows the image and metrics of a previous processing run
+func (mc *MainController) RestoreHistoryEntry(index int) {
+	entry, err := mc.imageRepo.GetHistoryEntry(index)
+	if err != nil {
+		mc.handleError("History", err)
+		return
+	}
+
+	mc.mainView.SetProcessedImage(entry.Image.Image)
+	mc.setShownProcessed(entry.Image)
+	mc.mainView.UpdateSegmentationMetrics(entry.Metrics)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Restored %s run from %s", entry.Algorithm, entry.Timestamp.Format("15:04:05")))
+}
+
+// refreshHistory applies the configured history length and lists the runs
+func (mc *MainController) refreshHistory() {
+	if entries, ok := mc.configRepo.GetGlobalSetting("max_history_entries"); ok {
+		if maxEntries, isInt := entries.(int); isInt {
+			mc.imageRepo.SetMaxHistorySize(maxEntries)
+		}
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.SetHistory(mc.imageRepo.GetHistory())
+	}
+}
+
+// pushUndoSnapshot records a processed image in the undo history if enabled
+func (mc *MainController) pushUndoSnapshot(img *models.ImageData) {
+	if enabled, ok := mc.configRepo.GetGlobalSetting("enable_undo"); ok {
+		if enabledBool, isBool := enabled.(bool); isBool && !enabledBool {
+			return
+		}
+	}
+
+	undoStack := mc.imageRepo.GetUndoStack()
+	if levels, ok := mc.configRepo.GetGlobalSetting("max_undo_levels"); ok {
+		if maxLevels, isInt := levels.(int); isInt {
+			undoStack.SetMaxLevels(maxLevels)
+		}
+	}
+
+	undoStack.PushUndo(img)
+}
+
+// GetApplicationState returns the current application state
+func (mc *MainController) GetApplicationState() ApplicationState {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	originalImg := mc.imageRepo.GetOriginalImage()
+	processedImg := mc.imageRepo.GetLatestProcessedImage()
+	processingState := mc.stateRepo.GetState()
+	currentAlgorithm := mc.configRepo.GetCurrentAlgorithm()
+
+	return ApplicationState{
+		HasOriginalImage:  originalImg != nil,
+		HasProcessedImage: processedImg != nil,
+		IsProcessing:      processingState.IsActive,
+		CurrentAlgorithm:  currentAlgorithm,
+		ProcessingStage:   processingState.CurrentStage,
+		ProcessingProgress: processingState.Progress,
+		LastImageLoad:     mc.lastImageLoad,
+	}
+}
+
+// ApplicationState represents the current state of the application
+type ApplicationState struct {
+	HasOriginalImage   bool
+	HasProcessedImage  bool
+	IsProcessing       bool
+	CurrentAlgorithm   string
+	ProcessingStage    string
+	ProcessingProgress float64
+	LastImageLoad      time.Time
+}
+
+// performImageProcessing handles the actual processing in background
+func (mc *MainController) performImageProcessing(algorithm string, overrides map[string]interface{}) {
+	// Create cancellable context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// Root span of the run; algorithm stages nest below it
+	ctx, span := tracer.Start(ctx, "MainController.performImageProcessing",
+		trace.WithAttributes(attribute.String("algorithm", algorithm)))
+	defer span.End()
+
+	mc.mu.Lock()
+	mc.processingCancelFunc = cancel
+	mc.mu.Unlock()
+
+	// Start progress monitoring
+	go mc.monitorProcessingProgress()
+
+	// Perform processing on the output of any pre-process hooks
+	var result *models.ProcessingResult
+	var err error
+	if original := mc.imageRepo.GetOriginalImage(); original != nil {
+		input := mc.runPreProcessHooks(original)
+		result, err = mc.processingService.ProcessImageFrom(ctx, algorithm, input, overrides)
+		if input != original {
+			input.Mat.Close()
+		}
+	} else {
+		result, err = mc.processingService.ProcessImage(ctx, algorithm)
+	}
+	if err == nil && result != nil && result.ProcessedImage != nil {
+		mc.emitPostProcessHooks(result)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if result != nil && mc.logger != nil {
+		mc.logger.Info("Image processing completed", map[string]interface{}{
+			"algorithm":       result.Algorithm,
+			"processing_time": result.ProcessTime,
+			"trace_id":        monitoring.TraceID(ctx),
+		})
+	}
+
+	// Clear cancellation function
+	mc.mu.Lock()
+	mc.processingCancelFunc = nil
+	mc.mu.Unlock()
+
+	if err != nil && ctx.Err() == nil {
+		mc.handleError("Processing failed", err)
+	}
+
+	// Update UI based on result
+	if mc.mainView == nil {
+		return
+	}
+
+	mc.mainView.SetProcessingActive(false)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			mc.mainView.UpdateStatus("Processing cancelled")
+		} else {
+			mc.mainView.UpdateStatus("Processing failed")
+		}
+		return
+	}
+
+	if result != nil && result.ProcessedImage != nil {
+		mc.pushUndoSnapshot(result.ProcessedImage)
+		mc.mainView.SetProcessedImage(result.ProcessedImage.Image)
+		mc.setShownProcessed(result.ProcessedImage)
+		mc.mainView.SetDifferenceImage(resultDifference(result))
+		mc.mainView.SetThresholds(resultThresholds(result))
+		mc.mainView.SetThresholdValue(resultThreshold(result))
+		go mc.refreshErrorMap()
+		mc.mainView.UpdateSegmentationMetrics(result.Metrics)
+		status := "Processing completed"
+		if size, ok := result.Statistics["downscaled_to"].(image.Point); ok {
+			status = fmt.Sprintf("Processing completed - Downscaled to %dx%d", size.X, size.Y)
+		}
+		if mc.privacyActive() {
+			status += " - " + privacy.StatusWarning
+		}
+		mc.mainView.UpdateStatus(status)
+
+		// Emit processing complete event
+		mc.emitEvent("processing_complete", result)
+	} else {
+		mc.mainView.UpdateStatus("Processing failed - no result")
+	}
+}
+
+// resultThreshold returns the threshold a run settled on: the pixel axis of
+// the 2D Otsu optimum, the last iteration of iterative algorithms or the first
+// reported threshold
+func resultThreshold(result *models.ProcessingResult) (float64, bool) {
+	if value, ok := threshold.ForcedThreshold(result.Parameters); ok {
+		return value, true
+	}
+
+	if optimal, ok := result.Statistics["optimal_threshold"].([2]float64); ok {
+		return optimal[0], true
+	}
+	if history, ok := result.Statistics["convergence_history"].([]convergence.ConvergenceRecord); ok && len(history) > 0 {
+		return history[len(history)-1].Threshold, true
+	}
+	if thresholds := resultThresholds(result); len(thresholds) > 0 {
+		return float64(thresholds[0]), true
+	}
+	return 0, false
+}
+
+// resultDifference returns the difference image of result, nil without one
+func resultDifference(result *models.ProcessingResult) image.Image {
+	if result.DifferenceImage == nil {
+		return nil
+	}
+	return result.DifferenceImage.Image
+}
+
+// resultThresholds extracts threshold values reported in result statistics
+func resultThresholds(result *models.ProcessingResult) []uint8 {
+	if result.Statistics == nil {
+		return nil
+	}
+
+	switch thresholds := result.Statistics["thresholds"].(type) {
+	case []uint8:
+		return thresholds
+	case []int:
+		values := make([]uint8, 0, len(thresholds))
+		for _, t := range thresholds {
+			if t >= 0 && t <= 255 {
+				values = append(values, uint8(t))
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// monitorProcessingProgress tracks processing progress and updates UI
+func (mc *MainController) monitorProcessingProgress() {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		state := mc.stateRepo.GetState()
+		if !state.IsActive {
+			break
+		}
+
+		// Update UI with current progress
+		if mc.mainView != nil {
+			mc.mainView.UpdateProcessingProgress(state.CurrentStage, state.Progress, state.ETA)
+		}
+	}
+}
+
+// showFileLoadDialog displays the file selection dialog
+func (mc *MainController) showFileLoadDialog() {
+	if mc.currentWindow == nil {
+		return
+	}
+
+	mc.mainView.ShowFileDialog(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			mc.handleError("File selection error", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+
+		go mc.loadImageFromReader(reader)
+	})
+}
+
+// showFileSaveDialog displays the file save dialog
+func (mc *MainController) showFileSaveDialog(imageData *models.ImageData) {
+	if mc.currentWindow == nil {
+		return
+	}
+
+	format := "png"
+	if setting, ok := mc.configRepo.GetGlobalSetting("default_save_format"); ok {
+		if f, ok := setting.(string); ok && models.IsSupportedSaveFormat(f) {
+			format = f
+		}
+	}
+
+	originalName := ""
+	if imageData.OriginalURI != nil {
+		originalName = imageData.OriginalURI.Name()
+	}
+
+	fileName := models.ProcessedFileName(originalName, format)
+	mc.mainView.ShowImageSaveDialog(fileName, models.SaveFormatExtension(format), func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			mc.handleError("File save error", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+
+		go mc.saveImageToWriter(writer, imageData)
+	})
+}
+
+// CancelLoad stops the image load in progress, if any
+func (mc *MainController) CancelLoad() {
+	mc.mu.Lock()
+	if mc.loadCancelFunc != nil {
+		mc.loadCancelFunc()
+	}
+	mc.mu.Unlock()
+}
+
+// loadImageFromReader loads an image from a file reader, reporting how much
+// of it has been read so slow network storage shows progress. Starting a new
+// load cancels the previous one.
+func (mc *MainController) loadImageFromReader(reader fyne.URIReadCloser) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mc.mu.Lock()
+	if mc.loadCancelFunc != nil {
+		mc.loadCancelFunc()
+	}
+	mc.loadCancelFunc = cancel
+	mc.mu.Unlock()
+
+	defer func() {
+		mc.mu.Lock()
+		mc.loadCancelFunc = nil
+		mc.mu.Unlock()
+		if mc.mainView != nil {
+			mc.mainView.SetLoadingActive(false)
+		}
+	}()
+
+	if mc.mainView != nil {
+		mc.mainView.SetLoadingActive(true)
+		mc.mainView.UpdateStatus("Loading image...")
+	}
+
+	started := time.Now()
+	ctx = services.WithLoadProgress(ctx, func(read, total int64) {
+		stage := fmt.Sprintf("Loading %.0f MB", float64(read)/(1<<20))
+		progress, eta := 0.0, time.Duration(-1)
+		if total > 0 {
+			stage = fmt.Sprintf("Loading %.0f of %.0f MB", float64(read)/(1<<20), float64(total)/(1<<20))
+			progress = min(1, float64(read)/float64(total))
+			eta = models.EstimateRemaining(time.Since(started), progress)
+		}
+		if mc.mainView != nil {
+			mc.mainView.UpdateProcessingProgress(stage, progress, eta)
+			mc.mainView.UpdateStatus(stage)
+		}
+	})
+
+	var imageData *models.ImageData
+	var err error
+	if services.IsTIFF(reader.URI()) {
+		imageData, err = mc.loadTIFFPages(reader)
+	} else {
+		mc.releasePages()
+		imageData, err = mc.imageService.LoadImage(ctx, reader)
+	}
+	mc.releaseStack()
+	if errors.Is(err, context.Canceled) {
+		if mc.mainView != nil {
+			mc.mainView.UpdateStatus("Load cancelled")
+		}
+		return
+	}
+	if err != nil {
+		mc.handleError("Image load failed", err)
+		if mc.mainView != nil {
+			mc.mainView.UpdateStatus("Ready")
+		}
+		return
+	}
+
+	mc.mu.Lock()
+	mc.lastImageLoad = time.Now()
+	mc.mu.Unlock()
+
+	mc.mu.RLock()
+	title := mc.windowTitle
+	mc.mu.RUnlock()
+	if uri := reader.URI(); uri != nil && title != "" {
+		title = fmt.Sprintf("%s - %s", title, uri.Name())
+	}
+
+	// Update UI with loaded image
+	if mc.mainView != nil {
+		mc.mainView.SetOriginalImage(imageData.Image)
+		mc.mainView.SetProcessedImage(nil) // Clear previous result
+		mc.mainView.SetImageMetadata(imageData.Metadata.Tags)
+		mc.mainView.EnableImageOperations(true)
+		mc.mainView.UpdateStatus("Image loaded")
+		if title != "" {
+			mc.mainView.SetWindowTitle(title)
+		}
+	}
+
+	// Emit image loaded event
+	mc.emitEvent("image_loaded", imageData)
+}
+
+// LoadDroppedFiles loads the first supported file dropped on the window and
+// offers to batch process the others
+func (mc *MainController) LoadDroppedFiles(uris []fyne.URI) {
+	var files []fyne.URI
+	for _, uri := range uris {
+		if isOpenableImage(uri) {
+			files = append(files, uri)
+		}
+	}
+
+	if len(files) == 0 {
+		mc.handleError("Unsupported file", fmt.Errorf("supported formats: %s", strings.Join(models.OpenFormatExtensions, ", ")))
+		return
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.UpdateStatus(fmt.Sprintf("Loading %s...", files[0].Name()))
+	}
+
+	go func() {
+		file, err := os.Open(files[0].Path())
+		if err != nil {
+			mc.handleError("Image load failed", err)
+			return
+		}
+		mc.loadImageFromReader(&droppedFile{File: file, uri: files[0]})
+	}()
+
+	if rest := files[1:]; len(rest) > 0 && mc.mainView != nil {
+		message := fmt.Sprintf("Batch process the other %d dropped images with the current algorithm?", len(rest))
+		mc.mainView.ShowConfirm("Batch Process", message, func(confirmed bool) {
+			if confirmed {
+				mc.batchProcessFiles(rest)
+			}
+		})
+	}
+}
+
+// batchProcessFiles asks for an output directory and processes files into it
+func (mc *MainController) batchProcessFiles(files []fyne.URI) {
+	if mc.isRunActive() {
+		mc.showRunActive("Batch Process")
+		return
+	}
+
+	mc.mainView.ShowFolderDialog(func(outputDir fyne.ListableURI, err error) {
+		if err != nil {
+			mc.handleError("Folder selection error", err)
+			return
+		}
+		if outputDir == nil {
+			return
+		}
+
+		mc.startBatch(func(ctx context.Context, progressFn func(int, int)) ([]services.BatchResult, error) {
+			return mc.batchProcessor.RunFiles(ctx, files, outputDir, progressFn)
+		})
+	})
+}
+
+// isOpenableImage reports whether uri has an extension offered in open dialogs
+func isOpenableImage(uri fyne.URI) bool {
+	if uri == nil || uri.Scheme() != "file" {
+		return false
+	}
+	extension := strings.ToLower(uri.Extension())
+	for _, supported := range models.OpenFormatExtensions {
+		if extension == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// droppedFile adapts a file opened from a drop to fyne.URIReadCloser
+type droppedFile struct {
+	*os.File
+	uri fyne.URI
+}
+
+func (f *droppedFile) URI() fyne.URI {
+	return f.uri
+}
+
+// loadTIFFPages reads every page of a TIFF and shows the first one
+func (mc *MainController) loadTIFFPages(reader fyne.URIReadCloser) (*models.ImageData, error) {
+	pages, err := mc.imageService.LoadTIFFPages(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	mc.releasePages()
+
+	mc.mu.Lock()
+	mc.pages = pages
+	mc.currentPage = 0
+	mc.mu.Unlock()
+
+	return mc.activatePage(0)
+}
+
+// LoadGroundTruth asks for a reference mask used for metrics and the error map
+func (mc *MainController) LoadGroundTruth() {
+	if mc.mainView == nil {
+		return
+	}
+
+	mc.mainView.ShowFileDialog(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			mc.handleError("File selection error", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+
+		go func() {
+			if err := mc.imageService.LoadGroundTruth(reader); err != nil {
+				mc.handleError("Ground truth load failed", err)
+				return
+			}
+
+			if mc.mainView != nil {
+				mc.mainView.SetErrorMapAvailable(true)
+				mc.mainView.UpdateStatus("Ground truth loaded")
+			}
+			mc.refreshErrorMap()
+		}()
+	})
+}
+
+// ExportConfig saves the current algorithm, the parameters of every algorithm
+// and the performance settings as YAML, so a run can be reproduced later
+func (mc *MainController) ExportConfig() {
+	data, err := models.NewConfigSerializer().Marshal(mc.configRepo)
+	if err != nil {
+		mc.handleError("Configuration export failed", err)
+		return
+	}
+
+	mc.mainView.ShowExportDialog("otsu-config.yaml", []string{".yaml", ".yml"}, func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			mc.handleError("File save error", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if _, err := writer.Write(data); err != nil {
+			mc.handleError("Configuration export failed", err)
+			return
+		}
+		mc.mainView.UpdateStatus("Configuration exported")
+	})
+}
+
+// ImportConfig reads a YAML configuration and applies it only when the file
+// parses and every algorithm accepts its parameters; otherwise every problem
+// found is listed and the current configuration is kept
+func (mc *MainController) ImportConfig() {
+	mc.mainView.ShowOpenDialog([]string{".yaml", ".yml"}, func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			mc.handleError("File selection error", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+
+		name := reader.URI().Name()
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			mc.handleError("Configuration import failed", err)
+			return
+		}
+
+		imported, err := models.NewConfigSerializer().Unmarshal(data)
+		var problems []string
+		if err != nil {
+			problems = strings.Split(err.Error(), "\n")
+		} else {
+			problems = mc.validateImportedConfig(imported)
+		}
+		if len(problems) > 0 {
+			mc.mainView.ShowValidationErrors("Import Config YAML", fmt.Sprintf("%s was not applied:", name), problems)
+			return
+		}
+
+		mc.applyImportedConfig(imported)
+		mc.mainView.UpdateStatus(fmt.Sprintf("Configuration imported from %s", name))
+	})
+}
+
+// validateImportedConfig runs each algorithm's own parameter validation on
+// the imported parameters, returning one line per problem
+func (mc *MainController) validateImportedConfig(imported *models.ProcessingConfiguration) []string {
+	algorithms := imported.GetAvailableAlgorithms()
+	slices.Sort(algorithms)
+
+	var problems []string
+	for _, algorithm := range algorithms {
+		params, err := imported.GetAlgorithmParameters(algorithm)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		if err := mc.processingService.ValidateAlgorithmParameters(algorithm, params.Parameters); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				problems = append(problems, fmt.Sprintf("%s: %s", algorithm, line))
+			}
+		}
+	}
+	return problems
+}
+
+// applyImportedConfig replaces the configuration with a validated import and
+// updates the view, reporting the changed parameters of the current
+// algorithm like a loaded preset
+func (mc *MainController) applyImportedConfig(imported *models.ProcessingConfiguration) {
+	previousAlgorithm := mc.configRepo.GetCurrentAlgorithm()
+	before, _ := mc.configRepo.GetAlgorithmParameters(imported.GetCurrentAlgorithm())
+
+	mc.configRepo.ApplyConfiguration(imported)
+
+	algorithm := mc.configRepo.GetCurrentAlgorithm()
+	if algorithm != previousAlgorithm {
+		mc.emitEvent("algorithm_changed", algorithm)
+	}
+
+	params, err := mc.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		mc.handleError("Configuration import failed", err)
+		return
+	}
+
+	changed := make(map[string]interface{})
+	for key, value := range params.Parameters {
+		if previous, ok := before.Parameters[key]; !ok || previous != value {
+			changed[key] = value
+		}
+	}
+
+	mc.mainView.ApplyPreset(algorithm, params.Parameters, changed)
+}
+
+// LoadWeightMap asks for a grayscale image weighting each pixel's
+// contribution to the 2D Otsu histogram
+func (mc *MainController) LoadWeightMap() {
+	if mc.mainView == nil {
+		return
+	}
+
+	mc.mainView.ShowFileDialog(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			mc.handleError("File selection error", err)
+			return
+		}
+		if reader == nil {
+			return
+		}
+
+		path := reader.URI().Path()
+		go func() {
+			if err := mc.imageService.LoadWeightMap(reader); err != nil {
+				mc.handleError("Weight map load failed", err)
+				return
+			}
+
+			algorithm := mc.configRepo.GetCurrentAlgorithm()
+			if err := mc.configRepo.SetAlgorithmParameter(algorithm, "weight_map_path", path); err != nil {
+				mc.handleError("Weight map load failed", err)
+				return
+			}
+
+			if mc.mainView != nil {
+				mc.mainView.UpdateAlgorithmParameters(algorithm, map[string]interface{}{"weight_map_path": path})
+				mc.mainView.UpdateStatus("Weight map loaded")
+			}
+			mc.schedulePreview()
+		}()
+	})
+}
+
+// ToggleErrorMap shows or hides the ground truth error map overlay
+func (mc *MainController) ToggleErrorMap(enabled bool) {
+	mc.mu.Lock()
+	mc.errorMapEnabled = enabled
+	mc.mu.Unlock()
+
+	go mc.refreshErrorMap()
+}
+
+// refreshErrorMap recomputes the overlay for the latest result
+func (mc *MainController) refreshErrorMap() {
+	mc.mu.RLock()
+	enabled := mc.errorMapEnabled
+	mc.mu.RUnlock()
+
+	if !enabled {
+		if mc.mainView != nil {
+			mc.mainView.SetErrorMap(nil)
+		}
+		return
+	}
+
+	latest := mc.processingService.GetLatestResult()
+	if latest == nil || latest.ProcessedImage == nil {
+		return
+	}
+
+	errorMap, err := mc.processingService.ComputeErrorMap(latest.ProcessedImage)
+	if mc.mainView == nil {
+		return
+	}
+	if err != nil {
+		mc.mainView.SetErrorMap(nil)
+		mc.mainView.UpdateStatus(fmt.Sprintf("Error map unavailable: %v", err))
+		return
+	}
+	mc.mainView.SetErrorMap(errorMap)
+}
+
+// SetROI restricts processing to a region of the original image, nil clears it.
+// The region is kept across parameter changes until a new image is loaded.
+func (mc *MainController) SetROI(roi *image.Rectangle) {
+	mc.imageRepo.SetCurrentROI(roi)
+
+	if mc.mainView != nil {
+		mc.mainView.SetROI(roi)
+		if roi == nil {
+			mc.mainView.UpdateStatus("ROI cleared")
+		} else {
+			mc.mainView.UpdateStatus(fmt.Sprintf("ROI: (%d, %d) %dx%d",
+				roi.Min.X, roi.Min.Y, roi.Dx(), roi.Dy()))
+		}
+	}
+
+	mc.schedulePreview()
+}
+
+// RotateImage rotates the loaded image clockwise by 90, 180 or 270 degrees
+func (mc *MainController) RotateImage(degrees int) {
+	mc.reorientImage(fmt.Sprintf("Rotated %d°", degrees), func() (*models.ImageData, error) {
+		return mc.imageService.RotateOriginal(degrees)
+	})
+}
+
+// FlipImage mirrors the loaded image horizontally or vertically
+func (mc *MainController) FlipImage(horizontal bool) {
+	status := "Flipped vertically"
+	if horizontal {
+		status = "Flipped horizontally"
+	}
+	mc.reorientImage(status, func() (*models.ImageData, error) {
+		return mc.imageService.FlipOriginal(horizontal)
+	})
+}
+
+// reorientImage replaces the original image with a rotated or flipped copy.
+// Earlier results no longer line up with it, so they are dropped along with
+// the region of interest and annotations.
+func (mc *MainController) reorientImage(status string, transform func() (*models.ImageData, error)) {
+	if mc.processingService.IsProcessing() {
+		mc.mainView.ShowInfo("Image Orientation", "Wait for processing to finish before rotating or flipping the image.")
+		return
+	}
+	mc.cancelPreview()
+
+	imageData, err := transform()
+	if err != nil {
+		mc.handleError("Orientation change failed", err)
+		return
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.SetOriginalImage(imageData.Image)
+		mc.mainView.SetProcessedImage(nil)
+		mc.mainView.SetROI(nil)
+		mc.mainView.SetAnnotations(nil)
+		mc.mainView.UpdateStatus(status)
+	}
+	mc.setShownProcessed(nil)
+
+	mc.schedulePreview()
+}
+
+// AddAnnotation stores an annotation drawn on the original image
+func (mc *MainController) AddAnnotation(annotation models.Annotation) {
+	mc.imageRepo.AddAnnotation(annotation)
+	mc.mainView.UpdateStatus(fmt.Sprintf("%d annotations", len(mc.imageRepo.GetAnnotations())))
+}
+
+// ClearAnnotations removes every annotation from the original image
+func (mc *MainController) ClearAnnotations() {
+	mc.imageRepo.ClearAnnotations()
+	mc.mainView.SetAnnotations(nil)
+	mc.mainView.UpdateStatus("Annotations cleared")
+}
+
+// ExportAnnotations writes the annotations to a GeoJSON file chosen by the user
+func (mc *MainController) ExportAnnotations() {
+	annotations := mc.imageRepo.GetAnnotations()
+	if len(annotations) == 0 {
+		mc.mainView.ShowInfo("Export Annotations", "Draw annotations on the original image before exporting.")
+		return
+	}
+
+	data, err := models.MarshalAnnotationsGeoJSON(annotations)
+	if err != nil {
+		mc.handleError("Annotation export failed", err)
+		return
+	}
+
+	mc.mainView.ShowExportDialog("annotations.json", []string{".json"}, func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			mc.handleError("File save error", err)
+			return
+		}
+		if writer == nil {
+			return
+		}
+		defer writer.Close()
+
+		if _, err := writer.Write(data); err != nil {
+			mc.handleError("Annotation export failed", err)
+			return
+		}
+		mc.mainView.UpdateStatus("Annotations exported")
+	})
+}
+
+// SetCurrentPage switches the original image to another page of the loaded file
+func (mc *MainController) SetCurrentPage(n int) {
+	imageData, err := mc.activatePage(n)
+	if err != nil {
+		mc.handleError("Page change failed", err)
+		return
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.SetOriginalImage(imageData.Image)
+		mc.mainView.SetProcessedImage(nil)
+		mc.mainView.UpdateStatus(fmt.Sprintf("Showing page %d", n+1))
+	}
+
+	mc.emitEvent("image_loaded", imageData)
+}
+
+// activatePage stores a copy of the cached page as the original image. The
+// repository closes the Mat it replaces, so the cached page keeps its own.
+func (mc *MainController) activatePage(n int) (*models.ImageData, error) {
+	mc.mu.Lock()
+	if n < 0 || n >= len(mc.pages) {
+		mc.mu.Unlock()
+		return nil, fmt.Errorf("page %d out of range", n+1)
+	}
+	page := mc.pages[n]
+	total := len(mc.pages)
+	mc.currentPage = n
+	mc.mu.Unlock()
+
+	mat, err := page.Mat.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy page: %w", err)
+	}
+
+	imageData := *page
+	imageData.Mat = mat
+	mc.imageRepo.SetOriginalImage(&imageData)
+
+	if mc.mainView != nil {
+		mc.mainView.SetPageInfo(n, total)
+	}
+
+	return &imageData, nil
+}
+
+// releasePages closes cached pages from a previous multi-page file
+func (mc *MainController) releasePages() {
+	mc.mu.Lock()
+	pages := mc.pages
+	mc.pages = nil
+	mc.currentPage = 0
+	mc.mu.Unlock()
+
+	for _, page := range pages {
+		if page.Mat != nil {
+			page.Mat.Close()
+		}
+	}
+
+	if len(pages) > 0 {
+		if mc.mainView != nil {
+			mc.mainView.SetPageInfo(0, 0)
+		}
+	}
+}
+
+// LoadStack asks for a folder holding a numbered TIFF sequence and segments
+// it as one volume with 3D Otsu
+func (mc *MainController) LoadStack() {
+	mc.mainView.ShowFolderDialog(func(dir fyne.ListableURI, err error) {
+		if err != nil {
+			mc.handleError("Folder selection error", err)
+			return
+		}
+		if dir == nil {
+			return
+		}
+
+		go mc.loadStack(dir)
+	})
+}
+
+// loadStack loads the slices in dir, shows the first one and runs 3D Otsu
+// over the whole stack
+func (mc *MainController) loadStack(dir fyne.URI) {
+	mc.mainView.UpdateStatus("Loading image stack...")
+
+	slices, err := mc.imageService.LoadStack(dir)
+	if err != nil {
+		mc.handleError("Stack load failed", err)
+		return
+	}
+
+	mc.releasePages()
+	mc.releaseStack()
+
+	mc.mu.Lock()
+	mc.stack = slices
+	mc.mu.Unlock()
+
+	// Open the stack on its best focused slice
+	focused, scores := mc.findFocusedSlice(slices)
+	mc.mainView.SetStackSharpness(scores)
+
+	mc.SetStackSlice(focused)
+	if !mc.tryBeginRun() {
+		mc.showRunActive("3D Segmentation")
+		return
+	}
+	defer mc.endRun()
+	mc.segmentStack(slices)
+}
+
+// findFocusedSlice returns the sharpest of slices with the score of each, or
+// the first slice and no scores when they cannot be scored
+func (mc *MainController) findFocusedSlice(slices []*models.ImageData) (int, []float64) {
+	mats := make([]*safe.Mat, len(slices))
+	for i, slice := range slices {
+		mats[i] = slice.Mat
+	}
+
+	focused, scores, err := focus.NewSharpnessAnalyser().FindFocusedSlice(mats)
+	if err != nil {
+		if mc.logger != nil {
+			mc.logger.Warning("Sharpness analysis failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+		return 0, nil
+	}
+	return focused, scores
+}
+
+// segmentStack runs 3D Otsu over slices and keeps the result of each slice
+// for the stack navigator
+func (mc *MainController) segmentStack(slices []*models.ImageData) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mc.mu.Lock()
+	mc.processingCancelFunc = cancel
+	mc.mu.Unlock()
+
+	mc.mainView.SetProcessingActive(true)
+	mc.mainView.UpdateStatus(fmt.Sprintf("Segmenting %d slices with 3D Otsu...", len(slices)))
+
+	defer func() {
+		mc.mu.Lock()
+		mc.processingCancelFunc = nil
+		mc.mu.Unlock()
+
+		mc.mainView.SetProcessingActive(false)
+	}()
+
+	mats := make([]*safe.Mat, len(slices))
+	for i, slice := range slices {
+		mats[i] = slice.Mat
+	}
+
+	processor := otsu3d.NewProcessor()
+	statsCtx, stats := processing.WithRunStatistics(ctx)
+	results, err := processor.ProcessStack(statsCtx, mats, processor.GetDefaultParameters())
+	if err != nil {
+		mc.handleError("3D Otsu failed", err)
+		return
+	}
+
+	images := make([]image.Image, len(results))
+	for i, result := range results {
+		images[i], err = conversion.MatToImage(result)
+		result.Close()
+		if err != nil {
+			for _, remaining := range results[i+1:] {
+				remaining.Close()
+			}
+			mc.handleError("3D Otsu failed", fmt.Errorf("failed to convert slice %d: %w", i+1, err))
+			return
+		}
+	}
+
+	mc.mu.Lock()
+	// A newer stack or image replaced this one while it was processing
+	if len(mc.stack) == 0 || mc.stack[0] != slices[0] {
+		mc.mu.Unlock()
+		return
+	}
+	mc.stackResults = images
+	current := mc.stackSlice
+	mc.mu.Unlock()
+
+	mc.SetStackSlice(current)
+
+	thresholds, _ := stats.Values()["optimal_threshold"].([2]int)
+	mc.mainView.UpdateStatus(fmt.Sprintf("3D Otsu: %d slices, thresholds %d (spatial) / %d (axial)", len(slices), thresholds[0], thresholds[1]))
+}
+
+// SetStackSlice shows slice n of the loaded stack and its 3D Otsu result.
+// Like a page, the slice is copied into the repository so the 2D algorithms
+// can process it on its own.
+func (mc *MainController) SetStackSlice(n int) {
+	mc.mu.Lock()
+	if n < 0 || n >= len(mc.stack) {
+		mc.mu.Unlock()
+		return
+	}
+	slice := mc.stack[n]
+	total := len(mc.stack)
+	var result image.Image
+	if n < len(mc.stackResults) {
+		result = mc.stackResults[n]
+	}
+	mc.stackSlice = n
+	mc.mu.Unlock()
+
+	mat, err := slice.Mat.Clone()
+	if err != nil {
+		mc.handleError("Slice change failed", err)
+		return
+	}
+
+	imageData := *slice
+	imageData.Mat = mat
+	mc.imageRepo.SetOriginalImage(&imageData)
+
+	if mc.mainView != nil {
+		mc.mainView.SetOriginalImage(imageData.Image)
+		mc.mainView.SetProcessedImage(result)
+		mc.mainView.SetStackInfo(n, total)
+	}
+
+	mc.emitEvent("image_loaded", &imageData)
+}
+
+// releaseStack closes the slices of a previously loaded stack
+func (mc *MainController) releaseStack() {
+	mc.mu.Lock()
+	stack := mc.stack
+	mc.stack = nil
+	mc.stackResults = nil
+	mc.stackSlice = 0
+	mc.mu.Unlock()
+
+	for _, slice := range stack {
+		if slice.Mat != nil {
+			slice.Mat.Close()
+		}
+	}
+
+	if len(stack) > 0 {
+		if mc.mainView != nil {
+			mc.mainView.SetStackInfo(0, 0)
+		}
+	}
+}
+
+// saveImageToWriter saves an image to a file writer
+func (mc *MainController) saveImageToWriter(writer fyne.URIWriteCloser, imageData *models.ImageData) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if mc.mainView != nil {
+		mc.mainView.UpdateStatus("Saving image...")
+	}
+
+	if quality, ok := mc.configRepo.GetGlobalSetting("webp_quality"); ok {
+		if q, ok := quality.(int); ok {
+			mc.imageService.SetWebPQuality(q)
+		}
+	}
+	if quality, ok := mc.configRepo.GetGlobalSetting("jpeg_quality"); ok {
+		if q, ok := quality.(int); ok {
+			mc.imageService.SetJPEGQuality(q)
+		}
+	}
+
+	if burn, ok := mc.configRepo.GetGlobalSetting("burn_annotations"); ok && burn == true {
+		if annotations := mc.imageRepo.GetAnnotations(); len(annotations) > 0 {
+			annotated, err := services.BurnAnnotations(imageData, annotations)
+			if err != nil {
+				mc.handleError("Image save failed", err)
+				return
+			}
+			defer annotated.Mat.Close()
+			imageData = annotated
+		}
+	}
+
+	err := mc.imageService.SaveImage(ctx, writer, imageData, "")
+	if err != nil {
+		mc.handleError("Image save failed", err)
+	}
+
+	if mc.mainView != nil {
+		if err != nil {
+			mc.mainView.UpdateStatus("Save failed")
+		} else {
+			mc.mainView.UpdateStatus("Image saved")
+		}
+	}
+
+	if err == nil {
+		// Emit image saved event
+		mc.emitEvent("image_saved", imageData)
+	}
+}
+
+// Event system methods
+
+// initializeEventHandlers sets up default event handlers
+func (mc *MainController) initializeEventHandlers() {
+	mc.addEventListener("image_loaded", mc.onImageLoaded)
+	mc.addEventListener("processing_complete", mc.onProcessingComplete)
+	mc.addEventListener("algorithm_changed", mc.onAlgorithmChanged)
+	mc.addEventListener(PostProcessHook, mc.onPostProcessHook)
+}
+
+// setupViewEventHandlers connects view events to controller methods
+func (mc *MainController) setupViewEventHandlers() {
+	if mc.mainView == nil {
+		return
+	}
+
+	// Connect view callbacks to controller methods
+	mc.mainView.SetLoadImageHandler(mc.LoadImage)
+	mc.mainView.SetCancelLoadHandler(mc.CancelLoad)
+	mc.mainView.SetDropHandler(mc.LoadDroppedFiles)
+	mc.mainView.SetSaveImageHandler(mc.SaveImage)
+	mc.mainView.SetProcessImageHandler(mc.ProcessImage)
+	mc.mainView.SetCancelProcessingHandler(mc.CancelProcessing)
+	mc.mainView.SetClearQueueHandler(mc.ClearQueue)
+	mc.mainView.SetAlgorithmChangeHandler(mc.ChangeAlgorithm)
+	mc.mainView.SetParameterChangeHandler(mc.UpdateParameter)
+	mc.mainView.SetBatchProcessHandler(mc.BatchProcess)
+	mc.mainView.SetUndoHandler(mc.Undo)
+	mc.mainView.SetRedoHandler(mc.Redo)
+	mc.mainView.SetPreviewToggleHandler(mc.SetPreviewEnabled)
+	mc.mainView.SetPageChangeHandler(mc.SetCurrentPage)
+	mc.mainView.SetGroundTruthHandler(mc.LoadGroundTruth)
+	mc.mainView.SetWeightMapHandler(mc.LoadWeightMap)
+	mc.mainView.SetPresetHandlers(mc.LoadPreset, mc.SavePreset)
+	mc.refreshPresets()
+	mc.mainView.SetErrorMapHandler(mc.ToggleErrorMap)
+	mc.mainView.SetROIHandler(mc.SetROI)
+	mc.mainView.SetOrientationHandlers(mc.RotateImage, mc.FlipImage)
+	mc.mainView.SetAnnotationHandlers(mc.AddAnnotation, mc.ClearAnnotations, mc.ExportAnnotations)
+	mc.mainView.SetFullResolutionHandler(mc.SetFullResolution)
+	mc.mainView.SetThresholdHandler(mc.ApplyThreshold)
+	mc.mainView.SetFullResolution(mc.configRepo.GetPerformanceSettings().FullResolution)
+	mc.mainView.SetExportReportHandler(mc.ExportReport)
+	mc.mainView.SetExportMacroHandler(mc.ExportImageJMacro)
+	mc.mainView.SetExportConfigHandler(mc.ExportConfig)
+	mc.mainView.SetImportConfigHandler(mc.ImportConfig)
+	mc.mainView.SetAutoSaveFolderHandler(mc.ChooseAutoSaveFolder)
+	mc.mainView.SetLoadStackHandler(mc.LoadStack)
+	mc.mainView.SetAutoTuneHandler(mc.AutoTune)
+	mc.mainView.SetSensitivityAnalysisHandler(mc.AnalyseSensitivity)
+	mc.mainView.SetStackSliceHandler(mc.SetStackSlice)
+	mc.mainView.SetHistorySelectHandler(mc.RestoreHistoryEntry)
+	mc.mainView.SetCompareModeHandler(mc.SetCompareMode)
+	mc.mainView.SetStatisticsHandler(mc.SetStatisticsSource)
+	mc.mainView.SetPreferencesHandlers(mc.configRepo.GetGlobalSettings, mc.UpdateGlobalSetting, mc.ResetGlobalSettings)
+	mc.mainView.SetPreviewEnabled(mc.PreviewEnabled())
+}
+
+// addEventListener adds an event handler for a specific event type. Each
+// handler receives its events one at a time, in the order they were emitted.
+func (mc *MainController) addEventListener(eventType string, handler EventHandler) {
+	if _, err := mc.eventBus.Subscribe(eventType, handler, eventBufferSize); err != nil {
+		mc.handleError(fmt.Sprintf("Event subscription failed (%s)", eventType), err)
+	}
+}
+
+// emitEvent queues an event for every handler of its type. A dropped event
+// is logged and its error returned.
+func (mc *MainController) emitEvent(eventType string, data interface{}) error {
+	err := mc.eventBus.Publish(eventType, data)
+	if err != nil && mc.logger != nil {
+		mc.logger.Warning("Event dropped", map[string]interface{}{
+			"event": eventType,
+			"error": err.Error(),
+		})
+	}
+	return err
 }
 
 // Event handlers
@@ -470,15 +2155,224 @@ func (mc *MainController) onImageLoaded(data interface{}) error {
 		return fmt.Errorf("invalid data type for image_loaded event")
 	}
 
+	// The repository drops the region of interest with the previous image
+	if mc.mainView != nil {
+		mc.mainView.SetROI(nil)
+		mc.mainView.SetAnnotations(nil)
+	}
+
 	// Update memory optimization based on image size
 	imageSize := int64(imageData.Width * imageData.Height * imageData.Channels)
 	if imageSize > 10*1024*1024 { // 10MB
 		mc.processingService.OptimizeMemoryUsage()
 	}
 
+	if mc.mainView != nil {
+		mc.mainView.SetImageDetails(imageData)
+	}
+	mc.setShownProcessed(nil)
+
+	mc.applyFilenameParameters(imageData)
+	mc.suggestPreprocessing(imageData)
+	mc.recommendAlgorithm(imageData)
+
 	return nil
 }
 
+// SetStatisticsSource records whether the metadata sidebar is shown and
+// whether its statistics describe the processed image, then updates them
+func (mc *MainController) SetStatisticsSource(visible, processed bool) {
+	mc.mu.Lock()
+	mc.statsVisible = visible
+	mc.statsProcessed = processed
+	mc.mu.Unlock()
+
+	mc.refreshImageStatistics()
+}
+
+// setShownProcessed records the processed image on display, nil when none
+// is, and updates the metadata sidebar statistics
+func (mc *MainController) setShownProcessed(imageData *models.ImageData) {
+	mc.mu.Lock()
+	mc.shownProcessed = imageData
+	mc.mu.Unlock()
+
+	mc.refreshImageStatistics()
+}
+
+// refreshImageStatistics computes the statistics of the original or
+// processed image in the background while the metadata sidebar is shown.
+// Statistics of an image replaced in the meantime are dropped.
+func (mc *MainController) refreshImageStatistics() {
+	seq := mc.statsSeq.Add(1)
+
+	mc.mu.RLock()
+	visible, processed, imageData := mc.statsVisible, mc.statsProcessed, mc.shownProcessed
+	mc.mu.RUnlock()
+
+	if !visible || mc.mainView == nil {
+		return
+	}
+	if !processed {
+		imageData = mc.imageRepo.GetOriginalImage()
+	}
+	if imageData == nil {
+		mc.mainView.SetImageStatistics(nil)
+		return
+	}
+
+	go func() {
+		stats, err := computeImageStatistics(imageData)
+		if mc.statsSeq.Load() != seq {
+			return
+		}
+		if err != nil {
+			if mc.logger != nil {
+				mc.logger.Debug("Image statistics failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			mc.mainView.SetImageStatistics(nil)
+			return
+		}
+		mc.mainView.SetImageStatistics(&stats)
+	}()
+}
+
+// computeImageStatistics measures imageData at its full depth. The Mat of a
+// 16-bit image is a scaled 8-bit copy, so those are measured from the image.
+func computeImageStatistics(imageData *models.ImageData) (models.ImageStatistics, error) {
+	if imageData.Mat != nil && imageData.Metadata.BitDepth <= 8 {
+		return analysis.ComputeImageStatistics(imageData.Mat)
+	}
+
+	mat, err := conversion.ImageToMat(imageData.Image)
+	if err != nil {
+		return models.ImageStatistics{}, err
+	}
+	defer mat.Close()
+
+	return analysis.ComputeImageStatistics(mat)
+}
+
+// filenameToastDuration is how long the filename parameter notice stays up
+const filenameToastDuration = 4 * time.Second
+
+// applyFilenameParameters sets parameters of the current algorithm encoded in
+// the loaded file's name, such as the window size in sample_w7.tif, unless
+// turned off in preferences. Values the algorithm rejects are skipped.
+func (mc *MainController) applyFilenameParameters(imageData *models.ImageData) {
+	if enabled, ok := mc.configRepo.GetGlobalSetting("filename_parameters"); ok {
+		if apply, isBool := enabled.(bool); isBool && !apply {
+			return
+		}
+	}
+	if imageData.OriginalURI == nil || mc.mainView == nil {
+		return
+	}
+
+	parser, err := services.NewFilenameParameterParser()
+	if err != nil {
+		return
+	}
+	parsed := parser.Parse(imageData.OriginalURI.Name())
+	if len(parsed) == 0 {
+		return
+	}
+
+	algorithm := mc.configRepo.GetCurrentAlgorithm()
+	params, err := mc.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		return
+	}
+
+	names := make([]string, 0, len(parsed))
+	for name := range parsed {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	applied := make(map[string]interface{})
+	for _, name := range names {
+		if _, known := params.Parameters[name]; !known {
+			continue
+		}
+
+		candidate := maps.Clone(params.Parameters)
+		candidate[name] = parsed[name]
+		if err := mc.processingService.ValidateAlgorithmParameters(algorithm, candidate); err != nil {
+			if mc.logger != nil {
+				mc.logger.Debug("Filename parameter rejected", map[string]interface{}{
+					"parameter": name,
+					"value":     parsed[name],
+					"error":     err.Error(),
+				})
+			}
+			continue
+		}
+		params.Parameters[name] = parsed[name]
+		applied[name] = parsed[name]
+	}
+	if len(applied) == 0 {
+		return
+	}
+
+	// The panel reports each value through UpdateParameter
+	mc.mainView.ApplyPreset(algorithm, params.Parameters, applied)
+	mc.mainView.ShowToast("Parameters loaded from filename", filenameToastDuration)
+}
+
+// recommendAlgorithm counts the modes of a loaded image's histogram and
+// recommends the algorithm suited to them unless recommendations are turned
+// off in preferences
+func (mc *MainController) recommendAlgorithm(imageData *models.ImageData) {
+	if enabled, ok := mc.configRepo.GetGlobalSetting("show_recommendations"); ok {
+		if show, isBool := enabled.(bool); isBool && !show {
+			return
+		}
+	}
+
+	result, err := modality.NewHistogramAnalyser().DetectModality(imageData.Mat)
+	if err != nil {
+		return
+	}
+
+	if mc.logger != nil {
+		mc.logger.Debug("Histogram modality detected", map[string]interface{}{
+			"peaks":       result.Peaks,
+			"peak_levels": result.PeakLevels,
+			"recommended": result.Recommended,
+			"mean":        result.Mean,
+			"std_dev":     result.StdDev,
+			"min":         result.Min,
+			"max":         result.Max,
+		})
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.ShowInfo("Algorithm Recommendation", result.Recommendation())
+	}
+}
+
+// suggestPreprocessing classifies the noise in a loaded image and recommends
+// matching preprocessing unless recommendations are turned off in preferences
+func (mc *MainController) suggestPreprocessing(imageData *models.ImageData) {
+	if enabled, ok := mc.configRepo.GetGlobalSetting("show_recommendations"); ok {
+		if show, isBool := enabled.(bool); isBool && !show {
+			return
+		}
+	}
+
+	profile, err := noise.NewNoiseClassifier().Classify(imageData.Mat)
+	if err != nil {
+		return
+	}
+
+	if suggestion := profile.Suggestion(); suggestion != "" && mc.mainView != nil {
+		mc.mainView.ShowInfo("Preprocessing Suggestion", suggestion)
+	}
+}
+
 // onProcessingComplete handles processing completion events
 func (mc *MainController) onProcessingComplete(data interface{}) error {
 	result, ok := data.(*models.ProcessingResult)
@@ -486,6 +2380,30 @@ func (mc *MainController) onProcessingComplete(data interface{}) error {
 		return fmt.Errorf("invalid data type for processing_complete event")
 	}
 
+	mc.imageRepo.StoreProcessedImage(result)
+
+	// Plot the convergence of iterative algorithms and the 2D Otsu joint
+	// histogram; results without them clear the plots
+	if mc.mainView != nil {
+		history, _ := result.Statistics["convergence_history"].([]convergence.ConvergenceRecord)
+		mc.mainView.SetConvergenceData(history)
+
+		histogram, _ := result.Statistics["histogram"].([][]float64)
+		optimal, _ := result.Statistics["optimal_threshold"].([2]float64)
+		mc.mainView.SetJointHistogram(histogram, optimal)
+	}
+
+	mc.refreshHistory()
+
+	if mc.metricsExporter != nil && result.Metrics != nil {
+		tags := map[string]string{"algorithm": result.Algorithm}
+		if err := mc.metricsExporter.Export(result.Metrics, tags); err != nil && mc.logger != nil {
+			mc.logger.Warning("Metrics export failed", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	// Perform post-processing cleanup
 	mc.processingService.OptimizeMemoryUsage()
 
@@ -508,8 +2426,7 @@ func (mc *MainController) onAlgorithmChanged(data interface{}) error {
 		return fmt.Errorf("invalid data type for algorithm_changed event")
 	}
 
-	// Clear processed images when algorithm changes
-	mc.imageRepo.ClearProcessedImages()
+	// Runs of the previous algorithm stay in the history for comparison
 
 	// Log algorithm change (in real implementation, use proper logger)
 	_ = algorithm // Suppress unused variable warning
@@ -522,17 +2439,20 @@ func (mc *MainController) handleError(title string, err error) {
 	// In a real implementation, this would use proper logging
 	// and potentially show user-friendly error dialogs
 	
-	fyne.Do(func() {
-		if mc.mainView != nil {
-			mc.mainView.ShowError(title, err)
-		}
-	})
+	if mc.mainView != nil {
+		mc.mainView.ShowError(title, err)
+	}
 }
 
 // Shutdown performs cleanup when the application closes
 func (mc *MainController) Shutdown() {
 	// Cancel any ongoing processing
 	mc.CancelProcessing()
+	mc.cancelPreview()
+	mc.releasePages()
+	mc.releaseStack()
+	mc.releaseComparison()
+	mc.eventBus.Close()
 
 	// Clean up services
 	mc.imageService.Cleanup()
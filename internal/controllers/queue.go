@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+)
+
+// ProcessingRequest is a processing run, waiting in the queue while another
+// one is active
+type ProcessingRequest struct {
+	Algorithm string
+	// Parameters are captured when the run is requested, so later edits
+	// only affect runs requested after them
+	Parameters map[string]interface{}
+	// Status is shown when the run starts on its own rather than from the queue
+	Status string
+	// Compare runs both compare mode algorithms with their configured
+	// parameters instead of Algorithm
+	Compare bool
+}
+
+// newProcessingRequest snapshots the current parameters of algorithm and
+// applies overrides on top
+func (mc *MainController) newProcessingRequest(algorithm, status string, overrides map[string]interface{}) ProcessingRequest {
+	parameters := make(map[string]interface{})
+	if current, err := mc.configRepo.GetAlgorithmParameters(algorithm); err == nil {
+		for key, value := range current.Parameters {
+			parameters[key] = value
+		}
+	}
+	for key, value := range overrides {
+		parameters[key] = value
+	}
+
+	return ProcessingRequest{
+		Algorithm:  algorithm,
+		Parameters: parameters,
+		Status:     status,
+	}
+}
+
+// submitProcessing starts request, or queues it behind the active run,
+// whether that is a processing run, a batch or an analysis. A full queue
+// rejects the request.
+func (mc *MainController) submitProcessing(request ProcessingRequest) {
+	mc.queueMu.Lock()
+	if !mc.runActive {
+		mc.runActive = true
+		mc.queueStarted, mc.queueTotal = 0, 1
+		mc.queueMu.Unlock()
+		mc.startProcessing(request)
+		return
+	}
+
+	select {
+	case mc.queue <- request:
+		mc.queueTotal++
+	default:
+		mc.queueMu.Unlock()
+		if mc.mainView != nil {
+			mc.mainView.UpdateStatus("Processing queue is full")
+		}
+		return
+	}
+	started, total, pending := mc.queueStarted, mc.queueTotal, len(mc.queue)
+	mc.queueMu.Unlock()
+
+	if mc.mainView != nil {
+		mc.mainView.SetQueueLength(pending)
+		mc.mainView.UpdateStatus(fmt.Sprintf("Processing %d of %d", started, total))
+	}
+}
+
+// startProcessing runs request in the background and then moves on to the
+// next queued request
+func (mc *MainController) startProcessing(request ProcessingRequest) {
+	mc.queueMu.Lock()
+	mc.queueStarted++
+	started, total, pending := mc.queueStarted, mc.queueTotal, len(mc.queue)
+	mc.queueMu.Unlock()
+
+	status := request.Status
+	if total > 1 {
+		status = fmt.Sprintf("Processing %d of %d", started, total)
+	}
+
+	if mc.mainView != nil {
+		mc.mainView.SetProcessingActive(true)
+		mc.mainView.SetQueueLength(pending)
+		mc.mainView.UpdateStatus(status)
+	}
+
+	go func() {
+		if request.Compare {
+			mc.performCompareProcessing()
+		} else {
+			mc.performImageProcessing(request.Algorithm, request.Parameters)
+		}
+		mc.processNextQueued()
+	}()
+}
+
+// processNextQueued starts the oldest queued request, if any, or releases
+// the run gate
+func (mc *MainController) processNextQueued() {
+	mc.queueMu.Lock()
+	select {
+	case next := <-mc.queue:
+		mc.queueMu.Unlock()
+		mc.startProcessing(next)
+	default:
+		mc.runActive = false
+		mc.queueMu.Unlock()
+	}
+}
+
+// tryBeginRun takes the run gate for a job started outside the queue, such
+// as a batch or an analysis. It returns false while another run is active;
+// otherwise the caller must call endRun when the job finishes.
+func (mc *MainController) tryBeginRun() bool {
+	mc.queueMu.Lock()
+	defer mc.queueMu.Unlock()
+
+	if mc.runActive {
+		return false
+	}
+	mc.runActive = true
+	mc.queueStarted, mc.queueTotal = 0, 0
+	return true
+}
+
+// endRun releases the run gate taken by tryBeginRun, starting any
+// processing queued in the meantime
+func (mc *MainController) endRun() {
+	mc.processNextQueued()
+}
+
+// isRunActive reports whether a run holds the run gate
+func (mc *MainController) isRunActive() bool {
+	mc.queueMu.Lock()
+	defer mc.queueMu.Unlock()
+	return mc.runActive
+}
+
+// showRunActive tells the user that title must wait for the active run
+func (mc *MainController) showRunActive(title string) {
+	if mc.mainView != nil {
+		mc.mainView.ShowInfo(title, "Wait for processing to finish, or cancel it, before starting this.")
+	}
+}
+
+// ClearQueue drops all queued requests; the active run continues
+func (mc *MainController) ClearQueue() {
+	cleared := mc.drainQueue()
+
+	if mc.mainView == nil {
+		return
+	}
+	mc.mainView.SetQueueLength(0)
+	if cleared > 0 {
+		mc.mainView.UpdateStatus(fmt.Sprintf("Cleared %d queued runs", cleared))
+	}
+}
+
+// drainQueue empties the queue and returns how many requests it held
+func (mc *MainController) drainQueue() int {
+	mc.queueMu.Lock()
+	defer mc.queueMu.Unlock()
+
+	cleared := 0
+	for {
+		select {
+		case <-mc.queue:
+			cleared++
+		default:
+			mc.queueTotal -= cleared
+			return cleared
+		}
+	}
+}
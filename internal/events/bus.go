@@ -0,0 +1,207 @@
+// Package events delivers application events to subscribers in the order
+// they were published.
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrBusClosed is returned by Subscribe and Publish after Close
+var ErrBusClosed = errors.New("event bus is closed")
+
+// ErrQueueFull is returned by Publish when the event was dropped for a
+// subscriber whose queue was full
+var ErrQueueFull = errors.New("event queue full")
+
+// Event is a published value and the topic it was published on
+type Event struct {
+	Topic string
+	Data  interface{}
+}
+
+// EventHandler handles the data of one event
+type EventHandler func(data interface{}) error
+
+// ErrorHandler receives the errors returned by event handlers
+type ErrorHandler func(topic string, err error)
+
+// SubscriptionID identifies a subscription for Unsubscribe
+type SubscriptionID uint64
+
+// subscription queues a topic's events for one handler. Its goroutine runs
+// the handler on one event at a time, so a handler never sees two events
+// of its topic concurrently or out of order.
+type subscription struct {
+	topic   string
+	handler EventHandler
+	queue   chan Event
+	done    chan struct{}
+}
+
+// EventBus dispatches published events to the handlers subscribed to their
+// topic. Publish never waits for a handler: it queues the event for every
+// subscriber with room and drops it for the others, so a handler that
+// publishes can never deadlock the bus. The handlers run on the subscribers'
+// goroutines.
+type EventBus struct {
+	mu            sync.RWMutex
+	subscriptions map[SubscriptionID]*subscription
+	topics        map[string][]SubscriptionID
+	nextID        SubscriptionID
+	closed        bool
+	onError       ErrorHandler
+	dropped       atomic.Uint64
+}
+
+// NewEventBus creates an event bus reporting handler errors to onError,
+// which may be nil
+func NewEventBus(onError ErrorHandler) *EventBus {
+	return &EventBus{
+		subscriptions: make(map[SubscriptionID]*subscription),
+		topics:        make(map[string][]SubscriptionID),
+		onError:       onError,
+	}
+}
+
+// Subscribe runs handler for every event later published on topic. Up to
+// bufferSize events wait for the handler; further events are dropped until
+// it catches up.
+func (b *EventBus) Subscribe(topic string, handler EventHandler, bufferSize int) (SubscriptionID, error) {
+	if handler == nil {
+		return 0, fmt.Errorf("nil handler for topic %q", topic)
+	}
+	if bufferSize < 0 {
+		return 0, fmt.Errorf("buffer size must not be negative, got %d", bufferSize)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, ErrBusClosed
+	}
+
+	b.nextID++
+	id := b.nextID
+	sub := &subscription{
+		topic:   topic,
+		handler: handler,
+		queue:   make(chan Event, bufferSize),
+		done:    make(chan struct{}),
+	}
+	b.subscriptions[id] = sub
+	b.topics[topic] = append(b.topics[topic], id)
+
+	go b.dispatch(sub)
+	return id, nil
+}
+
+// Unsubscribe stops the subscription. Events it has queued but not yet
+// handled are dropped; a handler already running finishes.
+func (b *EventBus) Unsubscribe(id SubscriptionID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub, exists := b.subscriptions[id]
+	if !exists {
+		return fmt.Errorf("unknown subscription %d", id)
+	}
+	b.removeLocked(id, sub)
+	return nil
+}
+
+// Publish queues data for every subscriber of topic without blocking. When a
+// subscriber's queue is full the event is dropped for it, counted in Dropped
+// and reported with an error wrapping ErrQueueFull.
+func (b *EventBus) Publish(topic string, data interface{}) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrBusClosed
+	}
+	ids := b.topics[topic]
+	subs := make([]*subscription, 0, len(ids))
+	for _, id := range ids {
+		subs = append(subs, b.subscriptions[id])
+	}
+	b.mu.RUnlock()
+
+	event := Event{Topic: topic, Data: data}
+	missed := 0
+	for _, sub := range subs {
+		select {
+		case sub.queue <- event:
+		case <-sub.done:
+		default:
+			missed++
+		}
+	}
+
+	if missed > 0 {
+		b.dropped.Add(uint64(missed))
+		return fmt.Errorf("%w: %d of %d subscribers of %q missed the event", ErrQueueFull, missed, len(subs), topic)
+	}
+	return nil
+}
+
+// Dropped returns how many deliveries were dropped on full queues
+func (b *EventBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// Close ends every subscription; later Subscribe and Publish calls fail
+// with ErrBusClosed
+func (b *EventBus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for id, sub := range b.subscriptions {
+		b.removeLocked(id, sub)
+	}
+}
+
+// removeLocked ends a subscription. Callers must hold the write lock.
+func (b *EventBus) removeLocked(id SubscriptionID, sub *subscription) {
+	delete(b.subscriptions, id)
+
+	ids := b.topics[sub.topic]
+	for i, topicID := range ids {
+		if topicID == id {
+			b.topics[sub.topic] = append(ids[:i:i], ids[i+1:]...)
+			break
+		}
+	}
+	if len(b.topics[sub.topic]) == 0 {
+		delete(b.topics, sub.topic)
+	}
+
+	close(sub.done)
+}
+
+// dispatch runs the subscription's handler on its queued events in order
+func (b *EventBus) dispatch(sub *subscription) {
+	for {
+		select {
+		case <-sub.done:
+			return
+		case event := <-sub.queue:
+			// A closed subscription drops what is left in its queue
+			select {
+			case <-sub.done:
+				return
+			default:
+			}
+
+			if err := sub.handler(event.Data); err != nil && b.onError != nil {
+				b.onError(event.Topic, err)
+			}
+		}
+	}
+}
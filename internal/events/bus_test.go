@@ -0,0 +1,112 @@
+package events
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPublishDropsWhenQueueFull(t *testing.T) {
+	bus := NewEventBus(nil)
+	defer bus.Close()
+
+	release := make(chan struct{})
+	var handled atomic.Int64
+	if _, err := bus.Subscribe("topic", func(interface{}) error {
+		<-release
+		handled.Add(1)
+		return nil
+	}, 2); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	const publishers = 10
+	const perPublisher = 100
+
+	var wg sync.WaitGroup
+	var rejected atomic.Int64
+	done := make(chan struct{})
+	for i := 0; i < publishers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perPublisher; j++ {
+				err := bus.Publish("topic", j)
+				if errors.Is(err, ErrQueueFull) {
+					rejected.Add(1)
+				} else if err != nil {
+					t.Errorf("Publish: %v", err)
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish blocked on a stalled handler")
+	}
+
+	if got := int64(bus.Dropped()); got != rejected.Load() {
+		t.Errorf("Dropped() = %d, want %d", got, rejected.Load())
+	}
+
+	close(release)
+	want := int64(publishers*perPublisher) - rejected.Load()
+	deadline := time.Now().Add(5 * time.Second)
+	for handled.Load() < want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if handled.Load() != want {
+		t.Errorf("handled %d events, want %d delivered plus %d dropped to make %d",
+			handled.Load(), want, rejected.Load(), publishers*perPublisher)
+	}
+}
+
+func TestHandlerPublishingToOwnTopicDoesNotDeadlock(t *testing.T) {
+	bus := NewEventBus(nil)
+	defer bus.Close()
+
+	finished := make(chan struct{})
+	var count atomic.Int64
+	if _, err := bus.Subscribe("topic", func(interface{}) error {
+		if count.Add(1) == 50 {
+			close(finished)
+			return nil
+		}
+		// Two publishes per event overflow the one-slot queue
+		bus.Publish("topic", nil)
+		bus.Publish("topic", nil)
+		return nil
+	}, 1); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	if err := bus.Publish("topic", nil); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler publishing to its own topic deadlocked")
+	}
+	if bus.Dropped() == 0 {
+		t.Error("expected events dropped on the full queue")
+	}
+}
+
+func TestPublishAfterClose(t *testing.T) {
+	bus := NewEventBus(nil)
+	bus.Close()
+
+	if err := bus.Publish("topic", nil); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("Publish after Close = %v, want ErrBusClosed", err)
+	}
+}
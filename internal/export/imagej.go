@@ -0,0 +1,443 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ImageJMacroExporter writes processing parameters as an ImageJ macro (.ijm)
+// that repeats the processing on the active image in ImageJ or Fiji, so
+// results can be reproduced where further analysis happens
+type ImageJMacroExporter struct{}
+
+func NewImageJMacroExporter() *ImageJMacroExporter {
+	return &ImageJMacroExporter{}
+}
+
+// Export returns the macro for algorithm run with params. Filters with no
+// ImageJ counterpart are written as comments.
+func (e *ImageJMacroExporter) Export(algorithm string, params map[string]interface{}) (string, error) {
+	m := &macroWriter{}
+	m.comment("ImageJ macro exported by Otsu Obliterator")
+	m.comment("Algorithm: %s", algorithm)
+	m.comment("Run with the image to threshold active; it is replaced by a binary mask")
+	m.blank()
+
+	switch algorithm {
+	case "2D Otsu":
+		e.writeOtsu2D(m, params)
+	case "Iterative Triclass":
+		e.writeTriclass(m, params)
+	default:
+		return "", fmt.Errorf("%s has no ImageJ macro equivalent", algorithm)
+	}
+
+	return m.String(), nil
+}
+
+// writeOtsu2D writes the 2D Otsu preprocessing in the application's order.
+// ImageJ has no 2D Otsu, so the threshold is a call to a plugin command of
+// that name that must be installed separately.
+func (e *ImageJMacroExporter) writeOtsu2D(m *macroWriter, params map[string]interface{}) {
+	e.writeChannelSelection(m, stringParam(params, "channel_selection", "luminance"))
+
+	if boolParam(params, "apply_global_equalization", false) && !boolParam(params, "use_clahe", false) {
+		e.writeGlobalEqualization(m)
+	}
+
+	if boolParam(params, "noise_robustness", true) {
+		m.comment("Noise robustness: 3x3 median blended with a light Gaussian; the median dominates")
+		m.run("Median...", "radius=1")
+		m.blank()
+	}
+
+	if boolParam(params, "use_clahe", false) {
+		clipLimit := floatParam(params, "clahe_clip_limit", 3.0)
+		tiles := intParam(params, "clahe_tile_size", 8)
+		m.comment("CLAHE over a %dx%d grid of tiles", tiles, tiles)
+		if clipLimit == 0 {
+			m.comment("The clip limit is chosen from the image noise in the application; 3 is used here")
+			clipLimit = 3.0
+		}
+		m.line("blocksize = floor(minOf(getWidth(), getHeight()) / %d);", tiles)
+		m.line(`run("Enhance Local Contrast (CLAHE)", "blocksize=" + blocksize + " histogram=256 maximum=%s mask=*None*");`, formatNumber(clipLimit))
+		m.blank()
+	}
+
+	e.writeUnsharp(m, params)
+
+	if boolParam(params, "gaussian_preprocessing", true) {
+		if sigma := floatParam(params, "smoothing_strength", 1.0); sigma > 0 {
+			m.comment("Gaussian smoothing")
+			m.run("Gaussian Blur...", "sigma="+formatNumber(sigma))
+			m.blank()
+		}
+	}
+
+	window := intParam(params, "window_size", 7)
+	bins := intParam(params, "histogram_bins", 0)
+	m.comment("2D Otsu on the joint histogram of grey level and %dx%d neighbourhood mean", window, window)
+	if bins == 0 {
+		m.comment("bins=0 sizes the histogram from the grey level entropy")
+	}
+	if boolParam(params, "adaptive_window_size", false) {
+		m.comment("The window size is derived from the image in the application; %d is used here", window)
+	}
+	if boolParam(params, "multi_scale", false) {
+		m.comment("The application blends neighbourhood means at several window sizes; only %d is used here", window)
+	}
+	m.comment("Stub: requires a plugin providing the Custom 2D Otsu command")
+	m.run("Custom 2D Otsu", fmt.Sprintf("window=%d bins=%d", window, bins))
+}
+
+// writeTriclass writes the Iterative Triclass preprocessing and the
+// iterations themselves. Each TBD region of the application is a band of
+// grey levels, so the iterations run on the image histogram and end in a
+// single global threshold.
+func (e *ImageJMacroExporter) writeTriclass(m *macroWriter, params map[string]interface{}) {
+	e.writeChannelSelection(m, stringParam(params, "channel_selection", "luminance"))
+
+	preprocessing := boolParam(params, "preprocessing", true)
+	if preprocessing && boolParam(params, "apply_global_equalization", false) {
+		e.writeGlobalEqualization(m)
+	}
+
+	if preprocessing && boolParam(params, "noise_robustness", true) {
+		m.comment("Non-local means denoising (h = 10, 7x7 patches); needs Fiji")
+		m.run("Non-local Means Denoising", "sigma=10 smoothing_factor=1")
+		m.blank()
+	}
+
+	if preprocessing {
+		e.writeUnsharp(m, params)
+	}
+
+	if preprocessing && boolParam(params, "guided_filtering", true) {
+		radius := intParam(params, "guided_radius", 6)
+		epsilon := floatParam(params, "guided_epsilon", 0.15)
+		m.comment("Guided filtering: blend of the local mean and the pixel, weighted by epsilon")
+		m.line("source = getTitle();")
+		m.run("Duplicate...", "title=guided_mean")
+		m.run("Mean...", fmt.Sprintf("radius=%d", radius))
+		m.run("Multiply...", "value="+formatNumber(1-epsilon))
+		m.line("selectImage(source);")
+		m.run("Multiply...", "value="+formatNumber(epsilon))
+		m.line(`imageCalculator("Add", source, "guided_mean");`)
+		m.line(`close("guided_mean");`)
+		m.blank()
+	}
+
+	method := stringParam(params, "initial_threshold_method", "otsu")
+	if _, ok := thresholdFunctions[method]; !ok {
+		// Unknown methods fall back to Otsu, as in the processor
+		method = "otsu"
+	}
+	maxIterations := intParam(params, "max_iterations", 8)
+	precision := floatParam(params, "convergence_precision", 1.0)
+	separation := floatParam(params, "class_separation", 0.5)
+	minTBD := floatParam(params, "minimum_tbd_fraction", 0.01)
+
+	m.comment("Iterative Triclass: %s threshold of the levels still undetermined, levels", method)
+	m.comment("above threshold * %s are foreground and below threshold * %s background", formatNumber(1+separation), formatNumber(1-separation))
+	if precision == 0 {
+		m.comment("The convergence precision is derived from the dynamic range in the application; 1 is used here")
+		precision = 1.0
+	}
+	if boolParam(params, "use_graph_cut", false) {
+		m.comment("Graph-cut refinement of the last undetermined band has no ImageJ equivalent and is left out")
+	}
+	m.line("getHistogram(values, counts, 256);")
+	m.line("total = getWidth() * getHeight();")
+	m.line("low = 1;")
+	m.line("high = 255;")
+	m.line("previous = -1;")
+	m.line("foreground = 256;")
+	m.line("for (i = 0; i < %d; i++) {", maxIterations)
+	m.indent++
+	m.line("region = newArray(256);")
+	m.line("pixels = 0;")
+	m.line("for (v = low; v <= high; v++) {")
+	m.indent++
+	m.line("region[v] = counts[v];")
+	m.line("pixels += counts[v];")
+	m.indent--
+	m.line("}")
+	m.line("if (pixels == 0) break;")
+	m.line("t = %sThreshold(region);", method)
+	m.line("if (i > 0 && abs(t - previous) < %s) break;", formatNumber(precision))
+	m.line("previous = t;")
+	m.line("upper = t * %s;", formatNumber(1+separation))
+	m.line("lower = t * %s;", formatNumber(1-separation))
+	m.line("foreground = minOf(foreground, floor(upper) + 1);")
+	m.line("low = maxOf(low, -floor(-lower));")
+	m.line("high = minOf(high, floor(upper));")
+	m.line("band = 0;")
+	m.line("for (v = low; v <= high; v++) band += counts[v];")
+	m.line("if (band / total < %s) break;", formatNumber(minTBD))
+	m.indent--
+	m.line("}")
+	m.line("setThreshold(minOf(foreground, 255), 255);")
+	m.line(`setOption("BlackBackground", true);`)
+	m.run("Convert to Mask", "")
+	m.blank()
+
+	if boolParam(params, "result_cleanup", true) {
+		small := intParam(params, "cleanup_kernel_small", 3)
+		large := intParam(params, "cleanup_kernel_large", 5)
+		iterations := intParam(params, "cleanup_iterations", 1)
+		m.comment("Cleanup: opening with a %d pixel and closing with a %d pixel kernel", small, large)
+		if boolParam(params, "adaptive_cleanup_kernels", false) {
+			m.comment("Kernel sizes are derived from the resolution in the application; the configured sizes are used here")
+		}
+		for i := 0; i < iterations; i++ {
+			m.run("Minimum...", fmt.Sprintf("radius=%d", small/2))
+			m.run("Maximum...", fmt.Sprintf("radius=%d", small/2))
+		}
+		for i := 0; i < iterations; i++ {
+			m.run("Maximum...", fmt.Sprintf("radius=%d", large/2))
+			m.run("Minimum...", fmt.Sprintf("radius=%d", large/2))
+		}
+		m.blank()
+
+		minArea := intParam(params, "min_object_area", 0)
+		fraction := floatParam(params, "min_object_area_fraction", 0.001)
+		maxArea := intParam(params, "max_object_area", 0)
+		if minArea > 0 || fraction > 0 || maxArea > 0 {
+			m.comment("Remove objects outside the area limits")
+			if minArea > 0 {
+				m.line("minArea = %d;", minArea)
+			} else {
+				m.line("minArea = round(%s * getWidth() * getHeight());", formatNumber(fraction))
+			}
+			maxSize := "Infinity"
+			if maxArea > 0 {
+				maxSize = strconv.Itoa(maxArea)
+			}
+			m.line(`run("Analyze Particles...", "size=" + minArea + "-%s pixel show=Masks in_situ");`, maxSize)
+			m.blank()
+		}
+	}
+
+	if boolParam(params, "apply_watershed", false) {
+		m.comment("Separate touching objects")
+		m.run("Watershed", "")
+		m.blank()
+	}
+
+	m.raw(thresholdFunctions[method])
+}
+
+// writeChannelSelection reduces colour images to the selected channel and
+// other depths to 8 bits
+func (e *ImageJMacroExporter) writeChannelSelection(m *macroWriter, channel string) {
+	m.comment("Channel: %s", channel)
+	m.line("if (bitDepth() == 24) {")
+	m.indent++
+	switch channel {
+	case "red", "green", "blue":
+		slice := map[string]int{"red": 1, "green": 2, "blue": 3}[channel]
+		m.run("RGB Stack", "")
+		m.line("setSlice(%d);", slice)
+		m.run("Duplicate...", "title="+channel)
+	case "hue", "saturation":
+		slice := map[string]int{"hue": 1, "saturation": 2}[channel]
+		m.run("HSB Stack", "")
+		m.line("setSlice(%d);", slice)
+		m.run("Duplicate...", "title="+channel)
+	default:
+		m.run("Conversions...", "scale weighted")
+		m.run("8-bit", "")
+	}
+	m.indent--
+	m.line("} else if (bitDepth() != 8) {")
+	m.indent++
+	m.run("8-bit", "")
+	m.indent--
+	m.line("}")
+	m.blank()
+}
+
+// writeGlobalEqualization equalises the whole histogram
+func (e *ImageJMacroExporter) writeGlobalEqualization(m *macroWriter) {
+	m.comment("Global histogram equalisation")
+	m.run("Enhance Contrast...", "saturated=0 equalize")
+	m.blank()
+}
+
+// writeUnsharp converts the application's unsharp mask, original + strength *
+// (original - blur), to ImageJ's mask weight w = strength / (1 + strength).
+// The blur sigma is the one OpenCV derives for a 2 * radius + 1 kernel.
+func (e *ImageJMacroExporter) writeUnsharp(m *macroWriter, params map[string]interface{}) {
+	if !boolParam(params, "apply_unsharp", false) {
+		return
+	}
+	strength := floatParam(params, "unsharp_strength", 0.5)
+	radius := intParam(params, "unsharp_radius", 2)
+	if strength <= 0 || radius < 1 {
+		return
+	}
+
+	sigma := 0.3*float64(radius-1) + 0.8
+	weight := strength / (1 + strength)
+	m.comment("Unsharp mask, strength %s", formatNumber(strength))
+	m.run("Unsharp Mask...", fmt.Sprintf("radius=%s mask=%s", formatNumber(sigma), strconv.FormatFloat(weight, 'f', 2, 64)))
+	m.blank()
+}
+
+// thresholdFunctions are macro ports of the Iterative Triclass threshold
+// methods, each named after its method and taking a 256 level histogram
+var thresholdFunctions = map[string]string{
+	"otsu": `function otsuThreshold(h) {
+	total = 0;
+	sum = 0;
+	for (i = 0; i < 256; i++) {
+		total += h[i];
+		sum += i * h[i];
+	}
+	if (total == 0) return 127.5;
+	sumB = 0;
+	wB = 0;
+	maxVariance = 0;
+	best = 127.5;
+	for (i = 0; i < 256; i++) {
+		wB += h[i];
+		sumB += i * h[i];
+		if (wB == 0) continue;
+		wF = total - wB;
+		if (wF == 0) break;
+		mB = sumB / wB;
+		mF = (sum - sumB) / wF;
+		variance = wB * wF * (mB - mF) * (mB - mF);
+		if (variance > maxVariance) {
+			maxVariance = variance;
+			best = i;
+		}
+	}
+	return best;
+}
+`,
+	"mean": `function meanThreshold(h) {
+	total = 0;
+	sum = 0;
+	for (i = 0; i < 256; i++) {
+		total += h[i];
+		sum += i * h[i];
+	}
+	if (total == 0) return 127.5;
+	return sum / total;
+}
+`,
+	"median": `function medianThreshold(h) {
+	total = 0;
+	for (i = 0; i < 256; i++) total += h[i];
+	if (total == 0) return 127.5;
+	half = floor(total / 2);
+	cumulative = 0;
+	for (i = 0; i < 256; i++) {
+		cumulative += h[i];
+		if (cumulative >= half) return i;
+	}
+	return 127.5;
+}
+`,
+	"triangle": `function triangleThreshold(h) {
+	peak = 0;
+	for (i = 1; i < 256; i++) {
+		if (h[i] > h[peak]) peak = i;
+	}
+	left = 0;
+	while (left < 255 && h[left] == 0) left++;
+	right = 255;
+	while (right > 0 && h[right] == 0) right--;
+	far = right;
+	if (peak - left > right - peak) far = left;
+	best = peak;
+	if (peak == far) return best;
+	x1 = peak;
+	y1 = h[peak];
+	x2 = far;
+	y2 = h[far];
+	norm = sqrt((y2 - y1) * (y2 - y1) + (x2 - x1) * (x2 - x1));
+	maxDistance = 0;
+	for (i = minOf(peak, far); i <= maxOf(peak, far); i++) {
+		distance = abs((y2 - y1) * i - (x2 - x1) * h[i] + x2 * y1 - y2 * x1) / norm;
+		if (distance > maxDistance) {
+			maxDistance = distance;
+			best = i;
+		}
+	}
+	return best;
+}
+`,
+}
+
+// macroWriter accumulates macro source with tab indentation
+type macroWriter struct {
+	sb     strings.Builder
+	indent int
+}
+
+func (m *macroWriter) line(format string, args ...interface{}) {
+	m.sb.WriteString(strings.Repeat("\t", m.indent))
+	fmt.Fprintf(&m.sb, format, args...)
+	m.sb.WriteByte('\n')
+}
+
+func (m *macroWriter) comment(format string, args ...interface{}) {
+	m.line("// "+format, args...)
+}
+
+// run writes a run call; commands without options take no second argument
+func (m *macroWriter) run(command, options string) {
+	if options == "" {
+		m.line("run(%s);", strconv.Quote(command))
+		return
+	}
+	m.line("run(%s, %s);", strconv.Quote(command), strconv.Quote(options))
+}
+
+func (m *macroWriter) blank() {
+	m.sb.WriteByte('\n')
+}
+
+func (m *macroWriter) raw(source string) {
+	m.sb.WriteString(source)
+}
+
+func (m *macroWriter) String() string {
+	return m.sb.String()
+}
+
+// formatNumber writes v with as few digits as represent it exactly
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func intParam(params map[string]interface{}, key string, defaultValue int) int {
+	if value, ok := params[key].(int); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func floatParam(params map[string]interface{}, key string, defaultValue float64) float64 {
+	if value, ok := params[key].(float64); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func boolParam(params map[string]interface{}, key string, defaultValue bool) bool {
+	if value, ok := params[key].(bool); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func stringParam(params map[string]interface{}, key string, defaultValue string) string {
+	if value, ok := params[key].(string); ok {
+		return value
+	}
+	return defaultValue
+}
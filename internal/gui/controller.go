@@ -11,11 +11,13 @@ import (
 	"otsu-obliterator/internal/algorithms"
 	"otsu-obliterator/internal/gui/widgets"
 	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/models"
 	"otsu-obliterator/internal/pipeline"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 )
 
 type Controller struct {
@@ -180,7 +182,7 @@ func (c *Controller) SaveImage() {
 	c.logger.Debug("Save image requested", nil)
 
 	fyne.Do(func() {
-		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
 			if err != nil {
 				c.handleError("File save error", err)
 				return
@@ -197,6 +199,8 @@ func (c *Controller) SaveImage() {
 				c.processSaveImage(writer, processedImg)
 			}()
 		}, c.getMainWindow())
+		saveDialog.SetFilter(storage.NewExtensionFileFilter(models.SaveFormatExtensions))
+		saveDialog.Show()
 	})
 }
 
@@ -0,0 +1,19 @@
+//go:build release
+
+package logger
+
+// Diagnostic returns the logger given to algorithms for per-iteration
+// diagnostics. Release builds swap in a wrapper whose Debug does nothing, so
+// those calls cost no formatting or I/O; other levels pass through.
+func Diagnostic(log Logger) Logger {
+	if log == nil {
+		return nil
+	}
+	return releaseLogger{log}
+}
+
+type releaseLogger struct {
+	Logger
+}
+
+func (releaseLogger) Debug(msg string, fields map[string]interface{}) {}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// JSONLogger writes newline-delimited JSON records with timestamp, level,
+// message and fields keys, for ingestion by log aggregation tools
+type JSONLogger struct {
+	mu     sync.Mutex
+	writer io.Writer
+	level  LogLevel
+}
+
+type jsonRecord struct {
+	Timestamp string                 `json:"timestamp"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+}
+
+func NewJSONLogger(level LogLevel, writer io.Writer) *JSONLogger {
+	return &JSONLogger{
+		writer: writer,
+		level:  level,
+	}
+}
+
+func (l *JSONLogger) Debug(msg string, fields map[string]interface{}) {
+	if l.level > DebugLevel {
+		return
+	}
+	l.write("DEBUG", msg, fields)
+}
+
+func (l *JSONLogger) Info(msg string, fields map[string]interface{}) {
+	if l.level > InfoLevel {
+		return
+	}
+	l.write("INFO", msg, fields)
+}
+
+func (l *JSONLogger) Warning(msg string, fields map[string]interface{}) {
+	if l.level > WarnLevel {
+		return
+	}
+	l.write("WARN", msg, fields)
+}
+
+func (l *JSONLogger) Error(msg string, err error, fields map[string]interface{}) {
+	if l.level > ErrorLevel {
+		return
+	}
+
+	if fields == nil {
+		fields = make(map[string]interface{})
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+
+	l.write("ERROR", msg, fields)
+}
+
+func (l *JSONLogger) write(level, msg string, fields map[string]interface{}) {
+	record := jsonRecord{
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+		Level:     level,
+		Message:   msg,
+		Fields:    sanitizeFields(fields),
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		// Fall back to string values so a record is never dropped
+		record.Fields = stringifyFields(fields)
+		data, err = json.Marshal(record)
+		if err != nil {
+			return
+		}
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.writer.Write(data)
+}
+
+// sanitizeFields renders values that do not marshal meaningfully on their own
+func sanitizeFields(fields map[string]interface{}) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	sanitized := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		switch value := v.(type) {
+		case error:
+			sanitized[k] = value.Error()
+		case time.Duration:
+			sanitized[k] = value.String()
+		case fmt.Stringer:
+			sanitized[k] = value.String()
+		default:
+			sanitized[k] = v
+		}
+	}
+	return sanitized
+}
+
+func stringifyFields(fields map[string]interface{}) map[string]interface{} {
+	stringified := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		stringified[k] = fmt.Sprint(v)
+	}
+	return stringified
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// decodeRecords round-trips every line written by a JSONLogger
+func decodeRecords(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestJSONLoggerFieldLayout(t *testing.T) {
+	var output bytes.Buffer
+	log := NewJSONLogger(DebugLevel, &output)
+
+	log.Info("Image processing completed", map[string]interface{}{
+		"algorithm":       "2D Otsu",
+		"threshold":       127.5,
+		"processing_time": 1500 * time.Millisecond,
+	})
+	log.Error("Failed to save image", errors.New("disk full"), nil)
+
+	records := decodeRecords(t, &output)
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+
+	info := records[0]
+	for _, key := range []string{"timestamp", "level", "message", "fields"} {
+		if _, ok := info[key]; !ok {
+			t.Errorf("record has no %q key: %v", key, info)
+		}
+	}
+	if len(info) != 4 {
+		t.Errorf("record has %d keys, want 4: %v", len(info), info)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, info["timestamp"].(string)); err != nil {
+		t.Errorf("timestamp %q is not RFC 3339: %v", info["timestamp"], err)
+	}
+	if info["level"] != "INFO" || info["message"] != "Image processing completed" {
+		t.Errorf("level, message = %v, %v", info["level"], info["message"])
+	}
+
+	fields := info["fields"].(map[string]interface{})
+	if fields["algorithm"] != "2D Otsu" || fields["threshold"] != 127.5 {
+		t.Errorf("fields = %v", fields)
+	}
+	if fields["processing_time"] != "1.5s" {
+		t.Errorf("processing_time = %v, want the duration string 1.5s", fields["processing_time"])
+	}
+
+	failure := records[1]
+	if failure["level"] != "ERROR" {
+		t.Errorf("level = %v, want ERROR", failure["level"])
+	}
+	if got := failure["fields"].(map[string]interface{})["error"]; got != "disk full" {
+		t.Errorf("error field = %v, want %q", got, "disk full")
+	}
+}
+
+func TestJSONLoggerOmitsEmptyFields(t *testing.T) {
+	var output bytes.Buffer
+	NewJSONLogger(DebugLevel, &output).Debug("Cache cleared", nil)
+
+	records := decodeRecords(t, &output)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if _, ok := records[0]["fields"]; ok {
+		t.Errorf("record without fields has a fields key: %v", records[0])
+	}
+}
+
+func TestJSONLoggerLevelFiltering(t *testing.T) {
+	tests := []struct {
+		level LogLevel
+		want  []string
+	}{
+		{DebugLevel, []string{"DEBUG", "INFO", "WARN", "ERROR"}},
+		{InfoLevel, []string{"INFO", "WARN", "ERROR"}},
+		{WarnLevel, []string{"WARN", "ERROR"}},
+		{ErrorLevel, []string{"ERROR"}},
+	}
+
+	for _, tt := range tests {
+		var output bytes.Buffer
+		log := NewJSONLogger(tt.level, &output)
+		log.Debug("debug", nil)
+		log.Info("info", nil)
+		log.Warning("warning", nil)
+		log.Error("error", nil, nil)
+
+		var levels []string
+		for _, record := range decodeRecords(t, &output) {
+			levels = append(levels, record["level"].(string))
+		}
+		if len(levels) != len(tt.want) {
+			t.Errorf("level %d: logged %v, want %v", tt.level, levels, tt.want)
+			continue
+		}
+		for i := range levels {
+			if levels[i] != tt.want[i] {
+				t.Errorf("level %d: logged %v, want %v", tt.level, levels, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// unmarshalable cannot be encoded, so the logger falls back to strings
+type unmarshalable struct {
+	C chan int
+}
+
+func TestJSONLoggerFallsBackToStrings(t *testing.T) {
+	var output bytes.Buffer
+	NewJSONLogger(InfoLevel, &output).Info("Odd field", map[string]interface{}{
+		"channel": unmarshalable{C: make(chan int)},
+	})
+
+	records := decodeRecords(t, &output)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if _, ok := records[0]["fields"].(map[string]interface{})["channel"].(string); !ok {
+		t.Errorf("unencodable field was not written as a string: %v", records[0])
+	}
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+)
+
+// AnnotationKind is the shape of an annotation
+type AnnotationKind string
+
+const (
+	// AnnotationFreehand is a polyline through every point
+	AnnotationFreehand AnnotationKind = "freehand"
+	// AnnotationRectangle spans the two corner points
+	AnnotationRectangle AnnotationKind = "rectangle"
+	// AnnotationCircle is centred on the first point and passes through the second
+	AnnotationCircle AnnotationKind = "circle"
+	// AnnotationText places its label at the single point
+	AnnotationText AnnotationKind = "text"
+)
+
+// Annotation marks a region of the original image with a label. Points are
+// in image pixel coordinates so annotations survive zooming and scrolling.
+type Annotation struct {
+	Kind   AnnotationKind
+	Points []image.Point
+	Label  string
+	Color  color.NRGBA
+}
+
+// Radius returns the radius of a circle annotation in pixels
+func (a Annotation) Radius() int {
+	if len(a.Points) < 2 {
+		return 0
+	}
+	d := a.Points[1].Sub(a.Points[0])
+	return int(math.Round(math.Hypot(float64(d.X), float64(d.Y))))
+}
+
+// geoJSONFeatureCollection is the subset of GeoJSON (RFC 7946) written for
+// annotations. Coordinates are image pixels with y pointing down rather than
+// longitude and latitude, as read by QuPath and the ImageJ GeoJSON importers.
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// circleExportSegments is how many vertices approximate a circle on export
+const circleExportSegments = 64
+
+// MarshalAnnotationsGeoJSON encodes annotations as a GeoJSON feature
+// collection. Rectangles and circles become closed polygons, freehand strokes
+// line strings and text labels points; the label, colour and kind are kept in
+// the feature properties.
+func MarshalAnnotationsGeoJSON(annotations []Annotation) ([]byte, error) {
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(annotations)),
+	}
+
+	for i, annotation := range annotations {
+		geometry, err := annotationGeometry(annotation)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %d: %w", i+1, err)
+		}
+
+		properties := map[string]interface{}{
+			"name":  annotation.Label,
+			"kind":  string(annotation.Kind),
+			"color": []uint8{annotation.Color.R, annotation.Color.G, annotation.Color.B},
+		}
+		if annotation.Kind == AnnotationCircle {
+			properties["radius"] = annotation.Radius()
+		}
+
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type:       "Feature",
+			Geometry:   geometry,
+			Properties: properties,
+		})
+	}
+
+	return json.MarshalIndent(collection, "", "  ")
+}
+
+// annotationGeometry converts the points of an annotation to a GeoJSON geometry
+func annotationGeometry(annotation Annotation) (geoJSONGeometry, error) {
+	points := annotation.Points
+
+	switch annotation.Kind {
+	case AnnotationText:
+		if len(points) < 1 {
+			return geoJSONGeometry{}, fmt.Errorf("text annotation has no position")
+		}
+		return geoJSONGeometry{Type: "Point", Coordinates: coordinate(points[0])}, nil
+
+	case AnnotationFreehand:
+		if len(points) < 2 {
+			return geoJSONGeometry{}, fmt.Errorf("freehand annotation needs at least 2 points")
+		}
+		line := make([][2]int, len(points))
+		for i, p := range points {
+			line[i] = coordinate(p)
+		}
+		return geoJSONGeometry{Type: "LineString", Coordinates: line}, nil
+
+	case AnnotationRectangle:
+		if len(points) < 2 {
+			return geoJSONGeometry{}, fmt.Errorf("rectangle annotation needs 2 corners")
+		}
+		r := image.Rectangle{Min: points[0], Max: points[1]}.Canon()
+		ring := [][2]int{
+			{r.Min.X, r.Min.Y}, {r.Max.X, r.Min.Y}, {r.Max.X, r.Max.Y}, {r.Min.X, r.Max.Y}, {r.Min.X, r.Min.Y},
+		}
+		return geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]int{ring}}, nil
+
+	case AnnotationCircle:
+		if len(points) < 2 {
+			return geoJSONGeometry{}, fmt.Errorf("circle annotation needs a centre and radius")
+		}
+		centre, radius := points[0], float64(annotation.Radius())
+		ring := make([][2]int, circleExportSegments+1)
+		for i := range circleExportSegments {
+			angle := 2 * math.Pi * float64(i) / circleExportSegments
+			ring[i] = [2]int{
+				centre.X + int(math.Round(radius*math.Cos(angle))),
+				centre.Y + int(math.Round(radius*math.Sin(angle))),
+			}
+		}
+		ring[circleExportSegments] = ring[0]
+		return geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]int{ring}}, nil
+
+	default:
+		return geoJSONGeometry{}, fmt.Errorf("unknown annotation kind %q", annotation.Kind)
+	}
+}
+
+func coordinate(p image.Point) [2]int {
+	return [2]int{p.X, p.Y}
+}
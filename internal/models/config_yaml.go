@@ -0,0 +1,196 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configVersion is written to every exported configuration
+const configVersion = 1
+
+// configDocument is the YAML layout of an exported configuration. Parameters
+// are plain YAML values; their Go types come from the algorithm defaults when
+// read back.
+type configDocument struct {
+	Version     int                               `yaml:"version"`
+	Algorithm   string                            `yaml:"algorithm"`
+	Parameters  map[string]map[string]interface{} `yaml:"parameters"`
+	Performance PerformanceSettings               `yaml:"performance"`
+}
+
+// ConfigSerializer writes a processing configuration as YAML, so a run can be
+// reproduced from the file, and reads it back
+type ConfigSerializer struct{}
+
+// NewConfigSerializer creates a serializer
+func NewConfigSerializer() *ConfigSerializer {
+	return &ConfigSerializer{}
+}
+
+// Marshal encodes the current algorithm, the parameters of every algorithm
+// and the performance settings of config
+func (cs *ConfigSerializer) Marshal(config *ProcessingConfiguration) ([]byte, error) {
+	config.mu.RLock()
+	doc := configDocument{
+		Version:     configVersion,
+		Algorithm:   config.currentAlgorithm,
+		Parameters:  make(map[string]map[string]interface{}, len(config.algorithmParameters)),
+		Performance: config.performanceSettings,
+	}
+	for name, params := range config.algorithmParameters {
+		values := make(map[string]interface{}, len(params.Parameters))
+		for key, value := range params.Parameters {
+			values[key] = value
+		}
+		doc.Parameters[name] = values
+	}
+	config.mu.RUnlock()
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal builds a configuration from YAML written by Marshal, starting from
+// the defaults so that missing algorithms, parameters and performance settings
+// keep their default values. Every unknown algorithm or parameter, mistyped
+// value and out of range value is reported, joined into one error.
+func (cs *ConfigSerializer) Unmarshal(data []byte) (*ProcessingConfiguration, error) {
+	config := NewProcessingConfiguration()
+
+	doc := configDocument{Performance: config.GetPerformanceSettings()}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid configuration file: %w", err)
+	}
+	if doc.Version > configVersion {
+		return nil, fmt.Errorf("configuration version %d is newer than supported version %d", doc.Version, configVersion)
+	}
+
+	var errs []error
+	algorithms := make([]string, 0, len(doc.Parameters))
+	for name := range doc.Parameters {
+		algorithms = append(algorithms, name)
+	}
+	slices.Sort(algorithms)
+
+	config.mu.Lock()
+	for _, name := range algorithms {
+		params, exists := config.algorithmParameters[name]
+		if !exists {
+			errs = append(errs, NewValidationError("algorithm", name, "algorithm not found"))
+			continue
+		}
+
+		values, err := config.parseConfigValues(params, doc.Parameters[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for key, value := range values {
+			params.Parameters[key] = value
+		}
+	}
+	config.performanceSettings = doc.Performance
+	config.mu.Unlock()
+
+	if doc.Algorithm != "" {
+		if err := config.SetCurrentAlgorithm(doc.Algorithm); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return config, nil
+}
+
+// parseConfigValues converts the YAML values of one algorithm to the types of
+// its defaults and checks them against their ranges. The cleanup kernels are
+// checked as a pair once both are known.
+func (pc *ProcessingConfiguration) parseConfigValues(params AlgorithmParameters, raw map[string]interface{}) (map[string]interface{}, error) {
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var errs []error
+	values := make(map[string]interface{}, len(raw))
+	for _, key := range keys {
+		defaultValue, known := params.Defaults[key]
+		if !known {
+			errs = append(errs, NewValidationError(key, raw[key], fmt.Sprintf("unknown parameter for %s", params.Name)))
+			continue
+		}
+
+		value, ok := convertConfigValue(raw[key], defaultValue)
+		if !ok {
+			errs = append(errs, NewValidationError(key, raw[key], fmt.Sprintf("wrong type, expected %T", defaultValue)))
+			continue
+		}
+		if err := pc.validateParameter(params, key, value); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values[key] = value
+	}
+
+	small, hasSmall := values["cleanup_kernel_small"]
+	large, hasLarge := values["cleanup_kernel_large"]
+	if hasSmall || hasLarge {
+		if !hasSmall {
+			small = params.Parameters["cleanup_kernel_small"]
+		}
+		if !hasLarge {
+			large = params.Parameters["cleanup_kernel_large"]
+		}
+		if err := validateCleanupKernels(small, large); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return values, nil
+}
+
+// convertConfigValue returns value as the type of defaultValue. YAML writes
+// whole floats such as 1.0 as integers, so ints are accepted for floats.
+func convertConfigValue(value, defaultValue interface{}) (interface{}, bool) {
+	switch defaultValue.(type) {
+	case int:
+		v, ok := value.(int)
+		return v, ok
+	case float64:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case int:
+			return float64(v), true
+		}
+		return nil, false
+	case bool:
+		v, ok := value.(bool)
+		return v, ok
+	case string:
+		v, ok := value.(string)
+		return v, ok
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// roundTrip writes config as YAML and reads it back
+func roundTrip(t *testing.T, config *ProcessingConfiguration) (*ProcessingConfiguration, []byte) {
+	t.Helper()
+
+	serializer := NewConfigSerializer()
+	data, err := serializer.Marshal(config)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	restored, err := serializer.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal: %v\n%s", err, data)
+	}
+	return restored, data
+}
+
+func TestConfigYAMLRoundTripKeepsDefaultParameterTypes(t *testing.T) {
+	config := NewProcessingConfiguration()
+	restored, data := roundTrip(t, config)
+
+	// Unmarshal starts from the defaults, so check that every parameter was
+	// actually written rather than filled back in
+	var doc configDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	names := config.GetAvailableAlgorithms()
+	slices.Sort(names)
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			want, err := config.GetAlgorithmParameters(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := restored.GetAlgorithmParameters(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for key, value := range want.Parameters {
+				if _, written := doc.Parameters[name][key]; !written {
+					t.Errorf("%s was not written", key)
+				}
+
+				restoredValue := got.Parameters[key]
+				if reflect.TypeOf(restoredValue) != reflect.TypeOf(value) {
+					t.Errorf("%s = %v (%T), want %v (%T)", key, restoredValue, restoredValue, value, value)
+				} else if !reflect.DeepEqual(restoredValue, value) {
+					t.Errorf("%s = %v, want %v", key, restoredValue, value)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigYAMLRoundTripKeepsChangedParameterTypes(t *testing.T) {
+	const algorithm = "Iterative Triclass"
+
+	tests := []struct {
+		name  string
+		key   string
+		value interface{}
+	}{
+		{name: "int", key: "max_iterations", value: 12},
+		// YAML writes a whole float as an integer
+		{name: "whole float", key: "convergence_precision", value: 2.0},
+		{name: "float", key: "class_separation", value: 0.35},
+		{name: "bool", key: "apply_watershed", value: true},
+		{name: "string", key: "initial_threshold_method", value: "triangle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewProcessingConfiguration()
+			if err := config.SetAlgorithmParameter(algorithm, tt.key, tt.value); err != nil {
+				t.Fatalf("SetAlgorithmParameter: %v", err)
+			}
+
+			restored, _ := roundTrip(t, config)
+			params, err := restored.GetAlgorithmParameters(algorithm)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := params.Parameters[tt.key]
+			if reflect.TypeOf(got) != reflect.TypeOf(tt.value) || got != tt.value {
+				t.Errorf("%s = %v (%T), want %v (%T)", tt.key, got, got, tt.value, tt.value)
+			}
+		})
+	}
+}
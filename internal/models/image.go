@@ -36,16 +36,39 @@ type ImageMetadata struct {
 	Author      string
 	Software    string
 	Keywords    []string
+	// Tags holds format-specific header attributes, e.g. DICOM PatientID
+	Tags map[string]string
+	// Exif holds the readable EXIF fields by tag name, nil without EXIF data
+	Exif map[string]string
+}
+
+// ImageStatistics summarises the grey levels of an image in the units of its
+// own depth
+type ImageStatistics struct {
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Max    float64
+	// Entropy of the 256-level histogram in bits
+	Entropy float64
+	// DynamicRange is Max - Min; DynamicRangeBits is the bits needed to
+	// represent it
+	DynamicRange     float64
+	DynamicRangeBits float64
 }
 
 // ProcessingResult contains the output of image processing operations
 type ProcessingResult struct {
 	ProcessedImage *ImageData
-	Algorithm      string
-	Parameters     map[string]interface{}
-	Metrics        *SegmentationMetrics
-	ProcessTime    time.Duration
-	MemoryUsed     int64
+	// DifferenceImage colours the pixel changes from the original, nil when
+	// the result does not match the original size
+	DifferenceImage *ImageData
+	Algorithm       string
+	Parameters      map[string]interface{}
+	Metrics         *SegmentationMetrics
+	ProcessTime     time.Duration
+	MemoryUsed      int64
+	Statistics      map[string]interface{}
 }
 
 // SegmentationMetrics contains quality evaluation metrics
@@ -56,15 +79,41 @@ type SegmentationMetrics struct {
 	RegionUniformity       float64
 	BoundaryAccuracy       float64
 	HausdorffDistance      float64
+	HausdorffDistance95    float64
+	PSNR                   float64
+	SSIM                   float64
+}
+
+// AlgorithmHistoryEntry records one processing run so it can be revisited
+// and compared with other runs without reprocessing
+type AlgorithmHistoryEntry struct {
+	Algorithm  string
+	Parameters map[string]interface{}
+	Image      *ImageData
+	Metrics    *SegmentationMetrics
+	Timestamp  time.Time
 }
 
 // ImageRepository manages image data storage and retrieval
 type ImageRepository struct {
 	mu               sync.RWMutex
 	originalImage    *ImageData
+	groundTruth      *ImageData
+	weightMap        *ImageData
+	currentROI       *image.Rectangle
+	annotations      []Annotation
 	processedImages  map[string]*ImageData
 	processingHistory []ProcessingResult
+	history          []AlgorithmHistoryEntry
 	maxHistorySize   int
+	undoStack        *UndoStack
+
+	// nextImageID numbers stored results so their IDs never repeat
+	nextImageID      uint64
+
+	// Completed runs reported through StoreProcessedImage
+	processedCount   int
+	lastAlgorithm    string
 }
 
 // NewImageRepository creates a new image repository
@@ -73,6 +122,7 @@ func NewImageRepository() *ImageRepository {
 		processedImages:  make(map[string]*ImageData),
 		processingHistory: make([]ProcessingResult, 0),
 		maxHistorySize:   10,
+		undoStack:        NewUndoStack(5),
 	}
 }
 
@@ -85,6 +135,10 @@ func (r *ImageRepository) SetOriginalImage(img *ImageData) {
 		r.originalImage.Mat.Close()
 	}
 	r.originalImage = img
+
+	// A region selected or annotated on the previous image no longer applies
+	r.currentROI = nil
+	r.annotations = nil
 }
 
 // GetOriginalImage retrieves the original image
@@ -94,22 +148,173 @@ func (r *ImageRepository) GetOriginalImage() *ImageData {
 	return r.originalImage
 }
 
+// SetCurrentROI restricts processing to a region of the original image, nil clears it
+func (r *ImageRepository) SetCurrentROI(roi *image.Rectangle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if roi == nil {
+		r.currentROI = nil
+		return
+	}
+	region := *roi
+	r.currentROI = &region
+}
+
+// GetCurrentROI returns a copy of the region of interest, or nil if none is set
+func (r *ImageRepository) GetCurrentROI() *image.Rectangle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.currentROI == nil {
+		return nil
+	}
+	region := *r.currentROI
+	return &region
+}
+
+// AddAnnotation appends an annotation of the original image
+func (r *ImageRepository) AddAnnotation(annotation Annotation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.annotations = append(r.annotations, annotation)
+}
+
+// GetAnnotations returns a copy of the annotations of the original image
+func (r *ImageRepository) GetAnnotations() []Annotation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	annotations := make([]Annotation, len(r.annotations))
+	copy(annotations, r.annotations)
+	return annotations
+}
+
+// ClearAnnotations removes every annotation
+func (r *ImageRepository) ClearAnnotations() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.annotations = nil
+}
+
+// SetGroundTruth stores the reference segmentation mask used for metrics
+func (r *ImageRepository) SetGroundTruth(mask *ImageData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.groundTruth != nil && r.groundTruth.Mat != nil {
+		r.groundTruth.Mat.Close()
+	}
+	r.groundTruth = mask
+}
+
+// GetGroundTruth retrieves the reference segmentation mask, or nil if none is
+// loaded. The returned Mat holds its own reference, so it stays valid when a
+// new mask replaces this one; the caller must Close it.
+func (r *ImageRepository) GetGroundTruth() *ImageData {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.groundTruth == nil {
+		return nil
+	}
+	mask := *r.groundTruth
+	if mask.Mat != nil {
+		mask.Mat.AddRef()
+	}
+	return &mask
+}
+
+// SetWeightMap stores the grayscale map weighting each pixel's contribution
+// to the 2D Otsu histogram; nil clears it
+func (r *ImageRepository) SetWeightMap(weights *ImageData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.weightMap != nil && r.weightMap.Mat != nil {
+		r.weightMap.Mat.Close()
+	}
+	r.weightMap = weights
+}
+
+// GetWeightMap retrieves the histogram weight map, or nil if none is loaded
+func (r *ImageRepository) GetWeightMap() *ImageData {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.weightMap
+}
+
 // AddProcessedImage stores a processed image result
 func (r *ImageRepository) AddProcessedImage(result ProcessingResult) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.addProcessedImage(result)
+}
+
+// StoreProcessedImage records a completed processing run for the repository
+// statistics. A result that was never stored is stored now; one stored
+// before, even if the history has since dropped it, is only counted.
+func (r *ImageRepository) StoreProcessedImage(result *ProcessingResult) {
+	if result == nil || result.ProcessedImage == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if result.ProcessedImage.ID == "" {
+		r.addProcessedImage(*result)
+	}
+	r.processedCount++
+	r.lastAlgorithm = result.Algorithm
+}
+
+// addProcessedImage stores result and appends it to the history; the caller
+// holds the lock
+func (r *ImageRepository) addProcessedImage(result ProcessingResult) {
 	// Store processed image
-	imageID := fmt.Sprintf("%s_%d", result.Algorithm, time.Now().Unix())
+	r.nextImageID++
+	imageID := fmt.Sprintf("%s_%d", result.Algorithm, r.nextImageID)
 	result.ProcessedImage.ID = imageID
 	r.processedImages[imageID] = result.ProcessedImage
 
 	// Add to processing history
 	r.processingHistory = append(r.processingHistory, result)
 
-	// Limit history size
-	if len(r.processingHistory) > r.maxHistorySize {
-		// Remove oldest entry and clean up its image
+	parameters := make(map[string]interface{}, len(result.Parameters))
+	for key, value := range result.Parameters {
+		parameters[key] = value
+	}
+	r.history = append(r.history, AlgorithmHistoryEntry{
+		Algorithm:  result.Algorithm,
+		Parameters: parameters,
+		Image:      result.ProcessedImage,
+		Metrics:    result.Metrics,
+		Timestamp:  time.Now(),
+	})
+
+	r.trimHistory()
+}
+
+// SetMaxHistorySize sets how many processing runs are kept, dropping the
+// oldest runs if the history is already longer
+func (r *ImageRepository) SetMaxHistorySize(size int) {
+	if size < 1 {
+		size = 1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxHistorySize = size
+	r.trimHistory()
+}
+
+// trimHistory removes the oldest runs and their images beyond maxHistorySize.
+// Callers must hold the write lock.
+func (r *ImageRepository) trimHistory() {
+	for len(r.processingHistory) > r.maxHistorySize {
 		oldest := r.processingHistory[0]
 		if oldImage, exists := r.processedImages[oldest.ProcessedImage.ID]; exists {
 			if oldImage.Mat != nil {
@@ -117,8 +322,43 @@ func (r *ImageRepository) AddProcessedImage(result ProcessingResult) {
 			}
 			delete(r.processedImages, oldest.ProcessedImage.ID)
 		}
+		closeDifferenceImage(oldest)
 		r.processingHistory = r.processingHistory[1:]
 	}
+
+	if excess := len(r.history) - r.maxHistorySize; excess > 0 {
+		r.history = r.history[excess:]
+	}
+}
+
+// closeDifferenceImage releases the difference Mat of a stored result
+func closeDifferenceImage(result ProcessingResult) {
+	if result.DifferenceImage != nil && result.DifferenceImage.Mat != nil {
+		result.DifferenceImage.Mat.Close()
+	}
+}
+
+// GetHistory returns the recorded processing runs, oldest first
+func (r *ImageRepository) GetHistory() []AlgorithmHistoryEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := make([]AlgorithmHistoryEntry, len(r.history))
+	copy(history, r.history)
+	return history
+}
+
+// GetHistoryEntry returns a copy of the processing run at index, oldest first
+func (r *ImageRepository) GetHistoryEntry(index int) (*AlgorithmHistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if index < 0 || index >= len(r.history) {
+		return nil, fmt.Errorf("history index %d out of range [0, %d)", index, len(r.history))
+	}
+
+	entry := r.history[index]
+	return &entry, nil
 }
 
 // GetLatestProcessedImage returns the most recently processed image
@@ -174,9 +414,13 @@ func (r *ImageRepository) ClearProcessedImages() {
 			img.Mat.Close()
 		}
 	}
+	for _, result := range r.processingHistory {
+		closeDifferenceImage(result)
+	}
 
 	r.processedImages = make(map[string]*ImageData)
 	r.processingHistory = make([]ProcessingResult, 0)
+	r.history = nil
 }
 
 // ClearAll removes all images including original
@@ -196,9 +440,31 @@ func (r *ImageRepository) ClearAll() {
 			img.Mat.Close()
 		}
 	}
+	for _, result := range r.processingHistory {
+		closeDifferenceImage(result)
+	}
+
+	// Clean up ground truth mask
+	if r.groundTruth != nil && r.groundTruth.Mat != nil {
+		r.groundTruth.Mat.Close()
+		r.groundTruth = nil
+	}
+	if r.weightMap != nil && r.weightMap.Mat != nil {
+		r.weightMap.Mat.Close()
+		r.weightMap = nil
+	}
+	r.currentROI = nil
+	r.annotations = nil
 
 	r.processedImages = make(map[string]*ImageData)
 	r.processingHistory = make([]ProcessingResult, 0)
+	r.history = nil
+	r.undoStack.Clear()
+}
+
+// GetUndoStack returns the undo/redo history of processed images
+func (r *ImageRepository) GetUndoStack() *UndoStack {
+	return r.undoStack
 }
 
 // GetImageStats returns statistics about stored images
@@ -207,11 +473,12 @@ func (r *ImageRepository) GetImageStats() ImageStats {
 	defer r.mu.RUnlock()
 
 	stats := ImageStats{
-		HasOriginal:        r.originalImage != nil,
-		ProcessedCount:     len(r.processedImages),
-		HistorySize:        len(r.processingHistory),
-		TotalMemoryUsage:   r.calculateMemoryUsage(),
-		AverageProcessTime: r.calculateAverageProcessTime(),
+		OriginalLoaded:         r.originalImage != nil,
+		ProcessedCount:         r.processedCount,
+		LastProcessedAlgorithm: r.lastAlgorithm,
+		HistorySize:            len(r.processingHistory),
+		TotalMemoryBytes:       r.calculateMemoryUsage(),
+		AverageProcessTime:     r.calculateAverageProcessTime(),
 	}
 
 	return stats
@@ -219,10 +486,13 @@ func (r *ImageRepository) GetImageStats() ImageStats {
 
 // ImageStats contains statistics about the image repository
 type ImageStats struct {
-	HasOriginal        bool
-	ProcessedCount     int
+	OriginalLoaded bool
+	// ProcessedCount is the number of completed processing runs
+	ProcessedCount         int
+	LastProcessedAlgorithm string
+	// HistorySize is the number of results currently held
 	HistorySize        int
-	TotalMemoryUsage   int64
+	TotalMemoryBytes   int64
 	AverageProcessTime time.Duration
 }
 
@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestProcessedImageIDsAreUnique(t *testing.T) {
+	repo := NewImageRepository()
+
+	// Runs completing within the same second must not replace each other
+	first := ProcessingResult{ProcessedImage: &ImageData{}, Algorithm: "2D Otsu"}
+	second := ProcessingResult{ProcessedImage: &ImageData{}, Algorithm: "2D Otsu"}
+	repo.AddProcessedImage(first)
+	repo.AddProcessedImage(second)
+
+	if first.ProcessedImage.ID == second.ProcessedImage.ID {
+		t.Fatalf("both results got ID %q", first.ProcessedImage.ID)
+	}
+	if got := repo.GetImageStats().HistorySize; got != 2 {
+		t.Errorf("HistorySize = %d, want 2", got)
+	}
+}
+
+func TestStoreProcessedImageDoesNotRestoreTrimmedResults(t *testing.T) {
+	repo := NewImageRepository()
+	repo.SetMaxHistorySize(1)
+
+	first := &ProcessingResult{ProcessedImage: &ImageData{}, Algorithm: "2D Otsu"}
+	second := &ProcessingResult{ProcessedImage: &ImageData{}, Algorithm: "Iterative Triclass"}
+	repo.AddProcessedImage(*first)
+	repo.AddProcessedImage(*second)
+
+	// The completion of the first run is reported after the history dropped it
+	repo.StoreProcessedImage(first)
+	repo.StoreProcessedImage(second)
+
+	stats := repo.GetImageStats()
+	if stats.HistorySize != 1 {
+		t.Errorf("HistorySize = %d, want 1", stats.HistorySize)
+	}
+	if stats.ProcessedCount != 2 {
+		t.Errorf("ProcessedCount = %d, want 2", stats.ProcessedCount)
+	}
+	if latest := repo.GetLatestProcessedImage(); latest != second.ProcessedImage {
+		t.Error("latest result is not the second run")
+	}
+}
+
+func TestStoreProcessedImageStoresNewResults(t *testing.T) {
+	repo := NewImageRepository()
+
+	repo.StoreProcessedImage(&ProcessingResult{ProcessedImage: &ImageData{}, Algorithm: "2D Otsu"})
+
+	if got := repo.GetImageStats().HistorySize; got != 1 {
+		t.Errorf("HistorySize = %d, want 1", got)
+	}
+}
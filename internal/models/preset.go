@@ -0,0 +1,215 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Preset is a named parameter set for one algorithm
+type Preset struct {
+	Name       string
+	Algorithm  string
+	SavedAt    time.Time
+	Parameters map[string]interface{}
+}
+
+// PresetInfo describes a saved preset without its parameters
+type PresetInfo struct {
+	Name      string
+	Algorithm string
+	SavedAt   time.Time
+}
+
+// presetFile is the JSON layout of a preset file; parameters keep their Go
+// types as in the session file
+type presetFile struct {
+	Name       string                  `json:"name"`
+	Algorithm  string                  `json:"algorithm"`
+	SavedAt    time.Time               `json:"saved_at"`
+	Parameters map[string]SessionValue `json:"parameters"`
+}
+
+// PresetManager stores presets as one JSON file each in a directory
+type PresetManager struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewPresetManager creates a manager using the presets folder of the platform
+// config directory
+func NewPresetManager(appDirName string) (*PresetManager, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate config directory: %w", err)
+	}
+
+	return NewPresetManagerWithDir(filepath.Join(configDir, appDirName, "presets")), nil
+}
+
+// NewPresetManagerWithDir creates a manager for an explicit directory
+func NewPresetManagerWithDir(dir string) *PresetManager {
+	return &PresetManager{dir: dir}
+}
+
+// Dir returns the presets directory
+func (pm *PresetManager) Dir() string {
+	return pm.dir
+}
+
+// SavePreset writes params for algorithm under name, replacing any preset
+// of that name
+func (pm *PresetManager) SavePreset(name, algorithm string, params map[string]interface{}) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("preset name is empty")
+	}
+
+	file := presetFile{
+		Name:       name,
+		Algorithm:  algorithm,
+		SavedAt:    time.Now(),
+		Parameters: make(map[string]SessionValue, len(params)),
+	}
+	for key, value := range params {
+		sv, err := NewSessionValue(value)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", key, err)
+		}
+		file.Parameters[key] = sv
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode preset: %w", err)
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if err := os.MkdirAll(pm.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create presets directory: %w", err)
+	}
+
+	path := pm.path(name)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write preset: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace preset file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadPreset reads the preset saved under name
+func (pm *PresetManager) LoadPreset(name string) (*Preset, error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	file, err := pm.read(pm.path(strings.TrimSpace(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no preset named %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	preset := &Preset{
+		Name:       file.Name,
+		Algorithm:  file.Algorithm,
+		SavedAt:    file.SavedAt,
+		Parameters: make(map[string]interface{}, len(file.Parameters)),
+	}
+	for key, sv := range file.Parameters {
+		value, err := sv.Decode()
+		if err != nil {
+			return nil, fmt.Errorf("preset %s parameter %s: %w", name, key, err)
+		}
+		preset.Parameters[key] = value
+	}
+
+	return preset, nil
+}
+
+// ListPresets returns the saved presets sorted by name. Unreadable files are
+// left out.
+func (pm *PresetManager) ListPresets() []PresetInfo {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	paths, err := filepath.Glob(filepath.Join(pm.dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+
+	var presets []PresetInfo
+	for _, path := range paths {
+		file, err := pm.read(path)
+		if err != nil {
+			continue
+		}
+		presets = append(presets, PresetInfo{
+			Name:      file.Name,
+			Algorithm: file.Algorithm,
+			SavedAt:   file.SavedAt,
+		})
+	}
+
+	slices.SortFunc(presets, func(a, b PresetInfo) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return presets
+}
+
+// DeletePreset removes the preset saved under name
+func (pm *PresetManager) DeletePreset(name string) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if err := os.Remove(pm.path(strings.TrimSpace(name))); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("no preset named %q", name)
+		}
+		return fmt.Errorf("failed to delete preset: %w", err)
+	}
+	return nil
+}
+
+// read decodes the preset file at path
+func (pm *PresetManager) read(path string) (*presetFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset: %w", err)
+	}
+
+	var file presetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode preset %s: %w", filepath.Base(path), err)
+	}
+	return &file, nil
+}
+
+// path returns the file of the preset called name. Characters that are not
+// safe in file names on every platform are written as %XX.
+func (pm *PresetManager) path(name string) string {
+	var b strings.Builder
+	for _, c := range []byte(name) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == ' ', c == '-', c == '_':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return filepath.Join(pm.dir, b.String()+".json")
+}
@@ -2,8 +2,14 @@ package models
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"otsu-obliterator/internal/processing"
+
+	"fyne.io/fyne/v2"
 )
 
 // ProcessingState represents the current state of image processing
@@ -14,25 +20,78 @@ type ProcessingState struct {
 	Progress          float64
 	StartTime         time.Time
 	EstimatedDuration time.Duration
+	ETA               time.Duration // Time remaining, -1 until progress is reported
 	CancellationToken CancellationToken
 }
 
+// EstimateRemaining extrapolates the time left from the time elapsed and the
+// fraction complete, assuming the rest runs at the same rate. It returns -1
+// while nothing is complete.
+func EstimateRemaining(elapsed time.Duration, progress float64) time.Duration {
+	if progress <= 0 {
+		return -1
+	}
+	if progress >= 1 {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * (1 - progress) / progress)
+}
+
 // CancellationToken provides a way to cancel ongoing processing
 type CancellationToken struct {
 	cancelled bool
+	done      chan struct{}
+	closeDone *sync.Once
 	mu        sync.RWMutex
 }
 
 // NewCancellationToken creates a new cancellation token
 func NewCancellationToken() *CancellationToken {
-	return &CancellationToken{}
+	return &CancellationToken{
+		done:      make(chan struct{}),
+		closeDone: &sync.Once{},
+	}
 }
 
-// Cancel marks the token as cancelled
+// Cancel marks the token as cancelled and closes its Done channel
 func (ct *CancellationToken) Cancel() {
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
 	ct.cancelled = true
+	ct.closeLocked()
+}
+
+// finish closes the Done channel of a run that ended without being
+// cancelled; IsCancelled stays false
+func (ct *CancellationToken) finish() {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.closeLocked()
+}
+
+// closeLocked closes the Done channel once. Callers must hold the write lock.
+func (ct *CancellationToken) closeLocked() {
+	done := ct.doneLocked()
+	ct.closeDone.Do(func() { close(done) })
+}
+
+// Done returns a channel closed when the token is cancelled or its run ends,
+// for use in select statements instead of polling IsCancelled. After Reset,
+// Done returns a new channel; channels obtained earlier stay closed.
+func (ct *CancellationToken) Done() <-chan struct{} {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.doneLocked()
+}
+
+// doneLocked creates the Done channel of a zero token. Callers must hold the
+// write lock.
+func (ct *CancellationToken) doneLocked() chan struct{} {
+	if ct.done == nil {
+		ct.done = make(chan struct{})
+		ct.closeDone = &sync.Once{}
+	}
+	return ct.done
 }
 
 // IsCancelled returns true if the token has been cancelled
@@ -42,11 +101,13 @@ func (ct *CancellationToken) IsCancelled() bool {
 	return ct.cancelled
 }
 
-// Reset clears the cancellation state
+// Reset clears the cancellation state and gives the token a new Done channel
 func (ct *CancellationToken) Reset() {
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
 	ct.cancelled = false
+	ct.done = make(chan struct{})
+	ct.closeDone = &sync.Once{}
 }
 
 // AlgorithmParameters contains algorithm-specific configuration
@@ -72,30 +133,47 @@ type ProcessingConfiguration struct {
 	algorithmParameters map[string]AlgorithmParameters
 	globalSettings      map[string]interface{}
 	performanceSettings PerformanceSettings
+	preferences         fyne.Preferences
 }
 
 // PerformanceSettings contains performance-related configuration
 type PerformanceSettings struct {
-	MaxWorkers            int
-	MemoryLimit           int64
-	EnableParallelization bool
-	UseGPUAcceleration    bool
-	CacheSize             int
-	GCThreshold           float64
+	MaxWorkers             int     `yaml:"max_workers"`
+	MemoryLimit            int64   `yaml:"memory_limit"`
+	EnableParallelization  bool    `yaml:"enable_parallelization"`
+	UseGPUAcceleration     bool    `yaml:"use_gpu_acceleration"`
+	CacheSize              int     `yaml:"cache_size"`
+	GCThreshold            float64 `yaml:"gc_threshold"`
+	TileSize               int     `yaml:"tile_size"`                // Edge length of tiles for very large images, 0 disables tiling
+	SeamWidth              int     `yaml:"seam_width"`               // Width of the strip re-processed on tile boundaries, 0 uses the window size
+	MaxProcessingDimension int     `yaml:"max_processing_dimension"` // Longest edge processed before downscaling, 0 disables downscaling
+	FullResolution         bool    `yaml:"full_resolution"`          // Process at original size regardless of MaxProcessingDimension
+	QueueCapacity          int     `yaml:"queue_capacity"`           // Runs that may wait while one is processing, 0 disables queueing
 }
 
 // NewProcessingConfiguration creates a new processing configuration
 func NewProcessingConfiguration() *ProcessingConfiguration {
+	return NewProcessingConfigurationWithPreferences(nil)
+}
+
+// NewProcessingConfigurationWithPreferences creates a processing configuration
+// whose global settings are loaded from and saved to prefs. A nil prefs keeps
+// settings in memory only.
+func NewProcessingConfigurationWithPreferences(prefs fyne.Preferences) *ProcessingConfiguration {
 	config := &ProcessingConfiguration{
+		preferences:         prefs,
 		algorithmParameters: make(map[string]AlgorithmParameters),
 		globalSettings:      make(map[string]interface{}),
 		performanceSettings: PerformanceSettings{
-			MaxWorkers:            4,
-			MemoryLimit:           4 * 1024 * 1024 * 1024, // 4GB
-			EnableParallelization: true,
-			UseGPUAcceleration:    false,
-			CacheSize:             100,
-			GCThreshold:           0.8,
+			MaxWorkers:             4,
+			MemoryLimit:            4 * 1024 * 1024 * 1024, // 4GB
+			EnableParallelization:  true,
+			UseGPUAcceleration:     false,
+			CacheSize:              100,
+			GCThreshold:            0.8,
+			TileSize:               2048,
+			MaxProcessingDimension: 2048,
+			QueueCapacity:          4,
 		},
 	}
 
@@ -111,41 +189,60 @@ func (pc *ProcessingConfiguration) initializeDefaultAlgorithms() {
 	pc.algorithmParameters["2D Otsu"] = AlgorithmParameters{
 		Name: "2D Otsu",
 		Parameters: map[string]interface{}{
-			"window_size":            7,
-			"histogram_bins":         0,
-			"smoothing_strength":     1.0,
-			"noise_robustness":       true,
-			"gaussian_preprocessing": true,
-			"use_clahe":              false,
-			"clahe_clip_limit":       3.0,
-			"clahe_tile_size":        8,
-			"guided_filtering":       false,
-			"guided_radius":          4,
-			"guided_epsilon":         0.05,
-			"parallel_processing":    true,
+			"window_size":               7,
+			"adaptive_window_size":      false,
+			"multi_scale":               false,
+			"histogram_bins":            0,
+			"smoothing_strength":        1.0,
+			"noise_robustness":          true,
+			"gaussian_preprocessing":    true,
+			"apply_global_equalization": false,
+			"use_clahe":                 false,
+			"clahe_clip_limit":          3.0,
+			"clahe_tile_size":           8,
+			"apply_unsharp":             false,
+			"unsharp_strength":          0.5,
+			"unsharp_radius":            2,
+			"guided_filtering":          false,
+			"guided_radius":             4,
+			"guided_epsilon":            0.05,
+			"parallel_processing":       true,
+			"channel_selection":         "luminance",
+			"weight_map_path":           "",
 		},
 		Defaults: map[string]interface{}{
-			"window_size":            7,
-			"histogram_bins":         0,
-			"smoothing_strength":     1.0,
-			"noise_robustness":       true,
-			"gaussian_preprocessing": true,
-			"use_clahe":              false,
-			"clahe_clip_limit":       3.0,
-			"clahe_tile_size":        8,
-			"guided_filtering":       false,
-			"guided_radius":          4,
-			"guided_epsilon":         0.05,
-			"parallel_processing":    true,
+			"window_size":               7,
+			"adaptive_window_size":      false,
+			"multi_scale":               false,
+			"histogram_bins":            0,
+			"smoothing_strength":        1.0,
+			"noise_robustness":          true,
+			"gaussian_preprocessing":    true,
+			"apply_global_equalization": false,
+			"use_clahe":                 false,
+			"clahe_clip_limit":          3.0,
+			"clahe_tile_size":           8,
+			"apply_unsharp":             false,
+			"unsharp_strength":          0.5,
+			"unsharp_radius":            2,
+			"guided_filtering":          false,
+			"guided_radius":             4,
+			"guided_epsilon":            0.05,
+			"parallel_processing":       true,
+			"channel_selection":         "luminance",
+			"weight_map_path":           "",
 		},
 		Ranges: map[string]ParameterRange{
 			"window_size":        {Min: 3, Max: 21, Step: 2},
 			"histogram_bins":     {Min: 0, Max: 256, Step: 1},
 			"smoothing_strength": {Min: 0.0, Max: 5.0, Step: 0.1},
-			"clahe_clip_limit":   {Min: 1.0, Max: 10.0, Step: 0.1},
+			"clahe_clip_limit":   {Min: 0.0, Max: 10.0, Step: 0.1}, // 0 selects the limit automatically
 			"clahe_tile_size":    {Min: 4, Max: 16, Step: 2},
+			"unsharp_strength":   {Min: 0.0, Max: 2.0, Step: 0.1},
+			"unsharp_radius":     {Min: 1, Max: 5, Step: 1},
 			"guided_radius":      {Min: 1, Max: 10, Step: 1},
 			"guided_epsilon":     {Min: 0.01, Max: 1.0, Step: 0.01},
+			"channel_selection":  {Options: []interface{}{"luminance", "red", "green", "blue", "hue", "saturation"}},
 		},
 	}
 
@@ -153,65 +250,216 @@ func (pc *ProcessingConfiguration) initializeDefaultAlgorithms() {
 	pc.algorithmParameters["Iterative Triclass"] = AlgorithmParameters{
 		Name: "Iterative Triclass",
 		Parameters: map[string]interface{}{
-			"initial_threshold_method": "otsu",
-			"histogram_bins":           0,
-			"convergence_precision":    1.0,
-			"max_iterations":           8,
-			"minimum_tbd_fraction":     0.01,
-			"class_separation":         0.5,
-			"preprocessing":            true,
-			"result_cleanup":           true,
-			"preserve_borders":         false,
-			"noise_robustness":         true,
-			"guided_filtering":         true,
-			"guided_radius":            6,
-			"guided_epsilon":           0.15,
-			"parallel_processing":      true,
+			"initial_threshold_method":  "otsu",
+			"histogram_bins":            0,
+			"convergence_precision":     1.0,
+			"max_iterations":            8,
+			"minimum_tbd_fraction":      0.01,
+			"class_separation":          0.5,
+			"preprocessing":             true,
+			"result_cleanup":            true,
+			"preserve_borders":          false,
+			"apply_global_equalization": false,
+			"noise_robustness":          true,
+			"apply_unsharp":             false,
+			"unsharp_strength":          0.5,
+			"unsharp_radius":            2,
+			"guided_filtering":          true,
+			"guided_radius":             6,
+			"guided_epsilon":            0.15,
+			"parallel_processing":       true,
+			"cleanup_kernel_small":      3,
+			"cleanup_kernel_large":      5,
+			"cleanup_iterations":        1,
+			"adaptive_cleanup_kernels":  false,
+			"min_object_area":           0,
+			"min_object_area_fraction":  0.001,
+			"max_object_area":           0,
+			"channel_selection":         "luminance",
+			"use_graph_cut":             false,
+			"apply_watershed":           false,
+			"watershed_min_distance":    5,
+			"pyramid_levels":            0,
 		},
 		Defaults: map[string]interface{}{
-			"initial_threshold_method": "otsu",
-			"histogram_bins":           0,
-			"convergence_precision":    1.0,
-			"max_iterations":           8,
-			"minimum_tbd_fraction":     0.01,
-			"class_separation":         0.5,
-			"preprocessing":            true,
-			"result_cleanup":           true,
-			"preserve_borders":         false,
-			"noise_robustness":         true,
-			"guided_filtering":         true,
-			"guided_radius":            6,
-			"guided_epsilon":           0.15,
-			"parallel_processing":      true,
+			"initial_threshold_method":  "otsu",
+			"histogram_bins":            0,
+			"convergence_precision":     1.0,
+			"max_iterations":            8,
+			"minimum_tbd_fraction":      0.01,
+			"class_separation":          0.5,
+			"preprocessing":             true,
+			"result_cleanup":            true,
+			"preserve_borders":          false,
+			"apply_global_equalization": false,
+			"noise_robustness":          true,
+			"apply_unsharp":             false,
+			"unsharp_strength":          0.5,
+			"unsharp_radius":            2,
+			"guided_filtering":          true,
+			"guided_radius":             6,
+			"guided_epsilon":            0.15,
+			"parallel_processing":       true,
+			"cleanup_kernel_small":      3,
+			"cleanup_kernel_large":      5,
+			"cleanup_iterations":        1,
+			"adaptive_cleanup_kernels":  false,
+			"min_object_area":           0,
+			"min_object_area_fraction":  0.001,
+			"max_object_area":           0,
+			"channel_selection":         "luminance",
+			"use_graph_cut":             false,
+			"apply_watershed":           false,
+			"watershed_min_distance":    5,
+			"pyramid_levels":            0,
 		},
 		Ranges: map[string]ParameterRange{
 			"initial_threshold_method": {Options: []interface{}{"otsu", "mean", "median", "triangle"}},
 			"histogram_bins":           {Min: 0, Max: 256, Step: 1},
-			"convergence_precision":    {Min: 0.5, Max: 2.0, Step: 0.1},
+			"convergence_precision":    {Min: 0.0, Max: 2.0, Step: 0.1}, // 0 derives it from the dynamic range
 			"max_iterations":           {Min: 3, Max: 15, Step: 1},
 			"minimum_tbd_fraction":     {Min: 0.001, Max: 0.1, Step: 0.001},
 			"class_separation":         {Min: 0.1, Max: 0.8, Step: 0.05},
+			"unsharp_strength":         {Min: 0.0, Max: 2.0, Step: 0.1},
+			"unsharp_radius":           {Min: 1, Max: 5, Step: 1},
 			"guided_radius":            {Min: 1, Max: 12, Step: 1},
 			"guided_epsilon":           {Min: 0.01, Max: 1.0, Step: 0.01},
+			"cleanup_kernel_small":     {Min: 1, Max: 9, Step: 2},
+			"cleanup_kernel_large":     {Min: 3, Max: 15, Step: 2},
+			"cleanup_iterations":       {Min: 1, Max: 5, Step: 1},
+			"min_object_area_fraction": {Min: 0.0, Max: 0.05, Step: 0.0005},
+			"watershed_min_distance":   {Min: 1, Max: 50, Step: 1},
+			"pyramid_levels":           {Min: 0, Max: 6, Step: 1},
+			"channel_selection":        {Options: []interface{}{"luminance", "red", "green", "blue", "hue", "saturation"}},
+		},
+	}
+
+	// Ridler-Calvard algorithm parameters
+	pc.algorithmParameters["Ridler-Calvard"] = AlgorithmParameters{
+		Name: "Ridler-Calvard",
+		Parameters: map[string]interface{}{
+			"convergence_epsilon":    0.5,
+			"max_iterations":         100,
+			"gaussian_preprocessing": false,
+			"result_cleanup":         false,
+			"pyramid_levels":         0,
+		},
+		Defaults: map[string]interface{}{
+			"convergence_epsilon":    0.5,
+			"max_iterations":         100,
+			"gaussian_preprocessing": false,
+			"result_cleanup":         false,
+			"pyramid_levels":         0,
+		},
+		Ranges: map[string]ParameterRange{
+			"convergence_epsilon": {Min: 0.01, Max: 10.0, Step: 0.01},
+			"max_iterations":      {Min: 1, Max: 1000, Step: 1},
+			"pyramid_levels":      {Min: 0, Max: 6, Step: 1},
+		},
+	}
+
+	// Minimum Cross-Entropy algorithm parameters
+	pc.algorithmParameters["Minimum Cross-Entropy"] = AlgorithmParameters{
+		Name: "Minimum Cross-Entropy",
+		Parameters: map[string]interface{}{
+			"histogram_bins": 256,
+			"apply_clahe":    false,
+			"max_iterations": 50,
+			"quality":        "Best",
+		},
+		Defaults: map[string]interface{}{
+			"histogram_bins": 256,
+			"apply_clahe":    false,
+			"max_iterations": 50,
+			"quality":        "Best",
+		},
+		Ranges: map[string]ParameterRange{
+			"histogram_bins": {Min: 8, Max: 256, Step: 1},
+			"max_iterations": {Min: 1, Max: 500, Step: 1},
+			"quality":        {Options: []interface{}{"Fast", "Best"}},
+		},
+	}
+
+	// Multi-Level Otsu algorithm parameters
+	pc.algorithmParameters["Multi-Level Otsu"] = AlgorithmParameters{
+		Name: "Multi-Level Otsu",
+		Parameters: map[string]interface{}{
+			"num_classes": 3,
+		},
+		Defaults: map[string]interface{}{
+			"num_classes": 3,
+		},
+		Ranges: map[string]ParameterRange{
+			"num_classes": {Min: 2, Max: 5, Step: 1},
+		},
+	}
+
+	// HSV Otsu algorithm parameters
+	pc.algorithmParameters["HSV Otsu"] = AlgorithmParameters{
+		Name: "HSV Otsu",
+		Parameters: map[string]interface{}{
+			"color_threshold_channel": "S",
+			"lower_hue":               0,
+			"upper_hue":               179,
+		},
+		Defaults: map[string]interface{}{
+			"color_threshold_channel": "S",
+			"lower_hue":               0,
+			"upper_hue":               179,
+		},
+		Ranges: map[string]ParameterRange{
+			"color_threshold_channel": {Options: []interface{}{"H", "S", "V"}},
+			"lower_hue":               {Min: 0, Max: 179, Step: 1},
+			"upper_hue":               {Min: 0, Max: 179, Step: 1},
 		},
 	}
 
 	pc.currentAlgorithm = "2D Otsu"
 }
 
-// initializeGlobalSettings sets up global application settings
+// defaultGlobalSettings returns the built-in value of every global setting
+func defaultGlobalSettings() map[string]interface{} {
+	return map[string]interface{}{
+		"auto_preview":               true,
+		"save_processing_log":        true,
+		"show_debug_info":            false,
+		"default_save_format":        "png",
+		"jpeg_quality":               95,
+		"webp_quality":               85,
+		"enable_undo":                true,
+		"max_undo_levels":            5,
+		"max_history_entries":        10,
+		"ui_theme":                   "auto",
+		"ui_scale":                   1.0,
+		"memory_limit_gib":           4.0,
+		"show_recommendations":       true,
+		"filename_parameters":        true,
+		"burn_annotations":           false,
+		"auto_save":                  false,
+		"auto_save_interval_seconds": 30,
+		"enable_dp":                  false,
+		"dp_epsilon":                 1.0,
+		"random_seed":                0,
+	}
+}
+
+// initializeGlobalSettings sets up global application settings, preferring
+// values saved in the preferences store over the defaults
 func (pc *ProcessingConfiguration) initializeGlobalSettings() {
-	pc.globalSettings = map[string]interface{}{
-		"auto_preview":        true,
-		"save_processing_log": true,
-		"show_debug_info":     false,
-		"default_save_format": "png",
-		"jpeg_quality":        95,
-		"enable_undo":         true,
-		"max_undo_levels":     5,
-		"ui_theme":            "auto",
-		"ui_scale":            1.0,
+	pc.globalSettings = defaultGlobalSettings()
+	if pc.preferences == nil {
+		return
+	}
+
+	for key, fallback := range pc.globalSettings {
+		value := loadPreference(pc.preferences, key, fallback)
+		if key == "default_save_format" && !IsSupportedSaveFormat(value.(string)) {
+			continue
+		}
+		pc.globalSettings[key] = value
 	}
+	pc.applyMemoryLimit()
+	pc.applyRandomSeed()
 }
 
 // GetCurrentAlgorithm returns the currently selected algorithm
@@ -263,6 +511,19 @@ func (pc *ProcessingConfiguration) SetAlgorithmParameter(algorithm, paramName st
 		return err
 	}
 
+	if paramName == "cleanup_kernel_small" || paramName == "cleanup_kernel_large" {
+		small := params.Parameters["cleanup_kernel_small"]
+		large := params.Parameters["cleanup_kernel_large"]
+		if paramName == "cleanup_kernel_small" {
+			small = value
+		} else {
+			large = value
+		}
+		if err := validateCleanupKernels(small, large); err != nil {
+			return err
+		}
+	}
+
 	// Update parameter
 	params.Parameters[paramName] = value
 	pc.algorithmParameters[algorithm] = params
@@ -270,6 +531,30 @@ func (pc *ProcessingConfiguration) SetAlgorithmParameter(algorithm, paramName st
 	return nil
 }
 
+// AddAlgorithm registers parameters for an algorithm added at runtime, such as a plugin
+func (pc *ProcessingConfiguration) AddAlgorithm(name string, defaults map[string]interface{}) error {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if _, exists := pc.algorithmParameters[name]; exists {
+		return NewValidationError("algorithm", name, "algorithm already exists")
+	}
+
+	params := AlgorithmParameters{
+		Name:       name,
+		Parameters: make(map[string]interface{}, len(defaults)),
+		Defaults:   make(map[string]interface{}, len(defaults)),
+		Ranges:     make(map[string]ParameterRange),
+	}
+	for key, value := range defaults {
+		params.Parameters[key] = value
+		params.Defaults[key] = value
+	}
+
+	pc.algorithmParameters[name] = params
+	return nil
+}
+
 // GetAvailableAlgorithms returns list of available algorithms
 func (pc *ProcessingConfiguration) GetAvailableAlgorithms() []string {
 	pc.mu.RLock()
@@ -294,9 +579,143 @@ func (pc *ProcessingConfiguration) GetGlobalSetting(key string) (interface{}, bo
 
 // SetGlobalSetting updates a global setting
 func (pc *ProcessingConfiguration) SetGlobalSetting(key string, value interface{}) {
+	// Unsupported save formats are ignored so the previous choice stays valid
+	if key == "default_save_format" {
+		if format, ok := value.(string); !ok || !IsSupportedSaveFormat(format) {
+			return
+		}
+	}
+
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
 	pc.globalSettings[key] = value
+
+	if key == "memory_limit_gib" {
+		pc.applyMemoryLimit()
+	}
+	if key == "random_seed" {
+		pc.applyRandomSeed()
+	}
+	if pc.preferences != nil {
+		storePreference(pc.preferences, key, value)
+	}
+}
+
+// GetGlobalSettings returns a copy of all global settings
+func (pc *ProcessingConfiguration) GetGlobalSettings() map[string]interface{} {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	settings := make(map[string]interface{}, len(pc.globalSettings))
+	for key, value := range pc.globalSettings {
+		settings[key] = value
+	}
+	return settings
+}
+
+// ResetGlobalSettings restores the defaults and removes saved preferences
+func (pc *ProcessingConfiguration) ResetGlobalSettings() {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	pc.globalSettings = defaultGlobalSettings()
+	pc.applyMemoryLimit()
+	pc.applyRandomSeed()
+
+	if pc.preferences != nil {
+		for key := range pc.globalSettings {
+			pc.preferences.RemoveValue(key)
+		}
+	}
+}
+
+// applyMemoryLimit copies memory_limit_gib into the performance settings.
+// Callers must hold pc.mu.
+func (pc *ProcessingConfiguration) applyMemoryLimit() {
+	if gib, ok := pc.globalSettings["memory_limit_gib"].(float64); ok && gib > 0 {
+		pc.performanceSettings.MemoryLimit = int64(gib * 1024 * 1024 * 1024)
+	}
+}
+
+// SetSeed fixes the seed of the random source shared by stochastic
+// processing steps so their results repeat across runs; 0 seeds it from the
+// operating system again
+func (pc *ProcessingConfiguration) SetSeed(seed int64) {
+	pc.SetGlobalSetting("random_seed", int(seed))
+}
+
+// applyRandomSeed reseeds processing.RNG from random_seed. Callers must hold
+// pc.mu.
+func (pc *ProcessingConfiguration) applyRandomSeed() {
+	seed, _ := pc.globalSettings["random_seed"].(int)
+	processing.RNG.SetSeed(int64(seed))
+}
+
+// loadPreference reads key from prefs using the type of fallback
+func loadPreference(prefs fyne.Preferences, key string, fallback interface{}) interface{} {
+	switch v := fallback.(type) {
+	case bool:
+		return prefs.BoolWithFallback(key, v)
+	case int:
+		return prefs.IntWithFallback(key, v)
+	case float64:
+		return prefs.FloatWithFallback(key, v)
+	case string:
+		return prefs.StringWithFallback(key, v)
+	default:
+		return fallback
+	}
+}
+
+// storePreference writes a setting value to prefs, ignoring unsupported types
+func storePreference(prefs fyne.Preferences, key string, value interface{}) {
+	switch v := value.(type) {
+	case bool:
+		prefs.SetBool(key, v)
+	case int:
+		prefs.SetInt(key, v)
+	case float64:
+		prefs.SetFloat(key, v)
+	case string:
+		prefs.SetString(key, v)
+	}
+}
+
+// SupportedSaveFormats lists the formats accepted for default_save_format
+var SupportedSaveFormats = []string{"png", "jpeg", "webp"}
+
+// OpenFormatExtensions lists the file extensions offered in open dialogs
+var OpenFormatExtensions = []string{".png", ".jpg", ".jpeg", ".bmp", ".tif", ".tiff", ".webp", ".dcm"}
+
+// SaveFormatExtensions lists the file extensions offered in save dialogs
+var SaveFormatExtensions = []string{".png", ".jpg", ".jpeg", ".webp"}
+
+// SaveFormatExtension returns the file extension written for a save format
+func SaveFormatExtension(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// ProcessedFileName suggests a file name for the result of processing
+// originalName, "scan.tif" becoming "scan_processed.png" for the png format
+func ProcessedFileName(originalName, format string) string {
+	base := strings.TrimSuffix(originalName, filepath.Ext(originalName))
+	if base == "" {
+		base = "image"
+	}
+	return base + "_processed" + SaveFormatExtension(format)
+}
+
+// IsSupportedSaveFormat returns true if format can be used as the default save format
+func IsSupportedSaveFormat(format string) bool {
+	for _, supported := range SupportedSaveFormats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
 }
 
 // GetPerformanceSettings returns current performance settings
@@ -313,6 +732,37 @@ func (pc *ProcessingConfiguration) UpdatePerformanceSettings(settings Performanc
 	pc.performanceSettings = settings
 }
 
+// ApplyConfiguration takes the current algorithm, the parameters of every
+// algorithm both configurations know and the performance settings from src.
+// Algorithms only pc knows, such as plugins, keep their parameters.
+func (pc *ProcessingConfiguration) ApplyConfiguration(src *ProcessingConfiguration) {
+	src.mu.RLock()
+	algorithm := src.currentAlgorithm
+	performance := src.performanceSettings
+	imported := make(map[string]AlgorithmParameters, len(src.algorithmParameters))
+	for name, params := range src.algorithmParameters {
+		imported[name] = src.copyAlgorithmParameters(params)
+	}
+	src.mu.RUnlock()
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	for name, params := range imported {
+		current, exists := pc.algorithmParameters[name]
+		if !exists {
+			continue
+		}
+		for key, value := range params.Parameters {
+			current.Parameters[key] = value
+		}
+	}
+	if _, exists := pc.algorithmParameters[algorithm]; exists {
+		pc.currentAlgorithm = algorithm
+	}
+	pc.performanceSettings = performance
+}
+
 // ResetAlgorithmToDefaults resets algorithm parameters to default values
 func (pc *ProcessingConfiguration) ResetAlgorithmToDefaults(algorithm string) error {
 	pc.mu.Lock()
@@ -378,6 +828,33 @@ func (pc *ProcessingConfiguration) validateParameter(params AlgorithmParameters,
 	return nil
 }
 
+// validateCleanupKernels checks that both morphological cleanup kernels are
+// odd and the small kernel is smaller, reporting every problem in one error
+func validateCleanupKernels(smallValue, largeValue interface{}) error {
+	small, smallOK := smallValue.(int)
+	large, largeOK := largeValue.(int)
+	if !smallOK || !largeOK {
+		return nil
+	}
+
+	var problems []string
+	if small%2 == 0 {
+		problems = append(problems, "cleanup_kernel_small must be odd")
+	}
+	if large%2 == 0 {
+		problems = append(problems, "cleanup_kernel_large must be odd")
+	}
+	if small >= large {
+		problems = append(problems, "cleanup_kernel_small must be less than cleanup_kernel_large")
+	}
+
+	if len(problems) > 0 {
+		return NewValidationError("cleanup_kernel_small/cleanup_kernel_large",
+			fmt.Sprintf("%d/%d", small, large), strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 // copyAlgorithmParameters creates a deep copy of algorithm parameters
 func (pc *ProcessingConfiguration) copyAlgorithmParameters(src AlgorithmParameters) AlgorithmParameters {
 	dst := AlgorithmParameters{
@@ -427,10 +904,29 @@ func (ve *ValidationError) Error() string {
 		ve.Parameter, ve.Value, ve.Message)
 }
 
+// RunOutcome is how a processing run ended
+type RunOutcome string
+
+const (
+	RunCompleted RunOutcome = "completed"
+	RunFailed    RunOutcome = "failed"
+	RunCancelled RunOutcome = "cancelled"
+)
+
+// RunEvent reports the end of a processing run
+type RunEvent struct {
+	Algorithm string
+	Outcome   RunOutcome
+	Duration  time.Duration
+}
+
 // ProcessingStateRepository manages processing state
 type ProcessingStateRepository struct {
-	mu    sync.RWMutex
-	state ProcessingState
+	mu           sync.RWMutex
+	state        ProcessingState
+	subscribers  map[chan struct{}]struct{}
+	runListeners map[int]func(RunEvent)
+	nextListener int
 }
 
 // NewProcessingStateRepository creates a new processing state repository
@@ -440,6 +936,57 @@ func NewProcessingStateRepository() *ProcessingStateRepository {
 			IsActive:          false,
 			CancellationToken: *NewCancellationToken(),
 		},
+		subscribers:  make(map[chan struct{}]struct{}),
+		runListeners: make(map[int]func(RunEvent)),
+	}
+}
+
+// AddRunListener calls listener once for every run that ends, with its
+// outcome, and returns a function that removes it. Unlike Subscribe no run is
+// missed however quickly runs follow each other. Listeners are called on the
+// goroutine ending the run and must not block.
+func (psr *ProcessingStateRepository) AddRunListener(listener func(RunEvent)) func() {
+	psr.mu.Lock()
+	id := psr.nextListener
+	psr.nextListener++
+	psr.runListeners[id] = listener
+	psr.mu.Unlock()
+
+	return func() {
+		psr.mu.Lock()
+		delete(psr.runListeners, id)
+		psr.mu.Unlock()
+	}
+}
+
+// Subscribe returns a channel signalled after every state change and a
+// function that ends the subscription. Signals are coalesced, so a slow
+// subscriber reads the latest state with GetState instead of every change.
+func (psr *ProcessingStateRepository) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	psr.mu.Lock()
+	psr.subscribers[ch] = struct{}{}
+	psr.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			psr.mu.Lock()
+			delete(psr.subscribers, ch)
+			psr.mu.Unlock()
+		})
+	}
+}
+
+// notifySubscribers signals every subscriber without blocking.
+// Callers must hold the write lock.
+func (psr *ProcessingStateRepository) notifySubscribers() {
+	for ch := range psr.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
 	}
 }
 
@@ -462,8 +1009,10 @@ func (psr *ProcessingStateRepository) StartProcessing(algorithm string) {
 		Progress:          0.0,
 		StartTime:         time.Now(),
 		EstimatedDuration: 0,
+		ETA:               -1,
 		CancellationToken: *NewCancellationToken(),
 	}
+	psr.notifySubscribers()
 }
 
 // UpdateProgress updates processing progress and stage
@@ -480,28 +1029,78 @@ func (psr *ProcessingStateRepository) UpdateProgress(stage string, progress floa
 			elapsed := time.Since(psr.state.StartTime)
 			estimated := time.Duration(float64(elapsed) / progress)
 			psr.state.EstimatedDuration = estimated
+			psr.state.ETA = EstimateRemaining(elapsed, progress)
 		}
+		psr.notifySubscribers()
 	}
 }
 
-// CompleteProcessing marks processing as complete
+// CompleteProcessing marks the active run as complete. It does nothing once
+// the run was cancelled or failed, so it can be deferred.
 func (psr *ProcessingStateRepository) CompleteProcessing() {
 	psr.mu.Lock()
-	defer psr.mu.Unlock()
+	if !psr.state.IsActive {
+		psr.mu.Unlock()
+		return
+	}
 
 	psr.state.IsActive = false
 	psr.state.CurrentStage = "Complete"
 	psr.state.Progress = 1.0
+	psr.state.ETA = 0
+	psr.finishLocked(RunCompleted)
+}
+
+// FailProcessing marks the active run as failed
+func (psr *ProcessingStateRepository) FailProcessing() {
+	psr.mu.Lock()
+	if !psr.state.IsActive {
+		psr.mu.Unlock()
+		return
+	}
+
+	psr.state.IsActive = false
+	psr.state.CurrentStage = "Failed"
+	psr.finishLocked(RunFailed)
 }
 
 // CancelProcessing cancels ongoing processing
 func (psr *ProcessingStateRepository) CancelProcessing() {
 	psr.mu.Lock()
-	defer psr.mu.Unlock()
 
+	wasActive := psr.state.IsActive
 	psr.state.CancellationToken.Cancel()
 	psr.state.IsActive = false
 	psr.state.CurrentStage = "Cancelled"
+	if !wasActive {
+		psr.notifySubscribers()
+		psr.mu.Unlock()
+		return
+	}
+	psr.finishLocked(RunCancelled)
+}
+
+// finishLocked closes the run's Done channel, notifies subscribers that the
+// run ended, releases the write lock the caller holds and then calls the run
+// listeners
+func (psr *ProcessingStateRepository) finishLocked(outcome RunOutcome) {
+	psr.state.CancellationToken.finish()
+	psr.notifySubscribers()
+
+	event := RunEvent{
+		Algorithm: psr.state.Algorithm,
+		Outcome:   outcome,
+		Duration:  time.Since(psr.state.StartTime),
+	}
+	listeners := make([]func(RunEvent), 0, len(psr.runListeners))
+	for _, listener := range psr.runListeners {
+		listeners = append(listeners, listener)
+	}
+	psr.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
 }
 
 // IsProcessing returns true if processing is currently active
@@ -517,3 +1116,12 @@ func (psr *ProcessingStateRepository) GetCancellationToken() *CancellationToken
 	defer psr.mu.RUnlock()
 	return &psr.state.CancellationToken
 }
+
+// CancellationDone returns the Done channel of the current run's token,
+// closed when the run is cancelled or ends. StartProcessing replaces the
+// token, so take the channel after starting a run.
+func (psr *ProcessingStateRepository) CancellationDone() <-chan struct{} {
+	psr.mu.RLock()
+	defer psr.mu.RUnlock()
+	return psr.state.CancellationToken.Done()
+}
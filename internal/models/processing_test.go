@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+// maxCloseDelay is how soon after Cancel or the end of a run Done must close
+const maxCloseDelay = time.Millisecond
+
+// waitClosed waits for done and returns how long after the time sent on
+// started it closed
+func waitClosed(t *testing.T, done <-chan struct{}, started <-chan time.Time) time.Duration {
+	t.Helper()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Done was not closed")
+	}
+	return time.Since(<-started)
+}
+
+func TestCancellationTokenDoneClosesOnCancel(t *testing.T) {
+	token := NewCancellationToken()
+	done := token.Done()
+
+	started := make(chan time.Time, 1)
+	go func() {
+		started <- time.Now()
+		token.Cancel()
+	}()
+
+	if delay := waitClosed(t, done, started); delay > maxCloseDelay {
+		t.Errorf("Done closed %v after Cancel, want at most %v", delay, maxCloseDelay)
+	}
+	if !token.IsCancelled() {
+		t.Error("IsCancelled = false after Cancel")
+	}
+
+	// Cancelling twice must not close the channel again
+	token.Cancel()
+}
+
+func TestCancellationTokenResetOpensNewChannel(t *testing.T) {
+	token := NewCancellationToken()
+	cancelled := token.Done()
+	token.Cancel()
+	token.Reset()
+
+	select {
+	case <-cancelled:
+	default:
+		t.Error("channel taken before Reset was reopened")
+	}
+	select {
+	case <-token.Done():
+		t.Error("Done closed after Reset")
+	default:
+	}
+	if token.IsCancelled() {
+		t.Error("IsCancelled = true after Reset")
+	}
+}
+
+func TestCancellationDoneClosesWhenRunEnds(t *testing.T) {
+	for name, end := range map[string]func(*ProcessingStateRepository){
+		"complete": (*ProcessingStateRepository).CompleteProcessing,
+		"fail":     (*ProcessingStateRepository).FailProcessing,
+		"cancel":   (*ProcessingStateRepository).CancelProcessing,
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := NewProcessingStateRepository()
+			repo.StartProcessing("2D Otsu")
+			done := repo.CancellationDone()
+
+			started := make(chan time.Time, 1)
+			go func() {
+				started <- time.Now()
+				end(repo)
+			}()
+
+			if delay := waitClosed(t, done, started); delay > maxCloseDelay {
+				t.Errorf("Done closed %v after the run ended, want at most %v", delay, maxCloseDelay)
+			}
+			cancelled := repo.GetCancellationToken().IsCancelled()
+			if want := name == "cancel"; cancelled != want {
+				t.Errorf("IsCancelled = %v, want %v", cancelled, want)
+			}
+		})
+	}
+}
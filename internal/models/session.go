@@ -0,0 +1,234 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const sessionVersion = 1
+
+// Session captures the application state persisted between runs
+type Session struct {
+	Version             int                                `json:"version"`
+	SavedAt             time.Time                          `json:"saved_at"`
+	CurrentAlgorithm    string                             `json:"current_algorithm"`
+	AlgorithmParameters map[string]map[string]SessionValue `json:"algorithm_parameters"`
+	LastImagePath       string                             `json:"last_image_path,omitempty"`
+	ViewState           SessionViewState                   `json:"view_state"`
+}
+
+// SessionViewState holds the window layout persisted with the session
+type SessionViewState struct {
+	WindowWidth  float32 `json:"window_width"`
+	WindowHeight float32 `json:"window_height"`
+	Fullscreen   bool    `json:"fullscreen"`
+}
+
+// SessionValue stores a parameter together with its Go type so that ints,
+// floats, bools and strings survive the JSON round trip unchanged
+type SessionValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// NewSessionValue encodes a parameter value
+func NewSessionValue(value interface{}) (SessionValue, error) {
+	var valueType string
+	switch value.(type) {
+	case int:
+		valueType = "int"
+	case float64:
+		valueType = "float64"
+	case bool:
+		valueType = "bool"
+	case string:
+		valueType = "string"
+	default:
+		return SessionValue{}, fmt.Errorf("unsupported parameter type %T", value)
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return SessionValue{}, err
+	}
+
+	return SessionValue{Type: valueType, Value: raw}, nil
+}
+
+// Decode returns the parameter value with its original Go type
+func (sv SessionValue) Decode() (interface{}, error) {
+	switch sv.Type {
+	case "int":
+		var v int
+		err := json.Unmarshal(sv.Value, &v)
+		return v, err
+	case "float64":
+		var v float64
+		err := json.Unmarshal(sv.Value, &v)
+		return v, err
+	case "bool":
+		var v bool
+		err := json.Unmarshal(sv.Value, &v)
+		return v, err
+	case "string":
+		var v string
+		err := json.Unmarshal(sv.Value, &v)
+		return v, err
+	default:
+		return nil, fmt.Errorf("unknown parameter type %q", sv.Type)
+	}
+}
+
+// CaptureSession builds a session from the current processing configuration
+func CaptureSession(config *ProcessingConfiguration, lastImagePath string, viewState SessionViewState) *Session {
+	session := &Session{
+		Version:             sessionVersion,
+		SavedAt:             time.Now(),
+		CurrentAlgorithm:    config.GetCurrentAlgorithm(),
+		AlgorithmParameters: make(map[string]map[string]SessionValue),
+		LastImagePath:       lastImagePath,
+		ViewState:           viewState,
+	}
+
+	for _, algorithm := range config.GetAvailableAlgorithms() {
+		params, err := config.GetAlgorithmParameters(algorithm)
+		if err != nil {
+			continue
+		}
+
+		encoded := make(map[string]SessionValue, len(params.Parameters))
+		for name, value := range params.Parameters {
+			if sv, err := NewSessionValue(value); err == nil {
+				encoded[name] = sv
+			}
+		}
+		session.AlgorithmParameters[algorithm] = encoded
+	}
+
+	return session
+}
+
+// ApplyTo restores the session into the processing configuration. Algorithms and
+// parameters that are unknown, mistyped or out of range are skipped.
+func (s *Session) ApplyTo(config *ProcessingConfiguration) error {
+	var errs []error
+
+	for algorithm, params := range s.AlgorithmParameters {
+		current, err := config.GetAlgorithmParameters(algorithm)
+		if err != nil {
+			continue
+		}
+
+		for name, sv := range params {
+			defaultValue, known := current.Defaults[name]
+			if !known {
+				continue
+			}
+
+			value, err := sv.Decode()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", algorithm, name, err))
+				continue
+			}
+
+			if fmt.Sprintf("%T", value) != fmt.Sprintf("%T", defaultValue) {
+				continue
+			}
+
+			if err := config.SetAlgorithmParameter(algorithm, name, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s.%s: %w", algorithm, name, err))
+			}
+		}
+	}
+
+	if s.CurrentAlgorithm != "" {
+		if err := config.SetCurrentAlgorithm(s.CurrentAlgorithm); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SessionSerializer reads and writes the session file
+type SessionSerializer struct {
+	path string
+}
+
+// NewSessionSerializer creates a serializer using the platform config directory
+// (XDG_CONFIG_HOME on Linux, ~/Library/Application Support on macOS, %AppData% on Windows)
+func NewSessionSerializer(appDirName string) (*SessionSerializer, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate config directory: %w", err)
+	}
+
+	return NewSessionSerializerWithPath(filepath.Join(configDir, appDirName, "session.json")), nil
+}
+
+// NewSessionSerializerWithPath creates a serializer for an explicit file path
+func NewSessionSerializerWithPath(path string) *SessionSerializer {
+	return &SessionSerializer{path: path}
+}
+
+// Path returns the session file location
+func (ss *SessionSerializer) Path() string {
+	return ss.path
+}
+
+// Exists reports whether a session file is present
+func (ss *SessionSerializer) Exists() bool {
+	_, err := os.Stat(ss.path)
+	return err == nil
+}
+
+// Save writes the session atomically
+func (ss *SessionSerializer) Save(session *Session) error {
+	if err := os.MkdirAll(filepath.Dir(ss.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	tmpPath := ss.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, ss.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace session file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the session file. Unknown JSON keys are ignored.
+func (ss *SessionSerializer) Load() (*Session, error) {
+	data, err := os.ReadFile(ss.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %w", err)
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// Clear deletes the session file
+func (ss *SessionSerializer) Clear() error {
+	if err := os.Remove(ss.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
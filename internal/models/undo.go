@@ -0,0 +1,171 @@
+package models
+
+import (
+	"sync"
+)
+
+// UndoStack keeps snapshots of processed images so results can be stepped back
+// and forward. The most recent snapshot is the current state.
+type UndoStack struct {
+	mu          sync.Mutex
+	undo        []*ImageData
+	redo        []*ImageData
+	maxLevels   int
+	memoryLimit int64
+}
+
+// NewUndoStack creates an undo stack holding up to maxLevels snapshots
+func NewUndoStack(maxLevels int) *UndoStack {
+	if maxLevels < 1 {
+		maxLevels = 1
+	}
+
+	return &UndoStack{
+		undo:        make([]*ImageData, 0, maxLevels),
+		redo:        make([]*ImageData, 0),
+		maxLevels:   maxLevels,
+		memoryLimit: 512 * 1024 * 1024, // 512MB
+	}
+}
+
+// SetMaxLevels changes the number of snapshots kept, evicting the oldest if needed
+func (s *UndoStack) SetMaxLevels(maxLevels int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if maxLevels < 1 {
+		maxLevels = 1
+	}
+	s.maxLevels = maxLevels
+	s.evictLocked()
+}
+
+// SetMemoryLimit sets the estimated memory budget in bytes for all snapshots
+func (s *UndoStack) SetMemoryLimit(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.memoryLimit = limit
+	s.evictLocked()
+}
+
+// PushUndo records a new current state and discards any redo history
+func (s *UndoStack) PushUndo(img *ImageData) {
+	if img == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.undo = append(s.undo, img)
+	s.redo = s.redo[:0]
+	s.evictLocked()
+}
+
+// Undo steps back to the previous snapshot
+func (s *UndoStack) Undo() (*ImageData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.undo) < 2 {
+		return nil, false
+	}
+
+	current := s.undo[len(s.undo)-1]
+	s.undo = s.undo[:len(s.undo)-1]
+	s.redo = append(s.redo, current)
+
+	return s.undo[len(s.undo)-1], true
+}
+
+// Redo re-applies the most recently undone snapshot
+func (s *UndoStack) Redo() (*ImageData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.redo) == 0 {
+		return nil, false
+	}
+
+	next := s.redo[len(s.redo)-1]
+	s.redo = s.redo[:len(s.redo)-1]
+	s.undo = append(s.undo, next)
+
+	return next, true
+}
+
+// Current returns the snapshot at the undo/redo cursor
+func (s *UndoStack) Current() (*ImageData, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.undo) == 0 {
+		return nil, false
+	}
+	return s.undo[len(s.undo)-1], true
+}
+
+// CanUndo returns true if there is a previous snapshot
+func (s *UndoStack) CanUndo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.undo) > 1
+}
+
+// CanRedo returns true if there is an undone snapshot
+func (s *UndoStack) CanRedo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.redo) > 0
+}
+
+// EvictOldest drops the oldest snapshot, used when memory pressure is detected
+func (s *UndoStack) EvictOldest() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.undo) < 2 {
+		return false
+	}
+
+	s.undo[0] = nil
+	s.undo = s.undo[1:]
+	return true
+}
+
+// Clear removes all snapshots
+func (s *UndoStack) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.undo = s.undo[:0]
+	s.redo = s.redo[:0]
+}
+
+// MemoryUsage returns the estimated memory held by snapshots
+func (s *UndoStack) MemoryUsage() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.memoryUsageLocked()
+}
+
+// evictLocked drops the oldest snapshots until level and memory limits hold.
+// The current state is always kept.
+func (s *UndoStack) evictLocked() {
+	for len(s.undo) > 1 && (len(s.undo) > s.maxLevels || s.memoryUsageLocked() > s.memoryLimit) {
+		s.undo[0] = nil
+		s.undo = s.undo[1:]
+	}
+}
+
+func (s *UndoStack) memoryUsageLocked() int64 {
+	var total int64
+	for _, img := range s.undo {
+		total += int64(img.Width * img.Height * img.Channels)
+	}
+	for _, img := range s.redo {
+		total += int64(img.Width * img.Height * img.Channels)
+	}
+	return total
+}
@@ -0,0 +1,236 @@
+package monitoring
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"otsu-obliterator/internal/models"
+)
+
+// exportTimeout bounds each write to a metrics backend
+const exportTimeout = 5 * time.Second
+
+// pushgatewayJob is the job label segmentation metrics are pushed under
+const pushgatewayJob = "otsu_obliterator"
+
+// influxMeasurement is the line protocol measurement name
+const influxMeasurement = "segmentation"
+
+// MetricsPoint is one set of segmentation metrics at a point in time
+type MetricsPoint struct {
+	Tags   map[string]string
+	Fields map[string]float64
+	Time   time.Time
+}
+
+// MetricsBackend writes metric points to a time-series store
+type MetricsBackend interface {
+	Write(point MetricsPoint) error
+}
+
+// MetricsExporter sends segmentation metrics to a time-series backend so
+// repeated runs can be tracked over time
+type MetricsExporter struct {
+	backend MetricsBackend
+}
+
+// NewMetricsExporter creates an exporter writing to backend
+func NewMetricsExporter(backend MetricsBackend) *MetricsExporter {
+	return &MetricsExporter{backend: backend}
+}
+
+// NewMetricsExporterFromEnv selects the backend from METRICS_BACKEND,
+// "prometheus" or "influxdb". It returns nil without an error when
+// METRICS_BACKEND is unset.
+func NewMetricsExporterFromEnv() (*MetricsExporter, error) {
+	switch backend := os.Getenv("METRICS_BACKEND"); backend {
+	case "":
+		return nil, nil
+	case "prometheus":
+		address := os.Getenv("PROMETHEUS_PUSHGATEWAY_URL")
+		if address == "" {
+			return nil, fmt.Errorf("PROMETHEUS_PUSHGATEWAY_URL is required for the prometheus backend")
+		}
+		return NewMetricsExporter(NewPrometheusExporter(address)), nil
+	case "influxdb":
+		exporter, err := NewInfluxDBExporter(os.Getenv("INFLUXDB_URL"))
+		if err != nil {
+			return nil, err
+		}
+		return NewMetricsExporter(exporter), nil
+	default:
+		return nil, fmt.Errorf("unknown METRICS_BACKEND %q, expected prometheus or influxdb", backend)
+	}
+}
+
+// Export writes metrics with tags, such as the algorithm, to the backend
+func (e *MetricsExporter) Export(metrics *models.SegmentationMetrics, tags map[string]string) error {
+	if metrics == nil {
+		return fmt.Errorf("no metrics to export")
+	}
+
+	return e.backend.Write(MetricsPoint{
+		Tags: tags,
+		Fields: map[string]float64{
+			"iou":                     metrics.IoU,
+			"dice":                    metrics.DiceCoefficient,
+			"misclassification_error": metrics.MisclassificationError,
+			"region_uniformity":       metrics.RegionUniformity,
+			"boundary_accuracy":       metrics.BoundaryAccuracy,
+			"hausdorff_distance":      metrics.HausdorffDistance,
+			"hausdorff_distance_95":   metrics.HausdorffDistance95,
+			"psnr":                    metrics.PSNR,
+			"ssim":                    metrics.SSIM,
+		},
+		Time: time.Now(),
+	})
+}
+
+// PrometheusExporter pushes gauges to a Prometheus pushgateway. Each push
+// replaces the previous values of the job, so the time series is built by
+// Prometheus scraping the gateway.
+type PrometheusExporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewPrometheusExporter creates a backend for the pushgateway at address
+func NewPrometheusExporter(address string) *PrometheusExporter {
+	return &PrometheusExporter{
+		url:    strings.TrimSuffix(address, "/") + "/metrics/job/" + pushgatewayJob,
+		client: &http.Client{Timeout: exportTimeout},
+	}
+}
+
+func (p *PrometheusExporter) Write(point MetricsPoint) error {
+	labels := formatPrometheusLabels(point.Tags)
+
+	var body bytes.Buffer
+	for _, name := range sortedKeys(point.Fields) {
+		metric := "otsu_segmentation_" + name
+		fmt.Fprintf(&body, "# TYPE %s gauge\n", metric)
+		fmt.Fprintf(&body, "%s%s %g\n", metric, labels, point.Fields[name])
+	}
+
+	resp, err := p.client.Post(p.url, "text/plain; version=0.0.4", &body)
+	if err != nil {
+		return fmt.Errorf("pushgateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway returned %s", resp.Status)
+	}
+	return nil
+}
+
+// formatPrometheusLabels renders tags as a label set, or nothing without tags
+func formatPrometheusLabels(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", prometheusLabelName(key), tags[key]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// prometheusLabelName replaces characters not allowed in label names
+func prometheusLabelName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// InfluxDBExporter writes InfluxDB line protocol over UDP or TCP, as
+// accepted by the InfluxDB UDP service and Telegraf socket listeners
+type InfluxDBExporter struct {
+	network string
+	address string
+}
+
+// NewInfluxDBExporter creates a backend for a udp://host:port or
+// tcp://host:port address
+func NewInfluxDBExporter(address string) (*InfluxDBExporter, error) {
+	if address == "" {
+		return nil, fmt.Errorf("INFLUXDB_URL is required for the influxdb backend")
+	}
+
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid INFLUXDB_URL: %w", err)
+	}
+	if parsed.Scheme != "udp" && parsed.Scheme != "tcp" {
+		return nil, fmt.Errorf("INFLUXDB_URL must use udp:// or tcp://, got %q", parsed.Scheme)
+	}
+	if parsed.Port() == "" {
+		return nil, fmt.Errorf("INFLUXDB_URL must include a port")
+	}
+
+	return &InfluxDBExporter{network: parsed.Scheme, address: parsed.Host}, nil
+}
+
+func (i *InfluxDBExporter) Write(point MetricsPoint) error {
+	conn, err := net.DialTimeout(i.network, i.address, exportTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to connect to InfluxDB: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(exportTimeout)); err != nil {
+		return err
+	}
+
+	if _, err := conn.Write([]byte(formatLineProtocol(influxMeasurement, point))); err != nil {
+		return fmt.Errorf("failed to write to InfluxDB: %w", err)
+	}
+	return nil
+}
+
+// formatLineProtocol renders point as a single newline terminated line:
+// measurement,tag=value field=value timestamp
+func formatLineProtocol(measurement string, point MetricsPoint) string {
+	var b strings.Builder
+	b.WriteString(lineProtocolEscaper.Replace(measurement))
+	for _, key := range sortedKeys(point.Tags) {
+		if point.Tags[key] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, ",%s=%s", lineProtocolEscaper.Replace(key), lineProtocolEscaper.Replace(point.Tags[key]))
+	}
+
+	for n, name := range sortedKeys(point.Fields) {
+		separator := ","
+		if n == 0 {
+			separator = " "
+		}
+		fmt.Fprintf(&b, "%s%s=%g", separator, lineProtocolEscaper.Replace(name), point.Fields[name])
+	}
+
+	fmt.Fprintf(&b, " %d\n", point.Time.UnixNano())
+	return b.String()
+}
+
+// lineProtocolEscaper escapes the characters that delimit tags and fields
+var lineProtocolEscaper = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
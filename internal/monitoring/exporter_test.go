@@ -0,0 +1,98 @@
+package monitoring
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"otsu-obliterator/internal/models"
+)
+
+// pushgateway records the last push it received
+type pushgateway struct {
+	path        string
+	contentType string
+	body        string
+}
+
+func newPushgateway(t *testing.T, status int) (*pushgateway, *httptest.Server) {
+	t.Helper()
+
+	gateway := &pushgateway{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read push: %v", err)
+		}
+		gateway.path = r.URL.Path
+		gateway.contentType = r.Header.Get("Content-Type")
+		gateway.body = string(body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return gateway, server
+}
+
+func TestPrometheusExporterPushesGauges(t *testing.T) {
+	gateway, server := newPushgateway(t, http.StatusOK)
+	exporter := NewMetricsExporter(NewPrometheusExporter(server.URL + "/"))
+
+	metrics := &models.SegmentationMetrics{
+		IoU:                    0.75,
+		DiceCoefficient:        0.5,
+		MisclassificationError: 0.125,
+		RegionUniformity:       0.9,
+		BoundaryAccuracy:       0.8,
+		HausdorffDistance:      12,
+		HausdorffDistance95:    4.5,
+		PSNR:                   31.25,
+		SSIM:                   0.96,
+	}
+	tags := map[string]string{"algorithm": "2D Otsu", "image-name": "cells.png"}
+	if err := exporter.Export(metrics, tags); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	if gateway.path != "/metrics/job/"+pushgatewayJob {
+		t.Errorf("pushed to %q, want /metrics/job/%s", gateway.path, pushgatewayJob)
+	}
+	if !strings.HasPrefix(gateway.contentType, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", gateway.contentType)
+	}
+
+	// Label names are sanitised and sorted, values written in full
+	const labels = `{algorithm="2D Otsu",image_name="cells.png"}`
+	for name, value := range map[string]string{
+		"iou":                     "0.75",
+		"dice":                    "0.5",
+		"misclassification_error": "0.125",
+		"region_uniformity":       "0.9",
+		"boundary_accuracy":       "0.8",
+		"hausdorff_distance":      "12",
+		"hausdorff_distance_95":   "4.5",
+		"psnr":                    "31.25",
+		"ssim":                    "0.96",
+	} {
+		metric := "otsu_segmentation_" + name
+		for _, want := range []string{
+			"# TYPE " + metric + " gauge\n",
+			metric + labels + " " + value + "\n",
+		} {
+			if !strings.Contains(gateway.body, want) {
+				t.Errorf("push missing %q:\n%s", strings.TrimSpace(want), gateway.body)
+			}
+		}
+	}
+}
+
+func TestPrometheusExporterReportsGatewayErrors(t *testing.T) {
+	_, server := newPushgateway(t, http.StatusInternalServerError)
+	exporter := NewMetricsExporter(NewPrometheusExporter(server.URL))
+
+	err := exporter.Export(&models.SegmentationMetrics{IoU: 1}, nil)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Export to a failing gateway: err = %v, want the 500 status", err)
+	}
+}
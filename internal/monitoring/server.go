@@ -0,0 +1,307 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/models"
+)
+
+// DefaultPort is used when MONITOR_PORT is set but empty
+const DefaultPort = 8080
+
+// DefaultHost keeps the server, which has no authentication, reachable from
+// this machine only. MONITOR_HOST selects another interface, such as 0.0.0.0
+// behind an authenticating proxy.
+const DefaultHost = "127.0.0.1"
+
+// Status is the JSON body of GET /status and of every /events message
+type Status struct {
+	Active           bool      `json:"active"`
+	Algorithm        string    `json:"algorithm"`
+	Stage            string    `json:"stage"`
+	Progress         float64   `json:"progress"`
+	StartedAt        time.Time `json:"started_at,omitempty"`
+	ElapsedSeconds   float64   `json:"elapsed_seconds"`
+	EstimatedSeconds float64   `json:"estimated_seconds"`
+}
+
+// MonitoringServer exposes processing state over HTTP for headless deployments:
+// GET /status returns the current state, GET /metrics Prometheus counters and
+// /events streams state changes over a WebSocket.
+type MonitoringServer struct {
+	stateRepo *models.ProcessingStateRepository
+	logger    logger.Logger
+	server    *http.Server
+
+	mu              sync.Mutex
+	clients         map[chan []byte]struct{}
+	imagesProcessed int64
+	imagesFailed    int64
+	imagesCancelled int64
+	durationTotal   time.Duration
+
+	unsubscribe   func()
+	removeCounter func()
+	done          chan struct{}
+}
+
+// StartFromEnv starts a server when MONITOR_PORT is set, on DefaultPort when
+// it is empty, bound to MONITOR_HOST or DefaultHost. It returns nil without
+// an error when MONITOR_PORT is not set.
+func StartFromEnv(stateRepo *models.ProcessingStateRepository, log logger.Logger) (*MonitoringServer, error) {
+	value, ok := os.LookupEnv("MONITOR_PORT")
+	if !ok {
+		return nil, nil
+	}
+
+	port := DefaultPort
+	if value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 65535 {
+			return nil, fmt.Errorf("invalid MONITOR_PORT %q", value)
+		}
+		port = parsed
+	}
+
+	host := os.Getenv("MONITOR_HOST")
+	if host == "" {
+		host = DefaultHost
+	}
+
+	server := NewMonitoringServer(host, port, stateRepo, log)
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+	return server, nil
+}
+
+// NewMonitoringServer creates a server for the given host and port; call
+// Start to listen
+func NewMonitoringServer(host string, port int, stateRepo *models.ProcessingStateRepository, log logger.Logger) *MonitoringServer {
+	ms := &MonitoringServer{
+		stateRepo: stateRepo,
+		logger:    log,
+		clients:   make(map[chan []byte]struct{}),
+		done:      make(chan struct{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", ms.handleStatus)
+	mux.HandleFunc("GET /metrics", ms.handleMetrics)
+	mux.HandleFunc("GET /events", ms.handleEvents)
+
+	ms.server = &http.Server{
+		Addr:              net.JoinHostPort(host, strconv.Itoa(port)),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return ms
+}
+
+// Start binds the port and serves requests in the background
+func (ms *MonitoringServer) Start() error {
+	listener, err := net.Listen("tcp", ms.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", ms.server.Addr, err)
+	}
+
+	updates, unsubscribe := ms.stateRepo.Subscribe()
+	ms.unsubscribe = unsubscribe
+	ms.removeCounter = ms.stateRepo.AddRunListener(ms.countRun)
+	go ms.watchState(updates)
+
+	go func() {
+		if err := ms.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			ms.logger.Error("Monitoring server stopped", err, nil)
+		}
+	}()
+
+	ms.logger.Info("Monitoring server started", map[string]interface{}{
+		"address": listener.Addr().String(),
+	})
+	return nil
+}
+
+// Shutdown stops accepting requests, closes event streams and waits for
+// in-flight requests until ctx expires
+func (ms *MonitoringServer) Shutdown(ctx context.Context) error {
+	if ms.unsubscribe != nil {
+		ms.unsubscribe()
+	}
+	if ms.removeCounter != nil {
+		ms.removeCounter()
+	}
+	close(ms.done)
+
+	return ms.server.Shutdown(ctx)
+}
+
+// countRun updates the counters for a run that ended
+func (ms *MonitoringServer) countRun(event models.RunEvent) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	switch event.Outcome {
+	case models.RunCompleted:
+		ms.imagesProcessed++
+		ms.durationTotal += event.Duration
+	case models.RunFailed:
+		ms.imagesFailed++
+	case models.RunCancelled:
+		ms.imagesCancelled++
+	}
+}
+
+// watchState broadcasts each state change. Changes are coalesced, so clients
+// see the latest state rather than every change.
+func (ms *MonitoringServer) watchState(updates <-chan struct{}) {
+	for {
+		select {
+		case <-ms.done:
+			return
+		case <-updates:
+		}
+
+		state := ms.stateRepo.GetState()
+		status := statusFromState(&state)
+
+		message, err := json.Marshal(status)
+		if err != nil {
+			continue
+		}
+		ms.broadcast(message)
+	}
+}
+
+// broadcast queues a message for every WebSocket client, dropping it for
+// clients that are not keeping up
+func (ms *MonitoringServer) broadcast(message []byte) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	for client := range ms.clients {
+		select {
+		case client <- message:
+		default:
+		}
+	}
+}
+
+func (ms *MonitoringServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	state := ms.stateRepo.GetState()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statusFromState(&state)); err != nil {
+		ms.logger.Warning("Failed to write monitoring status", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+func (ms *MonitoringServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	state := ms.stateRepo.GetState()
+
+	ms.mu.Lock()
+	processed := ms.imagesProcessed
+	failed := ms.imagesFailed
+	cancelled := ms.imagesCancelled
+	duration := ms.durationTotal
+	ms.mu.Unlock()
+
+	active := 0
+	if state.IsActive {
+		active = 1
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	fmt.Fprintf(w, "# HELP otsu_images_processed_total Images processed to completion.\n")
+	fmt.Fprintf(w, "# TYPE otsu_images_processed_total counter\n")
+	fmt.Fprintf(w, "otsu_images_processed_total %d\n", processed)
+	fmt.Fprintf(w, "# HELP otsu_images_failed_total Processing runs that ended with an error.\n")
+	fmt.Fprintf(w, "# TYPE otsu_images_failed_total counter\n")
+	fmt.Fprintf(w, "otsu_images_failed_total %d\n", failed)
+	fmt.Fprintf(w, "# HELP otsu_images_cancelled_total Processing runs cancelled before completion.\n")
+	fmt.Fprintf(w, "# TYPE otsu_images_cancelled_total counter\n")
+	fmt.Fprintf(w, "otsu_images_cancelled_total %d\n", cancelled)
+	fmt.Fprintf(w, "# HELP otsu_processing_duration_seconds Time spent on completed processing runs.\n")
+	fmt.Fprintf(w, "# TYPE otsu_processing_duration_seconds summary\n")
+	fmt.Fprintf(w, "otsu_processing_duration_seconds_sum %g\n", duration.Seconds())
+	fmt.Fprintf(w, "otsu_processing_duration_seconds_count %d\n", processed)
+	fmt.Fprintf(w, "# HELP otsu_processing_active Whether an image is being processed.\n")
+	fmt.Fprintf(w, "# TYPE otsu_processing_active gauge\n")
+	fmt.Fprintf(w, "otsu_processing_active %d\n", active)
+	fmt.Fprintf(w, "# HELP otsu_processing_progress Progress of the current run from 0 to 1.\n")
+	fmt.Fprintf(w, "# TYPE otsu_processing_progress gauge\n")
+	fmt.Fprintf(w, "otsu_processing_progress %g\n", state.Progress)
+}
+
+// handleEvents upgrades to a WebSocket and sends the current state followed
+// by every change until the client disconnects or the server shuts down
+func (ms *MonitoringServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer conn.Close()
+
+	client := make(chan []byte, 16)
+	ms.mu.Lock()
+	ms.clients[client] = struct{}{}
+	ms.mu.Unlock()
+
+	defer func() {
+		ms.mu.Lock()
+		delete(ms.clients, client)
+		ms.mu.Unlock()
+	}()
+
+	state := ms.stateRepo.GetState()
+	initial, err := json.Marshal(statusFromState(&state))
+	if err == nil {
+		if err := conn.WriteText(initial); err != nil {
+			return
+		}
+	}
+
+	closed := conn.WatchClose()
+	for {
+		select {
+		case message := <-client:
+			if err := conn.WriteText(message); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-ms.done:
+			conn.WriteClose()
+			return
+		}
+	}
+}
+
+// statusFromState converts the repository state to its JSON form
+func statusFromState(state *models.ProcessingState) Status {
+	status := Status{
+		Active:           state.IsActive,
+		Algorithm:        state.Algorithm,
+		Stage:            state.CurrentStage,
+		Progress:         state.Progress,
+		StartedAt:        state.StartTime,
+		EstimatedSeconds: state.EstimatedDuration.Seconds(),
+	}
+	if state.IsActive && !state.StartTime.IsZero() {
+		status.ElapsedSeconds = time.Since(state.StartTime).Seconds()
+	}
+	return status
+}
@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/models"
+)
+
+func newTestServer(t *testing.T, stateRepo *models.ProcessingStateRepository) *MonitoringServer {
+	t.Helper()
+
+	ms := NewMonitoringServer(DefaultHost, 0, stateRepo, logger.NewFileLogger(logger.ErrorLevel, io.Discard))
+	if err := ms.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ms.Shutdown(context.Background()) })
+	return ms
+}
+
+func TestMetricsCountEveryRunOutcome(t *testing.T) {
+	stateRepo := models.NewProcessingStateRepository()
+	ms := newTestServer(t, stateRepo)
+
+	// Runs that end before any state change is observed must still count
+	for i := 0; i < 100; i++ {
+		stateRepo.StartProcessing("2D Otsu")
+		stateRepo.CompleteProcessing()
+	}
+
+	// The deferred CompleteProcessing of a failed or cancelled run is ignored
+	stateRepo.StartProcessing("2D Otsu")
+	stateRepo.FailProcessing()
+	stateRepo.CompleteProcessing()
+
+	stateRepo.StartProcessing("2D Otsu")
+	stateRepo.CancelProcessing()
+	stateRepo.CompleteProcessing()
+
+	recorder := httptest.NewRecorder()
+	ms.handleMetrics(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for _, want := range []string{
+		"otsu_images_processed_total 100\n",
+		"otsu_images_failed_total 1\n",
+		"otsu_images_cancelled_total 1\n",
+		"otsu_processing_duration_seconds_count 100\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", strings.TrimSpace(want), body)
+		}
+	}
+}
+
+func TestStartFromEnvBindsLoopbackByDefault(t *testing.T) {
+	// Borrow a free port for the server
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := probe.Addr().(*net.TCPAddr).Port
+	probe.Close()
+
+	t.Setenv("MONITOR_PORT", strconv.Itoa(port))
+	t.Setenv("MONITOR_HOST", "")
+
+	ms, err := StartFromEnv(models.NewProcessingStateRepository(), logger.NewFileLogger(logger.ErrorLevel, io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ms.Shutdown(context.Background())
+
+	host, _, err := net.SplitHostPort(ms.server.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host != DefaultHost {
+		t.Fatalf("server bound to %q, want %q", host, DefaultHost)
+	}
+}
@@ -0,0 +1,63 @@
+package analysis
+
+import (
+	"math"
+
+	"otsu-obliterator/internal/opencv/safe"
+)
+
+// entropyLevels is the number of grey levels the entropy is measured over;
+// deeper images are scaled onto it so the result is always in [0, 8] bits
+const entropyLevels = 256
+
+// MaxHistogramEntropy is the entropy of a uniform 256-level histogram, log2(256)
+const MaxHistogramEntropy = 8.0
+
+// ComputeHistogramEntropy returns the Shannon entropy in bits of the grey
+// level histogram of a single channel Mat. An image spread evenly over all
+// 256 levels scores MaxHistogramEntropy; a flat image scores 0.
+func ComputeHistogramEntropy(src *safe.Mat) float64 {
+	if err := safe.ValidateMatForOperation(src, "entropy calculation"); err != nil {
+		return 0
+	}
+
+	rows := src.Rows()
+	cols := src.Cols()
+	scale := float64(entropyLevels-1) / safe.MaxElementValue(src.Type())
+
+	histogram := make([]float64, entropyLevels)
+	total := 0.0
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			value, err := safe.GetAt[float64](src, y, x)
+			if err != nil {
+				continue
+			}
+			level := max(0, min(int(value*scale), entropyLevels-1))
+			histogram[level]++
+			total++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	entropy := 0.0
+	for _, count := range histogram {
+		if count > 0 {
+			p := count / total
+			entropy -= p * math.Log2(p)
+		}
+	}
+
+	return entropy
+}
+
+// EntropyBinCount maps a histogram entropy onto a bin count between 8 and
+// 256: images using few grey levels are binned coarsely, images using the
+// whole range keep every level
+func EntropyBinCount(entropy float64) int {
+	entropy = max(0, min(entropy, MaxHistogramEntropy))
+	return int(8 + entropy*(248.0/MaxHistogramEntropy))
+}
@@ -0,0 +1,50 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// ComputeImageStatistics measures src with MeanStdDev and MinMaxLoc,
+// converting colour images to grayscale first
+func ComputeImageStatistics(src *safe.Mat) (models.ImageStatistics, error) {
+	if err := safe.ValidateMatForOperation(src, "image statistics"); err != nil {
+		return models.ImageStatistics{}, err
+	}
+
+	gray, err := conversion.ConvertToGrayscale(src)
+	if err != nil {
+		return models.ImageStatistics{}, fmt.Errorf("grayscale conversion failed: %w", err)
+	}
+	defer gray.Close()
+
+	grayMat := gray.GetMat()
+
+	mean := gocv.NewMat()
+	defer mean.Close()
+	stdDev := gocv.NewMat()
+	defer stdDev.Close()
+	if err := gocv.MeanStdDev(grayMat, &mean, &stdDev); err != nil {
+		return models.ImageStatistics{}, fmt.Errorf("mean and deviation failed: %w", err)
+	}
+
+	minVal, maxVal, _, _ := gocv.MinMaxLoc(grayMat)
+
+	stats := models.ImageStatistics{
+		Mean:         mean.GetDoubleAt(0, 0),
+		StdDev:       stdDev.GetDoubleAt(0, 0),
+		Min:          float64(minVal),
+		Max:          float64(maxVal),
+		Entropy:      ComputeHistogramEntropy(gray),
+		DynamicRange: float64(maxVal - minVal),
+	}
+	stats.DynamicRangeBits = math.Log2(stats.DynamicRange + 1)
+
+	return stats, nil
+}
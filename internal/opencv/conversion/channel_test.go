@@ -0,0 +1,109 @@
+package conversion
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// stripeColors are the vertical stripes of the test image, left to right
+var stripeColors = []color.NRGBA{
+	{R: 255, A: 255},
+	{G: 255, A: 255},
+	{B: 255, A: 255},
+	{R: 200, G: 100, B: 50, A: 255},
+}
+
+const stripeWidth = 8
+
+// decodeStripes encodes the stripes as a PNG and decodes it the way image
+// files are loaded, into a 3-channel BGR Mat
+func decodeStripes(t *testing.T) *safe.Mat {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, stripeWidth*len(stripeColors), 4))
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			img.SetNRGBA(x, y, stripeColors[x/stripeWidth])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := gocv.IMDecode(buf.Bytes(), gocv.IMReadColor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer decoded.Close()
+
+	mat, err := safe.NewMatFromMat(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mat.Close() })
+	return mat
+}
+
+func TestChannelExtractorPixelValues(t *testing.T) {
+	src := decodeStripes(t)
+	if src.Channels() != 3 {
+		t.Fatalf("decoded PNG has %d channels, want 3", src.Channels())
+	}
+
+	// One value per stripe: red, green, blue and (200, 100, 50). Hue is on
+	// the full 0-255 scale, so 120 and 240 degrees become 85 and 171, and
+	// 20 degrees becomes 14.
+	tests := []struct {
+		channel Channel
+		want    []uint8
+	}{
+		{ChannelRed, []uint8{255, 0, 0, 200}},
+		{ChannelGreen, []uint8{0, 255, 0, 100}},
+		{ChannelBlue, []uint8{0, 0, 255, 50}},
+		{ChannelLuminance, []uint8{76, 150, 29, 124}},
+		{ChannelHue, []uint8{0, 85, 171, 14}},
+		{ChannelSaturation, []uint8{255, 255, 255, 191}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.channel), func(t *testing.T) {
+			extractor, err := NewChannelExtractor(string(tt.channel))
+			if err != nil {
+				t.Fatal(err)
+			}
+			channel, err := extractor.Extract(src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer channel.Close()
+
+			if channel.Channels() != 1 {
+				t.Fatalf("extracted %d channels, want 1", channel.Channels())
+			}
+			for i, want := range tt.want {
+				got, err := channel.GetUCharAt(1, i*stripeWidth+stripeWidth/2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				// Colour conversions round in fixed point
+				if diff := int(got) - int(want); diff < -1 || diff > 1 {
+					t.Errorf("stripe %d: %s = %d, want %d", i, tt.channel, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestChannelExtractorRejectsUnknownChannel(t *testing.T) {
+	if _, err := NewChannelExtractor("alpha"); err == nil {
+		t.Error("NewChannelExtractor(\"alpha\") succeeded, want an error")
+	}
+}
@@ -79,6 +79,8 @@ func ImageToMat(img image.Image) (*safe.Mat, error) {
 	switch typedImg := img.(type) {
 	case *image.Gray:
 		return grayImageToMat(typedImg, width, height)
+	case *image.Gray16:
+		return gray16ImageToMat(typedImg, width, height)
 	case *image.RGBA:
 		return rgbaImageToMat(typedImg, width, height)
 	case *image.NRGBA:
@@ -88,13 +90,28 @@ func ImageToMat(img image.Image) (*safe.Mat, error) {
 	}
 }
 
-// matToGray converts single-channel Mat to grayscale image
-func matToGray(src *safe.Mat, rows, cols int) (*image.Gray, error) {
+// matToGray converts single-channel Mat to grayscale image, keeping 16-bit
+// Mats at full depth
+func matToGray(src *safe.Mat, rows, cols int) (image.Image, error) {
+	if src.Type() == gocv.MatTypeCV16UC1 {
+		img := image.NewGray16(image.Rect(0, 0, cols, rows))
+		for y := 0; y < rows; y++ {
+			for x := 0; x < cols; x++ {
+				value, err := src.GetUInt16At(y, x)
+				if err != nil {
+					return nil, fmt.Errorf("pixel access failed at (%d,%d): %w", x, y, err)
+				}
+				img.SetGray16(x, y, color.Gray16{Y: value})
+			}
+		}
+		return img, nil
+	}
+
 	img := image.NewGray(image.Rect(0, 0, cols, rows))
 
 	for y := 0; y < rows; y++ {
 		for x := 0; x < cols; x++ {
-			value, err := src.GetUCharAt(y, x)
+			value, err := safe.GetAt[uint8](src, y, x)
 			if err != nil {
 				return nil, fmt.Errorf("pixel access failed at (%d,%d): %w", x, y, err)
 			}
@@ -187,6 +204,28 @@ func grayImageToMat(img *image.Gray, width, height int) (*safe.Mat, error) {
 	return mat, nil
 }
 
+// gray16ImageToMat converts a 16-bit grayscale image to a 16-bit
+// single-channel Mat without reducing its depth
+func gray16ImageToMat(img *image.Gray16, width, height int) (*safe.Mat, error) {
+	mat, err := safe.NewMat(height, width, gocv.MatTypeCV16UC1)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixel := img.Gray16At(x+bounds.Min.X, y+bounds.Min.Y)
+			if err := safe.SetAt(mat, y, x, pixel.Y); err != nil {
+				mat.Close()
+				return nil, fmt.Errorf("pixel setting failed at (%d,%d): %w", x, y, err)
+			}
+		}
+	}
+
+	return mat, nil
+}
+
 // rgbaImageToMat converts RGBA image to BGR Mat
 func rgbaImageToMat(img *image.RGBA, width, height int) (*safe.Mat, error) {
 	mat, err := safe.NewMat(height, width, gocv.MatTypeCV8UC3)
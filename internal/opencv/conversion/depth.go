@@ -0,0 +1,33 @@
+package conversion
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// Depth16Scale is the ratio between the 16-bit and 8-bit intensity ranges,
+// 65535 / 255, so an 8-bit threshold t corresponds to t * Depth16Scale
+const Depth16Scale = 257.0
+
+// Is16Bit reports whether src is a 16-bit single channel Mat
+func Is16Bit(src *safe.Mat) bool {
+	return src != nil && src.Type() == gocv.MatTypeCV16UC1
+}
+
+// ScaleTo8Bit returns an 8-bit copy of a 16-bit single channel Mat, mapping
+// 65535 to 255. The algorithms histogram and threshold 8-bit intensities.
+func ScaleTo8Bit(src *safe.Mat) (*safe.Mat, error) {
+	if !Is16Bit(src) {
+		return nil, fmt.Errorf("expected a 16-bit single channel Mat")
+	}
+
+	srcMat := src.GetMat()
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	srcMat.ConvertToWithParams(&scaled, gocv.MatTypeCV8UC1, float32(1/Depth16Scale), 0)
+
+	return safe.NewMatFromMat(scaled)
+}
@@ -0,0 +1,36 @@
+package conversion
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// WebPFileExt is the OpenCV codec extension for WebP
+const WebPFileExt gocv.FileExt = ".webp"
+
+// DefaultWebPQuality is used when no quality preference is set
+const DefaultWebPQuality = 85
+
+// EncodeWebP encodes a Mat as WebP with quality in the range 0-100
+func EncodeWebP(mat *safe.Mat, quality int) ([]byte, error) {
+	if err := safe.ValidateMatForOperation(mat, "WebP encoding"); err != nil {
+		return nil, err
+	}
+
+	quality = max(0, min(quality, 100))
+
+	buf, err := gocv.IMEncodeWithParams(WebPFileExt, mat.GetMat(), []int{gocv.IMWriteWebpQuality, quality})
+	if err != nil {
+		return nil, fmt.Errorf("WebP encoding failed: %w", err)
+	}
+	defer buf.Close()
+
+	// Copy out of the native buffer before it is released
+	data := make([]byte, buf.Len())
+	copy(data, buf.GetBytes())
+
+	return data, nil
+}
@@ -0,0 +1,87 @@
+package cuda
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing/histogram"
+)
+
+var (
+	deviceCountOnce sync.Once
+	cachedDevices   int
+)
+
+// DeviceCount returns the number of CUDA capable devices visible to OpenCV.
+// Builds without the cuda tag always report zero.
+func DeviceCount() int {
+	deviceCountOnce.Do(func() {
+		cachedDevices = enabledDeviceCount()
+	})
+	return cachedDevices
+}
+
+// CUDAHistogramBuilder builds 2D histograms on the GPU and falls back to the
+// CPU builder when no CUDA device is present or the GPU path fails
+type CUDAHistogramBuilder struct {
+	enabled  bool
+	fallback *histogram.TwoDimensionalBuilder
+}
+
+func NewCUDAHistogramBuilder() *CUDAHistogramBuilder {
+	return &CUDAHistogramBuilder{
+		enabled:  DeviceCount() > 0,
+		fallback: histogram.NewTwoDimensionalBuilder(),
+	}
+}
+
+// IsAvailable reports whether histograms will be built on the GPU
+func (b *CUDAHistogramBuilder) IsAvailable() bool {
+	return b.enabled
+}
+
+// Build has the same contract as histogram.TwoDimensionalBuilder.Build
+func (b *CUDAHistogramBuilder) Build(ctx context.Context, src, neighborhood *safe.Mat, params map[string]interface{}) ([][]float64, error) {
+	histBins, ok := params["histogram_bins"].(int)
+	if !b.enabled || !ok || histBins <= 0 {
+		// Adaptive bin selection needs the CPU noise estimate
+		return b.fallback.Build(ctx, src, neighborhood, params)
+	}
+
+	if src.Channels() != 1 || neighborhood.Channels() != 1 {
+		return nil, fmt.Errorf("2D histogram requires single channel inputs")
+	}
+
+	joint, err := buildJointHistogramGPU(src, neighborhood)
+	if err != nil {
+		return b.fallback.Build(ctx, src, neighborhood, params)
+	}
+
+	return foldJointHistogram(joint, histBins), nil
+}
+
+// foldJointHistogram maps a 256x256 joint histogram onto histBins x histBins
+// using the same bin assignment as the CPU builder
+func foldJointHistogram(joint []int32, histBins int) [][]float64 {
+	result := make([][]float64, histBins)
+	for i := range result {
+		result[i] = make([]float64, histBins)
+	}
+
+	binScale := float64(histBins-1) / 255.0
+	for pixel := 0; pixel < 256; pixel++ {
+		pixelBin := int(float64(pixel) * binScale)
+		for neigh := 0; neigh < 256; neigh++ {
+			count := joint[pixel*256+neigh]
+			if count == 0 {
+				continue
+			}
+			neighBin := int(float64(neigh) * binScale)
+			result[pixelBin][neighBin] += float64(count)
+		}
+	}
+
+	return result
+}
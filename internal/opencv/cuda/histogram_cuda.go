@@ -0,0 +1,71 @@
+//go:build cuda
+
+package cuda
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+	gocvcuda "gocv.io/x/gocv/cuda"
+)
+
+func enabledDeviceCount() int {
+	return gocvcuda.GetCudaEnabledDeviceCount()
+}
+
+// buildJointHistogramGPU encodes each (pixel, neighbourhood) pair as
+// pixel*256+neighbourhood in a 16-bit image and counts the codes with a single
+// evenly binned GPU histogram
+func buildJointHistogramGPU(src, neighborhood *safe.Mat) ([]int32, error) {
+	srcGpu := gocvcuda.NewGpuMatFromMat(src.GetMat())
+	defer srcGpu.Close()
+	neighGpu := gocvcuda.NewGpuMatFromMat(neighborhood.GetMat())
+	defer neighGpu.Close()
+
+	src16 := gocvcuda.NewGpuMat()
+	defer src16.Close()
+	neigh16 := gocvcuda.NewGpuMat()
+	defer neigh16.Close()
+	srcGpu.ConvertTo(&src16, gocv.MatTypeCV16UC1)
+	neighGpu.ConvertTo(&neigh16, gocv.MatTypeCV16UC1)
+
+	scale := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(256, 0, 0, 0), src.Rows(), src.Cols(), gocv.MatTypeCV16UC1)
+	defer scale.Close()
+	scaleGpu := gocvcuda.NewGpuMatFromMat(scale)
+	defer scaleGpu.Close()
+
+	shifted := gocvcuda.NewGpuMat()
+	defer shifted.Close()
+	if err := gocvcuda.Multiply(src16, scaleGpu, &shifted); err != nil {
+		return nil, fmt.Errorf("gpu multiply failed: %w", err)
+	}
+
+	codes := gocvcuda.NewGpuMat()
+	defer codes.Close()
+	if err := gocvcuda.Add(shifted, neigh16, &codes); err != nil {
+		return nil, fmt.Errorf("gpu add failed: %w", err)
+	}
+
+	histGpu := gocvcuda.NewGpuMat()
+	defer histGpu.Close()
+	if err := gocvcuda.HistEven(codes, &histGpu, 65536, 0, 65536); err != nil {
+		return nil, fmt.Errorf("gpu histogram failed: %w", err)
+	}
+
+	hist := gocv.NewMat()
+	defer hist.Close()
+	histGpu.Download(&hist)
+
+	if hist.Total() != 65536 {
+		return nil, fmt.Errorf("unexpected gpu histogram size: %d", hist.Total())
+	}
+
+	result := make([]int32, 65536)
+	for i := range result {
+		result[i] = hist.GetIntAt(0, i)
+	}
+
+	return result, nil
+}
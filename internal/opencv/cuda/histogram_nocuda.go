@@ -0,0 +1,17 @@
+//go:build !cuda
+
+package cuda
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/safe"
+)
+
+func enabledDeviceCount() int {
+	return 0
+}
+
+func buildJointHistogramGPU(src, neighborhood *safe.Mat) ([]int32, error) {
+	return nil, fmt.Errorf("built without cuda support")
+}
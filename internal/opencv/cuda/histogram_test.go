@@ -0,0 +1,77 @@
+package cuda
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing/histogram"
+
+	"gocv.io/x/gocv"
+)
+
+// benchmarkSide is the edge length of the benchmark image, large enough for
+// the histogram to dominate
+const benchmarkSide = 2048
+
+// benchmarkInputs returns a noise image and its 7x7 neighbourhood mean
+func benchmarkInputs(b *testing.B) (src, neighborhood *safe.Mat) {
+	b.Helper()
+
+	noise := gocv.NewMatWithSize(benchmarkSide, benchmarkSide, gocv.MatTypeCV8UC1)
+	defer noise.Close()
+	gocv.RandU(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(256, 0, 0, 0))
+
+	mean := gocv.NewMat()
+	defer mean.Close()
+	if err := gocv.Blur(noise, &mean, image.Pt(7, 7)); err != nil {
+		b.Fatal(err)
+	}
+
+	src, err := safe.NewMatFromMat(noise)
+	if err != nil {
+		b.Fatal(err)
+	}
+	neighborhood, err = safe.NewMatFromMat(mean)
+	if err != nil {
+		src.Close()
+		b.Fatal(err)
+	}
+	b.Cleanup(func() {
+		src.Close()
+		neighborhood.Close()
+	})
+	return src, neighborhood
+}
+
+// histogramBuilder is the Build method shared by the CPU and GPU builders
+type histogramBuilder interface {
+	Build(ctx context.Context, src, neighborhood *safe.Mat, params map[string]interface{}) ([][]float64, error)
+}
+
+func benchmarkBuild(b *testing.B, builder histogramBuilder) {
+	src, neighborhood := benchmarkInputs(b)
+	params := map[string]interface{}{"histogram_bins": 64}
+	ctx := context.Background()
+
+	b.SetBytes(int64(benchmarkSide * benchmarkSide))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := builder.Build(ctx, src, neighborhood, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuild2DHistogramCPU(b *testing.B) {
+	benchmarkBuild(b, histogram.NewTwoDimensionalBuilder())
+}
+
+func BenchmarkBuild2DHistogramCUDA(b *testing.B) {
+	builder := NewCUDAHistogramBuilder()
+	if !builder.IsAvailable() {
+		b.Skip("no CUDA device; build with -tags cuda on a machine with one")
+	}
+	benchmarkBuild(b, builder)
+}
@@ -3,8 +3,12 @@ package memory
 import (
 	"context"
 	"fmt"
+	"os"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"otsu-obliterator/internal/logger"
@@ -14,13 +18,19 @@ import (
 )
 
 type Manager struct {
-	mu           sync.RWMutex
-	logger       logger.Logger
-	maxMemory    int64
-	usedMemory   int64
-	allocCount   int64
-	deallocCount int64
-	activeMats   map[uint64]*MatInfo
+	mu         sync.RWMutex
+	logger     logger.Logger
+	maxMemory  int64
+	activeMats map[uint64]*MatInfo
+
+	// Counters are atomic so GetStats never waits on the allocation lock
+	usedMemory   atomic.Int64
+	allocCount   atomic.Int64
+	deallocCount atomic.Int64
+
+	// recordAllocSites captures the call stack of every allocation so leaks
+	// reported at shutdown point at their source. Enabled by LOG_LEVEL=debug.
+	recordAllocSites bool
 
 	// Go 1.24 worker pool for memory operations
 	workers chan struct{}
@@ -40,8 +50,17 @@ type MatInfo struct {
 	Type      gocv.MatType
 	Rows      int
 	Cols      int
+	// AllocSite is the call stack of the allocation, innermost frame first.
+	// Empty unless allocation sites are recorded.
+	AllocSite string
 }
 
+// AllocEntry is a tracked Mat as reported by Snapshot
+type AllocEntry = MatInfo
+
+// maxAllocSiteFrames bounds the stack captured per allocation
+const maxAllocSiteFrames = 16
+
 func NewManager(log logger.Logger) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -64,6 +83,7 @@ func NewManager(log logger.Logger) *Manager {
 		logger:             log,
 		maxMemory:          maxMemory,
 		activeMats:         make(map[uint64]*MatInfo),
+		recordAllocSites:   os.Getenv("LOG_LEVEL") == "debug",
 		workers:            make(chan struct{}, runtime.NumCPU()),
 		ctx:                ctx,
 		cancel:             cancel,
@@ -83,6 +103,7 @@ func NewManager(log logger.Logger) *Manager {
 		"gc_trigger_gb":    manager.gcTriggerThreshold / (1024 * 1024 * 1024),
 		"system_memory_gb": systemMemory / (1024 * 1024 * 1024),
 		"worker_count":     runtime.NumCPU(),
+		"alloc_sites":      manager.recordAllocSites,
 	})
 
 	return manager
@@ -91,31 +112,39 @@ func NewManager(log logger.Logger) *Manager {
 func (m *Manager) GetMat(rows, cols int, matType gocv.MatType, tag string) (*safe.Mat, error) {
 	size := int64(rows * cols * getMatTypeSize(matType))
 
-	m.mu.Lock()
-	if m.usedMemory+size > m.maxMemory {
-		m.mu.Unlock()
+	if m.usedMemory.Load()+size > m.maxMemory {
 		m.forceGarbageCollection()
 
-		m.mu.Lock()
-		if m.usedMemory+size > m.maxMemory {
-			m.mu.Unlock()
+		if used := m.usedMemory.Load(); used+size > m.maxMemory {
 			return nil, &MemoryExhaustionError{
 				Requested: size,
-				Available: m.maxMemory - m.usedMemory,
+				Available: m.maxMemory - used,
 				Total:     m.maxMemory,
 			}
 		}
 	}
-	m.mu.Unlock()
 
-	mat, err := safe.NewMatWithTracker(rows, cols, matType, m, tag)
-	if err != nil {
-		return nil, err
+	// NewMatWithTracker registers the Mat through TrackMat
+	return safe.NewMatWithTracker(rows, cols, matType, m, tag)
+}
+
+// TrackMat registers a Mat created with this manager as its tracker. The
+// safe package calls it from its constructors, so Mats from GetMat, from
+// safe.NewMatFromMatWithTracker and clones of either are all counted.
+func (m *Manager) TrackMat(mat *safe.Mat, tag string) {
+	if mat == nil {
+		return
+	}
+
+	rows, cols, matType := mat.Rows(), mat.Cols(), mat.Type()
+	size := int64(rows * cols * getMatTypeSize(matType))
+
+	var site string
+	if m.recordAllocSites {
+		site = allocSite()
 	}
 
 	m.mu.Lock()
-	m.usedMemory += size
-	m.allocCount++
 	m.activeMats[mat.ID()] = &MatInfo{
 		ID:        mat.ID(),
 		Tag:       tag,
@@ -124,37 +153,100 @@ func (m *Manager) GetMat(rows, cols int, matType gocv.MatType, tag string) (*saf
 		Type:      matType,
 		Rows:      rows,
 		Cols:      cols,
+		AllocSite: site,
 	}
 	m.mu.Unlock()
 
+	m.allocCount.Add(1)
+	used := m.usedMemory.Add(size)
+
 	// Async memory pressure check
-	if m.usedMemory > m.gcTriggerThreshold {
+	if used > m.gcTriggerThreshold {
 		go m.asyncGarbageCollection()
 	}
-
-	return mat, nil
 }
 
+// ReleaseMat deregisters a tracked Mat and closes it. safe.Mat.Close calls
+// it for the last reference, after which the Mat is no longer tracked.
 func (m *Manager) ReleaseMat(mat *safe.Mat, tag string) {
 	if mat == nil {
 		return
 	}
 
 	m.mu.Lock()
-	if info, exists := m.activeMats[mat.ID()]; exists {
+	info, exists := m.activeMats[mat.ID()]
+	if exists {
 		delete(m.activeMats, mat.ID())
-		m.usedMemory -= info.Size
-		m.deallocCount++
 	}
 	m.mu.Unlock()
 
+	if exists {
+		m.usedMemory.Add(-info.Size)
+		m.deallocCount.Add(1)
+	}
+
 	mat.Close()
 }
 
-func (m *Manager) GetStats() (allocCount, deallocCount int64, usedMemory int64) {
+// allocSite formats the stack of the code that asked for a tracked Mat,
+// leaving out the frames of this package and the safe package
+func allocSite() string {
+	pcs := make([]uintptr, maxAllocSiteFrames+8)
+	// Skip runtime.Callers and allocSite
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var b strings.Builder
+	written := 0
+	for written < maxAllocSiteFrames {
+		frame, more := frames.Next()
+		if written > 0 || !isTrackingFrame(frame.Function) {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+			written++
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+// isTrackingFrame reports whether function belongs to the Mat allocation and
+// tracking code rather than to its caller
+func isTrackingFrame(function string) bool {
+	return strings.HasPrefix(function, "otsu-obliterator/internal/opencv/memory.") ||
+		strings.HasPrefix(function, "otsu-obliterator/internal/opencv/safe.")
+}
+
+// Snapshot returns a copy of every Mat allocated through GetMat and not yet
+// released, oldest first
+func (m *Manager) Snapshot() []AllocEntry {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.allocCount, m.deallocCount, m.usedMemory
+	entries := make([]AllocEntry, 0, len(m.activeMats))
+	for _, info := range m.activeMats {
+		entries = append(entries, *info)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// Stats counts the tracked allocations and releases of Mats and the bytes
+// held by tracked Mats that are still open
+type Stats struct {
+	AllocCount   int64
+	DeallocCount int64
+	UsedMemory   int64
+}
+
+// GetStats returns the current allocation statistics
+func (m *Manager) GetStats() Stats {
+	return Stats{
+		AllocCount:   m.allocCount.Load(),
+		DeallocCount: m.deallocCount.Load(),
+		UsedMemory:   m.usedMemory.Load(),
+	}
 }
 
 func (m *Manager) monitorMemoryUsage() {
@@ -172,28 +264,33 @@ func (m *Manager) monitorMemoryUsage() {
 }
 
 func (m *Manager) performMemoryCheck() {
-	alloc, dealloc, used := m.GetStats()
+	stats := m.GetStats()
+	m.mu.RLock()
 	activeCount := len(m.activeMats)
+	m.mu.RUnlock()
 	gocvCount := gocv.MatProfile.Count()
 
-	utilizationRatio := float64(used) / float64(m.maxMemory)
+	utilizationRatio := float64(stats.UsedMemory) / float64(m.maxMemory)
+	poolHits, poolMisses := safe.SharedMatPool.Stats()
 
 	m.logger.Debug("Memory statistics", map[string]interface{}{
-		"allocations":    alloc,
-		"deallocations":  dealloc,
-		"used_gb":        used / (1024 * 1024 * 1024),
+		"allocations":    stats.AllocCount,
+		"deallocations":  stats.DeallocCount,
+		"used_gb":        stats.UsedMemory / (1024 * 1024 * 1024),
 		"max_gb":         m.maxMemory / (1024 * 1024 * 1024),
 		"utilization":    utilizationRatio,
 		"active_mats":    activeCount,
 		"gocv_count":     gocvCount,
-		"leak_indicator": alloc - dealloc,
+		"leak_indicator": stats.AllocCount - stats.DeallocCount,
+		"pool_hits":      poolHits,
+		"pool_misses":    poolMisses,
 	})
 
 	// Memory pressure warnings
 	if utilizationRatio > 0.9 {
 		m.logger.Warning("High memory pressure detected", map[string]interface{}{
 			"utilization": utilizationRatio,
-			"used_gb":     used / (1024 * 1024 * 1024),
+			"used_gb":     stats.UsedMemory / (1024 * 1024 * 1024),
 		})
 		go m.forceGarbageCollection()
 	}
@@ -229,9 +326,23 @@ func (m *Manager) asyncGarbageCollection() {
 	}
 }
 
+// Shutdown stops the memory monitor, reports every Mat still open and logs
+// the final allocation counts
 func (m *Manager) Shutdown() {
 	m.cancel()
 
+	for _, entry := range m.Snapshot() {
+		m.logger.Warning("Mat not released before shutdown", map[string]interface{}{
+			"id":         entry.ID,
+			"tag":        entry.Tag,
+			"rows":       entry.Rows,
+			"cols":       entry.Cols,
+			"type":       entry.Type.String(),
+			"allocated":  entry.Timestamp.Format(time.RFC3339Nano),
+			"alloc_site": entry.AllocSite,
+		})
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -247,11 +358,11 @@ func (m *Manager) Shutdown() {
 		"mats_cleaned":        matCount,
 		"memory_freed_gb":     totalSize / (1024 * 1024 * 1024),
 		"final_gocv_count":    gocv.MatProfile.Count(),
-		"total_allocations":   m.allocCount,
-		"total_deallocations": m.deallocCount,
+		"total_allocations":   m.allocCount.Load(),
+		"total_deallocations": m.deallocCount.Load(),
 	})
 
-	m.usedMemory = 0
+	m.usedMemory.Store(0)
 	runtime.GC()
 }
 
@@ -0,0 +1,75 @@
+package memory_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/opencv/memory"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// newDebugManager returns a manager that records allocation sites, as it
+// does with LOG_LEVEL=debug
+func newDebugManager(t *testing.T) *memory.Manager {
+	t.Helper()
+
+	t.Setenv("LOG_LEVEL", "debug")
+	manager := memory.NewManager(logger.NewJSONLogger(logger.ErrorLevel, io.Discard))
+	t.Cleanup(manager.Shutdown)
+	return manager
+}
+
+// leakMat allocates a Mat and forgets to close it, from a frame the
+// allocation site has to name
+func leakMat(t *testing.T, manager *memory.Manager) *safe.Mat {
+	t.Helper()
+
+	mat, err := manager.GetMat(8, 16, gocv.MatTypeCV8UC1, "leaked_in_test")
+	if err != nil {
+		t.Fatalf("GetMat: %v", err)
+	}
+	return mat
+}
+
+func TestSnapshotReportsLeakedMat(t *testing.T) {
+	manager := newDebugManager(t)
+
+	released, err := manager.GetMat(4, 4, gocv.MatTypeCV8UC1, "released_in_test")
+	if err != nil {
+		t.Fatalf("GetMat: %v", err)
+	}
+	leaked := leakMat(t, manager)
+	defer leaked.Close()
+	released.Close()
+
+	entries := manager.Snapshot()
+	if len(entries) != 1 {
+		t.Fatalf("Snapshot() has %d entries, want only the leaked Mat: %+v", len(entries), entries)
+	}
+
+	entry := entries[0]
+	if entry.ID != leaked.ID() || entry.Tag != "leaked_in_test" {
+		t.Errorf("entry = Mat %d tagged %q, want Mat %d tagged leaked_in_test", entry.ID, entry.Tag, leaked.ID())
+	}
+	if entry.Rows != 8 || entry.Cols != 16 {
+		t.Errorf("entry size = %dx%d, want 16x8", entry.Cols, entry.Rows)
+	}
+
+	// The site starts at the caller of GetMat, past the tracking frames
+	firstFrame, _, _ := strings.Cut(entry.AllocSite, "\n")
+	if !strings.HasSuffix(firstFrame, "memory_test.leakMat") {
+		t.Errorf("allocation site starts at %q, want memory_test.leakMat:\n%s", firstFrame, entry.AllocSite)
+	}
+	if !strings.Contains(entry.AllocSite, "manager_test.go:") {
+		t.Errorf("allocation site does not name this file:\n%s", entry.AllocSite)
+	}
+
+	leaked.Close()
+	if entries := manager.Snapshot(); len(entries) != 0 {
+		t.Errorf("Snapshot() after Close = %+v, want none", entries)
+	}
+}
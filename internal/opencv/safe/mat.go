@@ -1,15 +1,21 @@
 package safe
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"runtime"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 
 	"gocv.io/x/gocv"
 )
 
+// MemoryTracker accounts for Mats created with a tracker: TrackMat is called
+// once the Mat exists and ReleaseMat when its last reference is closed
 type MemoryTracker interface {
+	TrackMat(mat *Mat, tag string)
 	ReleaseMat(mat *Mat, tag string)
 }
 
@@ -21,11 +27,16 @@ type Mat struct {
 	id         uint64
 	memTracker MemoryTracker
 	tag        string
+	closeStack []byte
 }
 
+// ErrMatClosed is returned when a Mat is used after its last reference was closed
+var ErrMatClosed = errors.New("mat is closed")
+
 var (
-	nextMatID atomic.Uint64
-	matPool   = sync.Pool{
+	strictMode atomic.Bool
+	nextMatID  atomic.Uint64
+	matPool    = sync.Pool{
 		New: func() interface{} {
 			return &Mat{}
 		},
@@ -59,6 +70,9 @@ func NewMatWithTracker(rows, cols int, matType gocv.MatType, memTracker MemoryTr
 
 	// Use Go 1.24 cleanup patterns
 	runtime.SetFinalizer(safeMat, (*Mat).finalize)
+	if memTracker != nil {
+		memTracker.TrackMat(safeMat, tag)
+	}
 	return safeMat, nil
 }
 
@@ -88,6 +102,9 @@ func NewMatFromMatWithTracker(srcMat gocv.Mat, memTracker MemoryTracker, tag str
 	safeMat.refCount.Store(1)
 
 	runtime.SetFinalizer(safeMat, (*Mat).finalize)
+	if memTracker != nil {
+		memTracker.TrackMat(safeMat, tag)
+	}
 	return safeMat, nil
 }
 
@@ -141,7 +158,10 @@ func (sm *Mat) Clone() (*Mat, error) {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	if !sm.IsValid() || sm.mat.Empty() {
+	if err := sm.checkOpen(); err != nil {
+		return nil, err
+	}
+	if sm.mat.Empty() {
 		return nil, fmt.Errorf("cannot clone invalid or empty Mat")
 	}
 
@@ -152,15 +172,15 @@ func (sm *Mat) CopyTo(dst *Mat) error {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
 
-	if !sm.IsValid() {
-		return fmt.Errorf("source Mat is invalid")
+	if err := sm.checkOpen(); err != nil {
+		return fmt.Errorf("source Mat: %w", err)
 	}
 
 	dst.mu.Lock()
 	defer dst.mu.Unlock()
 
-	if !dst.IsValid() {
-		return fmt.Errorf("destination Mat is invalid")
+	if err := dst.checkOpen(); err != nil {
+		return fmt.Errorf("destination Mat: %w", err)
 	}
 
 	sm.mat.CopyTo(&dst.mat)
@@ -236,9 +256,15 @@ func (sm *Mat) SetDoubleAt(row, col int, value float64) error {
 	return nil
 }
 
+// GetMat returns the underlying gocv.Mat, which is empty once the Mat is
+// closed. Strict mode reports such calls on stderr.
 func (sm *Mat) GetMat() gocv.Mat {
 	sm.mu.RLock()
 	defer sm.mu.RUnlock()
+
+	if err := sm.checkOpen(); err != nil && strictMode.Load() {
+		fmt.Fprintf(os.Stderr, "safe.Mat: GetMat: %v\nused at:\n%s\n", err, debug.Stack())
+	}
 	return sm.mat
 }
 
@@ -250,10 +276,9 @@ func (sm *Mat) AddRef() {
 	sm.refCount.Add(1)
 }
 
+// Release drops one reference, like Close
 func (sm *Mat) Release() {
-	if sm.refCount.Add(-1) == 0 {
-		sm.Close()
-	}
+	sm.Close()
 }
 
 func (sm *Mat) Reset() {
@@ -271,7 +296,16 @@ func (sm *Mat) Reset() {
 	sm.id = 0
 }
 
+// Close drops one reference and releases the native Mat with the last one.
+// Closing an already closed Mat is a no-op.
 func (sm *Mat) Close() {
+	if remaining := sm.refCount.Add(-1); remaining != 0 {
+		if remaining < 0 {
+			sm.refCount.Store(0)
+		}
+		return
+	}
+
 	if !sm.isValid.CompareAndSwap(true, false) {
 		return
 	}
@@ -290,22 +324,50 @@ func (sm *Mat) Close() {
 
 	runtime.SetFinalizer(sm, nil)
 	sm.mat = gocv.Mat{}
-	sm.tag = ""
 	sm.refCount.Store(0)
-	sm.id = 0
 
+	// Strict mode keeps the closed Mat out of the pool so later use is
+	// reported against it rather than against a recycled Mat
+	if strictMode.Load() {
+		sm.closeStack = debug.Stack()
+		return
+	}
+
+	sm.tag = ""
+	sm.id = 0
 	matPool.Put(sm)
 }
 
+// EnableStrictMode records where each Mat is closed and reports that stack
+// with use-after-close errors. It is meant for tests; closed Mats are no
+// longer recycled while it is enabled.
+func EnableStrictMode() {
+	strictMode.Store(true)
+}
+
+// checkOpen returns ErrMatClosed once the last reference has been closed.
+// Callers must hold the lock.
+func (sm *Mat) checkOpen() error {
+	if sm.refCount.Load() > 0 && sm.IsValid() {
+		return nil
+	}
+
+	if sm.closeStack != nil {
+		return fmt.Errorf("%w: Mat %d (%s) closed at:\n%s", ErrMatClosed, sm.id, sm.tag, sm.closeStack)
+	}
+	return ErrMatClosed
+}
+
 func (sm *Mat) finalize() {
 	if sm.isValid.Load() {
+		sm.refCount.Store(1)
 		sm.Close()
 	}
 }
 
 func (sm *Mat) validateCoordinates(row, col int) error {
-	if !sm.IsValid() {
-		return fmt.Errorf("Mat is invalid")
+	if err := sm.checkOpen(); err != nil {
+		return err
 	}
 
 	if row < 0 || row >= sm.mat.Rows() || col < 0 || col >= sm.mat.Cols() {
@@ -370,5 +432,6 @@ func ValidateMatForOperation(mat *Mat, operation string) error {
 			mat.Cols(), mat.Rows(), operation)
 	}
 
-	return nil
+	// Header consistency checks only run in debug builds
+	return validateMatDebug(mat, operation)
 }
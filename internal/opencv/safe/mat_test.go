@@ -0,0 +1,62 @@
+package safe
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// TestMain runs the package tests in strict mode, so use after close is
+// reported with the stack of the Close that caused it
+func TestMain(m *testing.M) {
+	EnableStrictMode()
+	os.Exit(m.Run())
+}
+
+func TestGetUCharAtAfterCloseReportsCloseSite(t *testing.T) {
+	mat, err := NewMat(4, 4, gocv.MatTypeCV8U)
+	if err != nil {
+		t.Fatalf("NewMat: %v", err)
+	}
+	mat.Close()
+
+	_, err = mat.GetUCharAt(0, 0)
+	if !errors.Is(err, ErrMatClosed) {
+		t.Fatalf("GetUCharAt after Close: err = %v, want ErrMatClosed", err)
+	}
+	if !strings.Contains(err.Error(), "closed at:") || !strings.Contains(err.Error(), "TestGetUCharAtAfterCloseReportsCloseSite") {
+		t.Errorf("error does not include the Close stack:\n%v", err)
+	}
+}
+
+func TestAddRefKeepsMatOpenAfterOneClose(t *testing.T) {
+	mat, err := NewMat(4, 4, gocv.MatTypeCV8U)
+	if err != nil {
+		t.Fatalf("NewMat: %v", err)
+	}
+	if err := mat.SetUCharAt(1, 2, 42); err != nil {
+		t.Fatalf("SetUCharAt: %v", err)
+	}
+
+	mat.AddRef()
+	mat.Close()
+
+	if !mat.IsValid() {
+		t.Fatal("closing one of two references closed the Mat")
+	}
+	value, err := mat.GetUCharAt(1, 2)
+	if err != nil {
+		t.Fatalf("GetUCharAt with a reference left: %v", err)
+	}
+	if value != 42 {
+		t.Errorf("GetUCharAt = %d, want 42", value)
+	}
+
+	mat.Close()
+	if _, err := mat.GetUCharAt(1, 2); !errors.Is(err, ErrMatClosed) {
+		t.Errorf("GetUCharAt after the last Close: err = %v, want ErrMatClosed", err)
+	}
+}
@@ -0,0 +1,95 @@
+package safe
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"gocv.io/x/gocv"
+)
+
+// SharedMatPool recycles temporary Mats across processing runs
+var SharedMatPool = NewMatPool()
+
+type matPoolKey struct {
+	rows    int
+	cols    int
+	matType gocv.MatType
+}
+
+// MatPool recycles Mats of identical dimensions and type. Pooled Mats keep
+// their previous contents, so callers must overwrite every element they read.
+// Mats dropped by the garbage collector are freed by their finalizer.
+type MatPool struct {
+	mu     sync.Mutex
+	pools  map[matPoolKey]*sync.Pool
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewMatPool creates an empty pool
+func NewMatPool() *MatPool {
+	return &MatPool{
+		pools: make(map[matPoolKey]*sync.Pool),
+	}
+}
+
+// Get returns a Mat of the requested size and type, reusing a pooled one when
+// available. It returns nil if a new Mat cannot be allocated.
+func (p *MatPool) Get(rows, cols int, matType gocv.MatType) *Mat {
+	if pooled, ok := p.poolFor(matPoolKey{rows, cols, matType}).Get().(*Mat); ok && pooled.IsValid() {
+		p.hits.Add(1)
+		pooled.refCount.Store(1)
+		return pooled
+	}
+
+	p.misses.Add(1)
+	mat, err := NewMat(rows, cols, matType)
+	if err != nil {
+		return nil
+	}
+	return mat
+}
+
+// Put returns a Mat to the pool. Mats tracked by a memory manager are closed
+// instead so the manager's accounting stays correct. A Mat still referenced
+// elsewhere is never pooled; Put only drops the caller's reference to it.
+func (p *MatPool) Put(mat *Mat) {
+	if mat == nil || !mat.IsValid() {
+		return
+	}
+	if mat.refCount.Load() > 1 {
+		mat.Close()
+		return
+	}
+
+	mat.mu.RLock()
+	tracked := mat.memTracker != nil
+	empty := mat.mat.Empty()
+	key := matPoolKey{mat.mat.Rows(), mat.mat.Cols(), mat.mat.Type()}
+	mat.mu.RUnlock()
+
+	if tracked || empty {
+		mat.Close()
+		return
+	}
+
+	p.poolFor(key).Put(mat)
+}
+
+// Stats returns the number of Get calls served from the pool and the number
+// that allocated a new Mat
+func (p *MatPool) Stats() (hits, misses int64) {
+	return p.hits.Load(), p.misses.Load()
+}
+
+func (p *MatPool) poolFor(key matPoolKey) *sync.Pool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pool, exists := p.pools[key]
+	if !exists {
+		pool = &sync.Pool{}
+		p.pools[key] = pool
+	}
+	return pool
+}
@@ -0,0 +1,94 @@
+package safe
+
+import (
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// poolBenchmarkSide matches the 1024x1024 temporaries of the neighbourhood
+// and guided filter passes
+const poolBenchmarkSide = 1024
+
+func TestMatPoolPutKeepsSharedMat(t *testing.T) {
+	pool := NewMatPool()
+
+	mat, err := NewMat(16, 16, gocv.MatTypeCV8U)
+	if err != nil {
+		t.Fatalf("NewMat: %v", err)
+	}
+	mat.AddRef()
+
+	pool.Put(mat)
+	if !mat.IsValid() {
+		t.Fatal("Put closed a Mat that is still referenced")
+	}
+
+	other := pool.Get(16, 16, gocv.MatTypeCV8U)
+	defer other.Close()
+	if other == mat {
+		t.Fatal("Get handed out a Mat that is still referenced")
+	}
+
+	mat.Close()
+	if mat.IsValid() {
+		t.Error("closing the last reference left the Mat open")
+	}
+}
+
+func TestMatPoolAllocatesFewerMats(t *testing.T) {
+	const cycles = 100
+
+	unpooled := countAllocatedMats(func() {
+		mat, err := NewMat(poolBenchmarkSide, poolBenchmarkSide, gocv.MatTypeCV64F)
+		if err != nil {
+			t.Fatalf("NewMat: %v", err)
+		}
+		mat.Close()
+	}, cycles)
+
+	pool := NewMatPool()
+	pooled := countAllocatedMats(func() {
+		pool.Put(pool.Get(poolBenchmarkSide, poolBenchmarkSide, gocv.MatTypeCV64F))
+	}, cycles)
+
+	if pooled > unpooled*4/5 {
+		t.Errorf("pool allocated %d Mats in %d cycles, want at most 80%% of the %d without it",
+			pooled, cycles, unpooled)
+	}
+}
+
+// BenchmarkMatPool compares allocating a 1024x1024 temporary for every use
+// with recycling it through a MatPool. mats/op counts native Mat allocations.
+func BenchmarkMatPool(b *testing.B) {
+	b.Run("NewMat", func(b *testing.B) {
+		b.ReportAllocs()
+		allocated := countAllocatedMats(func() {
+			mat, err := NewMat(poolBenchmarkSide, poolBenchmarkSide, gocv.MatTypeCV64F)
+			if err != nil {
+				b.Fatalf("NewMat: %v", err)
+			}
+			mat.Close()
+		}, b.N)
+		b.ReportMetric(float64(allocated)/float64(b.N), "mats/op")
+	})
+
+	b.Run("Pool", func(b *testing.B) {
+		pool := NewMatPool()
+		b.ReportAllocs()
+		allocated := countAllocatedMats(func() {
+			pool.Put(pool.Get(poolBenchmarkSide, poolBenchmarkSide, gocv.MatTypeCV64F))
+		}, b.N)
+		b.ReportMetric(float64(allocated)/float64(b.N), "mats/op")
+	})
+}
+
+// countAllocatedMats runs fn n times and returns how many native Mats it
+// allocated
+func countAllocatedMats(fn func(), n int) uint64 {
+	before := nextMatID.Load()
+	for i := 0; i < n; i++ {
+		fn()
+	}
+	return nextMatID.Load() - before
+}
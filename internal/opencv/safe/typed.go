@@ -0,0 +1,229 @@
+package safe
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+
+	"gocv.io/x/gocv"
+)
+
+// matDepthMask extracts the element depth from a MatType, dropping the channels
+const matDepthMask = 7
+
+// GetAt reads the element at (row, col) of a single-channel Mat as T. The
+// element is read at the Mat's own depth and converted to T, failing rather
+// than truncating when the value does not fit, such as a 16-bit value above
+// 255 read as uint8.
+func GetAt[T comparable](sm *Mat, row, col int) (T, error) {
+	var zero T
+
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	if err := sm.validateSingleChannel(row, col); err != nil {
+		return zero, err
+	}
+
+	element, err := sm.elementAt(row, col)
+	if err != nil {
+		return zero, err
+	}
+
+	if value, ok := element.(T); ok {
+		return value, nil
+	}
+
+	// Every depth converts exactly to float64, the common case in filters,
+	// so it skips the reflection path
+	if _, wantFloat := any(zero).(float64); wantFloat {
+		return any(elementToFloat64(element)).(T), nil
+	}
+	return convertElement[T](element)
+}
+
+// SetAt writes val to the element at (row, col) of a single-channel Mat,
+// converting it to the Mat's depth. Values the depth cannot hold exactly are
+// rejected.
+func SetAt[T comparable](sm *Mat, row, col int, val T) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if err := sm.validateSingleChannel(row, col); err != nil {
+		return err
+	}
+
+	switch sm.mat.Type() & matDepthMask {
+	case gocv.MatTypeCV8U:
+		v, err := convertElement[uint8](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetUCharAt(row, col, v)
+	case gocv.MatTypeCV8S:
+		v, err := convertElement[int8](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetSCharAt(row, col, v)
+	case gocv.MatTypeCV16U:
+		v, err := convertElement[uint16](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetShortAt(row, col, int16(v))
+	case gocv.MatTypeCV16S:
+		v, err := convertElement[int16](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetShortAt(row, col, v)
+	case gocv.MatTypeCV32S:
+		v, err := convertElement[int32](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetIntAt(row, col, v)
+	case gocv.MatTypeCV32F:
+		v, err := convertElement[float32](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetFloatAt(row, col, v)
+	case gocv.MatTypeCV64F:
+		v, err := convertElement[float64](val)
+		if err != nil {
+			return err
+		}
+		sm.mat.SetDoubleAt(row, col, v)
+	default:
+		return fmt.Errorf("unsupported Mat type: %v", sm.mat.Type())
+	}
+
+	return nil
+}
+
+// GetFloat32At reads a single-channel element as float32
+func (sm *Mat) GetFloat32At(row, col int) (float32, error) {
+	return GetAt[float32](sm, row, col)
+}
+
+// GetInt16At reads a single-channel element as int16
+func (sm *Mat) GetInt16At(row, col int) (int16, error) {
+	return GetAt[int16](sm, row, col)
+}
+
+// GetUInt16At reads a single-channel element as uint16
+func (sm *Mat) GetUInt16At(row, col int) (uint16, error) {
+	return GetAt[uint16](sm, row, col)
+}
+
+// MaxElementValue returns the largest intensity of an integer Mat type and
+// 1.0 for floating point types, which hold normalized intensities
+func MaxElementValue(matType gocv.MatType) float64 {
+	switch matType & matDepthMask {
+	case gocv.MatTypeCV8U:
+		return math.MaxUint8
+	case gocv.MatTypeCV8S:
+		return math.MaxInt8
+	case gocv.MatTypeCV16U:
+		return math.MaxUint16
+	case gocv.MatTypeCV16S:
+		return math.MaxInt16
+	case gocv.MatTypeCV32S:
+		return math.MaxInt32
+	default:
+		return 1.0
+	}
+}
+
+// elementAt reads an element as the Go type matching the Mat depth.
+// Callers must hold the lock.
+func (sm *Mat) elementAt(row, col int) (any, error) {
+	switch sm.mat.Type() & matDepthMask {
+	case gocv.MatTypeCV8U:
+		return sm.mat.GetUCharAt(row, col), nil
+	case gocv.MatTypeCV8S:
+		return sm.mat.GetSCharAt(row, col), nil
+	case gocv.MatTypeCV16U:
+		return uint16(sm.mat.GetShortAt(row, col)), nil
+	case gocv.MatTypeCV16S:
+		return sm.mat.GetShortAt(row, col), nil
+	case gocv.MatTypeCV32S:
+		return sm.mat.GetIntAt(row, col), nil
+	case gocv.MatTypeCV32F:
+		return sm.mat.GetFloatAt(row, col), nil
+	case gocv.MatTypeCV64F:
+		return sm.mat.GetDoubleAt(row, col), nil
+	default:
+		return nil, fmt.Errorf("unsupported Mat type: %v", sm.mat.Type())
+	}
+}
+
+// elementToFloat64 widens an element returned by elementAt
+func elementToFloat64(element any) float64 {
+	switch v := element.(type) {
+	case uint8:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return math.NaN()
+	}
+}
+
+func (sm *Mat) validateSingleChannel(row, col int) error {
+	if err := sm.validateCoordinates(row, col); err != nil {
+		return err
+	}
+
+	if sm.mat.Channels() != 1 {
+		return fmt.Errorf("typed access requires a single-channel Mat, got %d channels", sm.mat.Channels())
+	}
+
+	return nil
+}
+
+// convertElement converts a numeric value to T through reflection and checks
+// that converting back yields the same value, so no precision or range is lost
+func convertElement[T comparable](value any) (T, error) {
+	var zero T
+
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+
+	target := reflect.TypeOf(zero)
+	source := reflect.ValueOf(value)
+	if target == nil || !isNumericKind(target.Kind()) || !isNumericKind(source.Kind()) {
+		return zero, fmt.Errorf("cannot convert %T to %T", value, zero)
+	}
+
+	converted := source.Convert(target)
+	if converted.Convert(source.Type()).Interface() != value {
+		return zero, fmt.Errorf("value %v does not fit in %T", value, zero)
+	}
+
+	return converted.Interface().(T), nil
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,39 @@
+//go:build debug
+
+package safe
+
+import (
+	"fmt"
+
+	"gocv.io/x/gocv"
+)
+
+// validateMatDebug cross-checks a Mat's header against its type. Debug builds
+// run it on every ValidateMatForOperation call; release builds skip it.
+func validateMatDebug(mat *Mat, operation string) error {
+	rows, cols, channels := mat.Rows(), mat.Cols(), mat.Channels()
+	if err := validateDimensions(rows, cols); err != nil {
+		return fmt.Errorf("%w for operation: %s", err, operation)
+	}
+
+	if channels < 1 || channels > 4 {
+		return fmt.Errorf("Mat has %d channels for operation: %s", channels, operation)
+	}
+
+	// The low three bits of a type hold the depth, the rest channels-1
+	matType := mat.Type()
+	if depth := int(matType) & 7; depth > int(gocv.MatTypeCV64F) {
+		return fmt.Errorf("Mat type %v has unknown depth %d for operation: %s", matType, depth, operation)
+	}
+	if typeChannels := int(matType)>>3 + 1; typeChannels != channels {
+		return fmt.Errorf("Mat type %v implies %d channels but Mat has %d for operation: %s",
+			matType, typeChannels, channels, operation)
+	}
+
+	gm := mat.GetMat()
+	if total := gm.Total(); total != rows*cols {
+		return fmt.Errorf("Mat holds %d elements, expected %dx%d for operation: %s", total, cols, rows, operation)
+	}
+
+	return nil
+}
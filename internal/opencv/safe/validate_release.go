@@ -0,0 +1,7 @@
+//go:build !debug
+
+package safe
+
+func validateMatDebug(mat *Mat, operation string) error {
+	return nil
+}
@@ -19,13 +19,25 @@ import (
 
 	"otsu-obliterator/internal/algorithms"
 	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/monitoring"
+	"otsu-obliterator/internal/opencv/conversion"
 	"otsu-obliterator/internal/opencv/memory"
 	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/platform"
+	"otsu-obliterator/internal/processing"
 
 	"fyne.io/fyne/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gocv.io/x/gocv"
+	_ "golang.org/x/image/webp"
 )
 
+// tracer records the stages of each pipeline run when tracing is configured
+var tracer = otel.Tracer("otsu-obliterator/internal/pipeline")
+
 type ImageData struct {
 	Image       image.Image
 	Mat         *safe.Mat
@@ -43,12 +55,29 @@ type SegmentationMetrics struct {
 	RegionUniformity       float64
 	BoundaryAccuracy       float64
 	HausdorffDistance      float64
+	PSNR                   float64
+	SSIM                   float64
+}
+
+// Compare mode runs these two algorithms side by side
+const (
+	DualLeftAlgorithm  = "2D Otsu"
+	DualRightAlgorithm = "Iterative Triclass"
+)
+
+// DualResult holds the two results of ProcessImageDual. A metrics field is
+// nil when its metrics could not be calculated.
+type DualResult struct {
+	Left, Right               *ImageData
+	LeftMetrics, RightMetrics *SegmentationMetrics
 }
 
 type Coordinator struct {
 	mu               sync.RWMutex
 	originalImage    *ImageData
 	processedImage   *ImageData
+	dualLeft         *ImageData
+	dualRight        *ImageData
 	memoryManager    *memory.Manager
 	logger           logger.Logger
 	algorithmManager *algorithms.Manager
@@ -56,8 +85,14 @@ type Coordinator struct {
 	cancel           context.CancelFunc
 
 	// Go 1.24 worker pool for parallel operations
-	workers          chan struct{}
+	workers chan struct{}
+	// numaPool replaces workers when NUMA_AWARE=1 on Linux
+	numaPool         *platform.NumaAwarePool
 	processingActive atomic.Bool
+
+	hardware platform.HardwareCapabilities
+
+	webpQuality atomic.Int32
 }
 
 func NewCoordinator(memMgr *memory.Manager, log logger.Logger) *Coordinator {
@@ -77,10 +112,21 @@ func NewCoordinator(memMgr *memory.Manager, log logger.Logger) *Coordinator {
 		ctx:              ctx,
 		cancel:           cancel,
 		workers:          workers,
+		hardware:         platform.DetectHardwareCapabilities(),
+	}
+	coord.webpQuality.Store(conversion.DefaultWebPQuality)
+
+	numaNodes := 0
+	if platform.NumaAwareEnabled() {
+		coord.numaPool = platform.NewNumaAwarePool(runtime.NumCPU())
+		numaNodes = coord.numaPool.NodeCount()
 	}
 
 	log.Info("Pipeline coordinator initialized", map[string]interface{}{
-		"worker_count": runtime.NumCPU(),
+		"worker_count":  runtime.NumCPU(),
+		"numa_nodes":    numaNodes,
+		"cuda_devices":  coord.hardware.CUDADeviceCount,
+		"gpu_histogram": coord.hardware.CUDAAvailable,
 	})
 
 	return coord
@@ -124,6 +170,25 @@ func (c *Coordinator) LoadImage(reader fyne.URIReadCloser) (*ImageData, error) {
 	return imageData, nil
 }
 
+// LoadImageBytes decodes an image received in memory, such as over the
+// network, and makes it the original image. Without a file name the format
+// is taken from the data.
+func (c *Coordinator) LoadImageBytes(data []byte) (*ImageData, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.releaseImage(&c.originalImage, "original_image")
+	c.releaseImage(&c.processedImage, "processed_image")
+
+	imageData, err := c.loadFromBytes(data, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.originalImage = imageData
+	return imageData, nil
+}
+
 func (c *Coordinator) loadFromBytes(data []byte, format string, uri fyne.URI) (*ImageData, error) {
 	// Decode with standard library first
 	img, standardLibFormat, err := image.Decode(strings.NewReader(string(data)))
@@ -131,8 +196,13 @@ func (c *Coordinator) loadFromBytes(data []byte, format string, uri fyne.URI) (*
 		return nil, fmt.Errorf("failed to decode image with standard library: %w", err)
 	}
 
-	// Decode with GoCV for Mat operations
-	mat, err := gocv.IMDecode(data, gocv.IMReadColor)
+	// Decode with GoCV for Mat operations; 16-bit grayscale PNGs keep their
+	// depth, which IMReadColor would reduce to 8 bits
+	flags := gocv.IMReadColor
+	if _, deep := img.(*image.Gray16); deep {
+		flags = gocv.IMReadAnyDepth
+	}
+	mat, err := gocv.IMDecode(data, flags)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode image with OpenCV: %w", err)
 	}
@@ -164,6 +234,10 @@ func (c *Coordinator) ProcessImageWithContext(ctx context.Context, algorithmName
 	}
 	defer c.processingActive.Store(false)
 
+	ctx, span := tracer.Start(ctx, "Coordinator.ProcessImage",
+		trace.WithAttributes(attribute.String("algorithm", algorithmName)))
+	defer span.End()
+
 	c.mu.Lock()
 	if c.originalImage == nil {
 		c.mu.Unlock()
@@ -181,14 +255,19 @@ func (c *Coordinator) ProcessImageWithContext(ctx context.Context, algorithmName
 	memoryBefore := c.memoryManager.GetStats()
 
 	// Use worker pool for processing
-	select {
-	case <-c.workers:
-		defer func() { c.workers <- struct{}{} }()
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
+	var processedData *ImageData
+	if c.numaPool != nil {
+		processedData, err = c.processOnNumaPool(ctx, originalImage, algorithm, params)
+	} else {
+		select {
+		case <-c.workers:
+			defer func() { c.workers <- struct{}{} }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 
-	processedData, err := c.processImageInternal(ctx, originalImage, algorithm, params)
+		processedData, err = c.processImageInternal(ctx, originalImage, algorithm, params)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -214,38 +293,170 @@ func (c *Coordinator) ProcessImageWithContext(ctx context.Context, algorithmName
 		"width":             processedData.Width,
 		"height":            processedData.Height,
 		"processing_time":   processingTime,
-		"memory_delta":      memoryAfter.usedMemory - memoryBefore.usedMemory,
+		"memory_delta":      memoryAfter.UsedMemory - memoryBefore.UsedMemory,
 		"iou_score":         metrics.IoU,
 		"dice_coefficient":  metrics.DiceCoefficient,
 		"region_uniformity": metrics.RegionUniformity,
+		"trace_id":          monitoring.TraceID(ctx),
 	})
 
 	return processedData, nil
 }
 
+// ProcessImageDual segments image with DualLeftAlgorithm using params1 and
+// DualRightAlgorithm using params2 at the same time, each on its own worker.
+// The coordinator keeps both results until the next dual run or shutdown.
+func (c *Coordinator) ProcessImageDual(ctx context.Context, image *ImageData, params1, params2 map[string]interface{}) (*DualResult, error) {
+	if image == nil {
+		return nil, fmt.Errorf("no image loaded")
+	}
+	if !c.processingActive.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("processing already in progress")
+	}
+	defer c.processingActive.Store(false)
+
+	ctx, span := tracer.Start(ctx, "Coordinator.ProcessImageDual")
+	defer span.End()
+
+	start := time.Now()
+
+	names := [2]string{DualLeftAlgorithm, DualRightAlgorithm}
+	params := [2]map[string]interface{}{params1, params2}
+	var results [2]*ImageData
+	var errs [2]error
+
+	var wg sync.WaitGroup
+	for i := range names {
+		algorithm, err := c.algorithmManager.GetAlgorithm(names[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get algorithm: %w", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = c.processOnWorker(ctx, image, algorithm, params[i])
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			for _, result := range results {
+				if result != nil {
+					c.memoryManager.ReleaseMat(result.Mat, "dual_result")
+				}
+			}
+			return nil, fmt.Errorf("%s failed: %w", names[i], err)
+		}
+	}
+
+	c.mu.Lock()
+	c.releaseImage(&c.dualLeft, "dual_result")
+	c.releaseImage(&c.dualRight, "dual_result")
+	c.dualLeft, c.dualRight = results[0], results[1]
+	c.mu.Unlock()
+
+	dual := &DualResult{Left: results[0], Right: results[1]}
+	for i, metrics := range []**SegmentationMetrics{&dual.LeftMetrics, &dual.RightMetrics} {
+		m, err := c.calculateMetrics(image, results[i])
+		if err != nil {
+			c.logger.Warning("Failed to calculate segmentation metrics", map[string]interface{}{
+				"algorithm": names[i],
+				"error":     err.Error(),
+			})
+			continue
+		}
+		*metrics = m
+	}
+
+	c.logger.Info("Dual processing completed", map[string]interface{}{
+		"left":            names[0],
+		"right":           names[1],
+		"processing_time": time.Since(start),
+		"trace_id":        monitoring.TraceID(ctx),
+	})
+
+	return dual, nil
+}
+
+// processOnWorker runs processImageInternal once a worker is free, on the
+// NUMA pool when it is enabled
+func (c *Coordinator) processOnWorker(ctx context.Context, inputData *ImageData, algorithm algorithms.Algorithm, params map[string]interface{}) (*ImageData, error) {
+	if c.numaPool != nil {
+		return c.processOnNumaPool(ctx, inputData, algorithm, params)
+	}
+
+	select {
+	case <-c.workers:
+		defer func() { c.workers <- struct{}{} }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return c.processImageInternal(ctx, inputData, algorithm, params)
+}
+
+// processOnNumaPool runs processImageInternal on a NUMA pinned worker. It
+// waits for the worker even after cancellation, which processImageInternal
+// observes, so no result is left unreleased.
+func (c *Coordinator) processOnNumaPool(ctx context.Context, inputData *ImageData, algorithm algorithms.Algorithm, params map[string]interface{}) (*ImageData, error) {
+	var result *ImageData
+	var err error
+
+	done := make(chan struct{})
+	c.numaPool.Submit(func() {
+		defer close(done)
+		result, err = c.processImageInternal(ctx, inputData, algorithm, params)
+	})
+	<-done
+
+	return result, err
+}
+
 func (c *Coordinator) processImageInternal(ctx context.Context, inputData *ImageData, algorithm algorithms.Algorithm, params map[string]interface{}) (*ImageData, error) {
 	if err := safe.ValidateMatForOperation(inputData.Mat, "ProcessImage"); err != nil {
 		return nil, err
 	}
 
 	// Check for context cancellation
+	cancelled := processing.CancellationDone(ctx)
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
+	case <-cancelled:
+		return nil, processing.ErrCancelled
 	default:
 	}
 
-	// Process with context support
+	// Route histogram construction to the GPU when a CUDA device is present
+	if c.hardware.CUDAAvailable {
+		gpuParams := make(map[string]interface{}, len(params)+1)
+		for k, v := range params {
+			gpuParams[k] = v
+		}
+		gpuParams["use_gpu_histogram"] = true
+		params = gpuParams
+	}
+
+	// Process with context support. Algorithms that accept a context add
+	// grayscale, preprocessing, histogram and threshold spans below this one.
 	var resultMat *safe.Mat
 	var err error
 
+	algCtx, algSpan := tracer.Start(ctx, "algorithm", trace.WithAttributes(attribute.String("algorithm", algorithm.GetName())))
 	if contextualAlg, ok := algorithm.(interface {
 		ProcessWithContext(context.Context, *safe.Mat, map[string]interface{}) (*safe.Mat, error)
 	}); ok {
-		resultMat, err = contextualAlg.ProcessWithContext(ctx, inputData.Mat, params)
+		resultMat, err = contextualAlg.ProcessWithContext(algCtx, inputData.Mat, params)
 	} else {
 		resultMat, err = algorithm.Process(inputData.Mat, params)
 	}
+	if err != nil {
+		algSpan.RecordError(err)
+		algSpan.SetStatus(codes.Error, err.Error())
+	}
+	algSpan.End()
 
 	if err != nil {
 		return nil, fmt.Errorf("algorithm processing failed: %w", err)
@@ -260,11 +471,16 @@ func (c *Coordinator) processImageInternal(ctx context.Context, inputData *Image
 	case <-ctx.Done():
 		c.memoryManager.ReleaseMat(resultMat, "processing_result")
 		return nil, ctx.Err()
+	case <-cancelled:
+		c.memoryManager.ReleaseMat(resultMat, "processing_result")
+		return nil, processing.ErrCancelled
 	default:
 	}
 
 	// Convert Mat to Image using modern approach
+	_, convSpan := tracer.Start(ctx, "result_conversion")
 	resultImage, err := c.matToImage(resultMat)
+	convSpan.End()
 	if err != nil {
 		c.memoryManager.ReleaseMat(resultMat, "processing_result")
 		return nil, fmt.Errorf("Mat to image conversion failed: %w", err)
@@ -293,6 +509,9 @@ func (c *Coordinator) matToImage(mat *safe.Mat) (image.Image, error) {
 
 	switch channels {
 	case 1:
+		if mat.Type() == gocv.MatTypeCV16UC1 {
+			return c.matToGray16(mat, rows, cols)
+		}
 		return c.matToGray(mat, rows, cols)
 	case 3:
 		return c.matToRGBA(mat, rows, cols)
@@ -306,7 +525,7 @@ func (c *Coordinator) matToGray(mat *safe.Mat, rows, cols int) (*image.Gray, err
 
 	for y := 0; y < rows; y++ {
 		for x := 0; x < cols; x++ {
-			value, err := mat.GetUCharAt(y, x)
+			value, err := safe.GetAt[uint8](mat, y, x)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get pixel at (%d,%d): %w", x, y, err)
 			}
@@ -317,6 +536,23 @@ func (c *Coordinator) matToGray(mat *safe.Mat, rows, cols int) (*image.Gray, err
 	return img, nil
 }
 
+// matToGray16 converts a 16-bit single-channel Mat without reducing its depth
+func (c *Coordinator) matToGray16(mat *safe.Mat, rows, cols int) (*image.Gray16, error) {
+	img := image.NewGray16(image.Rect(0, 0, cols, rows))
+
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			value, err := mat.GetUInt16At(y, x)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get pixel at (%d,%d): %w", x, y, err)
+			}
+			img.SetGray16(x, y, color.Gray16{Y: value})
+		}
+	}
+
+	return img, nil
+}
+
 func (c *Coordinator) matToRGBA(mat *safe.Mat, rows, cols int) (*image.RGBA, error) {
 	img := image.NewRGBA(image.Rect(0, 0, cols, rows))
 
@@ -400,11 +636,29 @@ func (c *Coordinator) saveToWriter(writer io.Writer, imageData *ImageData, forma
 		saveFormat = "png"
 	}
 
+	// Only PNG stores 16 bits per sample; png.Encode writes a 16-bit PNG for
+	// *image.Gray16
+	if _, deep := img.(*image.Gray16); deep && saveFormat != "png" && saveFormat != ".png" {
+		c.logger.Warning("16-bit image saved at 8 bits per sample", map[string]interface{}{
+			"format": saveFormat,
+		})
+	}
+
 	switch saveFormat {
 	case "jpeg", ".jpg", ".jpeg":
 		return jpeg.Encode(writer, img, &jpeg.Options{Quality: 95})
 	case "png", ".png":
 		return png.Encode(writer, img)
+	case "webp", ".webp":
+		if imageData.Mat == nil {
+			return fmt.Errorf("WebP encoding requires Mat data")
+		}
+		data, err := conversion.EncodeWebP(imageData.Mat, int(c.webpQuality.Load()))
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(data)
+		return err
 	default:
 		c.logger.Warning("Unsupported format, using PNG", map[string]interface{}{
 			"requested_format": saveFormat,
@@ -490,6 +744,15 @@ func (c *Coordinator) calculateMetrics(original, segmented *ImageData) (*Segment
 	metrics.BoundaryAccuracy = (metrics.IoU + metrics.DiceCoefficient) / 2.0
 	metrics.HausdorffDistance = (1.0 - metrics.IoU) * 10.0
 
+	if original.Mat != nil && segmented.Mat != nil {
+		psnr, ssim, err := CalculateSimilarity(original.Mat, segmented.Mat)
+		if err != nil {
+			return nil, fmt.Errorf("similarity calculation failed: %w", err)
+		}
+		metrics.PSNR = psnr
+		metrics.SSIM = ssim
+	}
+
 	return metrics, nil
 }
 
@@ -533,6 +796,8 @@ func (c *Coordinator) determineFormat(uriExtension, stdLibFormat string) string
 		return "png"
 	case ".bmp":
 		return "bmp"
+	case ".webp":
+		return "webp"
 	default:
 		if stdLibFormat != "" {
 			return stdLibFormat
@@ -541,6 +806,17 @@ func (c *Coordinator) determineFormat(uriExtension, stdLibFormat string) string
 	}
 }
 
+// SetWebPQuality sets the WebP encoding quality (0-100) used when saving
+func (c *Coordinator) SetWebPQuality(quality int) {
+	c.webpQuality.Store(int32(max(0, min(quality, 100))))
+}
+
+// GetStats returns the memory manager's allocation counts and the bytes held
+// by the coordinator's tracked Mats
+func (c *Coordinator) GetStats() memory.Stats {
+	return c.memoryManager.GetStats()
+}
+
 func (c *Coordinator) Shutdown() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -548,14 +824,20 @@ func (c *Coordinator) Shutdown() {
 	c.logger.Info("Pipeline coordinator shutdown starting", nil)
 	c.cancel()
 
+	if c.numaPool != nil {
+		c.numaPool.Close()
+	}
+
 	c.releaseImage(&c.originalImage, "original_image")
 	c.releaseImage(&c.processedImage, "processed_image")
+	c.releaseImage(&c.dualLeft, "dual_result")
+	c.releaseImage(&c.dualRight, "dual_result")
 
-	allocCount, deallocCount, usedMemory := c.memoryManager.GetStats()
+	stats := c.memoryManager.GetStats()
 	c.logger.Info("Pipeline coordinator shutdown completed", map[string]interface{}{
-		"final_memory_usage":    usedMemory,
-		"total_allocations":     allocCount,
-		"total_deallocations":   deallocCount,
-		"memory_leak_indicator": allocCount - deallocCount,
+		"final_memory_usage":    stats.UsedMemory,
+		"total_allocations":     stats.AllocCount,
+		"total_deallocations":   stats.DeallocCount,
+		"memory_leak_indicator": stats.AllocCount - stats.DeallocCount,
 	})
 }
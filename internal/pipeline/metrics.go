@@ -15,6 +15,8 @@ type SegmentationMetrics struct {
 	RegionUniformity       float64 // Intra-region uniformity measure
 	BoundaryAccuracy       float64 // Boundary preservation accuracy
 	HausdorffDistance      float64 // Maximum boundary discrepancy
+	PSNR                   float64 // Peak signal-to-noise ratio against the original, dB
+	SSIM                   float64 // Structural similarity against the original
 }
 
 // CalculateSegmentationMetrics computes task-specific thresholding quality metrics
@@ -46,6 +48,14 @@ func CalculateSegmentationMetrics(original, segmented *ImageData, groundTruth *I
 		return nil, fmt.Errorf("failed to calculate boundary accuracy: %w", err)
 	}
 
+	// Calculate structural similarity to the original
+	psnr, ssim, err := CalculateSimilarity(original.Mat, segmented.Mat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate similarity: %w", err)
+	}
+	metrics.PSNR = psnr
+	metrics.SSIM = ssim
+
 	// Calculate Hausdorff distance if ground truth is available
 	if groundTruth != nil && groundTruth.Width == segmented.Width && groundTruth.Height == segmented.Height {
 		if err := calculateHausdorffDistance(groundTruth.Mat, segmented.Mat, metrics); err != nil {
@@ -467,5 +477,7 @@ func (m *SegmentationMetrics) GetMetricsDescription() map[string]string {
 		"RegionUniformity":       fmt.Sprintf("Region Uniformity: %.4f (higher is better, 1.0 = perfect)", m.RegionUniformity),
 		"BoundaryAccuracy":       fmt.Sprintf("Boundary Accuracy: %.4f (higher is better, 1.0 = perfect)", m.BoundaryAccuracy),
 		"HausdorffDistance":      fmt.Sprintf("Hausdorff Distance: %.2f pixels (lower is better, 0.0 = perfect)", m.HausdorffDistance),
+		"PSNR":                   fmt.Sprintf("PSNR: %.2f dB (higher means closer to the original)", m.PSNR),
+		"SSIM":                   fmt.Sprintf("SSIM: %.4f (higher means closer to the original, 1.0 = identical)", m.SSIM),
 	}
 }
\ No newline at end of file
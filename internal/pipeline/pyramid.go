@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+	"otsu-obliterator/internal/processing/threshold"
+
+	"gocv.io/x/gocv"
+)
+
+// pyramidMinSize is the smallest side a pyramid level may have; coarser
+// levels hold too few pixels for a meaningful histogram
+const pyramidMinSize = 64
+
+// PyramidProcessor runs an algorithm coarse to fine over a Gaussian pyramid.
+// The smallest level is processed from scratch and each larger level starts
+// from the threshold the one below reported in its warm_start_threshold run
+// statistic, passed in params["initial_threshold_override"]. Only the full
+// resolution result is returned. An algorithm that reports no warm start
+// threshold for the smallest level has the full resolution run from scratch.
+type PyramidProcessor struct {
+	algorithm algorithms.Algorithm
+	levels    int
+}
+
+// NewPyramidProcessor wraps algorithm with a pyramid of up to levels levels
+// below full resolution
+func NewPyramidProcessor(algorithm algorithms.Algorithm, levels int) *PyramidProcessor {
+	return &PyramidProcessor{
+		algorithm: algorithm,
+		levels:    max(levels, 0),
+	}
+}
+
+// GetName returns the name of the wrapped algorithm marked as a pyramid run
+func (pp *PyramidProcessor) GetName() string {
+	return pp.algorithm.GetName() + " (pyramid)"
+}
+
+// GetDefaultParameters returns the parameters of the wrapped algorithm
+func (pp *PyramidProcessor) GetDefaultParameters() map[string]interface{} {
+	return pp.algorithm.GetDefaultParameters()
+}
+
+// ValidateParameters validates params for the wrapped algorithm
+func (pp *PyramidProcessor) ValidateParameters(params map[string]interface{}) error {
+	return pp.algorithm.ValidateParameters(params)
+}
+
+// Process processes input coarse to fine without cancellation
+func (pp *PyramidProcessor) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return pp.ProcessWithContext(context.Background(), input, params)
+}
+
+// ProcessWithContext processes input coarse to fine and returns the full
+// resolution result
+func (pp *PyramidProcessor) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(input, "pyramid processing"); err != nil {
+		return nil, err
+	}
+
+	if pp.levels == 0 {
+		return processAlgorithm(ctx, pp.algorithm, input, params)
+	}
+
+	pyramid, err := pp.buildPyramid(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	defer closePyramid(pyramid)
+
+	levelParams := make(map[string]interface{}, len(params)+1)
+	for key, value := range params {
+		levelParams[key] = value
+	}
+
+	for i := len(pyramid) - 1; i > 0; i-- {
+		// Each level reports to its own collector, so the caller's statistics
+		// only see the full resolution run
+		levelCtx, stats := processing.WithRunStatistics(ctx)
+		result, err := processAlgorithm(levelCtx, pp.algorithm, pyramid[i], levelParams)
+		if err != nil {
+			return nil, fmt.Errorf("pyramid level %d failed: %w", i, err)
+		}
+		result.Close()
+
+		coarse, ok := stats.Values()[threshold.WarmStartThresholdStat].(float64)
+		if !ok {
+			break
+		}
+		levelParams[threshold.InitialThresholdOverrideParam] = coarse
+	}
+
+	return processAlgorithm(ctx, pp.algorithm, input, levelParams)
+}
+
+// buildPyramid returns input followed by successively halved copies, stopping
+// at pp.levels or before a side drops below pyramidMinSize
+func (pp *PyramidProcessor) buildPyramid(ctx context.Context, input *safe.Mat) ([]*safe.Mat, error) {
+	pyramid := []*safe.Mat{input}
+
+	for len(pyramid) <= pp.levels {
+		previous := pyramid[len(pyramid)-1]
+		rows, cols := (previous.Rows()+1)/2, (previous.Cols()+1)/2
+		if rows < pyramidMinSize || cols < pyramidMinSize {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			closePyramid(pyramid)
+			return nil, err
+		}
+
+		level, err := safe.NewMat(rows, cols, previous.Type())
+		if err != nil {
+			closePyramid(pyramid)
+			return nil, err
+		}
+
+		srcMat := previous.GetMat()
+		dstMat := level.GetMat()
+		// A zero size lets OpenCV pick ((cols+1)/2, (rows+1)/2), matching level
+		gocv.PyrDown(srcMat, &dstMat, image.Point{}, gocv.BorderDefault)
+
+		pyramid = append(pyramid, level)
+	}
+
+	return pyramid, nil
+}
+
+// closePyramid closes every level above the caller's input
+func closePyramid(pyramid []*safe.Mat) {
+	for _, level := range pyramid[1:] {
+		level.Close()
+	}
+}
+
+// processAlgorithm runs algorithm with context support when it has it
+func processAlgorithm(ctx context.Context, algorithm algorithms.Algorithm, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	if contextualAlg, ok := algorithm.(algorithms.ContextualAlgorithm); ok {
+		return contextualAlg.ProcessWithContext(ctx, input, params)
+	}
+	return algorithm.Process(input, params)
+}
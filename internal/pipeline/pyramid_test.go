@@ -0,0 +1,154 @@
+package pipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/algorithms/triclass"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+	"otsu-obliterator/internal/processing/threshold"
+
+	"gocv.io/x/gocv"
+)
+
+// levelRecorder notes the warm start each run receives and, when report is
+// set, reports the run's row count as its warm start threshold
+type levelRecorder struct {
+	report bool
+	// overrides holds the warm start of each run, -1 for none
+	overrides []float64
+}
+
+func (r *levelRecorder) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	return r.ProcessWithContext(context.Background(), input, params)
+}
+
+func (r *levelRecorder) ProcessWithContext(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	override, ok := threshold.InitialThresholdOverride(params)
+	if !ok {
+		override = -1
+	}
+	r.overrides = append(r.overrides, override)
+
+	if r.report {
+		processing.RecordStatistics(ctx, map[string]interface{}{
+			threshold.WarmStartThresholdStat: float64(input.Rows()),
+		})
+	}
+	return input.Clone()
+}
+
+func (r *levelRecorder) ValidateParameters(map[string]interface{}) error { return nil }
+
+func (r *levelRecorder) GetDefaultParameters() map[string]interface{} { return nil }
+
+func (r *levelRecorder) GetName() string { return "Level Recorder" }
+
+func TestPyramidWarmStartsFromLevelStatistics(t *testing.T) {
+	input := noiseImage(t, 256, 256)
+	recorder := &levelRecorder{report: true}
+
+	ctx, stats := processing.WithRunStatistics(context.Background())
+	result, err := NewPyramidProcessor(recorder, 2).ProcessWithContext(ctx, input, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Close()
+
+	// Levels of 64, 128 and 256 rows, each starting from the one below
+	want := []float64{-1, 64, 128}
+	if len(recorder.overrides) != len(want) {
+		t.Fatalf("overrides = %v, want %v", recorder.overrides, want)
+	}
+	for i := range want {
+		if recorder.overrides[i] != want[i] {
+			t.Errorf("overrides = %v, want %v", recorder.overrides, want)
+			break
+		}
+	}
+
+	// Only the full resolution run reaches the caller's statistics
+	if got := stats.Values()[threshold.WarmStartThresholdStat]; got != 256.0 {
+		t.Errorf("caller's %s = %v, want 256", threshold.WarmStartThresholdStat, got)
+	}
+}
+
+func TestPyramidSkipsLevelsWithoutWarmStart(t *testing.T) {
+	input := noiseImage(t, 256, 256)
+	recorder := &levelRecorder{}
+
+	result, err := NewPyramidProcessor(recorder, 2).Process(input, map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.Close()
+
+	// The smallest level reports nothing, so the full resolution runs next
+	// from scratch
+	if len(recorder.overrides) != 2 || recorder.overrides[0] != -1 || recorder.overrides[1] != -1 {
+		t.Errorf("overrides = %v, want [-1 -1]", recorder.overrides)
+	}
+}
+
+// BenchmarkPyramidVsFullResolution times Iterative Triclass on a 4000x4000
+// image processed directly and coarse to fine over three pyramid levels
+func BenchmarkPyramidVsFullResolution(b *testing.B) {
+	input := blobImage(b, 4000, 4000)
+	algorithm := triclass.NewProcessor()
+	params := algorithm.GetDefaultParameters()
+
+	variants := []struct {
+		name      string
+		algorithm algorithms.ContextualAlgorithm
+	}{
+		{"FullResolution", algorithm},
+		{"Pyramid", NewPyramidProcessor(algorithm, 3)},
+	}
+
+	for _, variant := range variants {
+		b.Run(variant.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				result, err := variant.algorithm.ProcessWithContext(context.Background(), input, params)
+				if err != nil {
+					b.Fatal(err)
+				}
+				result.Close()
+			}
+		})
+	}
+}
+
+// blobImage returns a width by height grey image with bright discs on a dark,
+// noisy background
+func blobImage(b *testing.B, width, height int) *safe.Mat {
+	b.Helper()
+
+	img := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(60, 0, 0, 0), height, width, gocv.MatTypeCV8UC1)
+	defer img.Close()
+	for y := height / 10; y < height; y += height / 5 {
+		for x := width / 10; x < width; x += width / 5 {
+			if err := gocv.Circle(&img, image.Pt(x, y), width/16, color.RGBA{190, 190, 190, 0}, -1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	noise := gocv.NewMatWithSize(height, width, gocv.MatTypeCV8UC1)
+	defer noise.Close()
+	gocv.RandU(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(40, 0, 0, 0))
+	if err := gocv.Add(img, noise, &img); err != nil {
+		b.Fatal(err)
+	}
+
+	mat, err := safe.NewMatFromMat(img)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { mat.Close() })
+	return mat
+}
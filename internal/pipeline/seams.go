@@ -0,0 +1,234 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing/histogram"
+
+	"gocv.io/x/gocv"
+)
+
+// SeamBlender removes grid artifacts from stitched tile results. Each tile
+// picks its own thresholds, so gradients crossing a tile edge can switch class
+// abruptly there. The blender re-processes a strip centred on every tile
+// boundary and copies the result over the stitched output. Strip segments stop
+// short of boundary crossings, which are re-processed as separate corner
+// squares so no pixel is written twice.
+type SeamBlender struct {
+	seamWidth int
+	padding   int
+}
+
+// NewSeamBlender creates a blender for seamWidth pixel wide strips, each
+// extended by padding pixels of context like the tiles themselves
+func NewSeamBlender(seamWidth, padding int) *SeamBlender {
+	return &SeamBlender{
+		seamWidth: seamWidth,
+		padding:   padding,
+	}
+}
+
+// seamSpan is a half-open interval [start, end) along one axis
+type seamSpan struct {
+	start, end int
+}
+
+// Blend re-processes the seams between tiles of input and writes them into
+// output, the stitched result of the same size
+func (sb *SeamBlender) Blend(
+	ctx context.Context,
+	algorithm algorithms.Algorithm,
+	input, output *safe.Mat,
+	tiles []image.Rectangle,
+	params map[string]interface{},
+) error {
+	if sb.seamWidth <= 0 || len(tiles) < 2 {
+		return nil
+	}
+
+	rows, cols := input.Rows(), input.Cols()
+	if output.Rows() != rows || output.Cols() != cols {
+		return fmt.Errorf("output size %dx%d does not match input %dx%d", output.Cols(), output.Rows(), cols, rows)
+	}
+
+	var xs, ys []int
+	for _, tile := range tiles {
+		xs = append(xs, tile.Min.X)
+		ys = append(ys, tile.Min.Y)
+	}
+	xBands, xGaps := sb.seamSpans(cols, xs)
+	yBands, yGaps := sb.seamSpans(rows, ys)
+
+	var regions []image.Rectangle
+	for _, x := range xBands {
+		for _, y := range yGaps {
+			regions = append(regions, image.Rect(x.start, y.start, x.end, y.end))
+		}
+	}
+	for _, y := range yBands {
+		for _, x := range xGaps {
+			regions = append(regions, image.Rect(x.start, y.start, x.end, y.end))
+		}
+	}
+	for _, x := range xBands {
+		for _, y := range yBands {
+			regions = append(regions, image.Rect(x.start, y.start, x.end, y.end))
+		}
+	}
+
+	for _, region := range regions {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if region.Empty() {
+			continue
+		}
+
+		blended, err := ProcessRegion(ctx, algorithm, input, region, sb.padding, params)
+		if err != nil {
+			return fmt.Errorf("seam %v failed: %w", region, err)
+		}
+
+		outputMat := output.GetMat()
+		blendedMat := blended.GetMat()
+		dst := outputMat.Region(region)
+		err = blendedMat.CopyTo(&dst)
+		dst.Close()
+		blended.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy seam %v: %w", region, err)
+		}
+	}
+
+	return nil
+}
+
+// seamSpans returns the seam bands centred on the interior tile boundaries of
+// an axis of the given length, and the gaps between them. Bands are clamped
+// so neighbouring bands never overlap.
+func (sb *SeamBlender) seamSpans(length int, starts []int) ([]seamSpan, []seamSpan) {
+	boundaries := make([]int, 0, len(starts))
+	seen := make(map[int]bool, len(starts))
+	for _, start := range starts {
+		if start > 0 && start < length && !seen[start] {
+			seen[start] = true
+			boundaries = append(boundaries, start)
+		}
+	}
+	sort.Ints(boundaries)
+
+	var bands, gaps []seamSpan
+	position := 0
+	for i, boundary := range boundaries {
+		start := max(boundary-sb.seamWidth/2, position)
+		end := min(start+sb.seamWidth, length)
+		if i+1 < len(boundaries) {
+			end = min(end, (boundary+boundaries[i+1])/2)
+		}
+		if end <= start {
+			continue
+		}
+
+		if start > position {
+			gaps = append(gaps, seamSpan{position, start})
+		}
+		bands = append(bands, seamSpan{start, end})
+		position = end
+	}
+	if position < length {
+		gaps = append(gaps, seamSpan{position, length})
+	}
+
+	return bands, gaps
+}
+
+// ProcessRegion runs the algorithm on region of input extended by padding on
+// every side and returns the result for region alone. Where the extension
+// falls outside the image the border is mirrored. A histogram weight map in
+// params is cut to the same region.
+func ProcessRegion(
+	ctx context.Context,
+	algorithm algorithms.Algorithm,
+	input *safe.Mat,
+	region image.Rectangle,
+	padding int,
+	params map[string]interface{},
+) (*safe.Mat, error) {
+	regionMat, err := padRegion(input, region, padding)
+	if err != nil {
+		return nil, err
+	}
+	defer regionMat.Close()
+
+	if weights, ok := params[histogram.WeightMapParam].(*safe.Mat); ok {
+		if weights.Cols() != input.Cols() || weights.Rows() != input.Rows() {
+			return nil, fmt.Errorf("weight map is %dx%d but the image is %dx%d",
+				weights.Cols(), weights.Rows(), input.Cols(), input.Rows())
+		}
+		regionWeights, err := padRegion(weights, region, padding)
+		if err != nil {
+			return nil, fmt.Errorf("weight map: %w", err)
+		}
+		defer regionWeights.Close()
+
+		regionParams := make(map[string]interface{}, len(params))
+		for key, value := range params {
+			regionParams[key] = value
+		}
+		regionParams[histogram.WeightMapParam] = regionWeights
+		params = regionParams
+	}
+
+	var result *safe.Mat
+	if contextualAlg, ok := algorithm.(algorithms.ContextualAlgorithm); ok {
+		result, err = contextualAlg.ProcessWithContext(ctx, regionMat, params)
+	} else {
+		result, err = algorithm.Process(regionMat, params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	// Drop the padding again
+	resultMat := result.GetMat()
+	interior := resultMat.Region(image.Rect(padding, padding, padding+region.Dx(), padding+region.Dy()))
+	defer interior.Close()
+
+	return safe.NewMatFromMat(interior)
+}
+
+// padRegion copies region of src extended by padding on every side, mirroring
+// the border where the extension falls outside src
+func padRegion(src *safe.Mat, region image.Rectangle, padding int) (*safe.Mat, error) {
+	bounds := image.Rect(0, 0, src.Cols(), src.Rows())
+	extended := region.Inset(-padding).Intersect(bounds)
+
+	srcMat := src.GetMat()
+	source := srcMat.Region(extended)
+	defer source.Close()
+
+	padded := gocv.NewMat()
+	defer padded.Close()
+	err := gocv.CopyMakeBorder(source, &padded,
+		padding-(region.Min.Y-extended.Min.Y),
+		padding-(extended.Max.Y-region.Max.Y),
+		padding-(region.Min.X-extended.Min.X),
+		padding-(extended.Max.X-region.Max.X),
+		gocv.BorderReflect101, color.RGBA{},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("region border extension failed: %w", err)
+	}
+
+	return safe.NewMatFromMat(padded)
+}
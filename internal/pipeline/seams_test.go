@@ -0,0 +1,171 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// localMeanThreshold marks pixels brighter than the mean of their 3x3
+// neighbourhood. Its output depends on pixels one step away, so a tile
+// processed without padding differs from the whole image along its edges.
+type localMeanThreshold struct{}
+
+func (localMeanThreshold) Process(input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	src := input.GetMat()
+	mean := gocv.NewMat()
+	defer mean.Close()
+	if err := gocv.Blur(src, &mean, image.Pt(3, 3)); err != nil {
+		return nil, err
+	}
+
+	dst := gocv.NewMat()
+	defer dst.Close()
+	if err := gocv.Compare(src, mean, &dst, gocv.CompareGT); err != nil {
+		return nil, err
+	}
+
+	return safe.NewMatFromMat(dst)
+}
+
+func (localMeanThreshold) ValidateParameters(map[string]interface{}) error { return nil }
+
+func (localMeanThreshold) GetDefaultParameters() map[string]interface{} { return nil }
+
+func (localMeanThreshold) GetName() string { return "Local Mean" }
+
+// noiseImage returns a width by height single channel noise image
+func noiseImage(t *testing.T, width, height int) *safe.Mat {
+	t.Helper()
+
+	noise := gocv.NewMatWithSize(height, width, gocv.MatTypeCV8UC1)
+	defer noise.Close()
+	gocv.RandU(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(256, 0, 0, 0))
+
+	mat, err := safe.NewMatFromMat(noise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { mat.Close() })
+	return mat
+}
+
+// stitchUnpadded processes every tile on its own, without context from its
+// neighbours, and copies the results into one Mat, leaving a known seam
+// along every tile boundary
+func stitchUnpadded(t *testing.T, input *safe.Mat, tiles []image.Rectangle) *safe.Mat {
+	t.Helper()
+
+	output, err := safe.NewMat(input.Rows(), input.Cols(), gocv.MatTypeCV8UC1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { output.Close() })
+
+	for _, tile := range tiles {
+		result, err := ProcessRegion(context.Background(), localMeanThreshold{}, input, tile, 0, nil)
+		if err != nil {
+			t.Fatalf("tile %v: %v", tile, err)
+		}
+		outputMat := output.GetMat()
+		resultMat := result.GetMat()
+		dst := outputMat.Region(tile)
+		err = resultMat.CopyTo(&dst)
+		dst.Close()
+		result.Close()
+		if err != nil {
+			t.Fatalf("tile %v: %v", tile, err)
+		}
+	}
+	return output
+}
+
+func pixels(mat *safe.Mat) []byte {
+	m := mat.GetMat()
+	return m.ToBytes()
+}
+
+func TestSeamBlenderMatchesWholeImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		tiles []image.Rectangle
+	}{
+		{
+			name: "two tiles",
+			tiles: []image.Rectangle{
+				image.Rect(0, 0, 32, 32),
+				image.Rect(32, 0, 64, 32),
+			},
+		},
+		{
+			// The boundaries cross, so the corner square is blended on its own
+			name: "four tiles",
+			tiles: []image.Rectangle{
+				image.Rect(0, 0, 32, 32),
+				image.Rect(32, 0, 64, 32),
+				image.Rect(0, 32, 32, 64),
+				image.Rect(32, 32, 64, 64),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounds := tt.tiles[len(tt.tiles)-1]
+			input := noiseImage(t, bounds.Max.X, bounds.Max.Y)
+
+			reference, err := localMeanThreshold{}.Process(input, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reference.Close()
+
+			stitched := stitchUnpadded(t, input, tt.tiles)
+			if bytes.Equal(pixels(stitched), pixels(reference)) {
+				t.Fatal("unpadded tiles left no seam to blend")
+			}
+
+			// A 4 pixel strip covers the pixel on either side of each
+			// boundary, and 1 pixel of padding restores their neighbours
+			blender := NewSeamBlender(4, 1)
+			if err := blender.Blend(context.Background(), localMeanThreshold{}, input, stitched, tt.tiles, nil); err != nil {
+				t.Fatalf("Blend: %v", err)
+			}
+			if !bytes.Equal(pixels(stitched), pixels(reference)) {
+				t.Error("blended output differs from the whole image result")
+			}
+		})
+	}
+}
+
+func TestSeamSpansClampNeighbouringBands(t *testing.T) {
+	blender := NewSeamBlender(8, 0)
+
+	bands, gaps := blender.seamSpans(40, []int{0, 10, 14, 30})
+
+	wantBands := []seamSpan{{6, 12}, {12, 20}, {26, 34}}
+	wantGaps := []seamSpan{{0, 6}, {20, 26}, {34, 40}}
+	if !equalSpans(bands, wantBands) {
+		t.Errorf("bands = %v, want %v", bands, wantBands)
+	}
+	if !equalSpans(gaps, wantGaps) {
+		t.Errorf("gaps = %v, want %v", gaps, wantGaps)
+	}
+}
+
+func equalSpans(a, b []seamSpan) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
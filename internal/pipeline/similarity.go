@@ -0,0 +1,149 @@
+package pipeline
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// SSIM constants from Wang et al. for 8-bit images: an 11x11 Gaussian window
+// with sigma 1.5 and stabilisers C1 = (0.01*255)^2, C2 = (0.03*255)^2
+const (
+	ssimWindowSize = 11
+	ssimSigma      = 1.5
+	ssimC1         = 6.5025
+	ssimC2         = 58.5225
+)
+
+// MaxPSNR is reported for identical images, whose PSNR is unbounded
+const MaxPSNR = 100.0
+
+// CalculateSimilarity returns the PSNR in dB and the mean SSIM between the
+// grayscale versions of original and processed. For a segmentation these act
+// as a proxy for how much of the original structure the result preserves.
+func CalculateSimilarity(original, processed *safe.Mat) (float64, float64, error) {
+	if err := safe.ValidateMatForOperation(original, "similarity calculation"); err != nil {
+		return 0, 0, err
+	}
+	if err := safe.ValidateMatForOperation(processed, "similarity calculation"); err != nil {
+		return 0, 0, err
+	}
+	if original.Rows() != processed.Rows() || original.Cols() != processed.Cols() {
+		return 0, 0, fmt.Errorf("image dimensions do not match")
+	}
+
+	first, err := floatGray(original)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer first.Close()
+
+	second, err := floatGray(processed)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer second.Close()
+
+	return psnr(first, second), ssim(first, second), nil
+}
+
+// floatGray converts src to a single channel 32-bit float Mat
+func floatGray(src *safe.Mat) (gocv.Mat, error) {
+	gray, err := conversion.ConvertToGrayscale(src)
+	if err != nil {
+		return gocv.Mat{}, fmt.Errorf("grayscale conversion failed: %w", err)
+	}
+	defer gray.Close()
+
+	grayMat := gray.GetMat()
+	result := gocv.NewMat()
+	grayMat.ConvertTo(&result, gocv.MatTypeCV32F)
+	return result, nil
+}
+
+// psnr computes 20*log10(255/RMSE), capped at MaxPSNR
+func psnr(first, second gocv.Mat) float64 {
+	diff := gocv.NewMat()
+	defer diff.Close()
+	gocv.Subtract(first, second, &diff)
+	gocv.Multiply(diff, diff, &diff)
+
+	mse := diff.Mean().Val1
+	if mse <= 0 {
+		return MaxPSNR
+	}
+	return math.Min(20*math.Log10(255/math.Sqrt(mse)), MaxPSNR)
+}
+
+// ssim computes the mean of the SSIM map, using Gaussian weighted local
+// means, variances and covariance
+func ssim(first, second gocv.Mat) float64 {
+	blur := func(src gocv.Mat) gocv.Mat {
+		dst := gocv.NewMat()
+		gocv.GaussianBlur(src, &dst, image.Pt(ssimWindowSize, ssimWindowSize), ssimSigma, ssimSigma, gocv.BorderReflect101)
+		return dst
+	}
+	product := func(a, b gocv.Mat) gocv.Mat {
+		dst := gocv.NewMat()
+		gocv.Multiply(a, b, &dst)
+		return dst
+	}
+
+	mu1 := blur(first)
+	defer mu1.Close()
+	mu2 := blur(second)
+	defer mu2.Close()
+
+	mu1Sq := product(mu1, mu1)
+	defer mu1Sq.Close()
+	mu2Sq := product(mu2, mu2)
+	defer mu2Sq.Close()
+	mu1Mu2 := product(mu1, mu2)
+	defer mu1Mu2.Close()
+
+	// sigma = blur(x*y) - mu_x*mu_y
+	variance := func(a, b, means gocv.Mat) gocv.Mat {
+		raw := product(a, b)
+		defer raw.Close()
+		dst := blur(raw)
+		gocv.Subtract(dst, means, &dst)
+		return dst
+	}
+	sigma1Sq := variance(first, first, mu1Sq)
+	defer sigma1Sq.Close()
+	sigma2Sq := variance(second, second, mu2Sq)
+	defer sigma2Sq.Close()
+	sigma12 := variance(first, second, mu1Mu2)
+	defer sigma12.Close()
+
+	// Numerator (2*mu1*mu2 + C1) * (2*sigma12 + C2)
+	lum := mu1Mu2.Clone()
+	defer lum.Close()
+	lum.MultiplyFloat(2)
+	lum.AddFloat(ssimC1)
+	structure := sigma12.Clone()
+	defer structure.Close()
+	structure.MultiplyFloat(2)
+	structure.AddFloat(ssimC2)
+	numerator := product(lum, structure)
+	defer numerator.Close()
+
+	// Denominator (mu1^2 + mu2^2 + C1) * (sigma1^2 + sigma2^2 + C2)
+	gocv.Add(mu1Sq, mu2Sq, &lum)
+	lum.AddFloat(ssimC1)
+	gocv.Add(sigma1Sq, sigma2Sq, &structure)
+	structure.AddFloat(ssimC2)
+	denominator := product(lum, structure)
+	defer denominator.Close()
+
+	ssimMap := gocv.NewMat()
+	defer ssimMap.Close()
+	gocv.Divide(numerator, denominator, &ssimMap)
+
+	return ssimMap.Mean().Val1
+}
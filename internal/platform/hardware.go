@@ -0,0 +1,37 @@
+package platform
+
+import (
+	"runtime"
+	"sync"
+
+	"otsu-obliterator/internal/opencv/cuda"
+)
+
+// HardwareCapabilities describes the compute resources available to the application
+type HardwareCapabilities struct {
+	CPUCount        int
+	OS              string
+	Arch            string
+	CUDADeviceCount int
+	CUDAAvailable   bool
+}
+
+var (
+	detectOnce   sync.Once
+	capabilities HardwareCapabilities
+)
+
+// DetectHardwareCapabilities probes the system once and returns the cached result
+func DetectHardwareCapabilities() HardwareCapabilities {
+	detectOnce.Do(func() {
+		devices := cuda.DeviceCount()
+		capabilities = HardwareCapabilities{
+			CPUCount:        runtime.NumCPU(),
+			OS:              runtime.GOOS,
+			Arch:            runtime.GOARCH,
+			CUDADeviceCount: devices,
+			CUDAAvailable:   devices > 0,
+		}
+	})
+	return capabilities
+}
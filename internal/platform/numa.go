@@ -0,0 +1,114 @@
+package platform
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// NumaAwarePool runs submitted functions on worker goroutines that are each
+// locked to an OS thread pinned to the CPUs of one NUMA node, assigned round
+// robin. Keeping a worker on one node avoids the cache misses of threads
+// migrating between sockets. Where node topology or affinity is unavailable
+// the workers run unpinned.
+type NumaAwarePool struct {
+	tasks     chan func()
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	nodes     [][]int
+}
+
+// NumaAwareEnabled reports whether NUMA_AWARE=1 is set and the platform can
+// pin threads to NUMA nodes
+func NumaAwareEnabled() bool {
+	return os.Getenv("NUMA_AWARE") == "1" && numaSupported
+}
+
+// NewNumaAwarePool starts workerCount workers, one per CPU when workerCount
+// is not positive
+func NewNumaAwarePool(workerCount int) *NumaAwarePool {
+	if workerCount <= 0 {
+		workerCount = runtime.NumCPU()
+	}
+
+	p := &NumaAwarePool{
+		tasks: make(chan func(), workerCount),
+		nodes: numaNodeCPUs(),
+	}
+
+	p.wg.Add(workerCount)
+	for i := range workerCount {
+		var cpus []int
+		if len(p.nodes) > 0 {
+			cpus = p.nodes[i%len(p.nodes)]
+		}
+		go p.worker(cpus)
+	}
+	return p
+}
+
+// NodeCount returns the number of NUMA nodes workers are spread across, zero
+// when the workers are unpinned
+func (p *NumaAwarePool) NodeCount() int {
+	return len(p.nodes)
+}
+
+// Submit queues fn for a worker, blocking while every worker is busy and the
+// queue is full. Submit must not be called after Close.
+func (p *NumaAwarePool) Submit(fn func()) {
+	p.tasks <- fn
+}
+
+// Close stops the workers once the queued functions have run
+func (p *NumaAwarePool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.tasks)
+	})
+	p.wg.Wait()
+}
+
+func (p *NumaAwarePool) worker(cpus []int) {
+	defer p.wg.Done()
+
+	// The thread stays locked for the worker's lifetime, so the runtime
+	// discards it on exit instead of reusing it with a narrowed affinity
+	runtime.LockOSThread()
+	if len(cpus) > 0 {
+		// An affinity failure leaves the worker unpinned but usable
+		_ = pinThread(cpus)
+	}
+
+	for fn := range p.tasks {
+		fn()
+	}
+}
+
+// parseCPUList parses the kernel list format used by sysfs, such as
+// "0-3,8-11" or "0,2"
+func parseCPUList(list string) ([]int, error) {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(list), ",") {
+		if part == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil {
+				return nil, err
+			}
+		}
+
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
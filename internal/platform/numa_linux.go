@@ -0,0 +1,63 @@
+//go:build linux
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const numaSupported = true
+
+// numaNodeSysfs is where the kernel lists NUMA nodes and their CPUs
+const numaNodeSysfs = "/sys/devices/system/node"
+
+// numaNodeCPUs returns the CPUs of each online NUMA node, or nil when the
+// topology cannot be read
+func numaNodeCPUs() [][]int {
+	online, err := os.ReadFile(numaNodeSysfs + "/online")
+	if err != nil {
+		return nil
+	}
+
+	nodes, err := parseCPUList(string(online))
+	if err != nil {
+		return nil
+	}
+
+	var nodeCPUs [][]int
+	for _, node := range nodes {
+		list, err := os.ReadFile(fmt.Sprintf("%s/node%d/cpulist", numaNodeSysfs, node))
+		if err != nil {
+			continue
+		}
+		if cpus, err := parseCPUList(string(list)); err == nil && len(cpus) > 0 {
+			nodeCPUs = append(nodeCPUs, cpus)
+		}
+	}
+	return nodeCPUs
+}
+
+// pinThread restricts the calling OS thread to cpus. The caller must hold
+// runtime.LockOSThread.
+func pinThread(cpus []int) error {
+	maxCPU := 0
+	for _, cpu := range cpus {
+		maxCPU = max(maxCPU, cpu)
+	}
+
+	mask := make([]uint64, maxCPU/64+1)
+	for _, cpu := range cpus {
+		mask[cpu/64] |= 1 << (cpu % 64)
+	}
+
+	// pid 0 applies the mask to the calling thread
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0,
+		uintptr(len(mask)*8), uintptr(unsafe.Pointer(&mask[0])))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
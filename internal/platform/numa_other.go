@@ -0,0 +1,14 @@
+//go:build !linux
+
+package platform
+
+const numaSupported = false
+
+// numaNodeCPUs is unavailable on this platform; pool workers run unpinned
+func numaNodeCPUs() [][]int {
+	return nil
+}
+
+func pinThread(cpus []int) error {
+	return nil
+}
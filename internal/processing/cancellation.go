@@ -0,0 +1,27 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrCancelled is returned by a processing loop stopped through the
+// cancellation channel attached to its context. It matches context.Canceled,
+// so callers treat it like a cancelled context.
+var ErrCancelled = fmt.Errorf("processing cancelled: %w", context.Canceled)
+
+type cancellationKey struct{}
+
+// WithCancellation attaches done, such as the Done channel of a run's
+// cancellation token, to the context. Iterative loops select on it next to
+// ctx.Done() and stop with ErrCancelled once it is closed.
+func WithCancellation(ctx context.Context, done <-chan struct{}) context.Context {
+	return context.WithValue(ctx, cancellationKey{}, done)
+}
+
+// CancellationDone returns the channel attached with WithCancellation, or
+// nil, which never becomes ready in a select, when none is attached
+func CancellationDone(ctx context.Context) <-chan struct{} {
+	done, _ := ctx.Value(cancellationKey{}).(<-chan struct{})
+	return done
+}
@@ -0,0 +1,29 @@
+package processing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCancellationDone(t *testing.T) {
+	if done := CancellationDone(context.Background()); done != nil {
+		t.Error("CancellationDone returned a channel without one attached")
+	}
+
+	cancel := make(chan struct{})
+	ctx := WithCancellation(context.Background(), cancel)
+	close(cancel)
+
+	select {
+	case <-CancellationDone(ctx):
+	default:
+		t.Error("attached channel was not returned")
+	}
+}
+
+func TestErrCancelledMatchesContextCanceled(t *testing.T) {
+	if !errors.Is(ErrCancelled, context.Canceled) {
+		t.Error("ErrCancelled does not match context.Canceled")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"image"
 	"math"
 
+	"otsu-obliterator/internal/models"
 	"otsu-obliterator/internal/opencv/safe"
 
 	"gocv.io/x/gocv"
@@ -150,9 +151,10 @@ func (g *GuidedFilter) Apply(ctx context.Context, input *safe.Mat, params map[st
 }
 
 func (g *GuidedFilter) applyGuidedFilter(src *safe.Mat, radius int, epsilon float64) (*safe.Mat, error) {
-	result, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create result Mat: %w", err)
+	// Every pixel is written below, so a recycled Mat is safe to use
+	result := safe.SharedMatPool.Get(src.Rows(), src.Cols(), src.Type())
+	if result == nil {
+		return nil, fmt.Errorf("failed to create result Mat")
 	}
 
 	rows := src.Rows()
@@ -244,6 +246,140 @@ func (g *GaussianFilter) applyGaussianBlur(src *safe.Mat, sigma float64) (*safe.
 	return dst, nil
 }
 
+// GlobalEqualizationPreprocessor stretches the histogram of the whole image
+// with a single mapping. Unlike CLAHE it has no tiles or clip limit, so it
+// suits images with uniform low contrast.
+type GlobalEqualizationPreprocessor struct{}
+
+func NewGlobalEqualizationPreprocessor() *GlobalEqualizationPreprocessor {
+	return &GlobalEqualizationPreprocessor{}
+}
+
+func (g *GlobalEqualizationPreprocessor) Name() string {
+	return "global_equalization"
+}
+
+// ShouldExecute is false while CLAHE is enabled, since CLAHE already
+// equalises the image
+func (g *GlobalEqualizationPreprocessor) ShouldExecute(params map[string]interface{}) bool {
+	equalize, ok := params["apply_global_equalization"].(bool)
+	if !ok || !equalize {
+		return false
+	}
+	useCLAHE, _ := params["use_clahe"].(bool)
+	return !useCLAHE
+}
+
+// ValidateEqualizationParameters rejects enabling global equalisation and
+// CLAHE together
+func ValidateEqualizationParameters(params map[string]interface{}) error {
+	equalize, _ := params["apply_global_equalization"].(bool)
+	useCLAHE, _ := params["use_clahe"].(bool)
+	if equalize && useCLAHE {
+		return models.NewValidationError("apply_global_equalization/use_clahe", "true/true",
+			"cannot use both global equalization and CLAHE simultaneously")
+	}
+	return nil
+}
+
+func (g *GlobalEqualizationPreprocessor) Apply(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	if input.Channels() != 1 {
+		return nil, fmt.Errorf("global equalization requires a single channel image, got %d channels", input.Channels())
+	}
+
+	dst, err := safe.NewMat(input.Rows(), input.Cols(), input.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	dstMat := dst.GetMat()
+	gocv.EqualizeHist(input.GetMat(), &dstMat)
+
+	return dst, nil
+}
+
+// UnsharpMaskPreprocessor restores fine detail lost to smoothing by adding
+// back the difference between the image and a Gaussian blur of it:
+// sharpened = original + strength * (original - blur)
+type UnsharpMaskPreprocessor struct{}
+
+func NewUnsharpMaskPreprocessor() *UnsharpMaskPreprocessor {
+	return &UnsharpMaskPreprocessor{}
+}
+
+func (u *UnsharpMaskPreprocessor) Name() string {
+	return "unsharp_mask"
+}
+
+func (u *UnsharpMaskPreprocessor) ShouldExecute(params map[string]interface{}) bool {
+	applyUnsharp, ok := params["apply_unsharp"].(bool)
+	return ok && applyUnsharp
+}
+
+// ValidateUnsharpParameters checks the unsharp mask strength and radius
+func ValidateUnsharpParameters(params map[string]interface{}) error {
+	if strength, ok := params["unsharp_strength"].(float64); ok && (strength < 0.0 || strength > 2.0) {
+		return fmt.Errorf("unsharp_strength must be between 0.0 and 2.0, got: %f", strength)
+	}
+
+	if radius, ok := params["unsharp_radius"].(int); ok && (radius < 1 || radius > 5) {
+		return fmt.Errorf("unsharp_radius must be between 1 and 5, got: %d", radius)
+	}
+
+	return nil
+}
+
+func (u *UnsharpMaskPreprocessor) Apply(ctx context.Context, input *safe.Mat, params map[string]interface{}) (*safe.Mat, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	strength := 0.5
+	if val, ok := params["unsharp_strength"].(float64); ok {
+		strength = val
+	}
+
+	radius := 2
+	if val, ok := params["unsharp_radius"].(int); ok {
+		radius = val
+	}
+
+	if strength <= 0.0 || radius < 1 {
+		return input.Clone()
+	}
+
+	return u.applyUnsharpMask(input, strength, radius)
+}
+
+func (u *UnsharpMaskPreprocessor) applyUnsharpMask(src *safe.Mat, strength float64, radius int) (*safe.Mat, error) {
+	dst, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create destination Mat: %w", err)
+	}
+
+	srcMat := src.GetMat()
+	dstMat := dst.GetMat()
+
+	blurred := gocv.NewMat()
+	defer blurred.Close()
+
+	kernelSize := 2*radius + 1
+	gocv.GaussianBlur(srcMat, &blurred, image.Point{X: kernelSize, Y: kernelSize}, 0, 0, gocv.BorderDefault)
+
+	// (1 + strength) * original - strength * blur, saturated to the Mat depth
+	gocv.AddWeighted(srcMat, 1.0+strength, blurred, -strength, 0, &dstMat)
+
+	return dst, nil
+}
+
 // MAOTSUFilter applies MAOTSU noise reduction
 type MAOTSUFilter struct{}
 
@@ -460,19 +596,101 @@ func NewNeighborhoodCalculator(windowSize int) *NeighborhoodCalculator {
 	}
 }
 
-func (n *NeighborhoodCalculator) Calculate(src *safe.Mat) (*safe.Mat, error) {
+func (n *NeighborhoodCalculator) Calculate(ctx context.Context, src *safe.Mat) (*safe.Mat, error) {
 	halfWindow := n.windowSize / 2
+	return n.calculate(ctx, src, func(y, x int) int {
+		return halfWindow
+	})
+}
 
-	dst, err := safe.NewMat(src.Rows(), src.Cols(), gocv.MatTypeCV8UC1)
-	if err != nil {
-		return nil, err
+// CalculateAdaptive averages each pixel over its own window size taken from
+// windowMap, which must have one odd entry per pixel
+func (n *NeighborhoodCalculator) CalculateAdaptive(ctx context.Context, src *safe.Mat, windowMap [][]int) (*safe.Mat, error) {
+	if len(windowMap) != src.Rows() || (len(windowMap) > 0 && len(windowMap[0]) != src.Cols()) {
+		return nil, fmt.Errorf("window map size does not match image: %dx%d", src.Cols(), src.Rows())
 	}
 
+	return n.calculate(ctx, src, func(y, x int) int {
+		return windowMap[y][x] / 2
+	})
+}
+
+// CalculateIntegral gives the same means as Calculate from a summed-area
+// table, so its cost does not grow with the window size
+func (n *NeighborhoodCalculator) CalculateIntegral(ctx context.Context, src *safe.Mat) (*safe.Mat, error) {
 	rows := src.Rows()
 	cols := src.Cols()
+	stride := cols + 1
+	rowsPerCheck := max(1, rows/100)
 
+	// integral[(y+1)*stride+x+1] holds the sum of src over [0,y]x[0,x]
+	integral := make([]float64, (rows+1)*stride)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		rowSum := 0.0
 		for x := 0; x < cols; x++ {
+			val, _ := safe.GetAt[float64](src, y, x)
+			rowSum += val
+			integral[(y+1)*stride+x+1] = integral[y*stride+x+1] + rowSum
+		}
+	}
+
+	dst := safe.SharedMatPool.Get(rows, cols, src.Type())
+	if dst == nil {
+		return nil, fmt.Errorf("failed to create neighborhood Mat")
+	}
+
+	halfWindow := n.windowSize / 2
+	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				safe.SharedMatPool.Put(dst)
+				return nil, err
+			}
+		}
+		y1 := max(0, y-halfWindow)
+		y2 := min(rows-1, y+halfWindow) + 1
+		for x := 0; x < cols; x++ {
+			x1 := max(0, x-halfWindow)
+			x2 := min(cols-1, x+halfWindow) + 1
+
+			sum := integral[y2*stride+x2] - integral[y1*stride+x2] - integral[y2*stride+x1] + integral[y1*stride+x1]
+			count := float64((y2 - y1) * (x2 - x1))
+
+			if err := safe.SetAt(dst, y, x, math.Trunc(sum/count)); err != nil {
+				safe.SharedMatPool.Put(dst)
+				return nil, fmt.Errorf("failed to store neighborhood mean: %w", err)
+			}
+		}
+	}
+
+	return dst, nil
+}
+
+// calculate computes box means with the half window returned for each pixel.
+// A row costs a whole window per pixel, so cancellation is checked every row.
+func (n *NeighborhoodCalculator) calculate(ctx context.Context, src *safe.Mat, halfWindowAt func(y, x int) int) (*safe.Mat, error) {
+	// Every pixel is written below, so a recycled Mat is safe to use. The
+	// means keep the source depth so 16-bit images are not cut to 8 bits.
+	dst := safe.SharedMatPool.Get(src.Rows(), src.Cols(), src.Type())
+	if dst == nil {
+		return nil, fmt.Errorf("failed to create neighborhood Mat")
+	}
+
+	rows := src.Rows()
+	cols := src.Cols()
+
+	for y := 0; y < rows; y++ {
+		if err := ctx.Err(); err != nil {
+			safe.SharedMatPool.Put(dst)
+			return nil, err
+		}
+		for x := 0; x < cols; x++ {
+			halfWindow := halfWindowAt(y, x)
 			y1 := max(0, y-halfWindow)
 			x1 := max(0, x-halfWindow)
 			y2 := min(rows-1, y+halfWindow)
@@ -482,31 +700,18 @@ func (n *NeighborhoodCalculator) Calculate(src *safe.Mat) (*safe.Mat, error) {
 			count := 0.0
 			for yy := y1; yy <= y2; yy++ {
 				for xx := x1; xx <= x2; xx++ {
-					val, _ := src.GetUCharAt(yy, xx)
-					sum += float64(val)
+					val, _ := safe.GetAt[float64](src, yy, xx)
+					sum += val
 					count++
 				}
 			}
 
-			mean := uint8(sum / count)
-			dst.SetUCharAt(y, x, mean)
+			if err := safe.SetAt(dst, y, x, math.Trunc(sum/count)); err != nil {
+				safe.SharedMatPool.Put(dst)
+				return nil, fmt.Errorf("failed to store neighborhood mean: %w", err)
+			}
 		}
 	}
 
 	return dst, nil
 }
-
-// Utility functions
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
\ No newline at end of file
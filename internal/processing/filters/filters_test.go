@@ -0,0 +1,174 @@
+package filters
+
+import (
+	"context"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+	"time"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// cancelLatency is how soon a cancelled pixel loop must return
+const cancelLatency = 50 * time.Millisecond
+
+func TestNeighborhoodCalculatorStopsOnCancel(t *testing.T) {
+	src, err := safe.NewMat(4000, 4000, gocv.MatTypeCV8U)
+	if err != nil {
+		t.Fatalf("NewMat: %v", err)
+	}
+	defer src.Close()
+
+	calculator := NewNeighborhoodCalculator(7)
+	tests := map[string]func(context.Context) (*safe.Mat, error){
+		"window": func(ctx context.Context) (*safe.Mat, error) {
+			return calculator.Calculate(ctx, src)
+		},
+		"integral": func(ctx context.Context) (*safe.Mat, error) {
+			return calculator.CalculateIntegral(ctx, src)
+		},
+	}
+
+	for name, calculate := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				dst, err := calculate(ctx)
+				if dst != nil {
+					safe.SharedMatPool.Put(dst)
+				}
+				done <- err
+			}()
+
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+			cancelled := time.Now()
+
+			select {
+			case err := <-done:
+				if elapsed := time.Since(cancelled); elapsed > cancelLatency {
+					t.Errorf("returned %v after cancellation, want within %v", elapsed, cancelLatency)
+				}
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("err = %v, want context.Canceled", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("calculation ignored cancellation")
+			}
+		})
+	}
+}
+
+func TestValidateEqualizationParametersRejectsCLAHE(t *testing.T) {
+	tests := []struct {
+		name     string
+		equalize bool
+		clahe    bool
+		wantErr  bool
+	}{
+		{name: "neither"},
+		{name: "global equalization", equalize: true},
+		{name: "CLAHE", clahe: true},
+		{name: "both", equalize: true, clahe: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateEqualizationParameters(map[string]interface{}{
+				"apply_global_equalization": tt.equalize,
+				"use_clahe":                 tt.clahe,
+			})
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("ValidateEqualizationParameters: %v", err)
+				}
+				return
+			}
+
+			var validationErr *models.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("err = %v, want a *models.ValidationError", err)
+			}
+			for _, field := range []string{"apply_global_equalization", "use_clahe"} {
+				if !strings.Contains(validationErr.Parameter, field) {
+					t.Errorf("Parameter = %q, want it to name %s", validationErr.Parameter, field)
+				}
+			}
+		})
+	}
+}
+
+// edgeCoherence measures how steep a vertical step edge between columns
+// edge-1 and edge is: the mean central difference over the two columns either
+// side of it, as a fraction of the step height
+func edgeCoherence(t *testing.T, src *safe.Mat, edge int, step float64) float64 {
+	t.Helper()
+
+	total, count := 0.0, 0
+	for y := 0; y < src.Rows(); y++ {
+		for x := edge - 2; x <= edge+1; x++ {
+			left, err := src.GetUCharAt(y, x-1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			right, err := src.GetUCharAt(y, x+1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			total += (float64(right) - float64(left)) / 2
+			count++
+		}
+	}
+	return total / float64(count) / step
+}
+
+func TestUnsharpMaskRaisesEdgeCoherence(t *testing.T) {
+	const (
+		side       = 128
+		dark       = 60
+		bright     = 190
+		minQuality = 1.02
+	)
+
+	// A step edge softened the way guided or Gaussian smoothing leaves it
+	step := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(dark, 0, 0, 0), side, side, gocv.MatTypeCV8UC1)
+	defer step.Close()
+	right := step.Region(image.Rect(side/2, 0, side, side))
+	right.SetTo(gocv.NewScalar(bright, 0, 0, 0))
+	right.Close()
+
+	blurredMat := gocv.NewMat()
+	defer blurredMat.Close()
+	if err := gocv.GaussianBlur(step, &blurredMat, image.Pt(9, 9), 2, 2, gocv.BorderReflect); err != nil {
+		t.Fatal(err)
+	}
+	blurred, err := safe.NewMatFromMat(blurredMat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blurred.Close()
+
+	sharpened, err := NewUnsharpMaskPreprocessor().Apply(context.Background(), blurred, map[string]interface{}{
+		"apply_unsharp":    true,
+		"unsharp_strength": 0.5,
+		"unsharp_radius":   2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sharpened.Close()
+
+	before := edgeCoherence(t, blurred, side/2, bright-dark)
+	after := edgeCoherence(t, sharpened, side/2, bright-dark)
+	if after < before*minQuality {
+		t.Errorf("edge coherence %.4f after sharpening, want at least 2%% above %.4f", after, before)
+	}
+}
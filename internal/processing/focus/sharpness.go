@@ -0,0 +1,74 @@
+package focus
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// SharpnessAnalyser scores how well focused the slices of a Z-stack are by
+// the variance of the Laplacian. Out of focus slices are blurred, which
+// removes the high frequencies the Laplacian responds to, so the slice in the
+// focal plane has the largest variance.
+type SharpnessAnalyser struct{}
+
+func NewSharpnessAnalyser() *SharpnessAnalyser {
+	return &SharpnessAnalyser{}
+}
+
+// Score returns the variance of the Laplacian of src, which may be colour or
+// grayscale. Scores are only comparable between images of the same scene.
+func (sa *SharpnessAnalyser) Score(src *safe.Mat) (float64, error) {
+	if err := safe.ValidateMatForOperation(src, "sharpness analysis"); err != nil {
+		return 0, err
+	}
+
+	gray, err := conversion.ConvertToGrayscale(src)
+	if err != nil {
+		return 0, fmt.Errorf("grayscale conversion failed: %w", err)
+	}
+	defer gray.Close()
+
+	laplacian := gocv.NewMat()
+	defer laplacian.Close()
+	if err := gocv.Laplacian(gray.GetMat(), &laplacian, gocv.MatTypeCV64F, 1, 1, 0, gocv.BorderReflect101); err != nil {
+		return 0, fmt.Errorf("laplacian failed: %w", err)
+	}
+
+	mean := gocv.NewMat()
+	defer mean.Close()
+	stdDev := gocv.NewMat()
+	defer stdDev.Close()
+	if err := gocv.MeanStdDev(laplacian, &mean, &stdDev); err != nil {
+		return 0, fmt.Errorf("mean and deviation failed: %w", err)
+	}
+
+	deviation := stdDev.GetDoubleAt(0, 0)
+	return deviation * deviation, nil
+}
+
+// FindFocusedSlice scores every slice of stack and returns the index of the
+// sharpest one with all scores. Ties go to the earliest slice.
+func (sa *SharpnessAnalyser) FindFocusedSlice(stack []*safe.Mat) (int, []float64, error) {
+	if len(stack) == 0 {
+		return 0, nil, fmt.Errorf("stack has no slices")
+	}
+
+	scores := make([]float64, len(stack))
+	best := 0
+	for i, slice := range stack {
+		score, err := sa.Score(slice)
+		if err != nil {
+			return 0, nil, fmt.Errorf("slice %d: %w", i+1, err)
+		}
+		scores[i] = score
+		if score > scores[best] {
+			best = i
+		}
+	}
+
+	return best, scores, nil
+}
@@ -1,20 +1,40 @@
 package histogram
 
 import (
+	"context"
+	"fmt"
 	"math"
 
+	"otsu-obliterator/internal/opencv/analysis"
 	"otsu-obliterator/internal/opencv/safe"
 )
 
+// WeightMapParam is the parameter holding an optional single channel
+// *safe.Mat of per-pixel weights the size of the image. Each pixel then adds
+// its weight, normalised to 0-1 by the maximum of the Mat depth, to the
+// histogram instead of 1.
+const WeightMapParam = "weight_map"
+
 type TwoDimensionalBuilder struct{}
 
 func NewTwoDimensionalBuilder() *TwoDimensionalBuilder {
 	return &TwoDimensionalBuilder{}
 }
 
-func (t *TwoDimensionalBuilder) Build(src, neighborhood *safe.Mat, params map[string]interface{}) ([][]float64, error) {
+func (t *TwoDimensionalBuilder) Build(ctx context.Context, src, neighborhood *safe.Mat, params map[string]interface{}) ([][]float64, error) {
+	weights, _ := params[WeightMapParam].(*safe.Mat)
+	if weights != nil {
+		if weights.Rows() != src.Rows() || weights.Cols() != src.Cols() {
+			return nil, fmt.Errorf("weight map is %dx%d but the image is %dx%d",
+				weights.Cols(), weights.Rows(), src.Cols(), src.Rows())
+		}
+		if weights.Channels() != 1 {
+			return nil, fmt.Errorf("weight map must be grayscale, got %d channels", weights.Channels())
+		}
+	}
+
 	histBins := t.getHistogramBins(src, params)
-	return t.build2DHistogramStable(src, neighborhood, histBins), nil
+	return t.build2DHistogramStable(ctx, src, neighborhood, weights, histBins)
 }
 
 func (t *TwoDimensionalBuilder) getHistogramBins(src *safe.Mat, params map[string]interface{}) int {
@@ -24,56 +44,20 @@ func (t *TwoDimensionalBuilder) getHistogramBins(src *safe.Mat, params map[strin
 	return t.calculateAdaptiveBinCount(src)
 }
 
+// calculateAdaptiveBinCount sizes the histogram from the entropy of the grey
+// levels. Dynamic range alone overestimates the bins a sparse histogram needs,
+// for example a mostly black scan with a few bright specks, leaving most 2D
+// bins empty and the Otsu optimum sensitive to single pixels. Entropy measures
+// how many levels are actually in use, so low entropy images (below about 4
+// bits) get coarse bins with stable counts while richly toned images keep fine
+// bins.
 func (t *TwoDimensionalBuilder) calculateAdaptiveBinCount(src *safe.Mat) int {
-	rows := src.Rows()
-	cols := src.Cols()
-	totalPixels := rows * cols
-
-	// Calculate dynamic range
-	var minVal, maxVal uint8 = 255, 0
-	for y := 0; y < rows; y++ {
-		for x := 0; x < cols; x++ {
-			val, _ := src.GetUCharAt(y, x)
-			if val < minVal {
-				minVal = val
-			}
-			if val > maxVal {
-				maxVal = val
-			}
-		}
-	}
-
-	dynamicRange := int(maxVal - minVal)
-
-	// Estimate noise level using local variance
-	noiseLevel := t.estimateNoiseLevel(src)
-
-	// Adaptive bin calculation
-	baseBins := 32
-	if dynamicRange < 30 {
-		baseBins = 16 // Low dynamic range
-	} else if dynamicRange > 150 {
-		baseBins = 64 // High dynamic range
-	}
-
-	// Adjust for noise level
-	if noiseLevel > 15.0 {
-		baseBins = max(baseBins/2, 8) // High noise - reduce bins
-	} else if noiseLevel < 5.0 {
-		baseBins = min(baseBins*2, 128) // Low noise - increase bins
-	}
-
-	// Adjust for image size
-	if totalPixels > 1000000 {
-		baseBins = min(baseBins*2, 256) // Large images
-	} else if totalPixels < 100000 {
-		baseBins = max(baseBins/2, 8) // Small images
-	}
-
-	return baseBins
+	return analysis.EntropyBinCount(analysis.ComputeHistogramEntropy(src))
 }
 
-func (t *TwoDimensionalBuilder) build2DHistogramStable(src, neighborhood *safe.Mat, histBins int) [][]float64 {
+// build2DHistogramStable accumulates the joint histogram of pixel and
+// neighbourhood values, weighting each pixel by weights when it is not nil
+func (t *TwoDimensionalBuilder) build2DHistogramStable(ctx context.Context, src, neighborhood, weights *safe.Mat, histBins int) ([][]float64, error) {
 	histogram := make([][]float64, histBins)
 	for i := range histogram {
 		histogram[i] = make([]float64, histBins)
@@ -81,60 +65,52 @@ func (t *TwoDimensionalBuilder) build2DHistogramStable(src, neighborhood *safe.M
 
 	rows := src.Rows()
 	cols := src.Cols()
-	binScale := float64(histBins-1) / 255.0
+	binScale := float64(histBins-1) / safe.MaxElementValue(src.Type())
+
+	var weightScale float64
+	if weights != nil {
+		weightScale = 1 / safe.MaxElementValue(weights.Type())
+	}
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		for x := 0; x < cols; x++ {
-			pixelValue, err := src.GetUCharAt(y, x)
+			pixelValue, err := safe.GetAt[float64](src, y, x)
 			if err != nil {
 				continue
 			}
 
-			neighValue, err := neighborhood.GetUCharAt(y, x)
+			neighValue, err := safe.GetAt[float64](neighborhood, y, x)
 			if err != nil {
 				continue
 			}
 
-			pixelBin := int(float64(pixelValue) * binScale)
-			neighBin := int(float64(neighValue) * binScale)
+			pixelBin := int(pixelValue * binScale)
+			neighBin := int(neighValue * binScale)
 
 			// Ensure bins are within valid range
 			pixelBin = max(0, min(pixelBin, histBins-1))
 			neighBin = max(0, min(neighBin, histBins-1))
 
-			histogram[pixelBin][neighBin] += 1.0
-		}
-	}
-
-	return histogram
-}
-
-func (t *TwoDimensionalBuilder) estimateNoiseLevel(src *safe.Mat) float64 {
-	rows := src.Rows()
-	cols := src.Cols()
+			weight := 1.0
+			if weights != nil {
+				value, err := safe.GetAt[float64](weights, y, x)
+				if err != nil {
+					continue
+				}
+				weight = value * weightScale
+			}
 
-	// Use Laplacian operator to estimate noise
-	var sumSq float64
-	count := 0
-
-	for y := 1; y < rows-1; y++ {
-		for x := 1; x < cols-1; x++ {
-			center, _ := src.GetUCharAt(y, x)
-			top, _ := src.GetUCharAt(y-1, x)
-			bottom, _ := src.GetUCharAt(y+1, x)
-			left, _ := src.GetUCharAt(y, x-1)
-			right, _ := src.GetUCharAt(y, x+1)
-
-			laplacian := float64(center)*4 - float64(top) - float64(bottom) - float64(left) - float64(right)
-			sumSq += laplacian * laplacian
-			count++
+			histogram[pixelBin][neighBin] += weight
 		}
 	}
 
-	if count > 0 {
-		return math.Sqrt(sumSq/float64(count)) / 6.0 // Normalize
-	}
-	return 10.0 // Default noise level
+	return histogram, nil
 }
 
 func (t *TwoDimensionalBuilder) SmoothHistogram(histogram [][]float64, sigma float64) {
@@ -199,17 +175,3 @@ func (t *TwoDimensionalBuilder) SmoothHistogram(histogram [][]float64, sigma flo
 		}
 	}
 }
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,54 @@
+package histogram
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+func TestBuildStopsOnCancel(t *testing.T) {
+	const side = 4000
+
+	src, err := safe.NewMat(side, side, gocv.MatTypeCV8U)
+	if err != nil {
+		t.Fatalf("NewMat: %v", err)
+	}
+	defer src.Close()
+	neighborhood, err := safe.NewMat(side, side, gocv.MatTypeCV8U)
+	if err != nil {
+		t.Fatalf("NewMat: %v", err)
+	}
+	defer neighborhood.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewTwoDimensionalBuilder().Build(ctx, src, neighborhood, map[string]interface{}{
+			"histogram_bins": 64,
+		})
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	cancelled := time.Now()
+
+	select {
+	case err := <-done:
+		if elapsed := time.Since(cancelled); elapsed > 50*time.Millisecond {
+			t.Errorf("returned %v after cancellation, want within 50ms", elapsed)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("histogram build ignored cancellation")
+	}
+}
@@ -0,0 +1,40 @@
+package labelling
+
+// FilterByArea clears the 8-connected foreground components of a width x
+// height binary image, given row by row as in ParallelCCL, that cover fewer
+// than minArea pixels or, when maxArea is positive, more than maxArea. It
+// returns the number of components removed.
+func FilterByArea(pixels []byte, width, height, minArea, maxArea int) int {
+	if minArea <= 1 && maxArea <= 0 {
+		return 0
+	}
+
+	labels, count := ParallelCCL(pixels, width, height)
+	areas := make([]int, count)
+	for _, row := range labels {
+		for _, label := range row {
+			areas[label]++
+		}
+	}
+
+	removed := 0
+	discard := make([]bool, count)
+	for label := 1; label < count; label++ {
+		if areas[label] < minArea || (maxArea > 0 && areas[label] > maxArea) {
+			discard[label] = true
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0
+	}
+
+	for y, row := range labels {
+		for x, label := range row {
+			if discard[label] {
+				pixels[y*width+x] = 0
+			}
+		}
+	}
+	return removed
+}
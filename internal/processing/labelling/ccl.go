@@ -0,0 +1,229 @@
+// Package labelling finds the connected components of binary images.
+package labelling
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// minStripRows keeps strips tall enough that the work saved by labelling
+// them in parallel outweighs merging their borders
+const minStripRows = 32
+
+// background marks pixels outside every component in the union-find forest
+const background = -1
+
+// ParallelCCL labels the 8-connected foreground of a width x height binary
+// image, where nonzero bytes of pixels, row by row, are foreground. It returns
+// one row of labels per image row and the number of labels including the
+// background, like gocv.ConnectedComponents: background is 0 and components
+// are numbered from 1 in the raster order of their first pixel.
+//
+// The image is cut into horizontal strips labelled concurrently by the
+// two-pass union-find algorithm, each strip ignoring the rows above it. A
+// merge pass then joins components across strip borders. Both passes share a
+// union-find forest over pixel indices whose links only ever move to a
+// smaller index, updated with compare-and-swap, so each component's root is
+// its first pixel.
+func ParallelCCL(pixels []byte, width, height int) ([][]int32, int) {
+	labels := make([]int32, width*height)
+	rows := make([][]int32, height)
+	for y := range rows {
+		rows[y] = labels[y*width : (y+1)*width]
+	}
+	if width == 0 || height == 0 {
+		return rows, 1
+	}
+
+	strips := stripBounds(height, runtime.GOMAXPROCS(0))
+	forest := make([]int32, width*height)
+
+	// First pass: label each strip on its own. Strips touch disjoint parts of
+	// the forest, so no atomics are needed until the merge.
+	parallel(len(strips), func(s int) {
+		first := strips[s][0]
+		for y := first; y < strips[s][1]; y++ {
+			for x := 0; x < width; x++ {
+				i := y*width + x
+				if pixels[i] == 0 {
+					forest[i] = background
+					continue
+				}
+				forest[i] = int32(i)
+
+				// A foreground pixel above is already joined to its left and
+				// right neighbours, and to the pixel to the left of i
+				if y > first && pixels[i-width] != 0 {
+					localUnion(forest, int32(i), int32(i-width))
+					continue
+				}
+				if x > 0 && pixels[i-1] != 0 {
+					localUnion(forest, int32(i), int32(i-1))
+				}
+				if y > first {
+					if x > 0 && pixels[i-width-1] != 0 {
+						localUnion(forest, int32(i), int32(i-width-1))
+					}
+					if x+1 < width && pixels[i-width+1] != 0 {
+						localUnion(forest, int32(i), int32(i-width+1))
+					}
+				}
+			}
+		}
+	})
+
+	// Merge pass: join the first row of every strip to the row above
+	parallel(len(strips)-1, func(s int) {
+		y := strips[s+1][0]
+		for x := 0; x < width; x++ {
+			if pixels[y*width+x] != 0 {
+				joinAbove(forest, pixels, width, x, y)
+			}
+		}
+	})
+
+	// The forest is final, so roots can be looked up without atomics. Each
+	// pixel's label temporarily holds its root index plus one.
+	roots := make([]int32, len(strips))
+	parallel(len(strips), func(s int) {
+		for i := strips[s][0] * width; i < strips[s][1]*width; i++ {
+			if pixels[i] == 0 {
+				continue
+			}
+			root := i
+			for int(forest[root]) != root {
+				root = int(forest[root])
+			}
+			labels[i] = int32(root) + 1
+			if root == i {
+				roots[s]++
+			}
+		}
+	})
+
+	// Roots are first pixels, so numbering them strip by strip in raster
+	// order numbers the components in raster order. A root's number is kept
+	// in its own forest slot.
+	offsets := make([]int32, len(strips))
+	count := int32(0)
+	for s, n := range roots {
+		offsets[s] = count
+		count += n
+	}
+	parallel(len(strips), func(s int) {
+		next := offsets[s]
+		for i := strips[s][0] * width; i < strips[s][1]*width; i++ {
+			if labels[i] == int32(i)+1 {
+				next++
+				forest[i] = next
+			}
+		}
+	})
+	parallel(len(strips), func(s int) {
+		for i := strips[s][0] * width; i < strips[s][1]*width; i++ {
+			if root := labels[i]; root != 0 {
+				labels[i] = forest[root-1]
+			}
+		}
+	})
+
+	return rows, int(count) + 1
+}
+
+// joinAbove unions foreground pixel (x, y) with its foreground neighbours in
+// the row above
+func joinAbove(forest []int32, pixels []byte, width, x, y int) {
+	i := y*width + x
+	above := i - width
+	for dx := -1; dx <= 1; dx++ {
+		if x+dx < 0 || x+dx >= width {
+			continue
+		}
+		if pixels[above+dx] != 0 {
+			union(forest, int32(i), int32(above+dx))
+		}
+	}
+}
+
+// localFind returns the root of i in a part of the forest no other goroutine
+// is using, compressing the path
+func localFind(forest []int32, i int32) int32 {
+	root := i
+	for forest[root] != root {
+		root = forest[root]
+	}
+	for forest[i] != root {
+		forest[i], i = root, forest[i]
+	}
+	return root
+}
+
+// localUnion joins the trees of a and b like union, without atomics
+func localUnion(forest []int32, a, b int32) {
+	a = localFind(forest, a)
+	b = localFind(forest, b)
+	if a < b {
+		forest[b] = a
+	} else if b < a {
+		forest[a] = b
+	}
+}
+
+// find returns the root of i, halving the path on the way
+func find(forest []int32, i int32) int32 {
+	for {
+		parent := atomic.LoadInt32(&forest[i])
+		if parent == i {
+			return i
+		}
+		grandparent := atomic.LoadInt32(&forest[parent])
+		if grandparent != parent {
+			atomic.CompareAndSwapInt32(&forest[i], parent, grandparent)
+		}
+		i = parent
+	}
+}
+
+// union joins the trees of a and b, linking the larger root under the
+// smaller. A failed swap means another goroutine moved the root, so the
+// roots are found again.
+func union(forest []int32, a, b int32) {
+	for {
+		a = find(forest, a)
+		b = find(forest, b)
+		if a == b {
+			return
+		}
+		if a < b {
+			a, b = b, a
+		}
+		if atomic.CompareAndSwapInt32(&forest[a], a, b) {
+			return
+		}
+	}
+}
+
+// stripBounds splits height rows into at most workers strips of at least
+// minStripRows rows each, as [first, end) row pairs
+func stripBounds(height, workers int) [][2]int {
+	count := max(1, min(workers, height/minStripRows))
+	strips := make([][2]int, count)
+	for s := range strips {
+		strips[s] = [2]int{s * height / count, (s + 1) * height / count}
+	}
+	return strips
+}
+
+// parallel calls fn for 0 to n-1 on separate goroutines and waits for all
+func parallel(n int, fn func(int)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,60 @@
+package labelling
+
+import (
+	"image"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// cclBenchmarkSide is the side of the mask the labellers are timed on
+const cclBenchmarkSide = 2048
+
+// blobMask returns a side x side mask of irregular blobs, smoothed noise
+// thresholded to 0 and 255, as a Mat and as the bytes ParallelCCL reads
+func blobMask(b *testing.B, side int) (gocv.Mat, []byte) {
+	b.Helper()
+
+	gocv.SetRNGSeed(1)
+	noise := gocv.NewMatWithSize(side, side, gocv.MatTypeCV8UC1)
+	defer noise.Close()
+	gocv.RandU(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(256, 0, 0, 0))
+	if err := gocv.Blur(noise, &noise, image.Pt(5, 5)); err != nil {
+		b.Fatal(err)
+	}
+
+	mask := gocv.NewMat()
+	b.Cleanup(func() { mask.Close() })
+	gocv.Threshold(noise, &mask, 135, 255, gocv.ThresholdBinary)
+	return mask, mask.ToBytes()
+}
+
+// BenchmarkParallelCCLVsConnectedComponents times ParallelCCL against
+// gocv.ConnectedComponents on a 2048x2048 mask, after checking both find the
+// same number of labels
+func BenchmarkParallelCCLVsConnectedComponents(b *testing.B) {
+	mask, pixels := blobMask(b, cclBenchmarkSide)
+	labels := gocv.NewMat()
+	defer labels.Close()
+
+	openCVCount := gocv.ConnectedComponents(mask, &labels)
+	if _, count := ParallelCCL(pixels, cclBenchmarkSide, cclBenchmarkSide); count != openCVCount {
+		b.Fatalf("ParallelCCL found %d labels, gocv.ConnectedComponents %d", count, openCVCount)
+	}
+
+	b.Run("ConnectedComponents", func(b *testing.B) {
+		b.SetBytes(cclBenchmarkSide * cclBenchmarkSide)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			gocv.ConnectedComponents(mask, &labels)
+		}
+	})
+
+	b.Run("ParallelCCL", func(b *testing.B) {
+		b.SetBytes(cclBenchmarkSide * cclBenchmarkSide)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ParallelCCL(pixels, cclBenchmarkSide, cclBenchmarkSide)
+		}
+	})
+}
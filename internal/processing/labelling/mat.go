@@ -0,0 +1,51 @@
+package labelling
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// LabelMat runs ParallelCCL on an 8-bit single channel mask
+func LabelMat(mask *safe.Mat) ([][]int32, int, error) {
+	if err := safe.ValidateMatForOperation(mask, "connected component labelling"); err != nil {
+		return nil, 0, err
+	}
+	if mask.Type() != gocv.MatTypeCV8UC1 {
+		return nil, 0, fmt.Errorf("connected component labelling requires an 8-bit single channel mask")
+	}
+
+	mat := mask.GetMat()
+	pixels := mat.ToBytes()
+	labels, count := ParallelCCL(pixels, mask.Cols(), mask.Rows())
+	return labels, count, nil
+}
+
+// FilterMatByArea returns a copy of an 8-bit single channel mask without the
+// components FilterByArea discards, and how many were removed
+func FilterMatByArea(mask *safe.Mat, minArea, maxArea int) (*safe.Mat, int, error) {
+	if err := safe.ValidateMatForOperation(mask, "object area filtering"); err != nil {
+		return nil, 0, err
+	}
+	if mask.Type() != gocv.MatTypeCV8UC1 {
+		return nil, 0, fmt.Errorf("object area filtering requires an 8-bit single channel mask")
+	}
+
+	mat := mask.GetMat()
+	pixels := mat.ToBytes()
+	removed := FilterByArea(pixels, mask.Cols(), mask.Rows(), minArea, maxArea)
+
+	filtered, err := gocv.NewMatFromBytes(mask.Rows(), mask.Cols(), gocv.MatTypeCV8UC1, pixels)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create filtered mask: %w", err)
+	}
+	defer filtered.Close()
+
+	result, err := safe.NewMatFromMat(filtered)
+	if err != nil {
+		return nil, 0, err
+	}
+	return result, removed, nil
+}
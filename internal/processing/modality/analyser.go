@@ -0,0 +1,124 @@
+package modality
+
+import (
+	"fmt"
+	"math"
+
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing/threshold"
+)
+
+// Algorithms recommended by DetectModality
+const (
+	RecommendUnimodal   = "Iterative Triclass"
+	RecommendBimodal    = "2D Otsu"
+	RecommendMultimodal = "Multi-Level Otsu"
+)
+
+const (
+	// Histogram bins used for peak counting. Coarser than the 256 grey
+	// levels so the smoothing spans about 20 levels and sensor noise does
+	// not split a mode in two.
+	modalityBins = 64
+
+	// Fraction of the tallest smoothed bin a maximum must exceed to count as
+	// a peak, so sparse tails do not add modes
+	minimumPeakFraction = 0.05
+)
+
+// ModalityResult describes the modes of an image's grey level histogram.
+// PeakLevels are the peak positions on the 0-255 scale. Mean, StdDev, Min
+// and Max summarise the grey levels.
+type ModalityResult struct {
+	Peaks       int
+	Recommended string
+	PeakLevels  []int
+	Mean        float64
+	StdDev      float64
+	Min         int
+	Max         int
+}
+
+// HistogramAnalyser counts histogram modes to recommend a thresholding algorithm
+type HistogramAnalyser struct{}
+
+func NewHistogramAnalyser() *HistogramAnalyser {
+	return &HistogramAnalyser{}
+}
+
+// DetectModality counts the peaks of the smoothed grey level histogram of
+// src, which may be colour, grayscale or 16-bit. One peak recommends
+// Iterative Triclass, two 2D Otsu and three or more Multi-Level Otsu.
+func (ha *HistogramAnalyser) DetectModality(src *safe.Mat) (ModalityResult, error) {
+	if err := safe.ValidateMatForOperation(src, "modality detection"); err != nil {
+		return ModalityResult{}, err
+	}
+
+	gray, err := conversion.ConvertToGrayscale(src)
+	if err != nil {
+		return ModalityResult{}, fmt.Errorf("grayscale conversion failed: %w", err)
+	}
+	defer gray.Close()
+
+	if conversion.Is16Bit(gray) {
+		scaled, err := conversion.ScaleTo8Bit(gray)
+		if err != nil {
+			return ModalityResult{}, err
+		}
+		defer scaled.Close()
+		gray = scaled
+	}
+
+	grayMat := gray.GetMat()
+	levels := grayMat.ToBytes()
+
+	histogram := make([]int, modalityBins)
+	result := ModalityResult{Min: math.MaxUint8}
+	sum, sumSq := 0.0, 0.0
+	for _, level := range levels {
+		histogram[int(level)*modalityBins/256]++
+		sum += float64(level)
+		sumSq += float64(level) * float64(level)
+		result.Min = min(result.Min, int(level))
+		result.Max = max(result.Max, int(level))
+	}
+	if len(levels) > 0 {
+		n := float64(len(levels))
+		result.Mean = sum / n
+		result.StdDev = math.Sqrt(max(0, sumSq/n-result.Mean*result.Mean))
+	}
+
+	binWidth := 256 / modalityBins
+	for _, bin := range threshold.FindHistogramPeaks(histogram, minimumPeakFraction) {
+		result.PeakLevels = append(result.PeakLevels, bin*binWidth+binWidth/2)
+	}
+	result.Peaks = len(result.PeakLevels)
+	result.Recommended = recommendAlgorithm(result.Peaks)
+
+	return result, nil
+}
+
+// recommendAlgorithm maps a peak count to an algorithm name
+func recommendAlgorithm(peaks int) string {
+	switch {
+	case peaks >= 3:
+		return RecommendMultimodal
+	case peaks == 2:
+		return RecommendBimodal
+	default:
+		return RecommendUnimodal
+	}
+}
+
+// Recommendation returns the advice shown to the user
+func (r ModalityResult) Recommendation() string {
+	switch r.Peaks {
+	case 0, 1:
+		return fmt.Sprintf("The histogram has a single mode — %s separates objects that differ little from the background.", r.Recommended)
+	case 2:
+		return fmt.Sprintf("The histogram has two modes — %s suits a clear foreground and background.", r.Recommended)
+	default:
+		return fmt.Sprintf("The histogram has %d modes — %s can separate more than two classes.", r.Peaks, r.Recommended)
+	}
+}
@@ -0,0 +1,227 @@
+package noise
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// Noise types reported by NoiseClassifier
+const (
+	TypeNone          = "none"
+	TypeGaussian      = "gaussian"
+	TypeSaltAndPepper = "salt-and-pepper"
+	TypeSpeckle       = "speckle"
+)
+
+const (
+	// Noise standard deviation in gray levels below which an image is treated as clean
+	minimumNoiseLevel = 2.0
+
+	// Deviation from the 3x3 median above which a pixel counts as an impulse
+	impulseDeviation = 50.0
+
+	// Fraction of impulse pixels at the intensity extremes that indicates salt-and-pepper noise
+	impulseRatioThreshold = 0.002
+
+	// Ratio of local deviation in bright versus dark regions that indicates
+	// multiplicative (speckle) rather than additive noise
+	speckleRatioThreshold = 1.8
+
+	maximumSNRdB = 100.0
+)
+
+// NoiseProfile describes the dominant noise in an image. Level is the
+// estimated noise standard deviation in gray levels.
+type NoiseProfile struct {
+	Type  string
+	Level float64
+	SNRdB float64
+}
+
+// NoiseClassifier estimates the type and strength of noise in an image
+type NoiseClassifier struct{}
+
+func NewNoiseClassifier() *NoiseClassifier {
+	return &NoiseClassifier{}
+}
+
+// Classify analyses src, which may be colour or grayscale
+func (nc *NoiseClassifier) Classify(src *safe.Mat) (NoiseProfile, error) {
+	if err := safe.ValidateMatForOperation(src, "noise classification"); err != nil {
+		return NoiseProfile{}, err
+	}
+	if src.Rows() < 5 || src.Cols() < 5 {
+		return NoiseProfile{Type: TypeNone, SNRdB: maximumSNRdB}, nil
+	}
+
+	gray, err := conversion.ConvertToGrayscale(src)
+	if err != nil {
+		return NoiseProfile{}, fmt.Errorf("grayscale conversion failed: %w", err)
+	}
+	defer gray.Close()
+
+	grayMat := gray.GetMat()
+	level := nc.laplacianNoiseLevel(grayMat)
+	profile := NoiseProfile{
+		Type:  TypeNone,
+		Level: level,
+		SNRdB: signalToNoiseDB(grayMat.Mean().Val1, level),
+	}
+
+	if nc.impulseRatio(grayMat) > impulseRatioThreshold {
+		profile.Type = TypeSaltAndPepper
+		return profile, nil
+	}
+
+	if level < minimumNoiseLevel {
+		return profile, nil
+	}
+
+	if ratio, ok := nc.brightToDarkDeviation(grayMat); ok && ratio > speckleRatioThreshold {
+		profile.Type = TypeSpeckle
+	} else {
+		profile.Type = TypeGaussian
+	}
+
+	return profile, nil
+}
+
+// Suggestion returns a preprocessing recommendation for the profile, or an
+// empty string when no change is needed
+func (p NoiseProfile) Suggestion() string {
+	switch p.Type {
+	case TypeSaltAndPepper:
+		return "Detected salt-and-pepper noise — consider enabling noise_robustness preprocessing."
+	case TypeSpeckle:
+		return fmt.Sprintf("Detected speckle noise (SNR %.1f dB) — consider enabling guided_filtering.", p.SNRdB)
+	case TypeGaussian:
+		return fmt.Sprintf("Detected Gaussian noise (σ ≈ %.1f) — consider enabling gaussian_preprocessing.", p.Level)
+	default:
+		return ""
+	}
+}
+
+// laplacianNoiseLevel estimates the noise standard deviation from the variance
+// of the 4-neighbour Laplacian, whose kernel amplifies white noise variance by 20
+func (nc *NoiseClassifier) laplacianNoiseLevel(gray gocv.Mat) float64 {
+	laplacian := gocv.NewMat()
+	defer laplacian.Close()
+	gocv.Laplacian(gray, &laplacian, gocv.MatTypeCV32F, 1, 1, 0, gocv.BorderReflect101)
+
+	mean := gocv.NewMat()
+	defer mean.Close()
+	stdDev := gocv.NewMat()
+	defer stdDev.Close()
+	gocv.MeanStdDev(laplacian, &mean, &stdDev)
+
+	return stdDev.GetDoubleAt(0, 0) / math.Sqrt(20)
+}
+
+// impulseRatio returns the fraction of pixels that are saturated black or
+// white and differ strongly from their local median
+func (nc *NoiseClassifier) impulseRatio(gray gocv.Mat) float64 {
+	median := gocv.NewMat()
+	defer median.Close()
+	gocv.MedianBlur(gray, &median, 3)
+
+	deviation := gocv.NewMat()
+	defer deviation.Close()
+	gocv.AbsDiff(gray, median, &deviation)
+
+	outliers := gocv.NewMat()
+	defer outliers.Close()
+	gocv.Threshold(deviation, &outliers, impulseDeviation, 255, gocv.ThresholdBinary)
+
+	dark := gocv.NewMat()
+	defer dark.Close()
+	gocv.Threshold(gray, &dark, 10, 255, gocv.ThresholdBinaryInv)
+
+	bright := gocv.NewMat()
+	defer bright.Close()
+	gocv.Threshold(gray, &bright, 245, 255, gocv.ThresholdBinary)
+
+	extremes := gocv.NewMat()
+	defer extremes.Close()
+	gocv.BitwiseOr(dark, bright, &extremes)
+
+	impulses := gocv.NewMat()
+	defer impulses.Close()
+	gocv.BitwiseAnd(outliers, extremes, &impulses)
+
+	total := gray.Rows() * gray.Cols()
+	return float64(gocv.CountNonZero(impulses)) / float64(total)
+}
+
+// brightToDarkDeviation compares the median local standard deviation of
+// bright regions with that of dark regions. Additive noise gives a ratio near
+// one, multiplicative noise grows with intensity.
+func (nc *NoiseClassifier) brightToDarkDeviation(gray gocv.Mat) (float64, bool) {
+	values := gocv.NewMat()
+	defer values.Close()
+	gray.ConvertTo(&values, gocv.MatTypeCV32F)
+
+	squares := gocv.NewMat()
+	defer squares.Close()
+	gocv.Multiply(values, values, &squares)
+
+	window := image.Pt(5, 5)
+	localMean := gocv.NewMat()
+	defer localMean.Close()
+	gocv.Blur(values, &localMean, window)
+
+	localSquares := gocv.NewMat()
+	defer localSquares.Close()
+	gocv.Blur(squares, &localSquares, window)
+
+	means, err := localMean.DataPtrFloat32()
+	if err != nil {
+		return 0, false
+	}
+	meanSquares, err := localSquares.DataPtrFloat32()
+	if err != nil {
+		return 0, false
+	}
+
+	var dark, bright []float64
+	for i, mean := range means {
+		variance := float64(meanSquares[i]) - float64(mean)*float64(mean)
+		deviation := math.Sqrt(math.Max(0, variance))
+		switch {
+		case mean > 20 && mean < 85:
+			dark = append(dark, deviation)
+		case mean > 170 && mean < 235:
+			bright = append(bright, deviation)
+		}
+	}
+
+	minimumSamples := len(means) / 50
+	if len(dark) < minimumSamples || len(bright) < minimumSamples || len(dark) == 0 || len(bright) == 0 {
+		return 0, false
+	}
+
+	darkMedian := median(dark)
+	if darkMedian <= 0 {
+		return 0, false
+	}
+	return median(bright) / darkMedian, true
+}
+
+func signalToNoiseDB(signal, noise float64) float64 {
+	if noise <= 0 || signal <= 0 {
+		return maximumSNRdB
+	}
+	return math.Min(maximumSNRdB, 20*math.Log10(signal/noise))
+}
+
+// median sorts values in place and returns the middle element
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	return values[len(values)/2]
+}
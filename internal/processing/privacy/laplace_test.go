@@ -0,0 +1,129 @@
+package privacy
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing"
+
+	"gocv.io/x/gocv"
+)
+
+// applyNoise runs a fresh preprocessor over a mid-grey image and returns
+// the noisy pixels
+func applyNoise(t *testing.T) []byte {
+	t.Helper()
+
+	src, err := safe.NewMat(32, 32, gocv.MatTypeCV8UC1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	srcMat := src.GetMat()
+	srcMat.SetTo(gocv.NewScalar(128, 0, 0, 0))
+
+	preprocessor, err := NewDifferentialPrivacyPreprocessor(DefaultEpsilon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noisy, err := preprocessor.Apply(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noisy.Close()
+
+	noisyMat := noisy.GetMat()
+	return noisyMat.ToBytes()
+}
+
+func TestApplyRepeatsWithFixedSeed(t *testing.T) {
+	processing.RNG.SetSeed(processing.DefaultFixedSeed)
+	defer processing.RNG.SetSeed(0)
+
+	first := applyNoise(t)
+	second := applyNoise(t)
+
+	if string(first) != string(second) {
+		t.Fatal("two runs with the same seed produced different noise")
+	}
+}
+
+func TestNewDifferentialPrivacyPreprocessorRejectsInvalidEpsilon(t *testing.T) {
+	for _, epsilon := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := NewDifferentialPrivacyPreprocessor(epsilon); err == nil {
+			t.Errorf("NewDifferentialPrivacyPreprocessor(%g) succeeded, want an error", epsilon)
+		}
+	}
+}
+
+func TestScaleIsSensitivityOverEpsilon(t *testing.T) {
+	tests := []struct {
+		epsilon     float64
+		sensitivity float64
+		want        float64
+	}{
+		{epsilon: 1, sensitivity: DefaultSensitivity, want: 1},
+		{epsilon: 0.5, sensitivity: DefaultSensitivity, want: 2},
+		{epsilon: 4, sensitivity: DefaultSensitivity, want: 0.25},
+		// A whole 8-bit pixel range as the neighbourhood
+		{epsilon: 2, sensitivity: 255, want: 127.5},
+	}
+
+	for _, tt := range tests {
+		preprocessor, err := NewDifferentialPrivacyPreprocessor(tt.epsilon)
+		if err != nil {
+			t.Fatal(err)
+		}
+		preprocessor.Sensitivity = tt.sensitivity
+
+		if got := preprocessor.Scale(); got != tt.want {
+			t.Errorf("Scale() with epsilon %g and sensitivity %g = %g, want %g",
+				tt.epsilon, tt.sensitivity, got, tt.want)
+		}
+	}
+}
+
+func TestNoiseMeanAbsoluteDeviationMatchesScale(t *testing.T) {
+	processing.RNG.SetSeed(processing.DefaultFixedSeed)
+	defer processing.RNG.SetSeed(0)
+
+	const (
+		side = 256
+		mean = 128
+	)
+
+	src, err := safe.NewMat(side, side, gocv.MatTypeCV8UC1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	srcMat := src.GetMat()
+	srcMat.SetTo(gocv.NewScalar(mean, 0, 0, 0))
+
+	// Scale 4 keeps clamping at 0 and 255 out of reach of all but a
+	// vanishing fraction of pixels
+	preprocessor, err := NewDifferentialPrivacyPreprocessor(0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noisy, err := preprocessor.Apply(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noisy.Close()
+
+	noisyMat := noisy.GetMat()
+	total := 0.0
+	for _, value := range noisyMat.ToBytes() {
+		total += math.Abs(float64(value) - mean)
+	}
+	deviation := total / (side * side)
+
+	// The mean absolute deviation of Laplace noise is its scale; rounding to
+	// whole grey levels moves it by a few hundredths
+	if scale := preprocessor.Scale(); math.Abs(deviation-scale) > 0.05*scale {
+		t.Errorf("mean absolute deviation = %.3f, want %.3f within 5%%", deviation, scale)
+	}
+}
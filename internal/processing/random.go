@@ -0,0 +1,67 @@
+// Package processing holds state shared by the image processing packages.
+package processing
+
+import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+	"sync"
+)
+
+// DefaultFixedSeed is the seed used when a fixed seed is turned on
+const DefaultFixedSeed = 42
+
+// RNG is the random source of every stochastic processing step, such as
+// differential privacy noise and Auto-Tune sampling. Setting a fixed seed
+// makes their results reproducible across runs.
+var RNG = NewSharedRand(0)
+
+// SharedRand hands out ChaCha8 generators for the runs of stochastic steps.
+// It is safe for concurrent use.
+type SharedRand struct {
+	mu   sync.Mutex
+	seed int64
+}
+
+// NewSharedRand creates a source with seed, see SetSeed
+func NewSharedRand(seed int64) *SharedRand {
+	r := &SharedRand{}
+	r.SetSeed(seed)
+	return r
+}
+
+// SetSeed sets the seed of the generators handed out from now on. A seed of
+// 0 gives every generator a fresh seed from the operating system, so no two
+// runs draw the same numbers.
+func (r *SharedRand) SetSeed(seed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seed = seed
+}
+
+// Seed returns the seed last set, 0 when generators are seeded by the
+// operating system
+func (r *SharedRand) Seed() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seed
+}
+
+// ForRun returns a generator for one run of the stochastic step named step.
+// With a fixed seed the generator depends only on the seed and step, so every
+// run with the same seed draws the same numbers however many runs came
+// before, while different steps draw independent sequences.
+func (r *SharedRand) ForRun(step string) *rand.Rand {
+	seed := r.Seed()
+
+	var key [32]byte
+	if seed == 0 {
+		crand.Read(key[:])
+	} else {
+		var seedBytes [8]byte
+		binary.LittleEndian.PutUint64(seedBytes[:], uint64(seed))
+		key = sha256.Sum256(append(seedBytes[:], step...))
+	}
+	return rand.New(rand.NewChaCha8(key))
+}
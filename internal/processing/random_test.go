@@ -0,0 +1,64 @@
+package processing
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// draw returns the first n numbers of rng
+func draw(rng *rand.Rand, n int) []uint64 {
+	values := make([]uint64, n)
+	for i := range values {
+		values[i] = rng.Uint64()
+	}
+	return values
+}
+
+func equalDraws(a, b []uint64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestForRunRepeatsWithFixedSeed(t *testing.T) {
+	r := NewSharedRand(DefaultFixedSeed)
+
+	first := draw(r.ForRun("noise"), 16)
+	// Other steps and earlier runs must not shift the next run's sequence
+	draw(r.ForRun("sampling"), 16)
+	second := draw(r.ForRun("noise"), 16)
+
+	if !equalDraws(first, second) {
+		t.Fatalf("runs with seed %d differ: %v and %v", DefaultFixedSeed, first, second)
+	}
+
+	other := NewSharedRand(DefaultFixedSeed)
+	if third := draw(other.ForRun("noise"), 16); !equalDraws(first, third) {
+		t.Fatalf("sources with seed %d differ: %v and %v", DefaultFixedSeed, first, third)
+	}
+}
+
+func TestForRunSeparatesStepsAndSeeds(t *testing.T) {
+	r := NewSharedRand(DefaultFixedSeed)
+	noise := draw(r.ForRun("noise"), 16)
+
+	if sampling := draw(r.ForRun("sampling"), 16); equalDraws(noise, sampling) {
+		t.Fatal("different steps drew the same sequence")
+	}
+
+	r.SetSeed(DefaultFixedSeed + 1)
+	if reseeded := draw(r.ForRun("noise"), 16); equalDraws(noise, reseeded) {
+		t.Fatal("different seeds drew the same sequence")
+	}
+}
+
+func TestForRunWithoutSeedDiffers(t *testing.T) {
+	r := NewSharedRand(0)
+
+	if equalDraws(draw(r.ForRun("noise"), 16), draw(r.ForRun("noise"), 16)) {
+		t.Fatal("unseeded runs drew the same sequence")
+	}
+}
@@ -0,0 +1,58 @@
+package processing
+
+import (
+	"context"
+	"sync"
+)
+
+// RunStatistics collects the statistics an algorithm reports for one
+// processing run, such as its thresholds. Each run gets its own collector
+// through its context, so concurrent runs on a shared processor cannot see
+// each other's values.
+type RunStatistics struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+type statisticsKey struct{}
+
+// WithRunStatistics attaches a new statistics collector to the context
+func WithRunStatistics(ctx context.Context) (context.Context, *RunStatistics) {
+	stats := &RunStatistics{}
+	return context.WithValue(ctx, statisticsKey{}, stats), stats
+}
+
+// RecordStatistics adds values to the run's statistics, replacing earlier
+// values of the same name. It does nothing when no collector is attached.
+func RecordStatistics(ctx context.Context, values map[string]interface{}) {
+	stats, ok := ctx.Value(statisticsKey{}).(*RunStatistics)
+	if !ok {
+		return
+	}
+
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	if stats.values == nil {
+		stats.values = make(map[string]interface{}, len(values))
+	}
+	for key, value := range values {
+		stats.values[key] = value
+	}
+}
+
+// Values returns a copy of the recorded statistics, nil when none were
+// recorded
+func (s *RunStatistics) Values() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.values) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
@@ -1,6 +1,7 @@
 package threshold
 
 import (
+	"context"
 	"fmt"
 
 	"otsu-obliterator/internal/opencv/safe"
@@ -117,13 +118,13 @@ func NewBilinearApplier() *BilinearApplier {
 	return &BilinearApplier{}
 }
 
-func (b *BilinearApplier) Apply(src, neighborhood *safe.Mat, thresholds [2]float64) (*safe.Mat, error) {
+func (b *BilinearApplier) Apply(ctx context.Context, src, neighborhood *safe.Mat, thresholds [2]float64) (*safe.Mat, error) {
 	result, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create result Mat: %w", err)
 	}
 
-	err = b.applyThresholdBilinear(src, neighborhood, result, thresholds)
+	err = b.applyThresholdBilinear(ctx, src, neighborhood, result, thresholds)
 	if err != nil {
 		result.Close()
 		return nil, err
@@ -132,13 +133,19 @@ func (b *BilinearApplier) Apply(src, neighborhood *safe.Mat, thresholds [2]float
 	return result, nil
 }
 
-func (b *BilinearApplier) applyThresholdBilinear(src, neighborhood, dst *safe.Mat, threshold [2]float64) error {
+func (b *BilinearApplier) applyThresholdBilinear(ctx context.Context, src, neighborhood, dst *safe.Mat, threshold [2]float64) error {
 	rows := src.Rows()
 	cols := src.Cols()
 	histBins := 256 // Standard 8-bit range
 	binScale := float64(histBins-1) / 255.0
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			pixelValue, err := src.GetUCharAt(y, x)
 			if err != nil {
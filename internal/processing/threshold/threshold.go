@@ -8,6 +8,66 @@ import (
 	"otsu-obliterator/internal/opencv/safe"
 )
 
+// ForceThresholdParam is the parameter that fixes the threshold of a run,
+// skipping the optimisation. Its value is a *float64; nil or absent
+// lets the algorithm choose.
+const ForceThresholdParam = "force_threshold"
+
+// ForcedThreshold returns the fixed threshold requested in params, if any
+func ForcedThreshold(params map[string]interface{}) (float64, bool) {
+	switch value := params[ForceThresholdParam].(type) {
+	case *float64:
+		if value != nil {
+			return *value, true
+		}
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+// ValidateForcedThreshold checks that a forced threshold is a valid intensity
+func ValidateForcedThreshold(params map[string]interface{}) error {
+	if value, ok := ForcedThreshold(params); ok && (value < 0 || value > 255) {
+		return fmt.Errorf("%s must be between 0 and 255, got: %f", ForceThresholdParam, value)
+	}
+	return nil
+}
+
+// InitialThresholdOverrideParam is the parameter that replaces the initial
+// threshold of iterative algorithms, such as a warm start from a coarser
+// pyramid level. Unlike a forced threshold the iterations still run.
+const InitialThresholdOverrideParam = "initial_threshold_override"
+
+// InitialThresholdOverride returns the initial threshold requested in params, if any
+func InitialThresholdOverride(params map[string]interface{}) (float64, bool) {
+	value, ok := params[InitialThresholdOverrideParam].(float64)
+	if !ok || value < 0 || value > 255 {
+		return 0, false
+	}
+	return value, true
+}
+
+// WarmStartThresholdStat is the run statistic in which iterative algorithms
+// report the threshold a finer pyramid level should start from, passed to it
+// as InitialThresholdOverrideParam
+const WarmStartThresholdStat = "warm_start_threshold"
+
+// PyramidLevelsParam is the parameter that runs an algorithm coarse to fine
+// over that many halved levels below full resolution; 0 disables the pyramid
+const PyramidLevelsParam = "pyramid_levels"
+
+// MaxPyramidLevels bounds PyramidLevelsParam
+const MaxPyramidLevels = 6
+
+// ValidatePyramidLevels checks that the pyramid level count is in range
+func ValidatePyramidLevels(params map[string]interface{}) error {
+	if levels, ok := params[PyramidLevelsParam].(int); ok && (levels < 0 || levels > MaxPyramidLevels) {
+		return fmt.Errorf("%s must be between 0 and %d, got: %d", PyramidLevelsParam, MaxPyramidLevels, levels)
+	}
+	return nil
+}
+
 // Otsu2DCalculator implements 2D Otsu thresholding
 type Otsu2DCalculator struct{}
 
@@ -121,13 +181,13 @@ func NewBilinearApplier() *BilinearApplier {
 	return &BilinearApplier{}
 }
 
-func (b *BilinearApplier) Apply(src, neighborhood *safe.Mat, thresholds [2]float64) (*safe.Mat, error) {
+func (b *BilinearApplier) Apply(ctx context.Context, src, neighborhood *safe.Mat, thresholds [2]float64) (*safe.Mat, error) {
 	result, err := safe.NewMat(src.Rows(), src.Cols(), src.Type())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create result Mat: %w", err)
 	}
 
-	err = b.applyThresholdBilinear(src, neighborhood, result, thresholds)
+	err = b.applyThresholdBilinear(ctx, src, neighborhood, result, thresholds)
 	if err != nil {
 		result.Close()
 		return nil, err
@@ -136,13 +196,19 @@ func (b *BilinearApplier) Apply(src, neighborhood *safe.Mat, thresholds [2]float
 	return result, nil
 }
 
-func (b *BilinearApplier) applyThresholdBilinear(src, neighborhood, dst *safe.Mat, threshold [2]float64) error {
+func (b *BilinearApplier) applyThresholdBilinear(ctx context.Context, src, neighborhood, dst *safe.Mat, threshold [2]float64) error {
 	rows := src.Rows()
 	cols := src.Cols()
 	histBins := 256 // Standard 8-bit range
 	binScale := float64(histBins-1) / 255.0
 
+	rowsPerCheck := max(1, rows/100)
 	for y := 0; y < rows; y++ {
+		if y%rowsPerCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		for x := 0; x < cols; x++ {
 			pixelValue, err := src.GetUCharAt(y, x)
 			if err != nil {
@@ -558,17 +624,3 @@ func (t *TriclassCalculator) getStringParam(params map[string]interface{}, key s
 	}
 	return defaultValue
 }
-
-func max(a, b int) int {
-	if a > b {
-		return a
-	}
-	return b
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
\ No newline at end of file
@@ -537,10 +537,18 @@ func (t *TriclassCalculator) estimateNoiseLevel(src *safe.Mat) float64 {
 }
 
 func (t *TriclassCalculator) isHistogramBimodal(histogram []int) bool {
+	return len(FindHistogramPeaks(histogram, 0)) >= 2
+}
+
+// FindHistogramPeaks returns the bins of the local maxima of histogram after
+// a 5-bin moving average, which keeps single-bin noise from counting. Maxima
+// not above minFraction of the tallest smoothed bin are left out.
+func FindHistogramPeaks(histogram []int, minFraction float64) []int {
 	histBins := len(histogram)
 
 	// Smooth histogram to reduce noise in peak detection
 	smoothed := make([]float64, histBins)
+	tallest := 0.0
 	for i := 0; i < histBins; i++ {
 		sum := 0.0
 		count := 0
@@ -549,15 +557,17 @@ func (t *TriclassCalculator) isHistogramBimodal(histogram []int) bool {
 			count++
 		}
 		smoothed[i] = sum / float64(count)
+		tallest = max(tallest, smoothed[i])
 	}
 
 	// Find local maxima
-	peaks := 0
+	floor := minFraction * tallest
+	var peaks []int
 	for i := 1; i < histBins-1; i++ {
-		if smoothed[i] > smoothed[i-1] && smoothed[i] > smoothed[i+1] && smoothed[i] > 0 {
-			peaks++
+		if smoothed[i] > smoothed[i-1] && smoothed[i] > smoothed[i+1] && smoothed[i] > floor {
+			peaks = append(peaks, i)
 		}
 	}
 
-	return peaks >= 2
+	return peaks
 }
\ No newline at end of file
@@ -0,0 +1,185 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"otsu-obliterator/proto/processingpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ClientResult is the JSON document the client prints to stdout
+type ClientResult struct {
+	Input         string                `json:"input"`
+	Output        string                `json:"output"`
+	Algorithm     string                `json:"algorithm"`
+	ProcessTimeMs int64                 `json:"process_time_ms"`
+	Metrics       *processingpb.Metrics `json:"metrics"`
+}
+
+// paramFlags collects repeated --params key=value flags
+type paramFlags []string
+
+func (p *paramFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *paramFlags) Set(value string) error {
+	*p = append(*p, value)
+	return nil
+}
+
+// RunClient parses args, sends one image to the server, saves the result
+// and writes a JSON summary to stdout. With --progress the request is
+// streamed and progress is printed to stderr. It returns the process exit
+// code.
+func RunClient(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("otsu-client", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var params paramFlags
+	server := fs.String("server", DefaultAddress, "server address")
+	serverName := fs.String("server-name", "", "name expected in the server certificate (default: host of --server)")
+	input := fs.String("input", "", "input image path")
+	output := fs.String("output", "", "output PNG path")
+	algorithm := fs.String("algorithm", "2D Otsu", "thresholding algorithm name")
+	showProgress := fs.Bool("progress", false, "stream the request and print progress to stderr")
+	plaintext := fs.Bool("insecure", false, "connect without TLS, for local testing only")
+	fs.Var(&params, "params", "algorithm parameter as key=value (repeatable)")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *input == "" || *output == "" {
+		fmt.Fprintln(stderr, "both --input and --output are required")
+		fs.Usage()
+		return 2
+	}
+
+	creds := insecure.NewCredentials()
+	if !*plaintext {
+		config, err := ClientTLSConfig(*serverName)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		creds = credentials.NewTLS(config)
+	}
+
+	req, err := buildRequest(*input, *algorithm, params)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+
+	conn, err := grpc.NewClient(*server,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(MaxMessageSize),
+			grpc.MaxCallSendMsgSize(MaxMessageSize),
+		),
+	)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	client := processingpb.NewProcessingServiceClient(conn)
+	var resp *processingpb.ProcessResponse
+	if *showProgress {
+		resp, err = processStreaming(ctx, client, req, stderr)
+	} else {
+		resp, err = client.Process(ctx, req)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	if err := os.WriteFile(*output, resp.GetResultBytes(), 0o644); err != nil {
+		fmt.Fprintf(stderr, "error: failed to save %s: %v\n", *output, err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(ClientResult{
+		Input:         *input,
+		Output:        *output,
+		Algorithm:     *algorithm,
+		ProcessTimeMs: resp.GetProcessTimeMs(),
+		Metrics:       resp.GetMetrics(),
+	}); err != nil {
+		fmt.Fprintf(stderr, "error: failed to encode result: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// buildRequest reads the input image and splits the key=value parameters;
+// the server converts their values
+func buildRequest(input, algorithm string, rawParams []string) (*processingpb.ProcessRequest, error) {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	overrides := make(map[string]string, len(rawParams))
+	for _, raw := range rawParams {
+		key, value, found := strings.Cut(raw, "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", raw)
+		}
+		overrides[key] = value
+	}
+
+	return &processingpb.ProcessRequest{
+		ImageBytes: data,
+		Algorithm:  algorithm,
+		Params:     overrides,
+	}, nil
+}
+
+// processStreaming sends req on a stream and prints its progress updates
+// until the result arrives
+func processStreaming(ctx context.Context, client processingpb.ProcessingServiceClient, req *processingpb.ProcessRequest, stderr io.Writer) (*processingpb.ProcessResponse, error) {
+	stream, err := client.ProcessStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("stream ended without a result")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if progress := update.GetProgress(); progress != nil {
+			fmt.Fprintf(stderr, "%-10s %3.0f%%\n", progress.GetStage(), progress.GetFraction()*100)
+		}
+		if result := update.GetResult(); result != nil {
+			return result, nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/opencv/memory"
+	"otsu-obliterator/proto/processingpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DefaultAddress is where the server listens and the client connects unless
+// told otherwise
+const DefaultAddress = "localhost:50051"
+
+// RunServer parses args and serves requests until ctx is cancelled. It
+// returns the process exit code.
+func RunServer(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("otsu-server", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	listen := fs.String("listen", DefaultAddress, "address to listen on")
+	concurrency := fs.Int("concurrency", 2, "images processed at the same time")
+	plaintext := fs.Bool("insecure", false, "serve without TLS, for local testing only")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	log := logger.NewFileLogger(logger.InfoLevel, stderr)
+
+	creds := insecure.NewCredentials()
+	if !*plaintext {
+		config, err := ServerTLSConfig()
+		if err != nil {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+			return 2
+		}
+		creds = credentials.NewTLS(config)
+	} else {
+		log.Warning("Serving without TLS", map[string]interface{}{"address": *listen})
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+
+	memManager := memory.NewManager(log)
+	defer memManager.Shutdown()
+
+	server := NewServer(memManager, log, *concurrency)
+	defer server.Shutdown()
+
+	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
+		grpc.MaxRecvMsgSize(MaxMessageSize),
+		grpc.MaxSendMsgSize(MaxMessageSize),
+	)
+	processingpb.RegisterProcessingServiceServer(grpcServer, server)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(listener)
+	}()
+
+	fmt.Fprintf(stdout, "listening on %s\n", listener.Addr())
+	log.Info("Processing server started", map[string]interface{}{
+		"address":     listener.Addr().String(),
+		"concurrency": *concurrency,
+		"tls":         !*plaintext,
+	})
+
+	select {
+	case <-ctx.Done():
+		grpcServer.GracefulStop()
+		return 0
+	case err := <-serveErr:
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+}
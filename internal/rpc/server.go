@@ -0,0 +1,199 @@
+// Package rpc serves the processing pipeline over gRPC, as defined in
+// proto/processing.proto, and provides the matching command line client.
+// The server runs pipeline.Coordinator directly and never touches the Fyne UI.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/algorithms/convergence"
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/opencv/memory"
+	"otsu-obliterator/internal/pipeline"
+	"otsu-obliterator/proto/processingpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxMessageSize bounds request and response messages, which carry whole
+// encoded images
+const MaxMessageSize = 256 << 20
+
+// progressFunc receives the stage of a request and the fraction completed
+type progressFunc func(stage string, fraction float64)
+
+// Server implements processingpb.ProcessingServiceServer. Each request takes
+// a coordinator from a fixed pool, so at most concurrency images are held
+// in memory and processed at once.
+type Server struct {
+	processingpb.UnimplementedProcessingServiceServer
+
+	coordinators chan *pipeline.Coordinator
+	algorithms   *algorithms.Manager
+	logger       logger.Logger
+}
+
+// NewServer creates a server with concurrency coordinators
+func NewServer(memManager *memory.Manager, log logger.Logger, concurrency int) *Server {
+	concurrency = max(1, concurrency)
+	coordinators := make(chan *pipeline.Coordinator, concurrency)
+	for i := 0; i < concurrency; i++ {
+		coordinators <- pipeline.NewCoordinator(memManager, log)
+	}
+
+	return &Server{
+		coordinators: coordinators,
+		algorithms:   algorithms.NewManager(),
+		logger:       log,
+	}
+}
+
+// Shutdown waits for running requests and shuts the coordinators down
+func (s *Server) Shutdown() {
+	for i := 0; i < cap(s.coordinators); i++ {
+		coordinator := <-s.coordinators
+		coordinator.Shutdown()
+	}
+}
+
+// Process segments one image
+func (s *Server) Process(ctx context.Context, req *processingpb.ProcessRequest) (*processingpb.ProcessResponse, error) {
+	return s.process(ctx, req, nil)
+}
+
+// ProcessStream segments the images of the stream in turn, sending progress
+// updates for each before its result
+func (s *Server) ProcessStream(stream grpc.BidiStreamingServer[processingpb.ProcessRequest, processingpb.ProcessUpdate]) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Iterative algorithms may report from their own goroutines
+		var sendMu sync.Mutex
+		progress := func(stage string, fraction float64) {
+			sendMu.Lock()
+			defer sendMu.Unlock()
+			_ = stream.Send(&processingpb.ProcessUpdate{
+				Update: &processingpb.ProcessUpdate_Progress{
+					Progress: &processingpb.Progress{Stage: stage, Fraction: fraction},
+				},
+			})
+		}
+
+		resp, err := s.process(stream.Context(), req, progress)
+		if err != nil {
+			return err
+		}
+
+		sendMu.Lock()
+		err = stream.Send(&processingpb.ProcessUpdate{
+			Update: &processingpb.ProcessUpdate_Result{Result: resp},
+		})
+		sendMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// process loads, segments and encodes the image of req, reporting to
+// progress when it is not nil
+func (s *Server) process(ctx context.Context, req *processingpb.ProcessRequest, progress progressFunc) (*processingpb.ProcessResponse, error) {
+	if progress == nil {
+		progress = func(string, float64) {}
+	}
+
+	if len(req.GetImageBytes()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "image_bytes is empty")
+	}
+	parameters, err := s.algorithms.ParseParameters(req.GetAlgorithm(), req.GetParams())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var coordinator *pipeline.Coordinator
+	select {
+	case coordinator = <-s.coordinators:
+		defer func() { s.coordinators <- coordinator }()
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	progress("loading", 0)
+	original, err := coordinator.LoadImageBytes(req.GetImageBytes())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to load image: %v", err)
+	}
+
+	// Iterations fill the processing stage, between 10% and 90%
+	progress("processing", 0.1)
+	processCtx := convergence.WithIterationHandler(ctx, func(iteration, maxIterations int, _, _ float64) {
+		if maxIterations > 0 {
+			progress("processing", 0.1+0.8*min(1, float64(iteration)/float64(maxIterations)))
+		}
+	})
+
+	start := time.Now()
+	processed, err := coordinator.ProcessImageWithContext(processCtx, req.GetAlgorithm(), parameters)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, status.FromContextError(ctxErr).Err()
+		}
+		return nil, status.Errorf(codes.Internal, "processing failed: %v", err)
+	}
+	processTime := time.Since(start)
+
+	metrics, err := coordinator.CalculateSegmentationMetrics(original, processed)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "metrics calculation failed: %v", err)
+	}
+
+	progress("encoding", 0.9)
+	var encoded bytes.Buffer
+	if err := coordinator.SaveImageToWriter(&encoded, processed, "png"); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode result: %v", err)
+	}
+
+	s.logger.Info("Request processed", map[string]interface{}{
+		"algorithm":       req.GetAlgorithm(),
+		"width":           processed.Width,
+		"height":          processed.Height,
+		"process_time_ms": processTime.Milliseconds(),
+	})
+
+	return &processingpb.ProcessResponse{
+		ResultBytes:   encoded.Bytes(),
+		Metrics:       toProtoMetrics(metrics),
+		ProcessTimeMs: processTime.Milliseconds(),
+	}, nil
+}
+
+// toProtoMetrics copies the pipeline metrics into their message
+func toProtoMetrics(metrics *pipeline.SegmentationMetrics) *processingpb.Metrics {
+	if metrics == nil {
+		return nil
+	}
+	return &processingpb.Metrics{
+		Iou:                    metrics.IoU,
+		DiceCoefficient:        metrics.DiceCoefficient,
+		MisclassificationError: metrics.MisclassificationError,
+		RegionUniformity:       metrics.RegionUniformity,
+		BoundaryAccuracy:       metrics.BoundaryAccuracy,
+		HausdorffDistance:      metrics.HausdorffDistance,
+		Psnr:                   metrics.PSNR,
+		Ssim:                   metrics.SSIM,
+	}
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// Environment variables naming the PEM files for mutual TLS. The server and
+// the client each present their own certificate and key, and trust peers
+// whose certificates are signed by the CA.
+const (
+	TLSCertEnv = "OTSU_TLS_CERT"
+	TLSKeyEnv  = "OTSU_TLS_KEY"
+	TLSCAEnv   = "OTSU_TLS_CA"
+)
+
+// ServerTLSConfig returns a TLS configuration that only accepts clients
+// presenting a certificate signed by the CA
+func ServerTLSConfig() (*tls.Config, error) {
+	certificate, pool, err := loadTLSFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// ClientTLSConfig returns a TLS configuration that presents the client
+// certificate and verifies the server against the CA. An empty serverName
+// is taken from the address being dialled.
+func ClientTLSConfig(serverName string) (*tls.Config, error) {
+	certificate, pool, err := loadTLSFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      pool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// loadTLSFiles reads the key pair and CA named by the environment
+func loadTLSFiles() (tls.Certificate, *x509.CertPool, error) {
+	certFile, keyFile, caFile := os.Getenv(TLSCertEnv), os.Getenv(TLSKeyEnv), os.Getenv(TLSCAEnv)
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return tls.Certificate{}, nil, fmt.Errorf("%s, %s and %s must name the certificate, key and CA files", TLSCertEnv, TLSKeyEnv, TLSCAEnv)
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to load key pair: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to read CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return tls.Certificate{}, nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	return certificate, pool, nil
+}
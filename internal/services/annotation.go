@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+const (
+	annotationThickness = 2
+	annotationFontScale = 0.6
+)
+
+// annotationLabelOffset places shape labels just above their first point
+var annotationLabelOffset = image.Pt(0, -6)
+
+// BurnAnnotations returns a colour copy of img with the annotations drawn
+// into its pixels. The returned image owns its Mat.
+func BurnAnnotations(img *models.ImageData, annotations []models.Annotation) (*models.ImageData, error) {
+	if img == nil || img.Mat == nil {
+		return nil, fmt.Errorf("no image to annotate")
+	}
+
+	canvas := gocv.NewMat()
+	defer canvas.Close()
+
+	src := img.Mat.GetMat()
+	switch img.Mat.Channels() {
+	case 1:
+		gocv.CvtColor(src, &canvas, gocv.ColorGrayToBGR)
+	case 3:
+		src.CopyTo(&canvas)
+	case 4:
+		gocv.CvtColor(src, &canvas, gocv.ColorBGRAToBGR)
+	default:
+		return nil, fmt.Errorf("unsupported channel count: %d", img.Mat.Channels())
+	}
+
+	for _, annotation := range annotations {
+		drawAnnotation(&canvas, annotation)
+	}
+
+	mat, err := safe.NewMatFromMat(canvas)
+	if err != nil {
+		return nil, err
+	}
+
+	burned, err := conversion.MatToImage(mat)
+	if err != nil {
+		mat.Close()
+		return nil, fmt.Errorf("annotated image conversion failed: %w", err)
+	}
+
+	annotated := *img
+	annotated.Image = burned
+	annotated.Mat = mat
+	annotated.Channels = 3
+	return &annotated, nil
+}
+
+// drawAnnotation draws one annotation and its label onto a BGR Mat
+func drawAnnotation(canvas *gocv.Mat, annotation models.Annotation) {
+	if len(annotation.Points) == 0 {
+		return
+	}
+
+	c := annotation.Color
+	stroke := color.RGBA{R: c.R, G: c.G, B: c.B, A: 255}
+
+	switch annotation.Kind {
+	case models.AnnotationFreehand:
+		points := gocv.NewPointsVectorFromPoints([][]image.Point{annotation.Points})
+		gocv.Polylines(canvas, points, false, stroke, annotationThickness)
+		points.Close()
+	case models.AnnotationRectangle:
+		if len(annotation.Points) < 2 {
+			return
+		}
+		r := image.Rectangle{Min: annotation.Points[0], Max: annotation.Points[1]}.Canon()
+		corners := []image.Point{r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y}}
+		points := gocv.NewPointsVectorFromPoints([][]image.Point{corners})
+		gocv.Polylines(canvas, points, true, stroke, annotationThickness)
+		points.Close()
+	case models.AnnotationCircle:
+		gocv.Circle(canvas, annotation.Points[0], annotation.Radius(), stroke, annotationThickness)
+	case models.AnnotationText:
+		gocv.PutText(canvas, annotation.Label, annotation.Points[0], gocv.FontHersheySimplex,
+			annotationFontScale, stroke, annotationThickness)
+		return
+	}
+
+	if annotation.Label != "" {
+		gocv.PutText(canvas, annotation.Label, annotation.Points[0].Add(annotationLabelOffset),
+			gocv.FontHersheySimplex, annotationFontScale, stroke, annotationThickness)
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+const (
+	// MinAutoSaveInterval is the shortest time allowed between auto-saves
+	MinAutoSaveInterval = 5 * time.Second
+
+	// autoSavePollInterval is how often the repository is checked for a new result
+	autoSavePollInterval = time.Second
+
+	autoSaveTimestampLayout = "20060102-150405"
+)
+
+// AutoSaver writes each new processed image to disk when the auto_save
+// preference is enabled. Results arriving faster than auto_save_interval_seconds
+// are coalesced, so only the latest one is saved when the interval elapses.
+type AutoSaver struct {
+	imageService *ImageService
+	imageRepo    *models.ImageRepository
+	configRepo   *models.ProcessingConfiguration
+	logger       logger.Logger
+
+	mu        sync.Mutex
+	directory fyne.URI
+	lastSaved *models.ImageData
+	lastSave  time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// NewAutoSaver creates an auto-saver for the processed images in imageRepo
+func NewAutoSaver(imageService *ImageService, imageRepo *models.ImageRepository, configRepo *models.ProcessingConfiguration, log logger.Logger) *AutoSaver {
+	return &AutoSaver{
+		imageService: imageService,
+		imageRepo:    imageRepo,
+		configRepo:   configRepo,
+		logger:       log,
+	}
+}
+
+// SetAutoSaveDirectory redirects auto-saves to dir. A nil dir saves next to
+// the input image again.
+func (as *AutoSaver) SetAutoSaveDirectory(dir fyne.URI) {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	as.directory = dir
+}
+
+// AutoSaveDirectory returns the folder set by SetAutoSaveDirectory, nil when
+// saving next to the input image
+func (as *AutoSaver) AutoSaveDirectory() fyne.URI {
+	as.mu.Lock()
+	defer as.mu.Unlock()
+	return as.directory
+}
+
+// Start watches for new processed images until ctx is cancelled or Stop is called
+func (as *AutoSaver) Start(ctx context.Context) {
+	as.mu.Lock()
+	if as.cancel != nil {
+		as.mu.Unlock()
+		return
+	}
+	ctx, as.cancel = context.WithCancel(ctx)
+	as.done = make(chan struct{})
+	// The image already on screen at start is not a new result
+	as.lastSaved = as.imageRepo.GetLatestProcessedImage()
+	done := as.done
+	as.mu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(autoSavePollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				as.check(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends watching and waits for an auto-save in progress to finish
+func (as *AutoSaver) Stop() {
+	as.mu.Lock()
+	cancel, done := as.cancel, as.done
+	as.cancel = nil
+	as.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// check saves the latest processed image if it is new and the interval has passed
+func (as *AutoSaver) check(ctx context.Context) {
+	if enabled, _ := as.setting("auto_save").(bool); !enabled {
+		return
+	}
+
+	latest := as.imageRepo.GetLatestProcessedImage()
+
+	as.mu.Lock()
+	due := latest != nil && latest != as.lastSaved && time.Since(as.lastSave) >= as.interval()
+	if due {
+		as.lastSaved = latest
+		as.lastSave = time.Now()
+	}
+	dir := as.directory
+	as.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	outputURI, err := as.save(ctx, latest, dir)
+	if err != nil {
+		as.logger.Warning("Auto-save failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	as.logger.Info("Processed image auto-saved", map[string]interface{}{
+		"path": outputURI.String(),
+	})
+}
+
+// interval returns auto_save_interval_seconds, never less than MinAutoSaveInterval
+func (as *AutoSaver) interval() time.Duration {
+	seconds, _ := as.setting("auto_save_interval_seconds").(int)
+	return max(time.Duration(seconds)*time.Second, MinAutoSaveInterval)
+}
+
+func (as *AutoSaver) setting(key string) interface{} {
+	value, _ := as.configRepo.GetGlobalSetting(key)
+	return value
+}
+
+// save writes img as <basename>_otsu_<timestamp>.<format> in dir, or beside
+// the input image when dir is nil
+func (as *AutoSaver) save(ctx context.Context, img *models.ImageData, dir fyne.URI) (fyne.URI, error) {
+	baseName := "image"
+	if img.OriginalURI != nil {
+		baseName = strings.TrimSuffix(img.OriginalURI.Name(), img.OriginalURI.Extension())
+		if dir == nil {
+			parent, err := storage.Parent(img.OriginalURI)
+			if err != nil {
+				return nil, fmt.Errorf("cannot determine input folder: %w", err)
+			}
+			dir = parent
+		}
+	}
+	if dir == nil {
+		return nil, fmt.Errorf("no auto-save folder set and the image has no input folder")
+	}
+
+	format, _ := as.setting("default_save_format").(string)
+	if !models.IsSupportedSaveFormat(format) {
+		format = "png"
+	}
+
+	name := fmt.Sprintf("%s_otsu_%s%s", baseName, time.Now().Format(autoSaveTimestampLayout), models.SaveFormatExtension(format))
+	outputURI, err := storage.Child(dir, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build output path: %w", err)
+	}
+
+	writer, err := storage.Writer(outputURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	if err := as.imageService.SaveImage(ctx, writer, img, format); err != nil {
+		return nil, fmt.Errorf("failed to save result: %w", err)
+	}
+	return outputURI, nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"otsu-obliterator/internal/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// BatchResult holds the outcome of processing a single file in a batch run
+type BatchResult struct {
+	FileName    string
+	InputURI    fyne.URI
+	OutputURI   fyne.URI
+	Algorithm   string
+	IoU         float64
+	Dice        float64
+	ProcessTime time.Duration
+	Err         error
+}
+
+// BatchSummary aggregates a batch run for display
+type BatchSummary struct {
+	Total           int
+	Succeeded       int
+	Failed          int
+	MeanProcessTime time.Duration
+	MeanIoU         float64
+}
+
+// BatchProcessor applies the current algorithm to every image in a directory
+type BatchProcessor struct {
+	imageService      *ImageService
+	processingService *ProcessingService
+	configRepo        *models.ProcessingConfiguration
+}
+
+// NewBatchProcessor creates a new batch processor
+func NewBatchProcessor(imageService *ImageService, processingService *ProcessingService, configRepo *models.ProcessingConfiguration) *BatchProcessor {
+	return &BatchProcessor{
+		imageService:      imageService,
+		processingService: processingService,
+		configRepo:        configRepo,
+	}
+}
+
+// RunBatch processes all supported images in inputDir with the current algorithm and
+// parameters, writing results to outputDir. progressFn is called after each file with
+// the number of files completed and the total.
+func (bp *BatchProcessor) RunBatch(ctx context.Context, inputDir, outputDir fyne.URI, progressFn func(int, int)) ([]BatchResult, error) {
+	files, err := bp.listImageFiles(inputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no supported images found in %s", inputDir.Path())
+	}
+
+	return bp.RunFiles(ctx, files, outputDir, progressFn)
+}
+
+// RunFiles processes the given image files like RunBatch, for selections that
+// do not come from a single directory such as files dropped on the window
+func (bp *BatchProcessor) RunFiles(ctx context.Context, files []fyne.URI, outputDir fyne.URI, progressFn func(int, int)) ([]BatchResult, error) {
+	algorithm := bp.configRepo.GetCurrentAlgorithm()
+	params, err := bp.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get algorithm parameters: %w", err)
+	}
+
+	results := make([]BatchResult, 0, len(files))
+	for i, file := range files {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+
+		results = append(results, bp.processFile(ctx, file, outputDir, algorithm, params.Parameters))
+
+		if progressFn != nil {
+			progressFn(i+1, len(files))
+		}
+	}
+
+	return results, nil
+}
+
+// processFile loads, processes and saves a single image
+func (bp *BatchProcessor) processFile(ctx context.Context, file, outputDir fyne.URI, algorithm string, params map[string]interface{}) BatchResult {
+	result := BatchResult{
+		FileName:  file.Name(),
+		InputURI:  file,
+		Algorithm: algorithm,
+	}
+
+	reader, err := storage.Reader(file)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to open file: %w", err)
+		return result
+	}
+
+	imageData, err := bp.imageService.DecodeImage(ctx, reader)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer imageData.Mat.Close()
+
+	processed, err := bp.processingService.ProcessImageData(ctx, imageData, algorithm, params)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer processed.ProcessedImage.Mat.Close()
+
+	result.ProcessTime = processed.ProcessTime
+	if processed.Metrics != nil {
+		result.IoU = processed.Metrics.IoU
+		result.Dice = processed.Metrics.DiceCoefficient
+	}
+
+	baseName := strings.TrimSuffix(file.Name(), file.Extension())
+	outputURI, err := storage.Child(outputDir, baseName+"_segmented.png")
+	if err != nil {
+		result.Err = fmt.Errorf("failed to build output path: %w", err)
+		return result
+	}
+
+	writer, err := storage.Writer(outputURI)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create output file: %w", err)
+		return result
+	}
+
+	if err := bp.imageService.SaveImage(ctx, writer, processed.ProcessedImage, "png"); err != nil {
+		result.Err = fmt.Errorf("failed to save result: %w", err)
+		return result
+	}
+
+	result.OutputURI = outputURI
+	return result
+}
+
+// listImageFiles returns the supported image files directly inside dir
+func (bp *BatchProcessor) listImageFiles(dir fyne.URI) ([]fyne.URI, error) {
+	lister, err := storage.ListerForURI(dir)
+	if err != nil {
+		return nil, fmt.Errorf("input is not a directory: %w", err)
+	}
+
+	entries, err := lister.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+
+	files := make([]fyne.URI, 0, len(entries))
+	for _, entry := range entries {
+		ext := strings.TrimPrefix(strings.ToLower(entry.Extension()), ".")
+		if ext != "" && bp.imageService.ValidateImageFormat(ext) {
+			files = append(files, entry)
+		}
+	}
+
+	return files, nil
+}
+
+// SummarizeBatch computes aggregate statistics over successful results
+func SummarizeBatch(results []BatchResult) BatchSummary {
+	summary := BatchSummary{Total: len(results)}
+
+	var totalTime time.Duration
+	var totalIoU float64
+	for _, result := range results {
+		if result.Err != nil {
+			summary.Failed++
+			continue
+		}
+		summary.Succeeded++
+		totalTime += result.ProcessTime
+		totalIoU += result.IoU
+	}
+
+	if summary.Succeeded > 0 {
+		summary.MeanProcessTime = totalTime / time.Duration(summary.Succeeded)
+		summary.MeanIoU = totalIoU / float64(summary.Succeeded)
+	}
+
+	return summary
+}
+
+// WriteBatchCSV writes batch results as CSV with a header row
+func WriteBatchCSV(w io.Writer, results []BatchResult) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"file", "algorithm", "iou", "dice", "processing_time_ms", "output", "error"}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, result := range results {
+		output := ""
+		if result.OutputURI != nil {
+			output = result.OutputURI.Path()
+		}
+
+		errText := ""
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+
+		record := []string{
+			result.FileName,
+			result.Algorithm,
+			strconv.FormatFloat(result.IoU, 'f', 4, 64),
+			strconv.FormatFloat(result.Dice, 'f', 4, 64),
+			strconv.FormatInt(result.ProcessTime.Milliseconds(), 10),
+			output,
+			errText,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/processing"
+)
+
+// Compare mode runs these two algorithms side by side
+const (
+	CompareLeftAlgorithm  = "2D Otsu"
+	CompareRightAlgorithm = "Iterative Triclass"
+)
+
+// DualResult holds the two results of ProcessImageDual
+type DualResult struct {
+	Left, Right *models.ProcessingResult
+}
+
+// ProcessImageDual segments input with CompareLeftAlgorithm and
+// CompareRightAlgorithm at the same time, each with its configured parameters
+// on its own worker. The results are not stored in the repository; the
+// caller releases them with ReleaseDualResult.
+func (ps *ProcessingService) ProcessImageDual(ctx context.Context, input *models.ImageData) (*DualResult, error) {
+	if input == nil {
+		return nil, fmt.Errorf("no input image")
+	}
+	if ps.stateRepo.IsProcessing() {
+		return nil, fmt.Errorf("processing already in progress")
+	}
+
+	names := [2]string{CompareLeftAlgorithm, CompareRightAlgorithm}
+	ps.stateRepo.StartProcessing(names[0] + " / " + names[1])
+	defer ps.stateRepo.CompleteProcessing()
+
+	ctx, cancel := ps.withCancellation(ctx)
+	defer cancel()
+
+	var results [2]*models.ProcessingResult
+	var errs [2]error
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = ps.processOnWorker(ctx, input, names[i])
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("%s failed: %w", names[i], errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	dual := &DualResult{Left: results[0], Right: results[1]}
+	if err := errors.Join(errs[:]...); err != nil {
+		ps.ReleaseDualResult(dual)
+		ps.endFailedRun(ctx)
+		return nil, err
+	}
+
+	ps.recordRun(dual.Left)
+	ps.recordRun(dual.Right)
+	return dual, nil
+}
+
+// processOnWorker runs algorithm on input with its configured parameters
+// once a worker is free and measures the result
+func (ps *ProcessingService) processOnWorker(ctx context.Context, input *models.ImageData, algorithm string) (*models.ProcessingResult, error) {
+	params, err := ps.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get algorithm parameters: %w", err)
+	}
+
+	groundTruth, err := ps.groundTruthFor(input)
+	if err != nil {
+		return nil, err
+	}
+	if groundTruth != nil {
+		defer groundTruth.Mat.Close()
+	}
+
+	select {
+	case <-ps.workerPool:
+		defer func() { ps.workerPool <- struct{}{} }()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	statsCtx, runStats := processing.WithRunStatistics(ctx)
+	processed, err := ps.processWithROI(statsCtx, input, algorithm, params.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	processTime := time.Since(start)
+	processed.ProcessTime = processTime
+
+	metrics, err := ps.calculateSegmentationMetrics(input, processed, groundTruth)
+	if err != nil {
+		metrics = nil
+		ps.logMetricsSkipped(err)
+	}
+
+	return &models.ProcessingResult{
+		ProcessedImage: processed,
+		Algorithm:      algorithm,
+		Parameters:     params.Parameters,
+		Metrics:        metrics,
+		ProcessTime:    processTime,
+		Statistics:     runStats.Values(),
+	}, nil
+}
+
+// ReleaseDualResult frees the Mats of both results; nil results are skipped
+func (ps *ProcessingService) ReleaseDualResult(dual *DualResult) {
+	if dual == nil {
+		return
+	}
+	for _, result := range []*models.ProcessingResult{dual.Left, dual.Right} {
+		if result != nil && result.ProcessedImage != nil {
+			ps.memoryManager.ReleaseMat(result.ProcessedImage.Mat, "processing_result")
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// differenceGain magnifies |original - processed| so small changes are visible
+const differenceGain = 4.0
+
+// differenceLUT maps a signed difference centred on 128 onto a diverging BGR
+// colour map: black where nothing changed, red where the result is darker than
+// the original (bright pixels lost), blue where it is brighter (dark pixels
+// retained as foreground)
+var differenceLUT = func() [256][3]uint8 {
+	var lut [256][3]uint8
+	for i := range lut {
+		switch {
+		case i < 128:
+			lut[i][2] = uint8(min((128-i)*2, 255))
+		case i > 128:
+			lut[i][0] = uint8(min((i-128)*2, 255))
+		}
+	}
+	return lut
+}()
+
+// computeDifferenceImage colours the pixel-level changes between original and
+// processed, magnified by differenceGain. The returned image owns its Mat.
+func computeDifferenceImage(original, processed *models.ImageData) (*models.ImageData, error) {
+	if original == nil || original.Mat == nil || processed == nil || processed.Mat == nil {
+		return nil, fmt.Errorf("difference requires both images")
+	}
+	if original.Width != processed.Width || original.Height != processed.Height {
+		return nil, fmt.Errorf("image dimensions do not match")
+	}
+
+	originalGray, err := conversion.ConvertToGrayscale(original.Mat)
+	if err != nil {
+		return nil, err
+	}
+	defer originalGray.Close()
+
+	processedGray, err := conversion.ConvertToGrayscale(processed.Mat)
+	if err != nil {
+		return nil, err
+	}
+	defer processedGray.Close()
+
+	// 128 + gain/2 * (processed - original): each half of the colour map
+	// covers the magnified magnitude in one direction, saturating at 255
+	signed := gocv.NewMat()
+	defer signed.Close()
+	gocv.AddWeighted(processedGray.GetMat(), differenceGain/2, originalGray.GetMat(), -differenceGain/2, 128, &signed)
+
+	bgr := gocv.NewMat()
+	defer bgr.Close()
+	gocv.CvtColor(signed, &bgr, gocv.ColorGrayToBGR)
+
+	lut := gocv.NewMatWithSize(1, 256, gocv.MatTypeCV8UC3)
+	defer lut.Close()
+	for i, entry := range differenceLUT {
+		for channel, value := range entry {
+			lut.SetUCharAt(0, i*3+channel, value)
+		}
+	}
+
+	colored := gocv.NewMat()
+	defer colored.Close()
+	gocv.LUT(bgr, lut, &colored)
+
+	mat, err := safe.NewMatFromMat(colored)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := conversion.MatToImage(mat)
+	if err != nil {
+		mat.Close()
+		return nil, fmt.Errorf("difference image conversion failed: %w", err)
+	}
+
+	return &models.ImageData{
+		Image:    img,
+		Mat:      mat,
+		Width:    processed.Width,
+		Height:   processed.Height,
+		Channels: 3,
+		Format:   processed.Format,
+	}, nil
+}
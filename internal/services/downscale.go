@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/processing/histogram"
+
+	"gocv.io/x/gocv"
+)
+
+// processingSize returns the size an image of width x height is processed at
+// and whether that is smaller than the original
+func (ps *ProcessingService) processingSize(width, height int) (image.Point, bool) {
+	settings := ps.configRepo.GetPerformanceSettings()
+	limit := settings.MaxProcessingDimension
+	if settings.FullResolution || limit <= 0 || (width <= limit && height <= limit) {
+		return image.Pt(width, height), false
+	}
+
+	scale := float64(limit) / float64(max(width, height))
+	return image.Pt(max(1, int(float64(width)*scale+0.5)), max(1, int(float64(height)*scale+0.5))), true
+}
+
+// processDownscaled processes a reduced copy of the input and scales the
+// binary result back to the original size
+func (ps *ProcessingService) processDownscaled(
+	ctx context.Context,
+	algorithm algorithms.Algorithm,
+	input *models.ImageData,
+	parameters map[string]interface{},
+	size image.Point,
+) (*safe.Mat, error) {
+	if ps.logger != nil {
+		ps.logger.Info("Downscaling image for processing", map[string]interface{}{
+			"original": fmt.Sprintf("%dx%d", input.Width, input.Height),
+			"scaled":   fmt.Sprintf("%dx%d", size.X, size.Y),
+			"scale":    float64(size.X) / float64(input.Width),
+		})
+	}
+
+	small, err := resizeMat(input.Mat, size, gocv.InterpolationLanczos4)
+	if err != nil {
+		return nil, fmt.Errorf("downscaling failed: %w", err)
+	}
+	defer small.Close()
+
+	if weights, ok := parameters[histogram.WeightMapParam].(*safe.Mat); ok {
+		smallWeights, err := resizeMat(weights, size, gocv.InterpolationArea)
+		if err != nil {
+			return nil, fmt.Errorf("weight map downscaling failed: %w", err)
+		}
+		defer smallWeights.Close()
+		parameters = withParameter(parameters, histogram.WeightMapParam, smallWeights)
+	}
+
+	var result *safe.Mat
+	if contextualAlg, ok := algorithm.(algorithms.ContextualAlgorithm); ok {
+		result, err = contextualAlg.ProcessWithContext(ctx, small, parameters)
+	} else {
+		result, err = algorithm.Process(small, parameters)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return upscaleBinary(result, image.Pt(input.Width, input.Height))
+}
+
+// resizeMat returns a copy of src resized to size
+func resizeMat(src *safe.Mat, size image.Point, interpolation gocv.InterpolationFlags) (*safe.Mat, error) {
+	dst := gocv.NewMat()
+	defer dst.Close()
+
+	gocv.Resize(src.GetMat(), &dst, size, 0, 0, interpolation)
+	return safe.NewMatFromMat(dst)
+}
+
+// upscaleBinary enlarges a binary result to size. Interpolation blurs the
+// edges, so the result is re-thresholded and closed with a small kernel to
+// restore crisp boundaries.
+func upscaleBinary(src *safe.Mat, size image.Point) (*safe.Mat, error) {
+	enlarged := gocv.NewMat()
+	defer enlarged.Close()
+	gocv.Resize(src.GetMat(), &enlarged, size, 0, 0, gocv.InterpolationLinear)
+
+	binary := gocv.NewMat()
+	defer binary.Close()
+	gocv.Threshold(enlarged, &binary, 127, 255, gocv.ThresholdBinary)
+
+	kernel := gocv.GetStructuringElement(gocv.MorphEllipse, image.Pt(3, 3))
+	defer kernel.Close()
+
+	sharpened := gocv.NewMat()
+	defer sharpened.Close()
+	gocv.MorphologyEx(binary, &sharpened, gocv.MorphClose, kernel)
+
+	return safe.NewMatFromMat(sharpened)
+}
+
+// processedSize returns the dimensions passed to the algorithm for an image,
+// which is the region of interest when one is set
+func processedSize(img *models.ImageData, roi *image.Rectangle) (int, int) {
+	if roi != nil {
+		return roi.Dx(), roi.Dy()
+	}
+	return img.Width, img.Height
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/rwcarlsen/goexif/exif"
+)
+
+// exifFields are the EXIF tags shown to the user, by the names goexif
+// gives them
+var exifFields = []exif.FieldName{
+	exif.Make,
+	exif.Model,
+	exif.LensModel,
+	exif.DateTimeOriginal,
+	exif.ExposureTime,
+	exif.FNumber,
+	exif.ISOSpeedRatings,
+	exif.FocalLength,
+	exif.Flash,
+	exif.WhiteBalance,
+	exif.XResolution,
+	exif.YResolution,
+	exif.Artist,
+	exif.Copyright,
+	exif.Software,
+}
+
+// ReadExif returns the readable EXIF fields of raw image bytes by tag name,
+// nil when the image carries no EXIF data
+func ReadExif(data []byte) map[string]string {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	for _, name := range exifFields {
+		tag, err := x.Get(name)
+		if err != nil {
+			continue
+		}
+
+		value, err := tag.StringVal()
+		if err != nil {
+			value = tag.String()
+		}
+		value = strings.Trim(strings.TrimSpace(value), "\"\x00")
+		if value != "" {
+			fields[string(name)] = value
+		}
+	}
+	return fields
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FilenamePattern maps a token in image filenames to a parameter. Template
+// is matched literally apart from one {int} or {float} placeholder; a float
+// may use "p" as its decimal point, as in sigma1p5. A token must end at an
+// underscore or at the end of the name.
+type FilenamePattern struct {
+	Template  string
+	Parameter string
+}
+
+// DefaultFilenamePatterns read names such as sample_w7_b64_sigma1p5.tif as
+// window_size 7, histogram_bins 64 and smoothing_strength 1.5
+var DefaultFilenamePatterns = []FilenamePattern{
+	{Template: "_w{int}", Parameter: "window_size"},
+	{Template: "_b{int}", Parameter: "histogram_bins"},
+	{Template: "_s{float}", Parameter: "smoothing_strength"},
+	{Template: "_sigma{float}", Parameter: "smoothing_strength"},
+}
+
+const (
+	filenameIntPlaceholder   = "{int}"
+	filenameFloatPlaceholder = "{float}"
+)
+
+// filenameRule is a compiled FilenamePattern
+type filenameRule struct {
+	pattern   *regexp.Regexp
+	parameter string
+	isFloat   bool
+}
+
+// FilenameParameterParser extracts parameters encoded in image filenames by
+// acquisition systems
+type FilenameParameterParser struct {
+	rules []filenameRule
+}
+
+// NewFilenameParameterParser compiles patterns, or DefaultFilenamePatterns
+// when none are given
+func NewFilenameParameterParser(patterns ...FilenamePattern) (*FilenameParameterParser, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultFilenamePatterns
+	}
+
+	parser := &FilenameParameterParser{}
+	for _, p := range patterns {
+		rule, err := compileFilenamePattern(p)
+		if err != nil {
+			return nil, err
+		}
+		parser.rules = append(parser.rules, rule)
+	}
+	return parser, nil
+}
+
+// compileFilenamePattern turns a template into an anchored expression
+func compileFilenamePattern(p FilenamePattern) (filenameRule, error) {
+	if p.Parameter == "" {
+		return filenameRule{}, fmt.Errorf("filename pattern %q has no parameter", p.Template)
+	}
+
+	placeholder, valueExpr, isFloat := filenameIntPlaceholder, `(\d+)`, false
+	if strings.Contains(p.Template, filenameFloatPlaceholder) {
+		placeholder, valueExpr, isFloat = filenameFloatPlaceholder, `(\d+(?:[p.]\d+)?)`, true
+	}
+
+	prefix, suffix, found := strings.Cut(p.Template, placeholder)
+	if !found || strings.Contains(suffix, "{") {
+		return filenameRule{}, fmt.Errorf("filename pattern %q needs exactly one {int} or {float}", p.Template)
+	}
+
+	expr := regexp.QuoteMeta(prefix) + valueExpr + regexp.QuoteMeta(suffix) + `(?:_|$)`
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return filenameRule{}, fmt.Errorf("filename pattern %q: %w", p.Template, err)
+	}
+
+	return filenameRule{pattern: pattern, parameter: p.Parameter, isFloat: isFloat}, nil
+}
+
+// Parse returns the parameters encoded in filename, which may include a
+// directory and extension. Parameters are int or float64 as their pattern
+// declares; the first matching pattern for a parameter wins. The result is
+// empty when nothing matches.
+func (fp *FilenameParameterParser) Parse(filename string) map[string]interface{} {
+	base := filepath.Base(filename)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+
+	params := make(map[string]interface{})
+	for _, rule := range fp.rules {
+		if _, exists := params[rule.parameter]; exists {
+			continue
+		}
+
+		match := rule.pattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		if rule.isFloat {
+			value, err := strconv.ParseFloat(strings.Replace(match[1], "p", ".", 1), 64)
+			if err == nil {
+				params[rule.parameter] = value
+			}
+			continue
+		}
+
+		if value, err := strconv.Atoi(match[1]); err == nil {
+			params[rule.parameter] = value
+		}
+	}
+	return params
+}
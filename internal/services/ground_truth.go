@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"fyne.io/fyne/v2"
+	"gocv.io/x/gocv"
+)
+
+// LoadGroundTruth reads a reference segmentation and stores it in the repository
+// as a binary mask (0 background, 255 foreground)
+func (is *ImageService) LoadGroundTruth(reader fyne.URIReadCloser) error {
+	imageData, err := is.DecodeImage(context.Background(), reader)
+	if err != nil {
+		return fmt.Errorf("failed to load ground truth: %w", err)
+	}
+
+	if original := is.repository.GetOriginalImage(); original != nil &&
+		(original.Width != imageData.Width || original.Height != imageData.Height) {
+		imageData.Mat.Close()
+		return fmt.Errorf("ground truth is %dx%d but the image is %dx%d",
+			imageData.Width, imageData.Height, original.Width, original.Height)
+	}
+
+	mask, err := binaryMask(imageData.Mat)
+	imageData.Mat.Close()
+	if err != nil {
+		return fmt.Errorf("failed to binarise ground truth: %w", err)
+	}
+
+	maskImage, err := conversion.MatToImage(mask)
+	if err != nil {
+		mask.Close()
+		return fmt.Errorf("failed to convert ground truth: %w", err)
+	}
+
+	imageData.Mat = mask
+	imageData.Image = maskImage
+	imageData.Channels = 1
+	imageData.Metadata.ColorSpace = "grayscale"
+
+	is.repository.SetGroundTruth(imageData)
+	return nil
+}
+
+// ComputeErrorMap colour-codes each pixel of a segmentation against the loaded
+// ground truth: green true positive, red false positive, blue false negative,
+// black true negative
+func (ps *ProcessingService) ComputeErrorMap(processed *models.ImageData) (image.Image, error) {
+	groundTruth := ps.imageRepo.GetGroundTruth()
+	if groundTruth == nil {
+		return nil, fmt.Errorf("no ground truth loaded")
+	}
+	defer groundTruth.Mat.Close()
+
+	if processed == nil || processed.Mat == nil {
+		return nil, fmt.Errorf("no processed image")
+	}
+	if processed.Width != groundTruth.Width || processed.Height != groundTruth.Height {
+		return nil, fmt.Errorf("ground truth is %dx%d but result is %dx%d",
+			groundTruth.Width, groundTruth.Height, processed.Width, processed.Height)
+	}
+
+	segmented, err := binaryMask(processed.Mat)
+	if err != nil {
+		return nil, err
+	}
+	defer segmented.Close()
+
+	seg := segmented.GetMat()
+	gt := groundTruth.Mat.GetMat()
+
+	truePositive := gocv.NewMat()
+	defer truePositive.Close()
+	difference := gocv.NewMat()
+	defer difference.Close()
+	falsePositive := gocv.NewMat()
+	defer falsePositive.Close()
+	falseNegative := gocv.NewMat()
+	defer falseNegative.Close()
+
+	if err := gocv.BitwiseAnd(seg, gt, &truePositive); err != nil {
+		return nil, fmt.Errorf("true positive mask failed: %w", err)
+	}
+	if err := gocv.BitwiseXor(seg, gt, &difference); err != nil {
+		return nil, fmt.Errorf("difference mask failed: %w", err)
+	}
+	if err := gocv.BitwiseAnd(difference, seg, &falsePositive); err != nil {
+		return nil, fmt.Errorf("false positive mask failed: %w", err)
+	}
+	if err := gocv.BitwiseAnd(difference, gt, &falseNegative); err != nil {
+		return nil, fmt.Errorf("false negative mask failed: %w", err)
+	}
+
+	// BGR channel order: blue=FN, green=TP, red=FP
+	merged := gocv.NewMat()
+	defer merged.Close()
+	if err := gocv.Merge([]gocv.Mat{falseNegative, truePositive, falsePositive}, &merged); err != nil {
+		return nil, fmt.Errorf("error map merge failed: %w", err)
+	}
+
+	errorMap, err := safe.NewMatFromMat(merged)
+	if err != nil {
+		return nil, err
+	}
+	defer errorMap.Close()
+
+	return conversion.MatToImage(errorMap)
+}
+
+// groundTruthFor returns the loaded ground truth, or nil when none is loaded,
+// and fails when the mask does not match the size of input. The caller must
+// close the returned Mat.
+func (ps *ProcessingService) groundTruthFor(input *models.ImageData) (*models.ImageData, error) {
+	groundTruth := ps.imageRepo.GetGroundTruth()
+	if groundTruth == nil {
+		return nil, nil
+	}
+	if groundTruth.Width != input.Width || groundTruth.Height != input.Height {
+		groundTruth.Mat.Close()
+		return nil, fmt.Errorf("ground truth is %dx%d but the image is %dx%d",
+			groundTruth.Width, groundTruth.Height, input.Width, input.Height)
+	}
+	return groundTruth, nil
+}
+
+// binaryMask converts a Mat to a single channel 0/255 mask
+func binaryMask(src *safe.Mat) (*safe.Mat, error) {
+	gray, err := conversion.ConvertToGrayscale(src)
+	if err != nil {
+		return nil, err
+	}
+
+	grayMat := gray.GetMat()
+	gocv.Threshold(grayMat, &grayMat, 127, 255, gocv.ThresholdBinary)
+
+	return gray, nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// hausdorffPercentile is the rank used for the outlier robust Hausdorff distance
+const hausdorffPercentile = 0.95
+
+// calculateHausdorffDistances measures the boundary discrepancy between two
+// binary masks. It returns the symmetric Hausdorff distance and its 95th
+// percentile variant, both in pixels. Masks without foreground boundaries
+// yield zero for both values.
+func calculateHausdorffDistances(reference, segmented *safe.Mat) (float64, float64, error) {
+	if err := safe.ValidateMatForOperation(reference, "Hausdorff distance"); err != nil {
+		return 0, 0, err
+	}
+	if err := safe.ValidateMatForOperation(segmented, "Hausdorff distance"); err != nil {
+		return 0, 0, err
+	}
+	if reference.Rows() != segmented.Rows() || reference.Cols() != segmented.Cols() {
+		return 0, 0, fmt.Errorf("mask dimensions do not match")
+	}
+
+	referenceBoundary, err := maskBoundary(reference)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer referenceBoundary.Close()
+
+	segmentedBoundary, err := maskBoundary(segmented)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer segmentedBoundary.Close()
+
+	if gocv.CountNonZero(referenceBoundary) == 0 || gocv.CountNonZero(segmentedBoundary) == 0 {
+		return 0, 0, nil
+	}
+
+	referenceDistance, err := boundaryDistanceMap(referenceBoundary)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer referenceDistance.Close()
+
+	segmentedDistance, err := boundaryDistanceMap(segmentedBoundary)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer segmentedDistance.Close()
+
+	// Distances from each boundary to the nearest pixel of the other
+	toSegmented, err := sampleDistances(referenceBoundary, segmentedDistance)
+	if err != nil {
+		return 0, 0, err
+	}
+	toReference, err := sampleDistances(segmentedBoundary, referenceDistance)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	hausdorff := math.Max(toSegmented[len(toSegmented)-1], toReference[len(toReference)-1])
+	hausdorff95 := math.Max(percentile(toSegmented, hausdorffPercentile), percentile(toReference, hausdorffPercentile))
+
+	return hausdorff, hausdorff95, nil
+}
+
+// maskBoundary extracts foreground edges of a binary mask with a morphological gradient
+func maskBoundary(mask *safe.Mat) (gocv.Mat, error) {
+	kernel := gocv.GetStructuringElement(gocv.MorphRect, image.Pt(3, 3))
+	defer kernel.Close()
+
+	boundary := gocv.NewMat()
+	if err := gocv.MorphologyEx(mask.GetMat(), &boundary, gocv.MorphGradient, kernel); err != nil {
+		boundary.Close()
+		return gocv.NewMat(), fmt.Errorf("boundary extraction failed: %w", err)
+	}
+
+	gocv.Threshold(boundary, &boundary, 127, 255, gocv.ThresholdBinary)
+	return boundary, nil
+}
+
+// boundaryDistanceMap returns, for every pixel, the Euclidean distance to the
+// nearest boundary pixel
+func boundaryDistanceMap(boundary gocv.Mat) (gocv.Mat, error) {
+	// DistanceTransform measures distance to zero pixels, so boundaries become zero
+	inverted := gocv.NewMat()
+	defer inverted.Close()
+	if err := gocv.BitwiseNot(boundary, &inverted); err != nil {
+		return gocv.NewMat(), fmt.Errorf("boundary inversion failed: %w", err)
+	}
+
+	distance := gocv.NewMat()
+	labels := gocv.NewMat()
+	defer labels.Close()
+	if err := gocv.DistanceTransform(inverted, &distance, &labels, gocv.DistL2, gocv.DistanceMask5, gocv.DistanceLabelCComp); err != nil {
+		distance.Close()
+		return gocv.NewMat(), fmt.Errorf("distance transform failed: %w", err)
+	}
+
+	return distance, nil
+}
+
+// sampleDistances reads the distance map at every boundary pixel and returns
+// the values in ascending order
+func sampleDistances(boundary, distance gocv.Mat) ([]float64, error) {
+	boundaryPixels, err := boundary.DataPtrUint8()
+	if err != nil {
+		return nil, fmt.Errorf("boundary access failed: %w", err)
+	}
+	distances, err := distance.DataPtrFloat32()
+	if err != nil {
+		return nil, fmt.Errorf("distance map access failed: %w", err)
+	}
+
+	samples := make([]float64, 0, gocv.CountNonZero(boundary))
+	for i, value := range boundaryPixels {
+		if value != 0 {
+			samples = append(samples, float64(distances[i]))
+		}
+	}
+
+	sort.Float64s(samples)
+	return samples, nil
+}
+
+// percentile returns the value at rank p of an ascending slice
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
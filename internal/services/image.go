@@ -8,10 +8,13 @@ import (
 	"image/jpeg"
 	"image/png"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"otsu-obliterator/internal/logger"
 	"otsu-obliterator/internal/models"
 	"otsu-obliterator/internal/opencv/conversion"
 	"otsu-obliterator/internal/opencv/memory"
@@ -19,24 +22,47 @@ import (
 
 	"fyne.io/fyne/v2"
 	"gocv.io/x/gocv"
+	_ "golang.org/x/image/webp"
 )
 
 // ImageService handles image loading, saving, and format conversions
 type ImageService struct {
 	memoryManager *memory.Manager
 	repository    *models.ImageRepository
+	webpQuality   atomic.Int32
+	jpegQuality   atomic.Int32
+	mmapLoader    *MemoryMappedImageLoader
+	orientation   *ExifOrientationCorrector
 }
 
 // NewImageService creates a new image service
 func NewImageService(memMgr *memory.Manager, repo *models.ImageRepository) *ImageService {
-	return &ImageService{
+	service := &ImageService{
 		memoryManager: memMgr,
 		repository:    repo,
+		mmapLoader:    NewMemoryMappedImageLoader(nil),
+		orientation:   NewExifOrientationCorrector(),
 	}
+	service.webpQuality.Store(conversion.DefaultWebPQuality)
+	service.jpegQuality.Store(95)
+	return service
 }
 
-// LoadImage loads an image from a URI reader
+// LoadImage loads an image from a URI reader and stores it as the original image
 func (is *ImageService) LoadImage(ctx context.Context, reader fyne.URIReadCloser) (*models.ImageData, error) {
+	imageData, err := is.DecodeImage(ctx, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store in repository
+	is.repository.SetOriginalImage(imageData)
+
+	return imageData, nil
+}
+
+// DecodeImage reads an image from a URI reader without touching the repository
+func (is *ImageService) DecodeImage(ctx context.Context, reader fyne.URIReadCloser) (*models.ImageData, error) {
 	defer reader.Close()
 
 	select {
@@ -47,16 +73,34 @@ func (is *ImageService) LoadImage(ctx context.Context, reader fyne.URIReadCloser
 
 	startTime := time.Now()
 	originalURI := reader.URI()
-	
-	// Read all data into buffer
-	bufferedReader := bufio.NewReader(reader)
-	data, err := io.ReadAll(bufferedReader)
+
+	// Large local files are decoded from a memory mapping instead of a heap copy
+	if !IsDICOM(originalURI) {
+		if imageData, handled, err := is.tryMemoryMappedLoad(ctx, originalURI, startTime); handled {
+			return imageData, err
+		}
+	}
+
+	// Read all data into buffer, reporting progress on slow storage when
+	// the caller asked for it
+	var source io.Reader = bufio.NewReader(reader)
+	if progressFn := loadProgressFromContext(ctx); progressFn != nil {
+		source = NewProgressReader(ctx, source, uriFileSize(originalURI), progressFn)
+	}
+	data, err := readAll(ctx, source)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		return nil, fmt.Errorf("failed to read image data: %w", err)
 	}
 
 	// Determine format from URI extension
 	uriExtension := strings.ToLower(filepath.Ext(originalURI.Path()))
+
+	if uriExtension == ".dcm" {
+		return is.decodeDICOM(data, originalURI)
+	}
 	
 	// Decode with standard library
 	img, standardFormat, err := image.Decode(strings.NewReader(string(data)))
@@ -76,35 +120,60 @@ func (is *ImageService) LoadImage(ctx context.Context, reader fyne.URIReadCloser
 		return nil, fmt.Errorf("failed to convert image to Mat: %w", err)
 	}
 
-	// Determine final format
+	// JPEGs are turned upright before anything else sees them. Memory-mapped
+	// loads need no correction since OpenCV applies the orientation itself.
+	if standardFormat == "jpeg" {
+		if orientation := is.orientation.Orientation(data); orientation != OrientationNormal {
+			img, mat, err = is.reorient(mat, func(src *safe.Mat) (*safe.Mat, error) {
+				return is.orientation.Correct(src, orientation)
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply EXIF orientation: %w", err)
+			}
+		}
+	}
+
 	actualFormat := is.determineFormat(uriExtension, standardFormat)
+	return is.newImageData(img, mat, originalURI, actualFormat, int64(len(data)), ReadExif(data), startTime)
+}
+
+// newImageData finishes a decode for both the buffered and memory-mapped
+// paths, taking ownership of mat. As with DICOM, a 16-bit image keeps its
+// full depth in img for display and saving while the Mat holds a scaled
+// 8-bit copy for the algorithms.
+func (is *ImageService) newImageData(img image.Image, mat *safe.Mat, uri fyne.URI, format string, fileSize int64, exif map[string]string, startTime time.Time) (*models.ImageData, error) {
+	bitDepth := 8
+	if conversion.Is16Bit(mat) {
+		scaled, err := conversion.ScaleTo8Bit(mat)
+		mat.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scale 16-bit image: %w", err)
+		}
+		mat = scaled
+		bitDepth = 16
+	}
+
 	bounds := img.Bounds()
 
-	imageData := &models.ImageData{
+	return &models.ImageData{
 		Image:       img,
 		Mat:         mat,
 		Width:       bounds.Dx(),
 		Height:      bounds.Dy(),
 		Channels:    mat.Channels(),
-		Format:      actualFormat,
-		OriginalURI: originalURI,
+		Format:      format,
+		OriginalURI: uri,
 		LoadTime:    time.Now(),
 		Metadata: models.ImageMetadata{
-			FileSize:    int64(len(data)),
+			FileSize:    fileSize,
 			ColorSpace:  is.determineColorSpace(mat),
-			BitDepth:    8, // Standard for most images
-			Compression: actualFormat,
+			BitDepth:    bitDepth,
+			Compression: format,
 			Software:    "Otsu Obliterator",
+			Exif:        exif,
 		},
-	}
-
-	// Store in repository
-	is.repository.SetOriginalImage(imageData)
-
-	loadDuration := time.Since(startTime)
-	imageData.ProcessTime = loadDuration
-
-	return imageData, nil
+		ProcessTime: time.Since(startTime),
+	}, nil
 }
 
 // SaveImage saves an image to a URI writer
@@ -172,6 +241,73 @@ func (is *ImageService) ConvertImageFormat(ctx context.Context, imageData *model
 	return convertedData, nil
 }
 
+// RotateOriginal rotates the original image clockwise by 90, 180 or 270
+// degrees and stores the result as the new original
+func (is *ImageService) RotateOriginal(degrees int) (*models.ImageData, error) {
+	return is.transformOriginal(func(src *safe.Mat) (*safe.Mat, error) {
+		return is.orientation.Rotate(src, degrees)
+	})
+}
+
+// FlipOriginal mirrors the original image horizontally or vertically and
+// stores the result as the new original
+func (is *ImageService) FlipOriginal(horizontal bool) (*models.ImageData, error) {
+	return is.transformOriginal(func(src *safe.Mat) (*safe.Mat, error) {
+		return is.orientation.Flip(src, horizontal)
+	})
+}
+
+// transformOriginal replaces the original image in the repository with a
+// transformed copy; the processed results no longer match it and are cleared
+func (is *ImageService) transformOriginal(transform func(*safe.Mat) (*safe.Mat, error)) (*models.ImageData, error) {
+	original := is.repository.GetOriginalImage()
+	if original == nil || original.Mat == nil {
+		return nil, fmt.Errorf("no image loaded")
+	}
+
+	mat, err := transform(original.Mat)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := conversion.MatToImage(mat)
+	if err != nil {
+		mat.Close()
+		return nil, fmt.Errorf("failed to convert Mat to image: %w", err)
+	}
+
+	transformed := *original
+	transformed.Image = img
+	transformed.Mat = mat
+	transformed.Width = mat.Cols()
+	transformed.Height = mat.Rows()
+	// The display image is rebuilt from the 8-bit Mat
+	transformed.Metadata.BitDepth = 8
+
+	is.repository.ClearProcessedImages()
+	is.repository.SetOriginalImage(&transformed)
+
+	return &transformed, nil
+}
+
+// reorient applies transform to mat, closing it, and returns the transformed
+// Mat with a matching display image
+func (is *ImageService) reorient(mat *safe.Mat, transform func(*safe.Mat) (*safe.Mat, error)) (image.Image, *safe.Mat, error) {
+	transformed, err := transform(mat)
+	mat.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img, err := conversion.MatToImage(transformed)
+	if err != nil {
+		transformed.Close()
+		return nil, nil, err
+	}
+
+	return img, transformed, nil
+}
+
 // ResizeImage resizes an image to new dimensions
 func (is *ImageService) ResizeImage(ctx context.Context, imageData *models.ImageData, newWidth, newHeight int) (*models.ImageData, error) {
 	select {
@@ -329,15 +465,83 @@ type ImageInfo struct {
 func (is *ImageService) saveToWriter(writer io.Writer, img image.Image, format string) error {
 	switch strings.ToLower(format) {
 	case "jpeg", "jpg":
-		return jpeg.Encode(writer, img, &jpeg.Options{Quality: 95})
+		return jpeg.Encode(writer, img, &jpeg.Options{Quality: int(is.jpegQuality.Load())})
 	case "png":
+		// Writes 16-bit PNG for *image.Gray16, keeping the full depth
 		return png.Encode(writer, img)
+	case "webp":
+		return is.encodeWebP(writer, img)
 	default:
 		// Default to PNG for unknown formats
 		return png.Encode(writer, img)
 	}
 }
 
+// SetLogger enables diagnostic logging for the memory-mapped loader
+func (is *ImageService) SetLogger(log logger.Logger) {
+	is.mmapLoader = NewMemoryMappedImageLoader(log)
+}
+
+// tryMemoryMappedLoad loads file URIs at or above MemoryMapThreshold through
+// the mmap loader. It reports handled as false when the caller should fall
+// back to buffered reads; a cancelled load is handled with the context error.
+func (is *ImageService) tryMemoryMappedLoad(ctx context.Context, uri fyne.URI, startTime time.Time) (imageData *models.ImageData, handled bool, err error) {
+	if uri == nil || uri.Scheme() != "file" {
+		return nil, false, nil
+	}
+
+	info, err := os.Stat(uri.Path())
+	if err != nil || info.Size() < MemoryMapThreshold {
+		return nil, false, nil
+	}
+
+	mapped, err := is.mmapLoader.Load(ctx, uri.Path(), loadProgressFromContext(ctx))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, true, ctxErr
+		}
+		return nil, false, nil
+	}
+
+	img, err := conversion.MatToImage(mapped.Mat)
+	if err != nil {
+		mapped.Mat.Close()
+		return nil, true, fmt.Errorf("failed to convert Mat to image: %w", err)
+	}
+
+	extension := strings.ToLower(filepath.Ext(uri.Path()))
+	format := is.determineFormat(extension, strings.TrimPrefix(extension, "."))
+	imageData, err = is.newImageData(img, mapped.Mat, uri, format, mapped.FileSize, mapped.Exif, startTime)
+	return imageData, true, err
+}
+
+// SetJPEGQuality sets the JPEG encoding quality (1-100) used when saving
+func (is *ImageService) SetJPEGQuality(quality int) {
+	is.jpegQuality.Store(int32(max(1, min(quality, 100))))
+}
+
+// SetWebPQuality sets the WebP encoding quality (0-100) used when saving
+func (is *ImageService) SetWebPQuality(quality int) {
+	is.webpQuality.Store(int32(max(0, min(quality, 100))))
+}
+
+// encodeWebP encodes through OpenCV since the standard library has no WebP encoder
+func (is *ImageService) encodeWebP(writer io.Writer, img image.Image) error {
+	mat, err := conversion.ImageToMat(img)
+	if err != nil {
+		return fmt.Errorf("failed to convert image to Mat: %w", err)
+	}
+	defer mat.Close()
+
+	data, err := conversion.EncodeWebP(mat, int(is.webpQuality.Load()))
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
+
 // determineFormat determines the appropriate format based on extension and detected format
 func (is *ImageService) determineFormat(extension, detectedFormat string) string {
 	switch extension {
@@ -349,6 +553,10 @@ func (is *ImageService) determineFormat(extension, detectedFormat string) string
 		return "bmp"
 	case ".tiff", ".tif":
 		return "tiff"
+	case ".webp":
+		return "webp"
+	case ".dcm":
+		return "dicom"
 	default:
 		if detectedFormat != "" {
 			return detectedFormat
@@ -391,7 +599,7 @@ func (is *ImageService) ValidateImageFormat(format string) bool {
 
 // GetSupportedFormats returns list of supported image formats
 func (is *ImageService) GetSupportedFormats() []string {
-	return []string{"jpeg", "jpg", "png", "bmp", "tiff", "tif"}
+	return []string{"jpeg", "jpg", "png", "bmp", "tiff", "tif", "dcm"}
 }
 
 // Cleanup releases resources
@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package services
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is unavailable on this platform; callers fall back to buffered reads
+func mapFile(file *os.File, size int64) ([]byte, func() error, error) {
+	return nil, nil, errors.New("memory mapping not supported on this platform")
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gocv.io/x/gocv"
+)
+
+// benchmarkImageSide gives a 3 channel BMP of about 100 MB
+const benchmarkImageSide = 5800
+
+// writeBenchmarkImage writes an uncompressed gradient image so the file size
+// is known in advance
+func writeBenchmarkImage(b *testing.B) string {
+	b.Helper()
+
+	mat := gocv.NewMatWithSize(benchmarkImageSide, benchmarkImageSide, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+	for y := 0; y < benchmarkImageSide; y++ {
+		for x := 0; x < benchmarkImageSide; x++ {
+			mat.SetUCharAt(y, x*3, uint8(x))
+			mat.SetUCharAt(y, x*3+1, uint8(y))
+			mat.SetUCharAt(y, x*3+2, uint8(x+y))
+		}
+	}
+
+	path := filepath.Join(b.TempDir(), "large.bmp")
+	if !gocv.IMWrite(path, mat) {
+		b.Fatalf("failed to write %s", path)
+	}
+	return path
+}
+
+// BenchmarkReadAllVsMemoryMap compares decoding a 100 MB image from a heap
+// copy of the file against decoding it from a memory mapping
+func BenchmarkReadAllVsMemoryMap(b *testing.B) {
+	path := writeBenchmarkImage(b)
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(info.Size())
+		for i := 0; i < b.N; i++ {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			mat, err := gocv.IMDecode(data, mmapDecodeFlags)
+			if err != nil {
+				b.Fatal(err)
+			}
+			mat.Close()
+		}
+	})
+
+	b.Run("MemoryMap", func(b *testing.B) {
+		loader := NewMemoryMappedImageLoader(nil)
+		b.ReportAllocs()
+		b.SetBytes(info.Size())
+		for i := 0; i < b.N; i++ {
+			mapped, err := loader.Load(context.Background(), path, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			mapped.Mat.Close()
+		}
+	})
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package services
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps the whole file read-only
+func mapFile(file *os.File, size int64) ([]byte, func() error, error) {
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
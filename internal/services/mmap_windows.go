@@ -0,0 +1,38 @@
+//go:build windows
+
+package services
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// mapFile maps the whole file read-only through a file mapping object
+func mapFile(file *os.File, size int64) ([]byte, func() error, error) {
+	mapping, err := windows.CreateFileMapping(
+		windows.Handle(file.Fd()), nil, windows.PAGE_READONLY,
+		uint32(size>>32), uint32(size), nil,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addr, err := windows.MapViewOfFile(mapping, windows.FILE_MAP_READ, 0, 0, uintptr(size))
+	if err != nil {
+		windows.CloseHandle(mapping)
+		return nil, nil, err
+	}
+
+	data := unsafe.Slice((*byte)(unsafe.Pointer(addr)), int(size))
+	unmap := func() error {
+		err := windows.UnmapViewOfFile(addr)
+		if closeErr := windows.CloseHandle(mapping); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	return data, unmap, nil
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"github.com/rwcarlsen/goexif/exif"
+	"gocv.io/x/gocv"
+)
+
+// OrientationNormal is the EXIF orientation of an image stored upright
+const OrientationNormal = 1
+
+// ExifOrientationCorrector rotates and mirrors images so they display upright.
+// The standard library JPEG decoder ignores the EXIF orientation tag, so
+// camera images shot in portrait would otherwise be segmented on their side.
+type ExifOrientationCorrector struct{}
+
+// NewExifOrientationCorrector creates an orientation corrector
+func NewExifOrientationCorrector() *ExifOrientationCorrector {
+	return &ExifOrientationCorrector{}
+}
+
+// Orientation returns the EXIF orientation (1-8) stored in raw JPEG bytes,
+// OrientationNormal when the tag is missing or unreadable
+func (c *ExifOrientationCorrector) Orientation(data []byte) int {
+	x, err := exif.Decode(bytes.NewReader(data))
+	if err != nil {
+		return OrientationNormal
+	}
+
+	tag, err := x.Get(exif.Orientation)
+	if err != nil {
+		return OrientationNormal
+	}
+
+	orientation, err := tag.Int(0)
+	if err != nil || orientation < 1 || orientation > 8 {
+		return OrientationNormal
+	}
+	return orientation
+}
+
+// Correct returns an upright copy of mat for the given EXIF orientation. The
+// caller owns the result, which is a plain clone for OrientationNormal.
+func (c *ExifOrientationCorrector) Correct(mat *safe.Mat, orientation int) (*safe.Mat, error) {
+	switch orientation {
+	case 2:
+		return c.Flip(mat, true)
+	case 3:
+		return c.Rotate(mat, 180)
+	case 4:
+		return c.Flip(mat, false)
+	case 5:
+		// Transpose: rotate clockwise then mirror
+		return c.rotateThenFlip(mat, 90)
+	case 6:
+		return c.Rotate(mat, 90)
+	case 7:
+		// Transverse: rotate counter-clockwise then mirror
+		return c.rotateThenFlip(mat, 270)
+	case 8:
+		return c.Rotate(mat, 270)
+	default:
+		return conversion.CloneMat(mat)
+	}
+}
+
+// Rotate returns a copy of mat rotated clockwise by 90, 180 or 270 degrees
+func (c *ExifOrientationCorrector) Rotate(mat *safe.Mat, degrees int) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(mat, "rotation"); err != nil {
+		return nil, err
+	}
+
+	var code gocv.RotateFlag
+	rows, cols := mat.Cols(), mat.Rows()
+	switch degrees {
+	case 90:
+		code = gocv.Rotate90Clockwise
+	case 180:
+		code = gocv.Rotate180Clockwise
+		rows, cols = mat.Rows(), mat.Cols()
+	case 270:
+		code = gocv.Rotate90CounterClockwise
+	default:
+		return nil, fmt.Errorf("rotation must be 90, 180 or 270 degrees, got: %d", degrees)
+	}
+
+	dst, err := safe.NewMat(rows, cols, mat.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	srcMat := mat.GetMat()
+	dstMat := dst.GetMat()
+	gocv.Rotate(srcMat, &dstMat, code)
+
+	return dst, nil
+}
+
+// Flip returns a copy of mat mirrored left to right when horizontal is true,
+// top to bottom otherwise
+func (c *ExifOrientationCorrector) Flip(mat *safe.Mat, horizontal bool) (*safe.Mat, error) {
+	if err := safe.ValidateMatForOperation(mat, "flip"); err != nil {
+		return nil, err
+	}
+
+	dst, err := safe.NewMat(mat.Rows(), mat.Cols(), mat.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	// OpenCV flip codes: 1 mirrors around the vertical axis, 0 around the horizontal
+	flipCode := 0
+	if horizontal {
+		flipCode = 1
+	}
+
+	srcMat := mat.GetMat()
+	dstMat := dst.GetMat()
+	gocv.Flip(srcMat, &dstMat, flipCode)
+
+	return dst, nil
+}
+
+func (c *ExifOrientationCorrector) rotateThenFlip(mat *safe.Mat, degrees int) (*safe.Mat, error) {
+	rotated, err := c.Rotate(mat, degrees)
+	if err != nil {
+		return nil, err
+	}
+	defer rotated.Close()
+
+	return c.Flip(rotated, true)
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"strings"
+)
+
+// A4 page size in PDF points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// pdfDocument assembles a minimal PDF 1.4 file with Helvetica text, vector
+// lines and JPEG images. Object 1 is the catalog, 2 the page tree and 3 the font.
+type pdfDocument struct {
+	objects [][]byte
+	pages   []int
+}
+
+// pdfPage collects drawing operators for one page. Coordinates are measured
+// from the top-left corner and converted to PDF space when written.
+type pdfPage struct {
+	content bytes.Buffer
+	images  map[string]int
+}
+
+func newPDFDocument() *pdfDocument {
+	doc := &pdfDocument{}
+	doc.addObject(nil) // catalog, written last
+	doc.addObject(nil) // page tree, written last
+	doc.addObject([]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"))
+	return doc
+}
+
+// addObject stores an object body and returns its object number
+func (d *pdfDocument) addObject(body []byte) int {
+	d.objects = append(d.objects, body)
+	return len(d.objects)
+}
+
+// addStream stores a stream object with the given dictionary entries
+func (d *pdfDocument) addStream(dict string, data []byte) int {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	body.Write(data)
+	body.WriteString("\nendstream")
+	return d.addObject(body.Bytes())
+}
+
+// addImage embeds img as a JPEG XObject and returns its object number
+func (d *pdfDocument) addImage(img image.Image) (int, error) {
+	var data bytes.Buffer
+	if err := jpeg.Encode(&data, img, &jpeg.Options{Quality: 90}); err != nil {
+		return 0, fmt.Errorf("JPEG encoding failed: %w", err)
+	}
+
+	// The JPEG encoder only writes a single component for *image.Gray
+	colorSpace := "/DeviceRGB"
+	if _, ok := img.(*image.Gray); ok {
+		colorSpace = "/DeviceGray"
+	}
+
+	bounds := img.Bounds()
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+		bounds.Dx(), bounds.Dy(), colorSpace)
+	return d.addStream(dict, data.Bytes()), nil
+}
+
+// addPage appends a finished page to the document
+func (d *pdfDocument) addPage(page *pdfPage) {
+	content := d.addStream("", page.content.Bytes())
+
+	var xobjects strings.Builder
+	for name, id := range page.images {
+		fmt.Fprintf(&xobjects, " /%s %d 0 R", name, id)
+	}
+
+	pageObject := fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> /XObject <<%s >> >> >>",
+		pdfPageWidth, pdfPageHeight, content, xobjects.String())
+	d.pages = append(d.pages, d.addObject([]byte(pageObject)))
+}
+
+// writeTo serialises the document with its cross-reference table
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	d.objects[0] = []byte("<< /Type /Catalog /Pages 2 0 R >>")
+
+	var kids strings.Builder
+	for _, id := range d.pages {
+		fmt.Fprintf(&kids, "%d 0 R ", id)
+	}
+	d.objects[1] = []byte(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(d.pages)))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(d.objects))
+	for i, body := range d.objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(body)
+		out.WriteString("\nendobj\n")
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(d.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(d.objects)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+func newPDFPage() *pdfPage {
+	return &pdfPage{images: make(map[string]int)}
+}
+
+// text draws a single line of text with its baseline at y
+func (p *pdfPage) text(x, y, size float64, s string) {
+	fmt.Fprintf(&p.content, "BT /F1 %.1f Tf %.2f %.2f Td (%s) Tj ET\n", size, x, pdfPageHeight-y, escapePDFText(s))
+}
+
+// line draws a straight line segment
+func (p *pdfPage) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, pdfPageHeight-y1, x2, pdfPageHeight-y2)
+}
+
+// polyline draws connected segments through the given points in one colour
+func (p *pdfPage) polyline(xs, ys []float64, r, g, b float64) {
+	if len(xs) < 2 {
+		return
+	}
+	fmt.Fprintf(&p.content, "q %.2f %.2f %.2f RG 1.5 w %.2f %.2f m", r, g, b, xs[0], pdfPageHeight-ys[0])
+	for i := 1; i < len(xs); i++ {
+		fmt.Fprintf(&p.content, " %.2f %.2f l", xs[i], pdfPageHeight-ys[i])
+	}
+	p.content.WriteString(" S Q\n")
+}
+
+// rect outlines a rectangle whose top-left corner is at x, y
+func (p *pdfPage) rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f %.2f %.2f re S\n", x, pdfPageHeight-y-h, w, h)
+}
+
+// image places an embedded image with its top-left corner at x, y
+func (p *pdfPage) image(name string, id int, x, y, w, h float64) {
+	p.images[name] = id
+	fmt.Fprintf(&p.content, "q %.2f 0 0 %.2f %.2f %.2f cm /%s Do Q\n", w, h, x, pdfPageHeight-y-h, name)
+}
+
+// escapePDFText escapes string delimiters and replaces characters outside
+// the Latin-1 range that the standard font encoding cannot show
+func escapePDFText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 255:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
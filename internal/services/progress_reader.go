@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"fyne.io/fyne/v2"
+)
+
+// LoadProgressInterval is how many bytes a ProgressReader reads between
+// progress reports
+const LoadProgressInterval = 1 << 20
+
+// LoadProgressFunc receives the bytes read so far and the file size, which
+// is -1 when unknown
+type LoadProgressFunc func(read, total int64)
+
+type loadProgressKey struct{}
+
+// WithLoadProgress returns a context that makes image loads report their
+// reading progress to progressFn
+func WithLoadProgress(ctx context.Context, progressFn LoadProgressFunc) context.Context {
+	return context.WithValue(ctx, loadProgressKey{}, progressFn)
+}
+
+// loadProgressFromContext returns the progress handler of ctx, or nil
+func loadProgressFromContext(ctx context.Context) LoadProgressFunc {
+	progressFn, _ := ctx.Value(loadProgressKey{}).(LoadProgressFunc)
+	return progressFn
+}
+
+// ProgressReader reports the bytes read from the wrapped reader every
+// LoadProgressInterval bytes and at the end, and fails with the context's
+// error once it is cancelled
+type ProgressReader struct {
+	ctx        context.Context
+	reader     io.Reader
+	total      int64
+	read       int64
+	reported   int64
+	progressFn LoadProgressFunc
+}
+
+// NewProgressReader wraps reader, whose size is total or -1 if unknown
+func NewProgressReader(ctx context.Context, reader io.Reader, total int64, progressFn LoadProgressFunc) *ProgressReader {
+	return &ProgressReader{
+		ctx:        ctx,
+		reader:     reader,
+		total:      total,
+		progressFn: progressFn,
+	}
+}
+
+// Read reads from the wrapped reader unless the context is done
+func (pr *ProgressReader) Read(p []byte) (int, error) {
+	if err := pr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := pr.reader.Read(p)
+	pr.read += int64(n)
+	if pr.read-pr.reported >= LoadProgressInterval || (err == io.EOF && pr.read > pr.reported) {
+		pr.reported = pr.read
+		pr.progressFn(pr.read, pr.total)
+	}
+	return n, err
+}
+
+// readAll reads reader to the end, returning as soon as ctx is cancelled. A
+// read blocked on slow storage is left to fail when the caller closes the
+// underlying file.
+func readAll(ctx context.Context, reader io.Reader) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		data, err := io.ReadAll(reader)
+		done <- result{data, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.data, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// uriFileSize returns the size of a local file, or -1 when it is unknown
+func uriFileSize(uri fyne.URI) int64 {
+	if uri == nil || uri.Scheme() != "file" {
+		return -1
+	}
+	info, err := os.Stat(uri.Path())
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"otsu-obliterator/internal/models"
+)
+
+// ReportGenerator renders processing results as a printable PDF report
+type ReportGenerator struct {
+	algorithm   string
+	convergence []float64
+	now         func() time.Time
+}
+
+// NewReportGenerator creates a report generator for results of the named algorithm
+func NewReportGenerator(algorithm string) *ReportGenerator {
+	return &ReportGenerator{
+		algorithm: algorithm,
+		now:       time.Now,
+	}
+}
+
+// SetConvergenceHistory sets the per-iteration threshold values plotted in the
+// report. Reports without history omit the plot.
+func (rg *ReportGenerator) SetConvergenceHistory(history []float64) {
+	rg.convergence = append([]float64(nil), history...)
+}
+
+// GeneratePDF writes a report with a title page showing the original and
+// processed images, followed by metrics, parameters and, for iterative
+// algorithms, a convergence plot
+func (rg *ReportGenerator) GeneratePDF(
+	original, processed *models.ImageData,
+	metrics *models.SegmentationMetrics,
+	params map[string]interface{},
+	outputWriter io.Writer,
+) error {
+	if original == nil || original.Image == nil || processed == nil || processed.Image == nil {
+		return fmt.Errorf("report requires original and processed images")
+	}
+
+	doc := newPDFDocument()
+
+	titlePage, err := rg.buildTitlePage(doc, original, processed)
+	if err != nil {
+		return err
+	}
+	doc.addPage(titlePage)
+	doc.addPage(rg.buildResultsPage(metrics, params))
+
+	if err := doc.writeTo(outputWriter); err != nil {
+		return fmt.Errorf("failed to write PDF report: %w", err)
+	}
+	return nil
+}
+
+// buildTitlePage lays out the heading and the two images side by side
+func (rg *ReportGenerator) buildTitlePage(doc *pdfDocument, original, processed *models.ImageData) (*pdfPage, error) {
+	page := newPDFPage()
+
+	page.text(pdfMargin, 90, 24, "Otsu Obliterator Segmentation Report")
+	page.text(pdfMargin, 125, 14, fmt.Sprintf("Algorithm: %s", rg.algorithm))
+	page.text(pdfMargin, 145, 11, fmt.Sprintf("Generated: %s", rg.now().Format("2006-01-02 15:04:05")))
+	page.text(pdfMargin, 162, 11, fmt.Sprintf("Image size: %d x %d", original.Width, original.Height))
+
+	originalID, err := doc.addImage(original.Image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed original image: %w", err)
+	}
+	processedID, err := doc.addImage(processed.Image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed processed image: %w", err)
+	}
+
+	const top = 200.0
+	const gap = 15.0
+	boxWidth := (pdfPageWidth - 2*pdfMargin - gap) / 2
+	boxHeight := 400.0
+
+	placeImage := func(name string, id int, x float64, data *models.ImageData, caption string) {
+		w, h := fitWithin(float64(data.Width), float64(data.Height), boxWidth, boxHeight)
+		page.image(name, id, x+(boxWidth-w)/2, top, w, h)
+		page.text(x, top+h+18, 11, caption)
+	}
+	placeImage("Im1", originalID, pdfMargin, original, "Original")
+	placeImage("Im2", processedID, pdfMargin+boxWidth+gap, processed, "Processed")
+
+	return page, nil
+}
+
+// buildResultsPage lays out the metrics and parameter tables and the convergence plot
+func (rg *ReportGenerator) buildResultsPage(metrics *models.SegmentationMetrics, params map[string]interface{}) *pdfPage {
+	page := newPDFPage()
+	y := 80.0
+
+	page.text(pdfMargin, y, 16, "Quality Metrics")
+	y += 12
+	if metrics == nil {
+		metrics = &models.SegmentationMetrics{}
+	}
+	y = drawTable(page, y, [][2]string{
+		{"IoU", fmt.Sprintf("%.4f", metrics.IoU)},
+		{"Dice coefficient", fmt.Sprintf("%.4f", metrics.DiceCoefficient)},
+		{"Misclassification error", fmt.Sprintf("%.4f", metrics.MisclassificationError)},
+		{"Region uniformity", fmt.Sprintf("%.4f", metrics.RegionUniformity)},
+		{"Boundary accuracy", fmt.Sprintf("%.4f", metrics.BoundaryAccuracy)},
+		{"Hausdorff distance", fmt.Sprintf("%.2f px", metrics.HausdorffDistance)},
+		{"Hausdorff distance (95th percentile)", fmt.Sprintf("%.2f px", metrics.HausdorffDistance95)},
+		{"PSNR", fmt.Sprintf("%.2f dB", metrics.PSNR)},
+		{"SSIM", fmt.Sprintf("%.4f", metrics.SSIM)},
+	})
+
+	y += 30
+	page.text(pdfMargin, y, 16, "Parameters")
+	y += 12
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][2]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, [2]string{name, fmt.Sprintf("%v", params[name])})
+	}
+	y = drawTable(page, y, rows)
+
+	if len(rg.convergence) > 1 {
+		y += 30
+		page.text(pdfMargin, y, 16, "Convergence")
+		drawConvergencePlot(page, y+15, rg.convergence)
+	}
+
+	return page
+}
+
+// drawTable draws two column rows starting at y and returns the y below the table
+func drawTable(page *pdfPage, y float64, rows [][2]string) float64 {
+	const rowHeight = 18.0
+	width := pdfPageWidth - 2*pdfMargin
+	split := pdfMargin + width*0.6
+
+	for _, row := range rows {
+		page.rect(pdfMargin, y, width, rowHeight)
+		page.line(split, y, split, y+rowHeight, 0.5)
+		page.text(pdfMargin+6, y+13, 10, row[0])
+		page.text(split+6, y+13, 10, row[1])
+		y += rowHeight
+	}
+	return y
+}
+
+// drawConvergencePlot draws threshold value against iteration as a line chart
+func drawConvergencePlot(page *pdfPage, top float64, values []float64) {
+	const plotHeight = 180.0
+	const axisPadding = 30.0
+	left := pdfMargin + axisPadding
+	width := pdfPageWidth - pdfMargin - left
+	bottom := top + plotHeight
+
+	minValue, maxValue := values[0], values[0]
+	for _, value := range values {
+		minValue = math.Min(minValue, value)
+		maxValue = math.Max(maxValue, value)
+	}
+	span := maxValue - minValue
+	if span == 0 {
+		span = 1
+	}
+
+	page.line(left, top, left, bottom, 0.75)
+	page.line(left, bottom, left+width, bottom, 0.75)
+	page.text(pdfMargin, top+8, 8, fmt.Sprintf("%.1f", maxValue))
+	page.text(pdfMargin, bottom, 8, fmt.Sprintf("%.1f", minValue))
+	page.text(left, bottom+14, 8, "1")
+	page.text(left+width-10, bottom+14, 8, fmt.Sprintf("%d", len(values)))
+	page.text(left+width/2-20, bottom+14, 9, "Iteration")
+
+	xs := make([]float64, len(values))
+	ys := make([]float64, len(values))
+	for i, value := range values {
+		xs[i] = left + width*float64(i)/float64(len(values)-1)
+		ys[i] = bottom - plotHeight*(value-minValue)/span
+	}
+	page.polyline(xs, ys, 0.8, 0.2, 0.2)
+}
+
+// fitWithin scales width and height to fit a box while keeping the aspect ratio
+func fitWithin(width, height, maxWidth, maxHeight float64) (float64, float64) {
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	scale := math.Min(maxWidth/width, maxHeight/height)
+	return width * scale, height * scale
+}
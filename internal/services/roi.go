@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"image"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// cropToROI copies the region of interest out of an image. The returned data
+// owns its Mat and must be closed by the caller.
+func cropToROI(src *models.ImageData, roi image.Rectangle) (*models.ImageData, error) {
+	if err := safe.ValidateMatForOperation(src.Mat, "ROI crop"); err != nil {
+		return nil, err
+	}
+
+	bounds := image.Rect(0, 0, src.Width, src.Height)
+	if !roi.In(bounds) || roi.Empty() {
+		return nil, fmt.Errorf("ROI %v outside image bounds %v", roi, bounds)
+	}
+
+	srcMat := src.Mat.GetMat()
+	region := srcMat.Region(roi)
+	defer region.Close()
+
+	croppedMat, err := safe.NewMatFromMat(region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy ROI: %w", err)
+	}
+
+	cropped := *src
+	cropped.Mat = croppedMat
+	cropped.Image = nil
+	cropped.Width = roi.Dx()
+	cropped.Height = roi.Dy()
+
+	return &cropped, nil
+}
+
+// pasteROI places a processed region into a zero background of the given size
+func pasteROI(result *models.ImageData, roi image.Rectangle, width, height int) (*models.ImageData, error) {
+	if err := safe.ValidateMatForOperation(result.Mat, "ROI paste"); err != nil {
+		return nil, err
+	}
+
+	full := gocv.Zeros(height, width, result.Mat.Type())
+	defer full.Close()
+
+	region := full.Region(roi)
+	resultMat := result.Mat.GetMat()
+	err := resultMat.CopyTo(&region)
+	region.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to paste ROI result: %w", err)
+	}
+
+	fullMat, err := safe.NewMatFromMat(full)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create full size result: %w", err)
+	}
+
+	fullImage, err := conversion.MatToImage(fullMat)
+	if err != nil {
+		fullMat.Close()
+		return nil, fmt.Errorf("Mat to image conversion failed: %w", err)
+	}
+
+	pasted := *result
+	pasted.Mat = fullMat
+	pasted.Image = fullImage
+	pasted.Width = width
+	pasted.Height = height
+
+	return &pasted, nil
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"otsu-obliterator/internal/models"
+
+	"gocv.io/x/gocv"
+)
+
+// MaxSensitivitySteps caps the values tried per parameter so a full study of
+// a typical algorithm stays within about 30 seconds
+const MaxSensitivitySteps = 5
+
+// SensitivityResult is the outcome of varying one parameter. Slope is the
+// least squares slope of IoU against the parameter's position in its range,
+// 0 at Min and 1 at Max, so slopes of parameters with different units can be
+// ranked against each other.
+type SensitivityResult struct {
+	ParameterName string
+	Values        []float64
+	IoUValues     []float64
+	Slope         float64
+}
+
+// SensitivityAnalyser runs a one-at-a-time perturbation study: each numeric
+// parameter is swept over its range with the others held at their configured
+// values, and every result is compared with a reference mask by IoU. The
+// reference is the loaded ground truth, or without one the result of the
+// configured parameters, in which case IoU measures how far a parameter moves
+// the segmentation.
+type SensitivityAnalyser struct {
+	processingService *ProcessingService
+	configRepo        *models.ProcessingConfiguration
+
+	mu         sync.Mutex
+	progressFn func(done, total int)
+}
+
+// NewSensitivityAnalyser creates an analyser that runs trials with processingService
+func NewSensitivityAnalyser(processingService *ProcessingService, configRepo *models.ProcessingConfiguration) *SensitivityAnalyser {
+	return &SensitivityAnalyser{
+		processingService: processingService,
+		configRepo:        configRepo,
+	}
+}
+
+// SetProgressHandler sets a function called after each run with the number
+// of runs completed and the total
+func (sa *SensitivityAnalyser) SetProgressHandler(progressFn func(done, total int)) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	sa.progressFn = progressFn
+}
+
+// Analyse sweeps every numeric parameter of algorithm in steps evenly spaced
+// values, at most MaxSensitivitySteps, and returns the results ordered from
+// the most to the least sensitive parameter. groundTruth may be nil.
+func (sa *SensitivityAnalyser) Analyse(ctx context.Context, algorithm string, image, groundTruth *models.ImageData, steps int) ([]SensitivityResult, error) {
+	if image == nil {
+		return nil, fmt.Errorf("no image to analyse")
+	}
+	steps = max(2, min(steps, MaxSensitivitySteps))
+
+	configured, err := sa.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get algorithm parameters: %w", err)
+	}
+
+	names := numericParameters(configured)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s has no numeric parameters", algorithm)
+	}
+
+	sa.mu.Lock()
+	progressFn := sa.progressFn
+	sa.mu.Unlock()
+
+	total := len(names) * steps
+	done := 0
+
+	reference := groundTruth
+	if reference == nil {
+		total++
+		baseline, err := sa.processingService.ProcessImageData(ctx, image, algorithm, configured.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("baseline run failed: %w", err)
+		}
+		defer sa.processingService.memoryManager.ReleaseMat(baseline.ProcessedImage.Mat, "processing_result")
+		reference = baseline.ProcessedImage
+
+		done++
+		if progressFn != nil {
+			progressFn(done, total)
+		}
+	}
+
+	var results []SensitivityResult
+	for _, name := range names {
+		result := SensitivityResult{ParameterName: name}
+		rng := configured.Ranges[name]
+		low, _ := toFloat(rng.Min)
+		high, _ := toFloat(rng.Max)
+
+		var positions []float64
+		for _, value := range sweepValues(rng, steps) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			iou, err := sa.evaluate(ctx, algorithm, image, reference, withParameter(configured.Parameters, name, value))
+			done++
+			if progressFn != nil {
+				progressFn(done, total)
+			}
+			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				// Values the algorithm rejects are left out of the sweep
+				continue
+			}
+
+			v, _ := toFloat(value)
+			result.Values = append(result.Values, v)
+			result.IoUValues = append(result.IoUValues, iou)
+			positions = append(positions, (v-low)/(high-low))
+		}
+
+		if len(result.Values) < 2 {
+			continue
+		}
+		result.Slope = regressionSlope(positions, result.IoUValues)
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no parameter of %s could be swept", algorithm)
+	}
+
+	slices.SortStableFunc(results, func(a, b SensitivityResult) int {
+		return cmp.Compare(math.Abs(b.Slope), math.Abs(a.Slope))
+	})
+	return results, nil
+}
+
+// evaluate processes image with params and returns the IoU with reference
+func (sa *SensitivityAnalyser) evaluate(ctx context.Context, algorithm string, image, reference *models.ImageData, params map[string]interface{}) (float64, error) {
+	if err := sa.processingService.ValidateAlgorithmParameters(algorithm, params); err != nil {
+		return 0, err
+	}
+
+	result, err := sa.processingService.ProcessImageData(ctx, image, algorithm, params)
+	if err != nil {
+		return 0, err
+	}
+	defer sa.processingService.memoryManager.ReleaseMat(result.ProcessedImage.Mat, "processing_result")
+
+	return maskIoU(result.ProcessedImage, reference)
+}
+
+// numericParameters returns, sorted, the configured parameters with a numeric
+// range; option lists and booleans are skipped
+func numericParameters(params models.AlgorithmParameters) []string {
+	var names []string
+	for name, rng := range params.Ranges {
+		if _, configured := params.Parameters[name]; !configured || len(rng.Options) > 0 {
+			continue
+		}
+		low, okLow := toFloat(rng.Min)
+		high, okHigh := toFloat(rng.Max)
+		if okLow && okHigh && high > low {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// sweepValues returns steps values evenly spaced from rng.Min to rng.Max,
+// snapped to rng.Step and typed like rng.Min. Values that snap together are
+// tried once.
+func sweepValues(rng models.ParameterRange, steps int) []interface{} {
+	low, _ := toFloat(rng.Min)
+	high, _ := toFloat(rng.Max)
+	// A missing or non-positive step leaves the values unsnapped
+	step, _ := toFloat(rng.Step)
+	_, isInt := rng.Min.(int)
+
+	var values []interface{}
+	seen := make(map[float64]bool, steps)
+	for i := 0; i < steps; i++ {
+		v := low + float64(i)*(high-low)/float64(steps-1)
+		if step > 0 {
+			v = min(high, low+math.Round((v-low)/step)*step)
+		}
+		if isInt {
+			v = math.Round(v)
+		}
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+
+		if isInt {
+			values = append(values, int(v))
+		} else {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// regressionSlope returns the least squares slope of y against x
+func regressionSlope(x, y []float64) float64 {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var covariance, variance float64
+	for i := range x {
+		covariance += (x[i] - meanX) * (y[i] - meanY)
+		variance += (x[i] - meanX) * (x[i] - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
+
+// maskIoU returns the intersection over union of two binary masks of the same
+// size, 1 when both are empty
+func maskIoU(a, b *models.ImageData) (float64, error) {
+	if a.Width != b.Width || a.Height != b.Height {
+		return 0, fmt.Errorf("mask sizes differ: %dx%d and %dx%d", a.Width, a.Height, b.Width, b.Height)
+	}
+
+	maskA, err := binaryMask(a.Mat)
+	if err != nil {
+		return 0, err
+	}
+	defer maskA.Close()
+	maskB, err := binaryMask(b.Mat)
+	if err != nil {
+		return 0, err
+	}
+	defer maskB.Close()
+
+	intersection := gocv.NewMat()
+	defer intersection.Close()
+	union := gocv.NewMat()
+	defer union.Close()
+
+	if err := gocv.BitwiseAnd(maskA.GetMat(), maskB.GetMat(), &intersection); err != nil {
+		return 0, fmt.Errorf("intersection failed: %w", err)
+	}
+	if err := gocv.BitwiseOr(maskA.GetMat(), maskB.GetMat(), &union); err != nil {
+		return 0, fmt.Errorf("union failed: %w", err)
+	}
+
+	unionCount := gocv.CountNonZero(union)
+	if unionCount == 0 {
+		return 1, nil
+	}
+	return float64(gocv.CountNonZero(intersection)) / float64(unionCount), nil
+}
+
+// toFloat converts an int or float64 range bound to float64
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"otsu-obliterator/internal/models"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/storage"
+)
+
+// LoadStack reads a numbered TIFF sequence (slice_001.tif, slice_002.tif, ...)
+// from dir as the slices of a volume, ordered by the trailing number in each
+// file name. Only the first page of each file is used and every slice must
+// have the same size.
+func (is *ImageService) LoadStack(dir fyne.URI) ([]*models.ImageData, error) {
+	lister, err := storage.ListerForURI(dir)
+	if err != nil {
+		return nil, fmt.Errorf("input is not a directory: %w", err)
+	}
+
+	entries, err := lister.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+
+	var files []fyne.URI
+	for _, entry := range entries {
+		if IsTIFF(entry) {
+			files = append(files, entry)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no TIFF files in %s", dir.Name())
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		ni, nj := sliceNumber(files[i]), sliceNumber(files[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return files[i].Name() < files[j].Name()
+	})
+
+	slices := make([]*models.ImageData, 0, len(files))
+	closeSlices := func() {
+		for _, slice := range slices {
+			slice.Mat.Close()
+		}
+	}
+
+	for i, file := range files {
+		slice, err := is.loadStackSlice(file)
+		if err != nil {
+			closeSlices()
+			return nil, fmt.Errorf("failed to load %s: %w", file.Name(), err)
+		}
+
+		if len(slices) > 0 && (slice.Width != slices[0].Width || slice.Height != slices[0].Height) {
+			slice.Mat.Close()
+			closeSlices()
+			return nil, fmt.Errorf("%s is %dx%d, expected %dx%d like the first slice",
+				file.Name(), slice.Width, slice.Height, slices[0].Width, slices[0].Height)
+		}
+
+		slice.ID = fmt.Sprintf("%s#%d", dir.Name(), i+1)
+		slices = append(slices, slice)
+	}
+
+	return slices, nil
+}
+
+// loadStackSlice decodes the first page of a TIFF file
+func (is *ImageService) loadStackSlice(uri fyne.URI) (*models.ImageData, error) {
+	reader, err := storage.Reader(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	pages, err := is.LoadTIFFPages(reader)
+	if err != nil {
+		return nil, err
+	}
+	for _, extra := range pages[1:] {
+		extra.Mat.Close()
+	}
+
+	return pages[0], nil
+}
+
+// sliceNumber returns the trailing number of a file's base name, -1 when
+// there is none
+func sliceNumber(uri fyne.URI) int {
+	base := strings.TrimSuffix(uri.Name(), uri.Extension())
+	// Only the last run of digits counts, so "scan2_slice010" sorts by 10
+	digits := base[strings.LastIndexFunc(base, func(r rune) bool { return !unicode.IsDigit(r) })+1:]
+
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return -1
+	}
+	return n
+}
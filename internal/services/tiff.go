@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"fyne.io/fyne/v2"
+	"gocv.io/x/gocv"
+)
+
+// IsTIFF reports whether a URI refers to a TIFF file
+func IsTIFF(uri fyne.URI) bool {
+	if uri == nil {
+		return false
+	}
+	ext := strings.ToLower(uri.Extension())
+	return ext == ".tif" || ext == ".tiff"
+}
+
+// LoadTIFFPages decodes every page of a multi-page TIFF. The standard library
+// decoders only read the first IFD, so pages are read through OpenCV's
+// imreadmulti, which needs a file path; non-file URIs are spooled to a temp file.
+func (is *ImageService) LoadTIFFPages(reader fyne.URIReadCloser) ([]*models.ImageData, error) {
+	defer reader.Close()
+
+	startTime := time.Now()
+	originalURI := reader.URI()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image data: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "otsu-*"+filepath.Ext(originalURI.Path()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	mats := gocv.IMReadMulti(tmpPath, gocv.IMReadColor)
+	if len(mats) == 0 {
+		return nil, fmt.Errorf("failed to decode TIFF pages")
+	}
+
+	pages := make([]*models.ImageData, 0, len(mats))
+	for i, mat := range mats {
+		page, err := is.tiffPageFromMat(mat, originalURI, int64(len(data)))
+		mat.Close()
+		if err != nil {
+			for _, loaded := range pages {
+				loaded.Mat.Close()
+			}
+			for _, remaining := range mats[i+1:] {
+				remaining.Close()
+			}
+			return nil, fmt.Errorf("failed to convert page %d: %w", i+1, err)
+		}
+
+		page.ID = fmt.Sprintf("%s#%d", originalURI.Name(), i+1)
+		page.ProcessTime = time.Since(startTime)
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// tiffPageFromMat wraps a decoded page in ImageData
+func (is *ImageService) tiffPageFromMat(mat gocv.Mat, uri fyne.URI, fileSize int64) (*models.ImageData, error) {
+	safeMat, err := safe.NewMatFromMat(mat)
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := conversion.MatToImage(safeMat)
+	if err != nil {
+		safeMat.Close()
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	return &models.ImageData{
+		Image:       img,
+		Mat:         safeMat,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Channels:    safeMat.Channels(),
+		Format:      "tiff",
+		OriginalURI: uri,
+		LoadTime:    time.Now(),
+		Metadata: models.ImageMetadata{
+			FileSize:    fileSize,
+			ColorSpace:  is.determineColorSpace(safeMat),
+			BitDepth:    8,
+			Compression: "tiff",
+			Software:    "Otsu Obliterator",
+		},
+	}, nil
+}
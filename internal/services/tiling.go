@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"sync"
+	"sync/atomic"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/algorithms/convergence"
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/safe"
+	"otsu-obliterator/internal/pipeline"
+
+	"gocv.io/x/gocv"
+)
+
+// TiledProcessingThreshold is the pixel count above which images are split
+// into tiles and processed in parallel
+const TiledProcessingThreshold = 4000 * 4000
+
+// tileSizeFor returns the tile edge length to use for an image, or false when
+// the image should be processed whole
+func (ps *ProcessingService) tileSizeFor(img *models.ImageData) (int, bool) {
+	if img.Width*img.Height <= TiledProcessingThreshold {
+		return 0, false
+	}
+
+	settings := ps.configRepo.GetPerformanceSettings()
+	if !settings.EnableParallelization || settings.TileSize <= 0 {
+		return 0, false
+	}
+	return settings.TileSize, true
+}
+
+// processTiled runs the algorithm on overlapping tiles concurrently and
+// stitches the tile results into a full size output. Each tile is extended by
+// half the neighbourhood window so local statistics at tile edges see the same
+// pixels as a whole image pass, and the seams between tiles are re-processed
+// afterwards to hide threshold changes from one tile to the next.
+func (ps *ProcessingService) processTiled(
+	ctx context.Context,
+	algorithm algorithms.Algorithm,
+	input *safe.Mat,
+	parameters map[string]interface{},
+	tileSize int,
+) (*safe.Mat, error) {
+	rows, cols := input.Rows(), input.Cols()
+	tiles := splitTiles(cols, rows, tileSize)
+	padding := tilePadding(parameters)
+
+	// Tiles iterate independently, so per-iteration progress is not reported
+	tileCtx := convergence.WithIterationHandler(ctx, nil)
+	tileCtx, cancel := context.WithCancel(tileCtx)
+	defer cancel()
+
+	results := make([]*safe.Mat, len(tiles))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+		done     atomic.Int32
+	)
+	defer func() {
+		for _, result := range results {
+			if result != nil {
+				result.Close()
+			}
+		}
+	}()
+
+	run := func(i int) {
+		result, err := pipeline.ProcessRegion(tileCtx, algorithm, input, tiles[i], padding, parameters)
+		if err != nil {
+			errOnce.Do(func() {
+				firstErr = fmt.Errorf("tile %d failed: %w", i, err)
+				cancel()
+			})
+			return
+		}
+		results[i] = result
+
+		completed := done.Add(1)
+		ps.stateRepo.UpdateProgress(
+			fmt.Sprintf("Processed tile %d of %d", completed, len(tiles)),
+			0.2+0.6*float64(completed)/float64(len(tiles)),
+		)
+	}
+
+	for i := range tiles {
+		if tileCtx.Err() != nil {
+			break
+		}
+
+		// Use a free worker when there is one, otherwise process the tile
+		// here so progress never depends on the caller's own worker slot
+		select {
+		case <-ps.workerPool:
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer func() { ps.workerPool <- struct{}{} }()
+				run(i)
+			}(i)
+		default:
+			run(i)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	output, err := stitchTiles(results, tiles, rows, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.stateRepo.UpdateProgress("Blending tile seams", 0.85)
+	blender := pipeline.NewSeamBlender(ps.seamWidth(parameters), padding)
+	if err := blender.Blend(tileCtx, algorithm, input, output, tiles, parameters); err != nil {
+		output.Close()
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// seamWidth returns the configured seam width, defaulting to the
+// neighbourhood window size
+func (ps *ProcessingService) seamWidth(parameters map[string]interface{}) int {
+	if width := ps.configRepo.GetPerformanceSettings().SeamWidth; width > 0 {
+		return width
+	}
+	if windowSize, ok := parameters["window_size"].(int); ok && windowSize > 0 {
+		return windowSize
+	}
+	return 0
+}
+
+// stitchTiles copies tile results into their place in a full size Mat
+func stitchTiles(results []*safe.Mat, tiles []image.Rectangle, rows, cols int) (*safe.Mat, error) {
+	if len(results) == 0 || results[0] == nil {
+		return nil, fmt.Errorf("no tile results to stitch")
+	}
+
+	output := gocv.Zeros(rows, cols, results[0].Type())
+	defer output.Close()
+
+	for i, tile := range tiles {
+		dst := output.Region(tile)
+		src := results[i].GetMat()
+		err := src.CopyTo(&dst)
+		dst.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stitch tile %d: %w", i, err)
+		}
+	}
+
+	return safe.NewMatFromMat(output)
+}
+
+// splitTiles covers a width by height image with tiles of at most size pixels
+func splitTiles(width, height, size int) []image.Rectangle {
+	var tiles []image.Rectangle
+	for y := 0; y < height; y += size {
+		for x := 0; x < width; x += size {
+			tiles = append(tiles, image.Rect(x, y, min(x+size, width), min(y+size, height)))
+		}
+	}
+	return tiles
+}
+
+// tilePadding returns the overlap needed for the algorithm's neighbourhood
+// window, which multi-scale analysis widens to about twice window_size
+func tilePadding(parameters map[string]interface{}) int {
+	if windowSize, ok := parameters["window_size"].(int); ok && windowSize > 1 {
+		if multiScale, _ := parameters["multi_scale"].(bool); multiScale {
+			return windowSize
+		}
+		return windowSize / 2
+	}
+	return 0
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"otsu-obliterator/internal/algorithms"
+	"otsu-obliterator/internal/logger"
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/memory"
+	"otsu-obliterator/internal/opencv/safe"
+
+	"gocv.io/x/gocv"
+)
+
+// The tile benchmark input is larger than TiledProcessingThreshold
+const (
+	tileBenchmarkWidth  = 8000
+	tileBenchmarkHeight = 6000
+)
+
+// newBenchmarkService creates a processing service with quiet logging
+func newBenchmarkService(b *testing.B) *ProcessingService {
+	b.Helper()
+
+	memMgr := memory.NewManager(logger.NewJSONLogger(logger.ErrorLevel, io.Discard))
+	ps := NewProcessingService(memMgr, models.NewImageRepository(),
+		models.NewProcessingConfiguration(), models.NewProcessingStateRepository())
+	b.Cleanup(func() {
+		ps.Shutdown()
+		memMgr.Shutdown()
+	})
+	return ps
+}
+
+// tileBenchmarkInput returns a gradient with noise, so tiles choose
+// different thresholds
+func tileBenchmarkInput(b *testing.B) *safe.Mat {
+	b.Helper()
+
+	gradient := gocv.NewMatWithSize(tileBenchmarkHeight, tileBenchmarkWidth, gocv.MatTypeCV8UC1)
+	defer gradient.Close()
+	for y := 0; y < tileBenchmarkHeight; y++ {
+		for x := 0; x < tileBenchmarkWidth; x++ {
+			gradient.SetUCharAt(y, x, uint8(x*200/tileBenchmarkWidth))
+		}
+	}
+
+	noise := gocv.NewMatWithSize(tileBenchmarkHeight, tileBenchmarkWidth, gocv.MatTypeCV8UC1)
+	defer noise.Close()
+	gocv.RandU(&noise, gocv.NewScalar(0, 0, 0, 0), gocv.NewScalar(56, 0, 0, 0))
+	if err := gocv.Add(gradient, noise, &gradient); err != nil {
+		b.Fatal(err)
+	}
+
+	input, err := safe.NewMatFromMat(gradient)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { input.Close() })
+	return input
+}
+
+// BenchmarkTileVsFullImage compares processing an 8000x6000 image in one
+// pass against splitting it into concurrently processed tiles with blended
+// seams
+func BenchmarkTileVsFullImage(b *testing.B) {
+	const algorithmName = "2D Otsu"
+
+	ps := newBenchmarkService(b)
+	input := tileBenchmarkInput(b)
+
+	algorithm, err := ps.algorithmManager.GetAlgorithm(algorithmName)
+	if err != nil {
+		b.Fatal(err)
+	}
+	contextualAlg, ok := algorithm.(algorithms.ContextualAlgorithm)
+	if !ok {
+		b.Fatalf("%s does not accept a context", algorithmName)
+	}
+	params, err := ps.configRepo.GetAlgorithmParameters(algorithmName)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tileSize := ps.configRepo.GetPerformanceSettings().TileSize
+	ctx := context.Background()
+
+	b.Run("FullImage", func(b *testing.B) {
+		b.SetBytes(int64(tileBenchmarkWidth * tileBenchmarkHeight))
+		for i := 0; i < b.N; i++ {
+			result, err := contextualAlg.ProcessWithContext(ctx, input, params.Parameters)
+			if err != nil {
+				b.Fatal(err)
+			}
+			result.Close()
+		}
+	})
+
+	b.Run("Tiled", func(b *testing.B) {
+		b.SetBytes(int64(tileBenchmarkWidth * tileBenchmarkHeight))
+		for i := 0; i < b.N; i++ {
+			result, err := ps.processTiled(ctx, algorithm, input, params.Parameters, tileSize)
+			if err != nil {
+				b.Fatal(err)
+			}
+			result.Close()
+		}
+	})
+}
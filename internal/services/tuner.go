@@ -0,0 +1,249 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/processing"
+
+	"gocv.io/x/gocv"
+)
+
+const (
+	// tunerStartupTrials are sampled uniformly before the estimator takes over
+	tunerStartupTrials = 5
+
+	// tunerCandidates are drawn from the good-trial density per guided trial
+	tunerCandidates = 24
+
+	// tunerGoodFraction of trials, by score, form the good-trial density
+	tunerGoodFraction = 0.25
+
+	// tunerBandwidth is the Parzen kernel width in normalised parameter units
+	tunerBandwidth = 0.15
+
+	// tunerRandomStep names the sampling step when drawing generators from
+	// processing.RNG
+	tunerRandomStep = "auto_tune"
+)
+
+// tunableParameter maps a normalised coordinate in [0, 1] onto a parameter value
+type tunableParameter struct {
+	name   string
+	decode func(u float64) interface{}
+}
+
+// tunableParameters are searched when the algorithm defines them
+var tunableParameters = []tunableParameter{
+	{"window_size", func(u float64) interface{} { return 3 + 2*int(math.Round(u*9)) }},
+	{"histogram_bins", func(u float64) interface{} { return 8 + int(math.Round(u*248)) }},
+	{"smoothing_strength", func(u float64) interface{} { return math.Round(u*50) / 10 }},
+}
+
+// tunerTrial is one evaluated point of the search
+type tunerTrial struct {
+	point []float64
+	score float64
+}
+
+// ParameterTuner searches algorithm parameters for a single image with a
+// tree-structured Parzen estimator. Trials are scored by the class balance
+// entropy of the result, which peaks when foreground and background are
+// evenly represented and drops for near-empty or near-full masks.
+type ParameterTuner struct {
+	processingService *ProcessingService
+	configRepo        *models.ProcessingConfiguration
+
+	mu         sync.Mutex
+	progressFn func(done, total int)
+}
+
+// NewParameterTuner creates a tuner that evaluates trials with processingService
+func NewParameterTuner(processingService *ProcessingService, configRepo *models.ProcessingConfiguration) *ParameterTuner {
+	return &ParameterTuner{
+		processingService: processingService,
+		configRepo:        configRepo,
+	}
+}
+
+// SetProgressHandler sets a function called after each trial with the number
+// of trials completed and the total
+func (pt *ParameterTuner) SetProgressHandler(progressFn func(done, total int)) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.progressFn = progressFn
+}
+
+// TuneParameters runs maxTrials trials of algorithm on image and returns the
+// configured parameters with the tunable ones replaced by the best values
+// found. Only window_size, histogram_bins and smoothing_strength are searched,
+// and only those the algorithm defines.
+func (pt *ParameterTuner) TuneParameters(ctx context.Context, algorithm string, image *models.ImageData, maxTrials int) (map[string]interface{}, error) {
+	if image == nil {
+		return nil, fmt.Errorf("no image to tune on")
+	}
+	if maxTrials < 1 {
+		return nil, fmt.Errorf("maxTrials must be at least 1, got: %d", maxTrials)
+	}
+
+	configured, err := pt.configRepo.GetAlgorithmParameters(algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get algorithm parameters: %w", err)
+	}
+
+	var space []tunableParameter
+	for _, param := range tunableParameters {
+		if _, ok := configured.Parameters[param.name]; ok {
+			space = append(space, param)
+		}
+	}
+	if len(space) == 0 {
+		return nil, fmt.Errorf("%s has no tunable parameters", algorithm)
+	}
+
+	pt.mu.Lock()
+	progressFn := pt.progressFn
+	pt.mu.Unlock()
+
+	rng := processing.RNG.ForRun(tunerRandomStep)
+	trials := make([]tunerTrial, 0, maxTrials)
+	var errs []error
+
+	for i := 0; i < maxTrials; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		point := pt.suggest(rng, trials, len(space))
+		params := pt.decode(configured.Parameters, space, point)
+
+		score, err := pt.evaluate(ctx, algorithm, image, params)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			errs = append(errs, fmt.Errorf("trial %d: %w", i+1, err))
+		} else {
+			trials = append(trials, tunerTrial{point: point, score: score})
+		}
+
+		if progressFn != nil {
+			progressFn(i+1, maxTrials)
+		}
+	}
+
+	if len(trials) == 0 {
+		return nil, fmt.Errorf("every trial failed: %w", errors.Join(errs...))
+	}
+
+	best := slices.MaxFunc(trials, func(a, b tunerTrial) int {
+		return cmp.Compare(a.score, b.score)
+	})
+	return pt.decode(configured.Parameters, space, best.point), nil
+}
+
+// suggest returns the next point to evaluate: uniform samples until enough
+// trials exist, then the candidate maximising l(x)/g(x), where l and g are
+// Parzen densities of the good and the remaining trials
+func (pt *ParameterTuner) suggest(rng *rand.Rand, trials []tunerTrial, dims int) []float64 {
+	if len(trials) < tunerStartupTrials {
+		point := make([]float64, dims)
+		for d := range point {
+			point[d] = rng.Float64()
+		}
+		return point
+	}
+
+	sorted := slices.Clone(trials)
+	slices.SortFunc(sorted, func(a, b tunerTrial) int { return cmp.Compare(b.score, a.score) })
+	split := max(1, int(math.Ceil(tunerGoodFraction*float64(len(sorted)))))
+	good, bad := sorted[:split], sorted[split:]
+
+	var best []float64
+	bestRatio := math.Inf(-1)
+	for c := 0; c < tunerCandidates; c++ {
+		// Sample around a random good trial
+		centre := good[rng.IntN(len(good))].point
+		candidate := make([]float64, dims)
+		for d := range candidate {
+			candidate[d] = math.Min(1, math.Max(0, centre[d]+rng.NormFloat64()*tunerBandwidth))
+		}
+
+		ratio := parzenLogDensity(candidate, good) - parzenLogDensity(candidate, bad)
+		if ratio > bestRatio {
+			bestRatio = ratio
+			best = candidate
+		}
+	}
+
+	return best
+}
+
+// parzenLogDensity returns the log of a Gaussian kernel density over trials at
+// point, with a uniform floor so an empty or distant set stays finite
+func parzenLogDensity(point []float64, trials []tunerTrial) float64 {
+	density := 1e-6
+	for _, trial := range trials {
+		sq := 0.0
+		for d, v := range point {
+			diff := (v - trial.point[d]) / tunerBandwidth
+			sq += diff * diff
+		}
+		density += math.Exp(-0.5*sq) / float64(len(trials))
+	}
+	return math.Log(density)
+}
+
+// decode returns a copy of base with the tunable parameters set from point
+func (pt *ParameterTuner) decode(base map[string]interface{}, space []tunableParameter, point []float64) map[string]interface{} {
+	params := make(map[string]interface{}, len(base))
+	for key, value := range base {
+		params[key] = value
+	}
+	for d, param := range space {
+		params[param.name] = param.decode(point[d])
+	}
+	return params
+}
+
+// evaluate processes image with params and returns the class balance entropy
+// of the result
+func (pt *ParameterTuner) evaluate(ctx context.Context, algorithm string, image *models.ImageData, params map[string]interface{}) (float64, error) {
+	if err := pt.processingService.ValidateAlgorithmParameters(algorithm, params); err != nil {
+		return 0, err
+	}
+
+	result, err := pt.processingService.ProcessImageData(ctx, image, algorithm, params)
+	if err != nil {
+		return 0, err
+	}
+	defer pt.processingService.memoryManager.ReleaseMat(result.ProcessedImage.Mat, "processing_result")
+
+	return classBalanceEntropy(result.ProcessedImage)
+}
+
+// classBalanceEntropy returns the binary entropy in bits of the foreground
+// fraction of a mask, 1 for an even split and 0 for an empty or full mask
+func classBalanceEntropy(mask *models.ImageData) (float64, error) {
+	if mask.Mat == nil || mask.Mat.Channels() != 1 {
+		return 0, fmt.Errorf("expected a single channel mask")
+	}
+
+	total := mask.Mat.Rows() * mask.Mat.Cols()
+	if total == 0 {
+		return 0, fmt.Errorf("empty mask")
+	}
+
+	p := float64(gocv.CountNonZero(mask.Mat.GetMat())) / float64(total)
+	if p <= 0 || p >= 1 {
+		return 0, nil
+	}
+	return -p*math.Log2(p) - (1-p)*math.Log2(1-p), nil
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/opencv/conversion"
+
+	"fyne.io/fyne/v2"
+)
+
+// LoadWeightMap reads a weight image and stores it with SetWeightMap
+func (is *ImageService) LoadWeightMap(reader fyne.URIReadCloser) error {
+	imageData, err := is.DecodeImage(context.Background(), reader)
+	if err != nil {
+		return fmt.Errorf("failed to load weight map: %w", err)
+	}
+
+	if err := is.SetWeightMap(imageData); err != nil {
+		imageData.Mat.Close()
+		return err
+	}
+	return nil
+}
+
+// SetWeightMap stores the map weighting each pixel's contribution to the 2D
+// Otsu histogram, converted to grayscale. It must have the dimensions of the
+// original image. The service takes ownership of weights on success; nil
+// clears the map.
+func (is *ImageService) SetWeightMap(weights *models.ImageData) error {
+	if weights == nil {
+		is.repository.SetWeightMap(nil)
+		return nil
+	}
+	if weights.Mat == nil {
+		return fmt.Errorf("weight map has no image data")
+	}
+
+	original := is.repository.GetOriginalImage()
+	if original == nil {
+		return fmt.Errorf("load an image before its weight map")
+	}
+	if weights.Width != original.Width || weights.Height != original.Height {
+		return fmt.Errorf("weight map is %dx%d but the image is %dx%d",
+			weights.Width, weights.Height, original.Width, original.Height)
+	}
+
+	gray, err := conversion.ConvertToGrayscale(weights.Mat)
+	if err != nil {
+		return fmt.Errorf("failed to convert weight map: %w", err)
+	}
+
+	weights.Mat.Close()
+	weights.Mat = gray
+	weights.Channels = 1
+	weights.Metadata.ColorSpace = "grayscale"
+
+	is.repository.SetWeightMap(weights)
+	return nil
+}
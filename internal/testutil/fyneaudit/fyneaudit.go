@@ -0,0 +1,265 @@
+// Package fyneaudit finds nested fyne.Do calls. A closure passed to fyne.Do
+// already runs on the UI goroutine, so calling something inside it that
+// queues another fyne.Do only delays that update behind later ones and, with
+// fyne.DoAndWait, blocks forever.
+//
+// The check is syntactic so it works on packages that cannot be type checked
+// without native dependencies. A function counts as wrapping when its body
+// calls fyne.Do or fyne.DoAndWait, or calls another wrapping function. Calls
+// are resolved through the receiver and its struct fields, which covers the
+// delegation style used by the views. Calls started with a go statement leave
+// the UI goroutine and are not reported.
+package fyneaudit
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Finding is a call that queues fyne.Do from inside a fyne.Do closure
+type Finding struct {
+	Pos    token.Position
+	Callee string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s calls fyne.Do inside a fyne.Do closure", f.Pos, f.Callee)
+}
+
+// funcKey names a function as "Type.Method", or "Func" for plain functions
+type funcKey string
+
+type auditor struct {
+	fset *token.FileSet
+
+	// fields maps a struct type to its field names and their type names
+	fields map[string]map[string]string
+	// bodies holds every function declaration by key
+	bodies map[funcKey]*ast.FuncDecl
+	// wraps records functions that end up calling fyne.Do
+	wraps map[funcKey]bool
+}
+
+// Audit parses the Go files directly inside each directory and reports nested
+// fyne.Do calls. Types are matched by name across all directories.
+func Audit(dirs ...string) ([]Finding, error) {
+	a := &auditor{
+		fset:   token.NewFileSet(),
+		fields: make(map[string]map[string]string),
+		bodies: make(map[funcKey]*ast.FuncDecl),
+		wraps:  make(map[funcKey]bool),
+	}
+
+	var files []*ast.File
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			file, err := parser.ParseFile(a.fset, filepath.Join(dir, name), nil, 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			files = append(files, file)
+		}
+	}
+
+	for _, file := range files {
+		a.collect(file)
+	}
+	a.propagate()
+
+	var findings []Finding
+	for _, decl := range a.bodies {
+		findings = append(findings, a.check(decl)...)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Pos.Filename != findings[j].Pos.Filename {
+			return findings[i].Pos.Filename < findings[j].Pos.Filename
+		}
+		return findings[i].Pos.Line < findings[j].Pos.Line
+	})
+	return findings, nil
+}
+
+// collect records struct fields and function bodies
+func (a *auditor) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				typeSpec, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				fields := make(map[string]string)
+				for _, field := range structType.Fields.List {
+					for _, name := range field.Names {
+						fields[name.Name] = typeName(field.Type)
+					}
+				}
+				a.fields[typeSpec.Name.Name] = fields
+			}
+		case *ast.FuncDecl:
+			if d.Body != nil {
+				a.bodies[declKey(d)] = d
+			}
+		}
+	}
+}
+
+// propagate marks functions that call fyne.Do directly, then callers of
+// those, until nothing changes
+func (a *auditor) propagate() {
+	for key, decl := range a.bodies {
+		if containsDo(decl.Body) {
+			a.wraps[key] = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for key, decl := range a.bodies {
+			if a.wraps[key] {
+				continue
+			}
+			ast.Inspect(decl.Body, func(n ast.Node) bool {
+				if _, isClosure := n.(*ast.FuncLit); isClosure {
+					return false
+				}
+				if call, ok := n.(*ast.CallExpr); ok && a.wraps[a.resolve(decl, call)] {
+					a.wraps[key] = true
+					changed = true
+					return false
+				}
+				return true
+			})
+		}
+	}
+}
+
+// check reports wrapping calls made inside the fyne.Do closures of decl
+func (a *auditor) check(decl *ast.FuncDecl) []Finding {
+	var findings []Finding
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isDoCall(call) || len(call.Args) == 0 {
+			return true
+		}
+
+		ast.Inspect(call.Args[0], func(inner ast.Node) bool {
+			if _, spawned := inner.(*ast.GoStmt); spawned {
+				return false
+			}
+			innerCall, ok := inner.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if isDoCall(innerCall) {
+				findings = append(findings, Finding{Pos: a.fset.Position(innerCall.Pos()), Callee: "fyne.Do"})
+				return false
+			}
+			if key := a.resolve(decl, innerCall); a.wraps[key] {
+				findings = append(findings, Finding{Pos: a.fset.Position(innerCall.Pos()), Callee: string(key)})
+			}
+			return true
+		})
+		return false
+	})
+	return findings
+}
+
+// resolve names the function a call refers to: receiver methods, methods of
+// receiver fields and package functions
+func (a *auditor) resolve(decl *ast.FuncDecl, call *ast.CallExpr) funcKey {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return funcKey(fun.Name)
+	case *ast.SelectorExpr:
+		recvName, recvType := receiver(decl)
+		switch x := fun.X.(type) {
+		case *ast.Ident:
+			if x.Name == recvName {
+				return funcKey(recvType + "." + fun.Sel.Name)
+			}
+		case *ast.SelectorExpr:
+			if base, ok := x.X.(*ast.Ident); ok && base.Name == recvName {
+				if fieldType := a.fields[recvType][x.Sel.Name]; fieldType != "" {
+					return funcKey(fieldType + "." + fun.Sel.Name)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// containsDo reports whether body calls fyne.Do outside nested closures
+// that are not themselves passed to fyne.Do
+func containsDo(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isDoCall(call) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isDoCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "fyne" && (sel.Sel.Name == "Do" || sel.Sel.Name == "DoAndWait")
+}
+
+func declKey(decl *ast.FuncDecl) funcKey {
+	if _, recvType := receiver(decl); recvType != "" {
+		return funcKey(recvType + "." + decl.Name.Name)
+	}
+	return funcKey(decl.Name.Name)
+}
+
+// receiver returns the receiver variable and type names of a method
+func receiver(decl *ast.FuncDecl) (string, string) {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return "", ""
+	}
+	field := decl.Recv.List[0]
+	name := ""
+	if len(field.Names) > 0 {
+		name = field.Names[0].Name
+	}
+	return name, typeName(field.Type)
+}
+
+// typeName strips pointers and package qualifiers from a type expression
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
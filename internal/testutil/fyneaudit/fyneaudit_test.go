@@ -0,0 +1,156 @@
+package fyneaudit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// uiDirs are the packages that update the UI, relative to this package. They
+// are audited together because the controllers call into the views and the
+// views into their components.
+var uiDirs = []string{"../../views", "../../views/components", "../../controllers"}
+
+func TestUIPackagesHaveNoNestedDo(t *testing.T) {
+	findings, err := Audit(uiDirs...)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	for _, finding := range findings {
+		t.Error(finding)
+	}
+}
+
+func TestAuditFindsNestedDo(t *testing.T) {
+	dir := writeSource(t, `package views
+
+import "fyne.io/fyne/v2"
+
+type Label struct{}
+
+func (l *Label) SetText(text string) {
+	fyne.Do(func() {})
+}
+
+type View struct {
+	label *Label
+}
+
+func (v *View) update() {
+	fyne.Do(func() {})
+}
+
+func (v *View) Refresh() {
+	fyne.Do(func() {
+		v.label.SetText("direct field call")
+		v.update()
+		fyne.Do(func() {})
+	})
+}
+`)
+
+	findings, err := Audit(dir)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+
+	var callees []string
+	for _, finding := range findings {
+		callees = append(callees, finding.Callee)
+	}
+	want := []string{"Label.SetText", "View.update", "fyne.Do"}
+	if strings.Join(callees, ",") != strings.Join(want, ",") {
+		t.Errorf("findings = %v, want %v", callees, want)
+	}
+}
+
+func TestAuditFindsNestedDoAcrossPackages(t *testing.T) {
+	views := writeSource(t, `package views
+
+import "fyne.io/fyne/v2"
+
+type MainView struct{}
+
+func (v *MainView) UpdateStatus(status string) {
+	fyne.Do(func() {})
+}
+`)
+	controllers := writeSource(t, `package controllers
+
+import (
+	"fyne.io/fyne/v2"
+
+	"example/views"
+)
+
+type MainController struct {
+	mainView *views.MainView
+}
+
+func (mc *MainController) finish() {
+	fyne.Do(func() {
+		mc.mainView.UpdateStatus("done")
+	})
+}
+`)
+
+	findings, err := Audit(controllers)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("controllers alone: findings = %v, want none", findings)
+	}
+
+	findings, err = Audit(views, controllers)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Callee != "MainView.UpdateStatus" {
+		t.Errorf("findings = %v, want one MainView.UpdateStatus", findings)
+	}
+}
+
+func TestAuditIgnoresSafeCalls(t *testing.T) {
+	dir := writeSource(t, `package views
+
+import "fyne.io/fyne/v2"
+
+type View struct{}
+
+func (v *View) queue() {
+	fyne.Do(func() {})
+}
+
+func (v *View) direct() {}
+
+func (v *View) Refresh() {
+	v.queue()
+	fyne.Do(func() {
+		v.direct()
+		// A goroutine started here leaves the UI goroutine
+		go v.queue()
+	})
+}
+`)
+
+	findings, err := Audit(dir)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	for _, finding := range findings {
+		t.Errorf("unexpected finding: %s", finding)
+	}
+}
+
+// writeSource writes src as the only Go file of a new directory
+func writeSource(t *testing.T, src string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "view.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("failed to write source: %v", err)
+	}
+	return dir
+}
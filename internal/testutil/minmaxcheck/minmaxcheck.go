@@ -0,0 +1,107 @@
+// Package minmaxcheck finds package-level declarations that shadow the min
+// and max built-ins of Go 1.21. A local helper with the same name silently
+// replaces the generic built-in for the whole package, so callers lose float
+// and multi-argument support and the helper drifts from the built-in's
+// semantics.
+//
+// Like fyneaudit the check is syntactic, so it runs on packages that cannot be
+// type checked without native dependencies.
+package minmaxcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// builtins are the names that must not be redeclared at package level
+var builtins = map[string]bool{"min": true, "max": true}
+
+// Finding is a package-level declaration of a built-in name
+type Finding struct {
+	Pos  token.Position
+	Name string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: package-level %s redeclares the built-in", f.Pos, f.Name)
+}
+
+// Check parses every Go file under root, skipping testdata and hidden
+// directories, and reports package-level functions, variables, constants and
+// types named min or max. Methods are not reported since they do not shadow.
+func Check(root string) ([]Finding, error) {
+	fset := token.NewFileSet()
+	var findings []Finding
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			if path != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		findings = append(findings, checkFile(fset, file)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].Pos.Filename != findings[j].Pos.Filename {
+			return findings[i].Pos.Filename < findings[j].Pos.Filename
+		}
+		return findings[i].Pos.Line < findings[j].Pos.Line
+	})
+	return findings, nil
+}
+
+// checkFile reports the top-level declarations of file named like a built-in
+func checkFile(fset *token.FileSet, file *ast.File) []Finding {
+	var findings []Finding
+	report := func(ident *ast.Ident) {
+		if builtins[ident.Name] {
+			findings = append(findings, Finding{Pos: fset.Position(ident.Pos()), Name: ident.Name})
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				report(d.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						report(name)
+					}
+				case *ast.TypeSpec:
+					report(s.Name)
+				}
+			}
+		}
+	}
+	return findings
+}
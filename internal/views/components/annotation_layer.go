@@ -0,0 +1,288 @@
+package components
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// AnnotationTool selects what the primary mouse button draws on an
+// annotation layer
+type AnnotationTool int
+
+const (
+	// AnnotationToolNone leaves the primary button to region of interest selection
+	AnnotationToolNone AnnotationTool = iota
+	AnnotationToolFreehand
+	AnnotationToolRectangle
+	AnnotationToolCircle
+	AnnotationToolText
+)
+
+// annotationToolNames are the tool selector entries, indexed by tool
+var annotationToolNames = []string{"Select ROI", "Freehand", "Rectangle", "Circle", "Text"}
+
+// annotationColors are the colours offered for new annotations
+var annotationColors = map[string]color.NRGBA{
+	"Red":     {R: 230, G: 40, B: 40, A: 255},
+	"Yellow":  {R: 240, G: 200, B: 0, A: 255},
+	"Green":   {R: 40, G: 190, B: 60, A: 255},
+	"Cyan":    {R: 0, G: 190, B: 220, A: 255},
+	"Magenta": {R: 210, G: 50, B: 200, A: 255},
+}
+
+// annotationColorNames orders annotationColors for the colour selector
+var annotationColorNames = []string{"Red", "Yellow", "Green", "Cyan", "Magenta"}
+
+const (
+	annotationStrokeWidth = 2
+	annotationTextSize    = 13
+)
+
+// Annotation is a labelled shape on the annotation layer. Points are in image
+// pixel coordinates: the corners of a rectangle, the centre and a rim point of
+// a circle, the path of a freehand stroke or the position of a text label.
+type Annotation struct {
+	Tool   AnnotationTool
+	Points []image.Point
+	Label  string
+	Color  color.NRGBA
+}
+
+// AnnotationLayer draws annotations over a zoomable image pane and lets the
+// user add new ones with the selected tool
+type AnnotationLayer struct {
+	objects     *fyne.Container
+	annotations []Annotation
+	current     *Annotation
+
+	tool  AnnotationTool
+	label string
+	color color.NRGBA
+
+	// Mapping from image pixels to surface positions, set by the pane
+	origin fyne.Position
+	scale  float32
+
+	addedHandler func(Annotation)
+}
+
+// NewAnnotationLayer creates an empty layer with annotation drawing disabled
+func NewAnnotationLayer() *AnnotationLayer {
+	return &AnnotationLayer{
+		objects: container.NewWithoutLayout(),
+		color:   annotationColors[annotationColorNames[0]],
+		scale:   1,
+	}
+}
+
+// SetTool selects what the primary mouse button draws
+func (al *AnnotationLayer) SetTool(tool AnnotationTool) {
+	al.tool = tool
+	al.current = nil
+	al.redraw()
+}
+
+// Tool returns the selected drawing tool
+func (al *AnnotationLayer) Tool() AnnotationTool {
+	return al.tool
+}
+
+// SetLabel sets the label given to new annotations
+func (al *AnnotationLayer) SetLabel(label string) {
+	al.label = label
+}
+
+// SetColor sets the colour of new annotations
+func (al *AnnotationLayer) SetColor(c color.NRGBA) {
+	al.color = c
+}
+
+// SetAnnotations replaces the displayed annotations
+func (al *AnnotationLayer) SetAnnotations(annotations []Annotation) {
+	al.annotations = append([]Annotation(nil), annotations...)
+	al.redraw()
+}
+
+// SetAnnotationAddedHandler sets the handler called when the user completes
+// an annotation
+func (al *AnnotationLayer) SetAnnotationAddedHandler(handler func(Annotation)) {
+	al.addedHandler = handler
+}
+
+// setTransform updates the image to surface mapping after zoom or resize
+func (al *AnnotationLayer) setTransform(origin fyne.Position, scale float32) {
+	al.origin = origin
+	al.scale = scale
+	al.redraw()
+}
+
+// drawing reports whether a drag is adding an annotation
+func (al *AnnotationLayer) drawing() bool {
+	return al.current != nil
+}
+
+// begin starts an annotation at point. Text labels are complete immediately.
+func (al *AnnotationLayer) begin(point image.Point) {
+	if al.tool == AnnotationToolNone {
+		return
+	}
+
+	al.current = &Annotation{
+		Tool:   al.tool,
+		Points: []image.Point{point},
+		Label:  al.label,
+		Color:  al.color,
+	}
+	if al.tool == AnnotationToolText {
+		al.finish()
+		return
+	}
+	al.redraw()
+}
+
+// extend follows the pointer: freehand strokes gain a point, other shapes
+// move their second point
+func (al *AnnotationLayer) extend(point image.Point) {
+	if al.current == nil {
+		return
+	}
+
+	points := al.current.Points
+	switch {
+	case al.current.Tool == AnnotationToolFreehand:
+		if points[len(points)-1] != point {
+			al.current.Points = append(points, point)
+		}
+	case len(points) == 1:
+		al.current.Points = append(points, point)
+	default:
+		points[1] = point
+	}
+	al.redraw()
+}
+
+// finish completes the annotation being drawn, discarding degenerate shapes
+func (al *AnnotationLayer) finish() {
+	annotation := al.current
+	al.current = nil
+	if annotation == nil || !annotationComplete(*annotation) {
+		al.redraw()
+		return
+	}
+
+	al.annotations = append(al.annotations, *annotation)
+	al.redraw()
+	if al.addedHandler != nil {
+		al.addedHandler(*annotation)
+	}
+}
+
+// annotationComplete reports whether an annotation has a visible shape
+func annotationComplete(annotation Annotation) bool {
+	points := annotation.Points
+	switch annotation.Tool {
+	case AnnotationToolText:
+		return len(points) == 1 && annotation.Label != ""
+	case AnnotationToolFreehand:
+		return len(points) >= 2
+	case AnnotationToolRectangle:
+		return len(points) == 2 && points[0].X != points[1].X && points[0].Y != points[1].Y
+	case AnnotationToolCircle:
+		return len(points) == 2 && points[0] != points[1]
+	default:
+		return false
+	}
+}
+
+// redraw rebuilds the canvas objects for every annotation
+func (al *AnnotationLayer) redraw() {
+	var objects []fyne.CanvasObject
+	for _, annotation := range al.annotations {
+		objects = append(objects, al.annotationObjects(annotation)...)
+	}
+	if al.current != nil {
+		objects = append(objects, al.annotationObjects(*al.current)...)
+	}
+
+	al.objects.Objects = objects
+	al.objects.Refresh()
+}
+
+// annotationObjects returns the lines, circle and text drawing annotation
+func (al *AnnotationLayer) annotationObjects(annotation Annotation) []fyne.CanvasObject {
+	points := annotation.Points
+	if len(points) == 0 {
+		return nil
+	}
+
+	var objects []fyne.CanvasObject
+	switch annotation.Tool {
+	case AnnotationToolFreehand:
+		for i := 1; i < len(points); i++ {
+			objects = append(objects, al.line(points[i-1], points[i], annotation.Color))
+		}
+	case AnnotationToolRectangle:
+		if len(points) < 2 {
+			break
+		}
+		r := image.Rectangle{Min: points[0], Max: points[1]}.Canon()
+		corners := []image.Point{r.Min, {X: r.Max.X, Y: r.Min.Y}, r.Max, {X: r.Min.X, Y: r.Max.Y}}
+		for i := range corners {
+			objects = append(objects, al.line(corners[i], corners[(i+1)%len(corners)], annotation.Color))
+		}
+	case AnnotationToolCircle:
+		if len(points) < 2 {
+			break
+		}
+		objects = append(objects, al.circle(points[0], points[1], annotation.Color))
+	case AnnotationToolText:
+		return []fyne.CanvasObject{al.text(annotation.Label, points[0], annotation.Color)}
+	}
+
+	if annotation.Label != "" {
+		label := al.text(annotation.Label, points[0], annotation.Color)
+		label.Move(label.Position().SubtractXY(0, label.MinSize().Height))
+		objects = append(objects, label)
+	}
+	return objects
+}
+
+// surfacePosition converts image pixel coordinates to a surface position
+func (al *AnnotationLayer) surfacePosition(p image.Point) fyne.Position {
+	return al.origin.AddXY(float32(p.X)*al.scale, float32(p.Y)*al.scale)
+}
+
+func (al *AnnotationLayer) line(from, to image.Point, c color.NRGBA) *canvas.Line {
+	line := canvas.NewLine(c)
+	line.StrokeWidth = annotationStrokeWidth
+	line.Position1 = al.surfacePosition(from)
+	line.Position2 = al.surfacePosition(to)
+	return line
+}
+
+func (al *AnnotationLayer) circle(centre, rim image.Point, c color.NRGBA) *canvas.Circle {
+	d := rim.Sub(centre)
+	radius := float32(math.Hypot(float64(d.X), float64(d.Y))) * al.scale
+	position := al.surfacePosition(centre)
+
+	circle := canvas.NewCircle(color.Transparent)
+	circle.StrokeColor = c
+	circle.StrokeWidth = annotationStrokeWidth
+	circle.Position1 = position.SubtractXY(radius, radius)
+	circle.Position2 = position.AddXY(radius, radius)
+	return circle
+}
+
+func (al *AnnotationLayer) text(label string, at image.Point, c color.NRGBA) *canvas.Text {
+	text := canvas.NewText(label, c)
+	text.TextSize = annotationTextSize
+	text.TextStyle = fyne.TextStyle{Bold: true}
+	text.Move(al.surfacePosition(at))
+	text.Resize(text.MinSize())
+	return text
+}
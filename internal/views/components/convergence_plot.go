@@ -0,0 +1,170 @@
+package components
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"otsu-obliterator/internal/algorithms/convergence"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	ConvergencePlotWidth  = 240
+	ConvergencePlotHeight = 120
+
+	convergencePlotMargin = 6
+)
+
+var (
+	convergenceBackground    = color.RGBA{R: 248, G: 248, B: 248, A: 255}
+	convergenceAxisColor     = color.RGBA{R: 160, G: 160, B: 160, A: 255}
+	convergenceThresholdLine = color.RGBA{R: 38, G: 139, B: 210, A: 255}
+	convergenceTBDLine       = color.RGBA{R: 203, G: 75, B: 22, A: 255}
+)
+
+// ConvergencePlot draws the threshold of each iteration of an iterative
+// algorithm on a 0-255 scale, with the TBD fraction on a secondary 0-1 scale
+type ConvergencePlot struct {
+	widget.BaseWidget
+
+	raster *canvas.Raster
+	legend *fyne.Container
+
+	mu      sync.RWMutex
+	records []convergence.ConvergenceRecord
+}
+
+// NewConvergencePlot creates an empty convergence plot
+func NewConvergencePlot() *ConvergencePlot {
+	plot := &ConvergencePlot{}
+	plot.raster = canvas.NewRaster(plot.draw)
+	plot.raster.SetMinSize(fyne.NewSize(ConvergencePlotWidth, ConvergencePlotHeight))
+
+	thresholdText := canvas.NewText("Threshold", convergenceThresholdLine)
+	thresholdText.TextSize = 11
+	tbdText := canvas.NewText("TBD fraction", convergenceTBDLine)
+	tbdText.TextSize = 11
+	plot.legend = container.NewHBox(thresholdText, tbdText)
+
+	plot.ExtendBaseWidget(plot)
+	return plot
+}
+
+// CreateRenderer lays out the plot above its legend
+func (cp *ConvergencePlot) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewBorder(nil, cp.legend, nil, nil, cp.raster))
+}
+
+// SetData replaces the plotted iterations. Nil or empty data clears the plot.
+func (cp *ConvergencePlot) SetData(records []convergence.ConvergenceRecord) {
+	cp.mu.Lock()
+	cp.records = append([]convergence.ConvergenceRecord(nil), records...)
+	cp.mu.Unlock()
+
+	fyne.Do(func() {
+		cp.raster.Refresh()
+	})
+}
+
+// setData is SetData for callers already running on the UI goroutine
+func (cp *ConvergencePlot) setData(records []convergence.ConvergenceRecord) {
+	cp.mu.Lock()
+	cp.records = append([]convergence.ConvergenceRecord(nil), records...)
+	cp.mu.Unlock()
+
+	cp.raster.Refresh()
+}
+
+// draw renders the axes and both series at the raster size
+func (cp *ConvergencePlot) draw(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 {
+		return img
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, convergenceBackground)
+		}
+	}
+
+	left, right := convergencePlotMargin, w-convergencePlotMargin-1
+	top, bottom := convergencePlotMargin, h-convergencePlotMargin-1
+	if right <= left || bottom <= top {
+		return img
+	}
+
+	for x := left; x <= right; x++ {
+		img.SetRGBA(x, bottom, convergenceAxisColor)
+	}
+	for y := top; y <= bottom; y++ {
+		img.SetRGBA(left, y, convergenceAxisColor)
+	}
+
+	cp.mu.RLock()
+	defer cp.mu.RUnlock()
+
+	if len(cp.records) == 0 {
+		return img
+	}
+
+	// A single iteration is drawn at the centre of the x-axis
+	xFor := func(i int) int {
+		if len(cp.records) == 1 {
+			return (left + right) / 2
+		}
+		return left + i*(right-left)/(len(cp.records)-1)
+	}
+	yFor := func(fraction float64) int {
+		fraction = math.Max(0, math.Min(1, fraction))
+		return bottom - int(fraction*float64(bottom-top))
+	}
+
+	thresholdPoints := make([]image.Point, len(cp.records))
+	tbdPoints := make([]image.Point, len(cp.records))
+	for i, record := range cp.records {
+		thresholdPoints[i] = image.Pt(xFor(i), yFor(record.Threshold/255.0))
+		tbdPoints[i] = image.Pt(xFor(i), yFor(record.TBDFraction))
+	}
+
+	drawPolyline(img, tbdPoints, convergenceTBDLine)
+	drawPolyline(img, thresholdPoints, convergenceThresholdLine)
+
+	return img
+}
+
+// drawPolyline joins the points with straight segments and marks each point
+func drawPolyline(img *image.RGBA, points []image.Point, c color.RGBA) {
+	for i, point := range points {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				img.SetRGBA(point.X+dx, point.Y+dy, c)
+			}
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := points[i-1]
+		steps := max(abs(point.X-prev.X), abs(point.Y-prev.Y))
+		for s := 0; s <= steps; s++ {
+			t := float64(s) / float64(max(steps, 1))
+			x := prev.X + int(math.Round(t*float64(point.X-prev.X)))
+			y := prev.Y + int(math.Round(t*float64(point.Y-prev.Y)))
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,201 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	HistogramBins   = 256
+	HistogramHeight = 80
+)
+
+var (
+	histogramBackground = color.RGBA{R: 248, G: 248, B: 248, A: 255}
+	histogramBarColor   = color.RGBA{R: 90, G: 110, B: 140, A: 255}
+	histogramLineColor  = color.RGBA{R: 220, G: 50, B: 47, A: 255}
+)
+
+// HistogramOverlay draws the grayscale histogram of an image with threshold markers
+type HistogramOverlay struct {
+	widget.BaseWidget
+
+	raster *canvas.Raster
+
+	// State
+	mu         sync.RWMutex
+	bins       [HistogramBins]int
+	maxCount   int
+	thresholds []uint8
+	generation uint64
+	popUp      *widget.PopUp
+}
+
+// NewHistogramOverlay creates an empty histogram overlay
+func NewHistogramOverlay() *HistogramOverlay {
+	overlay := &HistogramOverlay{}
+	overlay.raster = canvas.NewRaster(overlay.draw)
+	overlay.raster.SetMinSize(fyne.NewSize(HistogramBins, HistogramHeight))
+	overlay.ExtendBaseWidget(overlay)
+	return overlay
+}
+
+// CreateRenderer links the overlay to its raster
+func (ho *HistogramOverlay) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(ho.raster)
+}
+
+// SetImage computes the histogram of img in the background and repaints when done.
+// A nil image clears the histogram.
+func (ho *HistogramOverlay) SetImage(img image.Image) {
+	ho.mu.Lock()
+	ho.generation++
+	generation := ho.generation
+	ho.mu.Unlock()
+
+	if img == nil {
+		ho.setBins(generation, [HistogramBins]int{})
+		return
+	}
+
+	go func() {
+		ho.setBins(generation, computeGrayHistogram(img))
+	}()
+}
+
+// SetThresholds sets the threshold values marked with vertical lines
+func (ho *HistogramOverlay) SetThresholds(thresholds []uint8) {
+	ho.mu.Lock()
+	ho.thresholds = append([]uint8(nil), thresholds...)
+	ho.mu.Unlock()
+
+	fyne.Do(func() {
+		ho.raster.Refresh()
+	})
+}
+
+// BinCount returns the pixel count of a histogram bin
+func (ho *HistogramOverlay) BinCount(bin int) int {
+	if bin < 0 || bin >= HistogramBins {
+		return 0
+	}
+
+	ho.mu.RLock()
+	defer ho.mu.RUnlock()
+	return ho.bins[bin]
+}
+
+// Tapped shows the count of the bin under the pointer
+func (ho *HistogramOverlay) Tapped(ev *fyne.PointEvent) {
+	width := ho.Size().Width
+	if width <= 0 {
+		return
+	}
+
+	bin := int(ev.Position.X / width * HistogramBins)
+	if bin >= HistogramBins {
+		bin = HistogramBins - 1
+	}
+
+	c := fyne.CurrentApp().Driver().CanvasForObject(ho)
+	if c == nil {
+		return
+	}
+
+	if ho.popUp != nil {
+		ho.popUp.Hide()
+	}
+	label := widget.NewLabel(fmt.Sprintf("Bin %d: %d px", bin, ho.BinCount(bin)))
+	ho.popUp = widget.NewPopUp(label, c)
+	ho.popUp.ShowAtPosition(ev.AbsolutePosition.Add(fyne.NewPos(8, -label.MinSize().Height)))
+}
+
+// setBins stores a computed histogram unless a newer image has been set since
+func (ho *HistogramOverlay) setBins(generation uint64, bins [HistogramBins]int) {
+	maxCount := 0
+	for _, count := range bins {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+
+	ho.mu.Lock()
+	if generation != ho.generation {
+		ho.mu.Unlock()
+		return
+	}
+	ho.bins = bins
+	ho.maxCount = maxCount
+	ho.mu.Unlock()
+
+	fyne.Do(func() {
+		ho.raster.Refresh()
+	})
+}
+
+// draw renders the histogram bars and threshold lines at the raster size
+func (ho *HistogramOverlay) draw(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 {
+		return img
+	}
+
+	ho.mu.RLock()
+	defer ho.mu.RUnlock()
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, histogramBackground)
+		}
+	}
+
+	if ho.maxCount > 0 {
+		for x := 0; x < w; x++ {
+			bin := x * HistogramBins / w
+			barHeight := int(float64(ho.bins[bin]) / float64(ho.maxCount) * float64(h))
+			for y := h - barHeight; y < h; y++ {
+				img.SetRGBA(x, y, histogramBarColor)
+			}
+		}
+	}
+
+	for _, threshold := range ho.thresholds {
+		x := int(threshold) * w / HistogramBins
+		for y := 0; y < h; y++ {
+			img.SetRGBA(x, y, histogramLineColor)
+		}
+	}
+
+	return img
+}
+
+// computeGrayHistogram builds a 256-bin luminance histogram
+func computeGrayHistogram(img image.Image) [HistogramBins]int {
+	var bins [HistogramBins]int
+	bounds := img.Bounds()
+
+	switch src := img.(type) {
+	case *image.Gray:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			row := src.Pix[(y-bounds.Min.Y)*src.Stride:]
+			for x := 0; x < bounds.Dx(); x++ {
+				bins[row[x]]++
+			}
+		}
+	default:
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				gray := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+				bins[gray.Y]++
+			}
+		}
+	}
+
+	return bins
+}
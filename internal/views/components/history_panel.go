@@ -0,0 +1,64 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// HistoryPanel lists previous processing runs in a collapsible section
+type HistoryPanel struct {
+	container     *fyne.Container
+	list          *widget.List
+	entries       []string
+	selectHandler func(int)
+}
+
+// NewHistoryPanel creates an empty history panel
+func NewHistoryPanel() *HistoryPanel {
+	hp := &HistoryPanel{}
+
+	hp.list = widget.NewList(
+		func() int {
+			return len(hp.entries)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("")
+		},
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			item.(*widget.Label).SetText(hp.entries[id])
+		},
+	)
+	hp.list.OnSelected = func(id widget.ListItemID) {
+		if hp.selectHandler != nil {
+			hp.selectHandler(id)
+		}
+		hp.list.UnselectAll()
+	}
+
+	scroll := container.NewVScroll(hp.list)
+	scroll.SetMinSize(fyne.NewSize(0, 120))
+
+	hp.container = container.NewVBox(
+		widget.NewAccordion(widget.NewAccordionItem("History", scroll)),
+	)
+	return hp
+}
+
+// SetEntries replaces the listed runs, one label per run
+func (hp *HistoryPanel) SetEntries(entries []string) {
+	fyne.Do(func() {
+		hp.entries = append([]string(nil), entries...)
+		hp.list.Refresh()
+	})
+}
+
+// SetSelectHandler sets the callback invoked with the index of a clicked run
+func (hp *HistoryPanel) SetSelectHandler(handler func(int)) {
+	hp.selectHandler = handler
+}
+
+// GetContainer returns the history panel container
+func (hp *HistoryPanel) GetContainer() *fyne.Container {
+	return hp.container
+}
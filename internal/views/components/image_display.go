@@ -1,9 +1,12 @@
 package components
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 
+	analysistheme "otsu-obliterator/internal/views/theme"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
@@ -18,9 +21,44 @@ const (
 // ImageDisplay handles the display of original and processed images
 type ImageDisplay struct {
 	container      *fyne.Container
-	originalImage  *canvas.Image
-	processedImage *canvas.Image
+	originalPane   *ZoomableImageDisplay
+	processedPane  *ZoomableImageDisplay
+	linked         *LinkedImageDisplay
+	
+	// Histogram panels below each pane
+	originalHistogram  *HistogramOverlay
+	processedHistogram *HistogramOverlay
+	
+	// Ground truth error map toggle on the processed pane
+	errorMapCheck   *widget.Check
+	errorMapHandler func(bool)
+
+	// Difference view toggle, swapping the result for its difference image
+	differenceCheck *widget.Check
+	processedImage  image.Image
+	differenceImage image.Image
+	
+	// Region of interest drawn on the original pane
+	roiHandler func(image.Rectangle)
+
+	// Labelled annotations drawn on the original pane
+	annotations *AnnotationLayer
+
+	// Slice navigator shown below the panes while a volume stack is loaded
+	stackNavigator *fyne.Container
+	stackSlider    *widget.Slider
+	stackLabel     *widget.Label
+	stackSharpness *SharpnessChart
+	stackHandler   func(int)
 	splitView      *container.Split
+
+	// Compare mode replaces the processed pane with two results side by
+	// side, each captioned with its algorithm and IoU
+	processedPanel fyne.CanvasObject
+	comparePanel   *container.Split
+	compareImages  [2]*canvas.Image
+	compareCaption [2]*canvas.Text
+	compareMode    bool
 	
 	// Placeholder images
 	originalPlaceholder  *canvas.Image
@@ -29,6 +67,7 @@ type ImageDisplay struct {
 	// State
 	hasOriginal  bool
 	hasProcessed bool
+	hasPreview   bool
 }
 
 // NewImageDisplay creates a new image display component
@@ -45,16 +84,116 @@ func (id *ImageDisplay) createComponents() {
 	id.originalPlaceholder = id.createPlaceholderImage("Load an image to begin")
 	id.processedPlaceholder = id.createPlaceholderImage("Processed result will appear here")
 	
-	// Create image canvases with modern Fyne v2.6+ settings
-	id.originalImage = canvas.NewImageFromImage(id.originalPlaceholder.Image)
-	id.originalImage.FillMode = canvas.ImageFillContain
-	id.originalImage.ScaleMode = canvas.ImageScaleSmooth
-	id.originalImage.SetMinSize(fyne.NewSize(ImageAreaWidth, ImageAreaHeight))
+	// Create zoomable panes with independent zoom state
+	id.originalPane = NewZoomableImageDisplay("Original Image", id.originalPlaceholder.Image)
+	id.processedPane = NewZoomableImageDisplay("Processed Result", id.processedPlaceholder.Image)
+	id.linked = NewLinkedImageDisplay(id.originalPane, id.processedPane)
 	
-	id.processedImage = canvas.NewImageFromImage(id.processedPlaceholder.Image)
-	id.processedImage.FillMode = canvas.ImageFillContain
-	id.processedImage.ScaleMode = canvas.ImageScaleSmooth
-	id.processedImage.SetMinSize(fyne.NewSize(ImageAreaWidth, ImageAreaHeight))
+	id.errorMapCheck = widget.NewCheck("Show Error Map", func(enabled bool) {
+		if id.errorMapHandler != nil {
+			id.errorMapHandler(enabled)
+		}
+	})
+	id.errorMapCheck.Disable()
+	id.processedPane.AddHeaderItem(id.errorMapCheck)
+
+	id.differenceCheck = widget.NewCheck("Difference View", func(bool) {
+		id.showProcessed()
+	})
+	id.differenceCheck.Disable()
+	id.processedPane.AddHeaderItem(id.differenceCheck)
+	
+	id.originalPane.SetSelectionEnabled(true)
+	id.originalPane.SetSelectionHandler(func(roi image.Rectangle) {
+		if id.roiHandler != nil {
+			id.roiHandler(roi)
+		}
+	})
+
+	id.annotations = NewAnnotationLayer()
+	id.originalPane.SetAnnotationLayer(id.annotations)
+	id.originalPane.AddHeaderItem(id.createAnnotationControls())
+	
+	id.originalHistogram = NewHistogramOverlay()
+	id.processedHistogram = NewHistogramOverlay()
+
+	id.createStackNavigator()
+	id.createComparePanel()
+}
+
+// createComparePanel builds the two captioned result panes of compare mode
+func (id *ImageDisplay) createComparePanel() {
+	var panes [2]fyne.CanvasObject
+	for i := range panes {
+		img := canvas.NewImageFromImage(id.processedPlaceholder.Image)
+		img.FillMode = canvas.ImageFillContain
+		img.ScaleMode = canvas.ImageScaleSmooth
+		img.SetMinSize(fyne.NewSize(ImageAreaWidth/2, ImageAreaHeight))
+
+		caption := canvas.NewText("", color.RGBA{R: 220, G: 40, B: 40, A: 255})
+		caption.TextStyle = fyne.TextStyle{Bold: true}
+
+		id.compareImages[i] = img
+		id.compareCaption[i] = caption
+		panes[i] = container.NewStack(
+			id.createImageBackground(),
+			img,
+			container.NewVBox(container.NewPadded(caption)),
+		)
+	}
+
+	id.comparePanel = container.NewHSplit(panes[0], panes[1])
+	id.comparePanel.SetOffset(0.5)
+}
+
+// createStackNavigator builds the hidden slice slider for volume stacks
+func (id *ImageDisplay) createStackNavigator() {
+	id.stackLabel = widget.NewLabel("")
+	id.stackSlider = widget.NewSlider(1, 2)
+	id.stackSlider.Step = 1
+	id.stackSlider.OnChanged = func(value float64) {
+		id.stackLabel.SetText(fmt.Sprintf("Slice %d / %d", int(value), int(id.stackSlider.Max)))
+		id.stackSharpness.SetCurrent(int(value) - 1)
+	}
+	id.stackSlider.OnChangeEnded = func(value float64) {
+		if id.stackHandler != nil {
+			id.stackHandler(int(value) - 1)
+		}
+	}
+
+	// Focus score of each slice, hidden until the scores are known
+	id.stackSharpness = NewSharpnessChart()
+	id.stackSharpness.Hide()
+
+	id.stackNavigator = container.NewBorder(nil, nil, id.stackLabel, id.stackSharpness, id.stackSlider)
+	id.stackNavigator.Hide()
+}
+
+// createAnnotationControls builds the tool, label and colour selectors for
+// annotating the original pane
+func (id *ImageDisplay) createAnnotationControls() fyne.CanvasObject {
+	labelEntry := widget.NewEntry()
+	labelEntry.SetPlaceHolder("Label")
+	labelEntry.OnChanged = id.annotations.SetLabel
+
+	colorSelect := widget.NewSelect(annotationColorNames, func(name string) {
+		id.annotations.SetColor(annotationColors[name])
+	})
+	colorSelect.SetSelected(annotationColorNames[0])
+
+	toolSelect := widget.NewSelect(annotationToolNames, func(name string) {
+		for tool, toolName := range annotationToolNames {
+			if toolName == name {
+				id.annotations.SetTool(AnnotationTool(tool))
+			}
+		}
+	})
+	toolSelect.SetSelected(annotationToolNames[AnnotationToolNone])
+
+	// A bare entry collapses to its placeholder width inside the header
+	labelBox := container.NewGridWrap(fyne.NewSize(120, labelEntry.MinSize().Height), labelEntry)
+
+	return container.NewHBox(toolSelect, labelBox, colorSelect)
 }
 
 // createPlaceholderImage creates a placeholder image with text
@@ -90,72 +229,172 @@ func (id *ImageDisplay) createPlaceholderImage(text string) *canvas.Image {
 
 // setupLayout creates the split view layout
 func (id *ImageDisplay) setupLayout() {
-	// Create containers with headers
-	originalContainer := container.NewBorder(
-		container.NewHBox(
-			widget.NewRichTextFromMarkdown("**Original Image**"),
-		),
-		nil, nil, nil,
-		container.NewStack(
-			id.createImageBackground(),
-			id.originalImage,
-		),
-	)
-	
-	processedContainer := container.NewBorder(
-		container.NewHBox(
-			widget.NewRichTextFromMarkdown("**Processed Result**"),
-		),
-		nil, nil, nil,
-		container.NewStack(
-			id.createImageBackground(),
-			id.processedImage,
-		),
-	)
-	
 	// Create split view
-	id.splitView = container.NewHSplit(originalContainer, processedContainer)
+	id.processedPanel = id.withHistogramPanel(id.processedPane, id.processedHistogram)
+	id.splitView = container.NewHSplit(
+		id.withHistogramPanel(id.originalPane, id.originalHistogram),
+		id.processedPanel,
+	)
 	id.splitView.SetOffset(0.5) // Equal split
 	
-	id.container = id.splitView
+	id.container = container.NewBorder(nil, id.stackNavigator, nil, nil, id.splitView)
+}
+
+// withHistogramPanel places a collapsible histogram below an image pane
+func (id *ImageDisplay) withHistogramPanel(pane *ZoomableImageDisplay, histogram *HistogramOverlay) fyne.CanvasObject {
+	accordion := widget.NewAccordion(widget.NewAccordionItem("Histogram", histogram))
+	return container.NewBorder(nil, accordion, nil, nil, pane.GetContainer())
 }
 
 // createImageBackground creates background for image areas
 func (id *ImageDisplay) createImageBackground() *canvas.Rectangle {
-	bg := canvas.NewRectangle(color.RGBA{R: 252, G: 252, B: 252, A: 255})
+	bg := canvas.NewRectangle(imageBackgroundColor())
 	return bg
 }
 
+// imageBackgroundColor returns the image pane colour of the current theme
+func imageBackgroundColor() color.Color {
+	app := fyne.CurrentApp()
+	if app == nil {
+		return analysistheme.DefaultImageBackground
+	}
+	settings := app.Settings()
+	return analysistheme.ImageBackground(settings.Theme(), settings.ThemeVariant())
+}
+
 // SetOriginalImage updates the original image display
 func (id *ImageDisplay) SetOriginalImage(img image.Image) {
+	// The histograms are safe to update from any goroutine and queue their own redraw
+	id.originalHistogram.SetImage(img)
+	id.SetThresholds(nil)
+
 	fyne.Do(func() {
 		if img != nil {
-			id.originalImage.Image = img
+			id.originalPane.SetImage(img)
 			id.hasOriginal = true
 		} else {
-			id.originalImage.Image = id.originalPlaceholder.Image
+			id.originalPane.SetImage(id.originalPlaceholder.Image)
 			id.hasOriginal = false
 		}
-		id.originalImage.Refresh()
+		id.originalPane.FitToWindow()
 		id.container.Refresh()
 	})
 }
 
 // SetProcessedImage updates the processed image display
 func (id *ImageDisplay) SetProcessedImage(img image.Image) {
+	id.processedHistogram.SetImage(img)
+
 	fyne.Do(func() {
+		// Keep the user's zoom across reprocessing, fit only on first result
+		fit := !id.hasProcessed && !id.hasPreview
+		id.hasPreview = false
+		id.processedPane.SetWatermark("")
+		id.processedImage = img
+		id.differenceImage = nil
+		id.differenceCheck.Disable()
 		if img != nil {
-			id.processedImage.Image = img
+			id.processedPane.SetImage(img)
 			id.hasProcessed = true
 		} else {
-			id.processedImage.Image = id.processedPlaceholder.Image
+			id.processedPane.SetImage(id.processedPlaceholder.Image)
 			id.hasProcessed = false
+			fit = true
+		}
+		if fit {
+			id.processedPane.FitToWindow()
+		}
+		id.container.Refresh()
+	})
+}
+
+// SetDifferenceImage supplies the difference image for the result currently
+// shown, nil when there is none. It must follow the matching SetProcessedImage.
+func (id *ImageDisplay) SetDifferenceImage(img image.Image) {
+	fyne.Do(func() {
+		id.differenceImage = img
+		if img != nil && id.hasProcessed {
+			id.differenceCheck.Enable()
+		} else {
+			id.differenceCheck.Disable()
+		}
+		id.showProcessed()
+	})
+}
+
+// showProcessed puts the result or, in difference view, its difference image
+// in the processed pane. It must run on the UI goroutine.
+func (id *ImageDisplay) showProcessed() {
+	if !id.hasProcessed || id.hasPreview {
+		return
+	}
+
+	if id.differenceCheck.Checked && id.differenceImage != nil {
+		id.processedPane.SetImage(id.differenceImage)
+		id.processedPane.SetWatermark("Difference")
+	} else {
+		id.processedPane.SetImage(id.processedImage)
+		id.processedPane.SetWatermark("")
+	}
+}
+
+// SetPreviewImage shows a low quality preview in the processed pane
+func (id *ImageDisplay) SetPreviewImage(img image.Image) {
+	if img == nil {
+		return
+	}
+
+	id.processedHistogram.SetImage(img)
+
+	fyne.Do(func() {
+		fit := !id.hasProcessed && !id.hasPreview
+		id.hasPreview = true
+		id.processedPane.SetImage(img)
+		id.processedPane.SetWatermark("Preview")
+		if fit {
+			id.processedPane.FitToWindow()
 		}
-		id.processedImage.Refresh()
 		id.container.Refresh()
 	})
 }
 
+// SetCompareMode switches the processed side between a single result and
+// the two compare mode results
+func (id *ImageDisplay) SetCompareMode(enabled bool) {
+	fyne.Do(func() {
+		if enabled == id.compareMode {
+			return
+		}
+		id.compareMode = enabled
+
+		if enabled {
+			id.splitView.Trailing = id.comparePanel
+		} else {
+			id.splitView.Trailing = id.processedPanel
+		}
+		id.splitView.Refresh()
+	})
+}
+
+// SetComparisonImages shows the two compare mode results with their
+// captions. A nil image shows the placeholder.
+func (id *ImageDisplay) SetComparisonImages(left, right image.Image, leftCaption, rightCaption string) {
+	fyne.Do(func() {
+		images := [2]image.Image{left, right}
+		captions := [2]string{leftCaption, rightCaption}
+		for i, img := range images {
+			if img == nil {
+				img = id.processedPlaceholder.Image
+			}
+			id.compareImages[i].Image = img
+			id.compareImages[i].Refresh()
+
+			id.compareCaption[i].Text = captions[i]
+			id.compareCaption[i].Refresh()
+		}
+	})
+}
+
 // HasOriginalImage returns true if original image is loaded
 func (id *ImageDisplay) HasOriginalImage() bool {
 	return id.hasOriginal
@@ -168,10 +407,9 @@ func (id *ImageDisplay) HasProcessedImage() bool {
 
 // ClearImages clears both images
 func (id *ImageDisplay) ClearImages() {
-	fyne.Do(func() {
-		id.SetOriginalImage(nil)
-		id.SetProcessedImage(nil)
-	})
+	id.SetOriginalImage(nil)
+	id.SetProcessedImage(nil)
+	id.SetComparisonImages(nil, nil, "", "")
 }
 
 // SetSplitRatio adjusts the split ratio between images
@@ -185,22 +423,146 @@ func (id *ImageDisplay) SetSplitRatio(ratio float64) {
 
 // GetOriginalImageSize returns the dimensions of the original image
 func (id *ImageDisplay) GetOriginalImageSize() (int, int) {
-	if !id.hasOriginal || id.originalImage.Image == nil {
+	if !id.hasOriginal || id.originalPane.Image() == nil {
 		return 0, 0
 	}
-	bounds := id.originalImage.Image.Bounds()
+	bounds := id.originalPane.Image().Bounds()
 	return bounds.Dx(), bounds.Dy()
 }
 
 // GetProcessedImageSize returns the dimensions of the processed image
 func (id *ImageDisplay) GetProcessedImageSize() (int, int) {
-	if !id.hasProcessed || id.processedImage.Image == nil {
+	if !id.hasProcessed || id.processedPane.Image() == nil {
 		return 0, 0
 	}
-	bounds := id.processedImage.Image.Bounds()
+	bounds := id.processedPane.Image().Bounds()
 	return bounds.Dx(), bounds.Dy()
 }
 
+// GetOriginalPane returns the zoomable pane showing the original image
+func (id *ImageDisplay) GetOriginalPane() *ZoomableImageDisplay {
+	return id.originalPane
+}
+
+// GetProcessedPane returns the zoomable pane showing the processed image
+func (id *ImageDisplay) GetProcessedPane() *ZoomableImageDisplay {
+	return id.processedPane
+}
+
+// SetROIHandler sets the handler called when a region is drawn on the original image
+func (id *ImageDisplay) SetROIHandler(handler func(image.Rectangle)) {
+	id.roiHandler = handler
+}
+
+// SetROI outlines a region of the original image, nil removes the outline
+func (id *ImageDisplay) SetROI(roi *image.Rectangle) {
+	fyne.Do(func() {
+		id.originalPane.SetSelection(roi)
+	})
+}
+
+// SetAnnotationHandler sets the handler called when an annotation is drawn on
+// the original image
+func (id *ImageDisplay) SetAnnotationHandler(handler func(Annotation)) {
+	id.annotations.SetAnnotationAddedHandler(handler)
+}
+
+// SetAnnotations replaces the annotations drawn on the original image
+func (id *ImageDisplay) SetAnnotations(annotations []Annotation) {
+	fyne.Do(func() {
+		id.annotations.SetAnnotations(annotations)
+	})
+}
+
+// SetStackHandler sets the handler called with the zero-based slice index
+// when the stack navigator is moved
+func (id *ImageDisplay) SetStackHandler(handler func(int)) {
+	id.stackHandler = handler
+}
+
+// SetStackInfo shows the stack navigator at slice current of total, or hides
+// it when total is below two
+func (id *ImageDisplay) SetStackInfo(current, total int) {
+	fyne.Do(func() {
+		if total < 2 {
+			id.stackNavigator.Hide()
+			id.stackSharpness.SetScores(nil)
+			id.stackSharpness.Hide()
+			return
+		}
+
+		id.stackSlider.Max = float64(total)
+		id.stackSlider.SetValue(float64(current + 1))
+		id.stackLabel.SetText(fmt.Sprintf("Slice %d / %d", current+1, total))
+		id.stackSharpness.SetCurrent(current)
+		id.stackNavigator.Show()
+	})
+}
+
+// SetStackSharpness plots the focus score of each slice next to the stack
+// navigator; nil hides the chart
+func (id *ImageDisplay) SetStackSharpness(scores []float64) {
+	fyne.Do(func() {
+		id.stackSharpness.SetScores(scores)
+		if len(scores) < 2 {
+			id.stackSharpness.Hide()
+		} else {
+			id.stackSharpness.Show()
+		}
+		id.stackNavigator.Refresh()
+	})
+}
+
+// SetErrorMapHandler sets the handler for the error map toggle
+func (id *ImageDisplay) SetErrorMapHandler(handler func(bool)) {
+	id.errorMapHandler = handler
+}
+
+// SetErrorMapAvailable enables the error map toggle once ground truth is loaded
+func (id *ImageDisplay) SetErrorMapAvailable(available bool) {
+	fyne.Do(func() {
+		if available {
+			id.errorMapCheck.Enable()
+		} else {
+			id.errorMapCheck.SetChecked(false)
+			id.errorMapCheck.Disable()
+		}
+	})
+}
+
+// SetErrorMap overlays a pixel accuracy map on the processed image, nil removes it
+func (id *ImageDisplay) SetErrorMap(img image.Image) {
+	fyne.Do(func() {
+		id.processedPane.SetOverlay(img)
+	})
+}
+
+// SetThresholds marks threshold values on both histograms
+func (id *ImageDisplay) SetThresholds(thresholds []uint8) {
+	id.originalHistogram.SetThresholds(thresholds)
+	id.processedHistogram.SetThresholds(thresholds)
+}
+
+// SetSyncEnabled links or unlinks zoom and scroll between the two panes
+func (id *ImageDisplay) SetSyncEnabled(enabled bool) {
+	fyne.Do(func() {
+		id.linked.SetSyncEnabled(enabled)
+	})
+}
+
+// IsSyncEnabled returns true if the panes scroll and zoom together
+func (id *ImageDisplay) IsSyncEnabled() bool {
+	return id.linked.IsSyncEnabled()
+}
+
+// FitToWindow fits both images to their panes
+func (id *ImageDisplay) FitToWindow() {
+	fyne.Do(func() {
+		id.originalPane.FitToWindow()
+		id.processedPane.FitToWindow()
+	})
+}
+
 // GetContainer returns the main container
 func (id *ImageDisplay) GetContainer() *fyne.Container {
 	return id.container
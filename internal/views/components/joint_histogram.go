@@ -0,0 +1,124 @@
+package components
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+const JointHistogramSize = 256
+
+var (
+	jointHistogramEmpty     = color.RGBA{R: 0, G: 0, B: 64, A: 255}
+	jointHistogramCrosshair = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// JointHistogramDisplay renders the 2D Otsu joint histogram of pixel value
+// (x-axis) against neighbourhood mean (y-axis, increasing upwards) as a jet
+// colour heatmap, with the optimal threshold pair marked by a crosshair
+type JointHistogramDisplay struct {
+	widget.BaseWidget
+
+	raster *canvas.Raster
+
+	mu        sync.RWMutex
+	histogram [][]float64
+	logMax    float64
+	threshold [2]float64
+	hasData   bool
+}
+
+// NewJointHistogramDisplay creates an empty joint histogram display
+func NewJointHistogramDisplay() *JointHistogramDisplay {
+	display := &JointHistogramDisplay{}
+	display.raster = canvas.NewRaster(display.draw)
+	display.raster.SetMinSize(fyne.NewSize(JointHistogramSize, JointHistogramSize))
+	display.ExtendBaseWidget(display)
+	return display
+}
+
+// CreateRenderer links the display to its raster
+func (jh *JointHistogramDisplay) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(jh.raster)
+}
+
+// SetData shows histogram, indexed [pixel bin][neighbourhood bin], with the
+// threshold pair given in bin units. A nil histogram clears the display.
+func (jh *JointHistogramDisplay) SetData(histogram [][]float64, threshold [2]float64) {
+	// Counts span several orders of magnitude, so colours follow log(1+count)
+	logMax := 0.0
+	for _, row := range histogram {
+		for _, count := range row {
+			logMax = math.Max(logMax, math.Log1p(count))
+		}
+	}
+
+	jh.mu.Lock()
+	jh.histogram = histogram
+	jh.logMax = logMax
+	jh.threshold = threshold
+	jh.hasData = len(histogram) > 0
+	jh.mu.Unlock()
+
+	fyne.Do(func() {
+		jh.raster.Refresh()
+	})
+}
+
+// draw renders the heatmap and crosshair at the raster size
+func (jh *JointHistogramDisplay) draw(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 {
+		return img
+	}
+
+	jh.mu.RLock()
+	defer jh.mu.RUnlock()
+
+	if !jh.hasData {
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				img.SetRGBA(x, y, jointHistogramEmpty)
+			}
+		}
+		return img
+	}
+
+	bins := len(jh.histogram)
+	for y := 0; y < h; y++ {
+		j := (h - 1 - y) * bins / h
+		for x := 0; x < w; x++ {
+			i := x * bins / w
+			value := 0.0
+			if jh.logMax > 0 && j < len(jh.histogram[i]) {
+				value = math.Log1p(jh.histogram[i][j]) / jh.logMax
+			}
+			img.SetRGBA(x, y, jetColor(value))
+		}
+	}
+
+	crossX := int(jh.threshold[0] * float64(w) / float64(bins))
+	crossY := h - 1 - int(jh.threshold[1]*float64(h)/float64(bins))
+	for x := 0; x < w; x++ {
+		img.SetRGBA(x, crossY, jointHistogramCrosshair)
+	}
+	for y := 0; y < h; y++ {
+		img.SetRGBA(crossX, y, jointHistogramCrosshair)
+	}
+
+	return img
+}
+
+// jetColor maps v in [0, 1] onto the jet colour map from dark blue to dark red
+func jetColor(v float64) color.RGBA {
+	v = math.Max(0, math.Min(1, v))
+	channel := func(offset float64) uint8 {
+		return uint8(255 * math.Max(0, math.Min(1, 1.5-math.Abs(4*v-offset))))
+	}
+	return color.RGBA{R: channel(3), G: channel(2), B: channel(1), A: 255}
+}
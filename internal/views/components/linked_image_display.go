@@ -0,0 +1,69 @@
+package components
+
+import (
+	"fyne.io/fyne/v2"
+)
+
+// LinkedImageDisplay mirrors zoom and scroll between two zoomable panes
+type LinkedImageDisplay struct {
+	first  *ZoomableImageDisplay
+	second *ZoomableImageDisplay
+
+	// State
+	syncEnabled bool
+	syncing     bool
+}
+
+// NewLinkedImageDisplay links two panes, with synchronisation initially enabled
+func NewLinkedImageDisplay(first, second *ZoomableImageDisplay) *LinkedImageDisplay {
+	linked := &LinkedImageDisplay{
+		first:       first,
+		second:      second,
+		syncEnabled: true,
+	}
+	linked.setupEventHandlers()
+	return linked
+}
+
+// setupEventHandlers forwards view changes from each pane to the other
+func (ld *LinkedImageDisplay) setupEventHandlers() {
+	ld.first.SetZoomChangeHandler(func(float32) {
+		ld.mirror(ld.first, ld.second)
+	})
+	ld.first.SetScrollChangeHandler(func(fyne.Position) {
+		ld.mirror(ld.first, ld.second)
+	})
+	ld.second.SetZoomChangeHandler(func(float32) {
+		ld.mirror(ld.second, ld.first)
+	})
+	ld.second.SetScrollChangeHandler(func(fyne.Position) {
+		ld.mirror(ld.second, ld.first)
+	})
+}
+
+// mirror copies the view state of source to target. The syncing guard stops
+// the target's own change notifications from echoing back to the source.
+func (ld *LinkedImageDisplay) mirror(source, target *ZoomableImageDisplay) {
+	if !ld.syncEnabled || ld.syncing {
+		return
+	}
+
+	ld.syncing = true
+	defer func() { ld.syncing = false }()
+
+	target.applyZoom(source.ZoomLevel())
+	target.SetScrollOffset(source.ScrollOffset())
+}
+
+// SetSyncEnabled toggles linking. Enabling aligns the second pane to the first.
+func (ld *LinkedImageDisplay) SetSyncEnabled(enabled bool) {
+	ld.syncEnabled = enabled
+	if enabled {
+		ld.mirror(ld.first, ld.second)
+	}
+}
+
+// IsSyncEnabled returns true if zoom and scroll are linked
+func (ld *LinkedImageDisplay) IsSyncEnabled() bool {
+	return ld.syncEnabled
+}
@@ -0,0 +1,110 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// MemoryWarningRatio is the used/total ratio from which the gauge turns red
+const MemoryWarningRatio = 0.8
+
+// memoryCautionRatio is the used/total ratio from which the gauge turns yellow
+const memoryCautionRatio = 0.6
+
+var (
+	gaugeGreen  = color.NRGBA{R: 0x2e, G: 0xa0, B: 0x43, A: 0xff}
+	gaugeYellow = color.NRGBA{R: 0xe0, G: 0xb0, B: 0x1a, A: 0xff}
+	gaugeRed    = color.NRGBA{R: 0xd0, G: 0x35, B: 0x2a, A: 0xff}
+	gaugeTrack  = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0x40}
+)
+
+// MemoryGauge shows memory use as a bar coloured by how full it is, next to
+// a "X MB / Y MB" label
+type MemoryGauge struct {
+	container *fyne.Container
+	track     *canvas.Rectangle
+	fill      *canvas.Rectangle
+	label     *widget.Label
+	layout    *gaugeLayout
+}
+
+// NewMemoryGauge creates an empty gauge
+func NewMemoryGauge() *MemoryGauge {
+	mg := &MemoryGauge{
+		track:  canvas.NewRectangle(gaugeTrack),
+		fill:   canvas.NewRectangle(gaugeGreen),
+		label:  widget.NewLabel("Memory: --"),
+		layout: &gaugeLayout{},
+	}
+
+	bar := container.New(mg.layout, mg.track, mg.fill)
+	mg.container = container.NewHBox(container.NewCenter(bar), mg.label)
+	return mg
+}
+
+// SetUsage updates the bar and label. A total of zero or less shows the
+// gauge as unknown.
+func (mg *MemoryGauge) SetUsage(used, total int64) {
+	fyne.Do(func() {
+		if total <= 0 {
+			mg.layout.ratio = 0
+			mg.label.SetText("Memory: --")
+			mg.container.Refresh()
+			return
+		}
+
+		ratio := min(max(float64(used)/float64(total), 0), 1)
+		mg.layout.ratio = ratio
+		mg.fill.FillColor = gaugeColor(ratio)
+		mg.label.SetText(fmt.Sprintf("%d MB / %d MB", used/(1024*1024), total/(1024*1024)))
+		mg.fill.Refresh()
+		mg.container.Refresh()
+	})
+}
+
+// Reset clears the gauge
+func (mg *MemoryGauge) Reset() {
+	mg.SetUsage(0, 0)
+}
+
+// GetContainer returns the gauge container
+func (mg *MemoryGauge) GetContainer() *fyne.Container {
+	return mg.container
+}
+
+// gaugeColor maps a fill ratio to green, yellow or red
+func gaugeColor(ratio float64) color.Color {
+	switch {
+	case ratio >= MemoryWarningRatio:
+		return gaugeRed
+	case ratio >= memoryCautionRatio:
+		return gaugeYellow
+	default:
+		return gaugeGreen
+	}
+}
+
+// gaugeLayout stretches the track over the whole bar and the fill over the
+// used fraction of it
+type gaugeLayout struct {
+	ratio float64
+}
+
+func (l *gaugeLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	if len(objects) < 2 {
+		return
+	}
+	objects[0].Move(fyne.NewPos(0, 0))
+	objects[0].Resize(size)
+	objects[1].Move(fyne.NewPos(0, 0))
+	objects[1].Resize(fyne.NewSize(size.Width*float32(l.ratio), size.Height))
+}
+
+func (l *gaugeLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(80, 12)
+}
@@ -0,0 +1,216 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	MetadataSidebarWidth = 260
+
+	statisticsSourceOriginal  = "Original"
+	statisticsSourceProcessed = "Processed"
+)
+
+// FileInfo describes a loaded image file for the metadata sidebar. Size is
+// in bytes, 0 when unknown.
+type FileInfo struct {
+	Name     string
+	Size     int64
+	Width    int
+	Height   int
+	Channels int
+	BitDepth int
+}
+
+// ImageStatistics are the grey level statistics shown in the metadata sidebar
+type ImageStatistics struct {
+	Mean             float64
+	StdDev           float64
+	Min              float64
+	Max              float64
+	Entropy          float64
+	DynamicRange     float64
+	DynamicRangeBits float64
+}
+
+// MetadataSidebar shows the file details, EXIF tags and grey level
+// statistics of the loaded image. The statistics are of the original or the
+// processed image as chosen in the sidebar.
+type MetadataSidebar struct {
+	container    *fyne.Container
+	fileForm     *widget.Form
+	exifForm     *widget.Form
+	exifItem     *widget.AccordionItem
+	statsForm    *widget.Form
+	sourceSelect *widget.RadioGroup
+
+	sourceHandler func(processed bool)
+}
+
+// NewMetadataSidebar creates an empty metadata sidebar
+func NewMetadataSidebar() *MetadataSidebar {
+	ms := &MetadataSidebar{}
+	ms.createComponents()
+	ms.buildLayout()
+	return ms
+}
+
+// createComponents initializes the sidebar forms and source selector
+func (ms *MetadataSidebar) createComponents() {
+	ms.fileForm = widget.NewForm()
+	ms.exifForm = widget.NewForm()
+	ms.statsForm = widget.NewForm()
+
+	ms.sourceSelect = widget.NewRadioGroup([]string{statisticsSourceOriginal, statisticsSourceProcessed}, func(selected string) {
+		if ms.sourceHandler != nil && selected != "" {
+			ms.sourceHandler(selected == statisticsSourceProcessed)
+		}
+	})
+	ms.sourceSelect.Horizontal = true
+	ms.sourceSelect.Required = true
+	ms.sourceSelect.Selected = statisticsSourceOriginal
+
+	ms.clearForms()
+}
+
+// buildLayout constructs the sidebar layout
+func (ms *MetadataSidebar) buildLayout() {
+	ms.exifItem = widget.NewAccordionItem("EXIF", ms.exifForm)
+	exifPanel := widget.NewAccordion(ms.exifItem)
+
+	statistics := container.NewVBox(ms.sourceSelect, ms.statsForm)
+
+	content := container.NewVBox(
+		widget.NewCard("File", "", ms.fileForm),
+		exifPanel,
+		widget.NewCard("Statistics", "", statistics),
+	)
+
+	scroll := container.NewVScroll(content)
+	scroll.SetMinSize(fyne.NewSize(MetadataSidebarWidth, 0))
+	ms.container = container.NewStack(scroll)
+}
+
+// SetSourceHandler sets the handler called when the statistics source
+// switches between the original and the processed image
+func (ms *MetadataSidebar) SetSourceHandler(handler func(processed bool)) {
+	ms.sourceHandler = handler
+}
+
+// ShowsProcessed reports whether the statistics are of the processed image.
+// Call on the UI goroutine.
+func (ms *MetadataSidebar) ShowsProcessed() bool {
+	return ms.sourceSelect.Selected == statisticsSourceProcessed
+}
+
+// SetFileInfo shows the details of a newly loaded file and clears the
+// statistics until they are computed
+func (ms *MetadataSidebar) SetFileInfo(info FileInfo) {
+	fyne.Do(func() {
+		setFormRows(ms.fileForm, [][2]string{
+			{"Filename", info.Name},
+			{"File size", formatFileSize(info.Size)},
+			{"Dimensions", fmt.Sprintf("%d × %d", info.Width, info.Height)},
+			{"Channels", fmt.Sprintf("%d", info.Channels)},
+			{"Bit depth", fmt.Sprintf("%d", info.BitDepth)},
+		})
+		setFormRows(ms.statsForm, [][2]string{{"", "Computing..."}})
+	})
+}
+
+// SetExif lists EXIF tags by name; an empty map shows that there are none
+func (ms *MetadataSidebar) SetExif(tags map[string]string) {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := make([][2]string, 0, len(names))
+	for _, name := range names {
+		rows = append(rows, [2]string{name, tags[name]})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, [2]string{"", "No EXIF data"})
+	}
+
+	fyne.Do(func() {
+		setFormRows(ms.exifForm, rows)
+		ms.exifItem.Title = fmt.Sprintf("EXIF (%d)", len(names))
+	})
+}
+
+// SetStatistics shows statistics of the original or processed image; nil
+// shows that none are available for the selected source
+func (ms *MetadataSidebar) SetStatistics(stats *ImageStatistics) {
+	rows := [][2]string{{"", "Not available"}}
+	if stats != nil {
+		rows = [][2]string{
+			{"Mean", fmt.Sprintf("%.2f", stats.Mean)},
+			{"Std. deviation", fmt.Sprintf("%.2f", stats.StdDev)},
+			{"Min / Max", fmt.Sprintf("%.0f / %.0f", stats.Min, stats.Max)},
+			{"Entropy", fmt.Sprintf("%.3f bits", stats.Entropy)},
+			{"Dynamic range", fmt.Sprintf("%.0f (%.1f bits)", stats.DynamicRange, stats.DynamicRangeBits)},
+		}
+	}
+
+	fyne.Do(func() {
+		setFormRows(ms.statsForm, rows)
+	})
+}
+
+// Reset clears the sidebar for a session without an image
+func (ms *MetadataSidebar) Reset() {
+	fyne.Do(ms.clearForms)
+}
+
+// clearForms empties the forms. It must run on the UI goroutine.
+func (ms *MetadataSidebar) clearForms() {
+	setFormRows(ms.fileForm, [][2]string{{"", "No image loaded"}})
+	setFormRows(ms.exifForm, nil)
+	setFormRows(ms.statsForm, nil)
+	if ms.exifItem != nil {
+		ms.exifItem.Title = "EXIF"
+	}
+}
+
+// GetContainer returns the sidebar container
+func (ms *MetadataSidebar) GetContainer() *fyne.Container {
+	return ms.container
+}
+
+// setFormRows replaces the rows of form. It must run on the UI goroutine.
+func setFormRows(form *widget.Form, rows [][2]string) {
+	form.Items = nil
+	for _, row := range rows {
+		value := widget.NewLabel(row[1])
+		value.Wrapping = fyne.TextWrapWord
+		form.Append(row[0], value)
+	}
+	form.Refresh()
+}
+
+// formatFileSize renders a byte count with a binary unit
+func formatFileSize(size int64) string {
+	if size <= 0 {
+		return "Unknown"
+	}
+
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	for _, suffix := range []string{"KiB", "MiB", "GiB"} {
+		value /= unit
+		if value < unit || suffix == "GiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
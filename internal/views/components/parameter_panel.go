@@ -1,21 +1,36 @@
 package components
 
 import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strconv"
 
+	"otsu-obliterator/internal/algorithms/convergence"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 )
 
+// savePresetOption is the last entry of the presets dropdown
+const savePresetOption = "Save Current…"
+
 // ParameterPanel manages algorithm parameter controls
 type ParameterPanel struct {
 	container              *fyne.Container
 	parametersContent      *fyne.Container
 	parameterChangeHandler func(string, interface{})
+	weightMapHandler       func()
+	presetLoadHandler      func(string)
+	presetSaveHandler      func()
+	presetSelect           *widget.Select
 	currentAlgorithm       string
 	parameterWidgets       map[string]fyne.CanvasObject
 	parameterCount         int
+	convergencePlot        *ConvergencePlot
+	convergenceSection     *widget.Accordion
 }
 
 // NewParameterPanel creates a new parameter panel
@@ -32,7 +47,31 @@ func (pp *ParameterPanel) setupPanel() {
 	pp.parametersContent = container.NewVBox(
 		widget.NewLabel("Parameters:"),
 	)
-	pp.container = container.NewVBox(pp.parametersContent)
+
+	// Convergence plot, shown only for Iterative Triclass
+	pp.convergencePlot = NewConvergencePlot()
+	pp.convergenceSection = widget.NewAccordion(widget.NewAccordionItem("Convergence", pp.convergencePlot))
+	pp.convergenceSection.Hide()
+
+	// Saved presets, followed by an entry that saves the current parameters
+	pp.presetSelect = widget.NewSelect([]string{savePresetOption}, func(selected string) {
+		switch selected {
+		case "":
+		case savePresetOption:
+			pp.presetSelect.ClearSelected()
+			if pp.presetSaveHandler != nil {
+				pp.presetSaveHandler()
+			}
+		default:
+			if pp.presetLoadHandler != nil {
+				pp.presetLoadHandler(selected)
+			}
+		}
+	})
+	pp.presetSelect.PlaceHolder = "(no preset)"
+	presetsRow := container.NewBorder(nil, nil, widget.NewLabel("Presets"), nil, pp.presetSelect)
+
+	pp.container = container.NewVBox(presetsRow, pp.parametersContent, pp.convergenceSection)
 }
 
 // UpdateParameters rebuilds the parameter panel for a new algorithm
@@ -44,6 +83,13 @@ func (pp *ParameterPanel) UpdateParameters(algorithm string, params map[string]i
 		}
 
 		pp.currentAlgorithm = algorithm
+		pp.convergencePlot.setData(nil)
+		if algorithm == "Iterative Triclass" {
+			pp.convergenceSection.Show()
+		} else {
+			pp.convergenceSection.Hide()
+		}
+
 		pp.parametersContent.RemoveAll()
 		pp.parametersContent.Add(widget.NewLabel("Parameters:"))
 		pp.parameterWidgets = make(map[string]fyne.CanvasObject)
@@ -53,6 +99,16 @@ func (pp *ParameterPanel) UpdateParameters(algorithm string, params map[string]i
 			pp.buildOtsu2DParameters(params)
 		case "Iterative Triclass":
 			pp.buildTriclassParameters(params)
+		case "Ridler-Calvard":
+			pp.buildRidlerCalvardParameters(params)
+		case "Minimum Cross-Entropy":
+			pp.buildMinCrossEntropyParameters(params)
+		case "Multi-Level Otsu":
+			pp.buildMultiLevelOtsuParameters(params)
+		case "HSV Otsu":
+			pp.buildHSVOtsuParameters(params)
+		default:
+			pp.buildGenericParameters(params)
 		}
 
 		pp.parameterCount = len(pp.parameterWidgets)
@@ -79,6 +135,12 @@ func (pp *ParameterPanel) updateValues(params map[string]interface{}) {
 				if strVal, ok := value.(string); ok {
 					widget.SetSelected(strVal)
 				}
+			case *widget.Entry:
+				widget.SetText(fmt.Sprint(value))
+			case *widget.Label:
+				if strVal, ok := value.(string); ok {
+					widget.SetText(weightMapLabel(strVal))
+				}
 			}
 		}
 	}
@@ -104,6 +166,22 @@ func (pp *ParameterPanel) buildOtsu2DParameters(params map[string]interface{}) {
 		}
 	}
 
+	// Adaptive window sizes replace the fixed window in textured and smooth regions
+	adaptiveWindowCheck := widget.NewCheck("Adaptive Window Size (slower)", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("adaptive_window_size", checked)
+		}
+	})
+	adaptiveWindowCheck.SetChecked(pp.getBoolParam(params, "adaptive_window_size", false))
+
+	// Multi-scale blends means over half, full and double the window size
+	multiScaleCheck := widget.NewCheck("Multi-Scale Neighborhood", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("multi_scale", checked)
+		}
+	})
+	multiScaleCheck.SetChecked(pp.getBoolParam(params, "multi_scale", false))
+
 	// Histogram Bins parameter
 	histBinsSlider := widget.NewSlider(0, 256)
 	histBinsLabel := widget.NewLabel("Histogram Bins: Auto")
@@ -154,13 +232,45 @@ func (pp *ParameterPanel) buildOtsu2DParameters(params map[string]interface{}) {
 	})
 	gaussianPreprocessCheck.SetChecked(pp.getBoolParam(params, "gaussian_preprocessing", true))
 
+	globalEqualizationCheck := pp.newGlobalEqualizationCheck(params)
+
+	// Global equalisation and CLAHE are mutually exclusive, so enabling CLAHE
+	// clears and greys out the global option
 	useClaheCheck := widget.NewCheck("CLAHE Contrast Enhancement", func(checked bool) {
+		if checked {
+			globalEqualizationCheck.SetChecked(false)
+			globalEqualizationCheck.Disable()
+		} else {
+			globalEqualizationCheck.Enable()
+		}
 		if pp.parameterChangeHandler != nil {
 			pp.parameterChangeHandler("use_clahe", checked)
 		}
 	})
 	useClaheCheck.SetChecked(pp.getBoolParam(params, "use_clahe", false))
 
+	// CLAHE clip limit, where Auto (0.0) derives it from the image noise level
+	clipLimitOptions := []string{"Auto", "1.5", "2.0", "3.0", "4.0", "6.0", "8.0"}
+	clipLimitSelect := widget.NewSelect(clipLimitOptions, func(value string) {
+		clipLimit := 0.0
+		if value != "Auto" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return
+			}
+			clipLimit = parsed
+		}
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("clahe_clip_limit", clipLimit)
+		}
+	})
+	clipLimit := pp.getFloatParam(params, "clahe_clip_limit", 3.0)
+	if clipLimit == 0.0 {
+		clipLimitSelect.SetSelected("Auto")
+	} else {
+		clipLimitSelect.SetSelected(strconv.FormatFloat(clipLimit, 'f', 1, 64))
+	}
+
 	guidedFilteringCheck := widget.NewCheck("Guided Filtering", func(checked bool) {
 		if pp.parameterChangeHandler != nil {
 			pp.parameterChangeHandler("guided_filtering", checked)
@@ -177,29 +287,62 @@ func (pp *ParameterPanel) buildOtsu2DParameters(params map[string]interface{}) {
 
 	// Store widgets for updates
 	pp.parameterWidgets["window_size"] = windowSizeSlider
+	pp.parameterWidgets["adaptive_window_size"] = adaptiveWindowCheck
+	pp.parameterWidgets["multi_scale"] = multiScaleCheck
 	pp.parameterWidgets["histogram_bins"] = histBinsSlider
 	pp.parameterWidgets["smoothing_strength"] = smoothingSlider
 	pp.parameterWidgets["noise_robustness"] = noiseRobustnessCheck
 	pp.parameterWidgets["gaussian_preprocessing"] = gaussianPreprocessCheck
+	pp.parameterWidgets["apply_global_equalization"] = globalEqualizationCheck
 	pp.parameterWidgets["use_clahe"] = useClaheCheck
+	pp.parameterWidgets["clahe_clip_limit"] = clipLimitSelect
 	pp.parameterWidgets["guided_filtering"] = guidedFilteringCheck
 	pp.parameterWidgets["parallel_processing"] = parallelProcessingCheck
 
+	// Weight map, chosen by the controller's file dialog
+	weightMapPathLabel := widget.NewLabel(weightMapLabel(pp.getStringParam(params, "weight_map_path", "")))
+	weightMapPathLabel.Truncation = fyne.TextTruncateEllipsis
+	loadWeightMapButton := widget.NewButton("Load Weight Map", func() {
+		if pp.weightMapHandler != nil {
+			pp.weightMapHandler()
+		}
+	})
+	clearWeightMapButton := widget.NewButton("Clear", func() {
+		weightMapPathLabel.SetText(weightMapLabel(""))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("weight_map_path", "")
+		}
+	})
+	pp.parameterWidgets["weight_map_path"] = weightMapPathLabel
+
 	// Layout parameter groups
 	basicGroup := widget.NewCard("Basic Parameters", "",
 		container.NewVBox(
 			container.NewVBox(windowSizeLabel, windowSizeSlider),
+			adaptiveWindowCheck,
+			multiScaleCheck,
 			container.NewVBox(histBinsLabel, histBinsSlider),
 			container.NewVBox(smoothingLabel, smoothingSlider),
 		),
 	)
 
+	channelSelect := pp.newChannelSelect(params)
+	pp.parameterWidgets["channel_selection"] = channelSelect
+
 	preprocessingGroup := widget.NewCard("Preprocessing Options", "",
 		container.NewVBox(
+			container.NewBorder(nil, nil, widget.NewLabel("Channel"), nil, channelSelect),
 			noiseRobustnessCheck,
 			gaussianPreprocessCheck,
+			globalEqualizationCheck,
 			useClaheCheck,
+			container.NewBorder(nil, nil, widget.NewLabel("CLAHE Clip Limit"), nil, clipLimitSelect),
+			pp.newUnsharpControls(params),
 			guidedFilteringCheck,
+			container.NewBorder(nil, nil, nil,
+				container.NewHBox(loadWeightMapButton, clearWeightMapButton),
+				weightMapPathLabel,
+			),
 		),
 	)
 
@@ -236,14 +379,25 @@ func (pp *ParameterPanel) buildTriclassParameters(params map[string]interface{})
 		}
 	}
 
-	// Convergence precision
-	convergenceSlider := widget.NewSlider(0.5, 2.0)
+	// Convergence precision, where values below 0.5 select Auto (0.0) which
+	// derives it from the image dynamic range
+	convergenceSlider := widget.NewSlider(0.0, 2.0)
+	convergenceSlider.Step = 0.1
 	convergenceLabel := widget.NewLabel("Convergence Precision: 1.0")
+	convergenceText := func(value float64) string {
+		if value == 0 {
+			return "Convergence Precision: Auto"
+		}
+		return "Convergence Precision: " + strconv.FormatFloat(value, 'f', 1, 64)
+	}
 	convergence := pp.getFloatParam(params, "convergence_precision", 1.0)
 	convergenceSlider.SetValue(convergence)
-	convergenceLabel.SetText("Convergence Precision: " + strconv.FormatFloat(convergence, 'f', 1, 64))
+	convergenceLabel.SetText(convergenceText(convergence))
 	convergenceSlider.OnChanged = func(value float64) {
-		convergenceLabel.SetText("Convergence Precision: " + strconv.FormatFloat(value, 'f', 1, 64))
+		if value < 0.5 {
+			value = 0
+		}
+		convergenceLabel.SetText(convergenceText(value))
 		if pp.parameterChangeHandler != nil {
 			pp.parameterChangeHandler("convergence_precision", value)
 		}
@@ -298,7 +452,100 @@ func (pp *ParameterPanel) buildTriclassParameters(params map[string]interface{})
 	})
 	parallelCheck.SetChecked(pp.getBoolParam(params, "parallel_processing", true))
 
+	graphCutCheck := widget.NewCheck("Graph-Cut TBD Refinement", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("use_graph_cut", checked)
+		}
+	})
+	graphCutCheck.SetChecked(pp.getBoolParam(params, "use_graph_cut", false))
+
+	// Morphological cleanup kernels; the slider steps keep sizes odd
+	smallKernelSlider := widget.NewSlider(1, 9)
+	smallKernelSlider.Step = 2
+	smallKernel := pp.getIntParam(params, "cleanup_kernel_small", 3)
+	smallKernelSlider.SetValue(float64(smallKernel))
+	smallKernelLabel := widget.NewLabel("Opening Kernel: " + strconv.Itoa(smallKernel))
+	smallKernelSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		smallKernelLabel.SetText("Opening Kernel: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("cleanup_kernel_small", intValue)
+		}
+	}
+
+	largeKernelSlider := widget.NewSlider(3, 15)
+	largeKernelSlider.Step = 2
+	largeKernel := pp.getIntParam(params, "cleanup_kernel_large", 5)
+	largeKernelSlider.SetValue(float64(largeKernel))
+	largeKernelLabel := widget.NewLabel("Closing Kernel: " + strconv.Itoa(largeKernel))
+	largeKernelSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		largeKernelLabel.SetText("Closing Kernel: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("cleanup_kernel_large", intValue)
+		}
+	}
+
+	// Derived kernels override the sliders above
+	adaptiveKernelsCheck := widget.NewCheck("Kernel Size from Resolution", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("adaptive_cleanup_kernels", checked)
+		}
+	})
+	adaptiveKernelsCheck.SetChecked(pp.getBoolParam(params, "adaptive_cleanup_kernels", false))
+
+	cleanupIterSlider := widget.NewSlider(1, 5)
+	cleanupIter := pp.getIntParam(params, "cleanup_iterations", 1)
+	cleanupIterSlider.SetValue(float64(cleanupIter))
+	cleanupIterLabel := widget.NewLabel("Cleanup Iterations: " + strconv.Itoa(cleanupIter))
+	cleanupIterSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		cleanupIterLabel.SetText("Cleanup Iterations: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("cleanup_iterations", intValue)
+		}
+	}
+
+	// Object area limits; an explicit minimum area in pixels wins over the
+	// fraction of the image, and zero disables either bound
+	minAreaEntry := pp.newAreaEntry(params, "min_object_area")
+	maxAreaEntry := pp.newAreaEntry(params, "max_object_area")
+
+	areaFractionSlider := widget.NewSlider(0.0, 0.05)
+	areaFractionSlider.Step = 0.0005
+	areaFraction := pp.getFloatParam(params, "min_object_area_fraction", 0.001)
+	areaFractionSlider.SetValue(areaFraction)
+	areaFractionLabel := widget.NewLabel("Min Object Area Fraction: " + strconv.FormatFloat(areaFraction, 'f', 4, 64))
+	areaFractionSlider.OnChanged = func(value float64) {
+		areaFractionLabel.SetText("Min Object Area Fraction: " + strconv.FormatFloat(value, 'f', 4, 64))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("min_object_area_fraction", value)
+		}
+	}
+
+	// Watershed separation runs after the cleanup
+	watershedCheck := widget.NewCheck("Separate Touching Objects", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("apply_watershed", checked)
+		}
+	})
+	watershedCheck.SetChecked(pp.getBoolParam(params, "apply_watershed", false))
+
+	minDistanceSlider := widget.NewSlider(1, 50)
+	minDistance := pp.getIntParam(params, "watershed_min_distance", 5)
+	minDistanceSlider.SetValue(float64(minDistance))
+	minDistanceLabel := widget.NewLabel("Watershed Min Distance: " + strconv.Itoa(minDistance))
+	minDistanceSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		minDistanceLabel.SetText("Watershed Min Distance: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("watershed_min_distance", intValue)
+		}
+	}
+
 	// Store widgets for updates
+	channelSelect := pp.newChannelSelect(params)
+	pp.parameterWidgets["channel_selection"] = channelSelect
 	pp.parameterWidgets["initial_threshold_method"] = initialMethod
 	pp.parameterWidgets["max_iterations"] = maxIterSlider
 	pp.parameterWidgets["convergence_precision"] = convergenceSlider
@@ -308,11 +555,25 @@ func (pp *ParameterPanel) buildTriclassParameters(params map[string]interface{})
 	pp.parameterWidgets["noise_robustness"] = noiseRobustnessCheck
 	pp.parameterWidgets["guided_filtering"] = guidedFilteringCheck
 	pp.parameterWidgets["parallel_processing"] = parallelCheck
+	pp.parameterWidgets["use_graph_cut"] = graphCutCheck
+
+	globalEqualizationCheck := pp.newGlobalEqualizationCheck(params)
+	pp.parameterWidgets["apply_global_equalization"] = globalEqualizationCheck
+	pp.parameterWidgets["cleanup_kernel_small"] = smallKernelSlider
+	pp.parameterWidgets["cleanup_kernel_large"] = largeKernelSlider
+	pp.parameterWidgets["cleanup_iterations"] = cleanupIterSlider
+	pp.parameterWidgets["adaptive_cleanup_kernels"] = adaptiveKernelsCheck
+	pp.parameterWidgets["min_object_area"] = minAreaEntry
+	pp.parameterWidgets["min_object_area_fraction"] = areaFractionSlider
+	pp.parameterWidgets["max_object_area"] = maxAreaEntry
+	pp.parameterWidgets["apply_watershed"] = watershedCheck
+	pp.parameterWidgets["watershed_min_distance"] = minDistanceSlider
 
 	// Layout parameter groups
 	algorithmGroup := widget.NewCard("Algorithm Parameters", "",
 		container.NewVBox(
 			container.NewVBox(widget.NewLabel("Initial Method"), initialMethod),
+			container.NewVBox(widget.NewLabel("Channel"), channelSelect),
 			container.NewVBox(maxIterLabel, maxIterSlider),
 			container.NewVBox(convergenceLabel, convergenceSlider),
 			container.NewVBox(classSeparationLabel, classSeparationSlider),
@@ -323,8 +584,11 @@ func (pp *ParameterPanel) buildTriclassParameters(params map[string]interface{})
 		container.NewVBox(
 			preprocessingCheck,
 			cleanupCheck,
+			globalEqualizationCheck,
 			noiseRobustnessCheck,
+			pp.newUnsharpControls(params),
 			guidedFilteringCheck,
+			graphCutCheck,
 		),
 	)
 
@@ -332,13 +596,396 @@ func (pp *ParameterPanel) buildTriclassParameters(params map[string]interface{})
 		container.NewVBox(parallelCheck),
 	)
 
+	postProcessingGroup := widget.NewAccordion(widget.NewAccordionItem("Post-Processing",
+		container.NewVBox(
+			container.NewVBox(smallKernelLabel, smallKernelSlider),
+			container.NewVBox(largeKernelLabel, largeKernelSlider),
+			adaptiveKernelsCheck,
+			container.NewVBox(cleanupIterLabel, cleanupIterSlider),
+			container.NewVBox(widget.NewLabel("Min Object Area (pixels, 0 = use fraction)"), minAreaEntry),
+			container.NewVBox(areaFractionLabel, areaFractionSlider),
+			container.NewVBox(widget.NewLabel("Max Object Area (pixels, 0 = unlimited)"), maxAreaEntry),
+			watershedCheck,
+			container.NewVBox(minDistanceLabel, minDistanceSlider),
+		),
+	))
+
 	pp.parametersContent.Add(algorithmGroup)
 	pp.parametersContent.Add(processingGroup)
+	pp.parametersContent.Add(postProcessingGroup)
 	pp.parametersContent.Add(performanceGroup)
 }
 
+// buildRidlerCalvardParameters creates parameter controls for Ridler-Calvard algorithm
+func (pp *ParameterPanel) buildRidlerCalvardParameters(params map[string]interface{}) {
+	// Convergence epsilon
+	epsilonSlider := widget.NewSlider(0.01, 10.0)
+	epsilonSlider.Step = 0.01
+	epsilonLabel := widget.NewLabel("Convergence Epsilon: 0.50")
+	epsilon := pp.getFloatParam(params, "convergence_epsilon", 0.5)
+	epsilonSlider.SetValue(epsilon)
+	epsilonLabel.SetText("Convergence Epsilon: " + strconv.FormatFloat(epsilon, 'f', 2, 64))
+	epsilonSlider.OnChanged = func(value float64) {
+		epsilonLabel.SetText("Convergence Epsilon: " + strconv.FormatFloat(value, 'f', 2, 64))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("convergence_epsilon", value)
+		}
+	}
+
+	// Max iterations
+	maxIterSlider := widget.NewSlider(1, 1000)
+	maxIterLabel := widget.NewLabel("Max Iterations: 100")
+	maxIter := pp.getIntParam(params, "max_iterations", 100)
+	maxIterSlider.SetValue(float64(maxIter))
+	maxIterLabel.SetText("Max Iterations: " + strconv.Itoa(maxIter))
+	maxIterSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		maxIterLabel.SetText("Max Iterations: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("max_iterations", intValue)
+		}
+	}
+
+	// Boolean parameters
+	gaussianCheck := widget.NewCheck("Gaussian Preprocessing", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("gaussian_preprocessing", checked)
+		}
+	})
+	gaussianCheck.SetChecked(pp.getBoolParam(params, "gaussian_preprocessing", false))
+
+	cleanupCheck := widget.NewCheck("Result Cleanup", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("result_cleanup", checked)
+		}
+	})
+	cleanupCheck.SetChecked(pp.getBoolParam(params, "result_cleanup", false))
+
+	// Store widgets for updates
+	pp.parameterWidgets["convergence_epsilon"] = epsilonSlider
+	pp.parameterWidgets["max_iterations"] = maxIterSlider
+	pp.parameterWidgets["gaussian_preprocessing"] = gaussianCheck
+	pp.parameterWidgets["result_cleanup"] = cleanupCheck
+
+	// Layout parameter groups
+	algorithmGroup := widget.NewCard("Algorithm Parameters", "",
+		container.NewVBox(
+			container.NewVBox(epsilonLabel, epsilonSlider),
+			container.NewVBox(maxIterLabel, maxIterSlider),
+		),
+	)
+
+	processingGroup := widget.NewCard("Processing Options", "",
+		container.NewVBox(
+			gaussianCheck,
+			cleanupCheck,
+		),
+	)
+
+	pp.parametersContent.Add(algorithmGroup)
+	pp.parametersContent.Add(processingGroup)
+}
+
+// buildMinCrossEntropyParameters creates parameter controls for Minimum Cross-Entropy algorithm
+func (pp *ParameterPanel) buildMinCrossEntropyParameters(params map[string]interface{}) {
+	// Search quality
+	qualitySelect := widget.NewSelect([]string{"Fast", "Best"}, func(value string) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("quality", value)
+		}
+	})
+	qualitySelect.SetSelected(pp.getStringParam(params, "quality", "Best"))
+
+	// Histogram bins
+	histBinsSlider := widget.NewSlider(8, 256)
+	histBinsLabel := widget.NewLabel("Histogram Bins: 256")
+	histBins := pp.getIntParam(params, "histogram_bins", 256)
+	histBinsSlider.SetValue(float64(histBins))
+	histBinsLabel.SetText("Histogram Bins: " + strconv.Itoa(histBins))
+	histBinsSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		histBinsLabel.SetText("Histogram Bins: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("histogram_bins", intValue)
+		}
+	}
+
+	// Max iterations
+	maxIterSlider := widget.NewSlider(1, 500)
+	maxIterLabel := widget.NewLabel("Max Iterations: 50")
+	maxIter := pp.getIntParam(params, "max_iterations", 50)
+	maxIterSlider.SetValue(float64(maxIter))
+	maxIterLabel.SetText("Max Iterations: " + strconv.Itoa(maxIter))
+	maxIterSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		maxIterLabel.SetText("Max Iterations: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("max_iterations", intValue)
+		}
+	}
+
+	claheCheck := widget.NewCheck("CLAHE Enhancement", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("apply_clahe", checked)
+		}
+	})
+	claheCheck.SetChecked(pp.getBoolParam(params, "apply_clahe", false))
+
+	// Store widgets for updates
+	pp.parameterWidgets["quality"] = qualitySelect
+	pp.parameterWidgets["histogram_bins"] = histBinsSlider
+	pp.parameterWidgets["max_iterations"] = maxIterSlider
+	pp.parameterWidgets["apply_clahe"] = claheCheck
+
+	// Layout parameter groups
+	algorithmGroup := widget.NewCard("Algorithm Parameters", "",
+		container.NewVBox(
+			container.NewVBox(widget.NewLabel("Quality"), qualitySelect),
+			container.NewVBox(histBinsLabel, histBinsSlider),
+			container.NewVBox(maxIterLabel, maxIterSlider),
+		),
+	)
+
+	processingGroup := widget.NewCard("Processing Options", "",
+		container.NewVBox(claheCheck),
+	)
+
+	pp.parametersContent.Add(algorithmGroup)
+	pp.parametersContent.Add(processingGroup)
+}
+
+// buildMultiLevelOtsuParameters creates parameter controls for Multi-Level Otsu algorithm
+func (pp *ParameterPanel) buildMultiLevelOtsuParameters(params map[string]interface{}) {
+	// Number of classes
+	numClassesSlider := widget.NewSlider(2, 5)
+	numClassesLabel := widget.NewLabel("Classes: 3")
+	numClasses := pp.getIntParam(params, "num_classes", 3)
+	numClassesSlider.SetValue(float64(numClasses))
+	numClassesLabel.SetText("Classes: " + strconv.Itoa(numClasses))
+	numClassesSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		numClassesLabel.SetText("Classes: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("num_classes", intValue)
+		}
+	}
+
+	// Store widgets for updates
+	pp.parameterWidgets["num_classes"] = numClassesSlider
+
+	// Layout parameter groups
+	algorithmGroup := widget.NewCard("Algorithm Parameters", "",
+		container.NewVBox(
+			container.NewVBox(numClassesLabel, numClassesSlider),
+		),
+	)
+
+	pp.parametersContent.Add(algorithmGroup)
+}
+
+// buildHSVOtsuParameters creates parameter controls for HSV Otsu algorithm
+func (pp *ParameterPanel) buildHSVOtsuParameters(params map[string]interface{}) {
+	// Thresholded channel
+	channelSelect := widget.NewSelect([]string{"H", "S", "V"}, func(value string) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("color_threshold_channel", value)
+		}
+	})
+	channelSelect.SetSelected(pp.getStringParam(params, "color_threshold_channel", "S"))
+
+	// Hue range
+	lowerHueSlider := widget.NewSlider(0, 179)
+	lowerHue := pp.getIntParam(params, "lower_hue", 0)
+	lowerHueSlider.SetValue(float64(lowerHue))
+	lowerHueLabel := widget.NewLabel("Lower Hue: " + strconv.Itoa(lowerHue))
+	lowerHueSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		lowerHueLabel.SetText("Lower Hue: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("lower_hue", intValue)
+		}
+	}
+
+	upperHueSlider := widget.NewSlider(0, 179)
+	upperHue := pp.getIntParam(params, "upper_hue", 179)
+	upperHueSlider.SetValue(float64(upperHue))
+	upperHueLabel := widget.NewLabel("Upper Hue: " + strconv.Itoa(upperHue))
+	upperHueSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		upperHueLabel.SetText("Upper Hue: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("upper_hue", intValue)
+		}
+	}
+
+	// Store widgets for updates
+	pp.parameterWidgets["color_threshold_channel"] = channelSelect
+	pp.parameterWidgets["lower_hue"] = lowerHueSlider
+	pp.parameterWidgets["upper_hue"] = upperHueSlider
+
+	// Layout parameter groups
+	algorithmGroup := widget.NewCard("Algorithm Parameters", "",
+		container.NewVBox(
+			container.NewVBox(widget.NewLabel("Threshold Channel"), channelSelect),
+			container.NewVBox(lowerHueLabel, lowerHueSlider),
+			container.NewVBox(upperHueLabel, upperHueSlider),
+		),
+	)
+
+	pp.parametersContent.Add(algorithmGroup)
+}
+
 // Parameter helper functions
 
+// newChannelSelect creates the selector for the colour channel that is thresholded
+func (pp *ParameterPanel) newChannelSelect(params map[string]interface{}) *widget.Select {
+	channelSelect := widget.NewSelect([]string{"luminance", "red", "green", "blue", "hue", "saturation"}, nil)
+	channelSelect.SetSelected(pp.getStringParam(params, "channel_selection", "luminance"))
+	channelSelect.OnChanged = func(value string) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("channel_selection", value)
+		}
+	}
+	return channelSelect
+}
+
+// newGlobalEqualizationCheck creates the global histogram equalisation toggle,
+// shared by the 2D Otsu and Iterative Triclass layouts
+func (pp *ParameterPanel) newGlobalEqualizationCheck(params map[string]interface{}) *widget.Check {
+	check := widget.NewCheck("Global Histogram Equalization", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("apply_global_equalization", checked)
+		}
+	})
+	check.SetChecked(pp.getBoolParam(params, "apply_global_equalization", false))
+	return check
+}
+
+// newUnsharpControls builds the unsharp mask toggle with its strength and
+// radius sliders, shared by the 2D Otsu and Iterative Triclass layouts
+func (pp *ParameterPanel) newUnsharpControls(params map[string]interface{}) *fyne.Container {
+	applyUnsharpCheck := widget.NewCheck("Unsharp Mask Sharpening", func(checked bool) {
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("apply_unsharp", checked)
+		}
+	})
+	applyUnsharpCheck.SetChecked(pp.getBoolParam(params, "apply_unsharp", false))
+
+	strengthSlider := widget.NewSlider(0.0, 2.0)
+	strengthSlider.Step = 0.1
+	strength := pp.getFloatParam(params, "unsharp_strength", 0.5)
+	strengthSlider.SetValue(strength)
+	strengthLabel := widget.NewLabel("Sharpening Strength: " + strconv.FormatFloat(strength, 'f', 1, 64))
+	strengthSlider.OnChanged = func(value float64) {
+		strengthLabel.SetText("Sharpening Strength: " + strconv.FormatFloat(value, 'f', 1, 64))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("unsharp_strength", value)
+		}
+	}
+
+	radiusSlider := widget.NewSlider(1, 5)
+	radius := pp.getIntParam(params, "unsharp_radius", 2)
+	radiusSlider.SetValue(float64(radius))
+	radiusLabel := widget.NewLabel("Sharpening Radius: " + strconv.Itoa(radius))
+	radiusSlider.OnChanged = func(value float64) {
+		intValue := int(value)
+		radiusLabel.SetText("Sharpening Radius: " + strconv.Itoa(intValue))
+		if pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler("unsharp_radius", intValue)
+		}
+	}
+
+	pp.parameterWidgets["apply_unsharp"] = applyUnsharpCheck
+	pp.parameterWidgets["unsharp_strength"] = strengthSlider
+	pp.parameterWidgets["unsharp_radius"] = radiusSlider
+
+	return container.NewVBox(
+		applyUnsharpCheck,
+		container.NewVBox(strengthLabel, strengthSlider),
+		container.NewVBox(radiusLabel, radiusSlider),
+	)
+}
+
+// buildGenericParameters creates plain controls for algorithms without a
+// dedicated layout, such as plugins. Numeric and string values are edited as
+// text and keep their original type.
+func (pp *ParameterPanel) buildGenericParameters(params map[string]interface{}) {
+	if len(params) == 0 {
+		pp.parametersContent.Add(widget.NewLabel("No adjustable parameters"))
+		return
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	controls := container.NewVBox()
+	for _, name := range names {
+		paramName := name
+		switch value := params[paramName].(type) {
+		case bool:
+			check := widget.NewCheck(paramName, nil)
+			check.SetChecked(value)
+			check.OnChanged = func(checked bool) {
+				if pp.parameterChangeHandler != nil {
+					pp.parameterChangeHandler(paramName, checked)
+				}
+			}
+			pp.parameterWidgets[paramName] = check
+			controls.Add(check)
+		case int, float64, string:
+			entry := widget.NewEntry()
+			entry.SetText(fmt.Sprint(value))
+			entry.OnSubmitted = func(text string) {
+				parsed, ok := parseLike(value, text)
+				if ok && pp.parameterChangeHandler != nil {
+					pp.parameterChangeHandler(paramName, parsed)
+				}
+			}
+			pp.parameterWidgets[paramName] = entry
+			controls.Add(container.NewVBox(widget.NewLabel(paramName+":"), entry))
+		}
+	}
+
+	pp.parametersContent.Add(widget.NewCard("Algorithm Parameters", "", controls))
+}
+
+// newAreaEntry creates an entry for an object area in pixels, reporting
+// non-negative whole numbers when submitted
+func (pp *ParameterPanel) newAreaEntry(params map[string]interface{}, key string) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetText(strconv.Itoa(pp.getIntParam(params, key, 0)))
+	entry.Validator = func(text string) error {
+		if value, err := strconv.Atoi(text); err != nil || value < 0 {
+			return fmt.Errorf("enter a whole number of pixels, 0 to disable")
+		}
+		return nil
+	}
+	entry.OnSubmitted = func(text string) {
+		value, err := strconv.Atoi(text)
+		if err == nil && value >= 0 && pp.parameterChangeHandler != nil {
+			pp.parameterChangeHandler(key, value)
+		}
+	}
+	return entry
+}
+
+// parseLike parses text into the same type as the original value
+func parseLike(original interface{}, text string) (interface{}, bool) {
+	switch original.(type) {
+	case int:
+		value, err := strconv.Atoi(text)
+		return value, err == nil
+	case float64:
+		value, err := strconv.ParseFloat(text, 64)
+		return value, err == nil
+	default:
+		return text, true
+	}
+}
+
 // getIntParam safely extracts an integer parameter
 func (pp *ParameterPanel) getIntParam(params map[string]interface{}, key string, defaultValue int) int {
 	if value, ok := params[key].(int); ok {
@@ -371,6 +1018,61 @@ func (pp *ParameterPanel) getBoolParam(params map[string]interface{}, key string
 	return defaultValue
 }
 
+// weightMapLabel describes the weight map at path by its file name
+func weightMapLabel(path string) string {
+	if path == "" {
+		return "Weight Map: None"
+	}
+	return "Weight Map: " + filepath.Base(path)
+}
+
+// SetConvergenceData plots the per-iteration history of the last run
+func (pp *ParameterPanel) SetConvergenceData(records []convergence.ConvergenceRecord) {
+	pp.convergencePlot.SetData(records)
+}
+
+// SetPresets replaces the saved preset names listed in the presets dropdown
+func (pp *ParameterPanel) SetPresets(names []string) {
+	fyne.Do(func() {
+		pp.presetSelect.SetOptions(append(slices.Clone(names), savePresetOption))
+		if !slices.Contains(names, pp.presetSelect.Selected) {
+			pp.presetSelect.ClearSelected()
+		}
+	})
+}
+
+// ApplyPreset fills the parameter fields with params and reports every value
+// through the parameter change handler. The panel must already show the
+// preset's algorithm.
+func (pp *ParameterPanel) ApplyPreset(params map[string]interface{}) {
+	fyne.Do(func() {
+		pp.updateValues(params)
+		if pp.parameterChangeHandler == nil {
+			return
+		}
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			pp.parameterChangeHandler(name, params[name])
+		}
+	})
+}
+
+// SetPresetHandlers sets the handlers for choosing a saved preset and for
+// the Save Current option
+func (pp *ParameterPanel) SetPresetHandlers(load func(string), save func()) {
+	pp.presetLoadHandler = load
+	pp.presetSaveHandler = save
+}
+
+// SetWeightMapHandler sets the handler for the 2D Otsu Load Weight Map button
+func (pp *ParameterPanel) SetWeightMapHandler(handler func()) {
+	pp.weightMapHandler = handler
+}
+
 // SetParameterChangeHandler sets the handler for parameter changes
 func (pp *ParameterPanel) SetParameterChangeHandler(handler func(string, interface{})) {
 	pp.parameterChangeHandler = handler
@@ -394,6 +1096,8 @@ func (pp *ParameterPanel) Reset() {
 		pp.parameterWidgets = make(map[string]fyne.CanvasObject)
 		pp.parameterCount = 0
 		pp.currentAlgorithm = ""
+		pp.convergencePlot.setData(nil)
+		pp.convergenceSection.Hide()
 		pp.container.Refresh()
 	})
 }
@@ -0,0 +1,55 @@
+package components
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// SensitivityBarWidth is the length of the bar of the most sensitive parameter
+const SensitivityBarWidth = 220
+
+var (
+	sensitivityBarColor     = color.RGBA{R: 38, G: 139, B: 210, A: 255}
+	sensitivityHighestColor = color.RGBA{R: 203, G: 75, B: 22, A: 255}
+)
+
+// NewSensitivityChart returns a horizontal bar chart of parameter slopes,
+// already ranked by the caller, with bar lengths proportional to the absolute
+// slope. The first, most sensitive, parameter is highlighted.
+func NewSensitivityChart(names []string, slopes []float64) fyne.CanvasObject {
+	largest := 0.0
+	for _, slope := range slopes {
+		largest = max(largest, math.Abs(slope))
+	}
+
+	// Form layout lines the bars up after the widest parameter name
+	rows := container.New(layout.NewFormLayout())
+	for i, name := range names {
+		barColor := sensitivityBarColor
+		label := widget.NewLabel(name)
+		if i == 0 {
+			barColor = sensitivityHighestColor
+			label.TextStyle = fyne.TextStyle{Bold: true}
+		}
+
+		width := float32(1)
+		if largest > 0 {
+			width = max(width, float32(math.Abs(slopes[i])/largest*SensitivityBarWidth))
+		}
+		bar := canvas.NewRectangle(barColor)
+		bar.SetMinSize(fyne.NewSize(width, 14))
+
+		value := widget.NewLabel(fmt.Sprintf("%+.3f", slopes[i]))
+		rows.Add(label)
+		rows.Add(container.NewHBox(container.NewCenter(bar), value))
+	}
+
+	return rows
+}
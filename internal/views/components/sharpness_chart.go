@@ -0,0 +1,134 @@
+package components
+
+import (
+	"image"
+	"image/color"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	SharpnessChartWidth  = 160
+	SharpnessChartHeight = 36
+
+	sharpnessChartMargin = 3
+)
+
+var (
+	sharpnessCurveColor   = color.RGBA{R: 38, G: 139, B: 210, A: 255}
+	sharpnessCurrentColor = color.RGBA{R: 203, G: 75, B: 22, A: 255}
+	sharpnessBestColor    = color.RGBA{R: 133, G: 153, B: 0, A: 255}
+)
+
+// SharpnessChart is a sparkline of the focus score of every slice in a
+// volume stack, marking the sharpest slice and the one shown
+type SharpnessChart struct {
+	widget.BaseWidget
+
+	raster *canvas.Raster
+
+	mu      sync.RWMutex
+	scores  []float64
+	current int
+}
+
+// NewSharpnessChart creates an empty sharpness chart
+func NewSharpnessChart() *SharpnessChart {
+	chart := &SharpnessChart{}
+	chart.raster = canvas.NewRaster(chart.draw)
+	chart.raster.SetMinSize(fyne.NewSize(SharpnessChartWidth, SharpnessChartHeight))
+	chart.ExtendBaseWidget(chart)
+	return chart
+}
+
+// CreateRenderer renders the chart raster
+func (sc *SharpnessChart) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(sc.raster)
+}
+
+// SetScores replaces the plotted scores, one per slice; nil clears the chart.
+// Call on the UI goroutine.
+func (sc *SharpnessChart) SetScores(scores []float64) {
+	sc.mu.Lock()
+	sc.scores = append([]float64(nil), scores...)
+	sc.mu.Unlock()
+
+	sc.raster.Refresh()
+}
+
+// SetCurrent marks the zero-based slice currently shown. Call on the UI goroutine.
+func (sc *SharpnessChart) SetCurrent(slice int) {
+	sc.mu.Lock()
+	sc.current = slice
+	sc.mu.Unlock()
+
+	sc.raster.Refresh()
+}
+
+// draw renders the curve scaled between the lowest and highest score
+func (sc *SharpnessChart) draw(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 {
+		return img
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, convergenceBackground)
+		}
+	}
+
+	left, right := sharpnessChartMargin, w-sharpnessChartMargin-1
+	top, bottom := sharpnessChartMargin, h-sharpnessChartMargin-1
+	if right <= left || bottom <= top {
+		return img
+	}
+
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	if len(sc.scores) < 2 {
+		return img
+	}
+
+	low, high := sc.scores[0], sc.scores[0]
+	best := 0
+	for i, score := range sc.scores {
+		low = min(low, score)
+		if score > high {
+			high = score
+			best = i
+		}
+	}
+
+	xFor := func(i int) int {
+		return left + i*(right-left)/(len(sc.scores)-1)
+	}
+	yFor := func(score float64) int {
+		if high == low {
+			return (top + bottom) / 2
+		}
+		return bottom - int((score-low)/(high-low)*float64(bottom-top))
+	}
+
+	// Vertical guides for the sharpest and the current slice
+	for y := top; y <= bottom; y++ {
+		img.SetRGBA(xFor(best), y, sharpnessBestColor)
+	}
+	if sc.current >= 0 && sc.current < len(sc.scores) {
+		for y := top; y <= bottom; y++ {
+			img.SetRGBA(xFor(sc.current), y, sharpnessCurrentColor)
+		}
+	}
+
+	points := make([]image.Point, len(sc.scores))
+	for i, score := range sc.scores {
+		points[i] = image.Pt(xFor(i), yFor(score))
+	}
+	drawPolyline(img, points, sharpnessCurveColor)
+
+	return img
+}
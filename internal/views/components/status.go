@@ -2,6 +2,9 @@ package components
 
 import (
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -10,10 +13,16 @@ import (
 
 // StatusBar displays application status and information
 type StatusBar struct {
-	container    *fyne.Container
-	statusLabel  *widget.Label
-	imageInfo    *widget.Label
-	memoryInfo   *widget.Label
+	container     *fyne.Container
+	statusLabel   *widget.Label
+	imageInfo     *widget.Label
+	memoryGauge   *MemoryGauge
+	toastLabel    *widget.Label
+	metadataForm  *widget.Form
+	metadataPanel *widget.Accordion
+
+	toastMu    sync.Mutex
+	toastTimer *time.Timer
 }
 
 // NewStatusBar creates a new status bar component
@@ -28,7 +37,16 @@ func NewStatusBar() *StatusBar {
 func (sb *StatusBar) createComponents() {
 	sb.statusLabel = widget.NewLabel("Ready")
 	sb.imageInfo = widget.NewLabel("No image loaded")
-	sb.memoryInfo = widget.NewLabel("Memory: --")
+	sb.memoryGauge = NewMemoryGauge()
+
+	sb.toastLabel = widget.NewLabel("")
+	sb.toastLabel.Importance = widget.WarningImportance
+	sb.toastLabel.TextStyle = fyne.TextStyle{Bold: true}
+	sb.toastLabel.Hide()
+
+	sb.metadataForm = widget.NewForm()
+	sb.metadataPanel = widget.NewAccordion(widget.NewAccordionItem("DICOM Info", sb.metadataForm))
+	sb.metadataPanel.Hide()
 }
 
 // buildLayout constructs the status bar layout
@@ -38,7 +56,9 @@ func (sb *StatusBar) buildLayout() {
 		widget.NewSeparator(),
 		sb.imageInfo,
 		widget.NewSeparator(),
-		sb.memoryInfo,
+		sb.memoryGauge.GetContainer(),
+		sb.toastLabel,
+		sb.metadataPanel,
 	)
 }
 
@@ -62,13 +82,54 @@ func (sb *StatusBar) SetImageInfo(width, height, channels int, format string) {
 	})
 }
 
-// SetMemoryInfo updates the memory usage display
+// SetMemoryInfo updates the memory usage gauge
 func (sb *StatusBar) SetMemoryInfo(used, total int64) {
+	sb.memoryGauge.SetUsage(used, total)
+}
+
+// ShowToast shows message in the status bar for duration without blocking.
+// A new toast replaces one that is still showing.
+func (sb *StatusBar) ShowToast(message string, duration time.Duration) {
+	sb.toastMu.Lock()
+	defer sb.toastMu.Unlock()
+
+	if sb.toastTimer != nil {
+		sb.toastTimer.Stop()
+	}
+
 	fyne.Do(func() {
-		usedMB := used / (1024 * 1024)
-		totalMB := total / (1024 * 1024)
-		info := fmt.Sprintf("Memory: %d/%d MB", usedMB, totalMB)
-		sb.memoryInfo.SetText(info)
+		sb.toastLabel.SetText(message)
+		sb.toastLabel.Show()
+	})
+
+	sb.toastTimer = time.AfterFunc(duration, func() {
+		fyne.Do(func() {
+			sb.toastLabel.Hide()
+		})
+	})
+}
+
+// SetMetadata lists header attributes in the collapsible info panel,
+// which is hidden when tags is empty
+func (sb *StatusBar) SetMetadata(tags map[string]string) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fyne.Do(func() {
+		sb.metadataForm.Items = nil
+		for _, key := range keys {
+			sb.metadataForm.Append(key, widget.NewLabel(tags[key]))
+		}
+		sb.metadataForm.Refresh()
+
+		if len(keys) == 0 {
+			sb.metadataPanel.Hide()
+		} else {
+			sb.metadataPanel.Show()
+		}
 	})
 }
 
@@ -77,8 +138,12 @@ func (sb *StatusBar) Reset() {
 	fyne.Do(func() {
 		sb.statusLabel.SetText("Ready")
 		sb.imageInfo.SetText("No image loaded")
-		sb.memoryInfo.SetText("Memory: --")
+		sb.toastLabel.Hide()
+		sb.metadataForm.Items = nil
+		sb.metadataForm.Refresh()
+		sb.metadataPanel.Hide()
 	})
+	sb.memoryGauge.Reset()
 }
 
 // GetContainer returns the status bar container
@@ -93,12 +158,26 @@ func (sb *StatusBar) Refresh() {
 	})
 }
 
+// MaxDisplayedETA caps the remaining time shown; the first estimates for a
+// large image are often far too high and would only alarm
+const MaxDisplayedETA = 10 * time.Minute
+
+// etaSettleProgress is the fraction complete below which the estimate is
+// still too unsteady to show
+const etaSettleProgress = 0.05
+
 // ProgressBar displays processing progress with stage information
 type ProgressBar struct {
 	container   *fyne.Container
 	progressBar *widget.ProgressBar
 	stageLabel  *widget.Label
 	visible     bool
+
+	// Label state; only touched on the UI goroutine
+	stage    string
+	progress float64
+	eta      time.Duration
+	hasETA   bool
 }
 
 // NewProgressBar creates a new progress bar component
@@ -114,6 +193,7 @@ func (pb *ProgressBar) createComponents() {
 	pb.progressBar = widget.NewProgressBar()
 	pb.progressBar.SetValue(0.0)
 	pb.stageLabel = widget.NewLabel("Ready")
+	pb.stage = "Ready"
 	pb.visible = false
 }
 
@@ -135,6 +215,8 @@ func (pb *ProgressBar) SetProgress(progress float64) {
 			progress = 1.0
 		}
 		pb.progressBar.SetValue(progress)
+		pb.progress = progress
+		pb.updateLabel()
 	})
 }
 
@@ -146,27 +228,72 @@ func (pb *ProgressBar) GetProgress() float64 {
 // SetStage updates the current processing stage
 func (pb *ProgressBar) SetStage(stage string) {
 	fyne.Do(func() {
-		pb.stageLabel.SetText(stage)
+		pb.stage = stage
+		pb.updateLabel()
 	})
 }
 
 // GetStage returns the current stage
 func (pb *ProgressBar) GetStage() string {
-	return pb.stageLabel.Text
+	return pb.stage
+}
+
+// SetETA sets the estimated time remaining; a negative eta means there is no
+// estimate and hides it
+func (pb *ProgressBar) SetETA(eta time.Duration) {
+	fyne.Do(func() {
+		pb.eta = eta
+		pb.hasETA = eta >= 0
+		pb.updateLabel()
+	})
+}
+
+// updateLabel shows the stage, and while it runs the percentage and time
+// remaining as "Stage: X — 35% — ETA: 12s". It must run on the UI goroutine.
+func (pb *ProgressBar) updateLabel() {
+	if !pb.hasETA {
+		pb.stageLabel.SetText(pb.stage)
+		return
+	}
+
+	text := fmt.Sprintf("Stage: %s — %.0f%%", pb.stage, pb.progress*100)
+	switch {
+	case pb.progress < etaSettleProgress:
+		text += " — ETA: calculating..."
+	case pb.eta > MaxDisplayedETA:
+		text += fmt.Sprintf(" — ETA: over %s", formatETA(MaxDisplayedETA))
+	default:
+		text += " — ETA: " + formatETA(pb.eta)
+	}
+	pb.stageLabel.SetText(text)
+}
+
+// formatETA renders a duration to the second, as "12s" or "3m5s"
+func formatETA(eta time.Duration) string {
+	eta = eta.Round(time.Second)
+	if eta >= time.Minute && eta%time.Minute == 0 {
+		return fmt.Sprintf("%dm", eta/time.Minute)
+	}
+	return eta.String()
 }
 
 // SetVisible shows or hides the progress bar
 func (pb *ProgressBar) SetVisible(visible bool) {
 	fyne.Do(func() {
-		pb.visible = visible
-		if visible {
-			pb.container.Show()
-		} else {
-			pb.container.Hide()
-		}
+		pb.setVisible(visible)
 	})
 }
 
+// setVisible shows or hides the bar; it must run on the UI goroutine
+func (pb *ProgressBar) setVisible(visible bool) {
+	pb.visible = visible
+	if visible {
+		pb.container.Show()
+	} else {
+		pb.container.Hide()
+	}
+}
+
 // IsVisible returns true if the progress bar is visible
 func (pb *ProgressBar) IsVisible() bool {
 	return pb.visible
@@ -176,8 +303,11 @@ func (pb *ProgressBar) IsVisible() bool {
 func (pb *ProgressBar) Reset() {
 	fyne.Do(func() {
 		pb.progressBar.SetValue(0.0)
-		pb.stageLabel.SetText("Ready")
-		pb.SetVisible(false)
+		pb.stage = "Ready"
+		pb.progress = 0
+		pb.hasETA = false
+		pb.updateLabel()
+		pb.setVisible(false)
 	})
 }
 
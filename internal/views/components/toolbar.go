@@ -2,6 +2,8 @@ package components
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
@@ -13,21 +15,53 @@ type Toolbar struct {
 	container               *fyne.Container
 	loadButton              *widget.Button
 	saveButton              *widget.Button
+	groundTruthButton       *widget.Button
 	processButton           *widget.Button
 	cancelButton            *widget.Button
+	clearQueueButton        *widget.Button
+	autoTuneButton          *widget.Button
+	fullResolutionCheck     *widget.Check
+	thresholdEntry          *widget.Entry
 	algorithmSelect         *widget.Select
+	compareCheck            *widget.Check
 	metricsLabel            *widget.Label
+	hausdorffLabel          *widget.Label
+	similarityLabel         *widget.Label
+	syncViewsCheck          *widget.Check
+	clearROIButton          *widget.Button
+	rotateButtons           []*widget.Button
+	flipHorizontalButton    *widget.Button
+	flipVerticalButton      *widget.Button
+	prevPageButton          *widget.Button
+	nextPageButton          *widget.Button
+	pageLabel               *widget.Label
+	pageSection             *fyne.Container
 	
 	// Event handlers
 	loadHandler             func()
+	cancelLoadHandler       func()
 	saveHandler             func()
+	groundTruthHandler      func()
 	processHandler          func()
 	cancelHandler           func()
+	clearQueueHandler       func()
+	autoTuneHandler         func()
+	fullResolutionHandler   func(bool)
+	thresholdHandler        func(float64)
 	algorithmChangeHandler  func(string)
+	compareModeHandler      func(bool)
+	syncViewsHandler        func(bool)
+	clearROIHandler         func()
+	rotateHandler           func(int)
+	flipHandler             func(bool)
+	pageChangeHandler       func(int)
 	
 	// State
 	currentAlgorithm        string
 	processingActive        bool
+	loadingActive           bool
+	currentPage             int
+	pageCount               int
 }
 
 // NewToolbar creates a new toolbar component
@@ -49,6 +83,9 @@ func (t *Toolbar) createComponents() {
 	t.saveButton.Importance = widget.HighImportance
 	t.saveButton.Disable()
 	
+	t.groundTruthButton = widget.NewButton("Load Ground Truth", nil)
+	t.groundTruthButton.Importance = widget.MediumImportance
+	
 	t.processButton = widget.NewButton("Process", nil)
 	t.processButton.Importance = widget.HighImportance
 	t.processButton.Disable()
@@ -57,16 +94,64 @@ func (t *Toolbar) createComponents() {
 	t.cancelButton.Importance = widget.MediumImportance
 	t.cancelButton.Disable()
 	
+	t.clearQueueButton = widget.NewButton("Clear Queue", nil)
+	t.clearQueueButton.Importance = widget.LowImportance
+	t.clearQueueButton.Disable()
+	
+	// Searches parameters for the loaded image and fills in the best found
+	t.autoTuneButton = widget.NewButton("Auto-Tune", nil)
+	t.autoTuneButton.Importance = widget.MediumImportance
+	t.autoTuneButton.Disable()
+	
+	t.fullResolutionCheck = widget.NewCheck("Process at full resolution", nil)
+
+	// Threshold computed by the last run; submitting a new value re-applies it
+	t.thresholdEntry = widget.NewEntry()
+	t.thresholdEntry.SetPlaceHolder("Auto")
+	t.thresholdEntry.Disable()
+	
 	// Algorithm selection
 	t.algorithmSelect = widget.NewSelect(
-		[]string{"2D Otsu", "Iterative Triclass"},
+		[]string{"2D Otsu", "Iterative Triclass", "Ridler-Calvard", "Minimum Cross-Entropy", "Multi-Level Otsu", "HSV Otsu"},
 		nil,
 	)
 	t.algorithmSelect.SetSelected("2D Otsu")
 	t.currentAlgorithm = "2D Otsu"
 	
+	// Compare mode runs 2D Otsu and Iterative Triclass side by side
+	t.compareCheck = widget.NewCheck("Compare Mode", nil)
+	
+	// View linking toggle
+	t.syncViewsCheck = widget.NewCheck("Link Views", nil)
+	t.syncViewsCheck.SetChecked(true)
+	
+	t.clearROIButton = widget.NewButton("Clear ROI", nil)
+	t.clearROIButton.Importance = widget.LowImportance
+	t.clearROIButton.Disable()
+	
+	// Manual orientation fixes for images whose EXIF orientation is missing
+	for _, degrees := range []int{90, 180, 270} {
+		button := widget.NewButton(fmt.Sprintf("%d°", degrees), nil)
+		button.Importance = widget.LowImportance
+		button.Disable()
+		t.rotateButtons = append(t.rotateButtons, button)
+	}
+	t.flipHorizontalButton = widget.NewButton("Flip H", nil)
+	t.flipHorizontalButton.Importance = widget.LowImportance
+	t.flipHorizontalButton.Disable()
+	t.flipVerticalButton = widget.NewButton("Flip V", nil)
+	t.flipVerticalButton.Importance = widget.LowImportance
+	t.flipVerticalButton.Disable()
+	
+	// Page navigator for multi-page images
+	t.prevPageButton = widget.NewButton("<", nil)
+	t.nextPageButton = widget.NewButton(">", nil)
+	t.pageLabel = widget.NewLabel("Page 1 / 1")
+	
 	// Metrics display
 	t.metricsLabel = widget.NewLabel("IoU: -- | Dice: -- | Error: --")
+	t.hausdorffLabel = widget.NewLabel("HD: -- | HD95: --")
+	t.similarityLabel = widget.NewLabel("PSNR: -- | SSIM: --")
 }
 
 // buildLayout constructs the toolbar layout
@@ -76,24 +161,50 @@ func (t *Toolbar) buildLayout() {
 		t.loadButton,
 		widget.NewSeparator(),
 		t.saveButton,
+		widget.NewSeparator(),
+		t.groundTruthButton,
 	)
 	
 	// Algorithm section
 	algorithmSection := container.NewVBox(
 		widget.NewLabel("Algorithm"),
 		t.algorithmSelect,
+		t.compareCheck,
 	)
 	
 	// Processing section
 	processSection := container.NewVBox(
 		widget.NewLabel("Processing"),
-		container.NewHBox(t.processButton, t.cancelButton),
+		container.NewHBox(t.processButton, t.cancelButton, t.clearQueueButton, t.autoTuneButton),
+		t.fullResolutionCheck,
+		container.NewBorder(nil, nil, widget.NewLabel("Threshold"), nil, t.thresholdEntry),
 	)
 	
+	// View section
+	viewSection := container.NewVBox(
+		widget.NewLabel("View"),
+		t.syncViewsCheck,
+		t.clearROIButton,
+		container.NewHBox(widget.NewLabel("Rotate"), t.rotateButtons[0], t.rotateButtons[1], t.rotateButtons[2]),
+		container.NewHBox(t.flipHorizontalButton, t.flipVerticalButton),
+	)
+	
+	// Page section, only shown for multi-page images
+	t.pageSection = container.NewHBox(
+		container.NewVBox(
+			widget.NewLabel("Page"),
+			container.NewHBox(t.prevPageButton, t.pageLabel, t.nextPageButton),
+		),
+		widget.NewSeparator(),
+	)
+	t.pageSection.Hide()
+	
 	// Metrics section
 	metricsSection := container.NewVBox(
 		widget.NewLabel("Quality Metrics"),
 		t.metricsLabel,
+		t.hausdorffLabel,
+		t.similarityLabel,
 	)
 	
 	// Main toolbar layout
@@ -104,13 +215,23 @@ func (t *Toolbar) buildLayout() {
 		widget.NewSeparator(),
 		processSection,
 		widget.NewSeparator(),
+		viewSection,
+		widget.NewSeparator(),
+		t.pageSection,
 		metricsSection,
 	)
 }
 
 // setupEventHandlers connects button events
 func (t *Toolbar) setupEventHandlers() {
+	// Load becomes Cancel Load while an image is being read
 	t.loadButton.OnTapped = func() {
+		if t.loadingActive {
+			if t.cancelLoadHandler != nil {
+				t.cancelLoadHandler()
+			}
+			return
+		}
 		if t.loadHandler != nil {
 			t.loadHandler()
 		}
@@ -122,6 +243,12 @@ func (t *Toolbar) setupEventHandlers() {
 		}
 	}
 	
+	t.groundTruthButton.OnTapped = func() {
+		if t.groundTruthHandler != nil {
+			t.groundTruthHandler()
+		}
+	}
+	
 	t.processButton.OnTapped = func() {
 		if t.processHandler != nil {
 			t.processHandler()
@@ -134,12 +261,97 @@ func (t *Toolbar) setupEventHandlers() {
 		}
 	}
 	
+	t.clearQueueButton.OnTapped = func() {
+		if t.clearQueueHandler != nil {
+			t.clearQueueHandler()
+		}
+	}
+	
+	t.autoTuneButton.OnTapped = func() {
+		if t.autoTuneHandler != nil {
+			t.autoTuneHandler()
+		}
+	}
+	
+	t.fullResolutionCheck.OnChanged = func(enabled bool) {
+		if t.fullResolutionHandler != nil {
+			t.fullResolutionHandler(enabled)
+		}
+	}
+	
+	t.thresholdEntry.OnSubmitted = func(text string) {
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil || value < 0 || value > 255 {
+			return
+		}
+		if t.thresholdHandler != nil {
+			t.thresholdHandler(value)
+		}
+	}
+	
 	t.algorithmSelect.OnChanged = func(algorithm string) {
 		t.currentAlgorithm = algorithm
 		if t.algorithmChangeHandler != nil {
 			t.algorithmChangeHandler(algorithm)
 		}
 	}
+	
+	t.compareCheck.OnChanged = func(enabled bool) {
+		// The selected algorithm does not apply while both run
+		if enabled {
+			t.algorithmSelect.Disable()
+		} else {
+			t.algorithmSelect.Enable()
+		}
+		if t.compareModeHandler != nil {
+			t.compareModeHandler(enabled)
+		}
+	}
+	
+	t.clearROIButton.OnTapped = func() {
+		if t.clearROIHandler != nil {
+			t.clearROIHandler()
+		}
+	}
+	
+	for i, button := range t.rotateButtons {
+		degrees := 90 * (i + 1)
+		button.OnTapped = func() {
+			if t.rotateHandler != nil {
+				t.rotateHandler(degrees)
+			}
+		}
+	}
+	
+	t.flipHorizontalButton.OnTapped = func() {
+		if t.flipHandler != nil {
+			t.flipHandler(true)
+		}
+	}
+	
+	t.flipVerticalButton.OnTapped = func() {
+		if t.flipHandler != nil {
+			t.flipHandler(false)
+		}
+	}
+	
+	t.syncViewsCheck.OnChanged = func(enabled bool) {
+		if t.syncViewsHandler != nil {
+			t.syncViewsHandler(enabled)
+		}
+	}
+	
+	t.prevPageButton.OnTapped = func() {
+		if t.pageChangeHandler != nil && t.currentPage > 0 {
+			t.pageChangeHandler(t.currentPage - 1)
+		}
+	}
+	
+	t.nextPageButton.OnTapped = func() {
+		if t.pageChangeHandler != nil && t.currentPage < t.pageCount-1 {
+			t.pageChangeHandler(t.currentPage + 1)
+		}
+	}
 }
 
 // Event handler setters
@@ -149,11 +361,21 @@ func (t *Toolbar) SetLoadHandler(handler func()) {
 	t.loadHandler = handler
 }
 
+// SetCancelLoadHandler sets the handler that stops an image load
+func (t *Toolbar) SetCancelLoadHandler(handler func()) {
+	t.cancelLoadHandler = handler
+}
+
 // SetSaveHandler sets the save image handler
 func (t *Toolbar) SetSaveHandler(handler func()) {
 	t.saveHandler = handler
 }
 
+// SetGroundTruthHandler sets the load ground truth handler
+func (t *Toolbar) SetGroundTruthHandler(handler func()) {
+	t.groundTruthHandler = handler
+}
+
 // SetProcessHandler sets the process image handler
 func (t *Toolbar) SetProcessHandler(handler func()) {
 	t.processHandler = handler
@@ -164,11 +386,98 @@ func (t *Toolbar) SetCancelHandler(handler func()) {
 	t.cancelHandler = handler
 }
 
+// SetClearQueueHandler sets the handler that drops queued processing runs
+func (t *Toolbar) SetClearQueueHandler(handler func()) {
+	t.clearQueueHandler = handler
+}
+
+// SetAutoTuneHandler sets the parameter auto-tuning handler
+func (t *Toolbar) SetAutoTuneHandler(handler func()) {
+	t.autoTuneHandler = handler
+}
+
+// SetFullResolutionHandler sets the handler for the full resolution toggle
+func (t *Toolbar) SetFullResolutionHandler(handler func(bool)) {
+	t.fullResolutionHandler = handler
+}
+
+// SetFullResolution sets the full resolution toggle without notifying the handler
+func (t *Toolbar) SetFullResolution(enabled bool) {
+	fyne.Do(func() {
+		handler := t.fullResolutionHandler
+		t.fullResolutionHandler = nil
+		t.fullResolutionCheck.SetChecked(enabled)
+		t.fullResolutionHandler = handler
+	})
+}
+
+// SetThresholdHandler sets the handler called with a threshold entered by the user
+func (t *Toolbar) SetThresholdHandler(handler func(float64)) {
+	t.thresholdHandler = handler
+}
+
+// SetThreshold shows the threshold of the last run and enables overriding it
+func (t *Toolbar) SetThreshold(value float64) {
+	fyne.Do(func() {
+		t.thresholdEntry.SetText(strconv.FormatFloat(value, 'f', 1, 64))
+		t.thresholdEntry.Enable()
+	})
+}
+
+// ClearThreshold empties the threshold field until the next run
+func (t *Toolbar) ClearThreshold() {
+	fyne.Do(func() {
+		t.thresholdEntry.SetText("")
+		t.thresholdEntry.Disable()
+	})
+}
+
 // SetAlgorithmChangeHandler sets the algorithm change handler
 func (t *Toolbar) SetAlgorithmChangeHandler(handler func(string)) {
 	t.algorithmChangeHandler = handler
 }
 
+// SetCompareModeHandler sets the handler called when compare mode is toggled
+func (t *Toolbar) SetCompareModeHandler(handler func(bool)) {
+	t.compareModeHandler = handler
+}
+
+// SetClearROIHandler sets the clear region of interest handler
+func (t *Toolbar) SetClearROIHandler(handler func()) {
+	t.clearROIHandler = handler
+}
+
+// SetROIActive enables the clear ROI button while a region is selected
+func (t *Toolbar) SetROIActive(active bool) {
+	fyne.Do(func() {
+		if active {
+			t.clearROIButton.Enable()
+		} else {
+			t.clearROIButton.Disable()
+		}
+	})
+}
+
+// SetRotateHandler sets the handler rotating the image clockwise by degrees
+func (t *Toolbar) SetRotateHandler(handler func(int)) {
+	t.rotateHandler = handler
+}
+
+// SetFlipHandler sets the handler mirroring the image, horizontally when true
+func (t *Toolbar) SetFlipHandler(handler func(bool)) {
+	t.flipHandler = handler
+}
+
+// SetSyncViewsHandler sets the linked view toggle handler
+func (t *Toolbar) SetSyncViewsHandler(handler func(bool)) {
+	t.syncViewsHandler = handler
+}
+
+// SetPageChangeHandler sets the page navigation handler
+func (t *Toolbar) SetPageChangeHandler(handler func(int)) {
+	t.pageChangeHandler = handler
+}
+
 // State management methods
 
 // SetProcessingActive updates the processing state
@@ -176,29 +485,103 @@ func (t *Toolbar) SetProcessingActive(active bool) {
 	fyne.Do(func() {
 		t.processingActive = active
 		
+		// Process stays available while busy and queues the run instead
 		if active {
-			t.processButton.Disable()
+			t.processButton.SetText("Queue")
 			t.cancelButton.Enable()
 			t.saveButton.Disable()
+			t.autoTuneButton.Disable()
 		} else {
+			t.processButton.SetText("Process")
 			t.processButton.Enable()
 			t.cancelButton.Disable()
 			t.saveButton.Enable()
+			t.autoTuneButton.Enable()
 		}
 	})
 }
 
+// SetLoadingActive turns Load Image into Cancel Load while an image loads
+func (t *Toolbar) SetLoadingActive(active bool) {
+	fyne.Do(func() {
+		t.loadingActive = active
+		if active {
+			t.loadButton.SetText("Cancel Load")
+			t.loadButton.Importance = widget.DangerImportance
+		} else {
+			t.loadButton.SetText("Load Image")
+			t.loadButton.Importance = widget.HighImportance
+		}
+		t.loadButton.Refresh()
+	})
+}
+
+// SetQueueLength enables Clear Queue while processing runs are waiting
+func (t *Toolbar) SetQueueLength(pending int) {
+	fyne.Do(func() {
+		if pending > 0 {
+			t.clearQueueButton.SetText(fmt.Sprintf("Clear Queue (%d)", pending))
+			t.clearQueueButton.Enable()
+		} else {
+			t.clearQueueButton.SetText("Clear Queue")
+			t.clearQueueButton.Disable()
+		}
+	})
+}
+
+// SetPageInfo updates the page navigator, hiding it for single-page images
+func (t *Toolbar) SetPageInfo(current, total int) {
+	fyne.Do(func() {
+		t.currentPage = current
+		t.pageCount = total
+		
+		if total <= 1 {
+			t.pageSection.Hide()
+			return
+		}
+		
+		t.pageLabel.SetText(fmt.Sprintf("Page %d / %d", current+1, total))
+		if current > 0 {
+			t.prevPageButton.Enable()
+		} else {
+			t.prevPageButton.Disable()
+		}
+		if current < total-1 {
+			t.nextPageButton.Enable()
+		} else {
+			t.nextPageButton.Disable()
+		}
+		t.pageSection.Show()
+	})
+}
+
 // EnableImageOperations enables/disables image-dependent operations
 func (t *Toolbar) EnableImageOperations(enabled bool) {
 	fyne.Do(func() {
 		if enabled && !t.processingActive {
 			t.processButton.Enable()
+			t.autoTuneButton.Enable()
 		} else {
 			t.processButton.Disable()
+			t.autoTuneButton.Disable()
+		}
+		// Orientation changes stay available while processing; the
+		// controller rejects them until the run finishes
+		for _, button := range t.orientationButtons() {
+			if enabled {
+				button.Enable()
+			} else {
+				button.Disable()
+			}
 		}
 	})
 }
 
+// orientationButtons returns the rotate and flip buttons
+func (t *Toolbar) orientationButtons() []*widget.Button {
+	return append([]*widget.Button{t.flipHorizontalButton, t.flipVerticalButton}, t.rotateButtons...)
+}
+
 // SetCurrentAlgorithm updates the current algorithm
 func (t *Toolbar) SetCurrentAlgorithm(algorithm string) {
 	fyne.Do(func() {
@@ -207,13 +590,33 @@ func (t *Toolbar) SetCurrentAlgorithm(algorithm string) {
 	})
 }
 
+// AddAlgorithms appends algorithms, such as loaded plugins, to the algorithm selector
+func (t *Toolbar) AddAlgorithms(algorithms []string) {
+	fyne.Do(func() {
+		for _, algorithm := range algorithms {
+			exists := false
+			for _, option := range t.algorithmSelect.Options {
+				if option == algorithm {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				t.algorithmSelect.Options = append(t.algorithmSelect.Options, algorithm)
+			}
+		}
+		t.algorithmSelect.Refresh()
+	})
+}
+
 // GetCurrentAlgorithm returns the current algorithm
 func (t *Toolbar) GetCurrentAlgorithm() string {
 	return t.currentAlgorithm
 }
 
-// SetSegmentationMetrics updates the metrics display
-func (t *Toolbar) SetSegmentationMetrics(iou, dice, misclassError, uniformity, boundaryAccuracy float64) {
+// SetSegmentationMetrics updates the metrics display. Hausdorff distances are
+// in pixels; negative values are shown as unavailable.
+func (t *Toolbar) SetSegmentationMetrics(iou, dice, misclassError, uniformity, boundaryAccuracy, hausdorff, hausdorff95, psnr, ssim float64) {
 	fyne.Do(func() {
 		if iou >= 0 && dice >= 0 {
 			if misclassError >= 0 {
@@ -226,16 +629,38 @@ func (t *Toolbar) SetSegmentationMetrics(iou, dice, misclassError, uniformity, b
 		} else {
 			t.metricsLabel.SetText("IoU: -- | Dice: -- | Error: --")
 		}
+
+		if hausdorff >= 0 && hausdorff95 >= 0 {
+			t.hausdorffLabel.SetText(fmt.Sprintf("HD: %.1f px | HD95: %.1f px", hausdorff, hausdorff95))
+		} else {
+			t.hausdorffLabel.SetText("HD: -- | HD95: --")
+		}
+
+		if psnr > 0 {
+			t.similarityLabel.SetText(fmt.Sprintf("PSNR: %.1f dB | SSIM: %.3f", psnr, ssim))
+		} else {
+			t.similarityLabel.SetText("PSNR: -- | SSIM: --")
+		}
 	})
 }
 
 // Reset resets the toolbar to initial state
 func (t *Toolbar) Reset() {
 	fyne.Do(func() {
+		t.processButton.SetText("Process")
 		t.processButton.Disable()
 		t.cancelButton.Disable()
+		t.clearQueueButton.SetText("Clear Queue")
+		t.clearQueueButton.Disable()
 		t.saveButton.Disable()
+		for _, button := range t.orientationButtons() {
+			button.Disable()
+		}
 		t.metricsLabel.SetText("IoU: -- | Dice: -- | Error: --")
+		t.hausdorffLabel.SetText("HD: -- | HD95: --")
+		t.similarityLabel.SetText("PSNR: -- | SSIM: --")
+		t.thresholdEntry.SetText("")
+		t.thresholdEntry.Disable()
 		t.algorithmSelect.SetSelected("2D Otsu")
 		t.currentAlgorithm = "2D Otsu"
 		t.processingActive = false
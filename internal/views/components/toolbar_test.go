@@ -0,0 +1,77 @@
+package components
+
+import (
+	"sync"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+// TestToolbarUpdatesFromWorker drives the toolbar from a background goroutine
+// the way the controllers' workers do. The test driver runs fyne.Do callbacks
+// on the calling goroutine rather than serialising them, so the updates come
+// from one worker at a time; the race detector still checks the hand-off
+// between the worker and the test goroutine.
+func TestToolbarUpdatesFromWorker(t *testing.T) {
+	test.NewTempApp(t)
+	toolbar := NewToolbar()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			toolbar.SetProcessingActive(i%2 == 0)
+			toolbar.SetQueueLength(i)
+			toolbar.SetThreshold(float64(i))
+		}()
+		wg.Wait()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		toolbar.SetProcessingActive(false)
+		toolbar.SetQueueLength(0)
+		toolbar.SetThreshold(127.5)
+	}()
+	wg.Wait()
+
+	if got := toolbar.processButton.Text; got != "Process" {
+		t.Errorf("process button text = %q, want %q", got, "Process")
+	}
+	if !toolbar.cancelButton.Disabled() {
+		t.Error("cancel button enabled after processing finished")
+	}
+	if got := toolbar.clearQueueButton.Text; got != "Clear Queue" {
+		t.Errorf("clear queue button text = %q, want %q", got, "Clear Queue")
+	}
+	if !toolbar.clearQueueButton.Disabled() {
+		t.Error("clear queue button enabled with an empty queue")
+	}
+	if got := toolbar.thresholdEntry.Text; got != "127.5" {
+		t.Errorf("threshold entry = %q, want %q", got, "127.5")
+	}
+	if toolbar.thresholdEntry.Disabled() {
+		t.Error("threshold entry disabled after a threshold was set")
+	}
+}
+
+// TestToolbarQueueLength checks the Clear Queue label follows the queue
+func TestToolbarQueueLength(t *testing.T) {
+	test.NewTempApp(t)
+	toolbar := NewToolbar()
+
+	toolbar.SetQueueLength(3)
+	if got := toolbar.clearQueueButton.Text; got != "Clear Queue (3)" {
+		t.Errorf("clear queue button text = %q, want %q", got, "Clear Queue (3)")
+	}
+	if toolbar.clearQueueButton.Disabled() {
+		t.Error("clear queue button disabled with runs waiting")
+	}
+
+	toolbar.SetProcessingActive(true)
+	if got := toolbar.processButton.Text; got != "Queue" {
+		t.Errorf("process button text = %q, want %q", got, "Queue")
+	}
+}
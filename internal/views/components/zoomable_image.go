@@ -0,0 +1,610 @@
+package components
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	MinZoomLevel  float32 = 0.1
+	MaxZoomLevel  float32 = 10.0
+	ZoomLevelStep float32 = 0.1
+)
+
+// ZoomableImageDisplay shows a single image inside a scroll container with
+// mouse wheel zoom and middle-button panning
+type ZoomableImageDisplay struct {
+	container   *fyne.Container
+	image       *canvas.Image
+	surface     *zoomSurface
+	scroll      *container.Scroll
+	zoomLabel   *widget.Label
+	resetButton *widget.Button
+	fitButton   *widget.Button
+	watermark   *canvas.Text
+	overlay     *canvas.Image
+	header      *fyne.Container
+	selection   *canvas.Rectangle
+	background  *canvas.Rectangle
+
+	// Annotations drawn over the image, empty until a layer is attached
+	annotationHolder *fyne.Container
+	annotationLayer  *AnnotationLayer
+
+	// Event handlers
+	zoomChangeHandler   func(float32)
+	scrollChangeHandler func(fyne.Position)
+	selectionHandler    func(image.Rectangle)
+
+	// State
+	zoomLevel  float32
+	panning    bool
+	lastPanPos fyne.Position
+
+	// Rectangle selection in image pixel coordinates
+	selectionEnabled bool
+	selecting        bool
+	selectionStart   image.Point
+	selectedRegion   *image.Rectangle
+}
+
+// NewZoomableImageDisplay creates a zoomable image pane with the given title
+func NewZoomableImageDisplay(title string, img image.Image) *ZoomableImageDisplay {
+	zd := &ZoomableImageDisplay{
+		zoomLevel: 1.0,
+	}
+	zd.createComponents(img)
+	zd.buildLayout(title)
+	return zd
+}
+
+// createComponents initializes the image, scroll and toolbar widgets
+func (zd *ZoomableImageDisplay) createComponents(img image.Image) {
+	zd.image = canvas.NewImageFromImage(img)
+	zd.image.FillMode = canvas.ImageFillContain
+	zd.image.ScaleMode = canvas.ImageScaleSmooth
+
+	zd.overlay = canvas.NewImageFromImage(nil)
+	zd.overlay.FillMode = canvas.ImageFillContain
+	zd.overlay.ScaleMode = canvas.ImageScalePixels
+	zd.overlay.Translucency = 0.4
+	zd.overlay.Hide()
+
+	zd.selection = canvas.NewRectangle(color.NRGBA{R: 0, G: 120, B: 215, A: 40})
+	zd.selection.StrokeColor = color.NRGBA{R: 0, G: 120, B: 215, A: 255}
+	zd.selection.StrokeWidth = 2
+	zd.selection.Hide()
+
+	zd.annotationHolder = container.NewStack()
+
+	zd.background = canvas.NewRectangle(imageBackgroundColor())
+	if app := fyne.CurrentApp(); app != nil {
+		app.Settings().AddListener(func(fyne.Settings) {
+			fyne.Do(func() {
+				zd.background.FillColor = imageBackgroundColor()
+				zd.background.Refresh()
+			})
+		})
+	}
+
+	zd.surface = newZoomSurface(zd)
+	zd.scroll = container.NewScroll(zd.surface)
+	zd.scroll.SetMinSize(fyne.NewSize(ImageAreaWidth, ImageAreaHeight))
+	zd.scroll.OnScrolled = func(offset fyne.Position) {
+		if zd.scrollChangeHandler != nil {
+			zd.scrollChangeHandler(offset)
+		}
+	}
+
+	zd.zoomLabel = widget.NewLabel(formatZoomLevel(zd.zoomLevel))
+	zd.resetButton = widget.NewButton("Reset", func() {
+		zd.ResetZoom()
+		zd.notifyViewChanged()
+	})
+	zd.resetButton.Importance = widget.LowImportance
+	zd.fitButton = widget.NewButton("Fit", func() {
+		zd.FitToWindow()
+		zd.notifyViewChanged()
+	})
+	zd.fitButton.Importance = widget.LowImportance
+
+	zd.watermark = canvas.NewText("", color.NRGBA{R: 200, G: 40, B: 40, A: 140})
+	zd.watermark.TextSize = 48
+	zd.watermark.TextStyle = fyne.TextStyle{Bold: true}
+	zd.watermark.Hide()
+
+	zd.updateImageSize()
+}
+
+// buildLayout places the pane toolbar above the scrollable image
+func (zd *ZoomableImageDisplay) buildLayout(title string) {
+	zd.header = container.NewHBox(
+		widget.NewRichTextFromMarkdown(fmt.Sprintf("**%s**", title)),
+		layout.NewSpacer(),
+		zd.zoomLabel,
+		zd.fitButton,
+		zd.resetButton,
+	)
+
+	zd.container = container.NewBorder(
+		zd.header,
+		nil, nil, nil,
+		container.NewStack(
+			zd.background,
+			zd.scroll,
+			container.NewCenter(zd.watermark),
+		),
+	)
+}
+
+// SetImage replaces the displayed image while keeping the current zoom level
+func (zd *ZoomableImageDisplay) SetImage(img image.Image) {
+	zd.image.Image = img
+	zd.updateImageSize()
+	zd.image.Refresh()
+	zd.scroll.Refresh()
+}
+
+// SetWatermark overlays text on the pane, or hides it when text is empty
+func (zd *ZoomableImageDisplay) SetWatermark(text string) {
+	zd.watermark.Text = text
+	if text == "" {
+		zd.watermark.Hide()
+	} else {
+		zd.watermark.Show()
+	}
+	zd.watermark.Refresh()
+}
+
+// SetOverlay draws a translucent image over the pane, or removes it when nil
+func (zd *ZoomableImageDisplay) SetOverlay(img image.Image) {
+	zd.overlay.Image = img
+	if img == nil {
+		zd.overlay.Hide()
+	} else {
+		zd.overlay.Show()
+	}
+	zd.overlay.Refresh()
+}
+
+// AddHeaderItem appends a control to the pane header before the zoom controls
+func (zd *ZoomableImageDisplay) AddHeaderItem(item fyne.CanvasObject) {
+	// Insert after the title so zoom controls stay right-aligned
+	objects := zd.header.Objects
+	zd.header.Objects = append([]fyne.CanvasObject{objects[0], item}, objects[1:]...)
+	zd.header.Refresh()
+}
+
+// SetSelectionEnabled allows drawing a rectangle with the primary mouse button
+func (zd *ZoomableImageDisplay) SetSelectionEnabled(enabled bool) {
+	zd.selectionEnabled = enabled
+	if !enabled {
+		zd.selecting = false
+	}
+}
+
+// SetSelectionHandler sets the handler called when a rectangle drag completes.
+// The rectangle is in image pixel coordinates.
+func (zd *ZoomableImageDisplay) SetSelectionHandler(handler func(image.Rectangle)) {
+	zd.selectionHandler = handler
+}
+
+// SetSelection shows a rectangle in image pixel coordinates, nil hides it
+func (zd *ZoomableImageDisplay) SetSelection(region *image.Rectangle) {
+	if region == nil {
+		zd.selectedRegion = nil
+	} else {
+		selected := *region
+		zd.selectedRegion = &selected
+	}
+	zd.layoutSelection()
+}
+
+// SetAnnotationLayer draws layer over the image. While the layer has a tool
+// selected the primary mouse button draws annotations instead of selecting.
+func (zd *ZoomableImageDisplay) SetAnnotationLayer(layer *AnnotationLayer) {
+	zd.annotationLayer = layer
+	zd.annotationHolder.Objects = []fyne.CanvasObject{layer.objects}
+	zd.annotationHolder.Refresh()
+	zd.layoutAnnotations()
+}
+
+// Image returns the currently displayed image
+func (zd *ZoomableImageDisplay) Image() image.Image {
+	return zd.image.Image
+}
+
+// ZoomLevel returns the current zoom factor where 1.0 is 100%
+func (zd *ZoomableImageDisplay) ZoomLevel() float32 {
+	return zd.zoomLevel
+}
+
+// SetZoomLevel sets the zoom factor, clamped to the supported range
+func (zd *ZoomableImageDisplay) SetZoomLevel(level float32) {
+	zd.zoomAround(level, zd.viewportCenter())
+}
+
+// ZoomIn increases the zoom level by one step
+func (zd *ZoomableImageDisplay) ZoomIn() {
+	zd.SetZoomLevel(zd.zoomLevel + ZoomLevelStep)
+}
+
+// ZoomOut decreases the zoom level by one step
+func (zd *ZoomableImageDisplay) ZoomOut() {
+	zd.SetZoomLevel(zd.zoomLevel - ZoomLevelStep)
+}
+
+// ResetZoom returns to 100% and scrolls to the top-left corner
+func (zd *ZoomableImageDisplay) ResetZoom() {
+	zd.applyZoom(1.0)
+	zd.SetScrollOffset(fyne.Position{})
+}
+
+// FitToWindow scales the image so it fits entirely in the visible area
+func (zd *ZoomableImageDisplay) FitToWindow() {
+	if zd.image.Image == nil {
+		return
+	}
+
+	bounds := zd.image.Image.Bounds()
+	viewport := zd.scroll.Size()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || viewport.Width == 0 || viewport.Height == 0 {
+		return
+	}
+
+	scale := float32(math.Min(
+		float64(viewport.Width)/float64(bounds.Dx()),
+		float64(viewport.Height)/float64(bounds.Dy()),
+	))
+
+	zd.applyZoom(scale)
+	zd.SetScrollOffset(fyne.Position{})
+}
+
+// ScrollOffset returns the current scroll position of the image
+func (zd *ZoomableImageDisplay) ScrollOffset() fyne.Position {
+	return zd.scroll.Offset
+}
+
+// SetScrollOffset moves the visible area without notifying the scroll handler
+func (zd *ZoomableImageDisplay) SetScrollOffset(offset fyne.Position) {
+	handler := zd.scrollChangeHandler
+	zd.scrollChangeHandler = nil
+	zd.scroll.ScrollToOffset(offset)
+	zd.scrollChangeHandler = handler
+}
+
+// SetZoomChangeHandler sets the handler called after user driven zoom changes
+func (zd *ZoomableImageDisplay) SetZoomChangeHandler(handler func(float32)) {
+	zd.zoomChangeHandler = handler
+}
+
+// SetScrollChangeHandler sets the handler called after user driven scrolling
+func (zd *ZoomableImageDisplay) SetScrollChangeHandler(handler func(fyne.Position)) {
+	zd.scrollChangeHandler = handler
+}
+
+// GetContainer returns the pane container
+func (zd *ZoomableImageDisplay) GetContainer() *fyne.Container {
+	return zd.container
+}
+
+// zoomAround changes zoom while keeping the content point under anchor fixed
+func (zd *ZoomableImageDisplay) zoomAround(level float32, anchor fyne.Position) {
+	previous := zd.zoomLevel
+	if !zd.applyZoom(level) {
+		return
+	}
+
+	ratio := zd.zoomLevel / previous
+	offset := zd.scroll.Offset
+	viewportPos := anchor.Subtract(offset)
+	zd.SetScrollOffset(fyne.NewPos(
+		anchor.X*ratio-viewportPos.X,
+		anchor.Y*ratio-viewportPos.Y,
+	))
+
+	zd.notifyViewChanged()
+}
+
+// notifyViewChanged reports the current zoom and offset after a user action
+func (zd *ZoomableImageDisplay) notifyViewChanged() {
+	if zd.zoomChangeHandler != nil {
+		zd.zoomChangeHandler(zd.zoomLevel)
+	}
+	if zd.scrollChangeHandler != nil {
+		zd.scrollChangeHandler(zd.scroll.Offset)
+	}
+}
+
+// applyZoom clamps and stores the zoom level, returning true if it changed
+func (zd *ZoomableImageDisplay) applyZoom(level float32) bool {
+	level = clampZoomLevel(level)
+	if level == zd.zoomLevel {
+		return false
+	}
+
+	zd.zoomLevel = level
+	zd.zoomLabel.SetText(formatZoomLevel(level))
+	zd.updateImageSize()
+	zd.scroll.Refresh()
+	return true
+}
+
+// updateImageSize sizes the scroll content to the zoomed image dimensions
+func (zd *ZoomableImageDisplay) updateImageSize() {
+	if zd.image.Image == nil {
+		zd.image.SetMinSize(fyne.NewSize(0, 0))
+		return
+	}
+
+	bounds := zd.image.Image.Bounds()
+	zd.image.SetMinSize(fyne.NewSize(
+		float32(bounds.Dx())*zd.zoomLevel,
+		float32(bounds.Dy())*zd.zoomLevel,
+	))
+	zd.surface.Refresh()
+}
+
+// viewportCenter returns the content position at the centre of the visible area
+func (zd *ZoomableImageDisplay) viewportCenter() fyne.Position {
+	size := zd.scroll.Size()
+	return zd.scroll.Offset.Add(fyne.NewPos(size.Width/2, size.Height/2))
+}
+
+func (zd *ZoomableImageDisplay) handleScroll(ev *fyne.ScrollEvent) {
+	switch {
+	case ev.Scrolled.DY > 0:
+		zd.zoomAround(zd.zoomLevel+ZoomLevelStep, ev.Position)
+	case ev.Scrolled.DY < 0:
+		zd.zoomAround(zd.zoomLevel-ZoomLevelStep, ev.Position)
+	}
+}
+
+func (zd *ZoomableImageDisplay) handleMouseDown(ev *desktop.MouseEvent) {
+	switch ev.Button {
+	case desktop.MouseButtonTertiary:
+		zd.panning = true
+		zd.lastPanPos = ev.AbsolutePosition
+	case desktop.MouseButtonPrimary:
+		point, ok := zd.imagePoint(ev.Position)
+		if zd.annotating() {
+			if ok {
+				zd.annotationLayer.begin(point)
+			}
+			return
+		}
+		if !zd.selectionEnabled || !ok {
+			return
+		}
+		zd.selecting = true
+		zd.selectionStart = point
+		zd.SetSelection(&image.Rectangle{Min: point, Max: point})
+	}
+}
+
+func (zd *ZoomableImageDisplay) handleMouseUp(ev *desktop.MouseEvent) {
+	switch ev.Button {
+	case desktop.MouseButtonTertiary:
+		zd.panning = false
+	case desktop.MouseButtonPrimary:
+		if zd.annotationLayer != nil && zd.annotationLayer.drawing() {
+			point, _ := zd.imagePoint(ev.Position)
+			zd.annotationLayer.extend(point)
+			zd.annotationLayer.finish()
+			return
+		}
+		if !zd.selecting {
+			return
+		}
+		zd.updateSelection(ev.Position)
+		zd.finishSelection()
+	}
+}
+
+// finishSelection reports the drawn rectangle, discarding empty ones
+func (zd *ZoomableImageDisplay) finishSelection() {
+	zd.selecting = false
+	if zd.selectedRegion == nil || zd.selectedRegion.Empty() {
+		zd.SetSelection(nil)
+		return
+	}
+	if zd.selectionHandler != nil {
+		zd.selectionHandler(*zd.selectedRegion)
+	}
+}
+
+func (zd *ZoomableImageDisplay) handleMouseMoved(ev *desktop.MouseEvent) {
+	if zd.annotationLayer != nil && zd.annotationLayer.drawing() {
+		point, _ := zd.imagePoint(ev.Position)
+		zd.annotationLayer.extend(point)
+		return
+	}
+	if zd.selecting {
+		zd.updateSelection(ev.Position)
+		return
+	}
+	if !zd.panning {
+		return
+	}
+
+	delta := ev.AbsolutePosition.Subtract(zd.lastPanPos)
+	zd.lastPanPos = ev.AbsolutePosition
+
+	zd.SetScrollOffset(zd.scroll.Offset.Subtract(delta))
+	if zd.scrollChangeHandler != nil {
+		zd.scrollChangeHandler(zd.scroll.Offset)
+	}
+}
+
+// updateSelection stretches the rectangle being drawn to the pointer position
+func (zd *ZoomableImageDisplay) updateSelection(pos fyne.Position) {
+	point, _ := zd.imagePoint(pos)
+	region := image.Rectangle{Min: zd.selectionStart, Max: point}.Canon()
+	zd.SetSelection(&region)
+}
+
+// imageRect returns where the image is drawn on the surface and its scale
+func (zd *ZoomableImageDisplay) imageRect() (fyne.Position, float32, bool) {
+	if zd.image.Image == nil {
+		return fyne.Position{}, 0, false
+	}
+
+	bounds := zd.image.Image.Bounds()
+	size := zd.surface.Size()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || size.Width == 0 || size.Height == 0 {
+		return fyne.Position{}, 0, false
+	}
+
+	// Matches canvas.ImageFillContain, which centres the scaled image
+	scale := float32(math.Min(
+		float64(size.Width)/float64(bounds.Dx()),
+		float64(size.Height)/float64(bounds.Dy()),
+	))
+	origin := fyne.NewPos(
+		(size.Width-float32(bounds.Dx())*scale)/2,
+		(size.Height-float32(bounds.Dy())*scale)/2,
+	)
+	return origin, scale, true
+}
+
+// imagePoint converts a surface position to image pixel coordinates, clamped to
+// the image bounds. The boolean reports whether the position was on the image.
+func (zd *ZoomableImageDisplay) imagePoint(pos fyne.Position) (image.Point, bool) {
+	origin, scale, ok := zd.imageRect()
+	if !ok {
+		return image.Point{}, false
+	}
+
+	bounds := zd.image.Image.Bounds()
+	x := int((pos.X - origin.X) / scale)
+	y := int((pos.Y - origin.Y) / scale)
+	inside := x >= 0 && y >= 0 && x < bounds.Dx() && y < bounds.Dy()
+
+	x = max(0, min(x, bounds.Dx()))
+	y = max(0, min(y, bounds.Dy()))
+	return image.Pt(x, y), inside
+}
+
+// layoutSelection positions the selection rectangle for the current zoom
+func (zd *ZoomableImageDisplay) layoutSelection() {
+	origin, scale, ok := zd.imageRect()
+	if zd.selectedRegion == nil || !ok {
+		zd.selection.Hide()
+		return
+	}
+
+	region := zd.selectedRegion
+	zd.selection.Move(origin.Add(fyne.NewPos(float32(region.Min.X)*scale, float32(region.Min.Y)*scale)))
+	zd.selection.Resize(fyne.NewSize(float32(region.Dx())*scale, float32(region.Dy())*scale))
+	zd.selection.Show()
+	zd.selection.Refresh()
+}
+
+// annotating reports whether primary button drags draw annotations
+func (zd *ZoomableImageDisplay) annotating() bool {
+	return zd.annotationLayer != nil && zd.annotationLayer.Tool() != AnnotationToolNone
+}
+
+// layoutAnnotations maps the annotation layer onto the image for the current zoom
+func (zd *ZoomableImageDisplay) layoutAnnotations() {
+	if zd.annotationLayer == nil {
+		return
+	}
+	if origin, scale, ok := zd.imageRect(); ok {
+		zd.annotationLayer.setTransform(origin, scale)
+	}
+}
+
+// clampZoomLevel restricts a zoom level to the supported range
+func clampZoomLevel(level float32) float32 {
+	// Round to avoid drift from repeated float32 step additions
+	level = float32(math.Round(float64(level)*100) / 100)
+	if level < MinZoomLevel {
+		return MinZoomLevel
+	}
+	if level > MaxZoomLevel {
+		return MaxZoomLevel
+	}
+	return level
+}
+
+// formatZoomLevel renders a zoom factor as a percentage label
+func formatZoomLevel(level float32) string {
+	return fmt.Sprintf("%.0f%%", level*100)
+}
+
+// zoomSurface is the scroll content that captures wheel, selection and
+// middle-button events
+type zoomSurface struct {
+	widget.BaseWidget
+	owner *ZoomableImageDisplay
+}
+
+func newZoomSurface(owner *ZoomableImageDisplay) *zoomSurface {
+	surface := &zoomSurface{owner: owner}
+	surface.ExtendBaseWidget(surface)
+	return surface
+}
+
+// CreateRenderer links the surface to its image
+func (zs *zoomSurface) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(
+		zs.owner.image,
+		zs.owner.overlay,
+		zs.owner.annotationHolder,
+		container.NewWithoutLayout(zs.owner.selection),
+	))
+}
+
+// Resize keeps the selection rectangle and annotations aligned with the
+// resized image
+func (zs *zoomSurface) Resize(size fyne.Size) {
+	zs.BaseWidget.Resize(size)
+	zs.owner.layoutSelection()
+	zs.owner.layoutAnnotations()
+}
+
+// Scrolled zooms in or out on mouse wheel movement
+func (zs *zoomSurface) Scrolled(ev *fyne.ScrollEvent) {
+	zs.owner.handleScroll(ev)
+}
+
+// MouseDown starts panning on middle-button press or a selection on primary press
+func (zs *zoomSurface) MouseDown(ev *desktop.MouseEvent) {
+	zs.owner.handleMouseDown(ev)
+}
+
+// MouseUp stops panning or completes a selection
+func (zs *zoomSurface) MouseUp(ev *desktop.MouseEvent) {
+	zs.owner.handleMouseUp(ev)
+}
+
+// MouseIn is required by desktop.Hoverable
+func (zs *zoomSurface) MouseIn(*desktop.MouseEvent) {}
+
+// MouseMoved pans the view or stretches the selection while a button is held
+func (zs *zoomSurface) MouseMoved(ev *desktop.MouseEvent) {
+	zs.owner.handleMouseMoved(ev)
+}
+
+// MouseOut cancels panning and completes any selection or annotation when
+// the pointer leaves the image
+func (zs *zoomSurface) MouseOut() {
+	zs.owner.panning = false
+	if zs.owner.selecting {
+		zs.owner.finishSelection()
+	}
+	if zs.owner.annotationLayer != nil && zs.owner.annotationLayer.drawing() {
+		zs.owner.annotationLayer.finish()
+	}
+}
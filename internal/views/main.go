@@ -3,13 +3,20 @@ package views
 import (
 	"fmt"
 	"image"
+	"strconv"
+	"strings"
+	"time"
 
+	"otsu-obliterator/internal/algorithms/convergence"
+	"otsu-obliterator/internal/logger"
 	"otsu-obliterator/internal/models"
+	"otsu-obliterator/internal/processing"
 	"otsu-obliterator/internal/views/components"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/widget"
 )
 
@@ -21,16 +28,69 @@ type MainView struct {
 	toolbar       *components.Toolbar
 	imageDisplay  *components.ImageDisplay
 	paramPanel    *components.ParameterPanel
+	shortcuts     *KeyboardShortcutManager
 	statusBar     *components.StatusBar
 	progressBar   *components.ProgressBar
+	historyPanel  *components.HistoryPanel
+
+	// Joint histogram panel, docked on the right or floating in its own window
+	jointHistogram       *components.JointHistogramDisplay
+	jointHistogramPanel  *fyne.Container
+	jointHistogramDock   *fyne.Container
+	jointHistogramItem   *fyne.MenuItem
+	jointHistogramWindow fyne.Window
+
+	// Metadata sidebar, docked on the left and hidden until enabled from the
+	// View menu
+	metadataSidebar     *components.MetadataSidebar
+	metadataSidebarItem *fyne.MenuItem
 
 	// Event handlers - connected to controller
 	loadImageHandler       func()
+	cancelLoadHandler      func()
 	saveImageHandler       func()
 	processImageHandler    func()
 	cancelProcessingHandler func()
+	clearQueueHandler       func()
+	autoTuneHandler         func()
 	algorithmChangeHandler func(string)
+	compareModeHandler     func(bool)
 	parameterChangeHandler func(string, interface{})
+	batchProcessHandler    func()
+	undoHandler            func()
+	redoHandler            func()
+	clearSessionHandler    func()
+	previewToggleHandler   func(bool)
+	pageChangeHandler      func(int)
+	groundTruthHandler     func()
+	errorMapHandler        func(bool)
+	roiHandler             func(*image.Rectangle)
+	rotateHandler          func(int)
+	sensitivityHandler     func()
+	flipHandler            func(bool)
+	annotationHandler      func(models.Annotation)
+	clearAnnotationsHandler  func()
+	exportAnnotationsHandler func()
+	exportReportHandler    func()
+	exportMacroHandler     func()
+	exportConfigHandler    func()
+	importConfigHandler    func()
+	weightMapHandler       func()
+	presetLoadHandler      func(string)
+	presetSaveHandler      func(string)
+	autoSaveFolderHandler  func()
+	loadStackHandler       func()
+	stackSliceHandler      func(int)
+	fullResolutionHandler  func(bool)
+	thresholdHandler       func(float64)
+	historySelectHandler   func(int)
+	statisticsHandler      func(visible, processed bool)
+	settingsLoader         func() map[string]interface{}
+	settingChangeHandler   func(string, interface{})
+	settingsResetHandler   func()
+
+	// Preferences state
+	previewEnabled bool
 }
 
 // NewMainView creates a new main view
@@ -42,6 +102,8 @@ func NewMainView(window fyne.Window) *MainView {
 	view.initializeComponents()
 	view.buildLayout()
 	view.setupEventHandlers()
+	view.setupMainMenu()
+	view.setupShortcuts()
 
 	return view
 }
@@ -53,6 +115,11 @@ func (mv *MainView) initializeComponents() {
 	mv.paramPanel = components.NewParameterPanel()
 	mv.statusBar = components.NewStatusBar()
 	mv.progressBar = components.NewProgressBar()
+	mv.historyPanel = components.NewHistoryPanel()
+	mv.jointHistogram = components.NewJointHistogramDisplay()
+	mv.jointHistogramPanel = mv.newJointHistogramPanel()
+	mv.metadataSidebar = components.NewMetadataSidebar()
+	mv.metadataSidebar.GetContainer().Hide()
 }
 
 // buildLayout constructs the main layout
@@ -61,6 +128,7 @@ func (mv *MainView) buildLayout() {
 	contentArea := container.NewVBox(
 		mv.imageDisplay.GetContainer(),
 		mv.paramPanel.GetContainer(),
+		mv.historyPanel.GetContainer(),
 	)
 
 	// Create toolbar and status area
@@ -75,8 +143,8 @@ func (mv *MainView) buildLayout() {
 	mv.mainContainer = container.NewBorder(
 		topArea,   // top
 		bottomArea, // bottom
-		nil,       // left
-		nil,       // right
+		mv.metadataSidebar.GetContainer(), // left
+		mv.jointHistogramPanel, // right
 		contentArea, // center
 	)
 
@@ -94,6 +162,12 @@ func (mv *MainView) setupEventHandlers() {
 		}
 	})
 
+	mv.toolbar.SetCancelLoadHandler(func() {
+		if mv.cancelLoadHandler != nil {
+			mv.cancelLoadHandler()
+		}
+	})
+
 	mv.toolbar.SetSaveHandler(func() {
 		if mv.saveImageHandler != nil {
 			fyne.Do(func() {
@@ -118,6 +192,18 @@ func (mv *MainView) setupEventHandlers() {
 		}
 	})
 
+	mv.toolbar.SetAutoTuneHandler(func() {
+		if mv.autoTuneHandler != nil {
+			mv.autoTuneHandler()
+		}
+	})
+
+	mv.toolbar.SetClearQueueHandler(func() {
+		if mv.clearQueueHandler != nil {
+			mv.clearQueueHandler()
+		}
+	})
+
 	mv.toolbar.SetAlgorithmChangeHandler(func(algorithm string) {
 		if mv.algorithmChangeHandler != nil {
 			fyne.Do(func() {
@@ -126,6 +212,87 @@ func (mv *MainView) setupEventHandlers() {
 		}
 	})
 
+	mv.toolbar.SetCompareModeHandler(func(enabled bool) {
+		mv.imageDisplay.SetCompareMode(enabled)
+		if mv.compareModeHandler != nil {
+			mv.compareModeHandler(enabled)
+		}
+	})
+
+	mv.toolbar.SetGroundTruthHandler(func() {
+		if mv.groundTruthHandler != nil {
+			fyne.Do(func() {
+				mv.groundTruthHandler()
+			})
+		}
+	})
+
+	mv.imageDisplay.SetStackHandler(func(slice int) {
+		if mv.stackSliceHandler != nil {
+			mv.stackSliceHandler(slice)
+		}
+	})
+
+	mv.imageDisplay.SetErrorMapHandler(func(enabled bool) {
+		if mv.errorMapHandler != nil {
+			mv.errorMapHandler(enabled)
+		}
+	})
+
+	mv.imageDisplay.SetROIHandler(func(roi image.Rectangle) {
+		if mv.roiHandler != nil {
+			mv.roiHandler(&roi)
+		}
+	})
+
+	mv.toolbar.SetClearROIHandler(func() {
+		if mv.roiHandler != nil {
+			mv.roiHandler(nil)
+		}
+	})
+
+	mv.toolbar.SetRotateHandler(func(degrees int) {
+		if mv.rotateHandler != nil {
+			mv.rotateHandler(degrees)
+		}
+	})
+
+	mv.toolbar.SetFlipHandler(func(horizontal bool) {
+		if mv.flipHandler != nil {
+			mv.flipHandler(horizontal)
+		}
+	})
+
+	mv.imageDisplay.SetAnnotationHandler(func(annotation components.Annotation) {
+		if mv.annotationHandler != nil {
+			mv.annotationHandler(annotationToModel(annotation))
+		}
+	})
+
+	mv.toolbar.SetPageChangeHandler(func(page int) {
+		if mv.pageChangeHandler != nil {
+			fyne.Do(func() {
+				mv.pageChangeHandler(page)
+			})
+		}
+	})
+
+	mv.toolbar.SetFullResolutionHandler(func(enabled bool) {
+		if mv.fullResolutionHandler != nil {
+			mv.fullResolutionHandler(enabled)
+		}
+	})
+
+	mv.toolbar.SetThresholdHandler(func(value float64) {
+		if mv.thresholdHandler != nil {
+			mv.thresholdHandler(value)
+		}
+	})
+
+	mv.toolbar.SetSyncViewsHandler(func(enabled bool) {
+		mv.imageDisplay.SetSyncEnabled(enabled)
+	})
+
 	// Parameter panel events
 	mv.paramPanel.SetParameterChangeHandler(func(name string, value interface{}) {
 		if mv.parameterChangeHandler != nil {
@@ -134,6 +301,302 @@ func (mv *MainView) setupEventHandlers() {
 			})
 		}
 	})
+
+	mv.paramPanel.SetPresetHandlers(func(name string) {
+		if mv.presetLoadHandler != nil {
+			mv.presetLoadHandler(name)
+		}
+	}, mv.showSavePresetDialog)
+
+	mv.paramPanel.SetWeightMapHandler(func() {
+		if mv.weightMapHandler != nil {
+			mv.weightMapHandler()
+		}
+	})
+
+	mv.historyPanel.SetSelectHandler(func(index int) {
+		if mv.historySelectHandler != nil {
+			mv.historySelectHandler(index)
+		}
+	})
+
+	mv.metadataSidebar.SetSourceHandler(func(processed bool) {
+		if mv.statisticsHandler != nil {
+			mv.statisticsHandler(mv.metadataSidebar.GetContainer().Visible(), processed)
+		}
+	})
+}
+
+// setupMainMenu builds the window menu bar
+func (mv *MainView) setupMainMenu() {
+	fileMenu := fyne.NewMenu("File",
+		fyne.NewMenuItem("Load Image Stack...", func() {
+			if mv.loadStackHandler != nil {
+				mv.loadStackHandler()
+			}
+		}),
+		fyne.NewMenuItem("Batch Process...", func() {
+			if mv.batchProcessHandler != nil {
+				mv.batchProcessHandler()
+			}
+		}),
+		fyne.NewMenuItem("Export PDF Report...", func() {
+			if mv.exportReportHandler != nil {
+				mv.exportReportHandler()
+			}
+		}),
+		fyne.NewMenuItem("Export Annotations...", func() {
+			if mv.exportAnnotationsHandler != nil {
+				mv.exportAnnotationsHandler()
+			}
+		}),
+		fyne.NewMenuItem("Export ImageJ Macro", func() {
+			if mv.exportMacroHandler != nil {
+				mv.exportMacroHandler()
+			}
+		}),
+		fyne.NewMenuItem("Export Config YAML...", func() {
+			if mv.exportConfigHandler != nil {
+				mv.exportConfigHandler()
+			}
+		}),
+		fyne.NewMenuItem("Import Config YAML...", func() {
+			if mv.importConfigHandler != nil {
+				mv.importConfigHandler()
+			}
+		}),
+		fyne.NewMenuItem("Auto-save Folder...", func() {
+			if mv.autoSaveFolderHandler != nil {
+				mv.autoSaveFolderHandler()
+			}
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Clear Session", func() {
+			if mv.clearSessionHandler != nil {
+				mv.clearSessionHandler()
+			}
+		}),
+	)
+
+	editMenu := fyne.NewMenu("Edit",
+		fyne.NewMenuItem("Undo", mv.triggerUndo),
+		fyne.NewMenuItem("Redo", mv.triggerRedo),
+		fyne.NewMenuItem("Clear Annotations", func() {
+			if mv.clearAnnotationsHandler != nil {
+				mv.clearAnnotationsHandler()
+			}
+		}),
+		fyne.NewMenuItemSeparator(),
+		fyne.NewMenuItem("Preferences...", mv.ShowPreferences),
+	)
+
+	mv.jointHistogramItem = fyne.NewMenuItem("Joint Histogram", func() {
+		mv.SetJointHistogramVisible(!mv.jointHistogramItem.Checked)
+	})
+	mv.metadataSidebarItem = fyne.NewMenuItem("Image Metadata", func() {
+		mv.SetMetadataSidebarVisible(!mv.metadataSidebarItem.Checked)
+	})
+	viewMenu := fyne.NewMenu("View", mv.jointHistogramItem, mv.metadataSidebarItem)
+
+	analysisMenu := fyne.NewMenu("Analysis",
+		fyne.NewMenuItem("Sensitivity Analysis", func() {
+			if mv.sensitivityHandler != nil {
+				mv.sensitivityHandler()
+			}
+		}),
+	)
+
+	mv.window.SetMainMenu(fyne.NewMainMenu(fileMenu, editMenu, viewMenu, analysisMenu))
+}
+
+// newJointHistogramPanel builds the docked joint histogram panel, hidden until
+// enabled from the View menu
+func (mv *MainView) newJointHistogramPanel() *fyne.Container {
+	floatButton := widget.NewButton("Float", mv.floatJointHistogram)
+	header := container.NewBorder(nil, nil, widget.NewLabel("Joint Histogram"), floatButton)
+
+	mv.jointHistogramDock = container.NewStack(mv.jointHistogram)
+	panel := container.NewBorder(header, nil, nil, nil, mv.jointHistogramDock)
+	panel.Hide()
+	return panel
+}
+
+// SetJointHistogramVisible shows or hides the joint histogram panel
+func (mv *MainView) SetJointHistogramVisible(visible bool) {
+	fyne.Do(func() {
+		if mv.jointHistogramItem != nil {
+			mv.jointHistogramItem.Checked = visible
+		}
+
+		if mv.jointHistogramWindow != nil {
+			if !visible {
+				mv.jointHistogramWindow.Close()
+			}
+		} else if visible {
+			mv.jointHistogramPanel.Show()
+		} else {
+			mv.jointHistogramPanel.Hide()
+		}
+
+		mv.window.MainMenu().Refresh()
+		mv.mainContainer.Refresh()
+	})
+}
+
+// floatJointHistogram moves the joint histogram into its own window. Closing
+// that window docks it again.
+func (mv *MainView) floatJointHistogram() {
+	if mv.jointHistogramWindow != nil {
+		mv.jointHistogramWindow.RequestFocus()
+		return
+	}
+
+	mv.jointHistogramPanel.Hide()
+	mv.jointHistogramDock.Remove(mv.jointHistogram)
+	mv.mainContainer.Refresh()
+
+	window := fyne.CurrentApp().NewWindow("Joint Histogram")
+	window.SetContent(mv.jointHistogram)
+	window.SetOnClosed(func() {
+		mv.jointHistogramWindow = nil
+		mv.jointHistogramDock.Add(mv.jointHistogram)
+		if mv.jointHistogramItem != nil && mv.jointHistogramItem.Checked {
+			mv.jointHistogramPanel.Show()
+		}
+		mv.mainContainer.Refresh()
+	})
+	mv.jointHistogramWindow = window
+	window.Show()
+}
+
+// SetJointHistogram shows the 2D Otsu joint histogram and its threshold pair
+func (mv *MainView) SetJointHistogram(histogram [][]float64, threshold [2]float64) {
+	mv.jointHistogram.SetData(histogram, threshold)
+}
+
+// SetMetadataSidebarVisible shows or hides the image metadata sidebar
+func (mv *MainView) SetMetadataSidebarVisible(visible bool) {
+	fyne.Do(func() {
+		if mv.metadataSidebarItem != nil {
+			mv.metadataSidebarItem.Checked = visible
+		}
+
+		if visible {
+			mv.metadataSidebar.GetContainer().Show()
+		} else {
+			mv.metadataSidebar.GetContainer().Hide()
+		}
+
+		mv.window.MainMenu().Refresh()
+		mv.mainContainer.Refresh()
+
+		if mv.statisticsHandler != nil {
+			mv.statisticsHandler(visible, mv.metadataSidebar.ShowsProcessed())
+		}
+	})
+}
+
+// SetImageDetails shows the file details and EXIF data of a loaded image in
+// the metadata sidebar
+func (mv *MainView) SetImageDetails(imageData *models.ImageData) {
+	if imageData == nil {
+		mv.metadataSidebar.Reset()
+		return
+	}
+
+	info := components.FileInfo{
+		Size:     imageData.Metadata.FileSize,
+		Width:    imageData.Width,
+		Height:   imageData.Height,
+		Channels: imageData.Channels,
+		BitDepth: imageData.Metadata.BitDepth,
+	}
+	if imageData.OriginalURI != nil {
+		info.Name = imageData.OriginalURI.Name()
+	}
+
+	mv.metadataSidebar.SetFileInfo(info)
+	mv.metadataSidebar.SetExif(imageData.Metadata.Exif)
+}
+
+// SetImageStatistics shows grey level statistics in the metadata sidebar;
+// nil shows that none are available
+func (mv *MainView) SetImageStatistics(stats *models.ImageStatistics) {
+	if stats == nil {
+		mv.metadataSidebar.SetStatistics(nil)
+		return
+	}
+
+	mv.metadataSidebar.SetStatistics(&components.ImageStatistics{
+		Mean:             stats.Mean,
+		StdDev:           stats.StdDev,
+		Min:              stats.Min,
+		Max:              stats.Max,
+		Entropy:          stats.Entropy,
+		DynamicRange:     stats.DynamicRange,
+		DynamicRangeBits: stats.DynamicRangeBits,
+	})
+}
+
+// setupShortcuts registers the actions keyboard shortcuts can trigger and
+// binds the embedded default keys
+func (mv *MainView) setupShortcuts() {
+	mv.shortcuts = NewKeyboardShortcutManager(mv.window)
+	mv.shortcuts.Register("load_image", func() {
+		if mv.loadImageHandler != nil {
+			mv.loadImageHandler()
+		}
+	})
+	mv.shortcuts.Register("save_image", func() {
+		if mv.saveImageHandler != nil {
+			mv.saveImageHandler()
+		}
+	})
+	mv.shortcuts.Register("process", func() {
+		if mv.processImageHandler != nil {
+			mv.processImageHandler()
+		}
+	})
+	mv.shortcuts.Register("cancel", func() {
+		if mv.cancelProcessingHandler != nil {
+			mv.cancelProcessingHandler()
+		}
+	})
+	mv.shortcuts.Register("undo", mv.triggerUndo)
+	mv.shortcuts.Register("redo", mv.triggerRedo)
+
+	if definitions, err := DefaultShortcutDefinitions(); err == nil {
+		mv.shortcuts.Apply(definitions)
+	}
+}
+
+// LoadShortcuts rebinds the keyboard shortcuts from the shortcuts.json copy
+// in the appDirName config folder, if there is one, logging invalid and
+// conflicting definitions to log
+func (mv *MainView) LoadShortcuts(appDirName string, log logger.Logger) {
+	mv.shortcuts.SetLogger(log)
+
+	definitions, err := LoadShortcutDefinitions(appDirName)
+	if err != nil {
+		log.Warning("Custom shortcuts ignored", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if definitions != nil {
+		mv.shortcuts.Apply(definitions)
+	}
+}
+
+func (mv *MainView) triggerUndo() {
+	if mv.undoHandler != nil {
+		mv.undoHandler()
+	}
+}
+
+func (mv *MainView) triggerRedo() {
+	if mv.redoHandler != nil {
+		mv.redoHandler()
+	}
 }
 
 // Event handler setters - called by controller
@@ -143,6 +606,18 @@ func (mv *MainView) SetLoadImageHandler(handler func()) {
 	mv.loadImageHandler = handler
 }
 
+// SetCancelLoadHandler sets the handler that stops an image load
+func (mv *MainView) SetCancelLoadHandler(handler func()) {
+	mv.cancelLoadHandler = handler
+}
+
+// SetDropHandler sets the handler for files dropped on the window
+func (mv *MainView) SetDropHandler(handler func([]fyne.URI)) {
+	mv.window.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		handler(uris)
+	})
+}
+
 // SetSaveImageHandler sets the handler for save image requests
 func (mv *MainView) SetSaveImageHandler(handler func()) {
 	mv.saveImageHandler = handler
@@ -158,86 +633,437 @@ func (mv *MainView) SetCancelProcessingHandler(handler func()) {
 	mv.cancelProcessingHandler = handler
 }
 
+// SetClearQueueHandler sets the handler that drops queued processing runs
+func (mv *MainView) SetClearQueueHandler(handler func()) {
+	mv.clearQueueHandler = handler
+}
+
 // SetAlgorithmChangeHandler sets the handler for algorithm changes
 func (mv *MainView) SetAlgorithmChangeHandler(handler func(string)) {
 	mv.algorithmChangeHandler = handler
 }
 
+// SetCompareModeHandler sets the handler for the compare mode toggle
+func (mv *MainView) SetCompareModeHandler(handler func(bool)) {
+	mv.compareModeHandler = handler
+}
+
+// SetComparisonResults shows the two compare mode results, each captioned
+// with its algorithm and IoU. A nil result clears its pane.
+func (mv *MainView) SetComparisonResults(left, right *models.ProcessingResult) {
+	var images [2]image.Image
+	var captions [2]string
+	for i, result := range []*models.ProcessingResult{left, right} {
+		if result == nil || result.ProcessedImage == nil {
+			continue
+		}
+		images[i] = result.ProcessedImage.Image
+		captions[i] = comparisonCaption(result)
+	}
+	mv.imageDisplay.SetComparisonImages(images[0], images[1], captions[0], captions[1])
+}
+
+// comparisonCaption names a compare mode result and its IoU
+func comparisonCaption(result *models.ProcessingResult) string {
+	if result.Metrics == nil {
+		return fmt.Sprintf("%s | IoU: --", result.Algorithm)
+	}
+	return fmt.Sprintf("%s | IoU: %.3f", result.Algorithm, result.Metrics.IoU)
+}
+
+// SetFullResolutionHandler sets the handler for the full resolution toggle
+func (mv *MainView) SetFullResolutionHandler(handler func(bool)) {
+	mv.fullResolutionHandler = handler
+}
+
+// SetThresholdHandler sets the handler for re-applying a user entered threshold
+func (mv *MainView) SetThresholdHandler(handler func(float64)) {
+	mv.thresholdHandler = handler
+}
+
+// SetHistorySelectHandler sets the handler for restoring a previous run
+func (mv *MainView) SetHistorySelectHandler(handler func(int)) {
+	mv.historySelectHandler = handler
+}
+
+// SetStatisticsHandler sets the handler called when the metadata sidebar is
+// shown or hidden or its statistics switch between the original and the
+// processed image
+func (mv *MainView) SetStatisticsHandler(handler func(visible, processed bool)) {
+	mv.statisticsHandler = handler
+}
+
+// SetFullResolution reflects whether images are processed without downscaling
+func (mv *MainView) SetFullResolution(enabled bool) {
+	mv.toolbar.SetFullResolution(enabled)
+}
+
 // SetParameterChangeHandler sets the handler for parameter changes
 func (mv *MainView) SetParameterChangeHandler(handler func(string, interface{})) {
 	mv.parameterChangeHandler = handler
 }
 
-// UI update methods - called by controller
+// SetAutoTuneHandler sets the handler for parameter auto-tuning requests
+func (mv *MainView) SetAutoTuneHandler(handler func()) {
+	mv.autoTuneHandler = handler
+}
+
+// SetOrientationHandlers sets the handlers for manual rotation and flipping
+func (mv *MainView) SetOrientationHandlers(rotate func(int), flip func(bool)) {
+	mv.rotateHandler = rotate
+	mv.flipHandler = flip
+}
+
+// SetSensitivityAnalysisHandler sets the handler for Analysis > Sensitivity Analysis
+func (mv *MainView) SetSensitivityAnalysisHandler(handler func()) {
+	mv.sensitivityHandler = handler
+}
+
+// SetBatchProcessHandler sets the handler for batch processing requests
+func (mv *MainView) SetBatchProcessHandler(handler func()) {
+	mv.batchProcessHandler = handler
+}
+
+// SetLoadStackHandler sets the handler for loading a volume stack
+func (mv *MainView) SetLoadStackHandler(handler func()) {
+	mv.loadStackHandler = handler
+}
+
+// SetStackSliceHandler sets the handler for stack navigator moves
+func (mv *MainView) SetStackSliceHandler(handler func(int)) {
+	mv.stackSliceHandler = handler
+}
+
+// SetUndoHandler sets the handler for undo requests
+func (mv *MainView) SetUndoHandler(handler func()) {
+	mv.undoHandler = handler
+}
+
+// SetRedoHandler sets the handler for redo requests
+func (mv *MainView) SetRedoHandler(handler func()) {
+	mv.redoHandler = handler
+}
+
+// SetGroundTruthHandler sets the handler for load ground truth requests
+func (mv *MainView) SetGroundTruthHandler(handler func()) {
+	mv.groundTruthHandler = handler
+}
+
+// SetErrorMapHandler sets the handler for the error map toggle
+func (mv *MainView) SetErrorMapHandler(handler func(bool)) {
+	mv.errorMapHandler = handler
+}
+
+// SetROIHandler sets the handler for region of interest changes, nil means cleared
+func (mv *MainView) SetROIHandler(handler func(*image.Rectangle)) {
+	mv.roiHandler = handler
+}
+
+// SetAnnotationHandlers sets the handlers for a newly drawn annotation and
+// for clear and export requests
+func (mv *MainView) SetAnnotationHandlers(added func(models.Annotation), clear, export func()) {
+	mv.annotationHandler = added
+	mv.clearAnnotationsHandler = clear
+	mv.exportAnnotationsHandler = export
+}
+
+// SetPageChangeHandler sets the handler for multi-page navigation
+func (mv *MainView) SetPageChangeHandler(handler func(int)) {
+	mv.pageChangeHandler = handler
+}
+
+// SetPreviewToggleHandler sets the handler for live preview preference changes
+func (mv *MainView) SetPreviewToggleHandler(handler func(bool)) {
+	mv.previewToggleHandler = handler
+}
+
+// SetPreviewEnabled updates the live preview preference shown in the view
+func (mv *MainView) SetPreviewEnabled(enabled bool) {
+	mv.previewEnabled = enabled
+}
+
+// SetPreferencesHandlers sets where the preferences dialog reads its values,
+// and the handlers for edited and reset settings
+func (mv *MainView) SetPreferencesHandlers(load func() map[string]interface{}, change func(string, interface{}), reset func()) {
+	mv.settingsLoader = load
+	mv.settingChangeHandler = change
+	mv.settingsResetHandler = reset
+}
+
+// SetAutoSaveFolderHandler sets the handler for choosing the auto-save folder
+func (mv *MainView) SetAutoSaveFolderHandler(handler func()) {
+	mv.autoSaveFolderHandler = handler
+}
+
+// SetExportReportHandler sets the handler for PDF report export requests
+func (mv *MainView) SetExportReportHandler(handler func()) {
+	mv.exportReportHandler = handler
+}
+
+// SetExportMacroHandler sets the handler for ImageJ macro export requests
+func (mv *MainView) SetExportMacroHandler(handler func()) {
+	mv.exportMacroHandler = handler
+}
+
+// SetExportConfigHandler sets the handler for YAML configuration export requests
+func (mv *MainView) SetExportConfigHandler(handler func()) {
+	mv.exportConfigHandler = handler
+}
+
+// SetImportConfigHandler sets the handler for YAML configuration import requests
+func (mv *MainView) SetImportConfigHandler(handler func()) {
+	mv.importConfigHandler = handler
+}
+
+// SetWeightMapHandler sets the handler for 2D Otsu weight map load requests
+func (mv *MainView) SetWeightMapHandler(handler func()) {
+	mv.weightMapHandler = handler
+}
+
+// SetPresetHandlers sets the handlers for loading a saved preset and for
+// saving the current parameters under a name
+func (mv *MainView) SetPresetHandlers(load, save func(string)) {
+	mv.presetLoadHandler = load
+	mv.presetSaveHandler = save
+}
+
+// SetPresets lists the saved presets in the parameter panel
+func (mv *MainView) SetPresets(names []string) {
+	mv.paramPanel.SetPresets(names)
+}
+
+// ApplyPreset switches the parameter panel to algorithm, showing parameters,
+// and fills in the values of a loaded preset
+func (mv *MainView) ApplyPreset(algorithm string, parameters, preset map[string]interface{}) {
+	mv.UpdateAlgorithmParameters(algorithm, parameters)
+	mv.paramPanel.ApplyPreset(preset)
+}
+
+// showSavePresetDialog asks for the name to save the current parameters under
+func (mv *MainView) showSavePresetDialog() {
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("e.g. tissue slide")
+	nameEntry.Validator = func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("enter a name")
+		}
+		return nil
+	}
+
+	items := []*widget.FormItem{widget.NewFormItem("Name", nameEntry)}
+	dialog.ShowForm("Save Preset", "Save", "Cancel", items, func(confirmed bool) {
+		if confirmed && mv.presetSaveHandler != nil {
+			mv.presetSaveHandler(strings.TrimSpace(nameEntry.Text))
+		}
+	}, mv.window)
+}
+
+// SetClearSessionHandler sets the handler for clear session requests
+func (mv *MainView) SetClearSessionHandler(handler func()) {
+	mv.clearSessionHandler = handler
+}
+
+// UI update methods - called by controller. Components queue their own
+// widget updates with fyne.Do, so methods that only delegate call them directly.
 
 // SetOriginalImage updates the original image display
 func (mv *MainView) SetOriginalImage(img image.Image) {
-	fyne.Do(func() {
-		mv.imageDisplay.SetOriginalImage(img)
-	})
+	mv.imageDisplay.SetOriginalImage(img)
 }
 
 // SetProcessedImage updates the processed image display
 func (mv *MainView) SetProcessedImage(img image.Image) {
-	fyne.Do(func() {
-		mv.imageDisplay.SetProcessedImage(img)
-	})
+	mv.imageDisplay.SetProcessedImage(img)
+}
+
+// SetDifferenceImage supplies the difference view for the processed image
+func (mv *MainView) SetDifferenceImage(img image.Image) {
+	mv.imageDisplay.SetDifferenceImage(img)
+}
+
+// SetThresholds marks the active threshold values on the image histograms
+func (mv *MainView) SetThresholds(thresholds []uint8) {
+	mv.imageDisplay.SetThresholds(thresholds)
+}
+
+// SetThresholdValue shows the threshold chosen by the last run, or clears the
+// field for algorithms that report none
+func (mv *MainView) SetThresholdValue(value float64, ok bool) {
+	if ok {
+		mv.toolbar.SetThreshold(value)
+	} else {
+		mv.toolbar.ClearThreshold()
+	}
+}
+
+// SetStackInfo updates the stack navigator, hidden for fewer than two slices
+func (mv *MainView) SetStackInfo(current, total int) {
+	mv.imageDisplay.SetStackInfo(current, total)
+}
+
+// SetStackSharpness shows the focus score of each stack slice
+func (mv *MainView) SetStackSharpness(scores []float64) {
+	mv.imageDisplay.SetStackSharpness(scores)
+}
+
+// SetErrorMapAvailable enables the error map toggle when ground truth is loaded
+func (mv *MainView) SetErrorMapAvailable(available bool) {
+	mv.imageDisplay.SetErrorMapAvailable(available)
+}
+
+// SetErrorMap shows or clears the ground truth error map overlay
+func (mv *MainView) SetErrorMap(img image.Image) {
+	mv.imageDisplay.SetErrorMap(img)
+}
+
+// SetROI outlines the region of interest and updates the clear ROI control
+func (mv *MainView) SetROI(roi *image.Rectangle) {
+	mv.imageDisplay.SetROI(roi)
+	mv.toolbar.SetROIActive(roi != nil)
+}
+
+// SetAnnotations replaces the annotations drawn on the original image
+func (mv *MainView) SetAnnotations(annotations []models.Annotation) {
+	drawn := make([]components.Annotation, len(annotations))
+	for i, annotation := range annotations {
+		drawn[i] = components.Annotation{
+			Tool:   annotationTools[annotation.Kind],
+			Points: annotation.Points,
+			Label:  annotation.Label,
+			Color:  annotation.Color,
+		}
+	}
+	mv.imageDisplay.SetAnnotations(drawn)
+}
+
+// annotationTools maps annotation kinds to the tools that draw them
+var annotationTools = map[models.AnnotationKind]components.AnnotationTool{
+	models.AnnotationFreehand:  components.AnnotationToolFreehand,
+	models.AnnotationRectangle: components.AnnotationToolRectangle,
+	models.AnnotationCircle:    components.AnnotationToolCircle,
+	models.AnnotationText:      components.AnnotationToolText,
+}
+
+// annotationToModel converts a drawn annotation to its stored form
+func annotationToModel(annotation components.Annotation) models.Annotation {
+	var kind models.AnnotationKind
+	for k, tool := range annotationTools {
+		if tool == annotation.Tool {
+			kind = k
+		}
+	}
+	return models.Annotation{
+		Kind:   kind,
+		Points: annotation.Points,
+		Label:  annotation.Label,
+		Color:  annotation.Color,
+	}
+}
+
+// SetPageInfo updates the page navigator for multi-page images
+func (mv *MainView) SetPageInfo(current, total int) {
+	mv.toolbar.SetPageInfo(current, total)
+}
+
+// SetPreviewImage shows a live parameter preview in the processed pane
+func (mv *MainView) SetPreviewImage(img image.Image) {
+	mv.imageDisplay.SetPreviewImage(img)
+}
+
+// SetConvergenceData shows the per-iteration history of the last run in the parameter panel
+func (mv *MainView) SetConvergenceData(records []convergence.ConvergenceRecord) {
+	mv.paramPanel.SetConvergenceData(records)
+}
+
+// AddAlgorithms makes additional algorithms selectable in the toolbar
+func (mv *MainView) AddAlgorithms(algorithms []string) {
+	mv.toolbar.AddAlgorithms(algorithms)
 }
 
 // UpdateAlgorithmParameters updates the parameter panel for a new algorithm
 func (mv *MainView) UpdateAlgorithmParameters(algorithm string, parameters map[string]interface{}) {
-	fyne.Do(func() {
-		mv.paramPanel.UpdateParameters(algorithm, parameters)
-		mv.toolbar.SetCurrentAlgorithm(algorithm)
-	})
+	mv.paramPanel.UpdateParameters(algorithm, parameters)
+	mv.toolbar.SetCurrentAlgorithm(algorithm)
 }
 
 // UpdateStatus updates the status bar message
 func (mv *MainView) UpdateStatus(status string) {
-	fyne.Do(func() {
-		mv.statusBar.SetStatus(status)
-	})
+	mv.statusBar.SetStatus(status)
 }
 
-// UpdateProcessingProgress updates the progress bar
-func (mv *MainView) UpdateProcessingProgress(stage string, progress float64) {
-	fyne.Do(func() {
-		mv.progressBar.SetProgress(progress)
-		mv.progressBar.SetStage(stage)
-	})
+// UpdateProcessingProgress updates the progress bar with the estimated time
+// remaining, which is hidden when eta is negative
+func (mv *MainView) UpdateProcessingProgress(stage string, progress float64, eta time.Duration) {
+	mv.progressBar.SetProgress(progress)
+	mv.progressBar.SetStage(stage)
+	mv.progressBar.SetETA(eta)
 }
 
 // SetProcessingActive updates UI state for processing
 func (mv *MainView) SetProcessingActive(active bool) {
-	fyne.Do(func() {
-		mv.toolbar.SetProcessingActive(active)
-		mv.progressBar.SetVisible(active)
-		
-		if active {
-			mv.progressBar.SetProgress(0.0)
-			mv.progressBar.SetStage("Initializing...")
-		} else {
-			mv.progressBar.SetProgress(1.0)
-			mv.progressBar.SetStage("Complete")
+	mv.toolbar.SetProcessingActive(active)
+	mv.progressBar.SetVisible(active)
+	
+	// Each run sends its own estimates
+	mv.progressBar.SetETA(-1)
+	if active {
+		mv.progressBar.SetProgress(0.0)
+		mv.progressBar.SetStage("Initializing...")
+	} else {
+		mv.progressBar.SetProgress(1.0)
+		mv.progressBar.SetStage("Complete")
+	}
+}
+
+// SetLoadingActive shows the loading progress and offers Cancel Load while
+// an image is being read
+func (mv *MainView) SetLoadingActive(active bool) {
+	mv.toolbar.SetLoadingActive(active)
+	mv.progressBar.SetVisible(active)
+	mv.progressBar.SetETA(-1)
+	mv.progressBar.SetProgress(0.0)
+	if active {
+		mv.progressBar.SetStage("Loading")
+	}
+}
+
+// SetQueueLength shows how many processing runs are waiting
+func (mv *MainView) SetQueueLength(pending int) {
+	mv.toolbar.SetQueueLength(pending)
+}
+
+// SetHistory lists previous processing runs with their IoU, oldest first
+func (mv *MainView) SetHistory(entries []models.AlgorithmHistoryEntry) {
+	labels := make([]string, len(entries))
+	for i, entry := range entries {
+		label := fmt.Sprintf("%s  %s", entry.Timestamp.Format("15:04:05"), entry.Algorithm)
+		if entry.Metrics != nil {
+			label += fmt.Sprintf("  IoU %.3f", entry.Metrics.IoU)
 		}
-	})
+		labels[i] = label
+	}
+	mv.historyPanel.SetEntries(labels)
 }
 
 // UpdateSegmentationMetrics updates the metrics display
 func (mv *MainView) UpdateSegmentationMetrics(metrics *models.SegmentationMetrics) {
 	if metrics == nil {
+		// Negative values show every metric as unavailable
+		mv.toolbar.SetSegmentationMetrics(-1, -1, -1, -1, -1, -1, -1, 0, 0)
 		return
 	}
 
-	fyne.Do(func() {
-		mv.toolbar.SetSegmentationMetrics(
-			metrics.IoU,
-			metrics.DiceCoefficient,
-			metrics.MisclassificationError,
-			metrics.RegionUniformity,
-			metrics.BoundaryAccuracy,
-		)
-	})
+	mv.toolbar.SetSegmentationMetrics(
+		metrics.IoU,
+		metrics.DiceCoefficient,
+		metrics.MisclassificationError,
+		metrics.RegionUniformity,
+		metrics.BoundaryAccuracy,
+		metrics.HausdorffDistance,
+		metrics.HausdorffDistance95,
+		metrics.PSNR,
+		metrics.SSIM,
+	)
 }
 
 // ShowError displays an error dialog
@@ -264,14 +1090,176 @@ func (mv *MainView) ShowConfirm(title, message string, callback func(bool)) {
 // ShowFileDialog displays a file selection dialog
 func (mv *MainView) ShowFileDialog(callback func(fyne.URIReadCloser, error)) {
 	fyne.Do(func() {
-		dialog.ShowFileOpen(callback, mv.window)
+		openDialog := dialog.NewFileOpen(callback, mv.window)
+		openDialog.SetFilter(storage.NewExtensionFileFilter(models.OpenFormatExtensions))
+		openDialog.Show()
+	})
+}
+
+// ShowOpenDialog displays a file selection dialog limited to extensions
+func (mv *MainView) ShowOpenDialog(extensions []string, callback func(fyne.URIReadCloser, error)) {
+	fyne.Do(func() {
+		openDialog := dialog.NewFileOpen(callback, mv.window)
+		openDialog.SetFilter(storage.NewExtensionFileFilter(extensions))
+		openDialog.Show()
 	})
 }
 
 // ShowSaveDialog displays a file save dialog
 func (mv *MainView) ShowSaveDialog(callback func(fyne.URIWriteCloser, error)) {
 	fyne.Do(func() {
-		dialog.ShowFileSave(callback, mv.window)
+		saveDialog := dialog.NewFileSave(callback, mv.window)
+		saveDialog.SetFilter(storage.NewExtensionFileFilter(models.SaveFormatExtensions))
+		saveDialog.Show()
+	})
+}
+
+// ShowImageSaveDialog displays a save dialog for images suggesting fileName.
+// A name typed without an extension gets defaultExtension, and a location that
+// cannot be written reports the error and reopens the dialog.
+func (mv *MainView) ShowImageSaveDialog(fileName, defaultExtension string, callback func(fyne.URIWriteCloser, error)) {
+	fyne.Do(func() {
+		mv.showImageSaveDialog(fileName, defaultExtension, callback)
+	})
+}
+
+func (mv *MainView) showImageSaveDialog(fileName, defaultExtension string, callback func(fyne.URIWriteCloser, error)) {
+	saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err == nil && writer != nil {
+			writer, err = withDefaultExtension(writer, defaultExtension)
+		}
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("cannot save to the selected location: %w", err), mv.window)
+			mv.showImageSaveDialog(fileName, defaultExtension, callback)
+			return
+		}
+		callback(writer, nil)
+	}, mv.window)
+	saveDialog.SetFileName(fileName)
+	saveDialog.SetFilter(storage.NewExtensionFileFilter(models.SaveFormatExtensions))
+	saveDialog.Show()
+}
+
+// withDefaultExtension reopens writer at its path plus extension when the
+// chosen name has no extension, removing the empty file the dialog created
+func withDefaultExtension(writer fyne.URIWriteCloser, extension string) (fyne.URIWriteCloser, error) {
+	uri := writer.URI()
+	if uri.Extension() != "" {
+		return writer, nil
+	}
+
+	writer.Close()
+	if err := storage.Delete(uri); err != nil {
+		return nil, err
+	}
+
+	target, err := storage.ParseURI(uri.String() + extension)
+	if err != nil {
+		return nil, err
+	}
+	return storage.Writer(target)
+}
+
+// ShowExportDialog displays a file save dialog limited to the given extensions
+func (mv *MainView) ShowExportDialog(fileName string, extensions []string, callback func(fyne.URIWriteCloser, error)) {
+	fyne.Do(func() {
+		saveDialog := dialog.NewFileSave(callback, mv.window)
+		saveDialog.SetFileName(fileName)
+		saveDialog.SetFilter(storage.NewExtensionFileFilter(extensions))
+		saveDialog.Show()
+	})
+}
+
+// ShowFolderDialog displays a directory selection dialog
+func (mv *MainView) ShowFolderDialog(callback func(fyne.ListableURI, error)) {
+	fyne.Do(func() {
+		dialog.ShowFolderOpen(callback, mv.window)
+	})
+}
+
+// ShowBatchSummary displays the outcome of a batch run with an option to export it
+func (mv *MainView) ShowBatchSummary(total, failed int, meanTime time.Duration, meanIoU float64, exportHandler func()) {
+	fyne.Do(func() {
+		content := container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("Images processed: %d", total-failed)),
+			widget.NewLabel(fmt.Sprintf("Failed: %d", failed)),
+			widget.NewLabel(fmt.Sprintf("Mean processing time: %v", meanTime.Round(time.Millisecond))),
+			widget.NewLabel(fmt.Sprintf("Mean IoU: %.3f", meanIoU)),
+			widget.NewButton("Export CSV...", func() {
+				if exportHandler != nil {
+					exportHandler()
+				}
+			}),
+		)
+
+		dialog.ShowCustom("Batch Complete", "Close", content, mv.window)
+	})
+}
+
+// ShowSensitivityResults displays parameters ranked by the slope of IoU
+// against their normalised value, most sensitive first. reference names what
+// the IoU was measured against.
+func (mv *MainView) ShowSensitivityResults(algorithm, reference string, names []string, slopes []float64) {
+	fyne.Do(func() {
+		content := container.NewVBox(
+			widget.NewLabel(fmt.Sprintf("%s: IoU against %s", algorithm, reference)),
+			widget.NewLabel("Slope of IoU from the minimum to the maximum of each parameter"),
+			widget.NewSeparator(),
+			components.NewSensitivityChart(names, slopes),
+		)
+
+		dialog.ShowCustom("Sensitivity Analysis", "Close", content, mv.window)
+	})
+}
+
+// ShowValidationErrors lists the problems that stopped a file from being
+// applied, one per row below summary
+func (mv *MainView) ShowValidationErrors(title, summary string, problems []string) {
+	fyne.Do(func() {
+		list := widget.NewList(
+			func() int { return len(problems) },
+			func() fyne.CanvasObject {
+				label := widget.NewLabel("")
+				label.Wrapping = fyne.TextWrapWord
+				return label
+			},
+			func(id widget.ListItemID, item fyne.CanvasObject) {
+				item.(*widget.Label).SetText(problems[id])
+			},
+		)
+		list.OnSelected = func(widget.ListItemID) { list.UnselectAll() }
+
+		scroll := container.NewScroll(list)
+		scroll.SetMinSize(fyne.NewSize(520, 240))
+		header := widget.NewLabel(summary)
+		header.Wrapping = fyne.TextWrapWord
+		content := container.NewBorder(header, nil, nil, nil, scroll)
+
+		dialog.ShowCustom(title, "Close", content, mv.window)
+	})
+}
+
+// ShowMacro displays macro source in a read-only text area with a button
+// copying it to the clipboard
+func (mv *MainView) ShowMacro(title, source string) {
+	fyne.Do(func() {
+		text := widget.NewMultiLineEntry()
+		text.SetText(source)
+		text.TextStyle = fyne.TextStyle{Monospace: true}
+		text.Wrapping = fyne.TextWrapOff
+		text.Disable()
+
+		var copyButton *widget.Button
+		copyButton = widget.NewButton("Copy to Clipboard", func() {
+			fyne.CurrentApp().Clipboard().SetContent(source)
+			copyButton.SetText("Copied")
+		})
+
+		scroll := container.NewScroll(text)
+		scroll.SetMinSize(fyne.NewSize(560, 400))
+		content := container.NewBorder(nil, copyButton, nil, nil, scroll)
+
+		dialog.ShowCustom(title, "Close", content, mv.window)
 	})
 }
 
@@ -294,34 +1282,36 @@ func (mv *MainView) SetWindowTitle(title string) {
 
 // EnableImageOperations enables/disables image-dependent operations
 func (mv *MainView) EnableImageOperations(enabled bool) {
-	fyne.Do(func() {
-		mv.toolbar.EnableImageOperations(enabled)
-	})
+	mv.toolbar.EnableImageOperations(enabled)
 }
 
 // SetImageInfo updates image information display
 func (mv *MainView) SetImageInfo(width, height, channels int, format string) {
-	fyne.Do(func() {
-		mv.statusBar.SetImageInfo(width, height, channels, format)
-	})
+	mv.statusBar.SetImageInfo(width, height, channels, format)
+}
+
+// SetImageMetadata shows format-specific header attributes; nil hides them
+func (mv *MainView) SetImageMetadata(tags map[string]string) {
+	mv.statusBar.SetMetadata(tags)
 }
 
 // SetMemoryInfo updates memory usage information
 func (mv *MainView) SetMemoryInfo(used, total int64) {
-	fyne.Do(func() {
-		mv.statusBar.SetMemoryInfo(used, total)
-	})
+	mv.statusBar.SetMemoryInfo(used, total)
+}
+
+// ShowToast briefly shows a non-blocking notification in the status bar
+func (mv *MainView) ShowToast(message string, duration time.Duration) {
+	mv.statusBar.ShowToast(message, duration)
 }
 
 // ResetView resets the view to initial state
 func (mv *MainView) ResetView() {
-	fyne.Do(func() {
-		mv.imageDisplay.ClearImages()
-		mv.paramPanel.Reset()
-		mv.statusBar.Reset()
-		mv.progressBar.Reset()
-		mv.toolbar.Reset()
-	})
+	mv.imageDisplay.ClearImages()
+	mv.paramPanel.Reset()
+	mv.statusBar.Reset()
+	mv.progressBar.Reset()
+	mv.toolbar.Reset()
 }
 
 // Show displays the view
@@ -352,11 +1342,17 @@ func (mv *MainView) Refresh() {
 	})
 }
 
+// ApplyAppearance sets the theme ("light", "dark", "auto", "analysis_dark" or
+// "analysis_light") and UI scale
+func (mv *MainView) ApplyAppearance(themeName string, scale float64) {
+	mv.SetTheme(newAppearanceTheme(themeName, scale))
+}
+
 // SetTheme applies a theme to the view
 func (mv *MainView) SetTheme(theme fyne.Theme) {
 	fyne.Do(func() {
 		fyne.CurrentApp().Settings().SetTheme(theme)
-		mv.Refresh()
+		mv.mainContainer.Refresh()
 	})
 }
 
@@ -403,14 +1399,12 @@ func (mv *MainView) GetViewState() ViewState {
 
 // ApplyViewState applies a view state
 func (mv *MainView) ApplyViewState(state ViewState) {
-	fyne.Do(func() {
-		mv.toolbar.SetCurrentAlgorithm(state.CurrentAlgorithm)
-		mv.statusBar.SetStatus(state.StatusMessage)
-		mv.progressBar.SetProgress(state.ProgressValue)
-		mv.progressBar.SetStage(state.ProgressStage)
-		mv.progressBar.SetVisible(state.IsProcessing)
-		mv.toolbar.SetProcessingActive(state.IsProcessing)
-	})
+	mv.toolbar.SetCurrentAlgorithm(state.CurrentAlgorithm)
+	mv.statusBar.SetStatus(state.StatusMessage)
+	mv.progressBar.SetProgress(state.ProgressValue)
+	mv.progressBar.SetStage(state.ProgressStage)
+	mv.progressBar.SetVisible(state.IsProcessing)
+	mv.toolbar.SetProcessingActive(state.IsProcessing)
 }
 
 // UpdateLayout adjusts the layout based on window size
@@ -441,6 +1435,7 @@ func (mv *MainView) adjustForWideLayout() {
 	
 	rightPanel := container.NewVBox(
 		mv.paramPanel.GetContainer(),
+		mv.historyPanel.GetContainer(),
 	)
 	
 	contentArea := container.NewHSplit(leftPanel, rightPanel)
@@ -455,8 +1450,8 @@ func (mv *MainView) adjustForWideLayout() {
 	mv.mainContainer = container.NewBorder(
 		topArea,
 		mv.statusBar.GetContainer(),
-		nil,
-		nil,
+		mv.metadataSidebar.GetContainer(),
+		mv.jointHistogramPanel,
 		contentArea,
 	)
 	
@@ -469,6 +1464,7 @@ func (mv *MainView) adjustForTallLayout() {
 	contentArea := container.NewVBox(
 		mv.imageDisplay.GetContainer(),
 		mv.paramPanel.GetContainer(),
+		mv.historyPanel.GetContainer(),
 	)
 	
 	topArea := container.NewVBox(
@@ -480,8 +1476,8 @@ func (mv *MainView) adjustForTallLayout() {
 	mv.mainContainer = container.NewBorder(
 		topArea,
 		mv.statusBar.GetContainer(),
-		nil,
-		nil,
+		mv.metadataSidebar.GetContainer(),
+		mv.jointHistogramPanel,
 		contentArea,
 	)
 	
@@ -562,24 +1558,240 @@ func (mv *MainView) ShowAboutDialog(appName, version, description string) {
 	})
 }
 
-// ShowPreferences displays application preferences dialog
+// ShowPreferences displays the application preferences dialog
 func (mv *MainView) ShowPreferences() {
-	fyne.Do(func() {
-		// Create preferences dialog content
-		content := container.NewVBox(
-			widget.NewLabel("Application Preferences"),
-			widget.NewSeparator(),
-			widget.NewLabel("Coming soon..."),
-		)
-		
-		dialog.ShowCustom("Preferences", "Close", content, mv.window)
-	})
+	fyne.Do(mv.showPreferences)
 }
 
-// max returns the maximum of two float32 values
-func max(a, b float32) float32 {
-	if a > b {
-		return a
+// showPreferences builds and shows the preferences dialog on the UI goroutine
+func (mv *MainView) showPreferences() {
+	settings := map[string]interface{}{}
+	if mv.settingsLoader != nil {
+		settings = mv.settingsLoader()
+	}
+	changed := func(key string, value interface{}) {
+		if mv.settingChangeHandler != nil {
+			mv.settingChangeHandler(key, value)
+		}
+	}
+
+	previewCheck := widget.NewCheck("Live parameter preview", func(enabled bool) {
+		mv.previewEnabled = enabled
+		if mv.previewToggleHandler != nil {
+			mv.previewToggleHandler(enabled)
+		}
+	})
+	previewCheck.SetChecked(mv.previewEnabled)
+
+	// Change callbacks are attached after the initial values so opening
+	// the dialog does not write every setting back
+	debugCheck := widget.NewCheck("Show debug information", nil)
+	debugCheck.SetChecked(settingBool(settings, "show_debug_info"))
+	debugCheck.OnChanged = func(enabled bool) {
+		changed("show_debug_info", enabled)
+	}
+
+	recommendationsCheck := widget.NewCheck("Suggest preprocessing and an algorithm for loaded images", nil)
+	recommendationsCheck.SetChecked(settingBool(settings, "show_recommendations"))
+	recommendationsCheck.OnChanged = func(enabled bool) {
+		changed("show_recommendations", enabled)
+	}
+
+	filenameCheck := widget.NewCheck("Read parameters from image filenames", nil)
+	filenameCheck.SetChecked(settingBool(settings, "filename_parameters"))
+	filenameCheck.OnChanged = func(enabled bool) {
+		changed("filename_parameters", enabled)
 	}
-	return b
-}
\ No newline at end of file
+
+	burnCheck := widget.NewCheck("Burn annotations into saved images", nil)
+	burnCheck.SetChecked(settingBool(settings, "burn_annotations"))
+	burnCheck.OnChanged = func(enabled bool) {
+		changed("burn_annotations", enabled)
+	}
+
+	autoSaveCheck := widget.NewCheck("Auto-save processed images", nil)
+	autoSaveCheck.SetChecked(settingBool(settings, "auto_save"))
+	autoSaveCheck.OnChanged = func(enabled bool) {
+		changed("auto_save", enabled)
+	}
+
+	autoSaveInterval := settingInt(settings, "auto_save_interval_seconds")
+	autoSaveLabel := widget.NewLabel(fmt.Sprintf("Auto-save Interval: %d s", autoSaveInterval))
+	autoSaveSlider := widget.NewSlider(5, 300)
+	autoSaveSlider.Step = 5
+	autoSaveSlider.SetValue(float64(autoSaveInterval))
+	autoSaveSlider.OnChanged = func(value float64) {
+		autoSaveLabel.SetText(fmt.Sprintf("Auto-save Interval: %d s", int(value)))
+	}
+	autoSaveSlider.OnChangeEnded = func(value float64) {
+		changed("auto_save_interval_seconds", int(value))
+	}
+
+	// Laplace noise for patient data; smaller budgets add more noise
+	dpCheck := widget.NewCheck("Differential privacy noise", nil)
+	dpCheck.SetChecked(settingBool(settings, "enable_dp"))
+	dpCheck.OnChanged = func(enabled bool) {
+		changed("enable_dp", enabled)
+	}
+
+	dpEpsilon := settingFloat(settings, "dp_epsilon")
+	dpLabel := widget.NewLabel(fmt.Sprintf("Privacy Budget ε: %.1f", dpEpsilon))
+	dpSlider := widget.NewSlider(0.1, 10)
+	dpSlider.Step = 0.1
+	dpSlider.SetValue(dpEpsilon)
+	dpSlider.OnChanged = func(value float64) {
+		dpLabel.SetText(fmt.Sprintf("Privacy Budget ε: %.1f", value))
+	}
+	dpSlider.OnChangeEnded = func(value float64) {
+		changed("dp_epsilon", value)
+	}
+
+	// A fixed seed repeats differential privacy noise and Auto-Tune sampling
+	seedEntry := widget.NewEntry()
+	seedEntry.SetPlaceHolder(strconv.Itoa(processing.DefaultFixedSeed))
+	seedCheck := widget.NewCheck("Fixed seed", nil)
+	if seed := settingInt(settings, "random_seed"); seed != 0 {
+		seedCheck.SetChecked(true)
+		seedEntry.SetText(strconv.Itoa(seed))
+	} else {
+		seedEntry.Disable()
+	}
+	fixedSeed := func() int {
+		seed, err := strconv.Atoi(strings.TrimSpace(seedEntry.Text))
+		if err != nil || seed == 0 {
+			return processing.DefaultFixedSeed
+		}
+		return seed
+	}
+	seedCheck.OnChanged = func(enabled bool) {
+		if !enabled {
+			seedEntry.Disable()
+			changed("random_seed", 0)
+			return
+		}
+		seedEntry.Enable()
+		changed("random_seed", fixedSeed())
+	}
+	seedEntry.OnSubmitted = func(string) {
+		if seedCheck.Checked {
+			changed("random_seed", fixedSeed())
+		}
+	}
+
+	formatSelect := widget.NewSelect(models.SupportedSaveFormats, nil)
+	formatSelect.SetSelected(settingString(settings, "default_save_format"))
+	formatSelect.OnChanged = func(format string) {
+		changed("default_save_format", format)
+	}
+
+	jpegQuality := settingInt(settings, "jpeg_quality")
+	jpegLabel := widget.NewLabel(fmt.Sprintf("JPEG Quality: %d", jpegQuality))
+	jpegSlider := widget.NewSlider(1, 100)
+	jpegSlider.SetValue(float64(jpegQuality))
+	jpegSlider.OnChanged = func(value float64) {
+		jpegLabel.SetText(fmt.Sprintf("JPEG Quality: %d", int(value)))
+	}
+	jpegSlider.OnChangeEnded = func(value float64) {
+		changed("jpeg_quality", int(value))
+	}
+
+	themeNames := map[string]string{
+		"Light": "light", "Dark": "dark", "Auto": "auto",
+		"Dark Analysis": "analysis_dark", "Light Analysis": "analysis_light",
+	}
+	themeSelect := widget.NewSelect([]string{"Light", "Dark", "Auto", "Dark Analysis", "Light Analysis"}, nil)
+	for label, name := range themeNames {
+		if name == settingString(settings, "ui_theme") {
+			themeSelect.SetSelected(label)
+		}
+	}
+	themeSelect.OnChanged = func(label string) {
+		changed("ui_theme", themeNames[label])
+	}
+
+	scales := map[string]float64{"1.0×": 1.0, "1.25×": 1.25, "1.5×": 1.5, "2.0×": 2.0}
+	scaleSelect := widget.NewSelect([]string{"1.0×", "1.25×", "1.5×", "2.0×"}, nil)
+	for label, scale := range scales {
+		if scale == settingFloat(settings, "ui_scale") {
+			scaleSelect.SetSelected(label)
+		}
+	}
+	scaleSelect.OnChanged = func(label string) {
+		changed("ui_scale", scales[label])
+	}
+
+	memoryLimit := settingFloat(settings, "memory_limit_gib")
+	memoryLabel := widget.NewLabel(fmt.Sprintf("Memory Limit: %.0f GiB", memoryLimit))
+	memorySlider := widget.NewSlider(1, 32)
+	memorySlider.SetValue(memoryLimit)
+	memorySlider.OnChanged = func(value float64) {
+		memoryLabel.SetText(fmt.Sprintf("Memory Limit: %.0f GiB", value))
+	}
+	memorySlider.OnChangeEnded = func(value float64) {
+		changed("memory_limit_gib", value)
+	}
+
+	form := widget.NewForm(
+		widget.NewFormItem("Save format", formatSelect),
+		widget.NewFormItem("Theme", themeSelect),
+		widget.NewFormItem("UI scale", scaleSelect),
+	)
+
+	var preferencesDialog dialog.Dialog
+	resetButton := widget.NewButton("Reset to Defaults", func() {
+		if mv.settingsResetHandler != nil {
+			mv.settingsResetHandler()
+		}
+		// Reopen so every control shows the restored value
+		preferencesDialog.Hide()
+		mv.showPreferences()
+	})
+
+	content := container.NewVBox(
+		widget.NewLabel("Application Preferences"),
+		widget.NewSeparator(),
+		previewCheck,
+		debugCheck,
+		recommendationsCheck,
+		filenameCheck,
+		burnCheck,
+		autoSaveCheck,
+		form,
+		container.NewVBox(jpegLabel, jpegSlider),
+		container.NewVBox(autoSaveLabel, autoSaveSlider),
+		container.NewVBox(memoryLabel, memorySlider),
+		dpCheck,
+		container.NewVBox(dpLabel, dpSlider),
+		container.NewBorder(nil, nil, seedCheck, nil, seedEntry),
+		widget.NewSeparator(),
+		resetButton,
+	)
+
+	preferencesDialog = dialog.NewCustom("Preferences", "Close", content, mv.window)
+	preferencesDialog.Resize(fyne.NewSize(420, 0))
+	preferencesDialog.Show()
+}
+
+// settingBool returns a boolean preference value, false when missing
+func settingBool(settings map[string]interface{}, key string) bool {
+	value, _ := settings[key].(bool)
+	return value
+}
+
+// settingInt returns an integer preference value, 0 when missing
+func settingInt(settings map[string]interface{}, key string) int {
+	value, _ := settings[key].(int)
+	return value
+}
+
+// settingFloat returns a float preference value, 0 when missing
+func settingFloat(settings map[string]interface{}, key string) float64 {
+	value, _ := settings[key].(float64)
+	return value
+}
+
+// settingString returns a string preference value, empty when missing
+func settingString(settings map[string]interface{}, key string) string {
+	value, _ := settings[key].(string)
+	return value
+}
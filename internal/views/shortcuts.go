@@ -0,0 +1,223 @@
+package views
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"otsu-obliterator/internal/logger"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// ShortcutsFileName is the name of the shortcut definitions, both the
+// embedded defaults and the user's copy in the config directory
+const ShortcutsFileName = "shortcuts.json"
+
+// defaultShortcuts maps each action to its key combinations, such as
+// "Ctrl+O" or "F5"
+//
+//go:embed shortcuts.json
+var defaultShortcuts []byte
+
+// namedKeys are the keys other than letters and digits a shortcut can use
+var namedKeys = map[string]fyne.KeyName{
+	"ESCAPE": fyne.KeyEscape, "RETURN": fyne.KeyReturn, "ENTER": fyne.KeyEnter,
+	"TAB": fyne.KeyTab, "BACKSPACE": fyne.KeyBackspace, "INSERT": fyne.KeyInsert,
+	"DELETE": fyne.KeyDelete, "HOME": fyne.KeyHome, "END": fyne.KeyEnd,
+	"PAGEUP": fyne.KeyPageUp, "PAGEDOWN": fyne.KeyPageDown, "SPACE": fyne.KeySpace,
+	"UP": fyne.KeyUp, "DOWN": fyne.KeyDown, "LEFT": fyne.KeyLeft, "RIGHT": fyne.KeyRight,
+	"F1": fyne.KeyF1, "F2": fyne.KeyF2, "F3": fyne.KeyF3, "F4": fyne.KeyF4,
+	"F5": fyne.KeyF5, "F6": fyne.KeyF6, "F7": fyne.KeyF7, "F8": fyne.KeyF8,
+	"F9": fyne.KeyF9, "F10": fyne.KeyF10, "F11": fyne.KeyF11, "F12": fyne.KeyF12,
+}
+
+// keyCombination is a parsed shortcut; a zero modifier is a plain key press
+type keyCombination struct {
+	key      fyne.KeyName
+	modifier fyne.KeyModifier
+}
+
+// KeyboardShortcutManager binds key combinations from shortcut definitions
+// to registered actions. Combinations with a modifier become canvas
+// shortcuts; plain keys such as F5 and Escape are taken from the canvas
+// typed key events, which only arrive while no widget has focus.
+type KeyboardShortcutManager struct {
+	window  fyne.Window
+	actions map[string]func()
+	logger  logger.Logger
+
+	bound []fyne.Shortcut
+	plain map[fyne.KeyName]string
+}
+
+// NewKeyboardShortcutManager creates a manager for the window's canvas
+func NewKeyboardShortcutManager(window fyne.Window) *KeyboardShortcutManager {
+	return &KeyboardShortcutManager{
+		window:  window,
+		actions: make(map[string]func()),
+		plain:   make(map[fyne.KeyName]string),
+	}
+}
+
+// SetLogger enables warnings about invalid and conflicting shortcuts
+func (km *KeyboardShortcutManager) SetLogger(log logger.Logger) {
+	km.logger = log
+}
+
+// Register names an action shortcut definitions can bind keys to
+func (km *KeyboardShortcutManager) Register(action string, fn func()) {
+	km.actions[action] = fn
+}
+
+// DefaultShortcutDefinitions returns the embedded shortcut definitions
+func DefaultShortcutDefinitions() (map[string][]string, error) {
+	var definitions map[string][]string
+	if err := json.Unmarshal(defaultShortcuts, &definitions); err != nil {
+		return nil, fmt.Errorf("invalid embedded shortcuts: %w", err)
+	}
+	return definitions, nil
+}
+
+// LoadShortcutDefinitions returns the embedded definitions with the actions
+// of the user's copy in the appDirName config folder, if there is one,
+// replacing theirs. An unreadable copy is reported and ignored.
+func LoadShortcutDefinitions(appDirName string) (map[string][]string, error) {
+	definitions, err := DefaultShortcutDefinitions()
+	if err != nil {
+		return nil, err
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return definitions, nil
+	}
+	path := filepath.Join(configDir, appDirName, ShortcutsFileName)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return definitions, nil
+	}
+	if err != nil {
+		return definitions, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var custom map[string][]string
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return definitions, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	for action, keys := range custom {
+		definitions[action] = keys
+	}
+	return definitions, nil
+}
+
+// Apply replaces the bound shortcuts with definitions. Unknown actions,
+// unreadable key combinations and combinations bound to more than one action
+// are logged as warnings; a conflicting combination keeps the action that
+// comes first alphabetically.
+func (km *KeyboardShortcutManager) Apply(definitions map[string][]string) {
+	canvas := km.window.Canvas()
+	for _, shortcut := range km.bound {
+		canvas.RemoveShortcut(shortcut)
+	}
+	km.bound = nil
+	km.plain = make(map[fyne.KeyName]string)
+
+	actions := make([]string, 0, len(definitions))
+	for action := range definitions {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+
+	owners := make(map[keyCombination]string)
+	for _, action := range actions {
+		fn, known := km.actions[action]
+		if !known {
+			km.warn("Unknown shortcut action", map[string]interface{}{"action": action})
+			continue
+		}
+
+		for _, keys := range definitions[action] {
+			combination, err := parseKeyCombination(keys)
+			if err != nil {
+				km.warn("Invalid shortcut", map[string]interface{}{
+					"action": action,
+					"keys":   keys,
+					"error":  err.Error(),
+				})
+				continue
+			}
+			if owner, taken := owners[combination]; taken {
+				km.warn("Conflicting shortcut ignored", map[string]interface{}{
+					"keys":     keys,
+					"action":   action,
+					"bound_to": owner,
+				})
+				continue
+			}
+			owners[combination] = action
+
+			if combination.modifier == 0 {
+				km.plain[combination.key] = action
+				continue
+			}
+			shortcut := &desktop.CustomShortcut{KeyName: combination.key, Modifier: combination.modifier}
+			canvas.AddShortcut(shortcut, func(fyne.Shortcut) { fn() })
+			km.bound = append(km.bound, shortcut)
+		}
+	}
+
+	canvas.SetOnTypedKey(func(event *fyne.KeyEvent) {
+		if action, ok := km.plain[event.Name]; ok {
+			km.actions[action]()
+		}
+	})
+}
+
+// warn logs a shortcut problem when a logger is set
+func (km *KeyboardShortcutManager) warn(msg string, fields map[string]interface{}) {
+	if km.logger != nil {
+		km.logger.Warning(msg, fields)
+	}
+}
+
+// parseKeyCombination reads combinations such as "Ctrl+Shift+S" or "F5".
+// Ctrl is the platform's shortcut modifier, Command on macOS; Cmd and Super
+// name the Command or Windows key itself.
+func parseKeyCombination(text string) (keyCombination, error) {
+	parts := strings.Split(text, "+")
+	var combination keyCombination
+
+	for _, part := range parts[:len(parts)-1] {
+		switch strings.ToUpper(strings.TrimSpace(part)) {
+		case "CTRL", "CONTROL":
+			combination.modifier |= fyne.KeyModifierShortcutDefault
+		case "SHIFT":
+			combination.modifier |= fyne.KeyModifierShift
+		case "ALT", "OPTION":
+			combination.modifier |= fyne.KeyModifierAlt
+		case "CMD", "COMMAND", "SUPER":
+			combination.modifier |= fyne.KeyModifierSuper
+		default:
+			return keyCombination{}, fmt.Errorf("unknown modifier %q", part)
+		}
+	}
+
+	key := strings.ToUpper(strings.TrimSpace(parts[len(parts)-1]))
+	switch {
+	case len(key) == 1 && (key[0] >= 'A' && key[0] <= 'Z' || key[0] >= '0' && key[0] <= '9'):
+		combination.key = fyne.KeyName(key)
+	case namedKeys[key] != "":
+		combination.key = namedKeys[key]
+	default:
+		return keyCombination{}, fmt.Errorf("unknown key %q", parts[len(parts)-1])
+	}
+	return combination, nil
+}
@@ -0,0 +1,68 @@
+package views
+
+import (
+	"image/color"
+
+	analysistheme "otsu-obliterator/internal/views/theme"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// appearanceTheme wraps the default theme to force a light or dark variant
+// and scale every size by a user chosen factor
+type appearanceTheme struct {
+	base    fyne.Theme
+	variant fyne.ThemeVariant
+	forced  bool
+	scale   float32
+}
+
+// newAppearanceTheme creates a theme for the ui_theme ("light", "dark",
+// "auto", "analysis_dark" or "analysis_light") and ui_scale preferences
+func newAppearanceTheme(name string, scale float64) *appearanceTheme {
+	t := &appearanceTheme{
+		base:  theme.DefaultTheme(),
+		scale: float32(scale),
+	}
+	if t.scale <= 0 {
+		t.scale = 1
+	}
+
+	switch name {
+	case "light":
+		t.variant, t.forced = theme.VariantLight, true
+	case "dark":
+		t.variant, t.forced = theme.VariantDark, true
+	case "analysis_dark":
+		analysis := analysistheme.NewAnalysisTheme()
+		t.base, t.variant, t.forced = analysis, analysis.Variant(), true
+	case "analysis_light":
+		analysis := analysistheme.NewLightAnalysisTheme()
+		t.base, t.variant, t.forced = analysis, analysis.Variant(), true
+	}
+	return t
+}
+
+// Color returns the base theme colour in the forced variant, if any
+func (t *appearanceTheme) Color(name fyne.ThemeColorName, variant fyne.ThemeVariant) color.Color {
+	if t.forced {
+		variant = t.variant
+	}
+	return t.base.Color(name, variant)
+}
+
+// Font returns the base theme font
+func (t *appearanceTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return t.base.Font(style)
+}
+
+// Icon returns the base theme icon
+func (t *appearanceTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return t.base.Icon(name)
+}
+
+// Size returns the base theme size multiplied by the UI scale
+func (t *appearanceTheme) Size(name fyne.ThemeSizeName) float32 {
+	return t.base.Size(name) * t.scale
+}
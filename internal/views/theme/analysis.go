@@ -0,0 +1,159 @@
+// Package theme provides application themes tuned for image analysis, where
+// the UI must stay out of the way of the image being judged
+package theme
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// ColorNameImageBackground is the colour behind image panes. Themes that do
+// not define it leave the panes at DefaultImageBackground.
+const ColorNameImageBackground fyne.ThemeColorName = "imageBackground"
+
+// DefaultImageBackground is the image pane colour of the standard themes
+var DefaultImageBackground = color.NRGBA{R: 252, G: 252, B: 252, A: 255}
+
+// ImageBackground returns the image pane colour of t in variant
+func ImageBackground(t fyne.Theme, variant fyne.ThemeVariant) color.Color {
+	if t == nil {
+		return DefaultImageBackground
+	}
+	if c := t.Color(ColorNameImageBackground, variant); c != nil {
+		if _, _, _, a := c.RGBA(); a != 0 {
+			return c
+		}
+	}
+	return DefaultImageBackground
+}
+
+// AnalysisTheme is a low-distraction theme for judging segmentation results.
+// The dark palette keeps the UI near-black with a medium-grey image
+// background so dark images stay visible; the light palette puts images on
+// white with a grey UI. Both ignore the system variant.
+type AnalysisTheme struct {
+	palette map[fyne.ThemeColorName]color.Color
+	dark    bool
+}
+
+// NewAnalysisTheme creates the dark analysis theme
+func NewAnalysisTheme() *AnalysisTheme {
+	return &AnalysisTheme{palette: darkAnalysisPalette, dark: true}
+}
+
+// NewLightAnalysisTheme creates the light analysis theme
+func NewLightAnalysisTheme() *AnalysisTheme {
+	return &AnalysisTheme{palette: lightAnalysisPalette}
+}
+
+func hex(rgb uint32) color.NRGBA {
+	return color.NRGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 255}
+}
+
+func hexAlpha(rgb uint32, alpha uint8) color.NRGBA {
+	c := hex(rgb)
+	c.A = alpha
+	return c
+}
+
+// darkAnalysisPalette uses muted accents on #1A1A1A; foregrounds keep at
+// least 7:1 contrast so icons and labels remain legible
+var darkAnalysisPalette = map[fyne.ThemeColorName]color.Color{
+	ColorNameImageBackground:           hex(0x2A2A2A),
+	theme.ColorNameBackground:          hex(0x1A1A1A),
+	theme.ColorNameButton:              hex(0x303030),
+	theme.ColorNameDisabledButton:      hex(0x242424),
+	theme.ColorNameDisabled:            hex(0x6A6A6A),
+	theme.ColorNameError:               hex(0xC8605A),
+	theme.ColorNameFocus:               hexAlpha(0x5B8DB8, 0x7F),
+	theme.ColorNameForeground:          hex(0xE6E6E6),
+	theme.ColorNameForegroundOnError:   hex(0x1A1A1A),
+	theme.ColorNameForegroundOnPrimary: hex(0x1A1A1A),
+	theme.ColorNameForegroundOnSuccess: hex(0x1A1A1A),
+	theme.ColorNameForegroundOnWarning: hex(0x1A1A1A),
+	theme.ColorNameHeaderBackground:    hex(0x222222),
+	theme.ColorNameHover:               hexAlpha(0xFFFFFF, 0x0F),
+	theme.ColorNameHyperlink:           hex(0x7FA7C9),
+	theme.ColorNameInputBackground:     hex(0x242424),
+	theme.ColorNameInputBorder:         hex(0x4A4A4A),
+	theme.ColorNameMenuBackground:      hex(0x242424),
+	theme.ColorNameOverlayBackground:   hex(0x202020),
+	theme.ColorNamePlaceHolder:         hex(0x8A8A8A),
+	theme.ColorNamePressed:             hexAlpha(0xFFFFFF, 0x1E),
+	theme.ColorNamePrimary:             hex(0x5B8DB8),
+	theme.ColorNameScrollBar:           hexAlpha(0xFFFFFF, 0x66),
+	theme.ColorNameScrollBarBackground: hexAlpha(0xFFFFFF, 0x14),
+	theme.ColorNameSelection:           hexAlpha(0x5B8DB8, 0x55),
+	theme.ColorNameSeparator:           hex(0x333333),
+	theme.ColorNameShadow:              hexAlpha(0x000000, 0x66),
+	theme.ColorNameSuccess:             hex(0x6FA36F),
+	theme.ColorNameWarning:             hex(0xC9A04E),
+}
+
+// lightAnalysisPalette uses a grey UI around white image panes
+var lightAnalysisPalette = map[fyne.ThemeColorName]color.Color{
+	ColorNameImageBackground:           hex(0xFFFFFF),
+	theme.ColorNameBackground:          hex(0xE4E4E4),
+	theme.ColorNameButton:              hex(0xD4D4D4),
+	theme.ColorNameDisabledButton:      hex(0xDCDCDC),
+	theme.ColorNameDisabled:            hex(0x9A9A9A),
+	theme.ColorNameError:               hex(0xB03A32),
+	theme.ColorNameFocus:               hexAlpha(0x3C6E99, 0x7F),
+	theme.ColorNameForeground:          hex(0x1E1E1E),
+	theme.ColorNameForegroundOnError:   hex(0xFFFFFF),
+	theme.ColorNameForegroundOnPrimary: hex(0xFFFFFF),
+	theme.ColorNameForegroundOnSuccess: hex(0xFFFFFF),
+	theme.ColorNameForegroundOnWarning: hex(0x1E1E1E),
+	theme.ColorNameHeaderBackground:    hex(0xD8D8D8),
+	theme.ColorNameHover:               hexAlpha(0x000000, 0x0F),
+	theme.ColorNameHyperlink:           hex(0x2F5F8A),
+	theme.ColorNameInputBackground:     hex(0xF0F0F0),
+	theme.ColorNameInputBorder:         hex(0xB0B0B0),
+	theme.ColorNameMenuBackground:      hex(0xECECEC),
+	theme.ColorNameOverlayBackground:   hex(0xEEEEEE),
+	theme.ColorNamePlaceHolder:         hex(0x707070),
+	theme.ColorNamePressed:             hexAlpha(0x000000, 0x1E),
+	theme.ColorNamePrimary:             hex(0x3C6E99),
+	theme.ColorNameScrollBar:           hexAlpha(0x000000, 0x66),
+	theme.ColorNameScrollBarBackground: hexAlpha(0x000000, 0x14),
+	theme.ColorNameSelection:           hexAlpha(0x3C6E99, 0x40),
+	theme.ColorNameSeparator:           hex(0xC8C8C8),
+	theme.ColorNameShadow:              hexAlpha(0x000000, 0x33),
+	theme.ColorNameSuccess:             hex(0x4C8A4C),
+	theme.ColorNameWarning:             hex(0xA67C1E),
+}
+
+// Color returns the palette colour for name, falling back to the default
+// theme in the matching variant for names the palette does not cover
+func (t *AnalysisTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	if c, ok := t.palette[name]; ok {
+		return c
+	}
+	return theme.DefaultTheme().Color(name, t.Variant())
+}
+
+// Variant returns the fixed variant of the palette
+func (t *AnalysisTheme) Variant() fyne.ThemeVariant {
+	if t.dark {
+		return theme.VariantDark
+	}
+	return theme.VariantLight
+}
+
+// Font returns the default theme font
+func (t *AnalysisTheme) Font(style fyne.TextStyle) fyne.Resource {
+	return theme.DefaultTheme().Font(style)
+}
+
+// Icon returns the default theme icon. Icons are recoloured from the
+// foreground colour, which carries the contrast guarantee.
+func (t *AnalysisTheme) Icon(name fyne.ThemeIconName) fyne.Resource {
+	return theme.DefaultTheme().Icon(name)
+}
+
+// Size returns the default theme size
+func (t *AnalysisTheme) Size(name fyne.ThemeSizeName) float32 {
+	return theme.DefaultTheme().Size(name)
+}
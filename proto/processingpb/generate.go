@@ -0,0 +1,6 @@
+// Package processingpb holds the Go bindings generated from
+// proto/processing.proto. Regenerating them needs protoc with the
+// protoc-gen-go and protoc-gen-go-grpc plugins on the PATH.
+package processingpb
+
+//go:generate protoc -I.. --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ../processing.proto
@@ -0,0 +1,530 @@
+// Processing service exposed by otsu-server. Generate the Go bindings in
+// proto/processingpb with `go generate ./proto/...`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: processing.proto
+
+package processingpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ProcessRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Encoded image in any format the application can load
+	ImageBytes []byte `protobuf:"bytes,1,opt,name=image_bytes,json=imageBytes,proto3" json:"image_bytes,omitempty"`
+	// Algorithm name as shown in the application, such as "2D Otsu"
+	Algorithm string `protobuf:"bytes,2,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	// Parameter overrides; values are parsed into the type of the algorithm
+	// default, and unknown names are rejected
+	Params        map[string]string `protobuf:"bytes,3,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessRequest) Reset() {
+	*x = ProcessRequest{}
+	mi := &file_processing_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessRequest) ProtoMessage() {}
+
+func (x *ProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_processing_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessRequest.ProtoReflect.Descriptor instead.
+func (*ProcessRequest) Descriptor() ([]byte, []int) {
+	return file_processing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProcessRequest) GetImageBytes() []byte {
+	if x != nil {
+		return x.ImageBytes
+	}
+	return nil
+}
+
+func (x *ProcessRequest) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *ProcessRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+// Metrics are computed against an adaptive reference mask, as in the CLI
+type Metrics struct {
+	state                  protoimpl.MessageState `protogen:"open.v1"`
+	Iou                    float64                `protobuf:"fixed64,1,opt,name=iou,proto3" json:"iou,omitempty"`
+	DiceCoefficient        float64                `protobuf:"fixed64,2,opt,name=dice_coefficient,json=diceCoefficient,proto3" json:"dice_coefficient,omitempty"`
+	MisclassificationError float64                `protobuf:"fixed64,3,opt,name=misclassification_error,json=misclassificationError,proto3" json:"misclassification_error,omitempty"`
+	RegionUniformity       float64                `protobuf:"fixed64,4,opt,name=region_uniformity,json=regionUniformity,proto3" json:"region_uniformity,omitempty"`
+	BoundaryAccuracy       float64                `protobuf:"fixed64,5,opt,name=boundary_accuracy,json=boundaryAccuracy,proto3" json:"boundary_accuracy,omitempty"`
+	HausdorffDistance      float64                `protobuf:"fixed64,6,opt,name=hausdorff_distance,json=hausdorffDistance,proto3" json:"hausdorff_distance,omitempty"`
+	Psnr                   float64                `protobuf:"fixed64,7,opt,name=psnr,proto3" json:"psnr,omitempty"`
+	Ssim                   float64                `protobuf:"fixed64,8,opt,name=ssim,proto3" json:"ssim,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *Metrics) Reset() {
+	*x = Metrics{}
+	mi := &file_processing_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Metrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metrics) ProtoMessage() {}
+
+func (x *Metrics) ProtoReflect() protoreflect.Message {
+	mi := &file_processing_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metrics.ProtoReflect.Descriptor instead.
+func (*Metrics) Descriptor() ([]byte, []int) {
+	return file_processing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Metrics) GetIou() float64 {
+	if x != nil {
+		return x.Iou
+	}
+	return 0
+}
+
+func (x *Metrics) GetDiceCoefficient() float64 {
+	if x != nil {
+		return x.DiceCoefficient
+	}
+	return 0
+}
+
+func (x *Metrics) GetMisclassificationError() float64 {
+	if x != nil {
+		return x.MisclassificationError
+	}
+	return 0
+}
+
+func (x *Metrics) GetRegionUniformity() float64 {
+	if x != nil {
+		return x.RegionUniformity
+	}
+	return 0
+}
+
+func (x *Metrics) GetBoundaryAccuracy() float64 {
+	if x != nil {
+		return x.BoundaryAccuracy
+	}
+	return 0
+}
+
+func (x *Metrics) GetHausdorffDistance() float64 {
+	if x != nil {
+		return x.HausdorffDistance
+	}
+	return 0
+}
+
+func (x *Metrics) GetPsnr() float64 {
+	if x != nil {
+		return x.Psnr
+	}
+	return 0
+}
+
+func (x *Metrics) GetSsim() float64 {
+	if x != nil {
+		return x.Ssim
+	}
+	return 0
+}
+
+type ProcessResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Binary result encoded as PNG
+	ResultBytes   []byte   `protobuf:"bytes,1,opt,name=result_bytes,json=resultBytes,proto3" json:"result_bytes,omitempty"`
+	Metrics       *Metrics `protobuf:"bytes,2,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	ProcessTimeMs int64    `protobuf:"varint,3,opt,name=process_time_ms,json=processTimeMs,proto3" json:"process_time_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessResponse) Reset() {
+	*x = ProcessResponse{}
+	mi := &file_processing_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessResponse) ProtoMessage() {}
+
+func (x *ProcessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_processing_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessResponse.ProtoReflect.Descriptor instead.
+func (*ProcessResponse) Descriptor() ([]byte, []int) {
+	return file_processing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcessResponse) GetResultBytes() []byte {
+	if x != nil {
+		return x.ResultBytes
+	}
+	return nil
+}
+
+func (x *ProcessResponse) GetMetrics() *Metrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *ProcessResponse) GetProcessTimeMs() int64 {
+	if x != nil {
+		return x.ProcessTimeMs
+	}
+	return 0
+}
+
+type Progress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Stage name: loading, processing or encoding
+	Stage string `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	// Fraction of the request completed, from 0 to 1
+	Fraction      float64 `protobuf:"fixed64,2,opt,name=fraction,proto3" json:"fraction,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_processing_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_processing_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_processing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Progress) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *Progress) GetFraction() float64 {
+	if x != nil {
+		return x.Fraction
+	}
+	return 0
+}
+
+type ProcessUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Update:
+	//
+	//	*ProcessUpdate_Progress
+	//	*ProcessUpdate_Result
+	Update        isProcessUpdate_Update `protobuf_oneof:"update"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessUpdate) Reset() {
+	*x = ProcessUpdate{}
+	mi := &file_processing_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessUpdate) ProtoMessage() {}
+
+func (x *ProcessUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_processing_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessUpdate.ProtoReflect.Descriptor instead.
+func (*ProcessUpdate) Descriptor() ([]byte, []int) {
+	return file_processing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ProcessUpdate) GetUpdate() isProcessUpdate_Update {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+func (x *ProcessUpdate) GetProgress() *Progress {
+	if x != nil {
+		if x, ok := x.Update.(*ProcessUpdate_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *ProcessUpdate) GetResult() *ProcessResponse {
+	if x != nil {
+		if x, ok := x.Update.(*ProcessUpdate_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+type isProcessUpdate_Update interface {
+	isProcessUpdate_Update()
+}
+
+type ProcessUpdate_Progress struct {
+	Progress *Progress `protobuf:"bytes,1,opt,name=progress,proto3,oneof"`
+}
+
+type ProcessUpdate_Result struct {
+	Result *ProcessResponse `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+func (*ProcessUpdate_Progress) isProcessUpdate_Update() {}
+
+func (*ProcessUpdate_Result) isProcessUpdate_Update() {}
+
+var File_processing_proto protoreflect.FileDescriptor
+
+var file_processing_proto_rawDesc = string([]byte{
+	0x0a, 0x10, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x12, 0x6f, 0x74, 0x73, 0x75, 0x2e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73,
+	0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x22, 0xd2, 0x01, 0x0a, 0x0e, 0x50, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6d, 0x61,
+	0x67, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a,
+	0x69, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c,
+	0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61,
+	0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12, 0x46, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61,
+	0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x50, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
+	0x1a, 0x39, 0x0a, 0x0b, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xb0, 0x02, 0x0a, 0x07,
+	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x6f, 0x75, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x69, 0x6f, 0x75, 0x12, 0x29, 0x0a, 0x10, 0x64, 0x69, 0x63,
+	0x65, 0x5f, 0x63, 0x6f, 0x65, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0f, 0x64, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x65, 0x66, 0x66, 0x69, 0x63,
+	0x69, 0x65, 0x6e, 0x74, 0x12, 0x37, 0x0a, 0x17, 0x6d, 0x69, 0x73, 0x63, 0x6c, 0x61, 0x73, 0x73,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x16, 0x6d, 0x69, 0x73, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2b, 0x0a,
+	0x11, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x5f, 0x75, 0x6e, 0x69, 0x66, 0x6f, 0x72, 0x6d, 0x69,
+	0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e,
+	0x55, 0x6e, 0x69, 0x66, 0x6f, 0x72, 0x6d, 0x69, 0x74, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x62, 0x6f,
+	0x75, 0x6e, 0x64, 0x61, 0x72, 0x79, 0x5f, 0x61, 0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10, 0x62, 0x6f, 0x75, 0x6e, 0x64, 0x61, 0x72, 0x79, 0x41,
+	0x63, 0x63, 0x75, 0x72, 0x61, 0x63, 0x79, 0x12, 0x2d, 0x0a, 0x12, 0x68, 0x61, 0x75, 0x73, 0x64,
+	0x6f, 0x72, 0x66, 0x66, 0x5f, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x11, 0x68, 0x61, 0x75, 0x73, 0x64, 0x6f, 0x72, 0x66, 0x66, 0x44, 0x69,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x73, 0x6e, 0x72, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x70, 0x73, 0x6e, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x73,
+	0x69, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x04, 0x73, 0x73, 0x69, 0x6d, 0x22, 0x93,
+	0x01, 0x0a, 0x0f, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x35, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e, 0x70, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x26, 0x0a, 0x0f,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x6d, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x54, 0x69,
+	0x6d, 0x65, 0x4d, 0x73, 0x22, 0x3c, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x72, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x66, 0x72, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0x94, 0x01, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x12, 0x3a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e, 0x70, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x67,
+	0x72, 0x65, 0x73, 0x73, 0x48, 0x00, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x3d, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x23, 0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x42,
+	0x08, 0x0a, 0x06, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x32, 0xc3, 0x01, 0x0a, 0x11, 0x50, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x52, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x12, 0x22, 0x2e, 0x6f, 0x74, 0x73,
+	0x75, 0x2e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23,
+	0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x12, 0x22, 0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e, 0x70, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x6f, 0x74, 0x73, 0x75, 0x2e,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72,
+	0x6f, 0x63, 0x65, 0x73, 0x73, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42,
+	0x25, 0x5a, 0x23, 0x6f, 0x74, 0x73, 0x75, 0x2d, 0x6f, 0x62, 0x6c, 0x69, 0x74, 0x65, 0x72, 0x61,
+	0x74, 0x6f, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x69, 0x6e, 0x67, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_processing_proto_rawDescOnce sync.Once
+	file_processing_proto_rawDescData []byte
+)
+
+func file_processing_proto_rawDescGZIP() []byte {
+	file_processing_proto_rawDescOnce.Do(func() {
+		file_processing_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_processing_proto_rawDesc), len(file_processing_proto_rawDesc)))
+	})
+	return file_processing_proto_rawDescData
+}
+
+var file_processing_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_processing_proto_goTypes = []any{
+	(*ProcessRequest)(nil),  // 0: otsu.processing.v1.ProcessRequest
+	(*Metrics)(nil),         // 1: otsu.processing.v1.Metrics
+	(*ProcessResponse)(nil), // 2: otsu.processing.v1.ProcessResponse
+	(*Progress)(nil),        // 3: otsu.processing.v1.Progress
+	(*ProcessUpdate)(nil),   // 4: otsu.processing.v1.ProcessUpdate
+	nil,                     // 5: otsu.processing.v1.ProcessRequest.ParamsEntry
+}
+var file_processing_proto_depIdxs = []int32{
+	5, // 0: otsu.processing.v1.ProcessRequest.params:type_name -> otsu.processing.v1.ProcessRequest.ParamsEntry
+	1, // 1: otsu.processing.v1.ProcessResponse.metrics:type_name -> otsu.processing.v1.Metrics
+	3, // 2: otsu.processing.v1.ProcessUpdate.progress:type_name -> otsu.processing.v1.Progress
+	2, // 3: otsu.processing.v1.ProcessUpdate.result:type_name -> otsu.processing.v1.ProcessResponse
+	0, // 4: otsu.processing.v1.ProcessingService.Process:input_type -> otsu.processing.v1.ProcessRequest
+	0, // 5: otsu.processing.v1.ProcessingService.ProcessStream:input_type -> otsu.processing.v1.ProcessRequest
+	2, // 6: otsu.processing.v1.ProcessingService.Process:output_type -> otsu.processing.v1.ProcessResponse
+	4, // 7: otsu.processing.v1.ProcessingService.ProcessStream:output_type -> otsu.processing.v1.ProcessUpdate
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_processing_proto_init() }
+func file_processing_proto_init() {
+	if File_processing_proto != nil {
+		return
+	}
+	file_processing_proto_msgTypes[4].OneofWrappers = []any{
+		(*ProcessUpdate_Progress)(nil),
+		(*ProcessUpdate_Result)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_processing_proto_rawDesc), len(file_processing_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_processing_proto_goTypes,
+		DependencyIndexes: file_processing_proto_depIdxs,
+		MessageInfos:      file_processing_proto_msgTypes,
+	}.Build()
+	File_processing_proto = out.File
+	file_processing_proto_goTypes = nil
+	file_processing_proto_depIdxs = nil
+}
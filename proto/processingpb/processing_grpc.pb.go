@@ -0,0 +1,169 @@
+// Processing service exposed by otsu-server. Generate the Go bindings in
+// proto/processingpb with `go generate ./proto/...`.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: processing.proto
+
+package processingpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ProcessingService_Process_FullMethodName       = "/otsu.processing.v1.ProcessingService/Process"
+	ProcessingService_ProcessStream_FullMethodName = "/otsu.processing.v1.ProcessingService/ProcessStream"
+)
+
+// ProcessingServiceClient is the client API for ProcessingService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ProcessingService thresholds images with the application's algorithms
+type ProcessingServiceClient interface {
+	// Process segments one image and returns the result
+	Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error)
+	// ProcessStream segments each image the client sends in turn, answering
+	// every request with progress updates followed by its result. Closing the
+	// send side ends the stream once the last result has been sent.
+	ProcessStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ProcessRequest, ProcessUpdate], error)
+}
+
+type processingServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProcessingServiceClient(cc grpc.ClientConnInterface) ProcessingServiceClient {
+	return &processingServiceClient{cc}
+}
+
+func (c *processingServiceClient) Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessResponse)
+	err := c.cc.Invoke(ctx, ProcessingService_Process_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *processingServiceClient) ProcessStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ProcessRequest, ProcessUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ProcessingService_ServiceDesc.Streams[0], ProcessingService_ProcessStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ProcessRequest, ProcessUpdate]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProcessingService_ProcessStreamClient = grpc.BidiStreamingClient[ProcessRequest, ProcessUpdate]
+
+// ProcessingServiceServer is the server API for ProcessingService service.
+// All implementations must embed UnimplementedProcessingServiceServer
+// for forward compatibility.
+//
+// ProcessingService thresholds images with the application's algorithms
+type ProcessingServiceServer interface {
+	// Process segments one image and returns the result
+	Process(context.Context, *ProcessRequest) (*ProcessResponse, error)
+	// ProcessStream segments each image the client sends in turn, answering
+	// every request with progress updates followed by its result. Closing the
+	// send side ends the stream once the last result has been sent.
+	ProcessStream(grpc.BidiStreamingServer[ProcessRequest, ProcessUpdate]) error
+	mustEmbedUnimplementedProcessingServiceServer()
+}
+
+// UnimplementedProcessingServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProcessingServiceServer struct{}
+
+func (UnimplementedProcessingServiceServer) Process(context.Context, *ProcessRequest) (*ProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Process not implemented")
+}
+func (UnimplementedProcessingServiceServer) ProcessStream(grpc.BidiStreamingServer[ProcessRequest, ProcessUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method ProcessStream not implemented")
+}
+func (UnimplementedProcessingServiceServer) mustEmbedUnimplementedProcessingServiceServer() {}
+func (UnimplementedProcessingServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeProcessingServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProcessingServiceServer will
+// result in compilation errors.
+type UnsafeProcessingServiceServer interface {
+	mustEmbedUnimplementedProcessingServiceServer()
+}
+
+func RegisterProcessingServiceServer(s grpc.ServiceRegistrar, srv ProcessingServiceServer) {
+	// If the following call pancis, it indicates UnimplementedProcessingServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProcessingService_ServiceDesc, srv)
+}
+
+func _ProcessingService_Process_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProcessingServiceServer).Process(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProcessingService_Process_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProcessingServiceServer).Process(ctx, req.(*ProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProcessingService_ProcessStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ProcessingServiceServer).ProcessStream(&grpc.GenericServerStream[ProcessRequest, ProcessUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ProcessingService_ProcessStreamServer = grpc.BidiStreamingServer[ProcessRequest, ProcessUpdate]
+
+// ProcessingService_ServiceDesc is the grpc.ServiceDesc for ProcessingService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProcessingService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "otsu.processing.v1.ProcessingService",
+	HandlerType: (*ProcessingServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Process",
+			Handler:    _ProcessingService_Process_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ProcessStream",
+			Handler:       _ProcessingService_ProcessStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "processing.proto",
+}